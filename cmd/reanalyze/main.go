@@ -0,0 +1,10 @@
+package main
+
+import (
+	"business/internal/app/reanalyze"
+	"os"
+)
+
+func main() {
+	os.Exit(reanalyze.Run(os.Args[1:]))
+}
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"business/internal/app/worker"
+)
+
+func main() {
+	worker.Run()
+}
@@ -0,0 +1,130 @@
+package bootstrap
+
+import (
+	"business/internal/di"
+	"business/internal/library/crypto"
+	"business/internal/library/gmail"
+	"business/internal/library/gmailService"
+	"business/internal/library/logger"
+	"business/internal/library/mysql"
+	"business/internal/library/openai"
+	"business/internal/library/oswrapper"
+	"business/internal/library/ratelimit"
+	"business/internal/library/secret"
+	"business/internal/library/timewrapper"
+
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"go.uber.org/dig"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Dependencies は API サーバー・worker・再解析コマンドで共有する初期化済みの依存をまとめたもの。
+type Dependencies struct {
+	Container  *dig.Container
+	OsWrapper  *oswrapper.OsWrapper
+	BaseLogger *logger.Logger
+	Clock      *timewrapper.Clock
+}
+
+// Build は環境変数・シークレットを読み込み、DB / 外部クライアント / DI コンテナを初期化する。
+// service はログの service 名、component は初期化失敗時のログ component 名に使う。
+// ロガー生成前の失敗は panic し、それ以降の失敗はログを出したうえで error を返す。
+func Build(ctx context.Context, service string, component string) (*Dependencies, error) {
+	environment := strings.TrimSpace(os.Getenv("APP"))
+	if environment == "" {
+		panic("初期化に失敗しました: os.GetenvでAPP項目を取得できませんでした。")
+	}
+	var appSecretClient secret.Client
+	var err error
+	if environment != "local" && environment != "ci" {
+		appSecretName := strings.TrimSpace(os.Getenv("APP_SECRET_NAME"))
+		if appSecretName == "" {
+			panic("初期化に失敗しました: os.GetenvでAPP_SECRET_NAME項目を取得できませんでした。")
+		}
+		appSecretClient, err = secret.New(ctx, appSecretName)
+		if err != nil {
+			panic("アプリ共通シークレットクライアント初期化に失敗しました: " + err.Error())
+		}
+
+	}
+
+	osw, err := oswrapper.New(appSecretClient)
+	if err != nil {
+		panic("OsWrapper 初期化に失敗しました: " + err.Error())
+	}
+
+	baseLogger, err := logger.New("info", service, environment)
+	if err != nil {
+		panic("ロガー初期化に失敗しました: " + err.Error())
+	}
+	bootLogger := baseLogger.With(logger.Component(component))
+
+	fail := func(message string, cause error) (*Dependencies, error) {
+		bootLogger.Error(message, logger.Err(cause))
+		_ = baseLogger.Sync()
+		return nil, errors.Join(errors.New(message), cause)
+	}
+
+	// DBインスタンス生成
+	db, err := mysql.New(osw, baseLogger)
+	if err != nil {
+		return fail("DB 初期化時にエラーが発生しました", err)
+	}
+
+	providerLogger := baseLogger.With(logger.Component("ratelimit_provider"))
+	provider, err := ratelimit.NewProviderFromEnv(osw, providerLogger)
+	if err != nil {
+		return fail("レートリミット初期化時にエラーが発生しました", err)
+	}
+	gmailLimiter := provider.GetGmailLimiter()
+	openaiLimiter := provider.GetOpenAILimiter()
+
+	// OpenAiクライアント作成
+	apiKey, err := osw.GetEnv("OPENAI_API_KEY")
+	if err != nil {
+		return fail("環境変数 OPENAI_API_KEY の取得に失敗しました", err)
+	}
+	oa := openai.New(apiKey, openaiLimiter, baseLogger)
+	gs := gmailService.New()
+	gc := gmail.New(gmailLimiter, baseLogger)
+	// GMAIL_BODY_PREFERENCE は任意。IMAP / ファイル取り込みも DI でこの設定を引き継ぐ。不正な値は既定値で黙って続けず起動を止める。
+	if value, err := osw.GetEnv("GMAIL_BODY_PREFERENCE"); err == nil && strings.TrimSpace(value) != "" {
+		preference, err := gmail.ParseBodyPreference(value)
+		if err != nil {
+			return fail("環境変数 GMAIL_BODY_PREFERENCE が不正です", err)
+		}
+		gc = gc.WithBodyPreference(preference)
+	}
+	emailTokenKey, err := osw.GetEnv("EMAIL_TOKEN_KEY_V1")
+	if err != nil {
+		return fail("failed to read EMAIL_TOKEN_KEY_V1", err)
+	}
+
+	emailTokenSalt, err := osw.GetEnv("EMAIL_TOKEN_SALT")
+	if err != nil {
+		return fail("failed to read EMAIL_TOKEN_SALT", err)
+	}
+
+	// Vaultインスタンス生成
+	vault, err := crypto.NewVault(crypto.VaultConfig{
+		KeyMaterial: []byte(emailTokenKey),
+		Salt:        []byte(emailTokenSalt),
+		Info:        "email-credential-encryption",
+		BcryptCost:  bcrypt.DefaultCost,
+	})
+	if err != nil {
+		return fail("Vault 初期化時にエラーが発生しました", err)
+	}
+
+	// DIを行う
+	return &Dependencies{
+		Container:  di.BuildContainer(db, oa, gs, gc, osw, provider, baseLogger, vault),
+		OsWrapper:  osw,
+		BaseLogger: baseLogger,
+		Clock:      timewrapper.NewClock(),
+	}, nil
+}
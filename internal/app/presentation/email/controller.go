@@ -0,0 +1,127 @@
+package email
+
+import (
+	"business/internal/app/httpresponse"
+	"business/internal/library/logger"
+	mfapp "business/internal/mailfetch/application"
+	mfdomain "business/internal/mailfetch/domain"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Controller serves the stored source of saved emails.
+type Controller struct {
+	bodyUseCase mfapp.EmailBodyUseCase
+	log         logger.Interface
+}
+
+// NewController creates a new Controller.
+func NewController(bodyUseCase mfapp.EmailBodyUseCase, log logger.Interface) *Controller {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Controller{
+		bodyUseCase: bodyUseCase,
+		log:         log.With(logger.Component("email_controller")),
+	}
+}
+
+type sourceResponse struct {
+	EmailID     uint                       `json:"email_id"`
+	Body        string                     `json:"body"`
+	Attachments []sourceAttachmentResponse `json:"attachments"`
+	StoredAt    time.Time                  `json:"stored_at"`
+	ExpiresAt   time.Time                  `json:"expires_at"`
+}
+
+type sourceAttachmentResponse struct {
+	Filename      string `json:"filename"`
+	MimeType      string `json:"mime_type"`
+	Size          int64  `json:"size"`
+	Text          string `json:"text"`
+	Truncated     bool   `json:"truncated"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// Source handles GET /api/v1/emails/:email_id/source
+func (ctrl *Controller) Source(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	emailID, err := strconv.ParseUint(c.Param("email_id"), 10, 64)
+	if err != nil || emailID == 0 {
+		httpresponse.WriteInvalidRequest(c)
+		return
+	}
+
+	if ctrl.bodyUseCase == nil {
+		reqLog.Error("email_body_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	body, err := ctrl.bodyUseCase.GetBody(c.Request.Context(), uid, uint(emailID))
+	if err != nil {
+		switch {
+		case errors.Is(err, mfdomain.ErrInvalidCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, mfdomain.ErrEmailBodyNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "email_source_not_found", "メール本文は保存されていないか、保存期間を過ぎています。")
+		default:
+			reqLog.Error("get_email_source_failed",
+				logger.UserID(uid),
+				logger.Uint("email_id", uint(emailID)),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	attachments := make([]sourceAttachmentResponse, 0, len(body.Attachments))
+	for _, attachment := range body.Attachments {
+		attachments = append(attachments, sourceAttachmentResponse{
+			Filename:      attachment.Filename,
+			MimeType:      attachment.MimeType,
+			Size:          attachment.Size,
+			Text:          attachment.Text,
+			Truncated:     attachment.Truncated,
+			FailureReason: attachment.FailureReason,
+		})
+	}
+
+	c.JSON(http.StatusOK, sourceResponse{
+		EmailID:     body.EmailID,
+		Body:        body.Body,
+		Attachments: attachments,
+		StoredAt:    body.StoredAt,
+		ExpiresAt:   body.ExpiresAt,
+	})
+}
+
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		httpresponse.WriteError(c, http.StatusUnauthorized, "unauthorized", "認証が必要です。")
+		return 0, false
+	}
+
+	uid, ok := userID.(uint)
+	if !ok {
+		httpresponse.WriteInternalServerError(c)
+		return 0, false
+	}
+
+	return uid, true
+}
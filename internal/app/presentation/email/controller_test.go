@@ -0,0 +1,123 @@
+package email
+
+import (
+	cd "business/internal/common/domain"
+	mfdomain "business/internal/mailfetch/domain"
+	mocklibrary "business/test/mock/library"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockEmailBodyUseCase struct {
+	mock.Mock
+}
+
+func (m *mockEmailBodyUseCase) GetBody(ctx context.Context, userID, emailID uint) (mfdomain.EmailBody, error) {
+	args := m.Called(ctx, userID, emailID)
+	body, _ := args.Get(0).(mfdomain.EmailBody)
+	return body, args.Error(1)
+}
+
+func (m *mockEmailBodyUseCase) GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error) {
+	args := m.Called(ctx, userID, emailIDs)
+	bodies, _ := args.Get(0).([]mfdomain.EmailBody)
+	return bodies, args.Error(1)
+}
+
+func (m *mockEmailBodyUseCase) PurgeExpired(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func sourceRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.GET("/emails/:email_id/source", func(c *gin.Context) { c.Set("userID", uint(1)) }, ctrl.Source)
+	return r
+}
+
+func TestSource_200(t *testing.T) {
+	t.Parallel()
+
+	storedAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	uc := new(mockEmailBodyUseCase)
+	uc.On("GetBody", mock.Anything, uint(1), uint(11)).Return(mfdomain.EmailBody{
+		EmailID:     11,
+		Body:        "ご請求金額 1,000円",
+		Attachments: []cd.FetchedAttachmentDTO{{Filename: "invoice.pdf", MimeType: "application/pdf", Size: 2048, Text: "Total 1,000"}},
+		StoredAt:    storedAt,
+		ExpiresAt:   storedAt.Add(90 * 24 * time.Hour),
+	}, nil).Once()
+
+	r := sourceRouter(NewController(uc, mocklibrary.NewNopLogger()))
+
+	req := httptest.NewRequest(http.MethodGet, "/emails/11/source", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"email_id": 11,
+		"body": "ご請求金額 1,000円",
+		"attachments": [
+			{"filename": "invoice.pdf", "mime_type": "application/pdf", "size": 2048, "text": "Total 1,000", "truncated": false}
+		],
+		"stored_at": "2026-10-17T09:00:00Z",
+		"expires_at": "2027-01-15T09:00:00Z"
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestSource_400_invalid_email_id(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockEmailBodyUseCase)
+	r := sourceRouter(NewController(uc, mocklibrary.NewNopLogger()))
+
+	req := httptest.NewRequest(http.MethodGet, "/emails/abc/source", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	uc.AssertNotCalled(t, "GetBody", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSource_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "not stored", err: mfdomain.ErrEmailBodyNotFound, wantStatus: http.StatusNotFound, wantCode: "email_source_not_found"},
+		{name: "invalid", err: mfdomain.ErrInvalidCommand, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockEmailBodyUseCase)
+			uc.On("GetBody", mock.Anything, uint(1), uint(11)).Return(nil, tt.err).Once()
+			r := sourceRouter(NewController(uc, mocklibrary.NewNopLogger()))
+
+			req := httptest.NewRequest(http.MethodGet, "/emails/11/source", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"code":"`+tt.wantCode+`"`)
+		})
+	}
+}
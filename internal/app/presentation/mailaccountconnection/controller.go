@@ -44,6 +44,14 @@ type callbackResponse struct {
 	Message string `json:"message"`
 }
 
+type connectIMAPRequest struct {
+	Host     string `json:"host" binding:"required"`
+	Port     int    `json:"port"`
+	Security string `json:"security"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
 type listConnectionsResponse struct {
 	Items []connectionResponseItem `json:"items"`
 }
@@ -127,6 +135,129 @@ func (ctrl *Controller) Callback(c *gin.Context) {
 	})
 }
 
+// AuthorizeOutlook handles POST /api/v1/mail-account-connections/outlook/authorize
+func (ctrl *Controller) AuthorizeOutlook(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := ctrl.usecase.AuthorizeOutlook(c.Request.Context(), uid)
+	if err != nil {
+		reqLog.Error("authorize_outlook_failed", logger.Err(err))
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, authorizeResponse{
+		AuthorizationURL: result.AuthorizationURL,
+		ExpiresAt:        result.ExpiresAt,
+	})
+}
+
+// CallbackOutlook handles POST /api/v1/mail-account-connections/outlook/callback
+func (ctrl *Controller) CallbackOutlook(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req callbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.WriteInvalidRequest(c)
+		return
+	}
+
+	err := ctrl.usecase.CallbackOutlook(c.Request.Context(), uid, req.Code, req.State)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOAuthStateMismatch):
+			httpresponse.WriteError(c, http.StatusConflict, "oauth_state_mismatch", "OAuth stateが一致しません。")
+		case errors.Is(err, domain.ErrOAuthStateExpired):
+			httpresponse.WriteError(c, http.StatusConflict, "oauth_state_expired", "OAuth stateの有効期限が切れています。")
+		case errors.Is(err, domain.ErrOAuthExchangeFailed):
+			httpresponse.WriteServiceUnavailable(c, "outlook_oauth_exchange_failed", "Microsoftとのトークン交換に失敗しました。しばらくしてから再度お試しください。")
+		case errors.Is(err, domain.ErrOutlookProfileFetchFailed):
+			httpresponse.WriteServiceUnavailable(c, "outlook_profile_fetch_failed", "Outlookのメールアドレスの取得に失敗しました。しばらくしてから再度お試しください。")
+		case errors.Is(err, domain.ErrRefreshTokenMissing):
+			httpresponse.WriteServiceUnavailable(c, "outlook_oauth_exchange_failed", "Microsoftからリフレッシュトークンが取得できませんでした。再度お試しください。")
+		case errors.Is(err, domain.ErrVaultEncryptFailed):
+			reqLog.Error("vault_encrypt_failed", logger.Err(err))
+			httpresponse.WriteInternalServerError(c)
+		default:
+			reqLog.Error("callback_outlook_failed", logger.Err(err))
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, callbackResponse{
+		Message: "Outlook連携が完了しました。",
+	})
+}
+
+// ConnectIMAP handles POST /api/v1/mail-account-connections/imap
+func (ctrl *Controller) ConnectIMAP(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req connectIMAPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.WriteInvalidRequest(c)
+		return
+	}
+
+	connection, err := ctrl.usecase.ConnectIMAP(c.Request.Context(), uid, application.IMAPConnectInput{
+		Host:     req.Host,
+		Port:     req.Port,
+		Security: req.Security,
+		Username: req.Username,
+		Password: req.Password,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrIMAPSettingsInvalid):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, domain.ErrIMAPAuthenticationFailed):
+			httpresponse.WriteError(c, http.StatusBadRequest, "imap_authentication_failed", "IMAPサーバーへのログインに失敗しました。ユーザー名とパスワードを確認してください。")
+		case errors.Is(err, domain.ErrIMAPServerUnreachable):
+			httpresponse.WriteServiceUnavailable(c, "imap_server_unreachable", "IMAPサーバーに接続できませんでした。ホスト名・ポート・暗号化方式を確認してください。")
+		case errors.Is(err, domain.ErrVaultEncryptFailed):
+			reqLog.Error("vault_encrypt_failed", logger.Err(err))
+			httpresponse.WriteInternalServerError(c)
+		default:
+			reqLog.Error("connect_imap_failed", logger.Err(err))
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, connectionResponseItem{
+		ID:                connection.ID,
+		Provider:          connection.Provider,
+		AccountIdentifier: connection.AccountIdentifier,
+		CreatedAt:         connection.CreatedAt,
+		UpdatedAt:         connection.UpdatedAt,
+	})
+}
+
 // List handles GET /api/v1/mail-account-connections
 func (ctrl *Controller) List(c *gin.Context) {
 	reqLog := ctrl.log
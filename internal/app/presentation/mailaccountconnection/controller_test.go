@@ -359,3 +359,217 @@ func TestList_500_internal(t *testing.T) {
 	assert.Contains(t, resp.Body.String(), "internal_server_error")
 	uc.AssertExpectations(t)
 }
+
+func connectIMAPRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.POST("/imap", func(c *gin.Context) { setUserID(c, 1) }, ctrl.ConnectIMAP)
+	return r
+}
+
+func postConnectIMAP(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/imap", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestConnectIMAP_200(t *testing.T) {
+	t.Parallel()
+	createdAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	uc := new(mockUseCase)
+	uc.On("ConnectIMAP", mock.Anything, uint(1), application.IMAPConnectInput{
+		Host:     "imap.mail.me.com",
+		Port:     993,
+		Security: "tls",
+		Username: "billing@icloud.com",
+		Password: "app-password",
+	}).Return(domain.ConnectionView{
+		ID:                31,
+		Provider:          "imap",
+		AccountIdentifier: "billing@icloud.com",
+		CreatedAt:         createdAt,
+		UpdatedAt:         createdAt,
+	}, nil).Once()
+
+	resp := postConnectIMAP(connectIMAPRouter(newTestController(uc)),
+		`{"host":"imap.mail.me.com","port":993,"security":"tls","username":"billing@icloud.com","password":"app-password"}`)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"id": 31,
+		"provider": "imap",
+		"account_identifier": "billing@icloud.com",
+		"created_at": "2026-10-17T09:00:00Z",
+		"updated_at": "2026-10-17T09:00:00Z"
+	}`, resp.Body.String())
+	assert.NotContains(t, resp.Body.String(), "app-password")
+	uc.AssertExpectations(t)
+}
+
+func TestConnectIMAP_400_invalid_request(t *testing.T) {
+	t.Parallel()
+	uc := new(mockUseCase)
+
+	resp := postConnectIMAP(connectIMAPRouter(newTestController(uc)), `{"host":"imap.example.com","username":"u"}`)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "invalid_request")
+	uc.AssertNotCalled(t, "ConnectIMAP", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConnectIMAP_401_no_user(t *testing.T) {
+	t.Parallel()
+	uc := new(mockUseCase)
+	r := gin.New()
+	r.POST("/imap", newTestController(uc).ConnectIMAP)
+
+	resp := postConnectIMAP(r, `{"host":"imap.example.com","username":"u","password":"p"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestConnectIMAP_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "invalid settings", err: domain.ErrIMAPSettingsInvalid, expectedCode: http.StatusBadRequest, expectedBody: "invalid_request"},
+		{name: "authentication failed", err: domain.ErrIMAPAuthenticationFailed, expectedCode: http.StatusBadRequest, expectedBody: "imap_authentication_failed"},
+		{name: "server unreachable", err: domain.ErrIMAPServerUnreachable, expectedCode: http.StatusServiceUnavailable, expectedBody: "imap_server_unreachable"},
+		{name: "internal", err: errors.New("db timeout"), expectedCode: http.StatusInternalServerError, expectedBody: "internal_server_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := new(mockUseCase)
+			uc.On("ConnectIMAP", mock.Anything, uint(1), mock.Anything).Return(domain.ConnectionView{}, tt.err).Once()
+
+			resp := postConnectIMAP(connectIMAPRouter(newTestController(uc)), `{"host":"imap.example.com","username":"u","password":"p"}`)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func outlookRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.POST("/outlook/authorize", func(c *gin.Context) { setUserID(c, 1) }, ctrl.AuthorizeOutlook)
+	r.POST("/outlook/callback", func(c *gin.Context) { setUserID(c, 1) }, ctrl.CallbackOutlook)
+	return r
+}
+
+func postOutlookCallback(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/outlook/callback", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestAuthorizeOutlook_200(t *testing.T) {
+	t.Parallel()
+	uc := new(mockUseCase)
+	uc.On("AuthorizeOutlook", mock.Anything, uint(1)).Return(application.AuthorizeResult{
+		AuthorizationURL: "https://login.microsoftonline.com/common/oauth2/v2.0/authorize?state=abc",
+		ExpiresAt:        fixedExpiresAt(),
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/outlook/authorize", nil)
+	resp := httptest.NewRecorder()
+	outlookRouter(newTestController(uc)).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "login.microsoftonline.com")
+	assert.Contains(t, resp.Body.String(), "expires_at")
+	uc.AssertExpectations(t)
+}
+
+func TestAuthorizeOutlook_401_no_user(t *testing.T) {
+	t.Parallel()
+	uc := new(mockUseCase)
+
+	r := gin.New()
+	r.POST("/outlook/authorize", newTestController(uc).AuthorizeOutlook) // no userID set
+
+	req := httptest.NewRequest(http.MethodPost, "/outlook/authorize", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	uc.AssertNotCalled(t, "AuthorizeOutlook", mock.Anything, mock.Anything)
+}
+
+func TestAuthorizeOutlook_500_internal(t *testing.T) {
+	t.Parallel()
+	uc := new(mockUseCase)
+	uc.On("AuthorizeOutlook", mock.Anything, uint(1)).Return(application.AuthorizeResult{}, errors.New("EMAIL_OUTLOOK_CLIENT_ID is empty")).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/outlook/authorize", nil)
+	resp := httptest.NewRecorder()
+	outlookRouter(newTestController(uc)).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestCallbackOutlook_200(t *testing.T) {
+	t.Parallel()
+	uc := new(mockUseCase)
+	uc.On("CallbackOutlook", mock.Anything, uint(1), "auth-code", "state-value").Return(nil).Once()
+
+	resp := postOutlookCallback(outlookRouter(newTestController(uc)), `{"code":"auth-code","state":"state-value"}`)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Outlook連携が完了しました。")
+	uc.AssertExpectations(t)
+}
+
+func TestCallbackOutlook_400_invalid_request(t *testing.T) {
+	t.Parallel()
+	uc := new(mockUseCase)
+
+	resp := postOutlookCallback(outlookRouter(newTestController(uc)), `{"code":"auth-code"}`)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	uc.AssertNotCalled(t, "CallbackOutlook", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCallbackOutlook_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "state mismatch", err: domain.ErrOAuthStateMismatch, expectedCode: http.StatusConflict, expectedBody: "oauth_state_mismatch"},
+		{name: "state expired", err: domain.ErrOAuthStateExpired, expectedCode: http.StatusConflict, expectedBody: "oauth_state_expired"},
+		{name: "exchange failed", err: domain.ErrOAuthExchangeFailed, expectedCode: http.StatusServiceUnavailable, expectedBody: "outlook_oauth_exchange_failed"},
+		{name: "profile fetch failed", err: domain.ErrOutlookProfileFetchFailed, expectedCode: http.StatusServiceUnavailable, expectedBody: "outlook_profile_fetch_failed"},
+		{name: "refresh token missing", err: domain.ErrRefreshTokenMissing, expectedCode: http.StatusServiceUnavailable, expectedBody: "outlook_oauth_exchange_failed"},
+		{name: "vault failure", err: domain.ErrVaultEncryptFailed, expectedCode: http.StatusInternalServerError, expectedBody: "internal_server_error"},
+		{name: "internal", err: errors.New("db timeout"), expectedCode: http.StatusInternalServerError, expectedBody: "internal_server_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			uc := new(mockUseCase)
+			uc.On("CallbackOutlook", mock.Anything, uint(1), "code", "state").Return(tt.err).Once()
+
+			resp := postOutlookCallback(outlookRouter(newTestController(uc)), `{"code":"code","state":"state"}`)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+			uc.AssertExpectations(t)
+		})
+	}
+}
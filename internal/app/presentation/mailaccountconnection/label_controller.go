@@ -0,0 +1,105 @@
+package mailaccountconnection
+
+import (
+	"business/internal/app/httpresponse"
+	"business/internal/library/logger"
+	mfapp "business/internal/mailfetch/application"
+	mfdomain "business/internal/mailfetch/domain"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LabelController lists the provider labels of a mail account connection.
+type LabelController struct {
+	labelUseCase mfapp.LabelUseCase
+	log          logger.Interface
+}
+
+// NewLabelController creates a new LabelController.
+func NewLabelController(labelUseCase mfapp.LabelUseCase, log logger.Interface) *LabelController {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &LabelController{
+		labelUseCase: labelUseCase,
+		log:          log.With(logger.Component("mail_account_connection_label_controller")),
+	}
+}
+
+type listLabelsResponse struct {
+	Items []labelResponseItem `json:"items"`
+}
+
+type labelResponseItem struct {
+	Name           string `json:"name"`
+	System         bool   `json:"system"`
+	MessagesTotal  *int64 `json:"messages_total"`
+	MessagesUnread *int64 `json:"messages_unread"`
+}
+
+// List handles GET /api/v1/mail-account-connections/:connection_id/labels
+func (ctrl *LabelController) List(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	connectionID, ok := currentConnectionID(c)
+	if !ok {
+		return
+	}
+
+	if ctrl.labelUseCase == nil {
+		reqLog.Error("mail_label_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	labels, err := ctrl.labelUseCase.ListLabels(c.Request.Context(), uid, connectionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, mfdomain.ErrInvalidCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, mfdomain.ErrConnectionNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "mail_account_connection_not_found", "対象のメール連携は見つかりません。")
+		case errors.Is(err, mfdomain.ErrConnectionUnavailable):
+			httpresponse.WriteError(c, http.StatusConflict, "mail_account_connection_unavailable", "対象のメール連携は利用できません。再連携をおねがいします。")
+		case errors.Is(err, mfdomain.ErrLabelListUnsupported):
+			httpresponse.WriteError(c, http.StatusBadRequest, "mail_label_list_unsupported", "ラベルの一覧はGmail連携でのみ取得できます。")
+		case errors.Is(err, mfdomain.ErrProviderSessionBuildFailed), errors.Is(err, mfdomain.ErrProviderListFailed):
+			reqLog.Warn("list_labels_provider_failed",
+				logger.UserID(uid),
+				logger.Uint("connection_id", connectionID),
+				logger.Err(err),
+			)
+			httpresponse.WriteServiceUnavailable(c, "gmail_label_list_failed", "Gmailからラベルの一覧を取得できませんでした。しばらくしてから再度お試しください。")
+		default:
+			reqLog.Error("list_labels_failed",
+				logger.UserID(uid),
+				logger.Uint("connection_id", connectionID),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	items := make([]labelResponseItem, 0, len(labels))
+	for _, label := range labels {
+		items = append(items, labelResponseItem{
+			Name:           label.Name,
+			System:         label.System,
+			MessagesTotal:  label.MessagesTotal,
+			MessagesUnread: label.MessagesUnread,
+		})
+	}
+
+	c.JSON(http.StatusOK, listLabelsResponse{Items: items})
+}
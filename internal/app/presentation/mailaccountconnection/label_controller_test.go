@@ -0,0 +1,105 @@
+package mailaccountconnection
+
+import (
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLabelUseCase struct {
+	mock.Mock
+}
+
+func (m *mockLabelUseCase) ListLabels(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error) {
+	args := m.Called(ctx, userID, connectionID)
+	labels, _ := args.Get(0).([]mfdomain.MailLabel)
+	return labels, args.Error(1)
+}
+
+func labelsRouter(ctrl *LabelController) *gin.Engine {
+	r := gin.New()
+	r.GET("/connections/:connection_id/labels", func(c *gin.Context) { setUserID(c, 1) }, ctrl.List)
+	return r
+}
+
+func TestListLabels_200(t *testing.T) {
+	t.Parallel()
+
+	total, unread := int64(42), int64(3)
+	uc := new(mockLabelUseCase)
+	uc.On("ListLabels", mock.Anything, uint(1), uint(12)).Return([]mfdomain.MailLabel{
+		{Name: "INBOX", System: true},
+		{Name: "billing", MessagesTotal: &total, MessagesUnread: &unread},
+	}, nil).Once()
+
+	r := labelsRouter(NewLabelController(uc, newTestLogger()))
+
+	req := httptest.NewRequest(http.MethodGet, "/connections/12/labels", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"items": [
+			{"name": "INBOX", "system": true, "messages_total": null, "messages_unread": null},
+			{"name": "billing", "system": false, "messages_total": 42, "messages_unread": 3}
+		]
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestListLabels_400_invalid_connection_id(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockLabelUseCase)
+	r := labelsRouter(NewLabelController(uc, newTestLogger()))
+
+	req := httptest.NewRequest(http.MethodGet, "/connections/abc/labels", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	uc.AssertNotCalled(t, "ListLabels", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListLabels_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "not found", err: mfdomain.ErrConnectionNotFound, wantStatus: http.StatusNotFound, wantCode: "mail_account_connection_not_found"},
+		{name: "unavailable", err: mfdomain.ErrConnectionUnavailable, wantStatus: http.StatusConflict, wantCode: "mail_account_connection_unavailable"},
+		{name: "unsupported provider", err: mfdomain.ErrLabelListUnsupported, wantStatus: http.StatusBadRequest, wantCode: "mail_label_list_unsupported"},
+		{name: "gmail failure", err: mfdomain.ErrProviderListFailed, wantStatus: http.StatusServiceUnavailable, wantCode: "gmail_label_list_failed"},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockLabelUseCase)
+			uc.On("ListLabels", mock.Anything, uint(1), uint(12)).Return(nil, tt.err).Once()
+			r := labelsRouter(NewLabelController(uc, newTestLogger()))
+
+			req := httptest.NewRequest(http.MethodGet, "/connections/12/labels", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"code":"`+tt.wantCode+`"`)
+		})
+	}
+}
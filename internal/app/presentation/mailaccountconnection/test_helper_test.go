@@ -36,6 +36,21 @@ func (m *mockUseCase) Disconnect(ctx context.Context, userID uint, connectionID
 	return args.Error(0)
 }
 
+func (m *mockUseCase) ConnectIMAP(ctx context.Context, userID uint, input application.IMAPConnectInput) (domain.ConnectionView, error) {
+	args := m.Called(ctx, userID, input)
+	return args.Get(0).(domain.ConnectionView), args.Error(1)
+}
+
+func (m *mockUseCase) AuthorizeOutlook(ctx context.Context, userID uint) (application.AuthorizeResult, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(application.AuthorizeResult), args.Error(1)
+}
+
+func (m *mockUseCase) CallbackOutlook(ctx context.Context, userID uint, code, state string) error {
+	args := m.Called(ctx, userID, code, state)
+	return args.Error(0)
+}
+
 func newTestLogger() logger.Interface {
 	return mocklibrary.NewNopLogger()
 }
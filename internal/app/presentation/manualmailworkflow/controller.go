@@ -14,15 +14,30 @@ import (
 
 // Controller handles manual mail workflow HTTP requests.
 type Controller struct {
-	startUseCase manualapp.StartUseCase
-	listUseCase  manualapp.ListUseCase
-	log          logger.Interface
+	startUseCase   manualapp.StartUseCase
+	listUseCase    manualapp.ListUseCase
+	detailUseCase  manualapp.DetailUseCase
+	cancelUseCase  manualapp.CancelUseCase
+	retryUseCase   manualapp.RetryUseCase
+	resumeUseCase  manualapp.ResumeUseCase
+	eventsUseCase  manualapp.EventsUseCase
+	previewUseCase manualapp.PreviewUseCase
+	log            logger.Interface
 }
 
+// workflowEventsHeartbeatInterval keeps idle SSE connections open through proxies between transitions.
+const workflowEventsHeartbeatInterval = 15 * time.Second
+
 // NewController creates a new Controller.
 func NewController(
 	startUseCase manualapp.StartUseCase,
 	listUseCase manualapp.ListUseCase,
+	detailUseCase manualapp.DetailUseCase,
+	cancelUseCase manualapp.CancelUseCase,
+	retryUseCase manualapp.RetryUseCase,
+	resumeUseCase manualapp.ResumeUseCase,
+	eventsUseCase manualapp.EventsUseCase,
+	previewUseCase manualapp.PreviewUseCase,
 	log logger.Interface,
 ) *Controller {
 	if log == nil {
@@ -30,17 +45,29 @@ func NewController(
 	}
 
 	return &Controller{
-		startUseCase: startUseCase,
-		listUseCase:  listUseCase,
-		log:          log.With(logger.Component("manual_mail_workflow_controller")),
+		startUseCase:   startUseCase,
+		listUseCase:    listUseCase,
+		detailUseCase:  detailUseCase,
+		cancelUseCase:  cancelUseCase,
+		retryUseCase:   retryUseCase,
+		resumeUseCase:  resumeUseCase,
+		eventsUseCase:  eventsUseCase,
+		previewUseCase: previewUseCase,
+		log:            log.With(logger.Component("manual_mail_workflow_controller")),
 	}
 }
 
 type executeRequest struct {
-	ConnectionID uint      `json:"connection_id" binding:"required"`
-	LabelName    string    `json:"label_name" binding:"required"`
-	Since        time.Time `json:"since" binding:"required"`
-	Until        time.Time `json:"until" binding:"required"`
+	ConnectionID   uint      `json:"connection_id"`
+	AllConnections bool      `json:"all_connections"`
+	LabelName      string    `json:"label_name" binding:"required"`
+	Since          time.Time `json:"since" binding:"required"`
+	Until          time.Time `json:"until" binding:"required"`
+	// Query, IncludeLabelNames and ExcludeLabelNames narrow a Gmail fetch; other providers reject them.
+	Query             string   `json:"query"`
+	IncludeLabelNames []string `json:"include_label_names"`
+	ExcludeLabelNames []string `json:"exclude_label_names"`
+	DryRun            bool     `json:"dry_run"`
 }
 
 type executeAcceptedResponse struct {
@@ -49,28 +76,52 @@ type executeAcceptedResponse struct {
 	Status     string `json:"status"`
 }
 
+type cancelResponse struct {
+	Message    string `json:"message"`
+	WorkflowID string `json:"workflow_id"`
+	Status     string `json:"status"`
+}
+
 type listResponse struct {
 	Items      []workflowHistoryItemResponse `json:"items"`
 	TotalCount int64                         `json:"total_count"`
 }
 
 type workflowHistoryItemResponse struct {
-	WorkflowID         string               `json:"workflow_id"`
-	Provider           string               `json:"provider"`
-	AccountIdentifier  string               `json:"account_identifier"`
-	LabelName          string               `json:"label_name"`
-	Since              time.Time            `json:"since"`
-	Until              time.Time            `json:"until"`
-	Status             string               `json:"status"`
-	CurrentStage       *string              `json:"current_stage"`
-	QueuedAt           time.Time            `json:"queued_at"`
-	FinishedAt         *time.Time           `json:"finished_at"`
-	ErrorMessage       *string              `json:"error_message"`
-	Fetch              stageSummaryResponse `json:"fetch"`
-	Analysis           stageSummaryResponse `json:"analysis"`
-	VendorResolution   stageSummaryResponse `json:"vendor_resolution"`
-	BillingEligibility stageSummaryResponse `json:"billing_eligibility"`
-	Billing            stageSummaryResponse `json:"billing"`
+	WorkflowID         string                           `json:"workflow_id"`
+	RetryOfWorkflowID  *string                          `json:"retry_of_workflow_id"`
+	ParentWorkflowID   *string                          `json:"parent_workflow_id"`
+	FanOut             bool                             `json:"fan_out"`
+	DryRun             bool                             `json:"dry_run"`
+	Provider           string                           `json:"provider"`
+	AccountIdentifier  string                           `json:"account_identifier"`
+	LabelName          string                           `json:"label_name"`
+	Since              time.Time                        `json:"since"`
+	Until              time.Time                        `json:"until"`
+	Filter             *fetchFilterResponse             `json:"filter,omitempty"`
+	Status             string                           `json:"status"`
+	CurrentStage       *string                          `json:"current_stage"`
+	QueuedAt           time.Time                        `json:"queued_at"`
+	FinishedAt         *time.Time                       `json:"finished_at"`
+	ErrorMessage       *string                          `json:"error_message"`
+	Fetch              stageSummaryResponse             `json:"fetch"`
+	Analysis           stageSummaryResponse             `json:"analysis"`
+	VendorResolution   stageSummaryResponse             `json:"vendor_resolution"`
+	BillingEligibility stageSummaryResponse             `json:"billing_eligibility"`
+	Billing            stageSummaryResponse             `json:"billing"`
+	AdditionalStages   []additionalStageSummaryResponse `json:"additional_stages,omitempty"`
+	Timeline           []stageTimelineResponse          `json:"timeline"`
+}
+
+type fetchFilterResponse struct {
+	Query             string   `json:"query"`
+	IncludeLabelNames []string `json:"include_label_names"`
+	ExcludeLabelNames []string `json:"exclude_label_names"`
+}
+
+type additionalStageSummaryResponse struct {
+	Stage string `json:"stage"`
+	stageSummaryResponse
 }
 
 type stageSummaryResponse struct {
@@ -87,6 +138,145 @@ type stageFailureResponse struct {
 	CreatedAt         time.Time `json:"created_at"`
 }
 
+type detailResponse struct {
+	WorkflowID         string                         `json:"workflow_id"`
+	RetryOfWorkflowID  *string                        `json:"retry_of_workflow_id"`
+	ParentWorkflowID   *string                        `json:"parent_workflow_id"`
+	FanOut             bool                           `json:"fan_out"`
+	DryRun             bool                           `json:"dry_run"`
+	Provider           string                         `json:"provider"`
+	AccountIdentifier  string                         `json:"account_identifier"`
+	LabelName          string                         `json:"label_name"`
+	Since              time.Time                      `json:"since"`
+	Until              time.Time                      `json:"until"`
+	Filter             *fetchFilterResponse           `json:"filter,omitempty"`
+	Status             string                         `json:"status"`
+	CurrentStage       *string                        `json:"current_stage"`
+	QueuedAt           time.Time                      `json:"queued_at"`
+	FinishedAt         *time.Time                     `json:"finished_at"`
+	ErrorMessage       *string                        `json:"error_message"`
+	Fetch              stageCountResponse             `json:"fetch"`
+	Analysis           stageCountResponse             `json:"analysis"`
+	VendorResolution   stageCountResponse             `json:"vendor_resolution"`
+	BillingEligibility stageCountResponse             `json:"billing_eligibility"`
+	Billing            stageCountResponse             `json:"billing"`
+	AdditionalStages   []additionalStageCountResponse `json:"additional_stages,omitempty"`
+	Timeline           []stageTimelineResponse        `json:"timeline"`
+	Failures           []detailStageFailureResponse   `json:"failures"`
+	FailureTotalCount  int64                          `json:"failure_total_count"`
+	Children           []workflowChildResponse        `json:"children,omitempty"`
+}
+
+type workflowChildResponse struct {
+	WorkflowID        string     `json:"workflow_id"`
+	Provider          string     `json:"provider"`
+	AccountIdentifier string     `json:"account_identifier"`
+	Status            string     `json:"status"`
+	CurrentStage      *string    `json:"current_stage"`
+	FinishedAt        *time.Time `json:"finished_at"`
+	ErrorMessage      *string    `json:"error_message"`
+}
+
+type additionalStageCountResponse struct {
+	Stage string `json:"stage"`
+	stageCountResponse
+}
+
+type stageCountResponse struct {
+	SuccessCount          int `json:"success_count"`
+	BusinessFailureCount  int `json:"business_failure_count"`
+	TechnicalFailureCount int `json:"technical_failure_count"`
+}
+
+type stageTimelineResponse struct {
+	Stage          string     `json:"stage"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	DurationMillis *int64     `json:"duration_ms"`
+}
+
+type detailStageFailureResponse struct {
+	Stage             string    `json:"stage"`
+	ExternalMessageID *string   `json:"external_message_id"`
+	ReasonCode        string    `json:"reason_code"`
+	Message           string    `json:"message"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type previewResponse struct {
+	WorkflowID       string                      `json:"workflow_id"`
+	Status           string                      `json:"status"`
+	GeneratedAt      time.Time                   `json:"generated_at"`
+	WouldCreateCount int                         `json:"would_create_count"`
+	WouldCreateItems []previewBillingResponse    `json:"would_create_items"`
+	DuplicateItems   []previewDuplicateResponse  `json:"duplicate_items"`
+	UnresolvedItems  []previewUnresolvedResponse `json:"unresolved_items"`
+	IneligibleItems  []previewIneligibleResponse `json:"ineligible_items"`
+}
+
+type previewBillingResponse struct {
+	ExternalMessageID   string     `json:"external_message_id"`
+	VendorID            *uint      `json:"vendor_id"`
+	VendorName          string     `json:"vendor_name"`
+	VendorWouldRegister bool       `json:"vendor_would_register"`
+	MatchedBy           string     `json:"matched_by"`
+	ProductNameDisplay  *string    `json:"product_name_display"`
+	BillingNumber       string     `json:"billing_number"`
+	InvoiceNumber       *string    `json:"invoice_number"`
+	Amount              float64    `json:"amount"`
+	Currency            string     `json:"currency"`
+	BillingDate         *time.Time `json:"billing_date"`
+	PaymentCycle        string     `json:"payment_cycle"`
+}
+
+type previewDuplicateResponse struct {
+	previewBillingResponse
+	ExistingBillingID *uint  `json:"existing_billing_id"`
+	ReasonCode        string `json:"reason_code"`
+	Message           string `json:"message"`
+}
+
+type previewUnresolvedResponse struct {
+	ExternalMessageID   string `json:"external_message_id"`
+	CandidateVendorName string `json:"candidate_vendor_name"`
+	ReasonCode          string `json:"reason_code"`
+	Message             string `json:"message"`
+}
+
+type previewIneligibleResponse struct {
+	ExternalMessageID string `json:"external_message_id"`
+	VendorName        string `json:"vendor_name"`
+	ReasonCode        string `json:"reason_code"`
+	Message           string `json:"message"`
+}
+
+type workflowEventResponse struct {
+	WorkflowID     string                        `json:"workflow_id"`
+	Status         string                        `json:"status"`
+	CurrentStage   *string                       `json:"current_stage"`
+	Stage          string                        `json:"stage,omitempty"`
+	Counts         *stageCountResponse           `json:"counts,omitempty"`
+	FailureReasons []workflowEventReasonResponse `json:"failure_reasons,omitempty"`
+	Stages         *workflowEventStagesResponse  `json:"stages,omitempty"`
+	ErrorMessage   *string                       `json:"error_message"`
+	OccurredAt     time.Time                     `json:"occurred_at"`
+}
+
+type workflowEventReasonResponse struct {
+	ReasonCode string `json:"reason_code"`
+	Message    string `json:"message"`
+	Count      int    `json:"count"`
+}
+
+type workflowEventStagesResponse struct {
+	Fetch              stageCountResponse            `json:"fetch"`
+	Analysis           stageCountResponse            `json:"analysis"`
+	VendorResolution   stageCountResponse            `json:"vendor_resolution"`
+	BillingEligibility stageCountResponse            `json:"billing_eligibility"`
+	Billing            stageCountResponse            `json:"billing"`
+	Additional         map[string]stageCountResponse `json:"additional,omitempty"`
+}
+
 // Execute handles POST /api/v1/manual-mail-workflows.
 func (ctrl *Controller) Execute(c *gin.Context) {
 	reqLog := ctrl.log
@@ -104,6 +294,11 @@ func (ctrl *Controller) Execute(c *gin.Context) {
 		httpresponse.WriteInvalidRequest(c)
 		return
 	}
+	// connection_id と all_connections はどちらか一方だけを指定する。
+	if (req.ConnectionID == 0) != req.AllConnections {
+		httpresponse.WriteInvalidRequest(c)
+		return
+	}
 
 	if ctrl.startUseCase == nil {
 		reqLog.Error("manual_mail_workflow_start_usecase_not_configured")
@@ -112,21 +307,35 @@ func (ctrl *Controller) Execute(c *gin.Context) {
 	}
 
 	result, err := ctrl.startUseCase.Start(c.Request.Context(), manualapp.Command{
-		UserID:       uid,
-		ConnectionID: req.ConnectionID,
+		UserID:         uid,
+		ConnectionID:   req.ConnectionID,
+		AllConnections: req.AllConnections,
 		Condition: manualapp.FetchCondition{
 			LabelName: req.LabelName,
 			Since:     req.Since,
 			Until:     req.Until,
+			Filter: manualapp.FetchFilter{
+				Query:             req.Query,
+				IncludeLabelNames: req.IncludeLabelNames,
+				ExcludeLabelNames: req.ExcludeLabelNames,
+			},
 		},
+		DryRun: req.DryRun,
 	})
 	if err != nil {
 		ctrl.writeStartError(c, reqLog, uid, req.ConnectionID, err)
 		return
 	}
 
+	message := "メール取得ワークフローを受け付けました。"
+	if req.AllConnections {
+		message = "すべてのメール連携のメール取得ワークフローを受け付けました。"
+	}
+	if req.DryRun {
+		message = "メール取得ワークフローのプレビューを受け付けました。請求は作成されません。"
+	}
 	c.JSON(http.StatusAccepted, executeAcceptedResponse{
-		Message:    "メール取得ワークフローを受け付けました。",
+		Message:    message,
 		WorkflowID: result.WorkflowID,
 		Status:     result.Status,
 	})
@@ -181,10 +390,361 @@ func (ctrl *Controller) List(c *gin.Context) {
 	})
 }
 
+// Detail handles GET /api/v1/manual-mail-workflows/:workflow_id.
+func (ctrl *Controller) Detail(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if ctrl.detailUseCase == nil {
+		reqLog.Error("manual_mail_workflow_detail_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	query, err := buildDetailQuery(c, uid)
+	if err != nil {
+		httpresponse.WriteInvalidRequest(c)
+		return
+	}
+
+	detail, err := ctrl.detailUseCase.Get(c.Request.Context(), query)
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidDetailQuery):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrWorkflowHistoryNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "manual_mail_workflow_not_found", "対象のメール取得ワークフローは見つかりません。")
+		default:
+			reqLog.Error("manual_mail_workflow_detail_failed",
+				logger.UserID(uid),
+				logger.String("workflow_id", query.WorkflowID),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toDetailResponse(detail))
+}
+
+// Cancel handles POST /api/v1/manual-mail-workflows/:workflow_id/cancel.
+func (ctrl *Controller) Cancel(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if ctrl.cancelUseCase == nil {
+		reqLog.Error("manual_mail_workflow_cancel_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	workflowID := c.Param("workflow_id")
+	result, err := ctrl.cancelUseCase.Cancel(c.Request.Context(), manualapp.CancelCommand{
+		UserID:     uid,
+		WorkflowID: workflowID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrWorkflowHistoryNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "manual_mail_workflow_not_found", "対象のメール取得ワークフローは見つかりません。")
+		case errors.Is(err, manualapp.ErrWorkflowNotCancellable):
+			httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_not_cancellable", "完了済みのメール取得ワークフローはキャンセルできません。")
+		default:
+			reqLog.Error("manual_mail_workflow_cancel_failed",
+				logger.UserID(uid),
+				logger.String("workflow_id", workflowID),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	if result.Status == manualapp.WorkflowStatusCancelled {
+		c.JSON(http.StatusOK, cancelResponse{
+			Message:    "メール取得ワークフローをキャンセルしました。",
+			WorkflowID: result.WorkflowID,
+			Status:     result.Status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, cancelResponse{
+		Message:    "メール取得ワークフローのキャンセルを受け付けました。実行中の処理が止まり次第キャンセルされます。",
+		WorkflowID: result.WorkflowID,
+		Status:     result.Status,
+	})
+}
+
+// Retry handles POST /api/v1/manual-mail-workflows/:workflow_id/retry.
+func (ctrl *Controller) Retry(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if ctrl.retryUseCase == nil {
+		reqLog.Error("manual_mail_workflow_retry_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	workflowID := c.Param("workflow_id")
+	result, err := ctrl.retryUseCase.Retry(c.Request.Context(), manualapp.RetryCommand{
+		UserID:     uid,
+		WorkflowID: workflowID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrWorkflowHistoryNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "manual_mail_workflow_not_found", "対象のメール取得ワークフローは見つかりません。")
+		case errors.Is(err, manualapp.ErrWorkflowFanOutParent):
+			writeFanOutParentError(c)
+		case errors.Is(err, manualapp.ErrWorkflowNotRetryable):
+			httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_not_retryable", "完了していない、または失敗のないメール取得ワークフローは再実行できません。")
+		case errors.Is(err, manualapp.ErrWorkflowNoRetryTargets):
+			httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_no_retry_targets", "再実行できる失敗メールがありません。")
+		case errors.Is(err, manualapp.ErrWorkflowRetryConnectionUnavailable):
+			httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_retry_connection_unavailable", "メールアカウント連携が無効になっているため再実行できません。再連携してください。")
+		case errors.Is(err, manualapp.ErrWorkflowConflict):
+			writeWorkflowConflictError(c)
+		default:
+			reqLog.Error("manual_mail_workflow_retry_failed",
+				logger.UserID(uid),
+				logger.String("workflow_id", workflowID),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, executeAcceptedResponse{
+		Message:    "失敗したメールの再実行を受け付けました。",
+		WorkflowID: result.WorkflowID,
+		Status:     result.Status,
+	})
+}
+
+// Resume handles POST /api/v1/manual-mail-workflows/:workflow_id/resume.
+// The failed workflow is re-queued under the same workflow_id and continues after its last finished stage.
+func (ctrl *Controller) Resume(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if ctrl.resumeUseCase == nil {
+		reqLog.Error("manual_mail_workflow_resume_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	workflowID := c.Param("workflow_id")
+	result, err := ctrl.resumeUseCase.Resume(c.Request.Context(), manualapp.ResumeCommand{
+		UserID:     uid,
+		WorkflowID: workflowID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrWorkflowHistoryNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "manual_mail_workflow_not_found", "対象のメール取得ワークフローは見つかりません。")
+		case errors.Is(err, manualapp.ErrWorkflowFanOutParent):
+			writeFanOutParentError(c)
+		case errors.Is(err, manualapp.ErrWorkflowNotResumable):
+			httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_not_resumable", "メール解析まで完了していない、または失敗していないメール取得ワークフローは再開できません。")
+		case errors.Is(err, manualapp.ErrWorkflowConflict):
+			writeWorkflowConflictError(c)
+		default:
+			reqLog.Error("manual_mail_workflow_resume_failed",
+				logger.UserID(uid),
+				logger.String("workflow_id", workflowID),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, executeAcceptedResponse{
+		Message:    "メール取得ワークフローの再開を受け付けました。",
+		WorkflowID: result.WorkflowID,
+		Status:     result.Status,
+	})
+}
+
+// Preview handles GET /api/v1/manual-mail-workflows/:workflow_id/preview.
+// It returns what a dry-run workflow would have created once the dry run has completed.
+func (ctrl *Controller) Preview(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if ctrl.previewUseCase == nil {
+		reqLog.Error("manual_mail_workflow_preview_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	workflowID := c.Param("workflow_id")
+	view, err := ctrl.previewUseCase.Get(c.Request.Context(), manualapp.PreviewQuery{
+		UserID:     uid,
+		WorkflowID: workflowID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrWorkflowHistoryNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "manual_mail_workflow_not_found", "対象のメール取得ワークフローは見つかりません。")
+		case errors.Is(err, manualapp.ErrWorkflowFanOutParent):
+			writeFanOutParentError(c)
+		case errors.Is(err, manualapp.ErrWorkflowNotDryRun):
+			httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_not_dry_run", "プレビューはドライラン実行のメール取得ワークフローでのみ確認できます。")
+		case errors.Is(err, manualapp.ErrWorkflowPreviewNotReady):
+			httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_preview_not_ready", "ドライランが完了していないため、プレビューはまだ確認できません。")
+		default:
+			reqLog.Error("manual_mail_workflow_preview_failed",
+				logger.UserID(uid),
+				logger.String("workflow_id", workflowID),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toPreviewResponse(view))
+}
+
+// Events handles GET /api/v1/manual-mail-workflows/:workflow_id/events as Server-Sent Events.
+// It sends a snapshot first, then one event per transition until the workflow finishes or the client disconnects.
+func (ctrl *Controller) Events(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if ctrl.eventsUseCase == nil {
+		reqLog.Error("manual_mail_workflow_events_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	workflowID := c.Param("workflow_id")
+	stream, err := ctrl.eventsUseCase.Subscribe(c.Request.Context(), manualapp.EventsQuery{
+		UserID:     uid,
+		WorkflowID: workflowID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidEventsQuery):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrWorkflowHistoryNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "manual_mail_workflow_not_found", "対象のメール取得ワークフローは見つかりません。")
+		default:
+			reqLog.Error("manual_mail_workflow_events_subscribe_failed",
+				logger.UserID(uid),
+				logger.String("workflow_id", workflowID),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+	if stream.Subscription != nil {
+		defer func() {
+			if err := stream.Subscription.Close(); err != nil {
+				reqLog.Warn("manual_mail_workflow_events_close_failed",
+					logger.String("workflow_id", workflowID),
+					logger.Err(err),
+				)
+			}
+		}()
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	writeWorkflowEvent(c, workflowID, stream.Snapshot)
+	if stream.Subscription == nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(workflowEventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	events := stream.Subscription.Events()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, _ = c.Writer.WriteString(": keepalive\n\n")
+			c.Writer.Flush()
+		case event, ok := <-events:
+			if !ok {
+				// 購読が切れた場合は stream を閉じ、EventSource の再接続で snapshot から取り直させる。
+				return
+			}
+			writeWorkflowEvent(c, workflowID, event)
+			if event.IsTerminal() {
+				return
+			}
+		}
+	}
+}
+
 func (ctrl *Controller) writeStartError(c *gin.Context, reqLog logger.Interface, userID, connectionID uint, err error) {
 	switch {
 	case errors.Is(err, manualapp.ErrInvalidCommand), errors.Is(err, manualapp.ErrFetchConditionInvalid):
 		httpresponse.WriteInvalidRequest(c)
+	case errors.Is(err, manualapp.ErrLabelNotFound):
+		httpresponse.WriteError(c, http.StatusBadRequest, "manual_mail_workflow_label_not_found", "指定したラベルがメール連携に見つかりません。ラベル名を確認してください。")
+	case errors.Is(err, manualapp.ErrFetchFilterUnsupported):
+		httpresponse.WriteError(c, http.StatusBadRequest, "manual_mail_workflow_fetch_filter_unsupported", "検索クエリとラベルの絞り込みはGmail連携でのみ指定できます。")
+	case errors.Is(err, manualapp.ErrWorkflowConflict):
+		writeWorkflowConflictError(c)
+	case errors.Is(err, manualapp.ErrNoUsableConnection):
+		httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_no_usable_connection", "利用できるメールアカウント連携がありません。メールアカウントを連携してください。")
 	default:
 		reqLog.Error("manual_mail_workflow_start_failed",
 			logger.UserID(userID),
@@ -195,6 +755,14 @@ func (ctrl *Controller) writeStartError(c *gin.Context, reqLog logger.Interface,
 	}
 }
 
+func writeWorkflowConflictError(c *gin.Context) {
+	httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_conflict", "同じメール連携・ラベル・期間のメール取得ワークフローが実行中です。完了後に再度お試しください。")
+}
+
+func writeFanOutParentError(c *gin.Context) {
+	httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_fan_out_parent", "すべてのメール連携をまとめたワークフローには実行できません。メール連携ごとのワークフローを指定してください。")
+}
+
 func currentUserID(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -241,14 +809,288 @@ func buildListQuery(c *gin.Context, userID uint) (manualapp.ListQuery, error) {
 	return query, nil
 }
 
+func buildDetailQuery(c *gin.Context, userID uint) (manualapp.DetailQuery, error) {
+	query := manualapp.DetailQuery{
+		UserID:     userID,
+		WorkflowID: c.Param("workflow_id"),
+		Sort:       c.Query("sort"),
+	}
+
+	if rawLimit, exists := c.GetQuery("limit"); exists {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil {
+			return manualapp.DetailQuery{}, err
+		}
+		query.Limit = limit
+		query.HasLimit = true
+	}
+
+	if rawOffset, exists := c.GetQuery("offset"); exists {
+		offset, err := strconv.Atoi(rawOffset)
+		if err != nil {
+			return manualapp.DetailQuery{}, err
+		}
+		query.Offset = offset
+		query.HasOffset = true
+	}
+
+	if rawStage, exists := c.GetQuery("stage"); exists {
+		query.Stage = &rawStage
+	}
+	if rawReasonCode, exists := c.GetQuery("reason_code"); exists {
+		query.ReasonCode = &rawReasonCode
+	}
+
+	return query, nil
+}
+
+func toDetailResponse(detail manualapp.WorkflowHistoryDetail) detailResponse {
+	failures := make([]detailStageFailureResponse, 0, len(detail.Failures))
+	for _, failure := range detail.Failures {
+		failures = append(failures, detailStageFailureResponse{
+			Stage:             failure.Stage,
+			ExternalMessageID: cloneOptionalString(failure.ExternalMessageID),
+			ReasonCode:        failure.ReasonCode,
+			Message:           failure.Message,
+			CreatedAt:         failure.CreatedAt,
+		})
+	}
+
+	return detailResponse{
+		WorkflowID:         detail.WorkflowID,
+		RetryOfWorkflowID:  cloneOptionalString(detail.RetryOfWorkflowID),
+		ParentWorkflowID:   cloneOptionalString(detail.ParentWorkflowID),
+		FanOut:             detail.FanOut,
+		DryRun:             detail.DryRun,
+		Provider:           detail.Provider,
+		AccountIdentifier:  detail.AccountIdentifier,
+		LabelName:          detail.LabelName,
+		Since:              detail.Since,
+		Until:              detail.Until,
+		Filter:             toFetchFilterResponse(detail.Filter),
+		Status:             detail.Status,
+		CurrentStage:       cloneOptionalString(detail.CurrentStage),
+		QueuedAt:           detail.QueuedAt,
+		FinishedAt:         cloneOptionalTime(detail.FinishedAt),
+		ErrorMessage:       cloneOptionalString(detail.ErrorMessage),
+		Fetch:              toStageCountResponse(detail.Fetch),
+		Analysis:           toStageCountResponse(detail.Analysis),
+		VendorResolution:   toStageCountResponse(detail.VendorResolution),
+		BillingEligibility: toStageCountResponse(detail.BillingEligibility),
+		Billing:            toStageCountResponse(detail.Billing),
+		AdditionalStages:   toAdditionalStageCountResponses(detail.AdditionalStages),
+		Timeline:           toStageTimelineResponses(detail.Timeline),
+		Failures:           failures,
+		FailureTotalCount:  detail.FailureTotalCount,
+		Children:           toWorkflowChildResponses(detail.Children),
+	}
+}
+
+func toAdditionalStageCountResponses(stages []manualapp.AdditionalStageCountView) []additionalStageCountResponse {
+	if len(stages) == 0 {
+		return nil
+	}
+
+	responses := make([]additionalStageCountResponse, 0, len(stages))
+	for _, stage := range stages {
+		responses = append(responses, additionalStageCountResponse{
+			Stage:              stage.Stage,
+			stageCountResponse: toStageCountResponse(stage.StageCountView),
+		})
+	}
+	return responses
+}
+
+func toStageTimelineResponses(timeline []manualapp.StageTimelineView) []stageTimelineResponse {
+	responses := make([]stageTimelineResponse, 0, len(timeline))
+	for _, entry := range timeline {
+		var durationMillis *int64
+		if entry.DurationMillis != nil {
+			cloned := *entry.DurationMillis
+			durationMillis = &cloned
+		}
+		responses = append(responses, stageTimelineResponse{
+			Stage:          entry.Stage,
+			StartedAt:      entry.StartedAt,
+			FinishedAt:     cloneOptionalTime(entry.FinishedAt),
+			DurationMillis: durationMillis,
+		})
+	}
+	return responses
+}
+
+func toWorkflowChildResponses(children []manualapp.WorkflowChildSummary) []workflowChildResponse {
+	if len(children) == 0 {
+		return nil
+	}
+
+	responses := make([]workflowChildResponse, 0, len(children))
+	for _, child := range children {
+		responses = append(responses, workflowChildResponse{
+			WorkflowID:        child.WorkflowID,
+			Provider:          child.Provider,
+			AccountIdentifier: child.AccountIdentifier,
+			Status:            child.Status,
+			CurrentStage:      cloneOptionalString(child.CurrentStage),
+			FinishedAt:        cloneOptionalTime(child.FinishedAt),
+			ErrorMessage:      cloneOptionalString(child.ErrorMessage),
+		})
+	}
+	return responses
+}
+
+func toPreviewResponse(view manualapp.WorkflowPreviewView) previewResponse {
+	preview := view.Preview
+	response := previewResponse{
+		WorkflowID:       view.WorkflowID,
+		Status:           view.Status,
+		GeneratedAt:      preview.GeneratedAt,
+		WouldCreateCount: len(preview.WouldCreateItems),
+		WouldCreateItems: make([]previewBillingResponse, 0, len(preview.WouldCreateItems)),
+		DuplicateItems:   make([]previewDuplicateResponse, 0, len(preview.DuplicateItems)),
+		UnresolvedItems:  make([]previewUnresolvedResponse, 0, len(preview.UnresolvedItems)),
+		IneligibleItems:  make([]previewIneligibleResponse, 0, len(preview.IneligibleItems)),
+	}
+	for _, item := range preview.WouldCreateItems {
+		response.WouldCreateItems = append(response.WouldCreateItems, toPreviewBillingResponse(item))
+	}
+	for _, item := range preview.DuplicateItems {
+		response.DuplicateItems = append(response.DuplicateItems, previewDuplicateResponse{
+			previewBillingResponse: toPreviewBillingResponse(item.PreviewBillingItem),
+			ExistingBillingID:      optionalNonZeroUint(item.ExistingBillingID),
+			ReasonCode:             item.ReasonCode,
+			Message:                item.Message,
+		})
+	}
+	for _, item := range preview.UnresolvedItems {
+		response.UnresolvedItems = append(response.UnresolvedItems, previewUnresolvedResponse{
+			ExternalMessageID:   item.ExternalMessageID,
+			CandidateVendorName: item.CandidateVendorName,
+			ReasonCode:          item.ReasonCode,
+			Message:             item.Message,
+		})
+	}
+	for _, item := range preview.IneligibleItems {
+		response.IneligibleItems = append(response.IneligibleItems, previewIneligibleResponse{
+			ExternalMessageID: item.ExternalMessageID,
+			VendorName:        item.VendorName,
+			ReasonCode:        item.ReasonCode,
+			Message:           item.Message,
+		})
+	}
+
+	return response
+}
+
+func toPreviewBillingResponse(item manualapp.PreviewBillingItem) previewBillingResponse {
+	return previewBillingResponse{
+		ExternalMessageID:   item.ExternalMessageID,
+		VendorID:            optionalNonZeroUint(item.VendorID),
+		VendorName:          item.VendorName,
+		VendorWouldRegister: item.VendorWouldRegister,
+		MatchedBy:           item.MatchedBy,
+		ProductNameDisplay:  cloneOptionalString(item.ProductNameDisplay),
+		BillingNumber:       item.BillingNumber,
+		InvoiceNumber:       cloneOptionalString(item.InvoiceNumber),
+		Amount:              item.Amount,
+		Currency:            item.Currency,
+		BillingDate:         cloneOptionalTime(item.BillingDate),
+		PaymentCycle:        item.PaymentCycle,
+	}
+}
+
+func writeWorkflowEvent(c *gin.Context, workflowID string, event manualapp.WorkflowEvent) {
+	c.SSEvent(event.Type, toWorkflowEventResponse(workflowID, event))
+	c.Writer.Flush()
+}
+
+func toWorkflowEventResponse(workflowID string, event manualapp.WorkflowEvent) workflowEventResponse {
+	response := workflowEventResponse{
+		WorkflowID:   workflowID,
+		Status:       event.Status,
+		CurrentStage: optionalNonEmptyString(event.CurrentStage),
+		Stage:        event.Stage,
+		ErrorMessage: optionalNonEmptyString(event.ErrorMessage),
+		OccurredAt:   event.OccurredAt,
+	}
+
+	switch event.Type {
+	case manualapp.WorkflowEventSnapshot:
+		response.Stages = &workflowEventStagesResponse{
+			Fetch:              toStageCountResponse(event.Stages["fetch"]),
+			Analysis:           toStageCountResponse(event.Stages["analysis"]),
+			VendorResolution:   toStageCountResponse(event.Stages["vendorresolution"]),
+			BillingEligibility: toStageCountResponse(event.Stages["billingeligibility"]),
+			Billing:            toStageCountResponse(event.Stages["billing"]),
+		}
+		for stage, counts := range event.Stages {
+			if isBuiltinEventStage(stage) {
+				continue
+			}
+			if response.Stages.Additional == nil {
+				response.Stages.Additional = make(map[string]stageCountResponse)
+			}
+			response.Stages.Additional[stage] = toStageCountResponse(counts)
+		}
+	case manualapp.WorkflowEventStageProgress:
+		counts := toStageCountResponse(event.Counts)
+		response.Counts = &counts
+		for _, reason := range event.FailureReasons {
+			response.FailureReasons = append(response.FailureReasons, workflowEventReasonResponse{
+				ReasonCode: reason.ReasonCode,
+				Message:    reason.Message,
+				Count:      reason.Count,
+			})
+		}
+	}
+
+	return response
+}
+
+func isBuiltinEventStage(stage string) bool {
+	switch stage {
+	case "fetch", "analysis", "vendorresolution", "billingeligibility", "billing":
+		return true
+	default:
+		return false
+	}
+}
+
+func optionalNonEmptyString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func optionalNonZeroUint(value uint) *uint {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+func toStageCountResponse(counts manualapp.StageCountView) stageCountResponse {
+	return stageCountResponse{
+		SuccessCount:          counts.SuccessCount,
+		BusinessFailureCount:  counts.BusinessFailureCount,
+		TechnicalFailureCount: counts.TechnicalFailureCount,
+	}
+}
+
 func toWorkflowHistoryItemResponse(item manualapp.WorkflowHistoryListItem) workflowHistoryItemResponse {
 	return workflowHistoryItemResponse{
 		WorkflowID:         item.WorkflowID,
+		RetryOfWorkflowID:  cloneOptionalString(item.RetryOfWorkflowID),
+		ParentWorkflowID:   cloneOptionalString(item.ParentWorkflowID),
+		FanOut:             item.FanOut,
+		DryRun:             item.DryRun,
 		Provider:           item.Provider,
 		AccountIdentifier:  item.AccountIdentifier,
 		LabelName:          item.LabelName,
 		Since:              item.Since,
 		Until:              item.Until,
+		Filter:             toFetchFilterResponse(item.Filter),
 		Status:             item.Status,
 		CurrentStage:       cloneOptionalString(item.CurrentStage),
 		QueuedAt:           item.QueuedAt,
@@ -259,7 +1101,36 @@ func toWorkflowHistoryItemResponse(item manualapp.WorkflowHistoryListItem) workf
 		VendorResolution:   toStageSummaryResponse(item.VendorResolution),
 		BillingEligibility: toStageSummaryResponse(item.BillingEligibility),
 		Billing:            toStageSummaryResponse(item.Billing),
+		AdditionalStages:   toAdditionalStageSummaryResponses(item.AdditionalStages),
+		Timeline:           toStageTimelineResponses(item.Timeline),
+	}
+}
+
+// toFetchFilterResponse omits the filter of a workflow that fetched the whole label.
+func toFetchFilterResponse(filter manualapp.FetchFilter) *fetchFilterResponse {
+	if filter.IsZero() {
+		return nil
+	}
+	return &fetchFilterResponse{
+		Query:             filter.Query,
+		IncludeLabelNames: append([]string{}, filter.IncludeLabelNames...),
+		ExcludeLabelNames: append([]string{}, filter.ExcludeLabelNames...),
+	}
+}
+
+func toAdditionalStageSummaryResponses(stages []manualapp.AdditionalStageSummaryView) []additionalStageSummaryResponse {
+	if len(stages) == 0 {
+		return nil
+	}
+
+	responses := make([]additionalStageSummaryResponse, 0, len(stages))
+	for _, stage := range stages {
+		responses = append(responses, additionalStageSummaryResponse{
+			Stage:                stage.Stage,
+			stageSummaryResponse: toStageSummaryResponse(stage.StageSummaryView),
+		})
 	}
+	return responses
 }
 
 func toStageSummaryResponse(summary manualapp.StageSummaryView) stageSummaryResponse {
@@ -4,6 +4,7 @@ import (
 	manualapp "business/internal/manualmailworkflow/application"
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -69,6 +70,140 @@ func TestExecute_202(t *testing.T) {
 	uc.AssertExpectations(t)
 }
 
+func TestExecute_202_DryRun(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockUseCase)
+	uc.On("Start", mock.Anything, manualapp.Command{
+		UserID:       1,
+		ConnectionID: 12,
+		Condition: manualapp.FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+		DryRun: true,
+	}).Return(manualapp.StartResult{
+		WorkflowID: "wf-123",
+		Status:     manualapp.WorkflowStatusQueued,
+	}, nil).Once()
+
+	r := executeRouter(newTestController(uc, nil))
+
+	body := []byte(`{"connection_id":12,"label_name":"billing","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z","dry_run":true}`)
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "メール取得ワークフローのプレビューを受け付けました。請求は作成されません。",
+		"workflow_id": "wf-123",
+		"status": "queued"
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestExecute_202_WithFetchFilter(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockUseCase)
+	uc.On("Start", mock.Anything, manualapp.Command{
+		UserID:       1,
+		ConnectionID: 12,
+		Condition: manualapp.FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+			Filter: manualapp.FetchFilter{
+				Query:             "from:billing@example.com",
+				IncludeLabelNames: []string{"receipts"},
+				ExcludeLabelNames: []string{"archived"},
+			},
+		},
+	}).Return(manualapp.StartResult{
+		WorkflowID: "wf-123",
+		Status:     manualapp.WorkflowStatusQueued,
+	}, nil).Once()
+
+	r := executeRouter(newTestController(uc, nil))
+
+	body := []byte(`{"connection_id":12,"label_name":"billing","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z","query":"from:billing@example.com","include_label_names":["receipts"],"exclude_label_names":["archived"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestExecute_202_AllConnections(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockUseCase)
+	uc.On("Start", mock.Anything, manualapp.Command{
+		UserID:         1,
+		AllConnections: true,
+		Condition: manualapp.FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	}).Return(manualapp.StartResult{
+		WorkflowID: "wf-parent",
+		Status:     manualapp.WorkflowStatusQueued,
+	}, nil).Once()
+
+	r := executeRouter(newTestController(uc, nil))
+
+	body := []byte(`{"all_connections":true,"label_name":"billing","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z"}`)
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "すべてのメール連携のメール取得ワークフローを受け付けました。",
+		"workflow_id": "wf-parent",
+		"status": "queued"
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestExecute_400_ConnectionTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "neither", body: `{"label_name":"billing","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z"}`},
+		{name: "both", body: `{"connection_id":12,"all_connections":true,"label_name":"billing","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockUseCase)
+			r := executeRouter(newTestController(uc, nil))
+
+			req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			assert.Contains(t, resp.Body.String(), "invalid_request")
+			uc.AssertNotCalled(t, "Start", mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestExecute_400_InvalidRequest(t *testing.T) {
 	t.Parallel()
 
@@ -125,6 +260,83 @@ func TestExecute_500_StartFailure(t *testing.T) {
 	uc.AssertExpectations(t)
 }
 
+func TestExecute_400_LabelNotFound(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockUseCase)
+	uc.On("Start", mock.Anything, mock.Anything).Return(manualapp.StartResult{}, fmt.Errorf("%w: biling", manualapp.ErrLabelNotFound)).Once()
+
+	r := executeRouter(newTestController(uc, nil))
+
+	body := []byte(`{"connection_id":12,"label_name":"biling","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z"}`)
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "manual_mail_workflow_label_not_found")
+	uc.AssertExpectations(t)
+}
+
+func TestExecute_400_FetchFilterUnsupported(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockUseCase)
+	uc.On("Start", mock.Anything, mock.Anything).Return(manualapp.StartResult{}, fmt.Errorf("%w: imap", manualapp.ErrFetchFilterUnsupported)).Once()
+
+	r := executeRouter(newTestController(uc, nil))
+
+	body := []byte(`{"connection_id":12,"label_name":"INBOX","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z","query":"from:shop@example.com"}`)
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "manual_mail_workflow_fetch_filter_unsupported")
+	uc.AssertExpectations(t)
+}
+
+func TestExecute_409_Conflict(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockUseCase)
+	uc.On("Start", mock.Anything, mock.Anything).Return(manualapp.StartResult{}, manualapp.ErrWorkflowConflict).Once()
+
+	ctrl := newTestController(uc, nil)
+	r := executeRouter(ctrl)
+
+	body := []byte(`{"connection_id":12,"label_name":"billing","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z"}`)
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), "manual_mail_workflow_conflict")
+	uc.AssertExpectations(t)
+}
+
+func TestExecute_409_NoUsableConnection(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockUseCase)
+	uc.On("Start", mock.Anything, mock.Anything).Return(manualapp.StartResult{}, manualapp.ErrNoUsableConnection).Once()
+
+	r := executeRouter(newTestController(uc, nil))
+
+	body := []byte(`{"all_connections":true,"label_name":"billing","since":"2026-03-24T00:00:00Z","until":"2026-03-25T00:00:00Z"}`)
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), "manual_mail_workflow_no_usable_connection")
+	uc.AssertExpectations(t)
+}
+
 func TestList_200(t *testing.T) {
 	t.Parallel()
 
@@ -178,6 +390,10 @@ func TestList_200(t *testing.T) {
 		"items": [
 			{
 				"workflow_id": "wf-123",
+				"retry_of_workflow_id": null,
+				"parent_workflow_id": null,
+				"fan_out": false,
+				"dry_run": false,
 				"provider": "gmail",
 				"account_identifier": "billing@example.com",
 				"label_name": "billing",
@@ -224,7 +440,8 @@ func TestList_200(t *testing.T) {
 					"business_failure_count": 0,
 					"technical_failure_count": 0,
 					"failures": []
-				}
+				},
+				"timeline": []
 			}
 		],
 		"total_count": 57
@@ -343,3 +560,713 @@ func timePtr(value time.Time) *time.Time {
 func stringPtr(value string) *string {
 	return &value
 }
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func cancelRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.POST("/manual-mail-workflows/:workflow_id/cancel", func(c *gin.Context) { setUserID(c, 1) }, ctrl.Cancel)
+	return r
+}
+
+func TestCancel_200_QueuedWorkflow(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockCancelUseCase)
+	uc.On("Cancel", mock.Anything, manualapp.CancelCommand{
+		UserID:     1,
+		WorkflowID: "wf-123",
+	}).Return(manualapp.CancelResult{
+		WorkflowID: "wf-123",
+		Status:     manualapp.WorkflowStatusCancelled,
+	}, nil).Once()
+
+	r := cancelRouter(newCancelTestController(uc))
+
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows/wf-123/cancel", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "メール取得ワークフローをキャンセルしました。",
+		"workflow_id": "wf-123",
+		"status": "cancelled"
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestCancel_202_RunningWorkflow(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockCancelUseCase)
+	uc.On("Cancel", mock.Anything, mock.Anything).Return(manualapp.CancelResult{
+		WorkflowID: "wf-123",
+		Status:     manualapp.WorkflowStatusRunning,
+	}, nil).Once()
+
+	r := cancelRouter(newCancelTestController(uc))
+
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows/wf-123/cancel", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"status":"running"`)
+	uc.AssertExpectations(t)
+}
+
+func TestCancel_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "not found", err: manualapp.ErrWorkflowHistoryNotFound, wantStatus: http.StatusNotFound, wantCode: "manual_mail_workflow_not_found"},
+		{name: "not cancellable", err: manualapp.ErrWorkflowNotCancellable, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_not_cancellable"},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockCancelUseCase)
+			uc.On("Cancel", mock.Anything, mock.Anything).Return(manualapp.CancelResult{}, tt.err).Once()
+
+			r := cancelRouter(newCancelTestController(uc))
+
+			req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows/wf-123/cancel", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func detailRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.GET("/manual-mail-workflows/:workflow_id", func(c *gin.Context) { setUserID(c, 7) }, ctrl.Detail)
+	return r
+}
+
+func TestDetail_200(t *testing.T) {
+	t.Parallel()
+
+	queuedAt := time.Date(2026, 3, 25, 17, 0, 0, 0, time.UTC)
+	uc := new(mockDetailUseCase)
+	uc.On("Get", mock.Anything, manualapp.DetailQuery{
+		UserID:     7,
+		WorkflowID: "wf-123",
+		Stage:      stringPtr("analysis"),
+		ReasonCode: stringPtr("analysis_failed"),
+		Sort:       manualapp.WorkflowFailureSortCreatedAtDesc,
+		Limit:      10,
+		Offset:     20,
+		HasLimit:   true,
+		HasOffset:  true,
+	}).Return(manualapp.WorkflowHistoryDetail{
+		WorkflowID:        "wf-123",
+		RetryOfWorkflowID: stringPtr("wf-100"),
+		Provider:          "gmail",
+		AccountIdentifier: "billing@example.com",
+		LabelName:         "billing",
+		Since:             time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+		Until:             time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		Status:            manualapp.WorkflowStatusPartialSuccess,
+		QueuedAt:          queuedAt,
+		FinishedAt:        timePtr(queuedAt.Add(12 * time.Second)),
+		Fetch:             manualapp.StageCountView{SuccessCount: 3},
+		Analysis:          manualapp.StageCountView{SuccessCount: 2, TechnicalFailureCount: 1},
+		AdditionalStages: []manualapp.AdditionalStageCountView{
+			{Stage: "categorization", StageCountView: manualapp.StageCountView{SuccessCount: 2}},
+		},
+		Timeline: []manualapp.StageTimelineView{
+			{
+				Stage:          "fetch",
+				StartedAt:      queuedAt.Add(time.Second),
+				FinishedAt:     timePtr(queuedAt.Add(3 * time.Second)),
+				DurationMillis: int64Ptr(2000),
+			},
+			{
+				Stage:          "analysis",
+				StartedAt:      queuedAt.Add(3 * time.Second),
+				FinishedAt:     timePtr(queuedAt.Add(11*time.Second + 500*time.Millisecond)),
+				DurationMillis: int64Ptr(8500),
+			},
+		},
+		Failures: []manualapp.WorkflowStageFailureItem{
+			{
+				Stage:             "analysis",
+				ExternalMessageID: stringPtr("msg-3"),
+				ReasonCode:        "analysis_failed",
+				Message:           "メール解析に失敗しました。",
+				CreatedAt:         queuedAt.Add(5 * time.Second),
+			},
+		},
+		FailureTotalCount: 21,
+	}, nil).Once()
+
+	r := detailRouter(newDetailTestController(uc))
+
+	req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflows/wf-123?stage=analysis&reason_code=analysis_failed&sort=created_at_desc&limit=10&offset=20", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	assert.JSONEq(t, `{
+		"workflow_id": "wf-123",
+		"retry_of_workflow_id": "wf-100",
+		"parent_workflow_id": null,
+		"fan_out": false,
+		"dry_run": false,
+		"provider": "gmail",
+		"account_identifier": "billing@example.com",
+		"label_name": "billing",
+		"since": "2026-03-24T00:00:00Z",
+		"until": "2026-03-25T00:00:00Z",
+		"status": "partial_success",
+		"current_stage": null,
+		"queued_at": "2026-03-25T17:00:00Z",
+		"finished_at": "2026-03-25T17:00:12Z",
+		"error_message": null,
+		"fetch": {
+			"success_count": 3,
+			"business_failure_count": 0,
+			"technical_failure_count": 0
+		},
+		"analysis": {
+			"success_count": 2,
+			"business_failure_count": 0,
+			"technical_failure_count": 1
+		},
+		"vendor_resolution": {
+			"success_count": 0,
+			"business_failure_count": 0,
+			"technical_failure_count": 0
+		},
+		"billing_eligibility": {
+			"success_count": 0,
+			"business_failure_count": 0,
+			"technical_failure_count": 0
+		},
+		"billing": {
+			"success_count": 0,
+			"business_failure_count": 0,
+			"technical_failure_count": 0
+		},
+		"additional_stages": [
+			{
+				"stage": "categorization",
+				"success_count": 2,
+				"business_failure_count": 0,
+				"technical_failure_count": 0
+			}
+		],
+		"timeline": [
+			{
+				"stage": "fetch",
+				"started_at": "2026-03-25T17:00:01Z",
+				"finished_at": "2026-03-25T17:00:03Z",
+				"duration_ms": 2000
+			},
+			{
+				"stage": "analysis",
+				"started_at": "2026-03-25T17:00:03Z",
+				"finished_at": "2026-03-25T17:00:11.5Z",
+				"duration_ms": 8500
+			}
+		],
+		"failures": [
+			{
+				"stage": "analysis",
+				"external_message_id": "msg-3",
+				"reason_code": "analysis_failed",
+				"message": "メール解析に失敗しました。",
+				"created_at": "2026-03-25T17:00:05Z"
+			}
+		],
+		"failure_total_count": 21
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestDetail_400_InvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockDetailUseCase)
+	r := detailRouter(newDetailTestController(uc))
+
+	req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflows/wf-123?limit=abc", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	uc.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestDetail_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid query", err: manualapp.ErrInvalidDetailQuery, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "not found", err: manualapp.ErrWorkflowHistoryNotFound, wantStatus: http.StatusNotFound, wantCode: "manual_mail_workflow_not_found"},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockDetailUseCase)
+			uc.On("Get", mock.Anything, mock.Anything).Return(manualapp.WorkflowHistoryDetail{}, tt.err).Once()
+
+			r := detailRouter(newDetailTestController(uc))
+
+			req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflows/wf-123", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func retryRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.POST("/manual-mail-workflows/:workflow_id/retry", func(c *gin.Context) { setUserID(c, 1) }, ctrl.Retry)
+	return r
+}
+
+func TestRetry_202(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockRetryUseCase)
+	uc.On("Retry", mock.Anything, manualapp.RetryCommand{
+		UserID:     1,
+		WorkflowID: "wf-123",
+	}).Return(manualapp.StartResult{
+		WorkflowID: "wf-456",
+		Status:     manualapp.WorkflowStatusQueued,
+	}, nil).Once()
+
+	r := retryRouter(newRetryTestController(uc))
+
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows/wf-123/retry", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "失敗したメールの再実行を受け付けました。",
+		"workflow_id": "wf-456",
+		"status": "queued"
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestRetry_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid", err: manualapp.ErrInvalidCommand, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "not found", err: manualapp.ErrWorkflowHistoryNotFound, wantStatus: http.StatusNotFound, wantCode: "manual_mail_workflow_not_found"},
+		{name: "not retryable", err: manualapp.ErrWorkflowNotRetryable, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_not_retryable"},
+		{name: "fan-out parent", err: manualapp.ErrWorkflowFanOutParent, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_fan_out_parent"},
+		{name: "no targets", err: manualapp.ErrWorkflowNoRetryTargets, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_no_retry_targets"},
+		{name: "connection unavailable", err: manualapp.ErrWorkflowRetryConnectionUnavailable, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_retry_connection_unavailable"},
+		{name: "active workflow overlaps", err: manualapp.ErrWorkflowConflict, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_conflict"},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockRetryUseCase)
+			uc.On("Retry", mock.Anything, mock.Anything).Return(manualapp.StartResult{}, tt.err).Once()
+
+			r := retryRouter(newRetryTestController(uc))
+
+			req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows/wf-123/retry", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func resumeRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.POST("/manual-mail-workflows/:workflow_id/resume", func(c *gin.Context) { setUserID(c, 1) }, ctrl.Resume)
+	return r
+}
+
+func TestResume_202(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockResumeUseCase)
+	uc.On("Resume", mock.Anything, manualapp.ResumeCommand{
+		UserID:     1,
+		WorkflowID: "wf-123",
+	}).Return(manualapp.StartResult{
+		WorkflowID: "wf-123",
+		Status:     manualapp.WorkflowStatusQueued,
+	}, nil).Once()
+
+	r := resumeRouter(newResumeTestController(uc))
+
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows/wf-123/resume", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "メール取得ワークフローの再開を受け付けました。",
+		"workflow_id": "wf-123",
+		"status": "queued"
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestResume_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid", err: manualapp.ErrInvalidCommand, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "not found", err: manualapp.ErrWorkflowHistoryNotFound, wantStatus: http.StatusNotFound, wantCode: "manual_mail_workflow_not_found"},
+		{name: "not resumable", err: manualapp.ErrWorkflowNotResumable, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_not_resumable"},
+		{name: "active workflow overlaps", err: manualapp.ErrWorkflowConflict, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_conflict"},
+		{name: "fan-out parent", err: manualapp.ErrWorkflowFanOutParent, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_fan_out_parent"},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockResumeUseCase)
+			uc.On("Resume", mock.Anything, mock.Anything).Return(manualapp.StartResult{}, tt.err).Once()
+
+			r := resumeRouter(newResumeTestController(uc))
+
+			req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflows/wf-123/resume", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func previewRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.GET("/manual-mail-workflows/:workflow_id/preview", func(c *gin.Context) { setUserID(c, 1) }, ctrl.Preview)
+	return r
+}
+
+func TestPreview_200(t *testing.T) {
+	t.Parallel()
+
+	generatedAt := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	uc := new(mockPreviewUseCase)
+	uc.On("Get", mock.Anything, manualapp.PreviewQuery{
+		UserID:     1,
+		WorkflowID: "wf-123",
+	}).Return(manualapp.WorkflowPreviewView{
+		WorkflowID: "wf-123",
+		Status:     manualapp.WorkflowStatusPartialSuccess,
+		DryRun:     true,
+		Preview: &manualapp.WorkflowPreview{
+			WouldCreateItems: []manualapp.PreviewBillingItem{{
+				ExternalMessageID:   "msg-1",
+				VendorName:          "New Vendor",
+				VendorWouldRegister: true,
+				MatchedBy:           "name_exact",
+				BillingNumber:       "INV-1",
+				Amount:              1200,
+				Currency:            "JPY",
+				PaymentCycle:        "one_time",
+			}},
+			DuplicateItems: []manualapp.PreviewDuplicateItem{{
+				PreviewBillingItem: manualapp.PreviewBillingItem{
+					ExternalMessageID: "msg-2",
+					VendorID:          5,
+					VendorName:        "AWS",
+					MatchedBy:         "name_exact",
+					BillingNumber:     "INV-2",
+					Amount:            300,
+					Currency:          "USD",
+					PaymentCycle:      "recurring",
+				},
+				ExistingBillingID: 42,
+				ReasonCode:        "duplicate_billing",
+				Message:           "duplicate",
+			}},
+			UnresolvedItems: []manualapp.PreviewUnresolvedItem{{
+				ExternalMessageID: "msg-3",
+				ReasonCode:        "vendor_unresolved",
+				Message:           "unresolved",
+			}},
+			IneligibleItems: []manualapp.PreviewIneligibleItem{{
+				ExternalMessageID: "msg-4",
+				VendorName:        "AWS",
+				ReasonCode:        "amount_empty",
+				Message:           "ineligible",
+			}},
+			GeneratedAt: generatedAt,
+		},
+	}, nil).Once()
+
+	r := previewRouter(newPreviewTestController(uc))
+
+	req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflows/wf-123/preview", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"workflow_id": "wf-123",
+		"status": "partial_success",
+		"generated_at": "2026-03-25T12:00:00Z",
+		"would_create_count": 1,
+		"would_create_items": [{
+			"external_message_id": "msg-1",
+			"vendor_id": null,
+			"vendor_name": "New Vendor",
+			"vendor_would_register": true,
+			"matched_by": "name_exact",
+			"product_name_display": null,
+			"billing_number": "INV-1",
+			"invoice_number": null,
+			"amount": 1200,
+			"currency": "JPY",
+			"billing_date": null,
+			"payment_cycle": "one_time"
+		}],
+		"duplicate_items": [{
+			"external_message_id": "msg-2",
+			"vendor_id": 5,
+			"vendor_name": "AWS",
+			"vendor_would_register": false,
+			"matched_by": "name_exact",
+			"product_name_display": null,
+			"billing_number": "INV-2",
+			"invoice_number": null,
+			"amount": 300,
+			"currency": "USD",
+			"billing_date": null,
+			"payment_cycle": "recurring",
+			"existing_billing_id": 42,
+			"reason_code": "duplicate_billing",
+			"message": "duplicate"
+		}],
+		"unresolved_items": [{
+			"external_message_id": "msg-3",
+			"candidate_vendor_name": "",
+			"reason_code": "vendor_unresolved",
+			"message": "unresolved"
+		}],
+		"ineligible_items": [{
+			"external_message_id": "msg-4",
+			"vendor_name": "AWS",
+			"reason_code": "amount_empty",
+			"message": "ineligible"
+		}]
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestPreview_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid", err: manualapp.ErrInvalidCommand, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "not found", err: manualapp.ErrWorkflowHistoryNotFound, wantStatus: http.StatusNotFound, wantCode: "manual_mail_workflow_not_found"},
+		{name: "not dry run", err: manualapp.ErrWorkflowNotDryRun, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_not_dry_run"},
+		{name: "fan-out parent", err: manualapp.ErrWorkflowFanOutParent, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_fan_out_parent"},
+		{name: "not ready", err: manualapp.ErrWorkflowPreviewNotReady, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_preview_not_ready"},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockPreviewUseCase)
+			uc.On("Get", mock.Anything, mock.Anything).Return(manualapp.WorkflowPreviewView{}, tt.err).Once()
+
+			r := previewRouter(newPreviewTestController(uc))
+
+			req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflows/wf-123/preview", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func eventsRouter(ctrl *Controller) *gin.Engine {
+	r := gin.New()
+	r.GET("/manual-mail-workflows/:workflow_id/events", func(c *gin.Context) { setUserID(c, 7) }, ctrl.Events)
+	return r
+}
+
+func TestEvents_200_StreamsUntilTerminalEvent(t *testing.T) {
+	t.Parallel()
+
+	occurredAt := time.Date(2026, 3, 25, 17, 0, 0, 0, time.UTC)
+	subscription := &stubEventSubscription{events: make(chan manualapp.WorkflowEvent, 3)}
+	subscription.events <- manualapp.WorkflowEvent{
+		Type:         manualapp.WorkflowEventStageProgress,
+		Status:       manualapp.WorkflowStatusRunning,
+		CurrentStage: "vendorresolution",
+		Stage:        "vendorresolution",
+		Counts:       manualapp.StageCountView{SuccessCount: 1, BusinessFailureCount: 2},
+		FailureReasons: []manualapp.WorkflowEventFailureReason{
+			{ReasonCode: "vendor_unresolved", Message: "支払先を特定できませんでした。", Count: 2},
+		},
+		OccurredAt: occurredAt,
+	}
+	subscription.events <- manualapp.WorkflowEvent{
+		Type:       manualapp.WorkflowEventCompleted,
+		Status:     manualapp.WorkflowStatusPartialSuccess,
+		OccurredAt: occurredAt.Add(time.Second),
+	}
+	subscription.events <- manualapp.WorkflowEvent{Type: manualapp.WorkflowEventStageStarted}
+
+	uc := new(mockEventsUseCase)
+	uc.On("Subscribe", mock.Anything, manualapp.EventsQuery{UserID: 7, WorkflowID: "wf-123"}).Return(manualapp.EventStream{
+		Snapshot: manualapp.WorkflowEvent{
+			Type:         manualapp.WorkflowEventSnapshot,
+			Status:       manualapp.WorkflowStatusRunning,
+			CurrentStage: "analysis",
+			Stages:       map[string]manualapp.StageCountView{"fetch": {SuccessCount: 3}},
+			OccurredAt:   occurredAt.Add(-time.Second),
+		},
+		Subscription: subscription,
+	}, nil).Once()
+
+	r := eventsRouter(newEventsTestController(uc))
+
+	req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflows/wf-123/events", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/event-stream;charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "event:snapshot\n"+
+		`data:{"workflow_id":"wf-123","status":"running","current_stage":"analysis","stages":{"fetch":{"success_count":3,"business_failure_count":0,"technical_failure_count":0},"analysis":{"success_count":0,"business_failure_count":0,"technical_failure_count":0},"vendor_resolution":{"success_count":0,"business_failure_count":0,"technical_failure_count":0},"billing_eligibility":{"success_count":0,"business_failure_count":0,"technical_failure_count":0},"billing":{"success_count":0,"business_failure_count":0,"technical_failure_count":0}},"error_message":null,"occurred_at":"2026-03-25T16:59:59Z"}`+"\n\n"+
+		"event:stage_progress\n"+
+		`data:{"workflow_id":"wf-123","status":"running","current_stage":"vendorresolution","stage":"vendorresolution","counts":{"success_count":1,"business_failure_count":2,"technical_failure_count":0},"failure_reasons":[{"reason_code":"vendor_unresolved","message":"支払先を特定できませんでした。","count":2}],"error_message":null,"occurred_at":"2026-03-25T17:00:00Z"}`+"\n\n"+
+		"event:completed\n"+
+		`data:{"workflow_id":"wf-123","status":"partial_success","current_stage":null,"error_message":null,"occurred_at":"2026-03-25T17:00:01Z"}`+"\n\n",
+		resp.Body.String())
+	assert.True(t, subscription.closed)
+	uc.AssertExpectations(t)
+}
+
+func TestEvents_200_FinishedWorkflowSendsSnapshotOnly(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockEventsUseCase)
+	uc.On("Subscribe", mock.Anything, mock.Anything).Return(manualapp.EventStream{
+		Snapshot: manualapp.WorkflowEvent{
+			Type:         manualapp.WorkflowEventSnapshot,
+			Status:       manualapp.WorkflowStatusFailed,
+			CurrentStage: "analysis",
+			ErrorMessage: "メール解析に失敗しました。",
+		},
+	}, nil).Once()
+
+	r := eventsRouter(newEventsTestController(uc))
+
+	req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflows/wf-123/events", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "event:snapshot\n")
+	assert.Contains(t, resp.Body.String(), `"error_message":"メール解析に失敗しました。"`)
+	assert.NotContains(t, resp.Body.String(), "event:completed")
+	uc.AssertExpectations(t)
+}
+
+func TestEvents_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid query", err: manualapp.ErrInvalidEventsQuery, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "not found", err: manualapp.ErrWorkflowHistoryNotFound, wantStatus: http.StatusNotFound, wantCode: "manual_mail_workflow_not_found"},
+		{name: "internal", err: errors.New("redis down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockEventsUseCase)
+			uc.On("Subscribe", mock.Anything, mock.Anything).Return(manualapp.EventStream{}, tt.err).Once()
+
+			r := eventsRouter(newEventsTestController(uc))
+
+			req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflows/wf-123/events", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+			uc.AssertExpectations(t)
+		})
+	}
+}
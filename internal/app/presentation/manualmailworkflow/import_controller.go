@@ -0,0 +1,145 @@
+package manualmailworkflow
+
+import (
+	"business/internal/app/httpresponse"
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportRequestBytes limits the whole multipart upload, including every file.
+const maxImportRequestBytes = 25 << 20
+
+// ImportController handles uploads of .eml and mbox files for the manual mail workflow.
+type ImportController struct {
+	importUseCase manualapp.ImportUseCase
+	log           logger.Interface
+}
+
+// NewImportController creates a new ImportController.
+func NewImportController(
+	importUseCase manualapp.ImportUseCase,
+	log logger.Interface,
+) *ImportController {
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &ImportController{
+		importUseCase: importUseCase,
+		log:           log.With(logger.Component("manual_mail_workflow_import_controller")),
+	}
+}
+
+type importAcceptedResponse struct {
+	Message      string `json:"message"`
+	WorkflowID   string `json:"workflow_id"`
+	Status       string `json:"status"`
+	LabelName    string `json:"label_name"`
+	MessageCount int    `json:"message_count"`
+}
+
+// Import handles POST /api/v1/manual-mail-workflow-imports.
+func (ctrl *ImportController) Import(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportRequestBytes)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeImportTooLarge(c)
+			return
+		}
+		httpresponse.WriteInvalidRequest(c)
+		return
+	}
+
+	dryRun := false
+	if values := form.Value["dry_run"]; len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+		dryRun, err = strconv.ParseBool(strings.TrimSpace(values[0]))
+		if err != nil {
+			httpresponse.WriteInvalidRequest(c)
+			return
+		}
+	}
+
+	files := make([]manualapp.MailImportFile, 0, len(form.File["files"]))
+	for _, header := range form.File["files"] {
+		file, err := header.Open()
+		if err != nil {
+			httpresponse.WriteInvalidRequest(c)
+			return
+		}
+		content, err := io.ReadAll(file)
+		_ = file.Close()
+		if err != nil {
+			httpresponse.WriteInvalidRequest(c)
+			return
+		}
+		files = append(files, manualapp.MailImportFile{Name: header.Filename, Content: content})
+	}
+
+	if ctrl.importUseCase == nil {
+		reqLog.Error("manual_mail_workflow_import_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	result, err := ctrl.importUseCase.Import(c.Request.Context(), manualapp.ImportCommand{
+		UserID: uid,
+		Files:  files,
+		DryRun: dryRun,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrMailImportInvalid):
+			httpresponse.WriteError(c, http.StatusBadRequest, "manual_mail_workflow_import_invalid", "アップロードされたファイルからメールを読み取れませんでした。.eml または mbox 形式のファイルを指定してください。")
+		case errors.Is(err, manualapp.ErrMailImportTooLarge):
+			writeImportTooLarge(c)
+		case errors.Is(err, manualapp.ErrInvalidCommand), errors.Is(err, manualapp.ErrFetchConditionInvalid):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrWorkflowConflict):
+			httpresponse.WriteError(c, http.StatusConflict, "manual_mail_workflow_conflict", "同じメール連携・ラベル・期間のメール取得ワークフローが実行中です。完了後に再度お試しください。")
+		default:
+			reqLog.Error("manual_mail_workflow_import_failed",
+				logger.UserID(uid),
+				logger.Int("file_count", len(files)),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	message := "アップロードされたメールの取得ワークフローを受け付けました。"
+	if dryRun {
+		message = "アップロードされたメールの取得ワークフローのプレビューを受け付けました。請求は作成されません。"
+	}
+	c.JSON(http.StatusAccepted, importAcceptedResponse{
+		Message:      message,
+		WorkflowID:   result.WorkflowID,
+		Status:       result.Status,
+		LabelName:    result.LabelName,
+		MessageCount: result.MessageCount,
+	})
+}
+
+func writeImportTooLarge(c *gin.Context) {
+	httpresponse.WriteError(c, http.StatusRequestEntityTooLarge, "manual_mail_workflow_import_too_large",
+		"アップロードできるのは合計 25MB・"+strconv.Itoa(manualapp.MaxMailImportFiles)+" ファイル・"+strconv.Itoa(manualapp.MaxMailImportMessages)+" 通までです。")
+}
@@ -0,0 +1,137 @@
+package manualmailworkflow
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func importRouter(ctrl *ImportController) *gin.Engine {
+	r := gin.New()
+	r.POST("/manual-mail-workflow-imports", func(c *gin.Context) { setUserID(c, 1) }, ctrl.Import)
+	return r
+}
+
+func newImportRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	for name, content := range files {
+		part, err := writer.CreateFormFile("files", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflow-imports", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestImport_202(t *testing.T) {
+	t.Parallel()
+
+	eml := "Subject: invoice\r\n\r\nbody\r\n"
+	uc := new(mockImportUseCase)
+	uc.On("Import", mock.Anything, manualapp.ImportCommand{
+		UserID: 1,
+		Files:  []manualapp.MailImportFile{{Name: "invoice.eml", Content: []byte(eml)}},
+		DryRun: true,
+	}).Return(manualapp.ImportResult{
+		WorkflowID:   "wf-1",
+		Status:       "queued",
+		LabelName:    "imports/abc",
+		MessageCount: 1,
+	}, nil).Once()
+
+	r := importRouter(NewImportController(uc, newTestLogger()))
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, newImportRequest(t, map[string]string{"dry_run": "true"}, map[string]string{"invoice.eml": eml}))
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "アップロードされたメールの取得ワークフローのプレビューを受け付けました。請求は作成されません。",
+		"workflow_id": "wf-1",
+		"status": "queued",
+		"label_name": "imports/abc",
+		"message_count": 1
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestImport_MapsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid file", err: manualapp.ErrMailImportInvalid, wantStatus: http.StatusBadRequest, wantCode: "manual_mail_workflow_import_invalid"},
+		{name: "too many messages", err: manualapp.ErrMailImportTooLarge, wantStatus: http.StatusRequestEntityTooLarge, wantCode: "manual_mail_workflow_import_too_large"},
+		{name: "conflict", err: manualapp.ErrWorkflowConflict, wantStatus: http.StatusConflict, wantCode: "manual_mail_workflow_conflict"},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockImportUseCase)
+			uc.On("Import", mock.Anything, mock.Anything).Return(manualapp.ImportResult{}, tt.err).Once()
+
+			r := importRouter(NewImportController(uc, newTestLogger()))
+
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, newImportRequest(t, nil, map[string]string{"invoice.eml": "Subject: a\r\n\r\nbody\r\n"}))
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+		})
+	}
+}
+
+func TestImport_400_WhenNotMultipart(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockImportUseCase)
+	r := importRouter(NewImportController(uc, newTestLogger()))
+
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflow-imports", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	uc.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+}
+
+func TestImport_413_WhenBodyTooLarge(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockImportUseCase)
+	r := importRouter(NewImportController(uc, newTestLogger()))
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, newImportRequest(t, nil, map[string]string{"huge.mbox": string(bytes.Repeat([]byte("a"), maxImportRequestBytes+1))}))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	uc.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+}
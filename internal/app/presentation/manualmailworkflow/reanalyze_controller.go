@@ -0,0 +1,211 @@
+package manualmailworkflow
+
+import (
+	"business/internal/app/httpresponse"
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReanalyzeController handles reanalysis of saved emails.
+type ReanalyzeController struct {
+	reanalyzeUseCase manualapp.ReanalyzeUseCase
+	log              logger.Interface
+}
+
+// NewReanalyzeController creates a new ReanalyzeController.
+func NewReanalyzeController(
+	reanalyzeUseCase manualapp.ReanalyzeUseCase,
+	log logger.Interface,
+) *ReanalyzeController {
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &ReanalyzeController{
+		reanalyzeUseCase: reanalyzeUseCase,
+		log:              log.With(logger.Component("manual_mail_workflow_reanalyze_controller")),
+	}
+}
+
+type reanalyzeRequest struct {
+	EmailIDs      []uint     `json:"email_ids"`
+	ReceivedSince *time.Time `json:"received_since"`
+	ReceivedUntil *time.Time `json:"received_until"`
+	VendorID      uint       `json:"vendor_id"`
+	// BillingMode is none (default), preview or apply.
+	BillingMode string `json:"billing_mode"`
+}
+
+type reanalyzeResponse struct {
+	TargetCount      int                         `json:"target_count"`
+	RefetchedCount   int                         `json:"refetched_count"`
+	ParsedEmailCount int                         `json:"parsed_email_count"`
+	AnalysisFailures int                         `json:"analysis_failure_count"`
+	ResolvedCount    int                         `json:"resolved_count"`
+	UnresolvedCount  int                         `json:"unresolved_count"`
+	EligibleCount    int                         `json:"eligible_count"`
+	IneligibleCount  int                         `json:"ineligible_count"`
+	CreatedCount     int                         `json:"created_count"`
+	DuplicateCount   int                         `json:"duplicate_count"`
+	Skipped          []reanalysisSkippedResponse `json:"skipped"`
+	BillingChanges   []reanalysisChangeResponse  `json:"billing_changes"`
+}
+
+type reanalysisSkippedResponse struct {
+	EmailID           uint   `json:"email_id"`
+	ExternalMessageID string `json:"external_message_id"`
+	ReasonCode        string `json:"reason_code"`
+	Message           string `json:"message"`
+}
+
+type reanalysisChangeResponse struct {
+	Change            string                      `json:"change"`
+	EmailID           uint                        `json:"email_id"`
+	ExternalMessageID string                      `json:"external_message_id"`
+	ChangedFields     []string                    `json:"changed_fields"`
+	Existing          *reanalysisExistingResponse `json:"existing"`
+	Reanalyzed        *previewBillingResponse     `json:"reanalyzed"`
+	CreatedBillingID  *uint                       `json:"created_billing_id"`
+}
+
+type reanalysisExistingResponse struct {
+	BillingID          uint       `json:"billing_id"`
+	VendorID           uint       `json:"vendor_id"`
+	VendorName         string     `json:"vendor_name"`
+	ProductNameDisplay *string    `json:"product_name_display"`
+	BillingNumber      string     `json:"billing_number"`
+	InvoiceNumber      *string    `json:"invoice_number"`
+	Amount             *float64   `json:"amount"`
+	Currency           *string    `json:"currency"`
+	BillingDate        *time.Time `json:"billing_date"`
+	PaymentCycle       string     `json:"payment_cycle"`
+}
+
+// Reanalyze handles POST /api/v1/manual-mail-workflow-reanalyses.
+func (ctrl *ReanalyzeController) Reanalyze(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req reanalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.WriteInvalidRequest(c)
+		return
+	}
+
+	if ctrl.reanalyzeUseCase == nil {
+		reqLog.Error("manual_mail_workflow_reanalyze_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	cmd := manualapp.ReanalyzeCommand{
+		UserID:      uid,
+		EmailIDs:    req.EmailIDs,
+		VendorID:    req.VendorID,
+		BillingMode: req.BillingMode,
+	}
+	if req.ReceivedSince != nil {
+		cmd.ReceivedSince = *req.ReceivedSince
+	}
+	if req.ReceivedUntil != nil {
+		cmd.ReceivedUntil = *req.ReceivedUntil
+	}
+
+	result, err := ctrl.reanalyzeUseCase.Reanalyze(c.Request.Context(), cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrReanalysisTooManyEmails):
+			httpresponse.WriteError(c, http.StatusBadRequest, "manual_mail_workflow_reanalysis_too_many_emails", "再解析の対象が多すぎます。対象のメールや期間を絞り込んでください。")
+		default:
+			reqLog.Error("manual_mail_workflow_reanalyze_failed",
+				logger.UserID(uid),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toReanalyzeResponse(result))
+}
+
+func toReanalyzeResponse(result manualapp.ReanalyzeResult) reanalyzeResponse {
+	resp := reanalyzeResponse{
+		TargetCount:      result.TargetCount,
+		RefetchedCount:   result.RefetchedCount,
+		ParsedEmailCount: result.Analysis.ParsedEmailCount,
+		AnalysisFailures: len(result.Analysis.Failures),
+		ResolvedCount:    result.VendorResolution.ResolvedCount,
+		UnresolvedCount:  result.VendorResolution.UnresolvedCount,
+		EligibleCount:    result.BillingEligibility.EligibleCount,
+		IneligibleCount:  result.BillingEligibility.IneligibleCount,
+		CreatedCount:     result.Billing.CreatedCount,
+		DuplicateCount:   result.Billing.DuplicateCount,
+		Skipped:          make([]reanalysisSkippedResponse, 0, len(result.Skipped)),
+		BillingChanges:   make([]reanalysisChangeResponse, 0, len(result.BillingChanges)),
+	}
+	for _, skipped := range result.Skipped {
+		resp.Skipped = append(resp.Skipped, reanalysisSkippedResponse{
+			EmailID:           skipped.EmailID,
+			ExternalMessageID: skipped.ExternalMessageID,
+			ReasonCode:        skipped.ReasonCode,
+			Message:           skipped.Message,
+		})
+	}
+	for _, change := range result.BillingChanges {
+		item := reanalysisChangeResponse{
+			Change:            change.Change,
+			EmailID:           change.EmailID,
+			ExternalMessageID: change.ExternalMessageID,
+			ChangedFields:     append([]string{}, change.ChangedFields...),
+			CreatedBillingID:  optionalNonZeroUint(change.CreatedBillingID),
+		}
+		if change.Existing != nil {
+			item.Existing = &reanalysisExistingResponse{
+				BillingID:          change.Existing.BillingID,
+				VendorID:           change.Existing.VendorID,
+				VendorName:         change.Existing.VendorName,
+				ProductNameDisplay: cloneOptionalString(change.Existing.ProductNameDisplay),
+				BillingNumber:      change.Existing.BillingNumber,
+				InvoiceNumber:      cloneOptionalString(change.Existing.InvoiceNumber),
+				Amount:             change.Existing.Amount,
+				Currency:           optionalNonEmptyString(change.Existing.Currency),
+				BillingDate:        cloneOptionalTime(change.Existing.BillingDate),
+				PaymentCycle:       change.Existing.PaymentCycle,
+			}
+		}
+		if change.Reanalyzed != nil {
+			reanalyzed := toPreviewBillingResponse(manualapp.PreviewBillingItem{
+				ExternalMessageID:   change.Reanalyzed.ExternalMessageID,
+				VendorID:            change.Reanalyzed.VendorID,
+				VendorName:          change.Reanalyzed.VendorName,
+				VendorWouldRegister: change.Reanalyzed.VendorID == 0,
+				MatchedBy:           change.Reanalyzed.MatchedBy,
+				ProductNameDisplay:  change.Reanalyzed.ProductNameDisplay,
+				BillingNumber:       change.Reanalyzed.BillingNumber,
+				InvoiceNumber:       change.Reanalyzed.InvoiceNumber,
+				Amount:              change.Reanalyzed.Amount,
+				Currency:            change.Reanalyzed.Currency,
+				BillingDate:         change.Reanalyzed.BillingDate,
+				PaymentCycle:        change.Reanalyzed.PaymentCycle,
+			})
+			item.Reanalyzed = &reanalyzed
+		}
+		resp.BillingChanges = append(resp.BillingChanges, item)
+	}
+	return resp
+}
@@ -0,0 +1,158 @@
+package manualmailworkflow
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func reanalyzeRouter(ctrl *ReanalyzeController) *gin.Engine {
+	r := gin.New()
+	r.POST("/manual-mail-workflow-reanalyses", func(c *gin.Context) { setUserID(c, 1) }, ctrl.Reanalyze)
+	return r
+}
+
+func TestReanalyze_200(t *testing.T) {
+	t.Parallel()
+
+	billingDate := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	existingAmount := 1800.0
+	uc := new(mockReanalyzeUseCase)
+	uc.On("Reanalyze", mock.Anything, manualapp.ReanalyzeCommand{
+		UserID:        1,
+		EmailIDs:      []uint{11, 12},
+		ReceivedSince: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		BillingMode:   "preview",
+	}).Return(manualapp.ReanalyzeResult{
+		TargetCount:        2,
+		RefetchedCount:     1,
+		Skipped:            []manualapp.ReanalysisSkippedEmail{{EmailID: 12, ExternalMessageID: "msg-12", ReasonCode: "email_body_unavailable", Message: "再解析しませんでした。"}},
+		Analysis:           manualapp.AnalyzeResult{ParsedEmailCount: 1},
+		VendorResolution:   manualapp.VendorResolutionResult{ResolvedCount: 1},
+		BillingEligibility: manualapp.BillingEligibilityResult{EligibleCount: 1},
+		BillingChanges: []manualapp.ReanalysisBillingChange{{
+			Change:            manualapp.ReanalysisChangeChanged,
+			EmailID:           11,
+			ExternalMessageID: "msg-11",
+			Existing:          &manualapp.ExistingBilling{BillingID: 50, EmailID: 11, VendorID: 3, VendorName: "Acme", BillingNumber: "INV-1", Amount: &existingAmount, Currency: "JPY", PaymentCycle: "one_time"},
+			Reanalyzed:        &manualapp.EligibleItem{EmailID: 11, ExternalMessageID: "msg-11", VendorID: 3, VendorName: "Acme", MatchedBy: "name_exact", BillingNumber: "INV-1", Amount: 2000, Currency: "JPY", BillingDate: &billingDate, PaymentCycle: "one_time"},
+			ChangedFields:     []string{"amount", "billing_date"},
+		}},
+	}, nil).Once()
+
+	r := reanalyzeRouter(NewReanalyzeController(uc, newTestLogger()))
+
+	body := `{"email_ids":[11,12],"received_since":"2026-10-01T00:00:00Z","billing_mode":"preview"}`
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflow-reanalyses", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"target_count": 2,
+		"refetched_count": 1,
+		"parsed_email_count": 1,
+		"analysis_failure_count": 0,
+		"resolved_count": 1,
+		"unresolved_count": 0,
+		"eligible_count": 1,
+		"ineligible_count": 0,
+		"created_count": 0,
+		"duplicate_count": 0,
+		"skipped": [{"email_id": 12, "external_message_id": "msg-12", "reason_code": "email_body_unavailable", "message": "再解析しませんでした。"}],
+		"billing_changes": [{
+			"change": "changed",
+			"email_id": 11,
+			"external_message_id": "msg-11",
+			"changed_fields": ["amount", "billing_date"],
+			"existing": {
+				"billing_id": 50,
+				"vendor_id": 3,
+				"vendor_name": "Acme",
+				"product_name_display": null,
+				"billing_number": "INV-1",
+				"invoice_number": null,
+				"amount": 1800,
+				"currency": "JPY",
+				"billing_date": null,
+				"payment_cycle": "one_time"
+			},
+			"reanalyzed": {
+				"external_message_id": "msg-11",
+				"vendor_id": 3,
+				"vendor_name": "Acme",
+				"vendor_would_register": false,
+				"matched_by": "name_exact",
+				"product_name_display": null,
+				"billing_number": "INV-1",
+				"invoice_number": null,
+				"amount": 2000,
+				"currency": "JPY",
+				"billing_date": "2026-10-01T00:00:00Z",
+				"payment_cycle": "one_time"
+			},
+			"created_billing_id": null
+		}]
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestReanalyze_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid", err: manualapp.ErrInvalidCommand, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "too many emails", err: manualapp.ErrReanalysisTooManyEmails, wantStatus: http.StatusBadRequest, wantCode: "manual_mail_workflow_reanalysis_too_many_emails"},
+		{name: "internal", err: errors.New("openai down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockReanalyzeUseCase)
+			uc.On("Reanalyze", mock.Anything, mock.Anything).Return(manualapp.ReanalyzeResult{}, tt.err).Once()
+
+			r := reanalyzeRouter(NewReanalyzeController(uc, newTestLogger()))
+
+			req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflow-reanalyses", strings.NewReader(`{"vendor_id":3}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReanalyze_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	uc := new(mockReanalyzeUseCase)
+	r := reanalyzeRouter(NewReanalyzeController(uc, newTestLogger()))
+
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflow-reanalyses", strings.NewReader(`{"email_ids":"11"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	uc.AssertNotCalled(t, "Reanalyze", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,234 @@
+package manualmailworkflow
+
+import (
+	"business/internal/app/httpresponse"
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleController handles manual mail workflow schedule HTTP requests.
+type ScheduleController struct {
+	scheduleUseCase manualapp.ScheduleUseCase
+	log             logger.Interface
+}
+
+// NewScheduleController creates a new ScheduleController.
+func NewScheduleController(
+	scheduleUseCase manualapp.ScheduleUseCase,
+	log logger.Interface,
+) *ScheduleController {
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &ScheduleController{
+		scheduleUseCase: scheduleUseCase,
+		log:             log.With(logger.Component("manual_mail_workflow_schedule_controller")),
+	}
+}
+
+type createScheduleRequest struct {
+	ConnectionID  uint   `json:"connection_id" binding:"required"`
+	LabelName     string `json:"label_name" binding:"required"`
+	Frequency     string `json:"frequency" binding:"required"`
+	Weekday       *int   `json:"weekday"`
+	TimeOfDay     string `json:"time_of_day" binding:"required"`
+	Timezone      string `json:"timezone"`
+	LookbackHours int    `json:"lookback_hours" binding:"required"`
+}
+
+type scheduleListResponse struct {
+	Items []scheduleResponse `json:"items"`
+}
+
+type scheduleResponse struct {
+	ID             uint64     `json:"id"`
+	ConnectionID   uint       `json:"connection_id"`
+	LabelName      string     `json:"label_name"`
+	Frequency      string     `json:"frequency"`
+	Weekday        *int       `json:"weekday"`
+	TimeOfDay      string     `json:"time_of_day"`
+	Timezone       string     `json:"timezone"`
+	LookbackHours  int        `json:"lookback_hours"`
+	NextRunAt      time.Time  `json:"next_run_at"`
+	LastRunAt      *time.Time `json:"last_run_at"`
+	LastWorkflowID *string    `json:"last_workflow_id"`
+	LastError      *string    `json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Create handles POST /api/v1/manual-mail-workflow-schedules.
+func (ctrl *ScheduleController) Create(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req createScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresponse.WriteInvalidRequest(c)
+		return
+	}
+
+	if ctrl.scheduleUseCase == nil {
+		reqLog.Error("manual_mail_workflow_schedule_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	schedule, err := ctrl.scheduleUseCase.Create(c.Request.Context(), manualapp.CreateScheduleCommand{
+		UserID:        uid,
+		ConnectionID:  req.ConnectionID,
+		LabelName:     req.LabelName,
+		Frequency:     req.Frequency,
+		Weekday:       req.Weekday,
+		TimeOfDay:     req.TimeOfDay,
+		Timezone:      req.Timezone,
+		LookbackHours: req.LookbackHours,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidScheduleCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrScheduleConnectionNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "mail_account_connection_not_found", "対象のメール連携は見つかりません。")
+		default:
+			reqLog.Error("manual_mail_workflow_schedule_create_failed",
+				logger.UserID(uid),
+				logger.Uint("connection_id", req.ConnectionID),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toScheduleResponse(schedule))
+}
+
+// List handles GET /api/v1/manual-mail-workflow-schedules.
+func (ctrl *ScheduleController) List(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if ctrl.scheduleUseCase == nil {
+		reqLog.Error("manual_mail_workflow_schedule_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	schedules, err := ctrl.scheduleUseCase.List(c.Request.Context(), uid)
+	if err != nil {
+		reqLog.Error("manual_mail_workflow_schedule_list_failed",
+			logger.UserID(uid),
+			logger.Err(err),
+		)
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	items := make([]scheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		items = append(items, toScheduleResponse(schedule))
+	}
+
+	c.JSON(http.StatusOK, scheduleListResponse{Items: items})
+}
+
+// Delete handles DELETE /api/v1/manual-mail-workflow-schedules/:schedule_id.
+func (ctrl *ScheduleController) Delete(c *gin.Context) {
+	reqLog := ctrl.log
+	if l, err := ctrl.log.WithContext(c.Request.Context()); err == nil {
+		reqLog = l
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	scheduleID, ok := currentScheduleID(c)
+	if !ok {
+		return
+	}
+	if ctrl.scheduleUseCase == nil {
+		reqLog.Error("manual_mail_workflow_schedule_usecase_not_configured")
+		httpresponse.WriteInternalServerError(c)
+		return
+	}
+
+	err := ctrl.scheduleUseCase.Delete(c.Request.Context(), manualapp.DeleteScheduleCommand{
+		UserID:     uid,
+		ScheduleID: scheduleID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, manualapp.ErrInvalidScheduleCommand):
+			httpresponse.WriteInvalidRequest(c)
+		case errors.Is(err, manualapp.ErrWorkflowScheduleNotFound):
+			httpresponse.WriteError(c, http.StatusNotFound, "manual_mail_workflow_schedule_not_found", "対象のメール取得スケジュールは見つかりません。")
+		default:
+			reqLog.Error("manual_mail_workflow_schedule_delete_failed",
+				logger.UserID(uid),
+				logger.Uint("schedule_id", uint(scheduleID)),
+				logger.Err(err),
+			)
+			httpresponse.WriteInternalServerError(c)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func currentScheduleID(c *gin.Context) (uint64, bool) {
+	scheduleID, err := strconv.ParseUint(c.Param("schedule_id"), 10, 64)
+	if err != nil || scheduleID == 0 {
+		httpresponse.WriteInvalidRequest(c)
+		return 0, false
+	}
+
+	return scheduleID, true
+}
+
+func toScheduleResponse(schedule manualapp.WorkflowSchedule) scheduleResponse {
+	var weekday *int
+	if schedule.Weekday != nil {
+		value := *schedule.Weekday
+		weekday = &value
+	}
+
+	return scheduleResponse{
+		ID:             schedule.ID,
+		ConnectionID:   schedule.ConnectionID,
+		LabelName:      schedule.LabelName,
+		Frequency:      schedule.Frequency,
+		Weekday:        weekday,
+		TimeOfDay:      schedule.TimeOfDay(),
+		Timezone:       schedule.Timezone,
+		LookbackHours:  schedule.LookbackHours,
+		NextRunAt:      schedule.NextRunAt,
+		LastRunAt:      cloneOptionalTime(schedule.LastRunAt),
+		LastWorkflowID: cloneOptionalString(schedule.LastWorkflowID),
+		LastError:      cloneOptionalString(schedule.LastError),
+		CreatedAt:      schedule.CreatedAt,
+		UpdatedAt:      schedule.UpdatedAt,
+	}
+}
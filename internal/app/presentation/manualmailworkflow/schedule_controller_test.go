@@ -0,0 +1,203 @@
+package manualmailworkflow
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func scheduleRouter(ctrl *ScheduleController) *gin.Engine {
+	r := gin.New()
+	setUser := func(c *gin.Context) { setUserID(c, 1) }
+	r.GET("/manual-mail-workflow-schedules", setUser, ctrl.List)
+	r.POST("/manual-mail-workflow-schedules", setUser, ctrl.Create)
+	r.DELETE("/manual-mail-workflow-schedules/:schedule_id", setUser, ctrl.Delete)
+	return r
+}
+
+func TestScheduleCreate_201(t *testing.T) {
+	t.Parallel()
+
+	weekday := 1
+	uc := new(mockScheduleUseCase)
+	uc.On("Create", mock.Anything, manualapp.CreateScheduleCommand{
+		UserID:        1,
+		ConnectionID:  12,
+		LabelName:     "billing",
+		Frequency:     "weekly",
+		Weekday:       &weekday,
+		TimeOfDay:     "09:30",
+		Timezone:      "Asia/Tokyo",
+		LookbackHours: 168,
+	}).Return(manualapp.WorkflowSchedule{
+		ID:            3,
+		UserID:        1,
+		ConnectionID:  12,
+		LabelName:     "billing",
+		Frequency:     "weekly",
+		Weekday:       &weekday,
+		HourOfDay:     9,
+		MinuteOfHour:  30,
+		Timezone:      "Asia/Tokyo",
+		LookbackHours: 168,
+		NextRunAt:     time.Date(2026, 3, 30, 0, 30, 0, 0, time.UTC),
+		CreatedAt:     time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:     time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC),
+	}, nil).Once()
+
+	r := scheduleRouter(NewScheduleController(uc, newTestLogger()))
+
+	body := `{"connection_id":12,"label_name":"billing","frequency":"weekly","weekday":1,"time_of_day":"09:30","timezone":"Asia/Tokyo","lookback_hours":168}`
+	req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflow-schedules", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.JSONEq(t, `{
+		"id": 3,
+		"connection_id": 12,
+		"label_name": "billing",
+		"frequency": "weekly",
+		"weekday": 1,
+		"time_of_day": "09:30",
+		"timezone": "Asia/Tokyo",
+		"lookback_hours": 168,
+		"next_run_at": "2026-03-30T00:30:00Z",
+		"last_run_at": null,
+		"last_workflow_id": null,
+		"last_error": null,
+		"created_at": "2026-03-25T12:00:00Z",
+		"updated_at": "2026-03-25T12:00:00Z"
+	}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestScheduleCreate_ErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid", err: manualapp.ErrInvalidScheduleCommand, wantStatus: http.StatusBadRequest, wantCode: "invalid_request"},
+		{name: "connection not found", err: manualapp.ErrScheduleConnectionNotFound, wantStatus: http.StatusNotFound, wantCode: "mail_account_connection_not_found"},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockScheduleUseCase)
+			uc.On("Create", mock.Anything, mock.Anything).Return(manualapp.WorkflowSchedule{}, tt.err).Once()
+
+			r := scheduleRouter(NewScheduleController(uc, newTestLogger()))
+
+			body := `{"connection_id":12,"label_name":"billing","frequency":"daily","time_of_day":"09:30","lookback_hours":24}`
+			req := httptest.NewRequest(http.MethodPost, "/manual-mail-workflow-schedules", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantCode)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestScheduleList_200(t *testing.T) {
+	t.Parallel()
+
+	lastRunAt := time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)
+	workflowID := "01JQ0B7N0M7H3X9C2J5K8V6P4"
+	uc := new(mockScheduleUseCase)
+	uc.On("List", mock.Anything, uint(1)).Return([]manualapp.WorkflowSchedule{{
+		ID:             3,
+		ConnectionID:   12,
+		LabelName:      "billing",
+		Frequency:      "daily",
+		HourOfDay:      9,
+		Timezone:       "Asia/Tokyo",
+		LookbackHours:  24,
+		NextRunAt:      time.Date(2026, 3, 26, 0, 0, 0, 0, time.UTC),
+		LastRunAt:      &lastRunAt,
+		LastWorkflowID: &workflowID,
+		CreatedAt:      time.Date(2026, 3, 24, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:      lastRunAt,
+	}}, nil).Once()
+
+	r := scheduleRouter(NewScheduleController(uc, newTestLogger()))
+
+	req := httptest.NewRequest(http.MethodGet, "/manual-mail-workflow-schedules", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"items": [{
+		"id": 3,
+		"connection_id": 12,
+		"label_name": "billing",
+		"frequency": "daily",
+		"weekday": null,
+		"time_of_day": "09:00",
+		"timezone": "Asia/Tokyo",
+		"lookback_hours": 24,
+		"next_run_at": "2026-03-26T00:00:00Z",
+		"last_run_at": "2026-03-25T00:00:00Z",
+		"last_workflow_id": "01JQ0B7N0M7H3X9C2J5K8V6P4",
+		"last_error": null,
+		"created_at": "2026-03-24T12:00:00Z",
+		"updated_at": "2026-03-25T00:00:00Z"
+	}]}`, resp.Body.String())
+	uc.AssertExpectations(t)
+}
+
+func TestScheduleDelete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		callUC     bool
+		wantStatus int
+	}{
+		{name: "deleted", path: "/manual-mail-workflow-schedules/3", callUC: true, wantStatus: http.StatusNoContent},
+		{name: "not found", path: "/manual-mail-workflow-schedules/3", err: manualapp.ErrWorkflowScheduleNotFound, callUC: true, wantStatus: http.StatusNotFound},
+		{name: "invalid id", path: "/manual-mail-workflow-schedules/abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := new(mockScheduleUseCase)
+			if tt.callUC {
+				uc.On("Delete", mock.Anything, manualapp.DeleteScheduleCommand{UserID: 1, ScheduleID: 3}).Return(tt.err).Once()
+			}
+
+			r := scheduleRouter(NewScheduleController(uc, newTestLogger()))
+
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			uc.AssertExpectations(t)
+		})
+	}
+}
@@ -29,10 +29,149 @@ func (m *mockListUseCase) List(ctx context.Context, query manualapp.ListQuery) (
 	return result, args.Error(1)
 }
 
+type mockDetailUseCase struct {
+	mock.Mock
+}
+
+func (m *mockDetailUseCase) Get(ctx context.Context, query manualapp.DetailQuery) (manualapp.WorkflowHistoryDetail, error) {
+	args := m.Called(ctx, query)
+	result, _ := args.Get(0).(manualapp.WorkflowHistoryDetail)
+	return result, args.Error(1)
+}
+
+type mockCancelUseCase struct {
+	mock.Mock
+}
+
+func (m *mockCancelUseCase) Cancel(ctx context.Context, cmd manualapp.CancelCommand) (manualapp.CancelResult, error) {
+	args := m.Called(ctx, cmd)
+	result, _ := args.Get(0).(manualapp.CancelResult)
+	return result, args.Error(1)
+}
+
+type mockRetryUseCase struct {
+	mock.Mock
+}
+
+func (m *mockRetryUseCase) Retry(ctx context.Context, cmd manualapp.RetryCommand) (manualapp.StartResult, error) {
+	args := m.Called(ctx, cmd)
+	result, _ := args.Get(0).(manualapp.StartResult)
+	return result, args.Error(1)
+}
+
+type mockResumeUseCase struct {
+	mock.Mock
+}
+
+func (m *mockResumeUseCase) Resume(ctx context.Context, cmd manualapp.ResumeCommand) (manualapp.StartResult, error) {
+	args := m.Called(ctx, cmd)
+	result, _ := args.Get(0).(manualapp.StartResult)
+	return result, args.Error(1)
+}
+
+type mockEventsUseCase struct {
+	mock.Mock
+}
+
+func (m *mockEventsUseCase) Subscribe(ctx context.Context, query manualapp.EventsQuery) (manualapp.EventStream, error) {
+	args := m.Called(ctx, query)
+	result, _ := args.Get(0).(manualapp.EventStream)
+	return result, args.Error(1)
+}
+
+type mockPreviewUseCase struct {
+	mock.Mock
+}
+
+func (m *mockPreviewUseCase) Get(ctx context.Context, query manualapp.PreviewQuery) (manualapp.WorkflowPreviewView, error) {
+	args := m.Called(ctx, query)
+	result, _ := args.Get(0).(manualapp.WorkflowPreviewView)
+	return result, args.Error(1)
+}
+
+type stubEventSubscription struct {
+	events chan manualapp.WorkflowEvent
+	closed bool
+}
+
+func (s *stubEventSubscription) Events() <-chan manualapp.WorkflowEvent {
+	return s.events
+}
+
+func (s *stubEventSubscription) Close() error {
+	s.closed = true
+	return nil
+}
+
+type mockScheduleUseCase struct {
+	mock.Mock
+}
+
+func (m *mockScheduleUseCase) Create(ctx context.Context, cmd manualapp.CreateScheduleCommand) (manualapp.WorkflowSchedule, error) {
+	args := m.Called(ctx, cmd)
+	result, _ := args.Get(0).(manualapp.WorkflowSchedule)
+	return result, args.Error(1)
+}
+
+func (m *mockScheduleUseCase) List(ctx context.Context, userID uint) ([]manualapp.WorkflowSchedule, error) {
+	args := m.Called(ctx, userID)
+	result, _ := args.Get(0).([]manualapp.WorkflowSchedule)
+	return result, args.Error(1)
+}
+
+func (m *mockScheduleUseCase) Delete(ctx context.Context, cmd manualapp.DeleteScheduleCommand) error {
+	args := m.Called(ctx, cmd)
+	return args.Error(0)
+}
+
+type mockImportUseCase struct {
+	mock.Mock
+}
+
+func (m *mockImportUseCase) Import(ctx context.Context, cmd manualapp.ImportCommand) (manualapp.ImportResult, error) {
+	args := m.Called(ctx, cmd)
+	result, _ := args.Get(0).(manualapp.ImportResult)
+	return result, args.Error(1)
+}
+
 func newTestLogger() logger.Interface {
 	return mocklibrary.NewNopLogger()
 }
 
 func newTestController(startUseCase manualapp.StartUseCase, listUseCase manualapp.ListUseCase) *Controller {
-	return NewController(startUseCase, listUseCase, newTestLogger())
+	return NewController(startUseCase, listUseCase, nil, nil, nil, nil, nil, nil, newTestLogger())
+}
+
+func newDetailTestController(detailUseCase manualapp.DetailUseCase) *Controller {
+	return NewController(nil, nil, detailUseCase, nil, nil, nil, nil, nil, newTestLogger())
+}
+
+func newCancelTestController(cancelUseCase manualapp.CancelUseCase) *Controller {
+	return NewController(nil, nil, nil, cancelUseCase, nil, nil, nil, nil, newTestLogger())
+}
+
+func newRetryTestController(retryUseCase manualapp.RetryUseCase) *Controller {
+	return NewController(nil, nil, nil, nil, retryUseCase, nil, nil, nil, newTestLogger())
+}
+
+func newResumeTestController(resumeUseCase manualapp.ResumeUseCase) *Controller {
+	return NewController(nil, nil, nil, nil, nil, resumeUseCase, nil, nil, newTestLogger())
+}
+
+func newEventsTestController(eventsUseCase manualapp.EventsUseCase) *Controller {
+	return NewController(nil, nil, nil, nil, nil, nil, eventsUseCase, nil, newTestLogger())
+}
+
+func newPreviewTestController(previewUseCase manualapp.PreviewUseCase) *Controller {
+	return NewController(nil, nil, nil, nil, nil, nil, nil, previewUseCase, newTestLogger())
+}
+
+type mockReanalyzeUseCase struct {
+	mock.Mock
+}
+
+func (m *mockReanalyzeUseCase) Reanalyze(ctx context.Context, cmd manualapp.ReanalyzeCommand) (manualapp.ReanalyzeResult, error) {
+	args := m.Called(ctx, cmd)
+	result, _ := args.Get(0).(manualapp.ReanalyzeResult)
+	return result, args.Error(1)
 }
@@ -0,0 +1,146 @@
+package reanalyze
+
+import (
+	"business/internal/app/bootstrap"
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Run は保存済みメールを現在のプロンプトで解析し直すコマンドを実行し、終了コードを返す。
+// 対象は -email-ids・-since / -until・-vendor-id の AND 条件で指定し、-billing-mode で請求まで流すかを選ぶ。
+func Run(args []string) int {
+	cmd, err := parseArgs(args, os.Stderr)
+	if err != nil {
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	deps, err := bootstrap.Build(ctx, "reanalyze", "reanalyze")
+	if err != nil {
+		return 1
+	}
+	baseLogger := deps.BaseLogger
+	defer baseLogger.Sync()
+
+	reanalyzeLogger := baseLogger.With(logger.Component("reanalyze"))
+
+	var usecase manualapp.ReanalyzeUseCase
+	if err := deps.Container.Invoke(func(uc manualapp.ReanalyzeUseCase) {
+		usecase = uc
+	}); err != nil {
+		reanalyzeLogger.Error("再解析の初期化に失敗しました", logger.Err(err))
+		return 1
+	}
+
+	result, err := usecase.Reanalyze(ctx, cmd)
+	if err != nil {
+		reanalyzeLogger.Error("再解析に失敗しました", logger.UserID(cmd.UserID), logger.Err(err))
+		fmt.Fprintf(os.Stderr, "再解析に失敗しました: %v\n", err)
+		return 1
+	}
+
+	writeResult(os.Stdout, cmd.BillingMode, result)
+	return 0
+}
+
+// parseArgs はコマンドライン引数を再解析の入力に変換する。受信期間は RFC3339 で指定する。
+func parseArgs(args []string, output io.Writer) (manualapp.ReanalyzeCommand, error) {
+	flags := flag.NewFlagSet("reanalyze", flag.ContinueOnError)
+	flags.SetOutput(output)
+	userID := flags.Uint("user-id", 0, "再解析するユーザーの ID（必須）")
+	emailIDs := flags.String("email-ids", "", "再解析するメールの ID（カンマ区切り）")
+	since := flags.String("since", "", "この日時以降に受信したメールを対象にする（RFC3339）")
+	until := flags.String("until", "", "この日時より前に受信したメールを対象にする（RFC3339）")
+	vendorID := flags.Uint("vendor-id", 0, "この支払先の請求が登録済みのメールを対象にする")
+	billingMode := flags.String("billing-mode", manualapp.ReanalysisBillingNone, "none / preview / apply")
+	if err := flags.Parse(args); err != nil {
+		return manualapp.ReanalyzeCommand{}, err
+	}
+
+	cmd := manualapp.ReanalyzeCommand{
+		UserID:      *userID,
+		VendorID:    *vendorID,
+		BillingMode: strings.ToLower(strings.TrimSpace(*billingMode)),
+	}
+	for _, value := range strings.Split(*emailIDs, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		emailID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fmt.Fprintf(output, "-email-ids が不正です: %q\n", value)
+			return manualapp.ReanalyzeCommand{}, err
+		}
+		cmd.EmailIDs = append(cmd.EmailIDs, uint(emailID))
+	}
+	for _, period := range []struct {
+		name  string
+		value string
+		dest  *time.Time
+	}{
+		{name: "since", value: *since, dest: &cmd.ReceivedSince},
+		{name: "until", value: *until, dest: &cmd.ReceivedUntil},
+	} {
+		if strings.TrimSpace(period.value) == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(period.value))
+		if err != nil {
+			fmt.Fprintf(output, "-%s は RFC3339 で指定してください: %q\n", period.name, period.value)
+			return manualapp.ReanalyzeCommand{}, err
+		}
+		*period.dest = parsed
+	}
+	return cmd, nil
+}
+
+// writeResult は再解析の件数と、登録済みの請求との差分を 1 行ずつ出力する。
+func writeResult(output io.Writer, billingMode string, result manualapp.ReanalyzeResult) {
+	fmt.Fprintf(output, "対象 %d 件 / 再取得 %d 件 / 解析 %d 件 / 解析失敗 %d 件 / スキップ %d 件\n",
+		result.TargetCount,
+		result.RefetchedCount,
+		result.Analysis.ParsedEmailCount,
+		len(result.Analysis.Failures),
+		len(result.Skipped),
+	)
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(output, "skipped email_id=%d reason=%s\n", skipped.EmailID, skipped.ReasonCode)
+	}
+	if billingMode == manualapp.ReanalysisBillingPreview || billingMode == manualapp.ReanalysisBillingApply {
+		fmt.Fprintf(output, "支払先解決 %d 件 / 請求成立 %d 件 / 請求作成 %d 件 / 重複 %d 件\n",
+			result.VendorResolution.ResolvedCount,
+			result.BillingEligibility.EligibleCount,
+			result.Billing.CreatedCount,
+			result.Billing.DuplicateCount,
+		)
+	}
+	for _, change := range result.BillingChanges {
+		line := fmt.Sprintf("%s email_id=%d", change.Change, change.EmailID)
+		if change.Existing != nil {
+			line += fmt.Sprintf(" billing_id=%d billing_number=%s", change.Existing.BillingID, change.Existing.BillingNumber)
+		} else if change.Reanalyzed != nil {
+			line += fmt.Sprintf(" vendor=%s billing_number=%s", change.Reanalyzed.VendorName, change.Reanalyzed.BillingNumber)
+		}
+		if len(change.ChangedFields) > 0 {
+			line += " fields=" + strings.Join(change.ChangedFields, ",")
+		}
+		if change.CreatedBillingID != 0 {
+			line += fmt.Sprintf(" created_billing_id=%d", change.CreatedBillingID)
+		}
+		fmt.Fprintln(output, line)
+	}
+}
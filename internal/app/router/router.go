@@ -5,6 +5,7 @@ import (
 	authpresentation "business/internal/app/presentation/auth"
 	billingpresentation "business/internal/app/presentation/billing"
 	dashboardpresentation "business/internal/app/presentation/dashboard"
+	emailpresentation "business/internal/app/presentation/email"
 	macpresentation "business/internal/app/presentation/mailaccountconnection"
 	manualpresentation "business/internal/app/presentation/manualmailworkflow"
 	"business/internal/library/logger"
@@ -54,11 +55,22 @@ func Router(g *gin.Engine, container *dig.Container, log logger.Interface, allow
 		log.Error("failed to resolve mail account connection controller", logger.Err(err))
 		return g, err
 	}
+	var macLabelController *macpresentation.LabelController
+	if err := container.Invoke(func(lc *macpresentation.LabelController) {
+		macLabelController = lc
+	}); err != nil {
+		log.Error("failed to resolve mail account connection label controller", logger.Err(err))
+		return g, err
+	}
 	registerMailAccountConnectionRoutes := func(group *gin.RouterGroup) {
 		group.GET("", authMiddleware.Authenticate(), macController.List)
 		group.DELETE("/:connection_id", authMiddleware.Authenticate(), macController.Disconnect)
+		group.GET("/:connection_id/labels", authMiddleware.Authenticate(), macLabelController.List)
 		group.POST("/gmail/authorize", authMiddleware.Authenticate(), macController.Authorize)
 		group.POST("/gmail/callback", authMiddleware.Authenticate(), macController.Callback)
+		group.POST("/outlook/authorize", authMiddleware.Authenticate(), macController.AuthorizeOutlook)
+		group.POST("/outlook/callback", authMiddleware.Authenticate(), macController.CallbackOutlook)
+		group.POST("/imap", authMiddleware.Authenticate(), macController.ConnectIMAP)
 	}
 	registerMailAccountConnectionRoutes(g.Group("/api/v1/mail-account-connections"))
 
@@ -73,9 +85,66 @@ func Router(g *gin.Engine, container *dig.Container, log logger.Interface, allow
 	registerManualMailWorkflowRoutes := func(group *gin.RouterGroup) {
 		group.GET("", authMiddleware.Authenticate(), manualController.List)
 		group.POST("", authMiddleware.Authenticate(), manualController.Execute)
+		group.GET("/:workflow_id", authMiddleware.Authenticate(), manualController.Detail)
+		group.POST("/:workflow_id/cancel", authMiddleware.Authenticate(), manualController.Cancel)
+		group.POST("/:workflow_id/retry", authMiddleware.Authenticate(), manualController.Retry)
+		group.POST("/:workflow_id/resume", authMiddleware.Authenticate(), manualController.Resume)
+		group.GET("/:workflow_id/preview", authMiddleware.Authenticate(), manualController.Preview)
+		group.GET("/:workflow_id/events", authMiddleware.Authenticate(), manualController.Events)
 	}
 	registerManualMailWorkflowRoutes(g.Group("/api/v1/manual-mail-workflows"))
 
+	var scheduleController *manualpresentation.ScheduleController
+	if err := container.Invoke(func(sc *manualpresentation.ScheduleController) {
+		scheduleController = sc
+	}); err != nil {
+		log.Error("failed to resolve manual mail workflow schedule controller", logger.Err(err))
+		return g, err
+	}
+	registerManualMailWorkflowScheduleRoutes := func(group *gin.RouterGroup) {
+		group.GET("", authMiddleware.Authenticate(), scheduleController.List)
+		group.POST("", authMiddleware.Authenticate(), scheduleController.Create)
+		group.DELETE("/:schedule_id", authMiddleware.Authenticate(), scheduleController.Delete)
+	}
+	registerManualMailWorkflowScheduleRoutes(g.Group("/api/v1/manual-mail-workflow-schedules"))
+
+	var importController *manualpresentation.ImportController
+	if err := container.Invoke(func(ic *manualpresentation.ImportController) {
+		importController = ic
+	}); err != nil {
+		log.Error("failed to resolve manual mail workflow import controller", logger.Err(err))
+		return g, err
+	}
+	registerManualMailWorkflowImportRoutes := func(group *gin.RouterGroup) {
+		group.POST("", authMiddleware.Authenticate(), importController.Import)
+	}
+	registerManualMailWorkflowImportRoutes(g.Group("/api/v1/manual-mail-workflow-imports"))
+
+	var reanalyzeController *manualpresentation.ReanalyzeController
+	if err := container.Invoke(func(rc *manualpresentation.ReanalyzeController) {
+		reanalyzeController = rc
+	}); err != nil {
+		log.Error("failed to resolve manual mail workflow reanalyze controller", logger.Err(err))
+		return g, err
+	}
+	registerManualMailWorkflowReanalysisRoutes := func(group *gin.RouterGroup) {
+		group.POST("", authMiddleware.Authenticate(), reanalyzeController.Reanalyze)
+	}
+	registerManualMailWorkflowReanalysisRoutes(g.Group("/api/v1/manual-mail-workflow-reanalyses"))
+
+	// Email関連
+	var emailController *emailpresentation.Controller
+	if err := container.Invoke(func(ec *emailpresentation.Controller) {
+		emailController = ec
+	}); err != nil {
+		log.Error("failed to resolve email controller", logger.Err(err))
+		return g, err
+	}
+	registerEmailRoutes := func(group *gin.RouterGroup) {
+		group.GET("/:email_id/source", authMiddleware.Authenticate(), emailController.Source)
+	}
+	registerEmailRoutes(g.Group("/api/v1/emails"))
+
 	// Billing関連
 	var billingController *billingpresentation.Controller
 	if err := container.Invoke(func(bc *billingpresentation.Controller) {
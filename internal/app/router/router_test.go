@@ -9,6 +9,7 @@ import (
 	authpresentation "business/internal/app/presentation/auth"
 	billingpresentation "business/internal/app/presentation/billing"
 	dashboardpresentation "business/internal/app/presentation/dashboard"
+	emailpresentation "business/internal/app/presentation/email"
 	macpresentation "business/internal/app/presentation/mailaccountconnection"
 	manualpresentation "business/internal/app/presentation/manualmailworkflow"
 	"business/internal/auth/domain"
@@ -17,6 +18,7 @@ import (
 	"business/internal/library/logger"
 	macapp "business/internal/mailaccountconnection/application"
 	macdomain "business/internal/mailaccountconnection/domain"
+	mfdomain "business/internal/mailfetch/domain"
 	manualapp "business/internal/manualmailworkflow/application"
 	mocklibrary "business/test/mock/library"
 
@@ -98,6 +100,21 @@ func (s *stubEmailCredentialUsecase) Disconnect(ctx context.Context, userID uint
 	return nil
 }
 
+func (s *stubEmailCredentialUsecase) ConnectIMAP(ctx context.Context, userID uint, input macapp.IMAPConnectInput) (macdomain.ConnectionView, error) {
+	return macdomain.ConnectionView{}, nil
+}
+
+func (s *stubEmailCredentialUsecase) AuthorizeOutlook(ctx context.Context, userID uint) (macapp.AuthorizeResult, error) {
+	return macapp.AuthorizeResult{
+		AuthorizationURL: "https://login.microsoftonline.com/common/oauth2/v2.0/authorize?state=test",
+		ExpiresAt:        time.Now().Add(10 * time.Minute),
+	}, nil
+}
+
+func (s *stubEmailCredentialUsecase) CallbackOutlook(ctx context.Context, userID uint, code, state string) error {
+	return nil
+}
+
 type stubManualMailWorkflowUseCase struct{}
 
 func (s *stubManualMailWorkflowUseCase) Start(ctx context.Context, cmd manualapp.Command) (manualapp.StartResult, error) {
@@ -113,6 +130,105 @@ func (s *stubManualMailWorkflowListUseCase) List(ctx context.Context, query manu
 	return manualapp.ListResult{Items: []manualapp.WorkflowHistoryListItem{}}, nil
 }
 
+type stubManualMailWorkflowDetailUseCase struct{}
+
+func (s *stubManualMailWorkflowDetailUseCase) Get(ctx context.Context, query manualapp.DetailQuery) (manualapp.WorkflowHistoryDetail, error) {
+	return manualapp.WorkflowHistoryDetail{
+		WorkflowID: query.WorkflowID,
+		Failures:   []manualapp.WorkflowStageFailureItem{},
+	}, nil
+}
+
+type stubManualMailWorkflowCancelUseCase struct{}
+
+func (s *stubManualMailWorkflowCancelUseCase) Cancel(ctx context.Context, cmd manualapp.CancelCommand) (manualapp.CancelResult, error) {
+	return manualapp.CancelResult{
+		WorkflowID: cmd.WorkflowID,
+		Status:     manualapp.WorkflowStatusCancelled,
+	}, nil
+}
+
+type stubManualMailWorkflowRetryUseCase struct{}
+
+func (s *stubManualMailWorkflowRetryUseCase) Retry(ctx context.Context, cmd manualapp.RetryCommand) (manualapp.StartResult, error) {
+	return manualapp.StartResult{
+		WorkflowID: "wf-retry",
+		Status:     manualapp.WorkflowStatusQueued,
+	}, nil
+}
+
+type stubManualMailWorkflowResumeUseCase struct{}
+
+func (s *stubManualMailWorkflowResumeUseCase) Resume(ctx context.Context, cmd manualapp.ResumeCommand) (manualapp.StartResult, error) {
+	return manualapp.StartResult{
+		WorkflowID: cmd.WorkflowID,
+		Status:     manualapp.WorkflowStatusQueued,
+	}, nil
+}
+
+type stubManualMailWorkflowPreviewUseCase struct{}
+
+func (s *stubManualMailWorkflowPreviewUseCase) Get(ctx context.Context, query manualapp.PreviewQuery) (manualapp.WorkflowPreviewView, error) {
+	return manualapp.WorkflowPreviewView{}, manualapp.ErrWorkflowPreviewNotReady
+}
+
+type stubManualMailWorkflowEventsUseCase struct{}
+
+func (s *stubManualMailWorkflowEventsUseCase) Subscribe(ctx context.Context, query manualapp.EventsQuery) (manualapp.EventStream, error) {
+	return manualapp.EventStream{
+		Snapshot: manualapp.WorkflowEvent{
+			Type:   manualapp.WorkflowEventSnapshot,
+			Status: manualapp.WorkflowStatusSucceeded,
+		},
+	}, nil
+}
+
+type stubManualMailWorkflowImportUseCase struct{}
+
+func (s *stubManualMailWorkflowImportUseCase) Import(ctx context.Context, cmd manualapp.ImportCommand) (manualapp.ImportResult, error) {
+	return manualapp.ImportResult{WorkflowID: "workflow-import", Status: "queued"}, nil
+}
+
+type stubManualMailWorkflowReanalyzeUseCase struct{}
+
+func (s *stubManualMailWorkflowReanalyzeUseCase) Reanalyze(ctx context.Context, cmd manualapp.ReanalyzeCommand) (manualapp.ReanalyzeResult, error) {
+	return manualapp.ReanalyzeResult{}, nil
+}
+
+type stubManualMailWorkflowScheduleUseCase struct{}
+
+func (s *stubManualMailWorkflowScheduleUseCase) Create(ctx context.Context, cmd manualapp.CreateScheduleCommand) (manualapp.WorkflowSchedule, error) {
+	return manualapp.WorkflowSchedule{ID: 1, UserID: cmd.UserID, ConnectionID: cmd.ConnectionID}, nil
+}
+
+func (s *stubManualMailWorkflowScheduleUseCase) List(ctx context.Context, userID uint) ([]manualapp.WorkflowSchedule, error) {
+	return []manualapp.WorkflowSchedule{}, nil
+}
+
+func (s *stubManualMailWorkflowScheduleUseCase) Delete(ctx context.Context, cmd manualapp.DeleteScheduleCommand) error {
+	return nil
+}
+
+type stubMailLabelUseCase struct{}
+
+func (s *stubMailLabelUseCase) ListLabels(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error) {
+	return nil, nil
+}
+
+type stubEmailBodyUseCase struct{}
+
+func (s *stubEmailBodyUseCase) GetBody(ctx context.Context, userID, emailID uint) (mfdomain.EmailBody, error) {
+	return mfdomain.EmailBody{}, mfdomain.ErrEmailBodyNotFound
+}
+
+func (s *stubEmailBodyUseCase) GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error) {
+	return nil, nil
+}
+
+func (s *stubEmailBodyUseCase) PurgeExpired(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
 type stubBillingListUseCase struct{}
 
 func (s *stubBillingListUseCase) List(ctx context.Context, query billingqueryapp.ListQuery) (billingqueryapp.ListResult, error) {
@@ -161,8 +277,28 @@ func TestNewRouterRegistersVersionedAndLegacyRoutes(t *testing.T) {
 		return macpresentation.NewController(&stubEmailCredentialUsecase{}, log)
 	})
 	assert.NoError(t, err)
+	err = container.Provide(func() *macpresentation.LabelController {
+		return macpresentation.NewLabelController(&stubMailLabelUseCase{}, log)
+	})
+	assert.NoError(t, err)
 	err = container.Provide(func() *manualpresentation.Controller {
-		return manualpresentation.NewController(&stubManualMailWorkflowUseCase{}, &stubManualMailWorkflowListUseCase{}, log)
+		return manualpresentation.NewController(&stubManualMailWorkflowUseCase{}, &stubManualMailWorkflowListUseCase{}, &stubManualMailWorkflowDetailUseCase{}, &stubManualMailWorkflowCancelUseCase{}, &stubManualMailWorkflowRetryUseCase{}, &stubManualMailWorkflowResumeUseCase{}, &stubManualMailWorkflowEventsUseCase{}, &stubManualMailWorkflowPreviewUseCase{}, log)
+	})
+	assert.NoError(t, err)
+	err = container.Provide(func() *manualpresentation.ScheduleController {
+		return manualpresentation.NewScheduleController(&stubManualMailWorkflowScheduleUseCase{}, log)
+	})
+	assert.NoError(t, err)
+	err = container.Provide(func() *manualpresentation.ImportController {
+		return manualpresentation.NewImportController(&stubManualMailWorkflowImportUseCase{}, log)
+	})
+	assert.NoError(t, err)
+	err = container.Provide(func() *manualpresentation.ReanalyzeController {
+		return manualpresentation.NewReanalyzeController(&stubManualMailWorkflowReanalyzeUseCase{}, log)
+	})
+	assert.NoError(t, err)
+	err = container.Provide(func() *emailpresentation.Controller {
+		return emailpresentation.NewController(&stubEmailBodyUseCase{}, log)
 	})
 	assert.NoError(t, err)
 	err = container.Provide(func() *billingpresentation.Controller {
@@ -200,10 +336,26 @@ func TestNewRouterRegistersVersionedAndLegacyRoutes(t *testing.T) {
 		"GET /api/v1/auth/check",
 		"GET /api/v1/mail-account-connections",
 		"DELETE /api/v1/mail-account-connections/:connection_id",
+		"GET /api/v1/mail-account-connections/:connection_id/labels",
 		"POST /api/v1/mail-account-connections/gmail/authorize",
 		"POST /api/v1/mail-account-connections/gmail/callback",
+		"POST /api/v1/mail-account-connections/imap",
+		"POST /api/v1/mail-account-connections/outlook/authorize",
+		"POST /api/v1/mail-account-connections/outlook/callback",
 		"GET /api/v1/manual-mail-workflows",
 		"POST /api/v1/manual-mail-workflows",
+		"GET /api/v1/manual-mail-workflows/:workflow_id",
+		"POST /api/v1/manual-mail-workflows/:workflow_id/cancel",
+		"POST /api/v1/manual-mail-workflows/:workflow_id/retry",
+		"POST /api/v1/manual-mail-workflows/:workflow_id/resume",
+		"GET /api/v1/manual-mail-workflows/:workflow_id/preview",
+		"GET /api/v1/manual-mail-workflows/:workflow_id/events",
+		"GET /api/v1/manual-mail-workflow-schedules",
+		"POST /api/v1/manual-mail-workflow-schedules",
+		"DELETE /api/v1/manual-mail-workflow-schedules/:schedule_id",
+		"POST /api/v1/manual-mail-workflow-imports",
+		"POST /api/v1/manual-mail-workflow-reanalyses",
+		"GET /api/v1/emails/:email_id/source",
 		"GET /api/v1/billings",
 		"GET /api/v1/billings/summary/monthly-trend",
 		"GET /api/v1/billings/summary/monthly-detail/:year_month",
@@ -1,118 +1,93 @@
 package server
 
 import (
+	"business/internal/app/bootstrap"
 	"business/internal/app/middleware"
 	v1 "business/internal/app/router"
-	"business/internal/di"
-	"business/internal/library/crypto"
-	"business/internal/library/gmail"
-	"business/internal/library/gmailService"
 	"business/internal/library/logger"
-	"business/internal/library/mysql"
-	"business/internal/library/openai"
 	"business/internal/library/oswrapper"
-	"business/internal/library/ratelimit"
-	"business/internal/library/secret"
-	"business/internal/library/timewrapper"
+	mfinfra "business/internal/mailfetch/infrastructure"
+	manualinfra "business/internal/manualmailworkflow/infrastructure"
 
 	"context"
+	"errors"
 	"net/http"
-	"os"
+	"os/signal"
 	"strings"
-
-	"golang.org/x/crypto/bcrypt"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// shutdownTimeout は停止シグナルを受けてから、処理中のリクエストとワークフローの中断を待つ上限。
+// デプロイ先の停止猶予（既定 30 秒）より短くする。
+const shutdownTimeout = 20 * time.Second
+
+// Run は HTTP サーバーを起動し、SIGINT / SIGTERM を受けると新しい接続の受け付けをやめて停止する。
+// 組み込みのワークフローワーカーは実行中の job を中断して queue に戻してから止まる。
 func Run() {
 	g := gin.New()
-	clock := timewrapper.NewClock()
-
-	ctx := context.Background()
-	environment := strings.TrimSpace(os.Getenv("APP"))
-	if environment == "" {
-		panic("初期化に失敗しました: os.GetenvでAPP項目を取得できませんでした。")
-	}
-	var appSecretClient secret.Client
-	var err error
-	if environment != "local" && environment != "ci" {
-		appSecretName := strings.TrimSpace(os.Getenv("APP_SECRET_NAME"))
-		if appSecretName == "" {
-			panic("初期化に失敗しました: os.GetenvでAPP_SECRET_NAME項目を取得できませんでした。")
-		}
-		appSecretClient, err = secret.New(ctx, appSecretName)
-		if err != nil {
-			panic("アプリ共通シークレットクライアント初期化に失敗しました: " + err.Error())
-		}
 
-	}
-
-	osw, err := oswrapper.New(appSecretClient)
-	if err != nil {
-		panic("OsWrapper 初期化に失敗しました: " + err.Error())
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	baseLogger, err := logger.New("info", "backend", environment)
+	deps, err := bootstrap.Build(ctx, "backend", "server")
 	if err != nil {
-		panic("ロガー初期化に失敗しました: " + err.Error())
+		return
 	}
+	baseLogger := deps.BaseLogger
 	defer baseLogger.Sync()
 
+	clock := deps.Clock
+	osw := deps.OsWrapper
+	container := deps.Container
 	serverLogger := baseLogger.With(logger.Component("server"))
 	routerLogger := baseLogger.With(logger.Component("router"))
 
-	// DBインスタンス生成
-	db, err := mysql.New(osw, baseLogger)
-	if err != nil {
-		serverLogger.Error("DB 初期化時にエラーが発生しました", logger.Err(err))
-		return
-	}
-
-	providerLogger := baseLogger.With(logger.Component("ratelimit_provider"))
-	provider, err := ratelimit.NewProviderFromEnv(osw, providerLogger)
-	if err != nil {
-		serverLogger.Error("レートリミット初期化時にエラーが発生しました", logger.Err(err))
-		return
-	}
-	gmailLimiter := provider.GetGmailLimiter()
-	openaiLimiter := provider.GetOpenAILimiter()
-
-	// OpenAiクライアント作成
-	apiKey, err := osw.GetEnv("OPENAI_API_KEY")
-	if err != nil {
-		serverLogger.Error("環境変数 OPENAI_API_KEY の取得に失敗しました", logger.Err(err))
-		return
-	}
-	oa := openai.New(apiKey, openaiLimiter, baseLogger)
-	gs := gmailService.New()
-	gc := gmail.New(gmailLimiter, baseLogger)
-	emailTokenKey, err := osw.GetEnv("EMAIL_TOKEN_KEY_V1")
-	if err != nil {
-		serverLogger.Error("failed to read EMAIL_TOKEN_KEY_V1", logger.Err(err))
-		return
-	}
-
-	emailTokenSalt, err := osw.GetEnv("EMAIL_TOKEN_SALT")
-	if err != nil {
-		serverLogger.Error("failed to read EMAIL_TOKEN_SALT", logger.Err(err))
-		return
-	}
-
-	// Vaultインスタンス生成
-	vault, err := crypto.NewVault(crypto.VaultConfig{
-		KeyMaterial: []byte(emailTokenKey),
-		Salt:        []byte(emailTokenSalt),
-		Info:        "email-credential-encryption",
-		BcryptCost:  bcrypt.DefaultCost,
-	})
-	if err != nil {
-		serverLogger.Error("Vault 初期化時にエラーが発生しました", logger.Err(err))
-		return
+	// 手動メール取得ワークフローのワーカー・定期実行スケジューラ・放置 workflow と保存期間切れメール本文の回収を API プロセス内でも動かす（cmd/worker を分けて動かす場合は無効化する）
+	var background sync.WaitGroup
+	if isEmbeddedWorkflowWorkerEnabled(osw) {
+		if err := container.Invoke(func(
+			worker *manualinfra.WorkflowJobWorker,
+			scheduler *manualinfra.WorkflowScheduler,
+			reaper *manualinfra.WorkflowReaper,
+			bodyPurger *mfinfra.EmailBodyPurger,
+		) {
+			background.Add(4)
+			go func() {
+				defer background.Done()
+				if runErr := worker.Run(ctx); runErr != nil {
+					serverLogger.Error("ワークフローワーカーの実行に失敗しました", logger.Err(runErr))
+				}
+			}()
+			go func() {
+				defer background.Done()
+				if runErr := scheduler.Run(ctx); runErr != nil {
+					serverLogger.Error("ワークフロースケジューラの実行に失敗しました", logger.Err(runErr))
+				}
+			}()
+			go func() {
+				defer background.Done()
+				if runErr := reaper.Run(ctx); runErr != nil {
+					serverLogger.Error("放置ワークフローの回収に失敗しました", logger.Err(runErr))
+				}
+			}()
+			go func() {
+				defer background.Done()
+				if runErr := bodyPurger.Run(ctx); runErr != nil {
+					serverLogger.Error("保存期間切れメール本文の回収に失敗しました", logger.Err(runErr))
+				}
+			}()
+		}); err != nil {
+			stop()
+			background.Wait()
+			serverLogger.Error("ワークフローワーカーの初期化に失敗しました", logger.Err(err))
+			return
+		}
 	}
 
-	// DIを行う
-	container := di.BuildContainer(db, oa, gs, gc, osw, provider, baseLogger, vault)
 	var isUseSSL string
 	isUseSSL, err = osw.GetEnv("USE_SSL")
 	if err != nil {
@@ -158,6 +133,65 @@ func Run() {
 		return
 	}
 	addr := ":8080"
-	serverLogger.Info("HTTP サーバーを起動します", logger.String("addr", addr))
-	router.Run(addr)
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: router,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serverLogger.Info("HTTP サーバーを起動します", logger.String("addr", addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	select {
+	case <-ctx.Done():
+		serverLogger.Info("停止シグナルを受け取ったため HTTP サーバーを停止します")
+	case err := <-serveErr:
+		if err != nil {
+			serverLogger.Error("HTTP サーバーの実行に失敗しました", logger.Err(err))
+		}
+		stop()
+	}
+
+	shutdown(srv, &background, serverLogger)
+}
+
+// shutdown は処理中のリクエストを shutdownTimeout まで待ってから HTTP サーバーを閉じ、
+// 同じ期限までワークフローワーカーとスケジューラの停止を待つ。
+// SSE のように終わらない接続は期限を過ぎた時点で切断する。
+func shutdown(srv *http.Server, background *sync.WaitGroup, serverLogger logger.Interface) {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		serverLogger.Warn("期限内に終わらなかった接続を切断します", logger.Err(err))
+		if closeErr := srv.Close(); closeErr != nil {
+			serverLogger.Error("HTTP サーバーの切断に失敗しました", logger.Err(closeErr))
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		serverLogger.Info("HTTP サーバーを停止しました")
+	case <-shutdownCtx.Done():
+		serverLogger.Error("ワークフローワーカーの停止を待たずに終了します", logger.Err(shutdownCtx.Err()))
+	}
+}
+
+// isEmbeddedWorkflowWorkerEnabled は MANUAL_MAIL_WORKFLOW_EMBEDDED_WORKER が false 以外なら true を返す。
+func isEmbeddedWorkflowWorkerEnabled(osw oswrapper.OsWapperInterface) bool {
+	value, err := osw.GetEnv("MANUAL_MAIL_WORKFLOW_EMBEDDED_WORKER")
+	if err != nil {
+		return true
+	}
+	return !strings.EqualFold(strings.TrimSpace(value), "false")
 }
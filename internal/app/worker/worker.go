@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"business/internal/app/bootstrap"
+	"business/internal/library/logger"
+	mfinfra "business/internal/mailfetch/infrastructure"
+	manualinfra "business/internal/manualmailworkflow/infrastructure"
+
+	"context"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Run は手動メール取得ワークフローの job を処理する worker プロセスを起動する。
+// 定期実行スケジューラ、放置 workflow の回収、保存期間切れメール本文の回収も同じプロセスで動かす。
+// SIGINT / SIGTERM を受けると新しい job の claim をやめ、実行中の job を中断して queue に戻してから戻る。
+func Run() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	deps, err := bootstrap.Build(ctx, "worker", "worker")
+	if err != nil {
+		return
+	}
+	baseLogger := deps.BaseLogger
+	defer baseLogger.Sync()
+
+	workerLogger := baseLogger.With(logger.Component("worker"))
+
+	var jobWorker *manualinfra.WorkflowJobWorker
+	var scheduler *manualinfra.WorkflowScheduler
+	var reaper *manualinfra.WorkflowReaper
+	var bodyPurger *mfinfra.EmailBodyPurger
+	if err := deps.Container.Invoke(func(w *manualinfra.WorkflowJobWorker, s *manualinfra.WorkflowScheduler, r *manualinfra.WorkflowReaper, p *mfinfra.EmailBodyPurger) {
+		jobWorker = w
+		scheduler = s
+		reaper = r
+		bodyPurger = p
+	}); err != nil {
+		workerLogger.Error("ワークフローワーカーの初期化に失敗しました", logger.Err(err))
+		return
+	}
+
+	// 定期実行スケジューラは job を enqueue するだけ、回収は履歴を failed にするか期限切れの本文を消すだけなので、同じプロセスで並行に動かす。
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		if err := scheduler.Run(ctx); err != nil {
+			workerLogger.Error("ワークフロースケジューラの実行に失敗しました", logger.Err(err))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := reaper.Run(ctx); err != nil {
+			workerLogger.Error("放置ワークフローの回収に失敗しました", logger.Err(err))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := bodyPurger.Run(ctx); err != nil {
+			workerLogger.Error("保存期間切れメール本文の回収に失敗しました", logger.Err(err))
+		}
+	}()
+	defer wg.Wait()
+
+	workerLogger.Info("ワークフローワーカーを起動します")
+	if err := jobWorker.Run(ctx); err != nil {
+		workerLogger.Error("ワークフローワーカーの実行に失敗しました", logger.Err(err))
+		stop()
+		return
+	}
+	workerLogger.Info("ワークフローワーカーを停止しました")
+}
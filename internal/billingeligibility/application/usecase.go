@@ -48,10 +48,21 @@ func (t EligibilityTarget) Validate() error {
 	return nil
 }
 
+// validateForDryRun checks only the vendor name because dry-run items are never persisted
+// and a vendor that would be auto-registered has no ID yet.
+func (t EligibilityTarget) validateForDryRun() error {
+	if t.VendorName == "" {
+		return errors.New("vendor_name is required")
+	}
+	return nil
+}
+
 // Command is the billingeligibility stage input.
 type Command struct {
 	UserID        uint
 	ResolvedItems []EligibilityTarget
+	// DryRun accepts unsaved items whose ParsedEmailID, EmailID or VendorID are still zero.
+	DryRun bool
 }
 
 // Result is the billingeligibility stage output.
@@ -105,7 +116,11 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 	result := Result{}
 	for _, target := range cmd.ResolvedItems {
 		target = target.Normalize()
-		if err := target.Validate(); err != nil {
+		validate := target.Validate
+		if cmd.DryRun {
+			validate = target.validateForDryRun
+		}
+		if err := validate(); err != nil {
 			result.Failures = append(result.Failures, domain.Failure{
 				ParsedEmailID:     target.ParsedEmailID,
 				EmailID:           target.EmailID,
@@ -216,6 +216,48 @@ func TestUseCaseExecute_ProductNameMissingBecomesIneligible(t *testing.T) {
 	}
 }
 
+func TestUseCaseExecute_DryRunAcceptsUnsavedItems(t *testing.T) {
+	t.Parallel()
+
+	amount := 1200.0
+	currency := "JPY"
+	productName := "Plan"
+	billingNumber := "INV-001"
+	paymentCycle := "one_time"
+	item := EligibilityTarget{
+		ExternalMessageID: "msg-1",
+		VendorName:        "Acme",
+		MatchedBy:         "name_exact",
+		Data: commondomain.ParsedEmail{
+			ProductNameRaw: &productName,
+			BillingNumber:  &billingNumber,
+			Amount:         &amount,
+			Currency:       &currency,
+			PaymentCycle:   &paymentCycle,
+		},
+	}
+
+	uc := NewUseCase(logger.NewNop())
+	result, err := uc.Execute(context.Background(), Command{UserID: 10, ResolvedItems: []EligibilityTarget{item}})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Code != domain.FailureCodeInvalidEligibilityTarget {
+		t.Fatalf("expected invalid target failure without dry run, got %+v", result)
+	}
+
+	result, err = uc.Execute(context.Background(), Command{UserID: 10, ResolvedItems: []EligibilityTarget{item}, DryRun: true})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.EligibleCount != 1 || len(result.Failures) != 0 {
+		t.Fatalf("expected one eligible item, got %+v", result)
+	}
+	if result.EligibleItems[0].VendorID != 0 || result.EligibleItems[0].VendorName != "Acme" {
+		t.Fatalf("unexpected eligible item: %+v", result.EligibleItems[0])
+	}
+}
+
 func TestUseCaseExecute_NilContext(t *testing.T) {
 	t.Parallel()
 
@@ -25,6 +25,36 @@ type FetchedEmailDTO struct {
 	Date       time.Time `json:"date"`
 	Body       string    `json:"body"`
 	BodyDigest string    `json:"bodyDigest"`
+	// Attachments は本文とは別に解析へ渡す添付ファイルのテキストです
+	Attachments []FetchedAttachmentDTO `json:"attachments,omitempty"`
+}
+
+// 添付ファイルのテキストを取得できなかった理由です
+const (
+	// AttachmentFailureTooLarge はサイズ上限を超えた添付ファイルです
+	AttachmentFailureTooLarge = "too_large"
+	// AttachmentFailureLimitExceeded は 1 通あたりの添付ファイル数の上限を超えた分です
+	AttachmentFailureLimitExceeded = "limit_exceeded"
+	// AttachmentFailureDownloadFailed は添付ファイルのダウンロードに失敗したものです
+	AttachmentFailureDownloadFailed = "download_failed"
+	// AttachmentFailureEncrypted はパスワード付きの PDF です
+	AttachmentFailureEncrypted = "encrypted"
+	// AttachmentFailureUnreadable は PDF として読み取れなかったものです
+	AttachmentFailureUnreadable = "unreadable"
+	// AttachmentFailureNoText はテキストを含まない（画像だけの）PDF です
+	AttachmentFailureNoText = "no_text"
+)
+
+// FetchedAttachmentDTO は取得メールの添付ファイルから抽出したテキストを表す共通DTOです
+type FetchedAttachmentDTO struct {
+	Filename string `json:"filename"`
+	MimeType string `json:"mimeType"`
+	Size     int64  `json:"size"`
+	Text     string `json:"text"`
+	// Truncated は Text が文字数の上限で切り詰められたことを表します
+	Truncated bool `json:"truncated,omitempty"`
+	// FailureReason はテキストを取得できなかった理由で、空でなければ Text は空です
+	FailureReason string `json:"failureReason,omitempty"`
 }
 
 // ExtractSenderName は From フィールドから送信者名を抽出します
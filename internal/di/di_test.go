@@ -17,6 +17,7 @@ import (
 	"business/internal/library/oswrapper"
 	"business/internal/library/ratelimit"
 	"business/internal/library/timewrapper"
+	manualinfra "business/internal/manualmailworkflow/infrastructure"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -111,6 +112,23 @@ func TestBuildContainer_ResolvesAuthPresentation(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestBuildContainer_ResolvesManualMailWorkflowWorker(t *testing.T) {
+	t.Parallel()
+
+	conn, oa, gs, gc, osw, provider, log, vault := newBuildContainerTestDeps()
+	container := BuildContainer(conn, oa, gs, gc, osw, provider, log, vault)
+
+	err := container.Invoke(func(
+		queue *manualinfra.GormWorkflowJobQueue,
+		worker *manualinfra.WorkflowJobWorker,
+	) {
+		assert.NotNil(t, queue)
+		assert.NotNil(t, worker)
+	})
+
+	require.NoError(t, err)
+}
+
 func TestBuildContainer_DoesNotResolveUseCaseInterface(t *testing.T) {
 	t.Parallel()
 
@@ -4,12 +4,19 @@ import (
 	macpresentation "business/internal/app/presentation/mailaccountconnection"
 	"business/internal/library/crypto"
 	"business/internal/library/gmailService"
+	"business/internal/library/imap"
 	"business/internal/library/logger"
+	"business/internal/library/msgraph"
 	"business/internal/library/mysql"
 	"business/internal/library/oswrapper"
+	"business/internal/library/retry"
 	"business/internal/library/timewrapper"
 	"business/internal/mailaccountconnection/application"
 	"business/internal/mailaccountconnection/infrastructure"
+	mfapp "business/internal/mailfetch/application"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"go.uber.org/dig"
 )
@@ -36,17 +43,53 @@ func ProvideMailAccountConnectionDependencies(container *dig.Container) {
 		return infrastructure.NewGmailProfileFetcher(gs, log)
 	})
 
+	// Outlook OAuth config and Graph client factory (shared with the Outlook mail fetcher)
+	_ = container.Provide(func(osw *oswrapper.OsWrapper) *msgraph.OAuthConfigLoader {
+		return msgraph.NewOAuthConfigLoader(osw)
+	})
+	_ = container.Provide(func(log *logger.Logger) *msgraph.ClientFactory {
+		return msgraph.NewClientFactory(msgraph.DefaultBaseURL, retry.DefaultBackoff, log)
+	})
+
+	// OutlookProfileFetcher
+	_ = container.Provide(func(graph *msgraph.ClientFactory, log *logger.Logger) *infrastructure.OutlookProfileFetcher {
+		return infrastructure.NewOutlookProfileFetcher(graph, log)
+	})
+
+	// IMAP Dialer (shared with the IMAP mail fetcher)
+	_ = container.Provide(func(osw *oswrapper.OsWrapper, log *logger.Logger) (*imap.Dialer, error) {
+		allowLocal, err := imapAllowLocalServers(osw)
+		if err != nil {
+			return nil, err
+		}
+		return imap.NewDialer(0, log).WithPrivateAddresses(allowLocal), nil
+	})
+
+	// IMAPLoginVerifier
+	_ = container.Provide(func(dialer *imap.Dialer, log *logger.Logger) *infrastructure.IMAPLoginVerifier {
+		return infrastructure.NewIMAPLoginVerifier(dialer, log)
+	})
+
 	// UseCase
 	_ = container.Provide(func(
 		repo *infrastructure.Repository,
 		oauthCfg *gmailService.OAuthConfigLoader,
 		exchanger *infrastructure.OAuthTokenExchanger,
 		profiler *infrastructure.GmailProfileFetcher,
+		imapLogin *infrastructure.IMAPLoginVerifier,
+		outlookCfg *msgraph.OAuthConfigLoader,
+		outlookProfiler *infrastructure.OutlookProfileFetcher,
 		vault *crypto.Vault,
 		clock *timewrapper.Clock,
+		osw *oswrapper.OsWrapper,
 		log *logger.Logger,
-	) *application.UseCase {
-		return application.NewUseCase(repo, oauthCfg, exchanger, profiler, vault, clock, log)
+	) (*application.UseCase, error) {
+		allowLocal, err := imapAllowLocalServers(osw)
+		if err != nil {
+			return nil, err
+		}
+		return application.NewUseCase(repo, oauthCfg, exchanger, profiler, imapLogin, outlookCfg, outlookProfiler, vault, clock, log).
+			WithInsecureIMAP(allowLocal), nil
 	})
 
 	// Controller
@@ -56,4 +99,25 @@ func ProvideMailAccountConnectionDependencies(container *dig.Container) {
 	) *macpresentation.Controller {
 		return macpresentation.NewController(usecase, log)
 	})
+
+	_ = container.Provide(func(
+		labelUseCase mfapp.LabelUseCase,
+		log *logger.Logger,
+	) *macpresentation.LabelController {
+		return macpresentation.NewLabelController(labelUseCase, log)
+	})
+}
+
+// imapAllowLocalServers reads IMAP_ALLOW_LOCAL_SERVERS, which lets development setups reach a local
+// IMAP server without TLS. It is off unless set; an unparsable value fails startup.
+func imapAllowLocalServers(osw *oswrapper.OsWrapper) (bool, error) {
+	value, err := osw.GetEnv("IMAP_ALLOW_LOCAL_SERVERS")
+	if err != nil || strings.TrimSpace(value) == "" {
+		return false, nil
+	}
+	allow, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("invalid IMAP_ALLOW_LOCAL_SERVERS %q: must be true or false", value)
+	}
+	return allow, nil
 }
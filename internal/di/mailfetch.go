@@ -1,15 +1,27 @@
 package di
 
 import (
+	emailpresentation "business/internal/app/presentation/email"
+	"business/internal/library/blobstore"
 	"business/internal/library/crypto"
 	"business/internal/library/gmail"
 	"business/internal/library/gmailService"
+	"business/internal/library/imap"
 	"business/internal/library/logger"
+	"business/internal/library/msgraph"
+	"business/internal/library/oswrapper"
+	"business/internal/library/ratelimit"
 	"business/internal/library/timewrapper"
 	macinfra "business/internal/mailaccountconnection/infrastructure"
 	mfapp "business/internal/mailfetch/application"
 	mfinfra "business/internal/mailfetch/infrastructure"
+	"context"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"go.uber.org/dig"
 	"gorm.io/gorm"
 )
@@ -24,6 +36,22 @@ func ProvideMailFetchDependencies(container *dig.Container) {
 		return mfinfra.NewGormEmailRepositoryAdapter(db, clock, log)
 	})
 
+	_ = container.Provide(func(
+		db *gorm.DB,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) *mfinfra.GormSyncCheckpointRepository {
+		return mfinfra.NewGormSyncCheckpointRepository(db, clock, log)
+	})
+
+	_ = container.Provide(func(
+		db *gorm.DB,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) *mfinfra.GormFileMailRepository {
+		return mfinfra.NewGormFileMailRepository(db, clock, log)
+	})
+
 	_ = container.Provide(func(repo *macinfra.Repository, log *logger.Logger) *mfinfra.MailAccountConnectionReaderAdapter {
 		return mfinfra.NewMailAccountConnectionReaderAdapter(repo, log)
 	})
@@ -39,16 +67,173 @@ func ProvideMailFetchDependencies(container *dig.Container) {
 		return mfinfra.NewGmailSessionBuilder(repo, vault, oauthConfig, gmailServiceClient, gmailClient, log)
 	})
 
-	_ = container.Provide(func(builder *mfinfra.GmailSessionBuilder, log *logger.Logger) *mfinfra.DefaultMailFetcherFactory {
-		return mfinfra.NewDefaultMailFetcherFactory(builder, log)
+	_ = container.Provide(func(
+		repo *macinfra.Repository,
+		vault *crypto.Vault,
+		dialer *imap.Dialer,
+		log *logger.Logger,
+	) *mfinfra.IMAPSessionBuilder {
+		return mfinfra.NewIMAPSessionBuilder(repo, vault, dialer, log)
+	})
+
+	_ = container.Provide(func(
+		repo *macinfra.Repository,
+		vault *crypto.Vault,
+		oauthConfig *msgraph.OAuthConfigLoader,
+		graph *msgraph.ClientFactory,
+		log *logger.Logger,
+	) *mfinfra.OutlookSessionBuilder {
+		return mfinfra.NewOutlookSessionBuilder(repo, vault, oauthConfig, graph, log)
+	})
+
+	_ = container.Provide(func(
+		gmailBuilder *mfinfra.GmailSessionBuilder,
+		imapBuilder *mfinfra.IMAPSessionBuilder,
+		outlookBuilder *mfinfra.OutlookSessionBuilder,
+		fileMails *mfinfra.GormFileMailRepository,
+		gmailClient *gmail.Client,
+		log *logger.Logger,
+	) *mfinfra.DefaultMailFetcherFactory {
+		// IMAP and file messages pick their body part by the same GMAIL_BODY_PREFERENCE as Gmail.
+		return mfinfra.NewDefaultMailFetcherFactory(gmailBuilder, imapBuilder, outlookBuilder, fileMails, log).
+			WithBodyPreference(gmailClient.BodyPreference())
+	})
+
+	_ = container.Provide(func(gmailBuilder *mfinfra.GmailSessionBuilder, log *logger.Logger) *mfinfra.DefaultMailLabelLister {
+		return mfinfra.NewDefaultMailLabelLister(gmailBuilder, log)
+	})
+
+	_ = container.Provide(func(provider *ratelimit.Provider) *mfinfra.RedisMailLabelCache {
+		return mfinfra.NewRedisMailLabelCache(provider.GetRedisClient(), 0)
+	})
+
+	_ = container.Provide(func(
+		connectionRepo *mfinfra.MailAccountConnectionReaderAdapter,
+		lister *mfinfra.DefaultMailLabelLister,
+		cache *mfinfra.RedisMailLabelCache,
+		log *logger.Logger,
+	) mfapp.LabelUseCase {
+		return mfapp.NewLabelUseCase(connectionRepo, lister, cache, log)
 	})
 
 	_ = container.Provide(func(
 		connectionRepo *mfinfra.MailAccountConnectionReaderAdapter,
 		fetcherFactory *mfinfra.DefaultMailFetcherFactory,
 		emailRepo *mfinfra.GormEmailRepositoryAdapter,
+		checkpointRepo *mfinfra.GormSyncCheckpointRepository,
+		bodyStore *mfinfra.EncryptedEmailBodyStore,
+		clock *timewrapper.Clock,
 		log *logger.Logger,
 	) mfapp.UseCase {
-		return mfapp.NewUseCase(connectionRepo, fetcherFactory, emailRepo, log)
+		var bodyWriter mfapp.EmailBodyWriter
+		if bodyStore.Enabled() {
+			bodyWriter = bodyStore
+		}
+		return mfapp.NewUseCase(connectionRepo, fetcherFactory, emailRepo, checkpointRepo, bodyWriter, clock, log)
+	})
+
+	_ = container.Provide(func(
+		db *gorm.DB,
+		vault *crypto.Vault,
+		osw *oswrapper.OsWrapper,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) (*mfinfra.EncryptedEmailBodyStore, error) {
+		blobs, err := newEmailBodyBlobStore(osw)
+		if err != nil {
+			return nil, err
+		}
+		// EMAIL_BODY_RETENTION is optional; an unparsable value fails startup rather than silently using the default.
+		retention := mfinfra.DefaultEmailBodyRetention
+		if value := optionalEnv(osw, "EMAIL_BODY_RETENTION"); value != "" {
+			parsed, parseErr := time.ParseDuration(value)
+			if parseErr != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid EMAIL_BODY_RETENTION %q: must be a positive duration such as 2160h", value)
+			}
+			retention = parsed
+		}
+		return mfinfra.NewEncryptedEmailBodyStore(db, blobs, vault, retention, clock, log), nil
 	})
+
+	_ = container.Provide(func(
+		bodyStore *mfinfra.EncryptedEmailBodyStore,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) mfapp.EmailBodyUseCase {
+		return mfapp.NewEmailBodyUseCase(bodyStore, clock, log)
+	})
+
+	_ = container.Provide(func(
+		bodies mfapp.EmailBodyUseCase,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) *mfinfra.EmailBodyPurger {
+		return mfinfra.NewEmailBodyPurger(bodies, clock, 0, log)
+	})
+
+	_ = container.Provide(func(bodies mfapp.EmailBodyUseCase, log *logger.Logger) *emailpresentation.Controller {
+		return emailpresentation.NewController(bodies, log)
+	})
+}
+
+// newEmailBodyBlobStore builds the blob store selected by EMAIL_BODY_STORE.
+// An empty value or "none" disables email body storage and returns a nil store.
+func newEmailBodyBlobStore(osw *oswrapper.OsWrapper) (blobstore.Store, error) {
+	kind := strings.ToLower(optionalEnv(osw, "EMAIL_BODY_STORE"))
+	switch kind {
+	case "", "none":
+		return nil, nil
+	case "local":
+		dir := optionalEnv(osw, "EMAIL_BODY_STORE_DIR")
+		if dir == "" {
+			return nil, fmt.Errorf("EMAIL_BODY_STORE_DIR is required when EMAIL_BODY_STORE is local")
+		}
+		return blobstore.NewLocal(dir)
+	case "s3":
+		region := optionalEnv(osw, "EMAIL_BODY_STORE_S3_REGION")
+		if region == "" {
+			region = "ap-northeast-1"
+		}
+		credentials, err := emailBodyS3Credentials(osw, region)
+		if err != nil {
+			return nil, err
+		}
+		return blobstore.NewS3(blobstore.S3Config{
+			Endpoint:    optionalEnv(osw, "EMAIL_BODY_STORE_S3_ENDPOINT"),
+			Region:      region,
+			Bucket:      optionalEnv(osw, "EMAIL_BODY_STORE_S3_BUCKET"),
+			Prefix:      optionalEnv(osw, "EMAIL_BODY_STORE_S3_PREFIX"),
+			Credentials: credentials,
+		})
+	default:
+		return nil, fmt.Errorf("invalid EMAIL_BODY_STORE %q: must be none, local or s3", kind)
+	}
+}
+
+// emailBodyS3Credentials uses static keys when both are set, such as for MinIO,
+// and otherwise the default AWS credential chain.
+func emailBodyS3Credentials(osw *oswrapper.OsWrapper, region string) (aws.CredentialsProvider, error) {
+	accessKeyID := optionalEnv(osw, "EMAIL_BODY_STORE_S3_ACCESS_KEY_ID")
+	secretAccessKey := optionalEnv(osw, "EMAIL_BODY_STORE_S3_SECRET_ACCESS_KEY")
+	if accessKeyID != "" || secretAccessKey != "" {
+		if accessKeyID == "" || secretAccessKey == "" {
+			return nil, fmt.Errorf("EMAIL_BODY_STORE_S3_ACCESS_KEY_ID and EMAIL_BODY_STORE_S3_SECRET_ACCESS_KEY must be set together")
+		}
+		return aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, Source: "EmailBodyStoreEnv"}, nil
+		}), nil
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config for email body store: %w", err)
+	}
+	return cfg.Credentials, nil
+}
+
+func optionalEnv(osw *oswrapper.OsWrapper, key string) string {
+	value, err := osw.GetEnv(key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(value)
 }
@@ -5,12 +5,19 @@ import (
 	billingapp "business/internal/billing/application"
 	beapp "business/internal/billingeligibility/application"
 	"business/internal/library/logger"
+	"business/internal/library/oswrapper"
+	"business/internal/library/ratelimit"
 	"business/internal/library/timewrapper"
+	macinfra "business/internal/mailaccountconnection/infrastructure"
 	maapp "business/internal/mailanalysis/application"
 	mfapp "business/internal/mailfetch/application"
+	mfinfra "business/internal/mailfetch/infrastructure"
 	manualapp "business/internal/manualmailworkflow/application"
 	manualinfra "business/internal/manualmailworkflow/infrastructure"
 	vrapp "business/internal/vendorresolution/application"
+	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/dig"
 	"gorm.io/gorm"
@@ -22,6 +29,14 @@ func ProvideManualMailWorkflowDependencies(container *dig.Container) {
 		return manualinfra.NewDirectManualMailFetchAdapter(usecase)
 	})
 
+	_ = container.Provide(func(usecase mfapp.LabelUseCase) *manualinfra.DirectMailLabelAdapter {
+		return manualinfra.NewDirectMailLabelAdapter(usecase)
+	})
+
+	_ = container.Provide(func(usecase mfapp.EmailBodyUseCase) *manualinfra.DirectEmailBodyAdapter {
+		return manualinfra.NewDirectEmailBodyAdapter(usecase)
+	})
+
 	_ = container.Provide(func(usecase maapp.UseCase) *manualinfra.DirectMailAnalysisAdapter {
 		return manualinfra.NewDirectMailAnalysisAdapter(usecase)
 	})
@@ -46,33 +61,82 @@ func ProvideManualMailWorkflowDependencies(container *dig.Container) {
 		return manualinfra.NewGormWorkflowStatusRepository(db, clock, log)
 	})
 
+	_ = container.Provide(func(provider *ratelimit.Provider, log *logger.Logger) *manualinfra.RedisWorkflowEventBus {
+		return manualinfra.NewRedisWorkflowEventBus(provider.GetRedisClient(), log)
+	})
+
+	_ = container.Provide(func(
+		repository *manualinfra.GormWorkflowStatusRepository,
+		eventBus *manualinfra.RedisWorkflowEventBus,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) *manualinfra.PublishingWorkflowStatusRepository {
+		return manualinfra.NewPublishingWorkflowStatusRepository(repository, eventBus, clock, log)
+	})
+
+	// Additional stages are registered here as StagePlacement values after the built-in stages.
 	_ = container.Provide(func(
 		fetchStage *manualinfra.DirectManualMailFetchAdapter,
 		analyzeStage *manualinfra.DirectMailAnalysisAdapter,
 		vendorResolutionStage *manualinfra.DirectVendorResolutionAdapter,
 		billingEligibilityStage *manualinfra.DirectBillingEligibilityAdapter,
 		billingStage *manualinfra.DirectBillingAdapter,
-		repository *manualinfra.GormWorkflowStatusRepository,
+	) (manualapp.StagePipeline, error) {
+		return manualapp.NewStagePipeline(manualapp.BuiltinStages{
+			Fetch:              fetchStage,
+			Analyze:            analyzeStage,
+			VendorResolution:   vendorResolutionStage,
+			BillingEligibility: billingEligibilityStage,
+			Billing:            billingStage,
+		})
+	})
+
+	_ = container.Provide(func(
+		pipeline manualapp.StagePipeline,
+		repository *manualinfra.PublishingWorkflowStatusRepository,
 		clock *timewrapper.Clock,
 		log *logger.Logger,
 	) manualapp.UseCase {
-		return manualapp.NewUseCase(fetchStage, analyzeStage, vendorResolutionStage, billingEligibilityStage, billingStage, repository, clock, log)
+		return manualapp.NewPipelineUseCase(pipeline, repository, repository, repository, repository, clock, log)
 	})
 
 	_ = container.Provide(func(
+		db *gorm.DB,
+		eventBus *manualinfra.RedisWorkflowEventBus,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) *manualinfra.GormWorkflowJobQueue {
+		return manualinfra.NewGormWorkflowJobQueue(db, clock, log).WithEventPublisher(eventBus)
+	})
+
+	_ = container.Provide(func(
+		queue *manualinfra.GormWorkflowJobQueue,
 		runner manualapp.UseCase,
+		repository *manualinfra.PublishingWorkflowStatusRepository,
+		clock *timewrapper.Clock,
 		log *logger.Logger,
-	) *manualinfra.InProcessWorkflowDispatcher {
-		return manualinfra.NewInProcessWorkflowDispatcher(runner, log)
+	) *manualinfra.WorkflowJobWorker {
+		return manualinfra.NewWorkflowJobWorker(queue, runner, repository, clock, manualinfra.DefaultWorkflowJobWorkerConfig(), log)
+	})
+
+	_ = container.Provide(func(provider *ratelimit.Provider) *manualinfra.RedisWorkflowConnectionLock {
+		return manualinfra.NewRedisWorkflowConnectionLock(provider.GetRedisClient(), 0)
+	})
+
+	_ = container.Provide(func(connections *mfinfra.MailAccountConnectionReaderAdapter) *manualinfra.DirectUsableConnectionAdapter {
+		return manualinfra.NewDirectUsableConnectionAdapter(connections)
 	})
 
 	_ = container.Provide(func(
-		dispatcher *manualinfra.InProcessWorkflowDispatcher,
-		repository *manualinfra.GormWorkflowStatusRepository,
+		dispatcher *manualinfra.GormWorkflowJobQueue,
+		repository *manualinfra.PublishingWorkflowStatusRepository,
+		lock *manualinfra.RedisWorkflowConnectionLock,
+		connections *manualinfra.DirectUsableConnectionAdapter,
+		labels *manualinfra.DirectMailLabelAdapter,
 		clock *timewrapper.Clock,
 		log *logger.Logger,
 	) manualapp.StartUseCase {
-		return manualapp.NewStartUseCase(dispatcher, repository, clock, log)
+		return manualapp.NewStartUseCase(dispatcher, repository, repository, lock, connections, labels, clock, log)
 	})
 
 	_ = container.Provide(func(
@@ -82,11 +146,186 @@ func ProvideManualMailWorkflowDependencies(container *dig.Container) {
 		return manualapp.NewListUseCase(repository, log)
 	})
 
+	_ = container.Provide(func(
+		repository *manualinfra.GormWorkflowStatusRepository,
+		log *logger.Logger,
+	) manualapp.DetailUseCase {
+		return manualapp.NewDetailUseCase(repository, log)
+	})
+
+	_ = container.Provide(func(
+		repository *manualinfra.PublishingWorkflowStatusRepository,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) manualapp.CancelUseCase {
+		return manualapp.NewCancelUseCase(repository, clock, log)
+	})
+
+	_ = container.Provide(func(
+		dispatcher *manualinfra.GormWorkflowJobQueue,
+		repository *manualinfra.PublishingWorkflowStatusRepository,
+		lock *manualinfra.RedisWorkflowConnectionLock,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) manualapp.RetryUseCase {
+		return manualapp.NewRetryUseCase(repository, dispatcher, repository, repository, lock, clock, log)
+	})
+
+	_ = container.Provide(func(
+		dispatcher *manualinfra.GormWorkflowJobQueue,
+		repository *manualinfra.PublishingWorkflowStatusRepository,
+		lock *manualinfra.RedisWorkflowConnectionLock,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) manualapp.ResumeUseCase {
+		return manualapp.NewResumeUseCase(repository, dispatcher, repository, repository, lock, clock, log)
+	})
+
+	_ = container.Provide(func(
+		repository *manualinfra.GormWorkflowStatusRepository,
+		eventBus *manualinfra.RedisWorkflowEventBus,
+		log *logger.Logger,
+	) manualapp.EventsUseCase {
+		return manualapp.NewEventsUseCase(repository, eventBus, log)
+	})
+
+	_ = container.Provide(func(
+		repository *manualinfra.GormWorkflowStatusRepository,
+		log *logger.Logger,
+	) manualapp.PreviewUseCase {
+		return manualapp.NewPreviewUseCase(repository, log)
+	})
+
+	_ = container.Provide(func(
+		db *gorm.DB,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) *manualinfra.GormWorkflowScheduleRepository {
+		return manualinfra.NewGormWorkflowScheduleRepository(db, clock, log)
+	})
+
+	_ = container.Provide(func(
+		repository *manualinfra.GormWorkflowScheduleRepository,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) manualapp.ScheduleUseCase {
+		return manualapp.NewScheduleUseCase(repository, clock, log)
+	})
+
+	_ = container.Provide(func(
+		repository *manualinfra.GormWorkflowScheduleRepository,
+		startUseCase manualapp.StartUseCase,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) manualapp.ScheduleDispatchUseCase {
+		return manualapp.NewScheduleDispatchUseCase(repository, startUseCase, clock, log)
+	})
+
+	_ = container.Provide(func(
+		connections *macinfra.Repository,
+		mails *mfinfra.GormFileMailRepository,
+		clock *timewrapper.Clock,
+	) *manualinfra.DirectMailImportAdapter {
+		return manualinfra.NewDirectMailImportAdapter(connections, mails, clock)
+	})
+
+	_ = container.Provide(func(
+		store *manualinfra.DirectMailImportAdapter,
+		startUseCase manualapp.StartUseCase,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) manualapp.ImportUseCase {
+		return manualapp.NewImportUseCase(store, startUseCase, clock, log)
+	})
+
+	// Reanalysis calls the stages directly without a workflow history; fetch is used only to refetch bodies that are not stored.
+	_ = container.Provide(func(
+		repository *manualinfra.GormWorkflowStatusRepository,
+		bodies *manualinfra.DirectEmailBodyAdapter,
+		fetchStage *manualinfra.DirectManualMailFetchAdapter,
+		analyzeStage *manualinfra.DirectMailAnalysisAdapter,
+		vendorResolutionStage *manualinfra.DirectVendorResolutionAdapter,
+		billingEligibilityStage *manualinfra.DirectBillingEligibilityAdapter,
+		billingStage *manualinfra.DirectBillingAdapter,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) manualapp.ReanalyzeUseCase {
+		return manualapp.NewReanalyzeUseCase(repository, bodies, manualapp.BuiltinStages{
+			Fetch:              fetchStage,
+			Analyze:            analyzeStage,
+			VendorResolution:   vendorResolutionStage,
+			BillingEligibility: billingEligibilityStage,
+			Billing:            billingStage,
+		}, clock, log)
+	})
+
+	_ = container.Provide(func(
+		dispatcher manualapp.ScheduleDispatchUseCase,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) *manualinfra.WorkflowScheduler {
+		return manualinfra.NewWorkflowScheduler(dispatcher, clock, manualinfra.DefaultWorkflowSchedulerConfig(), log)
+	})
+
+	_ = container.Provide(func(
+		repository *manualinfra.GormWorkflowStatusRepository,
+		statuses *manualinfra.PublishingWorkflowStatusRepository,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) manualapp.ReapUseCase {
+		return manualapp.NewReapUseCase(repository, statuses, clock, log)
+	})
+
+	_ = container.Provide(func(
+		reapUseCase manualapp.ReapUseCase,
+		osw *oswrapper.OsWrapper,
+		clock *timewrapper.Clock,
+		log *logger.Logger,
+	) (*manualinfra.WorkflowReaper, error) {
+		cfg := manualinfra.DefaultWorkflowReaperConfig()
+		// MANUAL_MAIL_WORKFLOW_STALE_AFTER is optional; an unparsable value fails startup rather than silently using the default.
+		if value, err := osw.GetEnv("MANUAL_MAIL_WORKFLOW_STALE_AFTER"); err == nil && strings.TrimSpace(value) != "" {
+			staleAfter, parseErr := time.ParseDuration(strings.TrimSpace(value))
+			if parseErr != nil || staleAfter <= 0 {
+				return nil, fmt.Errorf("invalid MANUAL_MAIL_WORKFLOW_STALE_AFTER %q: must be a positive duration such as 3h", value)
+			}
+			cfg.StaleAfter = staleAfter
+		}
+		return manualinfra.NewWorkflowReaper(reapUseCase, clock, cfg, log), nil
+	})
+
 	_ = container.Provide(func(
 		startUseCase manualapp.StartUseCase,
 		listUseCase manualapp.ListUseCase,
+		detailUseCase manualapp.DetailUseCase,
+		cancelUseCase manualapp.CancelUseCase,
+		retryUseCase manualapp.RetryUseCase,
+		resumeUseCase manualapp.ResumeUseCase,
+		eventsUseCase manualapp.EventsUseCase,
+		previewUseCase manualapp.PreviewUseCase,
 		log *logger.Logger,
 	) *manualpresentation.Controller {
-		return manualpresentation.NewController(startUseCase, listUseCase, log)
+		return manualpresentation.NewController(startUseCase, listUseCase, detailUseCase, cancelUseCase, retryUseCase, resumeUseCase, eventsUseCase, previewUseCase, log)
+	})
+
+	_ = container.Provide(func(
+		scheduleUseCase manualapp.ScheduleUseCase,
+		log *logger.Logger,
+	) *manualpresentation.ScheduleController {
+		return manualpresentation.NewScheduleController(scheduleUseCase, log)
+	})
+
+	_ = container.Provide(func(
+		importUseCase manualapp.ImportUseCase,
+		log *logger.Logger,
+	) *manualpresentation.ImportController {
+		return manualpresentation.NewImportController(importUseCase, log)
+	})
+
+	_ = container.Provide(func(
+		reanalyzeUseCase manualapp.ReanalyzeUseCase,
+		log *logger.Logger,
+	) *manualpresentation.ReanalyzeController {
+		return manualpresentation.NewReanalyzeController(reanalyzeUseCase, log)
 	})
 }
@@ -0,0 +1,37 @@
+// Package blobstore keeps opaque objects by key on the local disk or in an S3-compatible bucket.
+//
+// Callers encrypt what they store; the stores only move bytes.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when no object exists under the key.
+var ErrNotFound = errors.New("blob not found")
+
+// Store puts, reads and removes objects by key.
+// Keys are slash-separated paths such as "email-bodies/42".
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns ErrNotFound when the object does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete succeeds when the object does not exist.
+	Delete(ctx context.Context, key string) error
+}
+
+// validateKey rejects keys that could leave the store root on disk or be read ambiguously as a URL path.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, "\\?#%") {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package blobstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_PutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store, err := NewLocal(filepath.Join(dir, "bodies"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "email-bodies/42", []byte("first")))
+	require.NoError(t, store.Put(ctx, "email-bodies/42", []byte("second")))
+
+	data, err := store.Get(ctx, "email-bodies/42")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), data)
+
+	info, err := os.Stat(filepath.Join(dir, "bodies", "email-bodies", "42"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(t, store.Delete(ctx, "email-bodies/42"))
+	require.NoError(t, store.Delete(ctx, "email-bodies/42"))
+	_, err = store.Get(ctx, "email-bodies/42")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocal_RejectsKeysOutsideTheRoot(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "a/../../b", "a//b", "a/", `a\b`} {
+		assert.Error(t, store.Put(context.Background(), key, []byte("x")), "key %q", key)
+	}
+}
@@ -0,0 +1,185 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// maxErrorBodyBytes bounds how much of an error response is kept in the returned error.
+const maxErrorBodyBytes = 1024
+
+// S3Config describes an S3-compatible bucket.
+type S3Config struct {
+	// Endpoint is the service URL, such as "http://minio:9000". Empty means AWS S3 in Region.
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to every key, without a trailing slash.
+	Prefix      string
+	Credentials aws.CredentialsProvider
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+}
+
+// S3 stores objects in an S3-compatible bucket through the REST API with path-style URLs,
+// which AWS S3, MinIO and most compatible services accept.
+type S3 struct {
+	endpoint    *url.URL
+	region      string
+	bucket      string
+	prefix      string
+	credentials aws.CredentialsProvider
+	client      *http.Client
+	signer      *v4.Signer
+	now         func() time.Time
+}
+
+// NewS3 validates cfg and returns a store for its bucket.
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("s3 region is required")
+	}
+	if cfg.Credentials == nil {
+		return nil, errors.New("s3 credentials are required")
+	}
+	if cfg.Prefix != "" {
+		if err := validateKey(cfg.Prefix); err != nil {
+			return nil, fmt.Errorf("invalid s3 prefix: %w", err)
+		}
+	}
+
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(rawEndpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &S3{
+		endpoint:    endpoint,
+		region:      cfg.Region,
+		bucket:      cfg.Bucket,
+		prefix:      cfg.Prefix,
+		credentials: cfg.Credentials,
+		client:      client,
+		signer:      v4.NewSigner(),
+		now:         time.Now,
+	}, nil
+}
+
+// Put uploads the object with PutObject.
+func (s *S3) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError("put", resp)
+	}
+	return nil
+}
+
+// Get downloads the object with GetObject.
+func (s *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read s3 object: %w", err)
+		}
+		return data, nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, responseError("get", resp)
+	}
+}
+
+// Delete removes the object with DeleteObject, which S3 also answers with 204 for a missing key.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return responseError("delete", resp)
+	}
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	if ctx == nil {
+		return nil, errors.New("context is required")
+	}
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+
+	objectURL := *s.endpoint
+	objectURL.Path = s.endpoint.Path + "/" + s.bucket + "/" + key
+	req, err := http.NewRequestWithContext(ctx, method, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build s3 request: %w", err)
+	}
+	if body != nil {
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve s3 credentials: %w", err)
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.region, s.now()); err != nil {
+		return nil, fmt.Errorf("failed to sign s3 request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s request failed: %w", strings.ToLower(method), err)
+	}
+	return resp, nil
+}
+
+func responseError(operation string, resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	return fmt.Errorf("s3 %s failed with status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(detail)))
+}
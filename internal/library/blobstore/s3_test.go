@@ -0,0 +1,120 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 keeps objects in memory and checks that every request is signed for the bucket region.
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/ap-northeast-1/s3/aws4_request") {
+		f.t.Errorf("request is not signed for s3: %q", auth)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.Header.Get("X-Amz-Content-Sha256") == "" {
+		f.t.Errorf("payload hash header is missing")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = data
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestS3(t *testing.T, handler http.Handler) *S3 {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3(S3Config{
+		Endpoint: server.URL,
+		Region:   "ap-northeast-1",
+		Bucket:   "billing-mail",
+		Prefix:   "dev",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+		}),
+	})
+	require.NoError(t, err)
+	return store
+}
+
+func TestS3_PutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeS3{t: t, objects: map[string][]byte{}}
+	store := newTestS3(t, fake)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "email-bodies/42", []byte("ciphertext")))
+	assert.Contains(t, fake.objects, "/billing-mail/dev/email-bodies/42")
+
+	data, err := store.Get(ctx, "email-bodies/42")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ciphertext"), data)
+
+	require.NoError(t, store.Delete(ctx, "email-bodies/42"))
+	_, err = store.Get(ctx, "email-bodies/42")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestS3_ReportsServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	store := newTestS3(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<Error><Code>AccessDenied</Code></Error>")
+	}))
+
+	err := store.Put(context.Background(), "email-bodies/42", []byte("x"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AccessDenied")
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestNewS3_ValidatesConfig(t *testing.T) {
+	t.Parallel()
+
+	creds := aws.AnonymousCredentials{}
+	for name, cfg := range map[string]S3Config{
+		"missing bucket":   {Region: "ap-northeast-1", Credentials: creds},
+		"missing region":   {Bucket: "b", Credentials: creds},
+		"missing creds":    {Bucket: "b", Region: "ap-northeast-1"},
+		"invalid endpoint": {Bucket: "b", Region: "ap-northeast-1", Credentials: creds, Endpoint: "minio:9000"},
+	} {
+		_, err := NewS3(cfg)
+		assert.Error(t, err, name)
+	}
+}
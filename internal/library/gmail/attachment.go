@@ -0,0 +1,145 @@
+package gmail
+
+import (
+	cd "business/internal/common/domain"
+	"business/internal/library/logger"
+	"business/internal/library/pdftext"
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+const (
+	// MaxAttachmentBytes is the largest PDF attachment that is downloaded.
+	MaxAttachmentBytes = 10 << 20
+	// MaxAttachmentsPerMessage is how many PDF attachments of one message are read.
+	MaxAttachmentsPerMessage = 5
+	// MaxAttachmentTextRunes caps the extracted text of one attachment so that it fits the analysis prompt.
+	MaxAttachmentTextRunes = 20000
+)
+
+// extractAttachments downloads the PDF attachments of a message and extracts their text.
+// A failure never fails the message; it is recorded on the attachment as FailureReason.
+func (c *Client) extractAttachments(ctx context.Context, reqLog logger.Interface, messageID string, payload *gmail.MessagePart) []cd.FetchedAttachmentDTO {
+	parts := collectPDFParts(payload, nil)
+	if len(parts) == 0 {
+		return nil
+	}
+
+	attachments := make([]cd.FetchedAttachmentDTO, 0, len(parts))
+	for i, part := range parts {
+		attachment := cd.FetchedAttachmentDTO{
+			Filename: part.Filename,
+			MimeType: part.MimeType,
+		}
+		if part.Body != nil {
+			attachment.Size = part.Body.Size
+		}
+
+		switch {
+		case i >= MaxAttachmentsPerMessage:
+			attachment.FailureReason = cd.AttachmentFailureLimitExceeded
+		case attachment.Size > MaxAttachmentBytes:
+			attachment.FailureReason = cd.AttachmentFailureTooLarge
+		default:
+			data, err := c.attachmentData(ctx, messageID, part.Body)
+			switch {
+			case err != nil:
+				attachment.FailureReason = cd.AttachmentFailureDownloadFailed
+				reqLog.Warn("external_api_failed",
+					logger.String("provider", "gmail"),
+					logger.String("operation", "get_attachment"),
+					logger.String("gmail_message_id", messageID),
+					logger.Err(err),
+				)
+			case len(data) > MaxAttachmentBytes:
+				attachment.FailureReason = cd.AttachmentFailureTooLarge
+			default:
+				attachment.Size = int64(len(data))
+				attachment.Text, attachment.Truncated, attachment.FailureReason = extractPDFText(data)
+			}
+		}
+
+		if attachment.FailureReason != "" {
+			reqLog.Warn("gmail_attachment_text_unavailable",
+				logger.String("gmail_message_id", messageID),
+				logger.String("filename", attachment.Filename),
+				logger.String("reason", attachment.FailureReason),
+			)
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments
+}
+
+// collectPDFParts walks the MIME tree in order and returns the parts that carry a PDF file.
+func collectPDFParts(part *gmail.MessagePart, parts []*gmail.MessagePart) []*gmail.MessagePart {
+	if part == nil {
+		return parts
+	}
+	if isPDFPart(part) {
+		parts = append(parts, part)
+	}
+	for _, child := range part.Parts {
+		parts = collectPDFParts(child, parts)
+	}
+	return parts
+}
+
+func isPDFPart(part *gmail.MessagePart) bool {
+	if part.Filename == "" || part.Body == nil {
+		return false
+	}
+	mimeType := strings.ToLower(part.MimeType)
+	if mimeType == "application/pdf" {
+		return true
+	}
+	// Some mailers send PDFs as a generic binary type and only the file name tells.
+	return mimeType == "application/octet-stream" && strings.HasSuffix(strings.ToLower(part.Filename), ".pdf")
+}
+
+// attachmentData returns the attachment bytes, inline when Gmail embedded them or through
+// users.messages.attachments.get otherwise.
+func (c *Client) attachmentData(ctx context.Context, messageID string, body *gmail.MessagePartBody) ([]byte, error) {
+	encoded := body.Data
+	if encoded == "" {
+		if body.AttachmentId == "" {
+			return nil, errors.New("attachment has neither data nor attachment id")
+		}
+		err := c.execute(ctx, func(ctx context.Context) error {
+			resp, err := c.svc.Users.Messages.Attachments.Get("me", messageID, body.AttachmentId).Context(ctx).Do()
+			if err != nil {
+				return err
+			}
+			encoded = resp.Data
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return decodeBase64URL(encoded)
+}
+
+// extractPDFText returns the text of a PDF, or the reason it has none.
+func extractPDFText(data []byte) (string, bool, string) {
+	text, err := pdftext.Extract(data)
+	switch {
+	case errors.Is(err, pdftext.ErrEncrypted):
+		return "", false, cd.AttachmentFailureEncrypted
+	case err != nil:
+		return "", false, cd.AttachmentFailureUnreadable
+	case text == "":
+		return "", false, cd.AttachmentFailureNoText
+	}
+
+	if utf8.RuneCountInString(text) <= MaxAttachmentTextRunes {
+		return text, false, ""
+	}
+	runes := []rune(text)
+	return string(runes[:MaxAttachmentTextRunes]), true, ""
+}
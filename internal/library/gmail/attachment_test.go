@@ -0,0 +1,89 @@
+package gmail
+
+import (
+	cd "business/internal/common/domain"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func invoicePDF() []byte {
+	content := "BT /F1 12 Tf 72 720 Td (Invoice INV-42) Tj 0 -20 Td (Total JPY 12,000) Tj ET"
+	return []byte(strings.Join([]string{
+		"%PDF-1.4",
+		"1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj",
+		"2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj",
+		"3 0 obj << /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >> endobj",
+		"4 0 obj << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> endobj",
+		fmt.Sprintf("5 0 obj << /Length %d >>\nstream\n%s\nendstream\nendobj", len(content), content),
+		"trailer << /Root 1 0 R >>",
+		"%%EOF",
+	}, "\n"))
+}
+
+func TestClient_GetGmailDetail_ExtractsPDFAttachments(t *testing.T) {
+	t.Parallel()
+
+	body := base64.URLEncoding.EncodeToString([]byte("請求書を添付します。"))
+	pdf := base64.URLEncoding.EncodeToString(invoicePDF())
+	client := newTestClient(t, "/gmail/v1/users/me/messages/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/gmail/v1/users/me/messages/msg-1":
+			fmt.Fprintf(w, `{"id":"msg-1","payload":{"mimeType":"multipart/mixed","headers":[{"name":"Subject","value":"Invoice"}],"parts":[
+				{"mimeType":"text/plain","body":{"data":%q}},
+				{"mimeType":"application/pdf","filename":"invoice.pdf","body":{"attachmentId":"att-1","size":%d}},
+				{"mimeType":"application/octet-stream","filename":"huge.PDF","body":{"attachmentId":"att-2","size":%d}},
+				{"mimeType":"image/png","filename":"logo.png","body":{"attachmentId":"att-3","size":10}},
+				{"mimeType":"application/pdf","filename":"broken.pdf","body":{"data":"bm90IGEgcGRm","size":9}}
+			]}}`, body, len(invoicePDF()), MaxAttachmentBytes+1)
+		case "/gmail/v1/users/me/messages/msg-1/attachments/att-1":
+			fmt.Fprintf(w, `{"data":%q}`, pdf)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	email, err := client.GetGmailDetail(context.Background(), "msg-1")
+	if err != nil {
+		t.Fatalf("GetGmailDetail returned error: %v", err)
+	}
+	if email.Body != "請求書を添付します。" {
+		t.Fatalf("unexpected body: %q", email.Body)
+	}
+	if len(email.Attachments) != 3 {
+		t.Fatalf("unexpected attachments: %+v", email.Attachments)
+	}
+
+	invoice := email.Attachments[0]
+	if invoice.Filename != "invoice.pdf" || invoice.Text != "Invoice INV-42\nTotal JPY 12,000" || invoice.FailureReason != "" {
+		t.Fatalf("unexpected invoice attachment: %+v", invoice)
+	}
+	if email.Attachments[1].Filename != "huge.PDF" || email.Attachments[1].FailureReason != cd.AttachmentFailureTooLarge {
+		t.Fatalf("oversized attachment must not be downloaded: %+v", email.Attachments[1])
+	}
+	if email.Attachments[2].FailureReason != cd.AttachmentFailureUnreadable || email.Attachments[2].Text != "" {
+		t.Fatalf("unexpected broken attachment: %+v", email.Attachments[2])
+	}
+}
+
+func TestExtractPDFText_TruncatesLongText(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", MaxAttachmentTextRunes+10)
+	content := fmt.Sprintf("BT /F1 12 Tf (%s) Tj ET", long)
+	pdf := []byte(strings.Join([]string{
+		"%PDF-1.4",
+		"1 0 obj << /Type /Page /Contents 2 0 R >> endobj",
+		fmt.Sprintf("2 0 obj << /Length %d >>\nstream\n%s\nendstream\nendobj", len(content), content),
+	}, "\n"))
+
+	text, truncated, reason := extractPDFText(pdf)
+	if reason != "" || !truncated || len(text) != MaxAttachmentTextRunes {
+		t.Fatalf("unexpected result: len=%d truncated=%v reason=%q", len(text), truncated, reason)
+	}
+}
@@ -3,12 +3,13 @@ package gmail
 import (
 	cd "business/internal/common/domain"
 	"business/internal/library/logger"
+	"business/internal/library/mailmime"
 	"business/internal/library/ratelimit"
 	"business/internal/library/retry"
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/mail"
 	"strings"
 	"time"
@@ -20,12 +21,15 @@ import (
 var (
 	// ErrLabelNotFound is returned when the requested Gmail label does not exist.
 	ErrLabelNotFound = errors.New("gmail label not found")
+	// ErrHistoryExpired is returned when the start history ID is too old for users.history.list.
+	ErrHistoryExpired = errors.New("gmail history id expired")
 )
 
 type Client struct {
-	svc     *gmail.Service
-	limiter ratelimit.Limiter
-	log     logger.Interface
+	svc            *gmail.Service
+	limiter        ratelimit.Limiter
+	log            logger.Interface
+	bodyPreference BodyPreference
 }
 
 func New(limiter ratelimit.Limiter, log logger.Interface) *Client {
@@ -34,20 +38,45 @@ func New(limiter ratelimit.Limiter, log logger.Interface) *Client {
 	}
 
 	return &Client{
-		limiter: limiter,
-		log:     log.With(logger.Component("gmail_client")),
+		limiter:        limiter,
+		log:            log.With(logger.Component("gmail_client")),
+		bodyPreference: PreferPlainText,
 	}
 }
 
 func (c *Client) SetClient(svc *gmail.Service) *Client {
 	return &Client{
-		svc:     svc,
-		limiter: c.limiter,
-		log:     c.log,
+		svc:            svc,
+		limiter:        c.limiter,
+		log:            c.log,
+		bodyPreference: c.bodyPreference,
 	}
 }
 
+// MessageQuery narrows users.messages.list.
+type MessageQuery struct {
+	// LabelNames lists messages that carry any of these labels. At least one is required.
+	LabelNames []string
+	// ExcludeLabelNames drops messages that carry any of these labels.
+	ExcludeLabelNames []string
+	// Since is sent as after: rounded down to midnight in its own location.
+	Since time.Time
+	// Until is sent as before: rounded up to the next second. A zero Until leaves the range open.
+	Until time.Time
+	// Query is an additional Gmail search expression such as "from:billing@example.com".
+	Query string
+}
+
 func (c *Client) GetMessagesByLabelName(ctx context.Context, labelName string, startDate time.Time) ([]string, error) {
+	return c.ListMessageIDs(ctx, MessageQuery{
+		LabelNames: []string{labelName},
+		Since:      startDate,
+	})
+}
+
+// ListMessageIDs returns the IDs of messages matching q, in listing order of q.LabelNames.
+// ErrLabelNotFound is returned when any included or excluded label does not exist.
+func (c *Client) ListMessageIDs(ctx context.Context, q MessageQuery) ([]string, error) {
 	if ctx == nil {
 		return nil, logger.ErrNilContext
 	}
@@ -57,12 +86,158 @@ func (c *Client) GetMessagesByLabelName(ctx context.Context, labelName string, s
 		reqLog = withContext
 	}
 
-	user := "me"
+	if len(q.LabelNames) == 0 {
+		return nil, errors.New("at least one label name is required")
+	}
+	resolved, err := c.findLabelIDs(ctx, reqLog, append(append([]string{}, q.LabelNames...), q.ExcludeLabelNames...))
+	if err != nil {
+		return nil, err
+	}
+	// Exclude labels are resolved only to reject unknown names; the search query filters them by name.
+	labelIDs := resolved[:len(q.LabelNames)]
+
+	// 検索条件（入力されたタイムゾーンに合わせて 0 時に揃え、UTC へ変換）
+	startDate := q.Since
+	truncated := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	period := fmt.Sprintf("after:%d", truncated.In(time.UTC).Unix())
+	if !q.Until.IsZero() {
+		// before: は秒単位なので、until より前のメールを取りこぼさないよう切り上げる。
+		before := q.Until.Truncate(time.Second)
+		if before.Before(q.Until) {
+			before = before.Add(time.Second)
+		}
+		period += fmt.Sprintf(" before:%d", before.Unix())
+	}
+	query := period
+	if extra := strings.TrimSpace(q.Query); extra != "" {
+		query += " (" + extra + ")"
+	}
+	for _, labelName := range q.ExcludeLabelNames {
+		query += " -label:" + labelSearchName(labelName)
+	}
+
+	var messageIDs []string
+	seen := make(map[string]struct{})
+	for _, labelID := range labelIDs {
+		ids, err := c.listMessagePages(ctx, reqLog, labelID, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			messageIDs = appendUniqueMessageID(messageIDs, seen, id)
+		}
+	}
+
+	reqLog.Info("external_api_succeeded",
+		logger.String("provider", "gmail"),
+		logger.String("operation", "list_messages_by_label"),
+		logger.Int("message_count", len(messageIDs)),
+	)
+
+	return messageIDs, nil
+}
+
+func (c *Client) listMessagePages(ctx context.Context, reqLog logger.Interface, labelID string, query string) ([]string, error) {
+	// ページングしながら取得
+	var messageIds []string
+	pageToken := ""
+
+	for {
+		req := c.svc.Users.Messages.List("me").Context(ctx).
+			LabelIds(labelID).
+			Q(query).
+			MaxResults(100)
+		if pageToken != "" {
+			req.PageToken(pageToken)
+		}
+
+		var resp *gmail.ListMessagesResponse
+		err := c.execute(ctx, func(ctx context.Context) error {
+			result, err := req.Context(ctx).Do()
+			if err != nil {
+				return err
+			}
+			resp = result
+			return nil
+		})
+		if err != nil {
+			reqLog.Error("external_api_failed",
+				logger.String("provider", "gmail"),
+				logger.String("operation", "list_messages_by_label"),
+				logger.Err(err),
+			)
+			return nil, err
+		}
+
+		for _, m := range resp.Messages {
+			messageIds = append(messageIds, m.Id)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	return messageIds, nil
+}
+
+// GetCurrentHistoryID returns the latest history ID of the mailbox.
+// Taking it before a full listing lets the next fetch resume with ListAddedMessageIDs.
+func (c *Client) GetCurrentHistoryID(ctx context.Context) (uint64, error) {
+	if ctx == nil {
+		return 0, logger.ErrNilContext
+	}
+
+	reqLog := c.log
+	if withContext, err := c.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+
+	var profile *gmail.Profile
+	err := c.execute(ctx, func(ctx context.Context) error {
+		resp, err := c.svc.Users.GetProfile("me").Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		profile = resp
+		return nil
+	})
+	if err != nil {
+		reqLog.Error("external_api_failed",
+			logger.String("provider", "gmail"),
+			logger.String("operation", "get_profile"),
+			logger.Err(err),
+		)
+		return 0, fmt.Errorf("プロフィール取得に失敗しました。: %v", err)
+	}
+
+	return profile.HistoryId, nil
+}
+
+// Label is a mailbox label. MessagesTotal and MessagesUnread are nil when the counts were not loaded.
+type Label struct {
+	ID             string
+	Name           string
+	Type           string
+	MessagesTotal  *int64
+	MessagesUnread *int64
+}
+
+// ListLabels returns the labels of the mailbox in labels.list order with a single labels.list call.
+// labels.list leaves the message counts out, so MessagesTotal and MessagesUnread are nil.
+func (c *Client) ListLabels(ctx context.Context) ([]Label, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+
+	reqLog := c.log
+	if withContext, err := c.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
 
-	// ラベルID取得
 	var labelResp *gmail.ListLabelsResponse
 	err := c.execute(ctx, func(ctx context.Context) error {
-		resp, err := c.svc.Users.Labels.List(user).Context(ctx).Do()
+		resp, err := c.svc.Users.Labels.List("me").Context(ctx).Do()
 		if err != nil {
 			return err
 		}
@@ -77,35 +252,48 @@ func (c *Client) GetMessagesByLabelName(ctx context.Context, labelName string, s
 		)
 		return nil, fmt.Errorf("ラベル取得に失敗しました。: %v", err)
 	}
-	var labelID string
-	for _, label := range labelResp.Labels {
-		if label.Name == labelName {
-			labelID = label.Id
-			break
-		}
+
+	labels := make([]Label, 0, len(labelResp.Labels))
+	for _, item := range labelResp.Labels {
+		labels = append(labels, Label{ID: item.Id, Name: item.Name, Type: item.Type})
 	}
-	if labelID == "" {
-		return nil, fmt.Errorf("%w: %s", ErrLabelNotFound, labelName)
+	return labels, nil
+}
+
+// ListAddedMessageIDs returns the IDs of messages that were added to the label, or had the label
+// added, after startHistoryID, together with the history ID to resume from next time.
+// ErrHistoryExpired is returned when Gmail no longer keeps history from startHistoryID.
+func (c *Client) ListAddedMessageIDs(ctx context.Context, labelName string, startHistoryID uint64) ([]string, uint64, error) {
+	if ctx == nil {
+		return nil, 0, logger.ErrNilContext
 	}
 
-	// 検索条件（入力されたタイムゾーンに合わせて 0 時に揃え、UTC へ変換）
-	truncated := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
-	query := fmt.Sprintf("after:%d", truncated.In(time.UTC).Unix())
+	reqLog := c.log
+	if withContext, err := c.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
 
-	// ページングしながら取得
-	var messageIds []string
+	labelID, err := c.findLabelID(ctx, reqLog, labelName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var messageIDs []string
+	seen := make(map[string]struct{})
+	latestHistoryID := startHistoryID
 	pageToken := ""
 
 	for {
-		req := c.svc.Users.Messages.List(user).Context(ctx).
-			LabelIds(labelID).
-			Q(query).
-			MaxResults(100)
+		req := c.svc.Users.History.List("me").
+			StartHistoryId(startHistoryID).
+			LabelId(labelID).
+			HistoryTypes("messageAdded", "labelAdded").
+			MaxResults(500)
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
 
-		var resp *gmail.ListMessagesResponse
+		var resp *gmail.ListHistoryResponse
 		err := c.execute(ctx, func(ctx context.Context) error {
 			result, err := req.Context(ctx).Do()
 			if err != nil {
@@ -115,31 +303,129 @@ func (c *Client) GetMessagesByLabelName(ctx context.Context, labelName string, s
 			return nil
 		})
 		if err != nil {
+			var apiErr *googleapi.Error
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+				reqLog.Warn("gmail_history_expired",
+					logger.String("provider", "gmail"),
+					logger.String("operation", "list_history"),
+				)
+				return nil, 0, ErrHistoryExpired
+			}
 			reqLog.Error("external_api_failed",
 				logger.String("provider", "gmail"),
-				logger.String("operation", "list_messages_by_label"),
+				logger.String("operation", "list_history"),
 				logger.Err(err),
 			)
-			return nil, err
+			return nil, 0, err
 		}
 
-		for _, m := range resp.Messages {
-			messageIds = append(messageIds, m.Id)
+		for _, history := range resp.History {
+			for _, added := range history.MessagesAdded {
+				if added.Message != nil && hasLabel(added.Message.LabelIds, labelID, true) {
+					messageIDs = appendUniqueMessageID(messageIDs, seen, added.Message.Id)
+				}
+			}
+			for _, added := range history.LabelsAdded {
+				if added.Message != nil && hasLabel(added.LabelIds, labelID, false) {
+					messageIDs = appendUniqueMessageID(messageIDs, seen, added.Message.Id)
+				}
+			}
+		}
+		if resp.HistoryId > latestHistoryID {
+			latestHistoryID = resp.HistoryId
 		}
 		if resp.NextPageToken == "" {
 			break
 		}
 		pageToken = resp.NextPageToken
-
 	}
 
 	reqLog.Info("external_api_succeeded",
 		logger.String("provider", "gmail"),
-		logger.String("operation", "list_messages_by_label"),
-		logger.Int("message_count", len(messageIds)),
+		logger.String("operation", "list_history"),
+		logger.Int("message_count", len(messageIDs)),
 	)
 
-	return messageIds, nil
+	return messageIDs, latestHistoryID, nil
+}
+
+func (c *Client) findLabelID(ctx context.Context, reqLog logger.Interface, labelName string) (string, error) {
+	labelIDs, err := c.findLabelIDs(ctx, reqLog, []string{labelName})
+	if err != nil {
+		return "", err
+	}
+	return labelIDs[0], nil
+}
+
+// findLabelIDs resolves label names with a single labels.list call.
+func (c *Client) findLabelIDs(ctx context.Context, reqLog logger.Interface, labelNames []string) ([]string, error) {
+	if len(labelNames) == 0 {
+		return nil, nil
+	}
+
+	var labelResp *gmail.ListLabelsResponse
+	err := c.execute(ctx, func(ctx context.Context) error {
+		resp, err := c.svc.Users.Labels.List("me").Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		labelResp = resp
+		return nil
+	})
+	if err != nil {
+		reqLog.Error("external_api_failed",
+			logger.String("provider", "gmail"),
+			logger.String("operation", "list_labels"),
+			logger.Err(err),
+		)
+		return nil, fmt.Errorf("ラベル取得に失敗しました。: %v", err)
+	}
+
+	idsByName := make(map[string]string, len(labelResp.Labels))
+	for _, label := range labelResp.Labels {
+		idsByName[label.Name] = label.Id
+	}
+	labelIDs := make([]string, 0, len(labelNames))
+	for _, labelName := range labelNames {
+		labelID, ok := idsByName[labelName]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrLabelNotFound, labelName)
+		}
+		labelIDs = append(labelIDs, labelID)
+	}
+	return labelIDs, nil
+}
+
+// labelSearchName writes a label name the way Gmail search expects it after label:,
+// where spaces and the slashes of nested labels become hyphens.
+func labelSearchName(labelName string) string {
+	return strings.NewReplacer(" ", "-", "/", "-").Replace(strings.TrimSpace(labelName))
+}
+
+// hasLabel reports whether labelIDs contains labelID. An empty list is accepted when
+// allowEmpty is set because history entries filtered by labelId may omit the message labels.
+func hasLabel(labelIDs []string, labelID string, allowEmpty bool) bool {
+	if len(labelIDs) == 0 {
+		return allowEmpty
+	}
+	for _, id := range labelIDs {
+		if id == labelID {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUniqueMessageID(messageIDs []string, seen map[string]struct{}, messageID string) []string {
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" {
+		return messageIDs
+	}
+	if _, ok := seen[messageID]; ok {
+		return messageIDs
+	}
+	seen[messageID] = struct{}{}
+	return append(messageIDs, messageID)
 }
 
 func (c *Client) GetGmailDetail(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
@@ -175,12 +461,13 @@ func (c *Client) GetGmailDetail(ctx context.Context, id string) (cd.FetchedEmail
 
 	msg := cd.FetchedEmailDTO{
 		ID:      full.Id,
-		Subject: getHeader(full.Payload.Headers, "Subject"),
-		From:    getHeader(full.Payload.Headers, "From"),
-		To:      parseHeaderMulti(getHeader(full.Payload.Headers, "To")),
-		Date:    parseDate(getHeader(full.Payload.Headers, "Date")),
-		Body:    stripHTMLTags(extractBody(full.Payload)), // HTMLタグを削除する。
+		Subject: mailmime.DecodeHeader(headerValue(full.Payload.Headers, "Subject")),
+		From:    mailmime.DecodeHeader(headerValue(full.Payload.Headers, "From")),
+		To:      parseHeaderMulti(mailmime.DecodeHeader(headerValue(full.Payload.Headers, "To"))),
+		Date:    parseDate(headerValue(full.Payload.Headers, "Date")),
+		Body:    StripHTMLTags(extractBody(full.Payload, c.bodyPreference)), // HTMLタグを削除する。
 	}
+	msg.Attachments = c.extractAttachments(ctx, reqLog, full.Id, full.Payload)
 
 	reqLog.Info("external_api_succeeded",
 		logger.String("provider", "gmail"),
@@ -191,15 +478,6 @@ func (c *Client) GetGmailDetail(ctx context.Context, id string) (cd.FetchedEmail
 	return msg, nil
 }
 
-func getHeader(headers []*gmail.MessagePartHeader, name string) string {
-	for _, h := range headers {
-		if h.Name == name {
-			return h.Value
-		}
-	}
-	return ""
-}
-
 func parseHeaderMulti(raw string) []string {
 	if raw == "" {
 		return nil
@@ -215,24 +493,6 @@ func parseDate(raw string) time.Time {
 	return t
 }
 
-func extractBody(payload *gmail.MessagePart) string {
-	if (payload.MimeType == "text/plain" || payload.MimeType == "text/html") &&
-		payload.Body != nil &&
-		payload.Body.Data != "" {
-
-		decoded, err := base64.URLEncoding.DecodeString(payload.Body.Data)
-		if err == nil {
-			return string(decoded)
-		}
-	}
-	for _, part := range payload.Parts {
-		if body := extractBody(part); body != "" {
-			return body
-		}
-	}
-	return ""
-}
-
 func (c *Client) execute(ctx context.Context, fn func(context.Context) error) error {
 	if ctx == nil {
 		return logger.ErrNilContext
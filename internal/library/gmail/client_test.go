@@ -0,0 +1,186 @@
+package gmail
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+type noopLimiter struct{}
+
+func (noopLimiter) Wait(ctx context.Context) error { return nil }
+
+func newHistoryTestClient(t *testing.T, historyHandler http.HandlerFunc) *Client {
+	t.Helper()
+
+	return newTestClient(t, "/gmail/v1/users/me/history", historyHandler)
+}
+
+func newTestClient(t *testing.T, path string, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gmail/v1/users/me/labels", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"labels":[{"id":"INBOX","name":"INBOX","type":"system"},{"id":"Label_1","name":"billing","type":"user"},{"id":"Label_2","name":"receipts","type":"user"},{"id":"Label_3","name":"archived","type":"user"},{"id":"Label_4","name":"old receipts","type":"user"}]}`))
+	})
+	mux.HandleFunc(path, handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithEndpoint(server.URL),
+		option.WithHTTPClient(server.Client()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create gmail service: %v", err)
+	}
+	return New(noopLimiter{}, logger.NewNop()).SetClient(svc)
+}
+
+func TestClient_ListAddedMessageIDs_CollectsAddedAndLabeledMessages(t *testing.T) {
+	t.Parallel()
+
+	client := newHistoryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("startHistoryId") != "100" || query.Get("labelId") != "Label_1" {
+			t.Errorf("unexpected history query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if query.Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{
+				"history":[
+					{"messagesAdded":[{"message":{"id":"msg-1","labelIds":["Label_1"]}}]},
+					{"labelsAdded":[{"message":{"id":"msg-2"},"labelIds":["Label_1"]},{"message":{"id":"msg-3"},"labelIds":["UNREAD"]}]}
+				],
+				"nextPageToken":"next",
+				"historyId":"150"
+			}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"history":[{"messagesAdded":[{"message":{"id":"msg-1","labelIds":["Label_1"]}},{"message":{"id":"msg-4","labelIds":["Label_1"]}}]}],
+			"historyId":"160"
+		}`))
+	})
+
+	messageIDs, historyID, err := client.ListAddedMessageIDs(context.Background(), "billing", 100)
+	if err != nil {
+		t.Fatalf("ListAddedMessageIDs returned error: %v", err)
+	}
+	if len(messageIDs) != 3 || messageIDs[0] != "msg-1" || messageIDs[1] != "msg-2" || messageIDs[2] != "msg-4" {
+		t.Fatalf("unexpected message ids: %v", messageIDs)
+	}
+	if historyID != 160 {
+		t.Fatalf("unexpected history id: %d", historyID)
+	}
+}
+
+func TestClient_ListAddedMessageIDs_ExpiredHistory(t *testing.T) {
+	t.Parallel()
+
+	client := newHistoryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found."}}`))
+	})
+
+	_, _, err := client.ListAddedMessageIDs(context.Background(), "billing", 1)
+	if !errors.Is(err, ErrHistoryExpired) {
+		t.Fatalf("expected ErrHistoryExpired, got %v", err)
+	}
+}
+
+func TestClient_ListMessageIDs_SendsPeriodAndQueryAndExcludesLabels(t *testing.T) {
+	t.Parallel()
+
+	jst := time.FixedZone("JST", 9*60*60)
+	since := time.Date(2026, 9, 1, 15, 0, 0, 0, jst)
+	until := time.Date(2026, 10, 1, 0, 0, 0, 500, jst)
+	wantQuery := "after:1788188400 before:1790780401 (from:billing@example.com) -label:archived -label:old-receipts"
+
+	client := newTestClient(t, "/gmail/v1/users/me/messages", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch query.Get("labelIds") {
+		case "Label_1":
+			if query.Get("q") != wantQuery {
+				t.Errorf("unexpected q: %s", query.Get("q"))
+			}
+			_, _ = w.Write([]byte(`{"messages":[{"id":"msg-1"},{"id":"msg-2"}]}`))
+		case "Label_2":
+			if query.Get("q") != wantQuery {
+				t.Errorf("unexpected q: %s", query.Get("q"))
+			}
+			_, _ = w.Write([]byte(`{"messages":[{"id":"msg-2"}]}`))
+		default:
+			t.Errorf("unexpected label: %s", query.Get("labelIds"))
+		}
+	})
+
+	messageIDs, err := client.ListMessageIDs(context.Background(), MessageQuery{
+		LabelNames:        []string{"billing", "receipts"},
+		ExcludeLabelNames: []string{"archived", "old receipts"},
+		Since:             since,
+		Until:             until,
+		Query:             "from:billing@example.com",
+	})
+	if err != nil {
+		t.Fatalf("ListMessageIDs returned error: %v", err)
+	}
+	if len(messageIDs) != 2 || messageIDs[0] != "msg-1" || messageIDs[1] != "msg-2" {
+		t.Fatalf("unexpected message ids: %v", messageIDs)
+	}
+}
+
+func TestClient_ListMessageIDs_UnknownExcludedLabel(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, "/gmail/v1/users/me/messages", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("messages must not be listed")
+	})
+
+	_, err := client.ListMessageIDs(context.Background(), MessageQuery{
+		LabelNames:        []string{"billing"},
+		ExcludeLabelNames: []string{"missing"},
+		Since:             time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if !errors.Is(err, ErrLabelNotFound) {
+		t.Fatalf("expected ErrLabelNotFound, got %v", err)
+	}
+}
+
+func TestClient_ListLabels_UsesSingleListCall(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, "/gmail/v1/users/me/labels/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("labels must not be looked up one by one: %s", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	labels, err := client.ListLabels(context.Background())
+	if err != nil {
+		t.Fatalf("ListLabels returned error: %v", err)
+	}
+	if len(labels) != 5 {
+		t.Fatalf("unexpected labels: %+v", labels)
+	}
+	if labels[0].ID != "INBOX" || labels[0].Name != "INBOX" || labels[0].Type != "system" {
+		t.Fatalf("unexpected system label: %+v", labels[0])
+	}
+	if labels[1].ID != "Label_1" || labels[1].Name != "billing" || labels[1].Type != "user" {
+		t.Fatalf("unexpected billing label: %+v", labels[1])
+	}
+	for _, label := range labels {
+		if label.MessagesTotal != nil || label.MessagesUnread != nil {
+			t.Fatalf("labels.list carries no counts: %+v", label)
+		}
+	}
+}
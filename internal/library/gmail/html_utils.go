@@ -5,8 +5,8 @@ import (
 	"strings"
 )
 
-// stripHTMLTags はHTMLタグを除去してプレーンテキストに変換します
-func stripHTMLTags(html string) string {
+// StripHTMLTags はHTMLタグを除去してプレーンテキストに変換します
+func StripHTMLTags(html string) string {
 	if html == "" {
 		return ""
 	}
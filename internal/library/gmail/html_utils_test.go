@@ -66,7 +66,7 @@ func TestStripHTMLTags(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := stripHTMLTags(tt.input)
+			result := StripHTMLTags(tt.input)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -10,6 +10,10 @@ import (
 
 type ClientInterface interface {
 	GetMessagesByLabelName(ctx context.Context, labelName string, startDate time.Time) ([]string, error)
+	ListMessageIDs(ctx context.Context, q MessageQuery) ([]string, error)
 	GetGmailDetail(ctx context.Context, id string) (cd.FetchedEmailDTO, error)
+	GetCurrentHistoryID(ctx context.Context) (uint64, error)
+	ListLabels(ctx context.Context) ([]Label, error)
+	ListAddedMessageIDs(ctx context.Context, labelName string, startHistoryID uint64) ([]string, uint64, error)
 	SetClient(svc *gmail.Service) *Client
 }
@@ -0,0 +1,128 @@
+package gmail
+
+import (
+	"business/internal/library/mailmime"
+	"encoding/base64"
+	"mime"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// BodyPreference decides which part of a multipart/alternative message becomes the body.
+// It is shared with the raw MIME parser so that every provider selects bodies the same way.
+type BodyPreference = mailmime.BodyPreference
+
+const (
+	// PreferPlainText uses text/plain and falls back to text/html. It is the default.
+	PreferPlainText = mailmime.PreferPlainText
+	// PreferHTML uses text/html and falls back to text/plain, for senders whose plain part is only a stub.
+	PreferHTML = mailmime.PreferHTML
+)
+
+// ParseBodyPreference reads a preference from configuration. An empty value is the default.
+func ParseBodyPreference(raw string) (BodyPreference, error) {
+	return mailmime.ParseBodyPreference(raw)
+}
+
+// WithBodyPreference returns a copy of the client that selects message bodies by p.
+func (c *Client) WithBodyPreference(p BodyPreference) *Client {
+	clone := *c
+	clone.bodyPreference = p
+	return &clone
+}
+
+// BodyPreference returns the preference the client selects message bodies by.
+func (c *Client) BodyPreference() BodyPreference {
+	if c.bodyPreference == "" {
+		return PreferPlainText
+	}
+	return c.bodyPreference
+}
+
+// extractBody returns the message body decoded by the transfer encoding and charset of its part.
+// text/plain and text/html are searched depth-first, skipping attachments, and chosen by preference;
+// the other type is used when the preferred one is missing.
+func extractBody(payload *gmail.MessagePart, preference BodyPreference) string {
+	var plain, html *gmail.MessagePart
+	collectTextParts(payload, &plain, &html)
+
+	first, second := plain, html
+	if preference == PreferHTML {
+		first, second = html, plain
+	}
+	for _, part := range []*gmail.MessagePart{first, second} {
+		if part == nil {
+			continue
+		}
+		if body, ok := decodeTextPart(part); ok {
+			return body
+		}
+	}
+	return ""
+}
+
+func collectTextParts(part *gmail.MessagePart, plain, html **gmail.MessagePart) {
+	if part == nil || (*plain != nil && *html != nil) {
+		return
+	}
+	if isTextBodyPart(part) {
+		switch strings.ToLower(part.MimeType) {
+		case "text/plain":
+			if *plain == nil {
+				*plain = part
+			}
+		case "text/html":
+			if *html == nil {
+				*html = part
+			}
+		}
+	}
+	for _, child := range part.Parts {
+		collectTextParts(child, plain, html)
+	}
+}
+
+// isTextBodyPart excludes text files attached to the message, such as a CSV statement.
+func isTextBodyPart(part *gmail.MessagePart) bool {
+	if part.Body == nil || part.Body.Data == "" || part.Filename != "" {
+		return false
+	}
+	disposition, _, err := mime.ParseMediaType(headerValue(part.Headers, "Content-Disposition"))
+	return err != nil || disposition != "attachment"
+}
+
+// decodeTextPart decodes the part data by the charset of its part.
+// Gmail has already undone the Content-Transfer-Encoding in the data, so it is not decoded again:
+// a plain text body such as "?a=1F" would otherwise be corrupted. Gmail never converts the charset.
+func decodeTextPart(part *gmail.MessagePart) (string, bool) {
+	data, err := decodeBase64URL(part.Body.Data)
+	if err != nil {
+		return "", false
+	}
+
+	charset := ""
+	if _, params, err := mime.ParseMediaType(headerValue(part.Headers, "Content-Type")); err == nil {
+		charset = params["charset"]
+	}
+	return mailmime.DecodeCharset(data, charset), true
+}
+
+// decodeBase64URL decodes the part and attachment data of the Gmail API, with or without padding.
+func decodeBase64URL(encoded string) ([]byte, error) {
+	data, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	}
+	return data, nil
+}
+
+// headerValue returns the first header with the given name. Header names are case-insensitive.
+func headerValue(headers []*gmail.MessagePartHeader, name string) string {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
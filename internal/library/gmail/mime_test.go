@@ -0,0 +1,183 @@
+package gmail
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"google.golang.org/api/gmail/v1"
+)
+
+func partData(t *testing.T, enc encoding.Encoding, text string) string {
+	t.Helper()
+
+	data := []byte(text)
+	if enc != nil {
+		encoded, err := enc.NewEncoder().Bytes(data)
+		if err != nil {
+			t.Fatalf("failed to encode %q: %v", text, err)
+		}
+		data = encoded
+	}
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+func textPart(mimeType, data string, headers ...string) *gmail.MessagePart {
+	part := &gmail.MessagePart{MimeType: mimeType, Body: &gmail.MessagePartBody{Data: data}}
+	for i := 0; i+1 < len(headers); i += 2 {
+		part.Headers = append(part.Headers, &gmail.MessagePartHeader{Name: headers[i], Value: headers[i+1]})
+	}
+	return part
+}
+
+func TestExtractBody_DecodesCharsetAndTransferEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		part *gmail.MessagePart
+		want string
+	}{
+		{
+			name: "iso-2022-jp",
+			part: textPart("text/plain", partData(t, japanese.ISO2022JP, "ご請求金額 1,000円"), "Content-Type", `text/plain; charset="ISO-2022-JP"`),
+			want: "ご請求金額 1,000円",
+		},
+		{
+			name: "shift_jis",
+			part: textPart("text/plain", partData(t, japanese.ShiftJIS, "請求書"), "content-type", "text/plain; charset=Shift_JIS"),
+			want: "請求書",
+		},
+		{
+			name: "quoted-printable decoded by gmail keeps escape-like text",
+			part: textPart("text/plain", partData(t, nil, "x=3D1, a=20b, https://example.com/?a=1F"),
+				"Content-Type", "text/plain; charset=UTF-8", "Content-Transfer-Encoding", "quoted-printable"),
+			want: "x=3D1, a=20b, https://example.com/?a=1F",
+		},
+		{
+			name: "quoted-printable already decoded by gmail",
+			part: textPart("text/plain", partData(t, nil, "a = b"),
+				"Content-Type", "text/plain; charset=UTF-8", "Content-Transfer-Encoding", "quoted-printable"),
+			want: "a = b",
+		},
+		{
+			name: "unlabeled shift_jis",
+			part: textPart("text/plain", partData(t, japanese.ShiftJIS, "ご利用料金")),
+			want: "ご利用料金",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := extractBody(tt.part, PreferPlainText); got != tt.want {
+				t.Fatalf("unexpected body: got=%q want=%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractBody_ChoosesAlternativeByPreference(t *testing.T) {
+	t.Parallel()
+
+	payload := &gmail.MessagePart{
+		MimeType: "multipart/mixed",
+		Parts: []*gmail.MessagePart{
+			{
+				MimeType: "multipart/alternative",
+				Parts: []*gmail.MessagePart{
+					textPart("text/html", partData(t, nil, "<p>html</p>")),
+					textPart("text/plain", partData(t, nil, "plain")),
+				},
+			},
+			{MimeType: "text/plain", Filename: "statement.csv", Body: &gmail.MessagePartBody{Data: partData(t, nil, "csv")}},
+		},
+	}
+	htmlOnly := textPart("text/html", partData(t, nil, "<p>only</p>"))
+	attachedText := &gmail.MessagePart{
+		MimeType: "multipart/mixed",
+		Parts: []*gmail.MessagePart{
+			textPart("text/plain", partData(t, nil, "detail"), "Content-Disposition", `attachment; filename="detail.txt"`),
+			textPart("text/html", partData(t, nil, "<p>body</p>")),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		payload    *gmail.MessagePart
+		preference BodyPreference
+		want       string
+	}{
+		{name: "plain preferred", payload: payload, preference: PreferPlainText, want: "plain"},
+		{name: "html preferred", payload: payload, preference: PreferHTML, want: "<p>html</p>"},
+		{name: "falls back to html", payload: htmlOnly, preference: PreferPlainText, want: "<p>only</p>"},
+		{name: "skips attached text", payload: attachedText, preference: PreferPlainText, want: "<p>body</p>"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := extractBody(tt.payload, tt.preference); got != tt.want {
+				t.Fatalf("unexpected body: got=%q want=%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBodyPreference(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]BodyPreference{"": PreferPlainText, "plain": PreferPlainText, " HTML ": PreferHTML} {
+		got, err := ParseBodyPreference(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseBodyPreference(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseBodyPreference("markdown"); err == nil {
+		t.Fatal("expected an error for an unknown preference")
+	}
+}
+
+func TestClient_GetGmailDetail_DecodesJapaneseHeadersAndBody(t *testing.T) {
+	t.Parallel()
+
+	plain := partData(t, japanese.ISO2022JP, "お支払い期限は月末です。")
+	html := partData(t, nil, "<p>お支払い期限は<b>10月31日</b>です。</p>")
+	client := newTestClient(t, "/gmail/v1/users/me/messages/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"msg-1","payload":{"mimeType":"multipart/alternative","headers":[
+			{"name":"Subject","value":"=?ISO-2022-JP?B?GyRCQEE1YUxAOlkbKEI=?="},
+			{"name":"From","value":"=?Shift_JIS?B?kL+LgY+R?= <billing@example.com>"}
+		],"parts":[
+			{"mimeType":"text/plain","headers":[{"name":"Content-Type","value":"text/plain; charset=ISO-2022-JP"}],"body":{"data":%q}},
+			{"mimeType":"text/html","headers":[{"name":"Content-Type","value":"text/html; charset=UTF-8"}],"body":{"data":%q}}
+		]}}`, plain, html)
+	})
+
+	email, err := client.GetGmailDetail(context.Background(), "msg-1")
+	if err != nil {
+		t.Fatalf("GetGmailDetail returned error: %v", err)
+	}
+	if email.Subject != "請求明細" || email.From != "請求書 <billing@example.com>" {
+		t.Fatalf("unexpected headers: subject=%q from=%q", email.Subject, email.From)
+	}
+	if email.Body != "お支払い期限は月末です。" {
+		t.Fatalf("unexpected plain body: %q", email.Body)
+	}
+
+	email, err = client.WithBodyPreference(PreferHTML).GetGmailDetail(context.Background(), "msg-1")
+	if err != nil {
+		t.Fatalf("GetGmailDetail returned error: %v", err)
+	}
+	if email.Body != "お支払い期限は10月31日です。" {
+		t.Fatalf("unexpected html body: %q", email.Body)
+	}
+}
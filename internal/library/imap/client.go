@@ -0,0 +1,485 @@
+package imap
+
+import (
+	"bufio"
+	"business/internal/library/logger"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// SecurityTLS connects with implicit TLS, usually on port 993.
+	SecurityTLS = "tls"
+	// SecurityStartTLS connects in plain text and upgrades with STARTTLS, usually on port 143.
+	SecurityStartTLS = "starttls"
+	// SecurityNone never encrypts the connection. It is meant for local servers only.
+	SecurityNone = "none"
+
+	// FetchBatchSize is the number of messages one UID FETCH asks for. Callers that keep raw messages
+	// should pass UIDs in batches of this size so that only one batch is held in memory at a time.
+	FetchBatchSize = 10
+	// MaxMessageBytes is the largest message FetchMessages reads. It leaves headroom over the 10 MB
+	// attachment limit for base64 and the rest of the message; larger messages are skipped.
+	MaxMessageBytes = 25 << 20
+
+	defaultCommandTimeout = 60 * time.Second
+)
+
+var (
+	// ErrAuthenticationFailed is returned when the server rejects LOGIN.
+	ErrAuthenticationFailed = errors.New("imap authentication failed")
+	// ErrMailboxNotFound is returned when SELECT fails for the requested mailbox.
+	ErrMailboxNotFound = errors.New("imap mailbox not found")
+	// ErrConnectionFailed is returned when the server cannot be reached or the greeting is invalid.
+	ErrConnectionFailed = errors.New("imap connection failed")
+
+	literalSuffixPattern = regexp.MustCompile(`\{(\d+)\}$`)
+	uidValidityPattern   = regexp.MustCompile(`\[UIDVALIDITY (\d+)\]`)
+	existsPattern        = regexp.MustCompile(`^\* (\d+) EXISTS`)
+	fetchUIDPattern      = regexp.MustCompile(`\bUID (\d+)\b`)
+)
+
+// Config is the server and account an IMAP session logs in to.
+type Config struct {
+	Host     string
+	Port     int
+	Security string
+	Username string
+	Password string
+	// TLSConfig overrides the TLS settings, e.g. to trust the certificate of a test server.
+	TLSConfig *tls.Config
+}
+
+// Mailbox is the state of the selected mailbox.
+type Mailbox struct {
+	Name        string
+	UIDValidity uint32
+	Exists      int
+}
+
+// CommandError is a NO or BAD completion of an IMAP command.
+type CommandError struct {
+	Command string
+	Status  string
+	Text    string
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("imap %s returned %s: %s", e.Command, e.Status, e.Text)
+}
+
+// Dialer opens authenticated IMAP sessions.
+type Dialer struct {
+	commandTimeout        time.Duration
+	allowPrivateAddresses bool
+	log                   logger.Interface
+}
+
+// NewDialer creates a Dialer. Each command is bounded by commandTimeout and by the context deadline.
+func NewDialer(commandTimeout time.Duration, log logger.Interface) *Dialer {
+	if commandTimeout <= 0 {
+		commandTimeout = defaultCommandTimeout
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Dialer{
+		commandTimeout: commandTimeout,
+		log:            log.With(logger.Component("imap_client")),
+	}
+}
+
+// WithPrivateAddresses returns a copy that may also connect to loopback, private and link-local
+// addresses. Users choose the host, so only development setups with a local IMAP server allow them.
+func (d *Dialer) WithPrivateAddresses(allow bool) *Dialer {
+	clone := *d
+	clone.allowPrivateAddresses = allow
+	return &clone
+}
+
+// Dial connects to the server and logs in. The caller must Logout the returned session.
+func (d *Dialer) Dial(ctx context.Context, cfg Config) (*Session, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if strings.TrimSpace(cfg.Host) == "" || cfg.Port <= 0 {
+		return nil, fmt.Errorf("%w: host and port are required", ErrConnectionFailed)
+	}
+
+	address := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := cfg.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12}
+	}
+
+	var conn net.Conn
+	var err error
+	netDialer := &net.Dialer{Timeout: d.commandTimeout}
+	if !d.allowPrivateAddresses {
+		// Checked on the resolved address so that a public name pointing at an internal host is refused too.
+		netDialer.Control = refusePrivateAddress
+	}
+	switch cfg.Security {
+	case SecurityTLS, "":
+		conn, err = (&tls.Dialer{NetDialer: netDialer, Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	case SecurityStartTLS, SecurityNone:
+		conn, err = netDialer.DialContext(ctx, "tcp", address)
+	default:
+		return nil, fmt.Errorf("%w: unsupported security %q", ErrConnectionFailed, cfg.Security)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+
+	session := newSession(conn, d.commandTimeout, d.log)
+	if err := session.readGreeting(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+
+	if cfg.Security == SecurityStartTLS {
+		if _, err := session.execute(ctx, "STARTTLS", "STARTTLS"); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
+		session.conn = tlsConn
+		session.reader = bufio.NewReader(tlsConn)
+	}
+
+	username, err := quoteString(cfg.Username)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	password, err := quoteString(cfg.Password)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if _, err := session.execute(ctx, "LOGIN", "LOGIN "+username+" "+password); err != nil {
+		_ = session.conn.Close()
+		var commandErr *CommandError
+		if errors.As(err, &commandErr) {
+			return nil, fmt.Errorf("%w: %s", ErrAuthenticationFailed, commandErr.Text)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+
+	return session, nil
+}
+
+// Session is one logged-in IMAP connection. Its methods may be called from one goroutine at a time;
+// concurrent calls are serialized.
+type Session struct {
+	mu             sync.Mutex
+	conn           net.Conn
+	reader         *bufio.Reader
+	tagSeq         int
+	broken         error
+	commandTimeout time.Duration
+	log            logger.Interface
+}
+
+func newSession(conn net.Conn, commandTimeout time.Duration, log logger.Interface) *Session {
+	return &Session{
+		conn:           conn,
+		reader:         bufio.NewReader(conn),
+		commandTimeout: commandTimeout,
+		log:            log,
+	}
+}
+
+// Select opens mailbox read-only, so fetching does not mark messages as seen.
+func (s *Session) Select(ctx context.Context, mailbox string) (Mailbox, error) {
+	name, err := quoteString(EncodeMailboxName(mailbox))
+	if err != nil {
+		return Mailbox{}, err
+	}
+
+	responses, err := s.execute(ctx, "EXAMINE", "EXAMINE "+name)
+	if err != nil {
+		var commandErr *CommandError
+		if errors.As(err, &commandErr) && commandErr.Status == "NO" {
+			return Mailbox{}, fmt.Errorf("%w: %s", ErrMailboxNotFound, mailbox)
+		}
+		return Mailbox{}, err
+	}
+
+	selected := Mailbox{Name: mailbox}
+	for _, resp := range responses {
+		if match := uidValidityPattern.FindStringSubmatch(resp.text); match != nil {
+			value, parseErr := strconv.ParseUint(match[1], 10, 32)
+			if parseErr == nil {
+				selected.UIDValidity = uint32(value)
+			}
+		}
+		if match := existsPattern.FindStringSubmatch(resp.text); match != nil {
+			selected.Exists, _ = strconv.Atoi(match[1])
+		}
+	}
+	return selected, nil
+}
+
+// SearchUIDs returns the UIDs of the selected mailbox whose internal date is on or after since
+// and before before. IMAP compares dates only, so callers must filter by the exact time themselves.
+// A zero since or before leaves that side open.
+func (s *Session) SearchUIDs(ctx context.Context, since, before time.Time) ([]uint32, error) {
+	criteria := make([]string, 0, 2)
+	if !since.IsZero() {
+		criteria = append(criteria, "SINCE "+since.Format("2-Jan-2006"))
+	}
+	if !before.IsZero() {
+		criteria = append(criteria, "BEFORE "+before.Format("2-Jan-2006"))
+	}
+	if len(criteria) == 0 {
+		criteria = append(criteria, "ALL")
+	}
+
+	responses, err := s.execute(ctx, "UID SEARCH", "UID SEARCH "+strings.Join(criteria, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make([]uint32, 0)
+	for _, resp := range responses {
+		if !strings.HasPrefix(resp.text, "* SEARCH") {
+			continue
+		}
+		for _, field := range strings.Fields(strings.TrimPrefix(resp.text, "* SEARCH")) {
+			uid, parseErr := strconv.ParseUint(field, 10, 32)
+			if parseErr != nil {
+				return nil, fmt.Errorf("invalid uid in search response: %q", field)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	return uids, nil
+}
+
+// FetchMessages returns the raw RFC 5322 message of each UID of the selected mailbox.
+// UIDs that no longer exist or whose message is larger than MaxMessageBytes are missing from the result.
+func (s *Session) FetchMessages(ctx context.Context, uids []uint32) (map[uint32][]byte, error) {
+	messages := make(map[uint32][]byte, len(uids))
+	for start := 0; start < len(uids); start += FetchBatchSize {
+		end := min(start+FetchBatchSize, len(uids))
+		set := make([]string, 0, end-start)
+		for _, uid := range uids[start:end] {
+			set = append(set, strconv.FormatUint(uint64(uid), 10))
+		}
+
+		responses, err := s.execute(ctx, "UID FETCH", "UID FETCH "+strings.Join(set, ",")+" (UID BODY.PEEK[])")
+		if err != nil {
+			return nil, err
+		}
+		for _, resp := range responses {
+			if !strings.Contains(resp.text, " FETCH ") || len(resp.literals) == 0 || resp.literals[0] == nil {
+				continue
+			}
+			match := fetchUIDPattern.FindStringSubmatch(resp.text)
+			if match == nil {
+				continue
+			}
+			uid, parseErr := strconv.ParseUint(match[1], 10, 32)
+			if parseErr != nil {
+				continue
+			}
+			messages[uint32(uid)] = resp.literals[0]
+		}
+	}
+	return messages, nil
+}
+
+// Logout ends the session and closes the connection. A session already closed by an I/O error returns nil.
+func (s *Session) Logout(ctx context.Context) error {
+	s.mu.Lock()
+	broken := s.broken != nil
+	s.mu.Unlock()
+	if broken {
+		return nil
+	}
+
+	_, err := s.execute(ctx, "LOGOUT", "LOGOUT")
+	closeErr := s.conn.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// response is one untagged or tagged server response with its literals read out of the stream.
+type response struct {
+	text     string
+	literals [][]byte
+}
+
+func (s *Session) readGreeting(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stop := s.bindDeadline(ctx)
+	defer stop()
+
+	greeting, err := s.readResponse()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting.text, "* OK") {
+		return fmt.Errorf("unexpected greeting: %q", greeting.text)
+	}
+	return nil
+}
+
+// execute sends one command and returns the untagged responses sent before its completion.
+func (s *Session) execute(ctx context.Context, name, command string) ([]response, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken != nil {
+		return nil, fmt.Errorf("imap %s failed: session is closed: %w", name, s.broken)
+	}
+
+	stop := s.bindDeadline(ctx)
+	defer stop()
+
+	s.tagSeq++
+	tag := fmt.Sprintf("A%04d", s.tagSeq)
+	if _, err := io.WriteString(s.conn, tag+" "+command+"\r\n"); err != nil {
+		return nil, s.wrapIOError(ctx, name, err)
+	}
+
+	untagged := make([]response, 0)
+	for {
+		resp, err := s.readResponse()
+		if err != nil {
+			return nil, s.wrapIOError(ctx, name, err)
+		}
+		if !strings.HasPrefix(resp.text, tag+" ") {
+			untagged = append(untagged, resp)
+			continue
+		}
+
+		status, text, _ := strings.Cut(strings.TrimPrefix(resp.text, tag+" "), " ")
+		if strings.EqualFold(status, "OK") {
+			return untagged, nil
+		}
+		s.log.Warn("external_api_failed",
+			logger.String("provider", "imap"),
+			logger.String("operation", strings.ToLower(strings.ReplaceAll(name, " ", "_"))),
+			logger.String("status", status),
+		)
+		return nil, &CommandError{Command: name, Status: strings.ToUpper(status), Text: text}
+	}
+}
+
+// bindDeadline bounds the I/O of one command by the context and the command timeout.
+func (s *Session) bindDeadline(ctx context.Context) func() {
+	deadline := time.Now().Add(s.commandTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = s.conn.SetDeadline(deadline)
+	stopAfter := context.AfterFunc(ctx, func() {
+		_ = s.conn.SetDeadline(time.Now())
+	})
+	return func() {
+		stopAfter()
+		_ = s.conn.SetDeadline(time.Time{})
+	}
+}
+
+// wrapIOError closes the connection because a command interrupted halfway leaves unread
+// responses in the stream, so the session cannot be used any more.
+func (s *Session) wrapIOError(ctx context.Context, name string, err error) error {
+	s.broken = err
+	_ = s.conn.Close()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return fmt.Errorf("imap %s failed: %w", name, err)
+}
+
+// readResponse reads one response line and every literal it announces with {n}.
+// A literal larger than MaxMessageBytes is read past and kept as nil, so one huge message
+// neither fills the memory nor breaks the session.
+func (s *Session) readResponse() (response, error) {
+	var resp response
+	var text strings.Builder
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return response{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		text.WriteString(line)
+
+		match := literalSuffixPattern.FindStringSubmatch(line)
+		if match == nil {
+			resp.text = text.String()
+			return resp, nil
+		}
+		size, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return response{}, fmt.Errorf("invalid literal size: %s", match[1])
+		}
+		if size > MaxMessageBytes {
+			if _, err := io.CopyN(io.Discard, s.reader, size); err != nil {
+				return response{}, err
+			}
+			resp.literals = append(resp.literals, nil)
+			continue
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(s.reader, literal); err != nil {
+			return response{}, err
+		}
+		resp.literals = append(resp.literals, literal)
+	}
+}
+
+// refusePrivateAddress is a net.Dialer Control hook that rejects addresses outside the public internet.
+func refusePrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("address %q is not an IP address", address)
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("address %s is not allowed", ip)
+	}
+	return nil
+}
+
+// quoteString renders value as an IMAP quoted string.
+func quoteString(value string) (string, error) {
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\r' || value[i] == '\n' || value[i] >= 0x80 {
+			return "", errors.New("imap quoted string must be 7-bit text without line breaks")
+		}
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return `"` + escaped + `"`, nil
+}
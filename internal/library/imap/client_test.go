@@ -0,0 +1,191 @@
+package imap_test
+
+import (
+	"business/internal/library/imap"
+	"business/internal/library/imap/imaptest"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *imaptest.Server {
+	t.Helper()
+
+	server, err := imaptest.NewServer("billing@example.com", `p"ss\word`, map[string]imaptest.Mailbox{
+		"INBOX": {
+			UIDValidity: 7,
+			Messages: []imaptest.Message{
+				{UID: 3, InternalDate: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), Raw: []byte("Subject: first\r\n\r\nbody 1")},
+				{UID: 5, InternalDate: time.Date(2026, 10, 3, 8, 0, 0, 0, time.UTC), Raw: []byte("Subject: second\r\n\r\nbody 2")},
+			},
+		},
+		"請求書": {UIDValidity: 11},
+	})
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testConfig(server *imaptest.Server, password string) imap.Config {
+	return imap.Config{
+		Host:     server.Host(),
+		Port:     server.Port(),
+		Security: imap.SecurityNone,
+		Username: "billing@example.com",
+		Password: password,
+	}
+}
+
+func TestDialer_Dial(t *testing.T) {
+	t.Parallel()
+
+	t.Run("logs in with a quoted password", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+
+		session, err := imap.NewDialer(time.Second, nil).WithPrivateAddresses(true).Dial(context.Background(), testConfig(server, `p"ss\word`))
+
+		require.NoError(t, err)
+		require.NoError(t, session.Logout(context.Background()))
+		assert.Equal(t, []string{`LOGIN "billing@example.com" "p\"ss\\word"`, "LOGOUT"}, server.Commands())
+	})
+
+	t.Run("returns ErrAuthenticationFailed when the server rejects LOGIN", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+
+		_, err := imap.NewDialer(time.Second, nil).WithPrivateAddresses(true).Dial(context.Background(), testConfig(server, "wrong"))
+
+		assert.ErrorIs(t, err, imap.ErrAuthenticationFailed)
+	})
+
+	t.Run("returns ErrConnectionFailed when nothing listens on the port", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+		cfg := testConfig(server, `p"ss\word`)
+		server.Close()
+
+		_, err := imap.NewDialer(time.Second, nil).WithPrivateAddresses(true).Dial(context.Background(), cfg)
+
+		assert.ErrorIs(t, err, imap.ErrConnectionFailed)
+	})
+
+	t.Run("refuses loopback addresses unless private addresses are allowed", func(t *testing.T) {
+		t.Parallel()
+		server := newTestServer(t)
+
+		_, err := imap.NewDialer(time.Second, nil).Dial(context.Background(), testConfig(server, `p"ss\word`))
+
+		assert.ErrorIs(t, err, imap.ErrConnectionFailed)
+		assert.Empty(t, server.Commands())
+	})
+}
+
+func TestSession_SelectSearchFetch(t *testing.T) {
+	t.Parallel()
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	session, err := imap.NewDialer(time.Second, nil).WithPrivateAddresses(true).Dial(ctx, testConfig(server, `p"ss\word`))
+	require.NoError(t, err)
+	defer func() { _ = session.Logout(ctx) }()
+
+	mailbox, err := session.Select(ctx, "INBOX")
+	require.NoError(t, err)
+	assert.Equal(t, imap.Mailbox{Name: "INBOX", UIDValidity: 7, Exists: 2}, mailbox)
+
+	uids, err := session.SearchUIDs(ctx, time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{5}, uids)
+
+	all, err := session.SearchUIDs(ctx, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{3, 5}, all)
+
+	messages, err := session.FetchMessages(ctx, []uint32{3, 5, 9})
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32][]byte{
+		3: []byte("Subject: first\r\n\r\nbody 1"),
+		5: []byte("Subject: second\r\n\r\nbody 2"),
+	}, messages)
+
+	japanese, err := session.Select(ctx, "請求書")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), japanese.UIDValidity)
+
+	_, err = session.Select(ctx, "Missing")
+	assert.ErrorIs(t, err, imap.ErrMailboxNotFound)
+
+	assert.Contains(t, server.Commands(), "UID SEARCH SINCE 2-Oct-2026 BEFORE 4-Oct-2026")
+	assert.Contains(t, server.Commands(), `EXAMINE "&istsQmb4-"`)
+}
+
+func TestSession_FetchMessages_SkipsOversizedMessages(t *testing.T) {
+	t.Parallel()
+
+	huge := append([]byte("Subject: huge\r\n\r\n"), bytes.Repeat([]byte("a"), imap.MaxMessageBytes)...)
+	server, err := imaptest.NewServer("billing@example.com", "secret", map[string]imaptest.Mailbox{
+		"INBOX": {
+			UIDValidity: 7,
+			Messages: []imaptest.Message{
+				{UID: 3, InternalDate: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), Raw: huge},
+				{UID: 5, InternalDate: time.Date(2026, 10, 3, 8, 0, 0, 0, time.UTC), Raw: []byte("Subject: small\r\n\r\nbody")},
+			},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	session, err := imap.NewDialer(time.Second, nil).WithPrivateAddresses(true).Dial(ctx, testConfig(server, "secret"))
+	require.NoError(t, err)
+	defer func() { _ = session.Logout(ctx) }()
+	_, err = session.Select(ctx, "INBOX")
+	require.NoError(t, err)
+
+	messages, err := session.FetchMessages(ctx, []uint32{3, 5})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32][]byte{5: []byte("Subject: small\r\n\r\nbody")}, messages)
+}
+
+func TestSession_ContextCancel(t *testing.T) {
+	t.Parallel()
+	server := newTestServer(t)
+
+	session, err := imap.NewDialer(time.Second, nil).WithPrivateAddresses(true).Dial(context.Background(), testConfig(server, `p"ss\word`))
+	require.NoError(t, err)
+	defer func() { _ = session.Logout(context.Background()) }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = session.Select(ctx, "INBOX")
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestEncodeMailboxName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "ascii is kept", input: "INBOX/Invoices", expected: "INBOX/Invoices"},
+		{name: "ampersand is escaped", input: "A&B", expected: "A&-B"},
+		{name: "japanese is encoded", input: "請求書", expected: "&istsQmb4-"},
+		{name: "mixed text", input: "Billing 請求", expected: "Billing &istsQg-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, imap.EncodeMailboxName(tt.input))
+		})
+	}
+}
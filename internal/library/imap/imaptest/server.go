@@ -0,0 +1,255 @@
+// Package imaptest provides a local IMAP server for tests of code that uses the imap package.
+package imaptest
+
+import (
+	"bufio"
+	"business/internal/library/imap"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is a message stored in a fake mailbox.
+type Message struct {
+	UID          uint32
+	InternalDate time.Time
+	Raw          []byte
+}
+
+// Mailbox is a fake mailbox keyed by its UTF-8 name in Server.
+type Mailbox struct {
+	UIDValidity uint32
+	Messages    []Message
+}
+
+// Server is a plain-text IMAP4rev1 server that supports the commands used by the imap package:
+// LOGIN, EXAMINE, UID SEARCH with SINCE / BEFORE, UID FETCH with BODY.PEEK[] and LOGOUT.
+type Server struct {
+	username  string
+	password  string
+	mailboxes map[string]Mailbox
+	listener  net.Listener
+
+	mu       sync.Mutex
+	commands []string
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port. Close must be called when the test ends.
+func NewServer(username, password string, mailboxes map[string]Mailbox) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		username:  username,
+		password:  password,
+		mailboxes: mailboxes,
+		listener:  listener,
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Host returns the host the server listens on.
+func (s *Server) Host() string {
+	return s.listener.Addr().(*net.TCPAddr).IP.String()
+}
+
+// Port returns the port the server listens on.
+func (s *Server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Commands returns every command received so far without its tag.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Close stops accepting connections and waits for open ones to end.
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	defer writer.Flush()
+
+	write := func(format string, args ...any) {
+		fmt.Fprintf(writer, format+"\r\n", args...)
+	}
+
+	write("* OK imaptest ready")
+	_ = writer.Flush()
+
+	var selected *Mailbox
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		verb, args, _ := strings.Cut(command, " ")
+		switch strings.ToUpper(verb) {
+		case "LOGIN":
+			fields := unquoteFields(args)
+			if len(fields) == 2 && fields[0] == s.username && fields[1] == s.password {
+				write("%s OK LOGIN completed", tag)
+			} else {
+				write("%s NO [AUTHENTICATIONFAILED] invalid credentials", tag)
+			}
+		case "EXAMINE", "SELECT":
+			selected = nil
+			fields := unquoteFields(args)
+			for name, mailbox := range s.mailboxes {
+				if len(fields) == 1 && imap.EncodeMailboxName(name) == fields[0] {
+					mailbox := mailbox
+					selected = &mailbox
+				}
+			}
+			if selected == nil {
+				write("%s NO mailbox does not exist", tag)
+				break
+			}
+			write("* %d EXISTS", len(selected.Messages))
+			write("* OK [UIDVALIDITY %d] UIDs valid", selected.UIDValidity)
+			write("%s OK [READ-ONLY] EXAMINE completed", tag)
+		case "UID":
+			if selected == nil {
+				write("%s BAD no mailbox selected", tag)
+				break
+			}
+			sub, rest, _ := strings.Cut(args, " ")
+			switch strings.ToUpper(sub) {
+			case "SEARCH":
+				uids, ok := searchUIDs(*selected, rest)
+				if !ok {
+					write("%s BAD invalid search criteria", tag)
+					break
+				}
+				write("* SEARCH%s", uids)
+				write("%s OK SEARCH completed", tag)
+			case "FETCH":
+				set, _, _ := strings.Cut(rest, " ")
+				wanted := map[string]bool{}
+				for _, uid := range strings.Split(set, ",") {
+					wanted[uid] = true
+				}
+				for i, message := range selected.Messages {
+					if !wanted[strconv.FormatUint(uint64(message.UID), 10)] {
+						continue
+					}
+					fmt.Fprintf(writer, "* %d FETCH (UID %d BODY[] {%d}\r\n", i+1, message.UID, len(message.Raw))
+					_, _ = writer.Write(message.Raw)
+					write(")")
+				}
+				write("%s OK FETCH completed", tag)
+			default:
+				write("%s BAD unsupported UID command", tag)
+			}
+		case "LOGOUT":
+			write("* BYE logging out")
+			write("%s OK LOGOUT completed", tag)
+			return
+		default:
+			write("%s BAD unsupported command", tag)
+		}
+		_ = writer.Flush()
+	}
+}
+
+func searchUIDs(mailbox Mailbox, criteria string) (string, bool) {
+	var since, before time.Time
+	fields := strings.Fields(criteria)
+	for i := 0; i < len(fields); i++ {
+		switch strings.ToUpper(fields[i]) {
+		case "ALL":
+		case "SINCE", "BEFORE":
+			if i+1 >= len(fields) {
+				return "", false
+			}
+			date, err := time.Parse("2-Jan-2006", fields[i+1])
+			if err != nil {
+				return "", false
+			}
+			if strings.EqualFold(fields[i], "SINCE") {
+				since = date
+			} else {
+				before = date
+			}
+			i++
+		default:
+			return "", false
+		}
+	}
+
+	var b strings.Builder
+	for _, message := range mailbox.Messages {
+		day := time.Date(message.InternalDate.Year(), message.InternalDate.Month(), message.InternalDate.Day(), 0, 0, 0, 0, time.UTC)
+		if !since.IsZero() && day.Before(since) {
+			continue
+		}
+		if !before.IsZero() && !day.Before(before) {
+			continue
+		}
+		fmt.Fprintf(&b, " %d", message.UID)
+	}
+	return b.String(), true
+}
+
+// unquoteFields splits space-separated quoted strings, e.g. `"user" "pass"`.
+func unquoteFields(args string) []string {
+	fields := make([]string, 0, 2)
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		if args[0] != '"' {
+			field, rest, _ := strings.Cut(args, " ")
+			fields = append(fields, field)
+			args = rest
+			continue
+		}
+		var b strings.Builder
+		i := 1
+		for ; i < len(args) && args[i] != '"'; i++ {
+			if args[i] == '\\' && i+1 < len(args) {
+				i++
+			}
+			b.WriteByte(args[i])
+		}
+		fields = append(fields, b.String())
+		if i+1 > len(args) {
+			break
+		}
+		args = args[i+1:]
+	}
+	return fields
+}
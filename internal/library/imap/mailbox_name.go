@@ -0,0 +1,48 @@
+package imap
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf16"
+)
+
+// modifiedBase64 is the base64 variant of RFC 3501 section 5.1.3, which uses "," instead of "/".
+var modifiedBase64 = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+,").WithPadding(base64.NoPadding)
+
+// EncodeMailboxName converts a UTF-8 mailbox name such as "請求書" to the modified UTF-7
+// that IMAP servers expect on the wire.
+func EncodeMailboxName(name string) string {
+	var b strings.Builder
+	var pending []rune
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		units := utf16.Encode(pending)
+		raw := make([]byte, 0, len(units)*2)
+		for _, unit := range units {
+			raw = append(raw, byte(unit>>8), byte(unit))
+		}
+		b.WriteByte('&')
+		b.WriteString(modifiedBase64.EncodeToString(raw))
+		b.WriteByte('-')
+		pending = pending[:0]
+	}
+
+	for _, r := range name {
+		switch {
+		case r == '&':
+			flush()
+			b.WriteString("&-")
+		case r >= 0x20 && r <= 0x7e:
+			flush()
+			b.WriteRune(r)
+		default:
+			pending = append(pending, r)
+		}
+	}
+	flush()
+
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package mailarchive
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+)
+
+// ErrEmptyArchive is returned for a file without any message.
+var ErrEmptyArchive = errors.New("mail archive has no message")
+
+var (
+	mboxSeparatorPrefix = []byte("From ")
+	// escapedFromLine matches the ">From " lines that mbox writers use to quote body lines starting with "From ".
+	escapedFromLine = regexp.MustCompile(`^>+From `)
+)
+
+// Split returns the raw RFC 5322 messages in an uploaded file.
+// A file that starts with an mbox "From " separator line is read as an mbox archive, in which a
+// separator line must follow an empty line. Anything else is returned as a single .eml message.
+func Split(content []byte) ([][]byte, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, ErrEmptyArchive
+	}
+	if !bytes.HasPrefix(content, mboxSeparatorPrefix) {
+		return [][]byte{content}, nil
+	}
+
+	var (
+		messages  [][]byte
+		current   []byte
+		started   bool
+		prevBlank = true
+	)
+	flush := func() {
+		if started {
+			if message := trimSeparatorBlankLine(current); len(bytes.TrimSpace(message)) > 0 {
+				messages = append(messages, message)
+			}
+		}
+		current = nil
+	}
+
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if prevBlank && bytes.HasPrefix(line, mboxSeparatorPrefix) {
+			flush()
+			started = true
+			prevBlank = false
+			continue
+		}
+		if escapedFromLine.Match(line) {
+			line = line[1:]
+		}
+		current = append(current, line...)
+		prevBlank = len(bytes.TrimRight(line, "\r\n")) == 0
+	}
+	flush()
+
+	if len(messages) == 0 {
+		return nil, ErrEmptyArchive
+	}
+	return messages, nil
+}
+
+// trimSeparatorBlankLine drops the empty line that mbox writers put before the next "From " line.
+func trimSeparatorBlankLine(message []byte) []byte {
+	for _, ending := range [][]byte{[]byte("\r\n"), []byte("\n")} {
+		if bytes.HasSuffix(message, append(append([]byte{}, ending...), ending...)) {
+			return message[:len(message)-len(ending)]
+		}
+	}
+	return message
+}
@@ -0,0 +1,80 @@
+package mailarchive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns an .eml file as one message", func(t *testing.T) {
+		t.Parallel()
+
+		raw := "Subject: invoice\r\nDate: Tue, 14 Oct 2026 09:30:00 +0900\r\n\r\nFrom the billing team\r\n"
+
+		messages, err := Split([]byte(raw))
+
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		assert.Equal(t, raw, string(messages[0]))
+	})
+
+	t.Run("splits an mbox archive on separator lines after an empty line", func(t *testing.T) {
+		t.Parallel()
+
+		mbox := strings.Join([]string{
+			"From billing@example.com Tue Oct 14 09:30:00 2026",
+			"Subject: first",
+			"",
+			"body line",
+			"From here on the line is part of the body",
+			">From quoted",
+			">>From double quoted",
+			"",
+			"From billing@example.com Wed Oct 15 09:30:00 2026",
+			"Subject: second",
+			"",
+			"second body",
+			"",
+		}, "\n")
+
+		messages, err := Split([]byte(mbox))
+
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, "Subject: first\n\nbody line\nFrom here on the line is part of the body\nFrom quoted\n>From double quoted\n", string(messages[0]))
+		assert.Equal(t, "Subject: second\n\nsecond body\n", string(messages[1]))
+	})
+
+	t.Run("keeps CRLF line endings in mbox messages", func(t *testing.T) {
+		t.Parallel()
+
+		mbox := "From a@example.com Tue Oct 14 09:30:00 2026\r\nSubject: crlf\r\n\r\nbody\r\n\r\nFrom b@example.com Tue Oct 14 09:31:00 2026\r\nSubject: next\r\n\r\nnext\r\n"
+
+		messages, err := Split([]byte(mbox))
+
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, "Subject: crlf\r\n\r\nbody\r\n", string(messages[0]))
+	})
+
+	t.Run("rejects an empty file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Split([]byte("\xef\xbb\xbf \r\n"))
+
+		assert.ErrorIs(t, err, ErrEmptyArchive)
+	})
+
+	t.Run("rejects an mbox archive without message content", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Split([]byte("From a@example.com Tue Oct 14 09:30:00 2026\n\n"))
+
+		assert.ErrorIs(t, err, ErrEmptyArchive)
+	})
+}
@@ -0,0 +1,98 @@
+package mailmime
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// charsetAliases maps labels that mailers use but the WHATWG encoding index does not know.
+var charsetAliases = map[string]string{
+	"cp932":   "windows-31j",
+	"x-cp932": "windows-31j",
+}
+
+// iso2022JPEscapes are the escape sequences that switch ISO-2022-JP into a Japanese character set.
+var iso2022JPEscapes = [][]byte{[]byte("\x1b$B"), []byte("\x1b$@"), []byte("\x1b(J"), []byte("\x1b(I")}
+
+// headerDecoder decodes RFC 2047 encoded words in any charset that DecodeCharset supports.
+var headerDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		enc := lookupEncoding(charset)
+		if enc == nil {
+			return nil, fmt.Errorf("unsupported charset %q", charset)
+		}
+		return enc.NewDecoder().Reader(input), nil
+	},
+}
+
+// DecodeCharset converts text in the given MIME charset to UTF-8.
+// A missing or ASCII / UTF-8 label is checked against the bytes, because Japanese mail is often sent
+// with ISO-2022-JP or Shift_JIS content under such labels. Bytes that cannot be decoded become U+FFFD.
+func DecodeCharset(data []byte, charset string) string {
+	label := strings.ToLower(strings.TrimSpace(charset))
+	switch label {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return decodeUnlabeled(data)
+	}
+
+	enc := lookupEncoding(label)
+	if enc == nil {
+		return strings.ToValidUTF8(string(data), "\uFFFD")
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "\uFFFD")
+	}
+	return string(decoded)
+}
+
+// DecodeHeader decodes RFC 2047 encoded words and unlabeled Japanese header bytes.
+// A header that cannot be decoded is kept as received.
+func DecodeHeader(raw string) string {
+	decoded, err := headerDecoder.DecodeHeader(raw)
+	if err != nil {
+		decoded = raw
+	}
+	return decodeUnlabeled([]byte(decoded))
+}
+
+func lookupEncoding(charset string) encoding.Encoding {
+	label := strings.ToLower(strings.TrimSpace(charset))
+	if alias, ok := charsetAliases[label]; ok {
+		label = alias
+	}
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return nil
+	}
+	return enc
+}
+
+// decodeUnlabeled guesses the Japanese encoding of text whose label cannot be trusted.
+func decodeUnlabeled(data []byte) string {
+	for _, escape := range iso2022JPEscapes {
+		if bytes.Contains(data, escape) {
+			if decoded, err := japanese.ISO2022JP.NewDecoder().Bytes(data); err == nil {
+				return string(decoded)
+			}
+			break
+		}
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	for _, enc := range []encoding.Encoding{japanese.ShiftJIS, japanese.EUCJP} {
+		if decoded, err := enc.NewDecoder().Bytes(data); err == nil && utf8.Valid(decoded) && !bytes.ContainsRune(decoded, utf8.RuneError) {
+			return string(decoded)
+		}
+	}
+	return strings.ToValidUTF8(string(data), "\uFFFD")
+}
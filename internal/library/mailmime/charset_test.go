@@ -0,0 +1,73 @@
+package mailmime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func encode(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+
+	encoded, err := enc.NewEncoder().Bytes([]byte(text))
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestDecodeCharset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		charset string
+		want    string
+	}{
+		{name: "iso-2022-jp", data: encode(t, japanese.ISO2022JP, "ご請求金額 1,000円"), charset: "ISO-2022-JP", want: "ご請求金額 1,000円"},
+		{name: "shift_jis", data: encode(t, japanese.ShiftJIS, "請求書①"), charset: "Shift_JIS", want: "請求書①"},
+		{name: "cp932 alias", data: encode(t, japanese.ShiftJIS, "株式会社"), charset: "cp932", want: "株式会社"},
+		{name: "euc-jp", data: encode(t, japanese.EUCJP, "領収書"), charset: "euc-jp", want: "領収書"},
+		{name: "latin-1", data: []byte("caf\xe9"), charset: "iso-8859-1", want: "café"},
+		{name: "utf-8", data: []byte("請求書"), charset: "utf-8", want: "請求書"},
+		{name: "iso-2022-jp labeled as us-ascii", data: encode(t, japanese.ISO2022JP, "お支払い"), charset: "us-ascii", want: "お支払い"},
+		{name: "unlabeled shift_jis", data: encode(t, japanese.ShiftJIS, "ご利用料金"), charset: "", want: "ご利用料金"},
+		{name: "unknown charset keeps valid text", data: []byte("total\xff"), charset: "x-unknown", want: "total�"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, DecodeCharset(tt.data, tt.charset))
+		})
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "iso-2022-jp encoded word", raw: "=?ISO-2022-JP?B?GyRCQEE1YUxAOlkbKEI=?=", want: "請求明細"},
+		{name: "shift_jis encoded word", raw: "=?Shift_JIS?B?kL+LgY+R?= <billing@example.com>", want: "請求書 <billing@example.com>"},
+		{name: "quoted-printable utf-8", raw: "=?UTF-8?Q?=E9=A0=98=E5=8F=8E=E6=9B=B8?=", want: "領収書"},
+		{name: "plain text", raw: "Invoice #1", want: "Invoice #1"},
+		{name: "unknown charset is kept", raw: "=?x-unknown?B?YWJj?=", want: "=?x-unknown?B?YWJj?="},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, DecodeHeader(tt.raw))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package mailmime
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// maxPartDepth stops walking pathologically nested multipart messages.
+const maxPartDepth = 16
+
+// BodyPreference decides which part of a multipart/alternative message becomes the body.
+type BodyPreference string
+
+const (
+	// PreferPlainText uses text/plain and falls back to text/html. It is the default.
+	PreferPlainText BodyPreference = "plain"
+	// PreferHTML uses text/html and falls back to text/plain, for senders whose plain part is only a stub.
+	PreferHTML BodyPreference = "html"
+)
+
+// ParseBodyPreference reads a preference from configuration. An empty value is the default.
+func ParseBodyPreference(raw string) (BodyPreference, error) {
+	switch BodyPreference(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", PreferPlainText:
+		return PreferPlainText, nil
+	case PreferHTML:
+		return PreferHTML, nil
+	default:
+		return "", fmt.Errorf("unknown body preference %q: want %q or %q", raw, PreferPlainText, PreferHTML)
+	}
+}
+
+// Message is the subset of an RFC 5322 message that the mail fetch stage needs.
+type Message struct {
+	MessageID string
+	Subject   string
+	From      string
+	To        []string
+	Date      time.Time
+	// Body is the first text/plain or text/html part in depth-first order, chosen by BodyPreference and
+	// decoded from its transfer encoding and charset. Attached text files are skipped.
+	// HTML is returned as is so that callers can normalize it the same way for every provider.
+	Body string
+}
+
+// Parse reads a raw RFC 5322 message such as an IMAP BODY[] or an .eml file, preferring text/plain.
+// A message without a readable text part is returned with an empty Body.
+func Parse(raw []byte) (Message, error) {
+	return ParseWithPreference(raw, PreferPlainText)
+}
+
+// ParseWithPreference is Parse with the body chosen by preference.
+func ParseWithPreference(raw []byte, preference BodyPreference) (Message, error) {
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	header := parsed.Header
+	msg := Message{
+		MessageID: strings.TrimSpace(header.Get("Message-Id")),
+		Subject:   DecodeHeader(header.Get("Subject")),
+		From:      DecodeHeader(header.Get("From")),
+		To:        splitAddressList(DecodeHeader(header.Get("To"))),
+	}
+	if date, err := mail.ParseDate(header.Get("Date")); err == nil {
+		msg.Date = date
+	}
+
+	var parts textParts
+	if err := parts.collect(header.Get("Content-Type"), header.Get("Content-Transfer-Encoding"), header.Get("Content-Disposition"), parsed.Body, 0); err != nil {
+		return Message{}, err
+	}
+	msg.Body = parts.body(preference)
+
+	return msg, nil
+}
+
+// textParts holds the first non-empty text/plain and text/html parts of a message.
+type textParts struct {
+	plain string
+	html  string
+}
+
+func (t *textParts) body(preference BodyPreference) string {
+	if preference == PreferHTML && t.html != "" {
+		return t.html
+	}
+	if t.plain != "" {
+		return t.plain
+	}
+	return t.html
+}
+
+func (t *textParts) collect(contentType, transferEncoding, contentDisposition string, body io.Reader, depth int) error {
+	if depth > maxPartDepth || (t.plain != "" && t.html != "") {
+		return nil
+	}
+	// Text files attached to the message, such as a CSV statement, are not the body.
+	if disposition, dispositionParams, err := mime.ParseMediaType(contentDisposition); err == nil &&
+		(disposition == "attachment" || dispositionParams["filename"] != "") {
+		return nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// RFC 2045 treats a missing or broken Content-Type as text/plain.
+		mediaType = "text/plain"
+		params = map[string]string{}
+	}
+
+	switch {
+	case mediaType == "text/plain" || mediaType == "text/html":
+		target := &t.plain
+		if mediaType == "text/html" {
+			target = &t.html
+		}
+		if *target != "" {
+			return nil
+		}
+		decoded, err := io.ReadAll(decodeTransferEncoding(transferEncoding, body))
+		if err != nil {
+			return fmt.Errorf("failed to decode %s part: %w", mediaType, err)
+		}
+		*target = DecodeCharset(decoded, params["charset"])
+		return nil
+	case strings.HasPrefix(mediaType, "multipart/"):
+		boundary := params["boundary"]
+		if boundary == "" {
+			return nil
+		}
+		reader := multipart.NewReader(body, boundary)
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read multipart body: %w", err)
+			}
+			if err := t.collect(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part.Header.Get("Content-Disposition"), part, depth+1); err != nil {
+				return err
+			}
+			if t.plain != "" && t.html != "" {
+				return nil
+			}
+		}
+	default:
+		return nil
+	}
+}
+
+func decodeTransferEncoding(transferEncoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, newBase64Filter(body))
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+func splitAddressList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
+
+// base64Filter drops line breaks and other bytes outside the base64 alphabet,
+// which mail clients put into base64 bodies.
+type base64Filter struct {
+	src io.Reader
+}
+
+func newBase64Filter(src io.Reader) io.Reader {
+	return &base64Filter{src: src}
+}
+
+func (f *base64Filter) Read(p []byte) (int, error) {
+	for {
+		n, err := f.src.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if isBase64Byte(b) {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+func isBase64Byte(b byte) bool {
+	return (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '+' || b == '/' || b == '='
+}
@@ -0,0 +1,200 @@
+package mailmime
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func crlf(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\r\n"))
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reads headers and a plain text body", func(t *testing.T) {
+		t.Parallel()
+
+		msg, err := Parse(crlf(
+			"Message-ID: <invoice-1@example.com>",
+			"Subject: =?UTF-8?B?6KuL5rGC5pu4?=",
+			"From: Billing <billing@example.com>",
+			"To: a@example.com,b@example.com",
+			"Date: Tue, 14 Oct 2026 09:30:00 +0900",
+			"Content-Type: text/plain; charset=utf-8",
+			"",
+			"Total 1,000 JPY",
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, "<invoice-1@example.com>", msg.MessageID)
+		assert.Equal(t, "請求書", msg.Subject)
+		assert.Equal(t, "Billing <billing@example.com>", msg.From)
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, msg.To)
+		assert.True(t, msg.Date.Equal(time.Date(2026, 10, 14, 0, 30, 0, 0, time.UTC)))
+		assert.Equal(t, "Total 1,000 JPY", msg.Body)
+	})
+
+	t.Run("takes the first text part of a multipart message and decodes it", func(t *testing.T) {
+		t.Parallel()
+
+		msg, err := Parse(crlf(
+			"Subject: multipart",
+			"Date: Tue, 14 Oct 2026 09:30:00 +0000",
+			`Content-Type: multipart/mixed; boundary="outer"`,
+			"",
+			"--outer",
+			`Content-Type: multipart/alternative; boundary="inner"`,
+			"",
+			"--inner",
+			"Content-Type: text/plain; charset=utf-8",
+			"Content-Transfer-Encoding: base64",
+			"",
+			"5ZCI6KiIIDEsMDAw",
+			"5YaG",
+			"--inner",
+			"Content-Type: text/html; charset=utf-8",
+			"",
+			"<p>html</p>",
+			"--inner--",
+			"--outer",
+			"Content-Type: application/pdf",
+			"",
+			"pdf",
+			"--outer--",
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, "合計 1,000円", msg.Body)
+	})
+
+	t.Run("decodes a quoted-printable html body", func(t *testing.T) {
+		t.Parallel()
+
+		msg, err := Parse(crlf(
+			"Subject: html",
+			"Content-Type: text/html; charset=utf-8",
+			"Content-Transfer-Encoding: quoted-printable",
+			"",
+			"<p>Amount=3D1000</p>=",
+			"<p>JPY</p>",
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, "<p>Amount=1000</p><p>JPY</p>", msg.Body)
+		assert.True(t, msg.Date.IsZero())
+	})
+
+	t.Run("decodes a japanese body and subject by their charsets", func(t *testing.T) {
+		t.Parallel()
+
+		msg, err := Parse(crlf(
+			"Subject: =?ISO-2022-JP?B?GyRCQEE1YUxAOlkbKEI=?=",
+			"Content-Type: text/plain; charset=ISO-2022-JP",
+			"Content-Transfer-Encoding: 7bit",
+			"",
+			string(encode(t, japanese.ISO2022JP, "ご請求金額 1,000円")),
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, "請求明細", msg.Subject)
+		assert.Equal(t, "ご請求金額 1,000円", msg.Body)
+	})
+
+	t.Run("returns an empty body when there is no text part", func(t *testing.T) {
+		t.Parallel()
+
+		msg, err := Parse(crlf(
+			"Subject: attachment only",
+			"Content-Type: application/pdf",
+			"",
+			"pdf",
+		))
+
+		require.NoError(t, err)
+		assert.Empty(t, msg.Body)
+	})
+
+	t.Run("fails on a message without a header block", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Parse([]byte("not a message"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestParseWithPreference(t *testing.T) {
+	t.Parallel()
+
+	alternative := crlf(
+		"Subject: alternative",
+		`Content-Type: multipart/mixed; boundary="outer"`,
+		"",
+		"--outer",
+		"Content-Type: text/plain; charset=utf-8",
+		`Content-Disposition: attachment; filename="statement.csv"`,
+		"",
+		"csv",
+		"--outer",
+		`Content-Type: multipart/alternative; boundary="inner"`,
+		"",
+		"--inner",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<p>html</p>",
+		"--inner",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"plain",
+		"--inner--",
+		"--outer--",
+	)
+	htmlOnly := crlf(
+		"Subject: html only",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<p>only</p>",
+	)
+
+	tests := []struct {
+		name       string
+		raw        []byte
+		preference BodyPreference
+		want       string
+	}{
+		{name: "plain preferred", raw: alternative, preference: PreferPlainText, want: "plain"},
+		{name: "html preferred", raw: alternative, preference: PreferHTML, want: "<p>html</p>"},
+		{name: "falls back to html", raw: htmlOnly, preference: PreferPlainText, want: "<p>only</p>"},
+		{name: "falls back to plain", raw: crlf("Content-Type: text/plain", "", "plain only"), preference: PreferHTML, want: "plain only"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := ParseWithPreference(tt.raw, tt.preference)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, msg.Body)
+		})
+	}
+}
+
+func TestParseBodyPreference(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]BodyPreference{"": PreferPlainText, " Plain ": PreferPlainText, "HTML": PreferHTML} {
+		got, err := ParseBodyPreference(raw)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseBodyPreference("markdown")
+	assert.Error(t, err)
+}
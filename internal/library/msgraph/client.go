@@ -0,0 +1,324 @@
+package msgraph
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/retry"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Microsoft Graph v1.0 endpoint.
+const DefaultBaseURL = "https://graph.microsoft.com/v1.0"
+
+const (
+	folderPageSize  = 100
+	messagePageSize = 50
+	maxErrorBody    = 64 << 10
+	messageFields   = "id,internetMessageId,subject,from,toRecipients,receivedDateTime,body"
+)
+
+// wellKnownFolderNames are the folder names Graph resolves regardless of the mailbox language.
+var wellKnownFolderNames = map[string]struct{}{
+	"inbox":        {},
+	"archive":      {},
+	"junkemail":    {},
+	"sentitems":    {},
+	"deleteditems": {},
+	"drafts":       {},
+}
+
+var (
+	// ErrMailFolderNotFound is returned when no mail folder matches the requested path.
+	ErrMailFolderNotFound = errors.New("graph mail folder not found")
+)
+
+// APIError is a non-2xx response from Graph.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("graph api error: status=%d code=%s message=%s", e.StatusCode, e.Code, e.Message)
+}
+
+// User is the part of the signed-in user's profile used to identify the mailbox.
+type User struct {
+	Mail              string `json:"mail"`
+	UserPrincipalName string `json:"userPrincipalName"`
+}
+
+// MailFolder is a folder of the signed-in user's mailbox.
+type MailFolder struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// EmailAddress is a Graph recipient address.
+type EmailAddress struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// Recipient wraps an EmailAddress as Graph returns it.
+type Recipient struct {
+	EmailAddress EmailAddress `json:"emailAddress"`
+}
+
+// ItemBody is a message body. ContentType is "text" or "html".
+type ItemBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+// Message is a message with the fields needed to build a FetchedEmailDTO.
+type Message struct {
+	ID                string      `json:"id"`
+	InternetMessageID string      `json:"internetMessageId"`
+	Subject           string      `json:"subject"`
+	From              Recipient   `json:"from"`
+	ToRecipients      []Recipient `json:"toRecipients"`
+	ReceivedDateTime  time.Time   `json:"receivedDateTime"`
+	Body              ItemBody    `json:"body"`
+}
+
+// ClientFactory creates Graph clients bound to an authorized HTTP client.
+type ClientFactory struct {
+	baseURL string
+	backoff []time.Duration
+	log     logger.Interface
+}
+
+// NewClientFactory creates a ClientFactory. An empty baseURL uses DefaultBaseURL, which tests replace
+// with an httptest server. Requests answered with 429 or 5xx are retried after each backoff duration.
+func NewClientFactory(baseURL string, backoff []time.Duration, log logger.Interface) *ClientFactory {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &ClientFactory{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		backoff: backoff,
+		log:     log.With(logger.Component("graph_client")),
+	}
+}
+
+// NewClient returns a Client that sends its requests with httpClient, which adds the OAuth token.
+func (f *ClientFactory) NewClient(httpClient *http.Client) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    f.baseURL,
+		backoff:    f.backoff,
+		log:        f.log,
+	}
+}
+
+// Client calls the Graph mail API for the signed-in user.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	backoff    []time.Duration
+	log        logger.Interface
+}
+
+// GetMe returns the signed-in user's profile.
+func (c *Client) GetMe(ctx context.Context) (User, error) {
+	var user User
+	if err := c.get(ctx, c.endpoint("/me", url.Values{"$select": {"mail,userPrincipalName"}}), &user); err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+// FindMailFolder resolves a folder path such as "Invoices" or "Inbox/Invoices" by display name,
+// compared case-insensitively level by level. A top-level name that matches no display name is
+// tried as a well-known folder name, so "inbox" works for mailboxes in any language.
+func (c *Client) FindMailFolder(ctx context.Context, path string) (MailFolder, error) {
+	segments := strings.Split(strings.Trim(strings.TrimSpace(path), "/"), "/")
+	if len(segments) == 0 || segments[0] == "" {
+		return MailFolder{}, fmt.Errorf("%w: %q", ErrMailFolderNotFound, path)
+	}
+
+	var current MailFolder
+	for i, segment := range segments {
+		listPath := "/me/mailFolders"
+		if i > 0 {
+			listPath = "/me/mailFolders/" + url.PathEscape(current.ID) + "/childFolders"
+		}
+
+		folders, err := c.listFolders(ctx, listPath)
+		if err != nil {
+			return MailFolder{}, err
+		}
+
+		found := false
+		for _, folder := range folders {
+			if strings.EqualFold(strings.TrimSpace(folder.DisplayName), strings.TrimSpace(segment)) {
+				current = folder
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		if _, ok := wellKnownFolderNames[strings.ToLower(strings.TrimSpace(segment))]; i == 0 && ok {
+			var folder MailFolder
+			if err := c.get(ctx, c.endpoint("/me/mailFolders/"+strings.ToLower(strings.TrimSpace(segment)), url.Values{"$select": {"id,displayName"}}), &folder); err != nil {
+				return MailFolder{}, err
+			}
+			current = folder
+			continue
+		}
+		return MailFolder{}, fmt.Errorf("%w: %q", ErrMailFolderNotFound, path)
+	}
+
+	return current, nil
+}
+
+// ListMessages returns the folder's messages received in [since, until), oldest first.
+func (c *Client) ListMessages(ctx context.Context, folderID string, since, until time.Time) ([]Message, error) {
+	filter := "receivedDateTime ge " + since.UTC().Format(time.RFC3339)
+	if !until.IsZero() {
+		filter += " and receivedDateTime lt " + until.UTC().Format(time.RFC3339)
+	}
+
+	next := c.endpoint("/me/mailFolders/"+url.PathEscape(folderID)+"/messages", url.Values{
+		"$filter":  {filter},
+		"$orderby": {"receivedDateTime"},
+		"$select":  {messageFields},
+		"$top":     {fmt.Sprint(messagePageSize)},
+	})
+
+	messages := make([]Message, 0)
+	for next != "" {
+		var page struct {
+			Value    []Message `json:"value"`
+			NextLink string    `json:"@odata.nextLink"`
+		}
+		if err := c.get(ctx, next, &page); err != nil {
+			return nil, err
+		}
+		messages = append(messages, page.Value...)
+		next = page.NextLink
+	}
+	return messages, nil
+}
+
+// GetMessage returns one message by its Graph ID.
+func (c *Client) GetMessage(ctx context.Context, messageID string) (Message, error) {
+	var message Message
+	if err := c.get(ctx, c.endpoint("/me/messages/"+url.PathEscape(messageID), url.Values{"$select": {messageFields}}), &message); err != nil {
+		return Message{}, err
+	}
+	return message, nil
+}
+
+func (c *Client) listFolders(ctx context.Context, path string) ([]MailFolder, error) {
+	next := c.endpoint(path, url.Values{
+		"$select": {"id,displayName"},
+		"$top":    {fmt.Sprint(folderPageSize)},
+	})
+
+	folders := make([]MailFolder, 0)
+	for next != "" {
+		var page struct {
+			Value    []MailFolder `json:"value"`
+			NextLink string       `json:"@odata.nextLink"`
+		}
+		if err := c.get(ctx, next, &page); err != nil {
+			return nil, err
+		}
+		folders = append(folders, page.Value...)
+		next = page.NextLink
+	}
+	return folders, nil
+}
+
+func (c *Client) endpoint(path string, query url.Values) string {
+	// OData expects %20 rather than + between the words of $filter.
+	return c.baseURL + path + "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func (c *Client) get(ctx context.Context, requestURL string, out any) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if c.httpClient == nil {
+		return fmt.Errorf("graph http client is not configured")
+	}
+
+	reqLog := c.log
+	if withContext, err := c.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+
+	err := retry.DoWithCondition(ctx, c.backoff, shouldRetryGraphError, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		// Immutable IDs keep a message's ID when it is moved to another folder, so stored
+		// external message IDs stay valid.
+		req.Header.Set("Prefer", `IdType="ImmutableId"`)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return decodeAPIError(resp)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode graph response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		reqLog.Error("external_api_failed",
+			logger.String("provider", "outlook"),
+			logger.String("operation", "graph_get"),
+			logger.Err(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&body); err == nil {
+		apiErr.Code = body.Error.Code
+		apiErr.Message = body.Error.Message
+	}
+	return apiErr
+}
+
+// shouldRetryGraphError retries throttling (429) and server errors (5xx) only.
+func shouldRetryGraphError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return false
+}
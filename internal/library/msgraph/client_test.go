@@ -0,0 +1,152 @@
+package msgraph_test
+
+import (
+	"business/internal/library/msgraph"
+	"business/internal/library/msgraph/graphtest"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func newTestGraph(t *testing.T, messages []msgraph.Message) *graphtest.Server {
+	t.Helper()
+
+	server := graphtest.NewServer(
+		msgraph.User{Mail: "Billing@Contoso.com", UserPrincipalName: "billing@contoso.onmicrosoft.com"},
+		"access-0",
+		"refresh-0",
+		[]graphtest.Folder{
+			{
+				ID:            "inbox-id",
+				DisplayName:   "受信トレイ",
+				WellKnownName: "inbox",
+				Children: []graphtest.Folder{
+					{ID: "invoices-id", DisplayName: "Invoices", Messages: messages},
+				},
+			},
+			{ID: "archive-id", DisplayName: "Archive", WellKnownName: "archive"},
+		},
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(server *graphtest.Server, backoff []time.Duration) *msgraph.Client {
+	httpClient := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "access-0"}))
+	return msgraph.NewClientFactory(server.BaseURL(), backoff, nil).NewClient(httpClient)
+}
+
+func TestClient_GetMe(t *testing.T) {
+	t.Parallel()
+	server := newTestGraph(t, nil)
+
+	user, err := newTestClient(server, nil).GetMe(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "Billing@Contoso.com", user.Mail)
+}
+
+func TestClient_FindMailFolder(t *testing.T) {
+	t.Parallel()
+	server := newTestGraph(t, nil)
+	client := newTestClient(server, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "display name is case-insensitive", path: "archive", expected: "archive-id"},
+		{name: "well-known name for a localized folder", path: "Inbox", expected: "inbox-id"},
+		{name: "nested path under a well-known folder", path: "inbox/invoices", expected: "invoices-id"},
+		{name: "nested path by display name", path: "受信トレイ/Invoices", expected: "invoices-id"},
+	}
+	for _, tt := range tests {
+		folder, err := client.FindMailFolder(ctx, tt.path)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expected, folder.ID, tt.name)
+	}
+
+	_, err := client.FindMailFolder(ctx, "Inbox/Missing")
+	assert.ErrorIs(t, err, msgraph.ErrMailFolderNotFound)
+
+	_, err = client.FindMailFolder(ctx, "")
+	assert.ErrorIs(t, err, msgraph.ErrMailFolderNotFound)
+}
+
+func TestClient_ListMessages_FollowsNextLink(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	messages := make([]msgraph.Message, 0, 62)
+	for i := range 62 {
+		messages = append(messages, msgraph.Message{
+			ID:               fmt.Sprintf("m-%02d", i),
+			Subject:          fmt.Sprintf("invoice %d", i),
+			ReceivedDateTime: since.Add(time.Duration(i-1) * time.Hour),
+		})
+	}
+	server := newTestGraph(t, messages)
+
+	listed, err := newTestClient(server, nil).ListMessages(context.Background(), "invoices-id", since, since.Add(60*time.Hour))
+
+	require.NoError(t, err)
+	require.Len(t, listed, 60)
+	assert.Equal(t, "m-01", listed[0].ID)
+	assert.Equal(t, "m-60", listed[59].ID)
+	assert.Contains(t, server.Requests(),
+		"/v1.0/me/mailFolders/invoices-id/messages?$filter=receivedDateTime ge 2026-10-01T00:00:00Z and receivedDateTime lt 2026-10-03T12:00:00Z"+
+			"&$orderby=receivedDateTime&$select=id,internetMessageId,subject,from,toRecipients,receivedDateTime,body&$top=50")
+}
+
+func TestClient_GetMessage(t *testing.T) {
+	t.Parallel()
+	server := newTestGraph(t, []msgraph.Message{{ID: "AAMk=/1", Subject: "invoice"}})
+	client := newTestClient(server, nil)
+
+	message, err := client.GetMessage(context.Background(), "AAMk=/1")
+	require.NoError(t, err)
+	assert.Equal(t, "invoice", message.Subject)
+
+	_, err = client.GetMessage(context.Background(), "missing")
+	var apiErr *msgraph.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "ErrorItemNotFound", apiErr.Code)
+}
+
+func TestClient_RetriesThrottlingOnly(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries 429 and 5xx", func(t *testing.T) {
+		t.Parallel()
+		server := newTestGraph(t, nil)
+		server.FailNext(http.StatusTooManyRequests, http.StatusServiceUnavailable)
+
+		user, err := newTestClient(server, []time.Duration{time.Millisecond, time.Millisecond}).GetMe(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "Billing@Contoso.com", user.Mail)
+		assert.Len(t, server.Requests(), 3)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		t.Parallel()
+		server := newTestGraph(t, nil)
+		server.FailNext(http.StatusForbidden)
+
+		_, err := newTestClient(server, []time.Duration{time.Millisecond}).GetMe(context.Background())
+
+		var apiErr *msgraph.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+		assert.Len(t, server.Requests(), 1)
+	})
+}
@@ -0,0 +1,321 @@
+// Package graphtest provides an httptest stand-in for the parts of Microsoft Graph and the
+// Microsoft identity platform token endpoint that the Outlook provider uses.
+package graphtest
+
+import (
+	"business/internal/library/msgraph"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Folder is a mail folder with its messages and child folders.
+type Folder struct {
+	ID            string
+	DisplayName   string
+	WellKnownName string
+	Children      []Folder
+	Messages      []msgraph.Message
+}
+
+// Server serves Graph under BaseURL and the OAuth token endpoint under TokenURL.
+type Server struct {
+	srv *httptest.Server
+
+	mu           sync.Mutex
+	user         msgraph.User
+	folders      []Folder
+	accessToken  string
+	refreshToken string
+	issued       int
+	requests     []string
+	failures     []int
+}
+
+var receivedFilter = regexp.MustCompile(`receivedDateTime (ge|lt) (\S+)`)
+
+// NewServer starts a server that accepts accessToken and refreshes it with refreshToken.
+// The authorization code "valid-code" is exchanged for the same pair.
+func NewServer(user msgraph.User, accessToken, refreshToken string, folders []Folder) *Server {
+	s := &Server{
+		user:         user,
+		folders:      folders,
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", s.handleToken)
+	mux.HandleFunc("/v1.0/", s.handleGraph)
+	s.srv = httptest.NewServer(mux)
+	return s
+}
+
+// BaseURL is the Graph endpoint to pass to msgraph.NewClientFactory.
+func (s *Server) BaseURL() string {
+	return s.srv.URL + "/v1.0"
+}
+
+// TokenURL is the OAuth token endpoint to put in oauth2.Config.Endpoint.
+func (s *Server) TokenURL() string {
+	return s.srv.URL + "/token"
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// AccessToken returns the access token the server currently accepts.
+func (s *Server) AccessToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accessToken
+}
+
+// RefreshToken returns the refresh token the server currently accepts.
+func (s *Server) RefreshToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshToken
+}
+
+// Requests returns the Graph request paths with their decoded query, in the order received.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// FailNext answers the next Graph requests with the given status codes, one per request.
+func (s *Server) FailNext(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		if r.PostForm.Get("code") != "valid-code" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+	case "refresh_token":
+		if r.PostForm.Get("refresh_token") != s.refreshToken {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		// Microsoft rotates the refresh token on every refresh.
+		s.issued++
+		s.accessToken = fmt.Sprintf("access-%d", s.issued)
+		s.refreshToken = fmt.Sprintf("refresh-%d", s.issued)
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"token_type":    "Bearer",
+		"access_token":  s.accessToken,
+		"refresh_token": s.refreshToken,
+		"expires_in":    3600,
+	})
+}
+
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.URL.Path+queryString(r))
+
+	if r.Header.Get("Authorization") != "Bearer "+s.accessToken {
+		writeGraphError(w, http.StatusUnauthorized, "InvalidAuthenticationToken", "Access token is invalid.")
+		return
+	}
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		writeGraphError(w, status, "ServiceUnavailable", "Injected failure.")
+		return
+	}
+
+	// Graph IDs may contain "/", so split the escaped path and unescape each segment.
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.EscapedPath(), "/v1.0/"), "/"), "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segments[i] = unescaped
+		}
+	}
+	switch {
+	case len(segments) == 1 && segments[0] == "me":
+		writeJSON(w, http.StatusOK, s.user)
+	case len(segments) == 2 && segments[1] == "mailFolders":
+		s.writeFolderPage(w, r, s.folders)
+	case len(segments) == 3 && segments[1] == "mailFolders":
+		folder, ok := s.findFolder(segments[2])
+		if !ok {
+			writeGraphError(w, http.StatusNotFound, "ErrorItemNotFound", "The specified object was not found in the store.")
+			return
+		}
+		writeJSON(w, http.StatusOK, msgraph.MailFolder{ID: folder.ID, DisplayName: folder.DisplayName})
+	case len(segments) == 4 && segments[1] == "mailFolders" && segments[3] == "childFolders":
+		folder, ok := s.findFolder(segments[2])
+		if !ok {
+			writeGraphError(w, http.StatusNotFound, "ErrorItemNotFound", "The specified object was not found in the store.")
+			return
+		}
+		s.writeFolderPage(w, r, folder.Children)
+	case len(segments) == 4 && segments[1] == "mailFolders" && segments[3] == "messages":
+		folder, ok := s.findFolder(segments[2])
+		if !ok {
+			writeGraphError(w, http.StatusNotFound, "ErrorItemNotFound", "The specified object was not found in the store.")
+			return
+		}
+		s.writeMessagePage(w, r, folder.Messages)
+	case len(segments) == 3 && segments[1] == "messages":
+		message, ok := s.findMessage(segments[2])
+		if !ok {
+			writeGraphError(w, http.StatusNotFound, "ErrorItemNotFound", "The specified object was not found in the store.")
+			return
+		}
+		writeJSON(w, http.StatusOK, message)
+	default:
+		writeGraphError(w, http.StatusNotFound, "ResourceNotFound", "Unsupported path.")
+	}
+}
+
+func (s *Server) writeFolderPage(w http.ResponseWriter, r *http.Request, folders []Folder) {
+	values := make([]msgraph.MailFolder, 0, len(folders))
+	for _, folder := range folders {
+		values = append(values, msgraph.MailFolder{ID: folder.ID, DisplayName: folder.DisplayName})
+	}
+	writePage(w, r, values)
+}
+
+func (s *Server) writeMessagePage(w http.ResponseWriter, r *http.Request, messages []msgraph.Message) {
+	var since, until time.Time
+	for _, match := range receivedFilter.FindAllStringSubmatch(r.URL.Query().Get("$filter"), -1) {
+		value, err := time.Parse(time.RFC3339, match[2])
+		if err != nil {
+			writeGraphError(w, http.StatusBadRequest, "BadRequest", "Invalid filter.")
+			return
+		}
+		if match[1] == "ge" {
+			since = value
+		} else {
+			until = value
+		}
+	}
+
+	filtered := make([]msgraph.Message, 0, len(messages))
+	for _, message := range messages {
+		if !since.IsZero() && message.ReceivedDateTime.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !message.ReceivedDateTime.Before(until) {
+			continue
+		}
+		filtered = append(filtered, message)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].ReceivedDateTime.Before(filtered[j].ReceivedDateTime)
+	})
+	writePage(w, r, filtered)
+}
+
+func (s *Server) findFolder(idOrWellKnownName string) (Folder, bool) {
+	var walk func(folders []Folder) (Folder, bool)
+	walk = func(folders []Folder) (Folder, bool) {
+		for _, folder := range folders {
+			if folder.ID == idOrWellKnownName || (folder.WellKnownName != "" && folder.WellKnownName == idOrWellKnownName) {
+				return folder, true
+			}
+			if found, ok := walk(folder.Children); ok {
+				return found, true
+			}
+		}
+		return Folder{}, false
+	}
+	return walk(s.folders)
+}
+
+func (s *Server) findMessage(id string) (msgraph.Message, bool) {
+	var walk func(folders []Folder) (msgraph.Message, bool)
+	walk = func(folders []Folder) (msgraph.Message, bool) {
+		for _, folder := range folders {
+			for _, message := range folder.Messages {
+				if message.ID == id {
+					return message, true
+				}
+			}
+			if found, ok := walk(folder.Children); ok {
+				return found, true
+			}
+		}
+		return msgraph.Message{}, false
+	}
+	return walk(s.folders)
+}
+
+func writePage[T any](w http.ResponseWriter, r *http.Request, values []T) {
+	query := r.URL.Query()
+	top, err := strconv.Atoi(query.Get("$top"))
+	if err != nil || top <= 0 {
+		top = len(values)
+	}
+	skip, _ := strconv.Atoi(query.Get("$skip"))
+	if skip > len(values) {
+		skip = len(values)
+	}
+	end := min(skip+top, len(values))
+
+	body := map[string]any{"value": values[skip:end]}
+	if end < len(values) {
+		query.Set("$skip", strconv.Itoa(end))
+		body["@odata.nextLink"] = "http://" + r.Host + r.URL.Path + "?" + query.Encode()
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func queryString(r *http.Request) string {
+	query := r.URL.Query()
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+query.Get(key))
+	}
+	return "?" + strings.Join(parts, "&")
+}
+
+func writeGraphError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]string{"code": code, "message": message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
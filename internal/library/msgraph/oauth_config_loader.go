@@ -0,0 +1,74 @@
+package msgraph
+
+import (
+	"business/internal/library/oswrapper"
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+)
+
+const (
+	// ScopeMailRead lets the app read the signed-in user's mail.
+	ScopeMailRead = "https://graph.microsoft.com/Mail.Read"
+	// ScopeUserRead lets the app read the signed-in user's profile for the mail address.
+	ScopeUserRead = "https://graph.microsoft.com/User.Read"
+	// ScopeOfflineAccess makes the token endpoint return a refresh token.
+	ScopeOfflineAccess = "offline_access"
+
+	defaultTenant = "common"
+)
+
+// OAuthConfigLoader loads the Microsoft identity platform OAuth configuration from environment settings.
+type OAuthConfigLoader struct {
+	osw oswrapper.OsWapperInterface
+}
+
+// NewOAuthConfigLoader creates a new OAuthConfigLoader.
+func NewOAuthConfigLoader(osw oswrapper.OsWapperInterface) *OAuthConfigLoader {
+	return &OAuthConfigLoader{osw: osw}
+}
+
+// GetOutlookOAuthConfig resolves the Outlook OAuth configuration.
+// EMAIL_OUTLOOK_TENANT is optional and defaults to "common", which accepts both
+// Microsoft 365 work accounts and personal Outlook.com accounts.
+func (l *OAuthConfigLoader) GetOutlookOAuthConfig(ctx context.Context) (*oauth2.Config, error) {
+	redirectURLRaw, err := l.osw.GetEnv("EMAIL_OUTLOOK_REDIRECT_URL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EMAIL_OUTLOOK_REDIRECT_URL: %w", err)
+	}
+	redirectURL := strings.TrimSpace(redirectURLRaw)
+	if redirectURL == "" {
+		return nil, fmt.Errorf("EMAIL_OUTLOOK_REDIRECT_URL environment variable is required")
+	}
+
+	clientID, err := l.osw.GetEnv("EMAIL_OUTLOOK_CLIENT_ID")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EMAIL_OUTLOOK_CLIENT_ID: %w", err)
+	}
+	clientSecret, err := l.osw.GetEnv("EMAIL_OUTLOOK_CLIENT_SECRET")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EMAIL_OUTLOOK_CLIENT_SECRET: %w", err)
+	}
+
+	id := strings.TrimSpace(clientID)
+	secret := strings.TrimSpace(clientSecret)
+	if id == "" || secret == "" {
+		return nil, fmt.Errorf("EMAIL_OUTLOOK_CLIENT_ID or EMAIL_OUTLOOK_CLIENT_SECRET is empty")
+	}
+
+	tenant := defaultTenant
+	if value, err := l.osw.GetEnv("EMAIL_OUTLOOK_TENANT"); err == nil && strings.TrimSpace(value) != "" {
+		tenant = strings.TrimSpace(value)
+	}
+
+	return &oauth2.Config{
+		ClientID:     id,
+		ClientSecret: secret,
+		Endpoint:     microsoft.AzureADEndpoint(tenant),
+		Scopes:       []string{ScopeOfflineAccess, ScopeUserRead, ScopeMailRead},
+		RedirectURL:  redirectURL,
+	}, nil
+}
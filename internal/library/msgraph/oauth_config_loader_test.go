@@ -0,0 +1,56 @@
+package msgraph
+
+import (
+	"context"
+	"testing"
+
+	mocklibrary "business/test/mock/library"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthConfigLoader_DefaultsToCommonTenant(t *testing.T) {
+	t.Parallel()
+
+	osw := mocklibrary.NewOsWrapperMock(map[string]string{
+		"EMAIL_OUTLOOK_CLIENT_ID":     "test-client-id",
+		"EMAIL_OUTLOOK_CLIENT_SECRET": "test-client-secret",
+		"EMAIL_OUTLOOK_REDIRECT_URL":  "http://localhost:8080/callback",
+	})
+
+	cfg, err := NewOAuthConfigLoader(osw).GetOutlookOAuthConfig(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "test-client-id", cfg.ClientID)
+	require.Equal(t, "test-client-secret", cfg.ClientSecret)
+	require.Equal(t, "http://localhost:8080/callback", cfg.RedirectURL)
+	require.Equal(t, "https://login.microsoftonline.com/common/oauth2/v2.0/token", cfg.Endpoint.TokenURL)
+	require.Equal(t, []string{ScopeOfflineAccess, ScopeUserRead, ScopeMailRead}, cfg.Scopes)
+}
+
+func TestOAuthConfigLoader_UsesConfiguredTenant(t *testing.T) {
+	t.Parallel()
+
+	osw := mocklibrary.NewOsWrapperMock(map[string]string{
+		"EMAIL_OUTLOOK_CLIENT_ID":     "test-client-id",
+		"EMAIL_OUTLOOK_CLIENT_SECRET": "test-client-secret",
+		"EMAIL_OUTLOOK_REDIRECT_URL":  "http://localhost:8080/callback",
+		"EMAIL_OUTLOOK_TENANT":        "organizations",
+	})
+
+	cfg, err := NewOAuthConfigLoader(osw).GetOutlookOAuthConfig(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "https://login.microsoftonline.com/organizations/oauth2/v2.0/authorize", cfg.Endpoint.AuthURL)
+}
+
+func TestOAuthConfigLoader_MissingClientSecretReturnsError(t *testing.T) {
+	t.Parallel()
+
+	osw := mocklibrary.NewOsWrapperMock(map[string]string{
+		"EMAIL_OUTLOOK_CLIENT_ID":    "test-client-id",
+		"EMAIL_OUTLOOK_REDIRECT_URL": "http://localhost:8080/callback",
+	})
+
+	_, err := NewOAuthConfigLoader(osw).GetOutlookOAuthConfig(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "EMAIL_OUTLOOK_CLIENT_SECRET")
+}
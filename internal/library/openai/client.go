@@ -25,9 +25,20 @@ const (
 	parsedEmailResponseSchemaName = "parsed_email_analysis_results"
 )
 
+// PromptAttachment is the text of one email attachment given to the extraction prompt.
+type PromptAttachment struct {
+	Filename string
+	Text     string
+	// Truncated tells the model that the text was cut at the size limit.
+	Truncated bool
+	// FailureReason is set instead of Text when the attachment could not be read.
+	FailureReason string
+}
+
 // BuildParsedEmailPrompt builds the extraction prompt for one email body.
 // The output contract is a billing header with nested line-items.
-func BuildParsedEmailPrompt(subject, from string, receivedAt time.Time, body string) string {
+// Attachments are appended as a separate section after the body; without them the prompt is unchanged.
+func BuildParsedEmailPrompt(subject, from string, receivedAt time.Time, body string, attachments ...PromptAttachment) string {
 	return fmt.Sprintf(`あなたはメール本文から請求関連の構造化情報を抽出するアシスタントです。
 
 出力規約:
@@ -51,7 +62,29 @@ from: %s
 receivedAt: %s
 body:
 %s
-`, strings.TrimSpace(subject), strings.TrimSpace(from), receivedAt.UTC().Format(time.RFC3339), strings.TrimSpace(body))
+%s`, strings.TrimSpace(subject), strings.TrimSpace(from), receivedAt.UTC().Format(time.RFC3339), strings.TrimSpace(body), buildAttachmentSection(attachments))
+}
+
+// buildAttachmentSection renders the attachments section, or nothing when the email has no attachment.
+func buildAttachmentSection(attachments []PromptAttachment) string {
+	if len(attachments) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("attachments（添付ファイルから抽出したテキストです。本文と同じメールの内容として扱ってください）:\n")
+	for _, attachment := range attachments {
+		filename := strings.TrimSpace(attachment.Filename)
+		if attachment.FailureReason != "" {
+			fmt.Fprintf(&b, "--- %s (読み取れませんでした: %s) ---\n", filename, attachment.FailureReason)
+			continue
+		}
+		fmt.Fprintf(&b, "--- %s ---\n%s\n", filename, strings.TrimSpace(attachment.Text))
+		if attachment.Truncated {
+			b.WriteString("（以降は長さの上限のため省略されています）\n")
+		}
+	}
+	return b.String()
 }
 
 type Client struct {
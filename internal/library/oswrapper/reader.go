@@ -17,15 +17,17 @@ type OsWrapper struct {
 }
 
 var appSecretEnvKeys = map[string]struct{}{
-	"JWT_SECRET_KEY":            {},
-	"OPENAI_API_KEY":            {},
-	"EMAIL_TOKEN_KEY_V1":        {},
-	"EMAIL_TOKEN_SALT":          {},
-	"REDIS_PASSWORD":            {},
-	"MYSQL_USER":                {},
-	"MYSQL_PASSWORD":            {},
-	"EMAIL_GMAIL_CLIENT_ID":     {},
-	"EMAIL_GMAIL_CLIENT_SECRET": {},
+	"JWT_SECRET_KEY":              {},
+	"OPENAI_API_KEY":              {},
+	"EMAIL_TOKEN_KEY_V1":          {},
+	"EMAIL_TOKEN_SALT":            {},
+	"REDIS_PASSWORD":              {},
+	"MYSQL_USER":                  {},
+	"MYSQL_PASSWORD":              {},
+	"EMAIL_GMAIL_CLIENT_ID":       {},
+	"EMAIL_GMAIL_CLIENT_SECRET":   {},
+	"EMAIL_OUTLOOK_CLIENT_ID":     {},
+	"EMAIL_OUTLOOK_CLIENT_SECRET": {},
 }
 
 // New は OsWrapper のインスタンスを返します
@@ -0,0 +1,358 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var (
+	objectHeader  = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	streamKeyword = []byte("stream")
+	endstream     = []byte("endstream")
+
+	errDecodeBudgetExceeded = errors.New("decoded stream data exceeds the document budget")
+)
+
+// maxDecodedBytes bounds the inflated data of all streams in one document together, a small multiple of
+// the largest attachment that is downloaded (10 MB). Without it a few kilobytes of deflated zeros expand
+// to gigabytes and exhaust the worker's memory, which recover cannot catch.
+const maxDecodedBytes = 64 << 20
+
+// document holds every object of a PDF file by object number. Objects are found by scanning the
+// file rather than reading the cross-reference table, which also copes with broken xref offsets.
+type document struct {
+	data      []byte
+	objects   map[int]any
+	encrypted bool
+	// decodeBudget is the number of inflated bytes the remaining streams may still produce.
+	decodeBudget int64
+	// overBudget is set once a stream exceeded decodeBudget; the document is then treated as invalid.
+	overBudget bool
+}
+
+func loadDocument(data []byte, decodeBudget int64) *document {
+	doc := &document{data: data, objects: make(map[int]any), decodeBudget: decodeBudget}
+
+	var objectStreams []stream
+	pos := 0
+	for pos < len(data) {
+		loc := objectHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		p := &parser{data: data, pos: pos + loc[1]}
+		value, err := p.next()
+		if err != nil {
+			pos += loc[1]
+			continue
+		}
+		if d, ok := value.(dict); ok {
+			if s, end, ok := readStream(data, p.pos, d); ok {
+				value = s
+				p.pos = end
+				if d["Type"] == name("ObjStm") {
+					objectStreams = append(objectStreams, s)
+				}
+			}
+			if _, ok := d["Encrypt"]; ok && d["Type"] == name("XRef") {
+				doc.encrypted = true
+			}
+		}
+		doc.objects[num] = value
+		pos = p.pos
+	}
+
+	for _, s := range objectStreams {
+		doc.loadObjectStream(s)
+	}
+	if trailer := doc.trailer(); trailer != nil {
+		if _, ok := trailer["Encrypt"]; ok {
+			doc.encrypted = true
+		}
+	}
+	return doc
+}
+
+// readStream returns the stream data that follows a dictionary at pos, and the position after endstream.
+func readStream(data []byte, pos int, d dict) (stream, int, bool) {
+	p := &parser{data: data, pos: pos}
+	p.skipSpace()
+	if !bytes.HasPrefix(data[p.pos:], streamKeyword) {
+		return stream{}, 0, false
+	}
+	start := p.pos + len(streamKeyword)
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+
+	if length, ok := d["Length"].(float64); ok && length >= 0 && start+int(length) <= len(data) {
+		end := start + int(length)
+		rest := &parser{data: data, pos: end}
+		rest.skipSpace()
+		if bytes.HasPrefix(data[rest.pos:], endstream) {
+			return stream{dict: d, raw: data[start:end]}, rest.pos + len(endstream), true
+		}
+	}
+
+	idx := bytes.Index(data[start:], endstream)
+	if idx < 0 {
+		return stream{}, 0, false
+	}
+	raw := data[start : start+idx]
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	raw = bytes.TrimSuffix(raw, []byte("\r"))
+	return stream{dict: d, raw: raw}, start + idx + len(endstream), true
+}
+
+// loadObjectStream adds the objects compressed into an /ObjStm stream (PDF 1.5+).
+// Objects already found in the file body take precedence.
+func (doc *document) loadObjectStream(s stream) {
+	data, err := doc.decode(s)
+	if err != nil {
+		return
+	}
+	count, _ := doc.resolve(s.dict["N"]).(float64)
+	first, _ := doc.resolve(s.dict["First"]).(float64)
+	if int(first) > len(data) {
+		return
+	}
+
+	header := &parser{data: data[:int(first)]}
+	for i := 0; i < int(count); i++ {
+		num, err1 := header.next()
+		offset, err2 := header.next()
+		if err1 != nil || err2 != nil {
+			return
+		}
+		n, ok1 := num.(float64)
+		off, ok2 := offset.(float64)
+		if !ok1 || !ok2 || int(first)+int(off) >= len(data) {
+			continue
+		}
+		if _, exists := doc.objects[int(n)]; exists {
+			continue
+		}
+		value, err := (&parser{data: data, pos: int(first) + int(off)}).next()
+		if err != nil {
+			continue
+		}
+		doc.objects[int(n)] = value
+	}
+}
+
+// trailer returns the last trailer dictionary, or the last cross-reference stream dictionary.
+func (doc *document) trailer() dict {
+	if idx := bytes.LastIndex(doc.data, []byte("trailer")); idx >= 0 {
+		if value, err := (&parser{data: doc.data, pos: idx + len("trailer")}).next(); err == nil {
+			if d, ok := value.(dict); ok {
+				return d
+			}
+		}
+	}
+
+	var (
+		last dict
+		max  = -1
+	)
+	for num, value := range doc.objects {
+		if s, ok := value.(stream); ok && s.dict["Type"] == name("XRef") && num > max {
+			last, max = s.dict, num
+		}
+	}
+	return last
+}
+
+// resolve follows indirect references.
+func (doc *document) resolve(value any) any {
+	for i := 0; i < 32; i++ {
+		r, ok := value.(ref)
+		if !ok {
+			return value
+		}
+		value = doc.objects[r.num]
+	}
+	return nil
+}
+
+func (doc *document) dict(value any) dict {
+	switch v := doc.resolve(value).(type) {
+	case dict:
+		return v
+	case stream:
+		return v.dict
+	}
+	return nil
+}
+
+// decode applies the stream filters. Only the filters used for text content are supported.
+func (doc *document) decode(s stream) ([]byte, error) {
+	var filters []name
+	switch f := doc.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = []name{f}
+	case array:
+		for _, item := range f {
+			if n, ok := doc.resolve(item).(name); ok {
+				filters = append(filters, n)
+			}
+		}
+	}
+
+	data := s.raw
+	for _, filter := range filters {
+		var err error
+		switch filter {
+		case "FlateDecode", "Fl":
+			data, err = doc.inflate(data)
+		case "ASCIIHexDecode", "AHx":
+			data = (&parser{data: append([]byte{'<'}, data...)}).readHexString()
+		case "ASCII85Decode", "A85":
+			data, err = decodeASCII85(data)
+		default:
+			err = fmt.Errorf("unsupported stream filter %s", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflate decompresses zlib data within the document's decode budget. Truncated streams are common
+// in real files, so whatever was decompressed before an error is kept.
+func (doc *document) inflate(data []byte) ([]byte, error) {
+	if doc.overBudget {
+		return nil, errDecodeBudgetExceeded
+	}
+
+	var reader io.ReadCloser
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		reader = zr
+	} else {
+		reader = flate.NewReader(bytes.NewReader(data))
+	}
+	defer reader.Close()
+
+	out, err := io.ReadAll(io.LimitReader(reader, doc.decodeBudget+1))
+	if int64(len(out)) > doc.decodeBudget {
+		doc.decodeBudget = 0
+		doc.overBudget = true
+		return nil, errDecodeBudgetExceeded
+	}
+	doc.decodeBudget -= int64(len(out))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if idx := bytes.Index(data, []byte("~>")); idx >= 0 {
+		data = data[:idx]
+	}
+	out := make([]byte, len(data)*4/5+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return out[:n], nil
+}
+
+// page is a page dictionary with the resources it inherits from the page tree.
+type page struct {
+	dict      dict
+	resources dict
+}
+
+// pages returns the pages in reading order. When the page tree cannot be read, every page
+// object is returned in object-number order.
+func (doc *document) pages() []page {
+	var pages []page
+	if trailer := doc.trailer(); trailer != nil {
+		if catalog := doc.dict(trailer["Root"]); catalog != nil {
+			doc.collectPages(catalog["Pages"], nil, make(map[int]bool), &pages)
+		}
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	nums := make([]int, 0, len(doc.objects))
+	for num := range doc.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		if d, ok := doc.objects[num].(dict); ok && d["Type"] == name("Page") {
+			pages = append(pages, page{dict: d, resources: doc.dict(d["Resources"])})
+		}
+	}
+	return pages
+}
+
+func (doc *document) collectPages(node any, inherited dict, seen map[int]bool, pages *[]page) {
+	if r, ok := node.(ref); ok {
+		if seen[r.num] {
+			return
+		}
+		seen[r.num] = true
+	}
+	d := doc.dict(node)
+	if d == nil {
+		return
+	}
+
+	resources := inherited
+	if own := doc.dict(d["Resources"]); own != nil {
+		resources = own
+	}
+	if kids, ok := doc.resolve(d["Kids"]).(array); ok {
+		for _, kid := range kids {
+			doc.collectPages(kid, resources, seen, pages)
+		}
+		return
+	}
+	if d["Type"] == name("Page") || d["Contents"] != nil {
+		*pages = append(*pages, page{dict: d, resources: resources})
+	}
+}
+
+// contents returns the decoded content streams of a page joined together.
+func (doc *document) contents(p page) []byte {
+	var refs []any
+	switch c := doc.resolve(p.dict["Contents"]).(type) {
+	case array:
+		refs = c
+	case stream:
+		refs = []any{c}
+	}
+
+	var out []byte
+	for _, item := range refs {
+		s, ok := doc.resolve(item).(stream)
+		if !ok {
+			continue
+		}
+		data, err := doc.decode(s)
+		if err != nil {
+			continue
+		}
+		out = append(out, data...)
+		out = append(out, '\n')
+	}
+	return out
+}
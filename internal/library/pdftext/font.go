@@ -0,0 +1,204 @@
+package pdftext
+
+import (
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxRangeSize bounds one bfrange entry so that a corrupt CMap cannot allocate without limit.
+const maxRangeSize = 1 << 16
+
+type codeRange struct {
+	lo, hi uint32
+	size   int
+}
+
+// font turns the bytes of a text-showing operator into text.
+type font struct {
+	ranges    []codeRange
+	toUnicode map[uint32]string
+	// composite fonts (Type0) have multi-byte codes that mean nothing without a ToUnicode CMap.
+	composite bool
+}
+
+func (doc *document) loadFont(d dict) *font {
+	f := &font{composite: d["Subtype"] == name("Type0")}
+	if s, ok := doc.resolve(d["ToUnicode"]).(stream); ok {
+		if data, err := doc.decode(s); err == nil {
+			f.toUnicode, f.ranges = parseCMap(data)
+		}
+	}
+	if len(f.ranges) == 0 {
+		size := 1
+		if f.composite {
+			size = 2
+		}
+		f.ranges = []codeRange{{lo: 0, hi: 1<<(8*size) - 1, size: size}}
+	}
+	return f
+}
+
+func (f *font) decode(b []byte) string {
+	if f == nil {
+		return decodeWinAnsi(b)
+	}
+	if f.toUnicode == nil {
+		if f.composite {
+			return ""
+		}
+		return decodeWinAnsi(b)
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(b); {
+		size := f.codeSize(b[i:])
+		code := codeOf(b[i : i+size])
+		if text, ok := f.toUnicode[code]; ok {
+			out.WriteString(text)
+		} else if !f.composite {
+			out.WriteString(decodeWinAnsi(b[i : i+size]))
+		}
+		i += size
+	}
+	return out.String()
+}
+
+// codeSize picks the code length from the CMap codespace ranges.
+func (f *font) codeSize(b []byte) int {
+	for _, r := range f.ranges {
+		if r.size > len(b) {
+			continue
+		}
+		if code := codeOf(b[:r.size]); code >= r.lo && code <= r.hi {
+			return r.size
+		}
+	}
+	if size := f.ranges[0].size; size <= len(b) {
+		return size
+	}
+	return len(b)
+}
+
+func codeOf(b []byte) uint32 {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code
+}
+
+// parseCMap reads the codespace ranges and the bfchar / bfrange mappings of a ToUnicode CMap.
+func parseCMap(data []byte) (map[uint32]string, []codeRange) {
+	var (
+		mapping  = make(map[uint32]string)
+		ranges   []codeRange
+		operands []any
+		p        = &parser{data: data}
+	)
+	for {
+		value, err := p.next()
+		if err == io.EOF || err != nil {
+			break
+		}
+		kw, ok := value.(keyword)
+		if !ok {
+			operands = append(operands, value)
+			continue
+		}
+
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 && len(lo) > 0 && len(lo) <= 4 {
+					ranges = append(ranges, codeRange{lo: codeOf(lo), hi: codeOf(hi), size: len(lo)})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					mapping[codeOf(src)] = decodeUTF16(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				addRange(mapping, codeOf(lo), codeOf(hi), operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+	return mapping, ranges
+}
+
+func addRange(mapping map[uint32]string, lo, hi uint32, dst any) {
+	if hi < lo || hi-lo >= maxRangeSize {
+		return
+	}
+	switch d := dst.(type) {
+	case []byte:
+		units := toUTF16Units(d)
+		if len(units) == 0 {
+			return
+		}
+		for code := lo; code <= hi; code++ {
+			shifted := append([]uint16(nil), units...)
+			shifted[len(shifted)-1] += uint16(code - lo)
+			mapping[code] = string(utf16.Decode(shifted))
+		}
+	case array:
+		for i, item := range d {
+			if b, ok := item.([]byte); ok && lo+uint32(i) <= hi {
+				mapping[lo+uint32(i)] = decodeUTF16(b)
+			}
+		}
+	}
+}
+
+func toUTF16Units(b []byte) []uint16 {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return units
+}
+
+func decodeUTF16(b []byte) string {
+	if len(b)%2 != 0 {
+		return decodeWinAnsi(b)
+	}
+	return string(utf16.Decode(toUTF16Units(b)))
+}
+
+// winAnsiHigh maps the 0x80-0x9F range of WinAnsiEncoding, where it differs from Latin-1.
+var winAnsiHigh = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ',
+	0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“',
+	0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›',
+	0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// decodeWinAnsi decodes the bytes of a simple font with the standard Windows encoding,
+// which is what most invoice generators use for Latin text.
+func decodeWinAnsi(b []byte) string {
+	var out strings.Builder
+	for _, c := range b {
+		if r, ok := winAnsiHigh[c]; ok {
+			out.WriteRune(r)
+			continue
+		}
+		if c < 0x20 && c != '\t' {
+			continue
+		}
+		out.WriteRune(rune(c))
+	}
+	return out.String()
+}
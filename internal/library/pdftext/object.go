@@ -0,0 +1,320 @@
+package pdftext
+
+import (
+	"io"
+	"strconv"
+)
+
+// name is a PDF name object such as /Type, stored without the leading slash.
+type name string
+
+// keyword is a bare word in PDF data: a content-stream operator, obj / stream markers or a closing delimiter.
+type keyword string
+
+// ref is an indirect reference such as "12 0 R".
+type ref struct {
+	num int
+	gen int
+}
+
+type (
+	dict  map[name]any
+	array []any
+)
+
+// stream is a stream object with its still-encoded data.
+type stream struct {
+	dict dict
+	raw  []byte
+}
+
+// parser reads PDF objects from a byte slice. It is lenient: malformed input yields keywords or
+// zero values instead of errors so that a broken object does not stop the whole document.
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isSpace(c) {
+			p.pos++
+			continue
+		}
+		if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// next reads one object or operator. Strings are returned as []byte, numbers as float64 and
+// null as nil. io.EOF is returned at the end of the data.
+func (p *parser) next() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, io.EOF
+	}
+
+	c := p.data[p.pos]
+	switch {
+	case c == '/':
+		return p.readName(), nil
+	case c == '(':
+		return p.readLiteralString(), nil
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		p.pos += 2
+		return p.readDict()
+	case c == '<':
+		return p.readHexString(), nil
+	case c == '>' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '>':
+		p.pos += 2
+		return keyword(">>"), nil
+	case c == '[':
+		p.pos++
+		return p.readArray()
+	case isDelimiter(c):
+		p.pos++
+		return keyword(string(c)), nil
+	case c == '+' || c == '-' || c == '.' || isDigit(c):
+		return p.readNumberOrRef(), nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	switch word := string(p.data[start:p.pos]); word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return keyword(word), nil
+	}
+}
+
+func (p *parser) readName() name {
+	p.pos++
+	var out []byte
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		c := p.data[p.pos]
+		if c == '#' && p.pos+2 < len(p.data) {
+			if v, err := strconv.ParseUint(string(p.data[p.pos+1:p.pos+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				p.pos += 3
+				continue
+			}
+		}
+		out = append(out, c)
+		p.pos++
+	}
+	return name(out)
+}
+
+func (p *parser) readLiteralString() []byte {
+	p.pos++
+	var out []byte
+	depth := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if p.pos >= len(p.data) {
+				return out
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					out = append(out, byte(v))
+					continue
+				}
+				out = append(out, e)
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (p *parser) readHexString() []byte {
+	p.pos++
+	var (
+		out  []byte
+		half = -1
+	)
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		if c == '>' {
+			break
+		}
+		v, ok := hexValue(c)
+		if !ok {
+			continue
+		}
+		if half < 0 {
+			half = v
+			continue
+		}
+		out = append(out, byte(half<<4|v))
+		half = -1
+	}
+	if half >= 0 {
+		out = append(out, byte(half<<4))
+	}
+	return out
+}
+
+func hexValue(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10, true
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10, true
+	}
+	return 0, false
+}
+
+func (p *parser) readDict() (any, error) {
+	d := dict{}
+	for {
+		key, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if key == keyword(">>") {
+			return d, nil
+		}
+		k, ok := key.(name)
+		if !ok {
+			continue
+		}
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if value == keyword(">>") {
+			return d, nil
+		}
+		d[k] = value
+	}
+}
+
+func (p *parser) readArray() (any, error) {
+	var a array
+	for {
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if value == keyword("]") {
+			return a, nil
+		}
+		a = append(a, value)
+	}
+}
+
+// readNumberOrRef reads a number, or an indirect reference when the number is followed by
+// a generation number and R.
+func (p *parser) readNumberOrRef() any {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.data) && (isDigit(p.data[p.pos]) || p.data[p.pos] == '.') {
+		p.pos++
+	}
+	token := string(p.data[start:p.pos])
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		value = 0
+	}
+
+	if !isDigit(token[0]) || !isInteger(token) {
+		return value
+	}
+	save := p.pos
+	p.skipSpace()
+	genStart := p.pos
+	for p.pos < len(p.data) && isDigit(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos > genStart {
+		gen, _ := strconv.Atoi(string(p.data[genStart:p.pos]))
+		p.skipSpace()
+		if p.pos < len(p.data) && p.data[p.pos] == 'R' &&
+			(p.pos+1 == len(p.data) || isSpace(p.data[p.pos+1]) || isDelimiter(p.data[p.pos+1])) {
+			p.pos++
+			return ref{num: int(value), gen: gen}
+		}
+	}
+	p.pos = save
+	return value
+}
+
+func isInteger(token string) bool {
+	for i := 0; i < len(token); i++ {
+		if !isDigit(token[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,289 @@
+// Package pdftext extracts the text of PDF documents in pure Go.
+//
+// It reads the text-showing operators of every page and decodes them through each font's
+// ToUnicode CMap, falling back to WinAnsiEncoding for simple fonts. This covers the invoices
+// and receipts produced by billing systems; scanned PDFs have no text and yield an empty string.
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	// ErrInvalid is returned for data that is not a readable PDF document.
+	ErrInvalid = errors.New("pdf is invalid")
+	// ErrEncrypted is returned for password-protected documents, whose streams cannot be read.
+	ErrEncrypted = errors.New("pdf is encrypted")
+)
+
+// maxFormDepth bounds nested form XObjects.
+const maxFormDepth = 5
+
+// Extract returns the text of every page, separated by blank lines.
+func Extract(data []byte) (text string, err error) {
+	header := data
+	if len(header) > 1024 {
+		header = header[:1024]
+	}
+	if !bytes.Contains(header, []byte("%PDF-")) {
+		return "", ErrInvalid
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			text = ""
+			err = fmt.Errorf("%w: %v", ErrInvalid, r)
+		}
+	}()
+
+	doc := loadDocument(data, maxDecodedBytes)
+	if doc.encrypted {
+		return "", ErrEncrypted
+	}
+	pages := doc.pages()
+	if doc.overBudget {
+		return "", fmt.Errorf("%w: %v", ErrInvalid, errDecodeBudgetExceeded)
+	}
+	if len(pages) == 0 {
+		return "", ErrInvalid
+	}
+
+	e := &extractor{doc: doc, fonts: make(map[int]*font)}
+	for i, p := range pages {
+		if i > 0 {
+			e.write("\n\n")
+		}
+		e.run(doc.contents(p), p.resources, 0)
+		if doc.overBudget {
+			return "", fmt.Errorf("%w: %v", ErrInvalid, errDecodeBudgetExceeded)
+		}
+	}
+	return normalize(e.out.String()), nil
+}
+
+// extractor interprets content streams and writes the shown text.
+type extractor struct {
+	doc   *document
+	fonts map[int]*font
+	out   strings.Builder
+	// last is the last byte written to out.
+	last byte
+
+	font    *font
+	leading float64
+	lineY   float64
+	shownY  float64
+	shown   bool
+	moved   bool
+}
+
+func (e *extractor) run(content []byte, resources dict, depth int) {
+	p := &parser{data: content}
+	var operands []any
+	for {
+		value, err := p.next()
+		if err != nil {
+			return
+		}
+		op, ok := value.(keyword)
+		if !ok {
+			operands = append(operands, value)
+			continue
+		}
+
+		switch op {
+		case "BT":
+			e.lineY = 0
+			e.moved = true
+		case "Tf":
+			if len(operands) >= 2 {
+				if fontName, ok := operands[0].(name); ok {
+					e.font = e.lookupFont(resources, fontName)
+				}
+			}
+		case "TL":
+			if len(operands) >= 1 {
+				e.leading = number(operands[0])
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				ty := number(operands[1])
+				if op == "TD" {
+					e.leading = -ty
+				}
+				e.lineY += ty
+				e.moved = true
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				e.lineY = number(operands[5])
+				e.moved = true
+			}
+		case "T*":
+			e.nextLine()
+		case "Tj":
+			if len(operands) >= 1 {
+				e.show(operands[len(operands)-1])
+			}
+		case "'", "\"":
+			e.nextLine()
+			if len(operands) >= 1 {
+				e.show(operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				if items, ok := operands[len(operands)-1].(array); ok {
+					for _, item := range items {
+						// A large negative adjustment is how generators lay out a word gap.
+						if adjust, ok := item.(float64); ok && adjust < -200 {
+							e.space()
+							continue
+						}
+						e.show(item)
+					}
+				}
+			}
+		case "ID":
+			skipInlineImage(p)
+		case "Do":
+			if len(operands) >= 1 && depth < maxFormDepth {
+				if xobjectName, ok := operands[0].(name); ok {
+					e.runForm(resources, xobjectName, depth)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+func (e *extractor) nextLine() {
+	e.lineY -= e.leading
+	if e.leading == 0 {
+		// Without a leading there is no y change to detect, but T* still starts a new line.
+		e.lineY -= 1
+	}
+	e.moved = true
+}
+
+func (e *extractor) show(value any) {
+	b, ok := value.([]byte)
+	if !ok {
+		return
+	}
+	text := e.font.decode(b)
+	if text == "" {
+		return
+	}
+
+	if e.shown && e.moved {
+		if math.Abs(e.lineY-e.shownY) > 1 {
+			e.write("\n")
+		} else {
+			e.space()
+		}
+	}
+	e.write(text)
+	e.shown = true
+	e.moved = false
+	e.shownY = e.lineY
+}
+
+func (e *extractor) space() {
+	if e.last == 0 || e.last == ' ' || e.last == '\n' {
+		return
+	}
+	e.write(" ")
+}
+
+func (e *extractor) write(s string) {
+	e.out.WriteString(s)
+	e.last = s[len(s)-1]
+}
+
+func (e *extractor) lookupFont(resources dict, fontName name) *font {
+	fonts := e.doc.dict(resources["Font"])
+	if fonts == nil {
+		return nil
+	}
+	entry := fonts[fontName]
+	if r, ok := entry.(ref); ok {
+		if cached, ok := e.fonts[r.num]; ok {
+			return cached
+		}
+		f := e.doc.loadFont(e.doc.dict(r))
+		e.fonts[r.num] = f
+		return f
+	}
+	if d := e.doc.dict(entry); d != nil {
+		return e.doc.loadFont(d)
+	}
+	return nil
+}
+
+// runForm interprets a form XObject, which some generators use for the whole page body.
+func (e *extractor) runForm(resources dict, xobjectName name, depth int) {
+	xobjects := e.doc.dict(resources["XObject"])
+	if xobjects == nil {
+		return
+	}
+	s, ok := e.doc.resolve(xobjects[xobjectName]).(stream)
+	if !ok || s.dict["Subtype"] != name("Form") {
+		return
+	}
+	data, err := e.doc.decode(s)
+	if err != nil {
+		return
+	}
+	formResources := resources
+	if own := e.doc.dict(s.dict["Resources"]); own != nil {
+		formResources = own
+	}
+
+	saved := e.font
+	e.run(data, formResources, depth+1)
+	e.font = saved
+}
+
+// skipInlineImage moves past the binary data of an inline image up to its EI operator.
+func skipInlineImage(p *parser) {
+	for i := p.pos; i+2 <= len(p.data); i++ {
+		if p.data[i] == 'E' && p.data[i+1] == 'I' &&
+			(i == 0 || isSpace(p.data[i-1])) &&
+			(i+2 == len(p.data) || isSpace(p.data[i+2])) {
+			p.pos = i + 2
+			return
+		}
+	}
+	p.pos = len(p.data)
+}
+
+func number(value any) float64 {
+	if v, ok := value.(float64); ok {
+		return v
+	}
+	return 0
+}
+
+// normalize trims every line and collapses runs of blank lines.
+func normalize(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
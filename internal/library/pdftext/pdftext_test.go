@@ -0,0 +1,157 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF lays out numbered objects and a trailer. Readers here do not need an xref table.
+func buildPDF(trailer string, objects ...string) []byte {
+	var b strings.Builder
+	b.WriteString("%PDF-1.7\n")
+	for i, object := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	b.WriteString("trailer\n" + trailer + "\n%%EOF\n")
+	return []byte(b.String())
+}
+
+func streamObject(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtract_SimpleFontPages(t *testing.T) {
+	t.Parallel()
+
+	page1 := "BT /F1 12 Tf 72 720 Td (INVOICE) Tj 0 -20 Td [(Total)-250(due:)] TJ 200 0 Td (USD 120.00) Tj ET"
+	page2 := "BT /F1 12 Tf 14 TL 72 720 Td (Thank you) Tj T* (for your \\(continued\\) business) Tj ET"
+	data := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		streamObject("", []byte(page1)),
+		streamObject("", []byte(page2)),
+	)
+
+	text, err := Extract(data)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	want := "INVOICE\nTotal due: USD 120.00\n\nThank you\nfor your (continued) business"
+	if text != want {
+		t.Fatalf("unexpected text:\n%q\nwant:\n%q", text, want)
+	}
+}
+
+func TestExtract_CompressedCompositeFontWithToUnicode(t *testing.T) {
+	t.Parallel()
+
+	cmap := strings.Join([]string{
+		"/CIDInit /ProcSet findresource begin",
+		"12 dict begin begincmap",
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def",
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange",
+		"3 beginbfchar <0001> <8ACB> <0002> <6C42> <0003> <66F8> endbfchar",
+		"2 beginbfrange <0010> <0019> <0030> <0020> <0021> [<5408> <8A08>] endbfrange",
+		"1 beginbfchar <0030> <FFE5> endbfchar",
+		"endcmap CMapName currentdict /CMap defineresource pop end end",
+	}, "\n")
+	content := "BT /F1 10 Tf 1 0 0 1 50 800 Tm <000100020003> Tj 1 0 0 1 50 780 Tm <00200021> Tj 1 0 0 1 120 780 Tm <003000110012001000100010> Tj ET"
+
+	// The font dictionaries live in an object stream, as PDF 1.5 writers emit them.
+	fontObjects := "<< /Type /Font /Subtype /Type0 /BaseFont /NotoSansJP /Encoding /Identity-H /ToUnicode 7 0 R >>"
+	objStmHeader := "5 0 "
+	objStm := deflate(t, objStmHeader+fontObjects)
+
+	data := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		streamObject("/Filter /FlateDecode", deflate(t, content)),
+		"null",
+		streamObject(fmt.Sprintf("/Type /ObjStm /N 1 /First %d /Filter /FlateDecode", len(objStmHeader)), objStm),
+		streamObject("/Filter /FlateDecode", deflate(t, cmap)),
+	)
+	// Object 5 is only a placeholder in the body; drop it so the object stream copy is used.
+	data = bytes.Replace(data, []byte("5 0 obj\nnull\nendobj\n"), nil, 1)
+
+	text, err := Extract(data)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if want := "請求書\n合計 ￥12000"; text != want {
+		t.Fatalf("unexpected text: got=%q want=%q", text, want)
+	}
+}
+
+func TestExtract_RejectsUnreadableDocuments(t *testing.T) {
+	t.Parallel()
+
+	encrypted := buildPDF("<< /Root 1 0 R /Encrypt 3 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 0 >>",
+		"<< /Filter /Standard /V 2 /R 3 >>",
+	)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "not a pdf", data: []byte("PK\x03\x04 zip archive"), wantErr: ErrInvalid},
+		{name: "no pages", data: buildPDF("<< >>", "<< /Type /Catalog >>"), wantErr: ErrInvalid},
+		{name: "encrypted", data: encrypted, wantErr: ErrEncrypted},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := Extract(tt.data); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unexpected error: got=%v want=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtract_RejectsFlateBomb(t *testing.T) {
+	t.Parallel()
+
+	// Each stream stays under the budget on its own; together they exceed it.
+	bomb := deflate(t, strings.Repeat("0", maxDecodedBytes/2+1))
+	data := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [4 0 R 5 0 R] >>",
+		streamObject("/Filter /FlateDecode", bomb),
+		streamObject("/Filter /FlateDecode", bomb),
+	)
+	if len(data) > 1<<20 {
+		t.Fatalf("fixture must be small compared to its inflated size: %d bytes", len(data))
+	}
+
+	if _, err := Extract(data); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+}
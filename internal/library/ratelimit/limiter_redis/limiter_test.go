@@ -157,6 +157,30 @@ func (m *mockRedisClient) RunRateLimitScript(ctx context.Context, params rediscl
 	return resp.result, nil
 }
 
+func (m *mockRedisClient) AcquireLease(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return false, errors.New("unexpected AcquireLease call")
+}
+
+func (m *mockRedisClient) ReleaseLease(ctx context.Context, key, token string) error {
+	return errors.New("unexpected ReleaseLease call")
+}
+
+func (m *mockRedisClient) Publish(ctx context.Context, channel, payload string) error {
+	return errors.New("unexpected Publish call")
+}
+
+func (m *mockRedisClient) Subscribe(ctx context.Context, channel string) (redisclient.Subscription, error) {
+	return nil, errors.New("unexpected Subscribe call")
+}
+
+func (m *mockRedisClient) GetString(ctx context.Context, key string) (string, bool, error) {
+	return "", false, errors.New("unexpected GetString call")
+}
+
+func (m *mockRedisClient) SetString(ctx context.Context, key, value string, ttl time.Duration) error {
+	return errors.New("unexpected SetString call")
+}
+
 func (m *mockRedisClient) EvalScript(ctx context.Context, scr script.Script, keys []string, args ...interface{}) (interface{}, error) {
 	return nil, errors.New("EvalScript should not be called directly in limiter tests")
 }
@@ -12,6 +12,7 @@ import (
 type Provider struct {
 	gmailLimiter  Limiter
 	openaiLimiter Limiter
+	redisClient   redisclient.ClientInterface
 }
 
 // GetGmailLimiter returns the Gmail limiter instance.
@@ -24,6 +25,12 @@ func (p *Provider) GetOpenAILimiter() Limiter {
 	return p.openaiLimiter
 }
 
+// GetRedisClient returns the Redis client shared by the limiters.
+// Other features reuse it so that a process keeps a single Redis connection pool.
+func (p *Provider) GetRedisClient() redisclient.ClientInterface {
+	return p.redisClient
+}
+
 // NewProviderFromEnv constructs a Provider by reading Redis configuration from the environment.
 func NewProviderFromEnv(osw oswrapper.OsWapperInterface, log logger.Interface) (*Provider, error) {
 	if osw == nil {
@@ -71,5 +78,6 @@ func NewProvider(
 	return &Provider{
 		gmailLimiter:  gmailLimiter,
 		openaiLimiter: openaiLimiter,
+		redisClient:   client,
 	}
 }
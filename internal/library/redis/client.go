@@ -2,10 +2,12 @@ package redisclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"business/internal/library/logger"
 	"business/internal/library/oswrapper"
@@ -207,6 +209,162 @@ func (c *Client) RunRateLimitScript(ctx context.Context, params RateLimitParams)
 	}, nil
 }
 
+// releaseLeaseScript deletes the lease only while it is still owned by the caller's token.
+var releaseLeaseScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLease sets key to token only when key does not exist, expiring after ttl.
+// It returns false when another owner currently holds the lease.
+func (c *Client) AcquireLease(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	if c.client == nil {
+		return false, &ErrRedisUnavailable{Err: fmt.Errorf("redis client is not configured")}
+	}
+
+	acquired, err := c.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		addr, db := c.redisAddrAndDB()
+		return false, &ErrRedisUnavailable{
+			Err: fmt.Errorf("redis lease acquisition failed (addr=%s db=%d): %w", addr, db, err),
+		}
+	}
+	return acquired, nil
+}
+
+// ReleaseLease deletes key only when it still holds token, so an expired and re-acquired lease is kept.
+func (c *Client) ReleaseLease(ctx context.Context, key, token string) error {
+	if c.client == nil {
+		return &ErrRedisUnavailable{Err: fmt.Errorf("redis client is not configured")}
+	}
+
+	if err := releaseLeaseScript.Run(ctx, c.client, []string{key}, token).Err(); err != nil {
+		addr, db := c.redisAddrAndDB()
+		return &ErrRedisUnavailable{
+			Err: fmt.Errorf("redis lease release failed (addr=%s db=%d): %w", addr, db, err),
+		}
+	}
+	return nil
+}
+
+// Publish sends payload to every subscriber of channel.
+func (c *Client) Publish(ctx context.Context, channel, payload string) error {
+	if c.client == nil {
+		return &ErrRedisUnavailable{Err: fmt.Errorf("redis client is not configured")}
+	}
+
+	if err := c.client.Publish(ctx, channel, payload).Err(); err != nil {
+		addr, db := c.redisAddrAndDB()
+		return &ErrRedisUnavailable{
+			Err: fmt.Errorf("redis publish failed (addr=%s db=%d): %w", addr, db, err),
+		}
+	}
+	return nil
+}
+
+// Subscribe subscribes to channel and waits for Redis to confirm the subscription,
+// so that messages published after it returns are not missed.
+func (c *Client) Subscribe(ctx context.Context, channel string) (Subscription, error) {
+	if c.client == nil {
+		return nil, &ErrRedisUnavailable{Err: fmt.Errorf("redis client is not configured")}
+	}
+
+	pubsub := c.client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		addr, db := c.redisAddrAndDB()
+		return nil, &ErrRedisUnavailable{
+			Err: fmt.Errorf("redis subscribe failed (addr=%s db=%d): %w", addr, db, err),
+		}
+	}
+
+	sub := &subscription{
+		pubsub:   pubsub,
+		messages: make(chan string),
+		done:     make(chan struct{}),
+	}
+	go sub.forward()
+	return sub, nil
+}
+
+// GetString returns the value stored at key. found is false when key does not exist or has expired.
+func (c *Client) GetString(ctx context.Context, key string) (string, bool, error) {
+	if c.client == nil {
+		return "", false, &ErrRedisUnavailable{Err: fmt.Errorf("redis client is not configured")}
+	}
+
+	value, err := c.client.Get(ctx, key).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		addr, db := c.redisAddrAndDB()
+		return "", false, &ErrRedisUnavailable{
+			Err: fmt.Errorf("redis get failed (addr=%s db=%d): %w", addr, db, err),
+		}
+	}
+	return value, true, nil
+}
+
+// SetString stores value at key, expiring after ttl.
+func (c *Client) SetString(ctx context.Context, key, value string, ttl time.Duration) error {
+	if c.client == nil {
+		return &ErrRedisUnavailable{Err: fmt.Errorf("redis client is not configured")}
+	}
+
+	if err := c.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		addr, db := c.redisAddrAndDB()
+		return &ErrRedisUnavailable{
+			Err: fmt.Errorf("redis set failed (addr=%s db=%d): %w", addr, db, err),
+		}
+	}
+	return nil
+}
+
+// subscription adapts goredis.PubSub to Subscription by forwarding only the payloads.
+type subscription struct {
+	pubsub    *goredis.PubSub
+	messages  chan string
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *subscription) Messages() <-chan string {
+	return s.messages
+}
+
+func (s *subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.pubsub.Close()
+	})
+	return err
+}
+
+func (s *subscription) forward() {
+	defer close(s.messages)
+
+	source := s.pubsub.Channel()
+	for {
+		select {
+		case <-s.done:
+			return
+		case msg, ok := <-source:
+			if !ok {
+				return
+			}
+			select {
+			case s.messages <- msg.Payload:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
 func (c *Client) redisAddrAndDB() (string, int) {
 	if c.client == nil {
 		return "", 0
@@ -508,3 +508,99 @@ func TestParseInt64(t *testing.T) {
 		})
 	}
 }
+
+// TestLease_AcquireAndReleaseByOwner tests that only the owning token can release a lease.
+func TestLease_AcquireAndReleaseByOwner(t *testing.T) {
+	t.Parallel()
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := &Client{
+		client:      goredis.NewClient(&goredis.Options{Addr: mr.Addr()}),
+		scriptCache: make(map[string]string),
+	}
+	ctx := context.Background()
+
+	acquired, err := client.AcquireLease(ctx, "lock:test", "owner-a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = client.AcquireLease(ctx, "lock:test", "owner-b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired, "second owner must not acquire a held lease")
+
+	require.NoError(t, client.ReleaseLease(ctx, "lock:test", "owner-b"))
+	assert.True(t, mr.Exists("lock:test"), "non-owner release must keep the lease")
+
+	require.NoError(t, client.ReleaseLease(ctx, "lock:test", "owner-a"))
+	assert.False(t, mr.Exists("lock:test"))
+
+	acquired, err = client.AcquireLease(ctx, "lock:test", "owner-b", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	mr.FastForward(2 * time.Minute)
+	assert.False(t, mr.Exists("lock:test"), "lease must expire after ttl")
+}
+
+// TestString_SetGetAndExpire tests that a cached value is returned until its ttl passes.
+func TestString_SetGetAndExpire(t *testing.T) {
+	t.Parallel()
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := &Client{
+		client:      goredis.NewClient(&goredis.Options{Addr: mr.Addr()}),
+		scriptCache: make(map[string]string),
+	}
+	ctx := context.Background()
+
+	_, found, err := client.GetString(ctx, "cache:test")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, client.SetString(ctx, "cache:test", "value", time.Minute))
+	value, found, err := client.GetString(ctx, "cache:test")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+
+	mr.FastForward(2 * time.Minute)
+	_, found, err = client.GetString(ctx, "cache:test")
+	require.NoError(t, err)
+	assert.False(t, found, "value must expire after ttl")
+}
+
+// TestPubSub_DeliversPublishedPayloads tests that a subscription receives payloads until it is closed.
+func TestPubSub_DeliversPublishedPayloads(t *testing.T) {
+	t.Parallel()
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := &Client{
+		client:      goredis.NewClient(&goredis.Options{Addr: mr.Addr()}),
+		scriptCache: make(map[string]string),
+	}
+	ctx := context.Background()
+
+	sub, err := client.Subscribe(ctx, "events:test")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Publish(ctx, "events:other", "ignored"))
+	require.NoError(t, client.Publish(ctx, "events:test", "hello"))
+
+	select {
+	case payload := <-sub.Messages():
+		assert.Equal(t, "hello", payload)
+	case <-time.After(time.Second):
+		t.Fatal("published payload was not delivered")
+	}
+
+	require.NoError(t, sub.Close())
+	require.NoError(t, sub.Close(), "closing twice must be a no-op")
+	select {
+	case _, ok := <-sub.Messages():
+		assert.False(t, ok, "messages must be closed after Close")
+	case <-time.After(time.Second):
+		t.Fatal("messages channel was not closed")
+	}
+}
@@ -24,9 +24,22 @@ type RateLimitResult struct {
 	Current       int
 }
 
-// ClientInterface abstracts the Redis client operations needed for rate limiting.
+// Subscription is an active Redis pub/sub subscription to one channel.
+// Messages is closed once the subscription is closed or the connection is lost.
+type Subscription interface {
+	Messages() <-chan string
+	Close() error
+}
+
+// ClientInterface abstracts the Redis client operations needed for rate limiting, leases, pub/sub and caching.
 type ClientInterface interface {
 	EvalScript(ctx context.Context, scr script.Script, keys []string, args ...interface{}) (interface{}, error)
 	EvalSha(ctx context.Context, sha string, keys []string, args ...interface{}) (interface{}, error)
 	RunRateLimitScript(ctx context.Context, params RateLimitParams) (RateLimitResult, error)
+	AcquireLease(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, key, token string) error
+	Publish(ctx context.Context, channel, payload string) error
+	Subscribe(ctx context.Context, channel string) (Subscription, error)
+	GetString(ctx context.Context, key string) (string, bool, error)
+	SetString(ctx context.Context, key, value string, ttl time.Duration) error
 }
@@ -0,0 +1,173 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/mailaccountconnection/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	imapCredentialType   = "imap"
+	defaultIMAPTLSPort   = 993
+	defaultIMAPPlainPort = 143
+	maxIMAPPort          = 65535
+)
+
+// IMAPConnectInput is the IMAP server and login entered by the user.
+type IMAPConnectInput struct {
+	Host     string
+	Port     int
+	Security string
+	Username string
+	Password string
+}
+
+// IMAPLoginVerifier checks that the IMAP server accepts the login before it is stored.
+// It returns domain.ErrIMAPAuthenticationFailed or domain.ErrIMAPServerUnreachable for the expected failures.
+type IMAPLoginVerifier interface {
+	VerifyLogin(ctx context.Context, input IMAPConnectInput) error
+}
+
+// WithInsecureIMAP returns a copy that also accepts security "none". The login is then sent in
+// plain text, so only development setups with a local IMAP server should allow it.
+func (uc *UseCase) WithInsecureIMAP(allow bool) *UseCase {
+	clone := *uc
+	clone.allowInsecureIMAP = allow
+	return &clone
+}
+
+// ConnectIMAP verifies the IMAP login and stores it with the password encrypted.
+// Connecting the same account again replaces its server settings and password.
+func (uc *UseCase) ConnectIMAP(ctx context.Context, userID uint, input IMAPConnectInput) (domain.ConnectionView, error) {
+	reqLog := uc.log
+	if l, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = l
+	}
+
+	normalized, err := normalizeIMAPConnectInput(input, uc.allowInsecureIMAP)
+	if err != nil {
+		reqLog.Info("imap_settings_invalid", logger.UserID(userID), logger.Err(err))
+		return domain.ConnectionView{}, err
+	}
+
+	if err := uc.imapLogin.VerifyLogin(ctx, normalized); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrIMAPAuthenticationFailed):
+			reqLog.Info("imap_authentication_failed", logger.UserID(userID), logger.String("imap_host", normalized.Host))
+			return domain.ConnectionView{}, domain.ErrIMAPAuthenticationFailed
+		case errors.Is(err, domain.ErrIMAPServerUnreachable):
+			reqLog.Warn("imap_server_unreachable", logger.UserID(userID), logger.String("imap_host", normalized.Host), logger.Err(err))
+			return domain.ConnectionView{}, domain.ErrIMAPServerUnreachable
+		default:
+			reqLog.Error("imap_login_verify_failed", logger.UserID(userID), logger.Err(err))
+			return domain.ConnectionView{}, fmt.Errorf("failed to verify imap login: %w", err)
+		}
+	}
+
+	encPassword, err := uc.vault.EncryptToString(normalized.Password)
+	if err != nil {
+		reqLog.Error("imap_password_encrypt_failed", logger.Err(err))
+		return domain.ConnectionView{}, domain.ErrVaultEncryptFailed
+	}
+
+	now := uc.clock.Now()
+	credential, err := uc.repo.SaveIMAPConnection(ctx,
+		domain.EmailCredential{
+			UserID:       userID,
+			Type:         imapCredentialType,
+			GmailAddress: imapAccountIdentifier(normalized),
+			KeyVersion:   defaultKeyVer,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		},
+		domain.IMAPSettings{
+			Host:      normalized.Host,
+			Port:      normalized.Port,
+			Security:  normalized.Security,
+			Username:  normalized.Username,
+			Password:  encPassword,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	)
+	if err != nil {
+		reqLog.Error("imap_connection_save_failed", logger.Err(err))
+		return domain.ConnectionView{}, fmt.Errorf("failed to save imap connection: %w", err)
+	}
+
+	reqLog.Info("imap_connection_saved",
+		logger.UserID(userID),
+		logger.Uint("connection_id", credential.ID),
+		logger.String("provider", imapCredentialType),
+	)
+
+	return domain.ConnectionView{
+		ID:                credential.ID,
+		Provider:          credential.Type,
+		AccountIdentifier: credential.GmailAddress,
+		CreatedAt:         credential.CreatedAt,
+		UpdatedAt:         credential.UpdatedAt,
+	}, nil
+}
+
+func normalizeIMAPConnectInput(input IMAPConnectInput, allowInsecure bool) (IMAPConnectInput, error) {
+	normalized := IMAPConnectInput{
+		Host:     strings.ToLower(strings.TrimSpace(input.Host)),
+		Port:     input.Port,
+		Security: strings.ToLower(strings.TrimSpace(input.Security)),
+		Username: strings.TrimSpace(input.Username),
+		Password: input.Password,
+	}
+
+	if normalized.Host == "" || strings.ContainsAny(normalized.Host, " \t/") {
+		return IMAPConnectInput{}, fmt.Errorf("%w: host is invalid", domain.ErrIMAPSettingsInvalid)
+	}
+
+	switch normalized.Security {
+	case "":
+		normalized.Security = domain.IMAPSecurityTLS
+	case domain.IMAPSecurityTLS, domain.IMAPSecurityStartTLS:
+	case domain.IMAPSecurityNone:
+		if !allowInsecure {
+			return IMAPConnectInput{}, fmt.Errorf("%w: security none is not allowed", domain.ErrIMAPSettingsInvalid)
+		}
+	default:
+		return IMAPConnectInput{}, fmt.Errorf("%w: security must be tls or starttls", domain.ErrIMAPSettingsInvalid)
+	}
+
+	if normalized.Port == 0 {
+		normalized.Port = defaultIMAPPlainPort
+		if normalized.Security == domain.IMAPSecurityTLS {
+			normalized.Port = defaultIMAPTLSPort
+		}
+	}
+	if normalized.Port < 1 || normalized.Port > maxIMAPPort {
+		return IMAPConnectInput{}, fmt.Errorf("%w: port is out of range", domain.ErrIMAPSettingsInvalid)
+	}
+
+	if normalized.Username == "" || normalized.Password == "" {
+		return IMAPConnectInput{}, fmt.Errorf("%w: username and password are required", domain.ErrIMAPSettingsInvalid)
+	}
+	// LOGIN sends both as IMAP quoted strings, which cannot carry line breaks or 8-bit characters.
+	for _, r := range normalized.Username + normalized.Password {
+		if r == '\r' || r == '\n' || r > unicode.MaxASCII {
+			return IMAPConnectInput{}, fmt.Errorf("%w: username and password must be ASCII without line breaks", domain.ErrIMAPSettingsInvalid)
+		}
+	}
+
+	return normalized, nil
+}
+
+// imapAccountIdentifier is the mail address shown in the connection list. Servers that log in
+// with a bare user name get "user@host" so that accounts on different servers stay distinct.
+func imapAccountIdentifier(input IMAPConnectInput) string {
+	username := strings.ToLower(input.Username)
+	if strings.Contains(username, "@") {
+		return username
+	}
+	return username + "@" + input.Host
+}
@@ -0,0 +1,190 @@
+package application
+
+import (
+	"business/internal/mailaccountconnection/domain"
+	mocklibrary "business/test/mock/library"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIMAPLoginVerifier struct {
+	mock.Mock
+}
+
+func (m *mockIMAPLoginVerifier) VerifyLogin(ctx context.Context, input IMAPConnectInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func newIMAPTestUseCase(repo *mockRepo, verifier *mockIMAPLoginVerifier, now time.Time) *UseCase {
+	return NewUseCase(repo, nil, nil, nil, verifier, nil, nil, newTestVault(), testClock(now), mocklibrary.NewNopLogger())
+}
+
+func TestConnectIMAP_Success(t *testing.T) {
+	t.Parallel()
+	repo := new(mockRepo)
+	verifier := new(mockIMAPLoginVerifier)
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	normalized := IMAPConnectInput{
+		Host:     "imap.mail.yahoo.co.jp",
+		Port:     993,
+		Security: domain.IMAPSecurityTLS,
+		Username: "Billing",
+		Password: "app-password",
+	}
+	verifier.On("VerifyLogin", mock.Anything, normalized).Return(nil)
+
+	var savedSettings domain.IMAPSettings
+	repo.On("SaveIMAPConnection", mock.Anything,
+		mock.MatchedBy(func(cred domain.EmailCredential) bool {
+			return cred.UserID == 1 && cred.Type == "imap" && cred.GmailAddress == "billing@imap.mail.yahoo.co.jp" &&
+				cred.AccessToken == "" && cred.RefreshToken == ""
+		}),
+		mock.Anything,
+	).Run(func(args mock.Arguments) {
+		savedSettings = args.Get(2).(domain.IMAPSettings)
+	}).Return(domain.EmailCredential{
+		ID:           21,
+		UserID:       1,
+		Type:         "imap",
+		GmailAddress: "billing@imap.mail.yahoo.co.jp",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil)
+
+	uc := newIMAPTestUseCase(repo, verifier, now)
+	view, err := uc.ConnectIMAP(context.Background(), 1, IMAPConnectInput{
+		Host:     " IMAP.mail.yahoo.co.jp ",
+		Username: " Billing ",
+		Password: "app-password",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ConnectionView{
+		ID:                21,
+		Provider:          "imap",
+		AccountIdentifier: "billing@imap.mail.yahoo.co.jp",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}, view)
+
+	assert.Equal(t, "imap.mail.yahoo.co.jp", savedSettings.Host)
+	assert.Equal(t, 993, savedSettings.Port)
+	assert.Equal(t, "Billing", savedSettings.Username)
+	assert.NotEqual(t, "app-password", savedSettings.Password)
+	decrypted, err := newTestVault().DecryptFromString(savedSettings.Password)
+	require.NoError(t, err)
+	assert.Equal(t, "app-password", decrypted)
+
+	repo.AssertExpectations(t)
+	verifier.AssertExpectations(t)
+}
+
+func TestConnectIMAP_InvalidSettings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input IMAPConnectInput
+	}{
+		{name: "missing host", input: IMAPConnectInput{Username: "u", Password: "p"}},
+		{name: "unknown security", input: IMAPConnectInput{Host: "imap.example.com", Security: "ssl3", Username: "u", Password: "p"}},
+		{name: "plain text without the local server flag", input: IMAPConnectInput{Host: "imap.example.com", Security: "none", Username: "u", Password: "p"}},
+		{name: "port out of range", input: IMAPConnectInput{Host: "imap.example.com", Port: 70000, Username: "u", Password: "p"}},
+		{name: "missing password", input: IMAPConnectInput{Host: "imap.example.com", Username: "u"}},
+		{name: "non-ascii password", input: IMAPConnectInput{Host: "imap.example.com", Username: "u", Password: "パスワード"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := new(mockRepo)
+			verifier := new(mockIMAPLoginVerifier)
+
+			uc := newIMAPTestUseCase(repo, verifier, time.Now())
+			_, err := uc.ConnectIMAP(context.Background(), 1, tt.input)
+
+			assert.ErrorIs(t, err, domain.ErrIMAPSettingsInvalid)
+			verifier.AssertNotCalled(t, "VerifyLogin", mock.Anything, mock.Anything)
+			repo.AssertNotCalled(t, "SaveIMAPConnection", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestConnectIMAP_AllowsPlainTextWhenInsecureIMAPIsEnabled(t *testing.T) {
+	t.Parallel()
+	repo := new(mockRepo)
+	verifier := new(mockIMAPLoginVerifier)
+	verifier.On("VerifyLogin", mock.Anything, mock.MatchedBy(func(input IMAPConnectInput) bool {
+		return input.Security == domain.IMAPSecurityNone && input.Port == 143
+	})).Return(nil)
+	repo.On("SaveIMAPConnection", mock.Anything, mock.Anything, mock.Anything).
+		Return(domain.EmailCredential{ID: 22, UserID: 1, Type: "imap", GmailAddress: "u@localhost"}, nil)
+
+	uc := newIMAPTestUseCase(repo, verifier, time.Now()).WithInsecureIMAP(true)
+	_, err := uc.ConnectIMAP(context.Background(), 1, IMAPConnectInput{
+		Host:     "localhost",
+		Security: "none",
+		Username: "u",
+		Password: "p",
+	})
+
+	require.NoError(t, err)
+	verifier.AssertExpectations(t)
+}
+
+func TestConnectIMAP_LoginRejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		verifyErr   error
+		expectedErr error
+	}{
+		{name: "authentication failed", verifyErr: domain.ErrIMAPAuthenticationFailed, expectedErr: domain.ErrIMAPAuthenticationFailed},
+		{name: "server unreachable", verifyErr: domain.ErrIMAPServerUnreachable, expectedErr: domain.ErrIMAPServerUnreachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := new(mockRepo)
+			verifier := new(mockIMAPLoginVerifier)
+			verifier.On("VerifyLogin", mock.Anything, mock.Anything).Return(tt.verifyErr)
+
+			uc := newIMAPTestUseCase(repo, verifier, time.Now())
+			_, err := uc.ConnectIMAP(context.Background(), 1, IMAPConnectInput{
+				Host:     "imap.example.com",
+				Username: "billing@example.com",
+				Password: "secret",
+			})
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			repo.AssertNotCalled(t, "SaveIMAPConnection", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestConnectIMAP_SaveError(t *testing.T) {
+	t.Parallel()
+	repo := new(mockRepo)
+	verifier := new(mockIMAPLoginVerifier)
+	verifier.On("VerifyLogin", mock.Anything, mock.Anything).Return(nil)
+	repo.On("SaveIMAPConnection", mock.Anything, mock.Anything, mock.Anything).
+		Return(domain.EmailCredential{}, errors.New("db timeout"))
+
+	uc := newIMAPTestUseCase(repo, verifier, time.Now())
+	_, err := uc.ConnectIMAP(context.Background(), 1, IMAPConnectInput{
+		Host:     "imap.example.com",
+		Security: domain.IMAPSecurityStartTLS,
+		Username: "billing@example.com",
+		Password: "secret",
+	})
+
+	assert.ErrorContains(t, err, "failed to save imap connection")
+}
@@ -0,0 +1,76 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/mailaccountconnection/domain"
+	"context"
+	"fmt"
+
+	"golang.org/x/oauth2"
+)
+
+const outlookCredentialType = "outlook"
+
+// OutlookOAuthConfigProvider resolves the Microsoft identity platform OAuth2 config.
+type OutlookOAuthConfigProvider interface {
+	GetOutlookOAuthConfig(ctx context.Context) (*oauth2.Config, error)
+}
+
+// OutlookProfileFetcher fetches the mailbox address of the signed-in Microsoft account from Graph.
+type OutlookProfileFetcher interface {
+	GetEmailAddress(ctx context.Context, token *oauth2.Token, cfg *oauth2.Config) (string, error)
+}
+
+// AuthorizeOutlook generates a Microsoft OAuth authorization URL and saves a pending state.
+func (uc *UseCase) AuthorizeOutlook(ctx context.Context, userID uint) (AuthorizeResult, error) {
+	reqLog := uc.log
+	if l, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = l
+	}
+
+	cfg, err := uc.outlookCfg.GetOutlookOAuthConfig(ctx)
+	if err != nil {
+		reqLog.Error("oauth_config_load_failed", logger.String("provider", outlookCredentialType), logger.Err(err))
+		return AuthorizeResult{}, fmt.Errorf("failed to load outlook oauth config: %w", err)
+	}
+
+	// The refresh token comes from the offline_access scope; select_account lets users who are
+	// signed in to several Microsoft accounts choose the mailbox to connect.
+	return uc.startOAuth(ctx, reqLog, userID, outlookCredentialType, cfg,
+		oauth2.SetAuthURLParam("prompt", "select_account"),
+	)
+}
+
+// CallbackOutlook validates state, exchanges code for tokens, and saves the Outlook credential.
+// Connecting the same mailbox again updates the stored tokens.
+func (uc *UseCase) CallbackOutlook(ctx context.Context, userID uint, code, state string) error {
+	reqLog := uc.log
+	if l, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = l
+	}
+
+	now, err := uc.consumePendingState(ctx, reqLog, userID, state, outlookCredentialType)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := uc.outlookCfg.GetOutlookOAuthConfig(ctx)
+	if err != nil {
+		reqLog.Error("oauth_config_load_failed", logger.String("provider", outlookCredentialType), logger.Err(err))
+		return domain.ErrOAuthExchangeFailed
+	}
+
+	token, err := uc.exchanger.Exchange(ctx, cfg, code)
+	if err != nil {
+		reqLog.Error("oauth_token_exchange_failed", logger.String("provider", outlookCredentialType), logger.Err(err))
+		return domain.ErrOAuthExchangeFailed
+	}
+
+	address, err := uc.outlookProfiler.GetEmailAddress(ctx, token, cfg)
+	if err != nil {
+		reqLog.Error("outlook_profile_fetch_failed", logger.Err(err))
+		return domain.ErrOutlookProfileFetchFailed
+	}
+
+	return uc.saveOAuthCredential(ctx, reqLog, userID, outlookCredentialType, address, token, now)
+}
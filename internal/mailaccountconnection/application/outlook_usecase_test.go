@@ -0,0 +1,209 @@
+package application
+
+import (
+	"business/internal/mailaccountconnection/domain"
+	mocklibrary "business/test/mock/library"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/oauth2"
+)
+
+type mockOutlookOAuthCfg struct {
+	mock.Mock
+}
+
+func (m *mockOutlookOAuthCfg) GetOutlookOAuthConfig(ctx context.Context) (*oauth2.Config, error) {
+	args := m.Called(ctx)
+	cfg, _ := args.Get(0).(*oauth2.Config)
+	return cfg, args.Error(1)
+}
+
+func testOutlookOAuthConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     "outlook-client-id",
+		ClientSecret: "outlook-client-secret",
+		RedirectURL:  "http://localhost/outlook/callback",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://login.microsoftonline.com/common/oauth2/v2.0/authorize",
+			TokenURL: "https://login.microsoftonline.com/common/oauth2/v2.0/token",
+		},
+		Scopes: []string{"offline_access", "https://graph.microsoft.com/Mail.Read"},
+	}
+}
+
+func outlookPendingState(now time.Time) domain.OAuthPendingState {
+	return domain.OAuthPendingState{
+		ID:        7,
+		UserID:    1,
+		Provider:  "outlook",
+		State:     "outlook-state",
+		ExpiresAt: now.Add(5 * time.Minute),
+		CreatedAt: now.Add(-5 * time.Minute),
+	}
+}
+
+func newOutlookTestUseCase(
+	repo *mockRepo,
+	outlookCfg *mockOutlookOAuthCfg,
+	exchanger *mockExchanger,
+	profiler *mockProfiler,
+	now time.Time,
+) *UseCase {
+	return NewUseCase(repo, nil, exchanger, nil, nil, outlookCfg, profiler, newTestVault(), testClock(now), mocklibrary.NewNopLogger())
+}
+
+func TestAuthorizeOutlook_Success(t *testing.T) {
+	t.Parallel()
+	repo := new(mockRepo)
+	outlookCfg := new(mockOutlookOAuthCfg)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	outlookCfg.On("GetOutlookOAuthConfig", mock.Anything).Return(testOutlookOAuthConfig(), nil)
+	repo.On("SavePendingState", mock.Anything, mock.MatchedBy(func(ps domain.OAuthPendingState) bool {
+		return ps.UserID == 1 && ps.Provider == "outlook" && ps.State != ""
+	})).Return(nil)
+
+	result, err := newOutlookTestUseCase(repo, outlookCfg, nil, nil, now).AuthorizeOutlook(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Contains(t, result.AuthorizationURL, "https://login.microsoftonline.com/common/oauth2/v2.0/authorize?")
+	assert.Contains(t, result.AuthorizationURL, "prompt=select_account")
+	assert.Contains(t, result.AuthorizationURL, "client_id=outlook-client-id")
+	assert.NotContains(t, result.AuthorizationURL, "access_type=offline")
+	assert.Equal(t, now.Add(10*time.Minute), result.ExpiresAt)
+	repo.AssertExpectations(t)
+}
+
+func TestAuthorizeOutlook_ConfigError(t *testing.T) {
+	t.Parallel()
+	outlookCfg := new(mockOutlookOAuthCfg)
+	outlookCfg.On("GetOutlookOAuthConfig", mock.Anything).Return(nil, errors.New("EMAIL_OUTLOOK_CLIENT_ID is empty"))
+
+	_, err := newOutlookTestUseCase(new(mockRepo), outlookCfg, nil, nil, time.Now()).AuthorizeOutlook(context.Background(), 1)
+
+	assert.Error(t, err)
+}
+
+func TestCallbackOutlook_NewConnection(t *testing.T) {
+	t.Parallel()
+	repo := new(mockRepo)
+	outlookCfg := new(mockOutlookOAuthCfg)
+	exchanger := new(mockExchanger)
+	profiler := new(mockProfiler)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cfg := testOutlookOAuthConfig()
+	token := testToken()
+
+	repo.On("FindPendingStateByState", mock.Anything, "outlook-state").Return(outlookPendingState(now), nil)
+	repo.On("ConsumePendingState", mock.Anything, uint(7), now).Return(nil)
+	outlookCfg.On("GetOutlookOAuthConfig", mock.Anything).Return(cfg, nil)
+	exchanger.On("Exchange", mock.Anything, cfg, "auth-code").Return(token, nil)
+	profiler.On("GetEmailAddress", mock.Anything, token, cfg).Return(" Billing@Contoso.com ", nil)
+	repo.On("FindCredentialByUserAndAccount", mock.Anything, uint(1), "outlook", "billing@contoso.com").
+		Return(domain.EmailCredential{}, domain.ErrCredentialNotFound)
+	repo.On("CreateCredential", mock.Anything, mock.MatchedBy(func(c domain.EmailCredential) bool {
+		return c.UserID == 1 &&
+			c.Type == "outlook" &&
+			c.GmailAddress == "billing@contoso.com" &&
+			c.AccessToken != "" && c.AccessToken != "access-tok" &&
+			c.RefreshToken != "" && c.RefreshToken != "refresh-tok"
+	})).Return(nil)
+
+	err := newOutlookTestUseCase(repo, outlookCfg, exchanger, profiler, now).CallbackOutlook(context.Background(), 1, "auth-code", "outlook-state")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestCallbackOutlook_RelinkKeepsRefreshTokenWhenNotReturned(t *testing.T) {
+	t.Parallel()
+	repo := new(mockRepo)
+	outlookCfg := new(mockOutlookOAuthCfg)
+	exchanger := new(mockExchanger)
+	profiler := new(mockProfiler)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cfg := testOutlookOAuthConfig()
+	token := &oauth2.Token{AccessToken: "new-access", Expiry: now.Add(time.Hour)}
+	existing := domain.EmailCredential{
+		ID:           42,
+		UserID:       1,
+		Type:         "outlook",
+		GmailAddress: "billing@contoso.com",
+		RefreshToken: "old-encrypted-refresh",
+	}
+
+	repo.On("FindPendingStateByState", mock.Anything, "outlook-state").Return(outlookPendingState(now), nil)
+	repo.On("ConsumePendingState", mock.Anything, uint(7), now).Return(nil)
+	outlookCfg.On("GetOutlookOAuthConfig", mock.Anything).Return(cfg, nil)
+	exchanger.On("Exchange", mock.Anything, cfg, "auth-code").Return(token, nil)
+	profiler.On("GetEmailAddress", mock.Anything, token, cfg).Return("billing@contoso.com", nil)
+	repo.On("FindCredentialByUserAndAccount", mock.Anything, uint(1), "outlook", "billing@contoso.com").Return(existing, nil)
+	repo.On("UpdateCredentialTokens", mock.Anything, mock.MatchedBy(func(c domain.EmailCredential) bool {
+		return c.ID == 42 && c.RefreshToken == "old-encrypted-refresh" && c.AccessToken != "" && c.UpdatedAt.Equal(now)
+	})).Return(nil)
+
+	err := newOutlookTestUseCase(repo, outlookCfg, exchanger, profiler, now).CallbackOutlook(context.Background(), 1, "auth-code", "outlook-state")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestCallbackOutlook_RejectsGmailState(t *testing.T) {
+	t.Parallel()
+	repo := new(mockRepo)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	gmailState := outlookPendingState(now)
+	gmailState.Provider = "gmail"
+
+	repo.On("FindPendingStateByState", mock.Anything, "outlook-state").Return(gmailState, nil)
+
+	err := newOutlookTestUseCase(repo, new(mockOutlookOAuthCfg), nil, nil, now).CallbackOutlook(context.Background(), 1, "auth-code", "outlook-state")
+
+	assert.ErrorIs(t, err, domain.ErrOAuthStateMismatch)
+	repo.AssertNotCalled(t, "ConsumePendingState", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCallbackOutlook_ProviderErrors(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cfg := testOutlookOAuthConfig()
+	token := testToken()
+
+	t.Run("exchange failure", func(t *testing.T) {
+		t.Parallel()
+		repo := new(mockRepo)
+		outlookCfg := new(mockOutlookOAuthCfg)
+		exchanger := new(mockExchanger)
+		repo.On("FindPendingStateByState", mock.Anything, "outlook-state").Return(outlookPendingState(now), nil)
+		repo.On("ConsumePendingState", mock.Anything, uint(7), now).Return(nil)
+		outlookCfg.On("GetOutlookOAuthConfig", mock.Anything).Return(cfg, nil)
+		exchanger.On("Exchange", mock.Anything, cfg, "bad-code").Return(nil, errors.New("invalid_grant"))
+
+		err := newOutlookTestUseCase(repo, outlookCfg, exchanger, nil, now).CallbackOutlook(context.Background(), 1, "bad-code", "outlook-state")
+
+		assert.ErrorIs(t, err, domain.ErrOAuthExchangeFailed)
+	})
+
+	t.Run("profile failure", func(t *testing.T) {
+		t.Parallel()
+		repo := new(mockRepo)
+		outlookCfg := new(mockOutlookOAuthCfg)
+		exchanger := new(mockExchanger)
+		profiler := new(mockProfiler)
+		repo.On("FindPendingStateByState", mock.Anything, "outlook-state").Return(outlookPendingState(now), nil)
+		repo.On("ConsumePendingState", mock.Anything, uint(7), now).Return(nil)
+		outlookCfg.On("GetOutlookOAuthConfig", mock.Anything).Return(cfg, nil)
+		exchanger.On("Exchange", mock.Anything, cfg, "auth-code").Return(token, nil)
+		profiler.On("GetEmailAddress", mock.Anything, token, cfg).Return("", errors.New("graph unavailable"))
+
+		err := newOutlookTestUseCase(repo, outlookCfg, exchanger, profiler, now).CallbackOutlook(context.Background(), 1, "auth-code", "outlook-state")
+
+		assert.ErrorIs(t, err, domain.ErrOutlookProfileFetchFailed)
+		repo.AssertNotCalled(t, "CreateCredential", mock.Anything, mock.Anything)
+	})
+}
@@ -27,11 +27,12 @@ type Repository interface {
 	SavePendingState(ctx context.Context, ps domain.OAuthPendingState) error
 	FindPendingStateByState(ctx context.Context, state string) (domain.OAuthPendingState, error)
 	ConsumePendingState(ctx context.Context, id uint, consumedAt time.Time) error
-	FindCredentialByUserAndGmail(ctx context.Context, userID uint, gmailAddress string) (domain.EmailCredential, error)
+	FindCredentialByUserAndAccount(ctx context.Context, userID uint, provider, accountIdentifier string) (domain.EmailCredential, error)
 	ListCredentialsByUser(ctx context.Context, userID uint) ([]domain.EmailCredential, error)
 	DeleteCredentialByIDAndUser(ctx context.Context, credentialID, userID uint) error
 	CreateCredential(ctx context.Context, cred domain.EmailCredential) error
 	UpdateCredentialTokens(ctx context.Context, cred domain.EmailCredential) error
+	SaveIMAPConnection(ctx context.Context, cred domain.EmailCredential, settings domain.IMAPSettings) (domain.EmailCredential, error)
 }
 
 // OAuthConfigProvider resolves the Gmail OAuth2 config.
@@ -61,6 +62,9 @@ type UseCaseInterface interface {
 	Callback(ctx context.Context, userID uint, code, state string) error
 	ListConnections(ctx context.Context, userID uint) ([]domain.ConnectionView, error)
 	Disconnect(ctx context.Context, userID uint, connectionID uint) error
+	ConnectIMAP(ctx context.Context, userID uint, input IMAPConnectInput) (domain.ConnectionView, error)
+	AuthorizeOutlook(ctx context.Context, userID uint) (AuthorizeResult, error)
+	CallbackOutlook(ctx context.Context, userID uint, code, state string) error
 }
 
 // AuthorizeResult holds the result of the authorize use case.
@@ -71,13 +75,18 @@ type AuthorizeResult struct {
 
 // UseCase implements mail account connection business logic.
 type UseCase struct {
-	repo      Repository
-	oauthCfg  OAuthConfigProvider
-	exchanger OAuthTokenExchanger
-	profiler  GmailProfileFetcher
-	vault     TokenVault
-	clock     timewrapper.ClockInterface
-	log       logger.Interface
+	repo            Repository
+	oauthCfg        OAuthConfigProvider
+	exchanger       OAuthTokenExchanger
+	profiler        GmailProfileFetcher
+	imapLogin       IMAPLoginVerifier
+	outlookCfg      OutlookOAuthConfigProvider
+	outlookProfiler OutlookProfileFetcher
+	vault           TokenVault
+	clock           timewrapper.ClockInterface
+	log             logger.Interface
+	// allowInsecureIMAP accepts IMAP settings without TLS, for local development servers only.
+	allowInsecureIMAP bool
 }
 
 // NewUseCase creates a new UseCase.
@@ -86,6 +95,9 @@ func NewUseCase(
 	oauthCfg OAuthConfigProvider,
 	exchanger OAuthTokenExchanger,
 	profiler GmailProfileFetcher,
+	imapLogin IMAPLoginVerifier,
+	outlookCfg OutlookOAuthConfigProvider,
+	outlookProfiler OutlookProfileFetcher,
 	vault TokenVault,
 	clock timewrapper.ClockInterface,
 	log logger.Interface,
@@ -97,13 +109,16 @@ func NewUseCase(
 		log = logger.NewNop()
 	}
 	return &UseCase{
-		repo:      repo,
-		oauthCfg:  oauthCfg,
-		exchanger: exchanger,
-		profiler:  profiler,
-		vault:     vault,
-		clock:     clock,
-		log:       log.With(logger.Component("mail_account_connection_usecase")),
+		repo:            repo,
+		oauthCfg:        oauthCfg,
+		exchanger:       exchanger,
+		profiler:        profiler,
+		imapLogin:       imapLogin,
+		outlookCfg:      outlookCfg,
+		outlookProfiler: outlookProfiler,
+		vault:           vault,
+		clock:           clock,
+		log:             log.With(logger.Component("mail_account_connection_usecase")),
 	}
 }
 
@@ -120,6 +135,57 @@ func (uc *UseCase) Authorize(ctx context.Context, userID uint) (AuthorizeResult,
 		return AuthorizeResult{}, fmt.Errorf("failed to load oauth config: %w", err)
 	}
 
+	return uc.startOAuth(ctx, reqLog, userID, credentialType, cfg,
+		oauth2.AccessTypeOffline,
+		oauth2.SetAuthURLParam("prompt", "consent"),
+	)
+}
+
+// Callback validates state, exchanges code for tokens, and saves the credential.
+func (uc *UseCase) Callback(ctx context.Context, userID uint, code, state string) error {
+	reqLog := uc.log
+	if l, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = l
+	}
+
+	// 1-2. Validate and consume state
+	now, err := uc.consumePendingState(ctx, reqLog, userID, state, credentialType)
+	if err != nil {
+		return err
+	}
+
+	// 3. Exchange code for token
+	cfg, err := uc.oauthCfg.GetGmailOAuthConfig(ctx)
+	if err != nil {
+		reqLog.Error("oauth_config_load_failed", logger.Err(err))
+		return domain.ErrOAuthExchangeFailed
+	}
+
+	token, err := uc.exchanger.Exchange(ctx, cfg, code)
+	if err != nil {
+		reqLog.Error("oauth_token_exchange_failed", logger.Err(err))
+		return domain.ErrOAuthExchangeFailed
+	}
+
+	// 4. Fetch Gmail address
+	gmailAddr, err := uc.profiler.GetEmailAddress(ctx, token, cfg)
+	if err != nil {
+		reqLog.Error("gmail_profile_fetch_failed", logger.Err(err))
+		return domain.ErrGmailProfileFetchFailed
+	}
+
+	return uc.saveOAuthCredential(ctx, reqLog, userID, credentialType, gmailAddr, token, now)
+}
+
+// startOAuth saves a pending state for the provider and builds its authorization URL.
+func (uc *UseCase) startOAuth(
+	ctx context.Context,
+	reqLog logger.Interface,
+	userID uint,
+	provider string,
+	cfg *oauth2.Config,
+	opts ...oauth2.AuthCodeOption,
+) (AuthorizeResult, error) {
 	stateBytes := make([]byte, oauthStateBytes)
 	if _, err := rand.Read(stateBytes); err != nil {
 		reqLog.Error("state_generation_failed", logger.Err(err))
@@ -132,6 +198,7 @@ func (uc *UseCase) Authorize(ctx context.Context, userID uint) (AuthorizeResult,
 
 	ps := domain.OAuthPendingState{
 		UserID:    userID,
+		Provider:  provider,
 		State:     state,
 		ExpiresAt: expiresAt,
 		CreatedAt: now,
@@ -141,13 +208,11 @@ func (uc *UseCase) Authorize(ctx context.Context, userID uint) (AuthorizeResult,
 		return AuthorizeResult{}, fmt.Errorf("failed to save pending state: %w", err)
 	}
 
-	url := cfg.AuthCodeURL(state,
-		oauth2.AccessTypeOffline,
-		oauth2.SetAuthURLParam("prompt", "consent"),
-	)
+	url := cfg.AuthCodeURL(state, opts...)
 
 	reqLog.Info("oauth_authorize_initiated",
 		logger.UserID(userID),
+		logger.String("provider", provider),
 	)
 
 	return AuthorizeResult{
@@ -156,67 +221,55 @@ func (uc *UseCase) Authorize(ctx context.Context, userID uint) (AuthorizeResult,
 	}, nil
 }
 
-// Callback validates state, exchanges code for tokens, and saves the credential.
-func (uc *UseCase) Callback(ctx context.Context, userID uint, code, state string) error {
-	reqLog := uc.log
-	if l, err := uc.log.WithContext(ctx); err == nil {
-		reqLog = l
-	}
-
-	// 1. Validate state
+// consumePendingState checks that the state was issued to the user for the provider and is still valid,
+// then consumes it so that it cannot be replayed. It returns the current time for the saved credential.
+func (uc *UseCase) consumePendingState(ctx context.Context, reqLog logger.Interface, userID uint, state, provider string) (time.Time, error) {
 	ps, err := uc.repo.FindPendingStateByState(ctx, state)
 	if err != nil {
 		if errors.Is(err, domain.ErrPendingStateNotFound) {
 			reqLog.Info("oauth_state_mismatch", logger.UserID(userID))
-			return domain.ErrOAuthStateMismatch
+			return time.Time{}, domain.ErrOAuthStateMismatch
 		}
 		reqLog.Error("pending_state_lookup_failed", logger.Err(err))
-		return fmt.Errorf("failed to look up pending state: %w", err)
+		return time.Time{}, fmt.Errorf("failed to look up pending state: %w", err)
 	}
-	if ps.UserID != userID {
+	if ps.UserID != userID || ps.Provider != provider {
 		reqLog.Info("oauth_state_mismatch", logger.UserID(userID))
-		return domain.ErrOAuthStateMismatch
+		return time.Time{}, domain.ErrOAuthStateMismatch
 	}
 	if ps.ConsumedAt != nil {
 		reqLog.Info("oauth_state_mismatch", logger.UserID(userID))
-		return domain.ErrOAuthStateMismatch
+		return time.Time{}, domain.ErrOAuthStateMismatch
 	}
 
 	now := uc.clock.Now()
 	if !now.Before(ps.ExpiresAt) {
 		reqLog.Info("oauth_state_expired", logger.UserID(userID))
-		return domain.ErrOAuthStateExpired
+		return time.Time{}, domain.ErrOAuthStateExpired
 	}
 
-	// 2. Consume state
 	if err := uc.repo.ConsumePendingState(ctx, ps.ID, now); err != nil {
 		reqLog.Error("pending_state_consume_failed", logger.Err(err))
-		return fmt.Errorf("failed to consume pending state: %w", err)
-	}
-
-	// 3. Exchange code for token
-	cfg, err := uc.oauthCfg.GetGmailOAuthConfig(ctx)
-	if err != nil {
-		reqLog.Error("oauth_config_load_failed", logger.Err(err))
-		return domain.ErrOAuthExchangeFailed
-	}
-
-	token, err := uc.exchanger.Exchange(ctx, cfg, code)
-	if err != nil {
-		reqLog.Error("oauth_token_exchange_failed", logger.Err(err))
-		return domain.ErrOAuthExchangeFailed
+		return time.Time{}, fmt.Errorf("failed to consume pending state: %w", err)
 	}
+	return now, nil
+}
 
-	// 4. Fetch Gmail address
-	gmailAddr, err := uc.profiler.GetEmailAddress(ctx, token, cfg)
-	if err != nil {
-		reqLog.Error("gmail_profile_fetch_failed", logger.Err(err))
-		return domain.ErrGmailProfileFetchFailed
-	}
-	normalizedAddr := strings.ToLower(strings.TrimSpace(gmailAddr))
+// saveOAuthCredential encrypts the tokens and creates the provider connection for the address,
+// or updates the tokens of the existing one.
+func (uc *UseCase) saveOAuthCredential(
+	ctx context.Context,
+	reqLog logger.Interface,
+	userID uint,
+	provider string,
+	address string,
+	token *oauth2.Token,
+	now time.Time,
+) error {
+	normalizedAddr := strings.ToLower(strings.TrimSpace(address))
 
 	// 5. Check existing credential (distinguish not-found from DB error)
-	existing, err := uc.repo.FindCredentialByUserAndGmail(ctx, userID, normalizedAddr)
+	existing, err := uc.repo.FindCredentialByUserAndAccount(ctx, userID, provider, normalizedAddr)
 	var isNew bool
 	if err != nil {
 		if errors.Is(err, domain.ErrCredentialNotFound) {
@@ -261,7 +314,7 @@ func (uc *UseCase) Callback(ctx context.Context, userID uint, code, state string
 
 		cred := domain.EmailCredential{
 			UserID:             userID,
-			Type:               credentialType,
+			Type:               provider,
 			GmailAddress:       normalizedAddr,
 			KeyVersion:         defaultKeyVer,
 			AccessToken:        encAccess,
@@ -276,9 +329,9 @@ func (uc *UseCase) Callback(ctx context.Context, userID uint, code, state string
 			reqLog.Error("credential_create_failed", logger.Err(err))
 			return fmt.Errorf("failed to create credential: %w", err)
 		}
-		reqLog.Info("gmail_connection_created",
+		reqLog.Info(provider+"_connection_created",
 			logger.UserID(userID),
-			logger.String("provider", credentialType),
+			logger.String("provider", provider),
 		)
 	} else {
 		// 8b. Re-link: update existing credential
@@ -307,9 +360,9 @@ func (uc *UseCase) Callback(ctx context.Context, userID uint, code, state string
 			reqLog.Error("credential_update_failed", logger.Err(err))
 			return fmt.Errorf("failed to update credential: %w", err)
 		}
-		reqLog.Info("gmail_connection_updated",
+		reqLog.Info(provider+"_connection_updated",
 			logger.UserID(userID),
-			logger.String("provider", credentialType),
+			logger.String("provider", provider),
 		)
 	}
 
@@ -37,8 +37,8 @@ func (m *mockRepo) ConsumePendingState(ctx context.Context, id uint, consumedAt
 	return args.Error(0)
 }
 
-func (m *mockRepo) FindCredentialByUserAndGmail(ctx context.Context, userID uint, gmailAddress string) (domain.EmailCredential, error) {
-	args := m.Called(ctx, userID, gmailAddress)
+func (m *mockRepo) FindCredentialByUserAndAccount(ctx context.Context, userID uint, provider, accountIdentifier string) (domain.EmailCredential, error) {
+	args := m.Called(ctx, userID, provider, accountIdentifier)
 	return args.Get(0).(domain.EmailCredential), args.Error(1)
 }
 
@@ -63,6 +63,11 @@ func (m *mockRepo) UpdateCredentialTokens(ctx context.Context, cred domain.Email
 	return args.Error(0)
 }
 
+func (m *mockRepo) SaveIMAPConnection(ctx context.Context, cred domain.EmailCredential, settings domain.IMAPSettings) (domain.EmailCredential, error) {
+	args := m.Called(ctx, cred, settings)
+	return args.Get(0).(domain.EmailCredential), args.Error(1)
+}
+
 type mockOAuthCfg struct {
 	mock.Mock
 }
@@ -155,7 +160,7 @@ func newTestUseCase(
 	clock *fixedClock,
 ) UseCaseInterface {
 	var log logger.Interface = mocklibrary.NewNopLogger()
-	return NewUseCase(repo, oauthCfg, exchanger, profiler, newTestVault(), clock, log)
+	return NewUseCase(repo, oauthCfg, exchanger, profiler, nil, nil, nil, newTestVault(), clock, log)
 }
 
 // --- Authorize tests ---
@@ -168,7 +173,7 @@ func TestAuthorize_Success(t *testing.T) {
 
 	oauthCfg.On("GetGmailOAuthConfig", mock.Anything).Return(testOAuthConfig(), nil)
 	repo.On("SavePendingState", mock.Anything, mock.MatchedBy(func(ps domain.OAuthPendingState) bool {
-		return ps.UserID == 1 && ps.State != "" && ps.ExpiresAt.After(now)
+		return ps.UserID == 1 && ps.Provider == "gmail" && ps.State != "" && ps.ExpiresAt.After(now)
 	})).Return(nil)
 
 	uc := newTestUseCase(repo, oauthCfg, nil, nil, testClock(now))
@@ -190,6 +195,7 @@ func validPendingState(now time.Time) domain.OAuthPendingState {
 	return domain.OAuthPendingState{
 		ID:        1,
 		UserID:    1,
+		Provider:  "gmail",
 		State:     "valid-state",
 		ExpiresAt: now.Add(5 * time.Minute),
 		CreatedAt: now.Add(-5 * time.Minute),
@@ -237,6 +243,7 @@ func TestCallback_StateExpired(t *testing.T) {
 	expired := domain.OAuthPendingState{
 		ID:        1,
 		UserID:    1,
+		Provider:  "gmail",
 		State:     "expired-state",
 		ExpiresAt: now.Add(-1 * time.Minute), // already expired
 		CreatedAt: now.Add(-15 * time.Minute),
@@ -266,7 +273,7 @@ func TestCallback_NewConnection_Success(t *testing.T) {
 	oauthCfg.On("GetGmailOAuthConfig", mock.Anything).Return(cfg, nil)
 	exchanger.On("Exchange", mock.Anything, cfg, "auth-code").Return(tok, nil)
 	profiler.On("GetEmailAddress", mock.Anything, tok, cfg).Return("User@Gmail.com", nil)
-	repo.On("FindCredentialByUserAndGmail", mock.Anything, uint(1), "user@gmail.com").
+	repo.On("FindCredentialByUserAndAccount", mock.Anything, uint(1), "gmail", "user@gmail.com").
 		Return(domain.EmailCredential{}, domain.ErrCredentialNotFound)
 	repo.On("CreateCredential", mock.Anything, mock.MatchedBy(func(c domain.EmailCredential) bool {
 		return c.UserID == 1 &&
@@ -301,7 +308,7 @@ func TestCallback_DifferentGmail_CreatesNew(t *testing.T) {
 	oauthCfg.On("GetGmailOAuthConfig", mock.Anything).Return(cfg, nil)
 	exchanger.On("Exchange", mock.Anything, cfg, "code").Return(tok, nil)
 	profiler.On("GetEmailAddress", mock.Anything, tok, cfg).Return("other@gmail.com", nil)
-	repo.On("FindCredentialByUserAndGmail", mock.Anything, uint(1), "other@gmail.com").
+	repo.On("FindCredentialByUserAndAccount", mock.Anything, uint(1), "gmail", "other@gmail.com").
 		Return(domain.EmailCredential{}, domain.ErrCredentialNotFound)
 	repo.On("CreateCredential", mock.Anything, mock.MatchedBy(func(c domain.EmailCredential) bool {
 		return c.GmailAddress == "other@gmail.com"
@@ -341,7 +348,7 @@ func TestCallback_SameGmail_Updates(t *testing.T) {
 	oauthCfg.On("GetGmailOAuthConfig", mock.Anything).Return(cfg, nil)
 	exchanger.On("Exchange", mock.Anything, cfg, "code").Return(tok, nil)
 	profiler.On("GetEmailAddress", mock.Anything, tok, cfg).Return("user@gmail.com", nil)
-	repo.On("FindCredentialByUserAndGmail", mock.Anything, uint(1), "user@gmail.com").
+	repo.On("FindCredentialByUserAndAccount", mock.Anything, uint(1), "gmail", "user@gmail.com").
 		Return(existing, nil)
 	repo.On("UpdateCredentialTokens", mock.Anything, mock.MatchedBy(func(c domain.EmailCredential) bool {
 		return c.ID == 42 &&
@@ -385,7 +392,7 @@ func TestCallback_SameGmail_NoRefreshToken_KeepsExisting(t *testing.T) {
 	oauthCfg.On("GetGmailOAuthConfig", mock.Anything).Return(cfg, nil)
 	exchanger.On("Exchange", mock.Anything, cfg, "code").Return(tok, nil)
 	profiler.On("GetEmailAddress", mock.Anything, tok, cfg).Return("user@gmail.com", nil)
-	repo.On("FindCredentialByUserAndGmail", mock.Anything, uint(1), "user@gmail.com").
+	repo.On("FindCredentialByUserAndAccount", mock.Anything, uint(1), "gmail", "user@gmail.com").
 		Return(existing, nil)
 	repo.On("UpdateCredentialTokens", mock.Anything, mock.MatchedBy(func(c domain.EmailCredential) bool {
 		// refresh_token should remain the same
@@ -420,7 +427,7 @@ func TestCallback_NewConnection_NoRefreshToken_Fails(t *testing.T) {
 	oauthCfg.On("GetGmailOAuthConfig", mock.Anything).Return(cfg, nil)
 	exchanger.On("Exchange", mock.Anything, cfg, "code").Return(tok, nil)
 	profiler.On("GetEmailAddress", mock.Anything, tok, cfg).Return("new@gmail.com", nil)
-	repo.On("FindCredentialByUserAndGmail", mock.Anything, uint(1), "new@gmail.com").
+	repo.On("FindCredentialByUserAndAccount", mock.Anything, uint(1), "gmail", "new@gmail.com").
 		Return(domain.EmailCredential{}, domain.ErrCredentialNotFound)
 
 	uc := newTestUseCase(repo, oauthCfg, exchanger, profiler, testClock(now))
@@ -467,7 +474,7 @@ func TestCallback_CredentialLookup_DBError(t *testing.T) {
 	oauthCfg.On("GetGmailOAuthConfig", mock.Anything).Return(cfg, nil)
 	exchanger.On("Exchange", mock.Anything, cfg, "code").Return(tok, nil)
 	profiler.On("GetEmailAddress", mock.Anything, tok, cfg).Return("user@gmail.com", nil)
-	repo.On("FindCredentialByUserAndGmail", mock.Anything, uint(1), "user@gmail.com").
+	repo.On("FindCredentialByUserAndAccount", mock.Anything, uint(1), "gmail", "user@gmail.com").
 		Return(domain.EmailCredential{}, errors.New("db connection timeout"))
 
 	uc := newTestUseCase(repo, oauthCfg, exchanger, profiler, testClock(now))
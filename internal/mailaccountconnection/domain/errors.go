@@ -11,12 +11,22 @@ var (
 	ErrOAuthStateMismatch = errors.New("oauth state mismatch")
 	// ErrOAuthStateExpired is returned when the pending state has expired.
 	ErrOAuthStateExpired = errors.New("oauth state expired")
-	// ErrOAuthExchangeFailed is returned when the Google token exchange fails.
-	ErrOAuthExchangeFailed = errors.New("gmail oauth exchange failed")
+	// ErrOAuthExchangeFailed is returned when the provider token exchange fails.
+	ErrOAuthExchangeFailed = errors.New("oauth exchange failed")
 	// ErrGmailProfileFetchFailed is returned when fetching the Gmail profile fails.
 	ErrGmailProfileFetchFailed = errors.New("gmail profile fetch failed")
+	// ErrOutlookProfileFetchFailed is returned when fetching the Outlook mailbox address from Graph fails.
+	ErrOutlookProfileFetchFailed = errors.New("outlook profile fetch failed")
 	// ErrRefreshTokenMissing is returned when a new connection has no refresh token.
 	ErrRefreshTokenMissing = errors.New("refresh token missing for new connection")
 	// ErrVaultEncryptFailed is returned when token encryption fails.
 	ErrVaultEncryptFailed = errors.New("vault encrypt failed")
+	// ErrIMAPSettingsNotFound is returned when an IMAP connection has no stored server settings.
+	ErrIMAPSettingsNotFound = errors.New("imap settings not found")
+	// ErrIMAPSettingsInvalid is returned when the IMAP host, port, security or login is malformed.
+	ErrIMAPSettingsInvalid = errors.New("imap settings are invalid")
+	// ErrIMAPAuthenticationFailed is returned when the IMAP server rejects the login.
+	ErrIMAPAuthenticationFailed = errors.New("imap authentication failed")
+	// ErrIMAPServerUnreachable is returned when the IMAP server cannot be reached.
+	ErrIMAPServerUnreachable = errors.New("imap server unreachable")
 )
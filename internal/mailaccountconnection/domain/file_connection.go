@@ -0,0 +1,9 @@
+package domain
+
+const (
+	// FileConnectionType is the provider of the connection that uploaded .eml and mbox files are imported into.
+	// Each user has at most one, created by the first upload.
+	FileConnectionType = "file"
+	// FileConnectionAccountIdentifier is the account identifier of the file connection.
+	FileConnectionAccountIdentifier = "uploaded-files"
+)
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	// IMAPSecurityTLS connects with implicit TLS.
+	IMAPSecurityTLS = "tls"
+	// IMAPSecurityStartTLS upgrades a plain connection with STARTTLS.
+	IMAPSecurityStartTLS = "starttls"
+	// IMAPSecurityNone sends the login in plain text and is meant for local servers.
+	IMAPSecurityNone = "none"
+)
+
+// IMAPSettings is the server and login of an IMAP connection. Password holds the ciphertext from crypto.Vault.
+type IMAPSettings struct {
+	ID                uint
+	EmailCredentialID uint
+	Host              string
+	Port              int
+	Security          string
+	Username          string
+	Password          string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
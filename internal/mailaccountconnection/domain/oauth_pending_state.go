@@ -3,9 +3,11 @@ package domain
 import "time"
 
 // OAuthPendingState represents a temporary OAuth state for the authorize flow.
+// Provider is the OAuth provider that issued the state, so a callback only accepts its own states.
 type OAuthPendingState struct {
 	ID         uint
 	UserID     uint
+	Provider   string
 	State      string
 	ExpiresAt  time.Time
 	ConsumedAt *time.Time
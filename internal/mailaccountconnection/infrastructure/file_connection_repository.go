@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"business/internal/mailaccountconnection/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// mailImportMessageRecord maps to the mail_import_messages table. Only the owning connection is needed here,
+// to delete the imported messages together with the file connection.
+type mailImportMessageRecord struct {
+	ID                uint64 `gorm:"column:id;primaryKey;autoIncrement"`
+	EmailCredentialID uint   `gorm:"column:email_credential_id;not null"`
+}
+
+func (mailImportMessageRecord) TableName() string {
+	return "mail_import_messages"
+}
+
+// EnsureFileConnection returns the user's file connection, creating it on the first upload.
+func (r *Repository) EnsureFileConnection(ctx context.Context, userID uint, now time.Time) (domain.EmailCredential, error) {
+	existing, err := r.FindCredentialByUserAndAccount(ctx, userID, domain.FileConnectionType, domain.FileConnectionAccountIdentifier)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, domain.ErrCredentialNotFound) {
+		return domain.EmailCredential{}, err
+	}
+
+	rec := toCredentialRecord(domain.EmailCredential{
+		UserID:       userID,
+		Type:         domain.FileConnectionType,
+		GmailAddress: domain.FileConnectionAccountIdentifier,
+		KeyVersion:   1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err := r.db.WithContext(ctx).Create(&rec).Error; err != nil {
+		// A concurrent upload may have created it first; the unique index on user, type and address keeps one row.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return r.FindCredentialByUserAndAccount(ctx, userID, domain.FileConnectionType, domain.FileConnectionAccountIdentifier)
+		}
+		logDBQueryFailed(r.log, "email_credentials", "create_file", err)
+		return domain.EmailCredential{}, fmt.Errorf("failed to create file connection: %w", err)
+	}
+	return toDomainCredential(rec), nil
+}
+
+func (r *Repository) deleteImportedMessages(tx *gorm.DB, credentialID uint) error {
+	if err := tx.Where("email_credential_id = ?", credentialID).Delete(&mailImportMessageRecord{}).Error; err != nil {
+		logDBQueryFailed(r.log, "mail_import_messages", "delete_by_email_credential_id", err)
+		return fmt.Errorf("failed to delete imported messages: %w", err)
+	}
+	return nil
+}
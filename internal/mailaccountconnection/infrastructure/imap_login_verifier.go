@@ -0,0 +1,77 @@
+package infrastructure
+
+import (
+	"business/internal/library/imap"
+	"business/internal/library/logger"
+	"business/internal/mailaccountconnection/application"
+	"business/internal/mailaccountconnection/domain"
+	"context"
+	"errors"
+	"fmt"
+)
+
+type imapDialer interface {
+	Dial(ctx context.Context, cfg imap.Config) (*imap.Session, error)
+}
+
+// IMAPLoginVerifier checks an IMAP login by logging in and out once.
+type IMAPLoginVerifier struct {
+	dialer imapDialer
+	log    logger.Interface
+}
+
+// NewIMAPLoginVerifier creates a new IMAPLoginVerifier.
+func NewIMAPLoginVerifier(dialer imapDialer, log logger.Interface) *IMAPLoginVerifier {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &IMAPLoginVerifier{
+		dialer: dialer,
+		log:    log.With(logger.Component("imap_login_verifier")),
+	}
+}
+
+var _ application.IMAPLoginVerifier = (*IMAPLoginVerifier)(nil)
+
+// VerifyLogin logs in to the IMAP server with the given settings.
+func (v *IMAPLoginVerifier) VerifyLogin(ctx context.Context, input application.IMAPConnectInput) error {
+	reqLog := v.log
+	if l, err := v.log.WithContext(ctx); err == nil {
+		reqLog = l
+	}
+
+	session, err := v.dialer.Dial(ctx, imap.Config{
+		Host:     input.Host,
+		Port:     input.Port,
+		Security: input.Security,
+		Username: input.Username,
+		Password: input.Password,
+	})
+	if err != nil {
+		reqLog.Warn("external_api_failed",
+			logger.String("provider", "imap"),
+			logger.String("operation", "login"),
+			logger.String("imap_host", input.Host),
+			logger.Err(err),
+		)
+		switch {
+		case errors.Is(err, imap.ErrAuthenticationFailed):
+			return fmt.Errorf("%w: %v", domain.ErrIMAPAuthenticationFailed, err)
+		case errors.Is(err, imap.ErrConnectionFailed):
+			return fmt.Errorf("%w: %v", domain.ErrIMAPServerUnreachable, err)
+		default:
+			return fmt.Errorf("failed to log in to imap server: %w", err)
+		}
+	}
+
+	if err := session.Logout(ctx); err != nil {
+		reqLog.Warn("imap_logout_failed", logger.Err(err))
+	}
+
+	reqLog.Info("external_api_succeeded",
+		logger.String("provider", "imap"),
+		logger.String("operation", "login"),
+		logger.String("imap_host", input.Host),
+	)
+	return nil
+}
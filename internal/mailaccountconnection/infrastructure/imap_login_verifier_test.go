@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"business/internal/library/imap"
+	"business/internal/library/imap/imaptest"
+	"business/internal/mailaccountconnection/application"
+	"business/internal/mailaccountconnection/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIMAPLoginVerifier_VerifyLogin(t *testing.T) {
+	t.Parallel()
+
+	server, err := imaptest.NewServer("billing@example.com", "secret", map[string]imaptest.Mailbox{})
+	require.NoError(t, err)
+	defer server.Close()
+
+	verifier := NewIMAPLoginVerifier(imap.NewDialer(time.Second, nil).WithPrivateAddresses(true), nil)
+	input := application.IMAPConnectInput{
+		Host:     server.Host(),
+		Port:     server.Port(),
+		Security: domain.IMAPSecurityNone,
+		Username: "billing@example.com",
+		Password: "secret",
+	}
+
+	t.Run("accepts a valid login", func(t *testing.T) {
+		assert.NoError(t, verifier.VerifyLogin(context.Background(), input))
+	})
+
+	t.Run("maps a rejected login to ErrIMAPAuthenticationFailed", func(t *testing.T) {
+		wrong := input
+		wrong.Password = "wrong"
+
+		err := verifier.VerifyLogin(context.Background(), wrong)
+
+		assert.ErrorIs(t, err, domain.ErrIMAPAuthenticationFailed)
+	})
+
+	t.Run("maps an unreachable server to ErrIMAPServerUnreachable", func(t *testing.T) {
+		unreachable := input
+		unreachable.Port = 1
+
+		err := verifier.VerifyLogin(context.Background(), unreachable)
+
+		assert.ErrorIs(t, err, domain.ErrIMAPServerUnreachable)
+	})
+}
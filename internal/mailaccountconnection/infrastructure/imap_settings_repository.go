@@ -0,0 +1,130 @@
+package infrastructure
+
+import (
+	"business/internal/mailaccountconnection/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// imapSettingsRecord maps to the imap_connection_settings table, one row per IMAP email_credentials row.
+type imapSettingsRecord struct {
+	ID                uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	EmailCredentialID uint      `gorm:"column:email_credential_id;not null;uniqueIndex:uni_imap_connection_settings_email_credential_id"`
+	Host              string    `gorm:"column:host;size:255;not null"`
+	Port              int       `gorm:"column:port;not null"`
+	Security          string    `gorm:"column:security;size:16;not null"`
+	Username          string    `gorm:"column:username;size:255;not null"`
+	Password          string    `gorm:"column:password;type:text;not null"`
+	CreatedAt         time.Time `gorm:"column:created_at;not null"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;not null"`
+}
+
+func (imapSettingsRecord) TableName() string {
+	return "imap_connection_settings"
+}
+
+// SaveIMAPConnection creates the IMAP connection of cred.GmailAddress, or replaces the settings of the
+// user's existing connection for the same account, and returns the stored connection.
+func (r *Repository) SaveIMAPConnection(ctx context.Context, cred domain.EmailCredential, settings domain.IMAPSettings) (domain.EmailCredential, error) {
+	var saved credentialRecord
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND type = ? AND gmail_address = ? AND o_auth_state IS NULL", cred.UserID, cred.Type, cred.GmailAddress).
+			First(&saved).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			saved = toCredentialRecord(cred)
+			if err := tx.Create(&saved).Error; err != nil {
+				logDBQueryFailed(r.log, "email_credentials", "create_imap", err)
+				return fmt.Errorf("failed to create imap credential: %w", err)
+			}
+		case err != nil:
+			logDBQueryFailed(r.log, "email_credentials", "find_imap", err)
+			return fmt.Errorf("failed to find imap credential: %w", err)
+		default:
+			if err := tx.Model(&saved).Update("updated_at", cred.UpdatedAt).Error; err != nil {
+				logDBQueryFailed(r.log, "email_credentials", "touch_imap", err)
+				return fmt.Errorf("failed to update imap credential: %w", err)
+			}
+		}
+
+		var existing imapSettingsRecord
+		err = tx.Where("email_credential_id = ?", saved.ID).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			rec := toIMAPSettingsRecord(settings)
+			rec.EmailCredentialID = saved.ID
+			if err := tx.Create(&rec).Error; err != nil {
+				logDBQueryFailed(r.log, "imap_connection_settings", "create", err)
+				return fmt.Errorf("failed to create imap settings: %w", err)
+			}
+		case err != nil:
+			logDBQueryFailed(r.log, "imap_connection_settings", "find_by_email_credential_id", err)
+			return fmt.Errorf("failed to find imap settings: %w", err)
+		default:
+			if err := tx.Model(&existing).Updates(map[string]interface{}{
+				"host":       settings.Host,
+				"port":       settings.Port,
+				"security":   settings.Security,
+				"username":   settings.Username,
+				"password":   settings.Password,
+				"updated_at": settings.UpdatedAt,
+			}).Error; err != nil {
+				logDBQueryFailed(r.log, "imap_connection_settings", "update", err)
+				return fmt.Errorf("failed to update imap settings: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.EmailCredential{}, err
+	}
+	return toDomainCredential(saved), nil
+}
+
+// FindIMAPSettingsByCredentialID returns the server settings of an IMAP connection.
+func (r *Repository) FindIMAPSettingsByCredentialID(ctx context.Context, credentialID uint) (domain.IMAPSettings, error) {
+	var rec imapSettingsRecord
+	err := r.db.WithContext(ctx).
+		Where("email_credential_id = ?", credentialID).
+		First(&rec).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.IMAPSettings{}, domain.ErrIMAPSettingsNotFound
+		}
+		logDBQueryFailed(r.log, "imap_connection_settings", "find_by_email_credential_id", err)
+		return domain.IMAPSettings{}, fmt.Errorf("failed to find imap settings: %w", err)
+	}
+	return toDomainIMAPSettings(rec), nil
+}
+
+func toIMAPSettingsRecord(settings domain.IMAPSettings) imapSettingsRecord {
+	return imapSettingsRecord{
+		ID:                settings.ID,
+		EmailCredentialID: settings.EmailCredentialID,
+		Host:              settings.Host,
+		Port:              settings.Port,
+		Security:          settings.Security,
+		Username:          settings.Username,
+		Password:          settings.Password,
+		CreatedAt:         settings.CreatedAt,
+		UpdatedAt:         settings.UpdatedAt,
+	}
+}
+
+func toDomainIMAPSettings(rec imapSettingsRecord) domain.IMAPSettings {
+	return domain.IMAPSettings{
+		ID:                rec.ID,
+		EmailCredentialID: rec.EmailCredentialID,
+		Host:              rec.Host,
+		Port:              rec.Port,
+		Security:          rec.Security,
+		Username:          rec.Username,
+		Password:          rec.Password,
+		CreatedAt:         rec.CreatedAt,
+		UpdatedAt:         rec.UpdatedAt,
+	}
+}
@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/msgraph"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// OutlookProfileFetcher fetches the mailbox address of the signed-in Microsoft account from Graph.
+type OutlookProfileFetcher struct {
+	graph *msgraph.ClientFactory
+	log   logger.Interface
+}
+
+// NewOutlookProfileFetcher creates a new OutlookProfileFetcher.
+func NewOutlookProfileFetcher(graph *msgraph.ClientFactory, log logger.Interface) *OutlookProfileFetcher {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &OutlookProfileFetcher{
+		graph: graph,
+		log:   log.With(logger.Component("outlook_profile_fetcher")),
+	}
+}
+
+// GetEmailAddress returns the user's mail address. Personal Outlook.com accounts may have no mail
+// property, so the user principal name, which is their sign-in address, is used instead.
+func (f *OutlookProfileFetcher) GetEmailAddress(ctx context.Context, token *oauth2.Token, cfg *oauth2.Config) (string, error) {
+	reqLog := f.log
+	if l, err := f.log.WithContext(ctx); err == nil {
+		reqLog = l
+	}
+
+	user, err := f.graph.NewClient(cfg.Client(ctx, token)).GetMe(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch outlook profile: %w", err)
+	}
+
+	address := strings.TrimSpace(user.Mail)
+	if address == "" {
+		address = strings.TrimSpace(user.UserPrincipalName)
+	}
+	if address == "" {
+		return "", errors.New("outlook profile has no mail address")
+	}
+
+	reqLog.Info("external_api_succeeded",
+		logger.String("provider", "outlook"),
+		logger.String("operation", "get_me"),
+	)
+
+	return address, nil
+}
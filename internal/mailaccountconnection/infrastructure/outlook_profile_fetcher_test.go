@@ -0,0 +1,48 @@
+package infrastructure
+
+import (
+	"business/internal/library/msgraph"
+	"business/internal/library/msgraph/graphtest"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestOutlookProfileFetcher_GetEmailAddress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		user     msgraph.User
+		expected string
+		wantErr  bool
+	}{
+		{name: "uses mail", user: msgraph.User{Mail: "billing@contoso.com", UserPrincipalName: "billing@contoso.onmicrosoft.com"}, expected: "billing@contoso.com"},
+		{name: "falls back to the user principal name", user: msgraph.User{UserPrincipalName: "someone@outlook.com"}, expected: "someone@outlook.com"},
+		{name: "fails without an address", user: msgraph.User{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := graphtest.NewServer(tt.user, "access-0", "refresh-0", nil)
+			t.Cleanup(server.Close)
+
+			fetcher := NewOutlookProfileFetcher(msgraph.NewClientFactory(server.BaseURL(), nil, nil), nil)
+			cfg := &oauth2.Config{ClientID: "client-id", Endpoint: oauth2.Endpoint{TokenURL: server.TokenURL()}}
+			token := &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh-0", Expiry: time.Now().Add(time.Hour)}
+
+			address, err := fetcher.GetEmailAddress(context.Background(), token, cfg)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, address)
+		})
+	}
+}
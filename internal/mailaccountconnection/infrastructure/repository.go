@@ -57,9 +57,13 @@ func (credentialRecord) TableName() string {
 }
 
 func (r *Repository) SavePendingState(ctx context.Context, ps domain.OAuthPendingState) error {
+	provider := strings.ToLower(strings.TrimSpace(ps.Provider))
+	if provider == "" {
+		provider = "gmail"
+	}
 	rec := credentialRecord{
 		UserID:              ps.UserID,
-		Type:                "gmail",
+		Type:                provider,
 		GmailAddress:        pendingGmailAddressForState(ps.State),
 		KeyVersion:          1,
 		AccessToken:         "",
@@ -96,6 +100,7 @@ func (r *Repository) FindPendingStateByState(ctx context.Context, state string)
 	return domain.OAuthPendingState{
 		ID:        rec.ID,
 		UserID:    rec.UserID,
+		Provider:  rec.Type,
 		State:     *rec.OAuthState,
 		ExpiresAt: *rec.OAuthStateExpiresAt,
 		CreatedAt: rec.CreatedAt,
@@ -116,17 +121,18 @@ func (r *Repository) ConsumePendingState(ctx context.Context, id uint, consumedA
 	return nil
 }
 
-func (r *Repository) FindCredentialByUserAndGmail(ctx context.Context, userID uint, gmailAddress string) (domain.EmailCredential, error) {
-	normalized := strings.ToLower(strings.TrimSpace(gmailAddress))
+// FindCredentialByUserAndAccount returns the user's connection of the provider for the given mail address.
+func (r *Repository) FindCredentialByUserAndAccount(ctx context.Context, userID uint, provider, accountIdentifier string) (domain.EmailCredential, error) {
+	normalized := strings.ToLower(strings.TrimSpace(accountIdentifier))
 	var rec credentialRecord
 	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND type = ? AND gmail_address = ? AND o_auth_state IS NULL", userID, "gmail", normalized).
+		Where("user_id = ? AND type = ? AND gmail_address = ? AND o_auth_state IS NULL", userID, strings.ToLower(strings.TrimSpace(provider)), normalized).
 		First(&rec).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return domain.EmailCredential{}, domain.ErrCredentialNotFound
 		}
-		logDBQueryFailed(r.log, "email_credentials", "find_by_user_gmail", err)
+		logDBQueryFailed(r.log, "email_credentials", "find_by_user_account", err)
 		return domain.EmailCredential{}, fmt.Errorf("failed to find credential: %w", err)
 	}
 	return toDomainCredential(rec), nil
@@ -165,18 +171,26 @@ func (r *Repository) ListCredentialsByUser(ctx context.Context, userID uint) ([]
 	return credentials, nil
 }
 
+// DeleteCredentialByIDAndUser deletes the connection together with its IMAP settings or imported messages, if any.
 func (r *Repository) DeleteCredentialByIDAndUser(ctx context.Context, credentialID, userID uint) error {
-	result := r.db.WithContext(ctx).
-		Where("id = ? AND user_id = ? AND o_auth_state IS NULL", credentialID, userID).
-		Delete(&credentialRecord{})
-	if result.Error != nil {
-		logDBQueryFailed(r.log, "email_credentials", "delete_by_id_and_user", result.Error)
-		return fmt.Errorf("failed to delete credential: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return domain.ErrCredentialNotFound
-	}
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Where("id = ? AND user_id = ? AND o_auth_state IS NULL", credentialID, userID).
+			Delete(&credentialRecord{})
+		if result.Error != nil {
+			logDBQueryFailed(r.log, "email_credentials", "delete_by_id_and_user", result.Error)
+			return fmt.Errorf("failed to delete credential: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrCredentialNotFound
+		}
+
+		if err := tx.Where("email_credential_id = ?", credentialID).Delete(&imapSettingsRecord{}).Error; err != nil {
+			logDBQueryFailed(r.log, "imap_connection_settings", "delete_by_email_credential_id", err)
+			return fmt.Errorf("failed to delete imap settings: %w", err)
+		}
+		return r.deleteImportedMessages(tx, credentialID)
+	})
 }
 
 func (r *Repository) CreateCredential(ctx context.Context, cred domain.EmailCredential) error {
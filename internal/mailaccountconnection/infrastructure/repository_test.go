@@ -30,7 +30,7 @@ func newRepoTestEnv(t *testing.T) *repoTestEnv {
 	}
 	require.NoError(t, err)
 
-	err = mysqlConn.DB.AutoMigrate(&credentialRecord{})
+	err = mysqlConn.DB.AutoMigrate(&credentialRecord{}, &imapSettingsRecord{}, &mailImportMessageRecord{})
 	require.NoError(t, err)
 
 	return &repoTestEnv{
@@ -76,6 +76,7 @@ func TestSavePendingState_And_FindByState(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, uint(1), found.UserID)
 	assert.Equal(t, "test-state-123", found.State)
+	assert.Equal(t, "gmail", found.Provider)
 	assert.Nil(t, found.ConsumedAt)
 
 	var stored credentialRecord
@@ -87,6 +88,24 @@ func TestSavePendingState_And_FindByState(t *testing.T) {
 	assert.Equal(t, pendingStatePlaceholder("test-state-123"), stored.GmailAddress)
 }
 
+func TestSavePendingState_KeepsProvider(t *testing.T) {
+	env := newRepoTestEnv(t)
+	defer env.clean()
+	ctx := context.Background()
+
+	require.NoError(t, env.repo.SavePendingState(ctx, domain.OAuthPendingState{
+		UserID:    1,
+		Provider:  "outlook",
+		State:     "outlook-state",
+		ExpiresAt: env.nowUTC.Add(10 * time.Minute),
+		CreatedAt: env.nowUTC,
+	}))
+
+	found, err := env.repo.FindPendingStateByState(ctx, "outlook-state")
+	require.NoError(t, err)
+	assert.Equal(t, "outlook", found.Provider)
+}
+
 func TestFindPendingStateByState_NotFound(t *testing.T) {
 	env := newRepoTestEnv(t)
 	defer env.clean()
@@ -147,23 +166,49 @@ func TestCreateCredential_And_FindByUserAndGmail(t *testing.T) {
 	err := env.repo.CreateCredential(ctx, cred)
 	require.NoError(t, err)
 
-	found, err := env.repo.FindCredentialByUserAndGmail(ctx, 1, "user@gmail.com")
+	found, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "user@gmail.com")
 	require.NoError(t, err)
 	assert.Equal(t, "user@gmail.com", found.GmailAddress)
 	assert.Equal(t, "enc-access", found.AccessToken)
 	assert.Equal(t, "enc-refresh", found.RefreshToken)
 }
 
-func TestFindCredentialByUserAndGmail_NotFound(t *testing.T) {
+func TestFindCredentialByUserAndAccount_NotFound(t *testing.T) {
 	env := newRepoTestEnv(t)
 	defer env.clean()
 	ctx := context.Background()
 
-	_, err := env.repo.FindCredentialByUserAndGmail(ctx, 999, "nope@gmail.com")
+	_, err := env.repo.FindCredentialByUserAndAccount(ctx, 999, "gmail", "nope@gmail.com")
 	assert.ErrorIs(t, err, domain.ErrCredentialNotFound)
 }
 
-func TestFindCredentialByUserAndGmail_NormalizesAddress(t *testing.T) {
+func TestFindCredentialByUserAndAccount_SeparatesProviders(t *testing.T) {
+	env := newRepoTestEnv(t)
+	defer env.clean()
+	ctx := context.Background()
+
+	require.NoError(t, env.repo.CreateCredential(ctx, domain.EmailCredential{
+		UserID:             1,
+		Type:               "outlook",
+		GmailAddress:       "user@contoso.com",
+		KeyVersion:         1,
+		AccessToken:        "a",
+		AccessTokenDigest:  "a",
+		RefreshToken:       "r",
+		RefreshTokenDigest: "r",
+		CreatedAt:          env.nowUTC,
+		UpdatedAt:          env.nowUTC,
+	}))
+
+	found, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "outlook", "user@contoso.com")
+	require.NoError(t, err)
+	assert.Equal(t, "outlook", found.Type)
+
+	_, err = env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "user@contoso.com")
+	assert.ErrorIs(t, err, domain.ErrCredentialNotFound)
+}
+
+func TestFindCredentialByUserAndAccount_NormalizesAddress(t *testing.T) {
 	env := newRepoTestEnv(t)
 	defer env.clean()
 	ctx := context.Background()
@@ -183,7 +228,7 @@ func TestFindCredentialByUserAndGmail_NormalizesAddress(t *testing.T) {
 	require.NoError(t, env.repo.CreateCredential(ctx, cred))
 
 	// Query with uppercase should still find it
-	found, err := env.repo.FindCredentialByUserAndGmail(ctx, 1, "USER@GMAIL.COM")
+	found, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "USER@GMAIL.COM")
 	require.NoError(t, err)
 	assert.Equal(t, "user@gmail.com", found.GmailAddress)
 }
@@ -207,7 +252,7 @@ func TestFindCredentialByIDAndUser(t *testing.T) {
 	}
 	require.NoError(t, env.repo.CreateCredential(ctx, cred))
 
-	foundByMail, err := env.repo.FindCredentialByUserAndGmail(ctx, 1, "id-check@gmail.com")
+	foundByMail, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "id-check@gmail.com")
 	require.NoError(t, err)
 
 	foundByID, err := env.repo.FindCredentialByIDAndUser(ctx, foundByMail.ID, 1)
@@ -238,7 +283,7 @@ func TestUpdateCredentialTokens(t *testing.T) {
 	}
 	require.NoError(t, env.repo.CreateCredential(ctx, cred))
 
-	found, err := env.repo.FindCredentialByUserAndGmail(ctx, 1, "update@gmail.com")
+	found, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "update@gmail.com")
 	require.NoError(t, err)
 
 	newExpiry := env.nowUTC.Add(1 * time.Hour)
@@ -252,7 +297,7 @@ func TestUpdateCredentialTokens(t *testing.T) {
 	err = env.repo.UpdateCredentialTokens(ctx, found)
 	require.NoError(t, err)
 
-	updated, err := env.repo.FindCredentialByUserAndGmail(ctx, 1, "update@gmail.com")
+	updated, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "update@gmail.com")
 	require.NoError(t, err)
 	assert.Equal(t, "new-access", updated.AccessToken)
 	assert.Equal(t, "new-refresh", updated.RefreshToken)
@@ -343,13 +388,13 @@ func TestDeleteCredentialByIDAndUser_Success(t *testing.T) {
 	}
 	require.NoError(t, env.repo.CreateCredential(ctx, cred))
 
-	found, err := env.repo.FindCredentialByUserAndGmail(ctx, 1, "delete-me@gmail.com")
+	found, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "delete-me@gmail.com")
 	require.NoError(t, err)
 
 	err = env.repo.DeleteCredentialByIDAndUser(ctx, found.ID, 1)
 	require.NoError(t, err)
 
-	_, err = env.repo.FindCredentialByUserAndGmail(ctx, 1, "delete-me@gmail.com")
+	_, err = env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "delete-me@gmail.com")
 	assert.ErrorIs(t, err, domain.ErrCredentialNotFound)
 }
 
@@ -372,13 +417,13 @@ func TestDeleteCredentialByIDAndUser_OtherUserCannotDelete(t *testing.T) {
 	}
 	require.NoError(t, env.repo.CreateCredential(ctx, cred))
 
-	found, err := env.repo.FindCredentialByUserAndGmail(ctx, 1, "owner@gmail.com")
+	found, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "owner@gmail.com")
 	require.NoError(t, err)
 
 	err = env.repo.DeleteCredentialByIDAndUser(ctx, found.ID, 2)
 	assert.ErrorIs(t, err, domain.ErrCredentialNotFound)
 
-	stillExists, err := env.repo.FindCredentialByUserAndGmail(ctx, 1, "owner@gmail.com")
+	stillExists, err := env.repo.FindCredentialByUserAndAccount(ctx, 1, "gmail", "owner@gmail.com")
 	require.NoError(t, err)
 	assert.Equal(t, found.ID, stillExists.ID)
 }
@@ -411,3 +456,114 @@ func TestDeleteCredentialByIDAndUser_PendingStateNotDeletableAsConnection(t *tes
 	err = env.repo.DeleteCredentialByIDAndUser(ctx, pending.ID, 1)
 	assert.ErrorIs(t, err, domain.ErrCredentialNotFound)
 }
+
+// --- IMAP connection tests ---
+
+func testIMAPConnection(now time.Time, password string) (domain.EmailCredential, domain.IMAPSettings) {
+	return domain.EmailCredential{
+		UserID:       1,
+		Type:         "imap",
+		GmailAddress: "billing@example.com",
+		KeyVersion:   1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, domain.IMAPSettings{
+		Host:      "imap.example.com",
+		Port:      993,
+		Security:  "tls",
+		Username:  "billing@example.com",
+		Password:  password,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TestSaveIMAPConnection_CreatesThenReplacesSettings(t *testing.T) {
+	env := newRepoTestEnv(t)
+	defer env.clean()
+	ctx := context.Background()
+
+	cred, settings := testIMAPConnection(env.nowUTC, "enc-password-1")
+	created, err := env.repo.SaveIMAPConnection(ctx, cred, settings)
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	assert.Equal(t, "imap", created.Type)
+
+	later := env.nowUTC.Add(time.Hour)
+	cred, settings = testIMAPConnection(later, "enc-password-2")
+	settings.Host = "mail.example.com"
+	updated, err := env.repo.SaveIMAPConnection(ctx, cred, settings)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+
+	credentials, err := env.repo.ListCredentialsByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	assert.True(t, credentials[0].UpdatedAt.Equal(later))
+
+	stored, err := env.repo.FindIMAPSettingsByCredentialID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "mail.example.com", stored.Host)
+	assert.Equal(t, "enc-password-2", stored.Password)
+	assert.True(t, stored.CreatedAt.Equal(env.nowUTC))
+}
+
+func TestFindIMAPSettingsByCredentialID_NotFound(t *testing.T) {
+	env := newRepoTestEnv(t)
+	defer env.clean()
+
+	_, err := env.repo.FindIMAPSettingsByCredentialID(context.Background(), 9999)
+	assert.ErrorIs(t, err, domain.ErrIMAPSettingsNotFound)
+}
+
+func TestDeleteCredentialByIDAndUser_DeletesIMAPSettings(t *testing.T) {
+	env := newRepoTestEnv(t)
+	defer env.clean()
+	ctx := context.Background()
+
+	cred, settings := testIMAPConnection(env.nowUTC, "enc-password")
+	created, err := env.repo.SaveIMAPConnection(ctx, cred, settings)
+	require.NoError(t, err)
+
+	require.NoError(t, env.repo.DeleteCredentialByIDAndUser(ctx, created.ID, 1))
+
+	_, err = env.repo.FindIMAPSettingsByCredentialID(ctx, created.ID)
+	assert.ErrorIs(t, err, domain.ErrIMAPSettingsNotFound)
+}
+
+// --- File connection tests ---
+
+func TestEnsureFileConnection_CreatesOncePerUser(t *testing.T) {
+	env := newRepoTestEnv(t)
+	defer env.clean()
+	ctx := context.Background()
+
+	created, err := env.repo.EnsureFileConnection(ctx, 1, env.nowUTC)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FileConnectionType, created.Type)
+	assert.Equal(t, domain.FileConnectionAccountIdentifier, created.GmailAddress)
+
+	again, err := env.repo.EnsureFileConnection(ctx, 1, env.nowUTC.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, again.ID)
+
+	other, err := env.repo.EnsureFileConnection(ctx, 2, env.nowUTC)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.ID, other.ID)
+}
+
+func TestDeleteCredentialByIDAndUser_DeletesImportedMessages(t *testing.T) {
+	env := newRepoTestEnv(t)
+	defer env.clean()
+	ctx := context.Background()
+
+	created, err := env.repo.EnsureFileConnection(ctx, 1, env.nowUTC)
+	require.NoError(t, err)
+	require.NoError(t, env.db.Create(&mailImportMessageRecord{EmailCredentialID: created.ID}).Error)
+
+	require.NoError(t, env.repo.DeleteCredentialByIDAndUser(ctx, created.ID, 1))
+
+	var remaining int64
+	require.NoError(t, env.db.Model(&mailImportMessageRecord{}).Where("email_credential_id = ?", created.ID).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
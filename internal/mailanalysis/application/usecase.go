@@ -45,6 +45,27 @@ type EmailForAnalysisTarget struct {
 	ReceivedAt        time.Time
 	Body              string
 	BodyDigest        string
+	// Attachments は本文とは別の節として analyzer に渡す添付ファイルのテキスト。
+	Attachments []AttachmentForAnalysis
+}
+
+// AttachmentForAnalysis は添付ファイルから抽出したテキスト。
+// FailureReason が入っている場合は Text が空で、読み取れなかったことだけを analyzer に伝える。
+type AttachmentForAnalysis struct {
+	Filename      string
+	Text          string
+	Truncated     bool
+	FailureReason string
+}
+
+// hasAttachmentText は本文の代わりに解析できる添付ファイルのテキストがあるかを返す。
+func (e EmailForAnalysisTarget) hasAttachmentText() bool {
+	for _, attachment := range e.Attachments {
+		if strings.TrimSpace(attachment.Text) != "" {
+			return true
+		}
+	}
+	return false
 }
 
 // Normalize は文字列項目と宛先一覧を整形する。
@@ -65,6 +86,17 @@ func (e EmailForAnalysisTarget) Normalize() EmailForAnalysisTarget {
 	}
 	e.To = recipients
 
+	if len(e.Attachments) > 0 {
+		attachments := make([]AttachmentForAnalysis, 0, len(e.Attachments))
+		for _, attachment := range e.Attachments {
+			attachment.Filename = strings.TrimSpace(attachment.Filename)
+			attachment.Text = strings.TrimSpace(attachment.Text)
+			attachment.FailureReason = strings.TrimSpace(attachment.FailureReason)
+			attachments = append(attachments, attachment)
+		}
+		e.Attachments = attachments
+	}
+
 	return e
 }
 
@@ -73,10 +105,16 @@ func (e EmailForAnalysisTarget) Validate() error {
 	if e.EmailID == 0 {
 		return fmt.Errorf("%w: email_id is required", domain.ErrEmailForAnalysisInvalid)
 	}
+	return e.validatePayload()
+}
+
+// validatePayload は未保存メールでも必要な解析入力の不変条件を検証する。
+func (e EmailForAnalysisTarget) validatePayload() error {
 	if strings.TrimSpace(e.ExternalMessageID) == "" {
 		return fmt.Errorf("%w: external_message_id is required", domain.ErrEmailForAnalysisInvalid)
 	}
-	if strings.TrimSpace(e.Body) == "" {
+	// 本文が空でも、添付ファイルの請求書だけで解析できる。
+	if strings.TrimSpace(e.Body) == "" && !e.hasAttachmentText() {
 		return fmt.Errorf("%w: body is required", domain.ErrEmailForAnalysisInvalid)
 	}
 	return nil
@@ -86,6 +124,9 @@ func (e EmailForAnalysisTarget) Validate() error {
 type Command struct {
 	UserID uint
 	Emails []EmailForAnalysisTarget
+	// DryRun は ParsedEmail を保存せず、解析結果だけを ParsedEmailID なしで返す。
+	// 未保存メールを受け取るため EmailID の必須チェックも行わない。
+	DryRun bool
 }
 
 // ParsedEmailResultItem は保存済み ParsedEmail と source email の必要情報をまとめたもの。
@@ -165,7 +206,7 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 	}
 
 	result := Result{}
-	validEmails, failures := normalizeAndValidateEmails(cmd.Emails)
+	validEmails, failures := normalizeAndValidateEmails(cmd.Emails, cmd.DryRun)
 	result.Failures = append(result.Failures, failures...)
 
 	if len(validEmails) == 0 {
@@ -215,6 +256,14 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 			continue
 		}
 
+		if cmd.DryRun {
+			for _, parsedEmail := range output.ParsedEmails {
+				result.ParsedEmails = append(result.ParsedEmails, newParsedEmailResultItem(0, email, parsedEmail))
+			}
+			result.ParsedEmailCount += len(output.ParsedEmails)
+			continue
+		}
+
 		records, err := uc.repository.SaveAll(ctx, domain.SaveInput{
 			UserID:        cmd.UserID,
 			EmailID:       email.EmailID,
@@ -252,16 +301,8 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 				)
 				break
 			}
-			result.ParsedEmails = append(result.ParsedEmails, ParsedEmailResultItem{
-				ParsedEmailID:     record.ID,
-				EmailID:           record.EmailID,
-				ExternalMessageID: email.ExternalMessageID,
-				Subject:           email.Subject,
-				From:              email.From,
-				To:                append([]string(nil), email.To...),
-				BodyDigest:        email.BodyDigest,
-				ParsedEmail:       output.ParsedEmails[idx],
-			})
+			email.EmailID = record.EmailID
+			result.ParsedEmails = append(result.ParsedEmails, newParsedEmailResultItem(record.ID, email, output.ParsedEmails[idx]))
 		}
 		result.ParsedEmailCount += len(records)
 	}
@@ -276,12 +317,16 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 	return result, nil
 }
 
-func normalizeAndValidateEmails(emails []EmailForAnalysisTarget) ([]EmailForAnalysisTarget, []domain.MessageFailure) {
+func normalizeAndValidateEmails(emails []EmailForAnalysisTarget, dryRun bool) ([]EmailForAnalysisTarget, []domain.MessageFailure) {
 	validEmails := make([]EmailForAnalysisTarget, 0, len(emails))
 	failures := make([]domain.MessageFailure, 0)
 	for _, email := range emails {
 		email = email.Normalize()
-		if err := email.Validate(); err != nil {
+		validate := email.Validate
+		if dryRun {
+			validate = email.validatePayload
+		}
+		if err := validate(); err != nil {
 			failures = append(failures, domain.MessageFailure{
 				EmailID:           email.EmailID,
 				ExternalMessageID: email.ExternalMessageID,
@@ -296,6 +341,19 @@ func normalizeAndValidateEmails(emails []EmailForAnalysisTarget) ([]EmailForAnal
 	return validEmails, failures
 }
 
+func newParsedEmailResultItem(parsedEmailID uint, email EmailForAnalysisTarget, parsedEmail commondomain.ParsedEmail) ParsedEmailResultItem {
+	return ParsedEmailResultItem{
+		ParsedEmailID:     parsedEmailID,
+		EmailID:           email.EmailID,
+		ExternalMessageID: email.ExternalMessageID,
+		Subject:           email.Subject,
+		From:              email.From,
+		To:                append([]string(nil), email.To...),
+		BodyDigest:        email.BodyDigest,
+		ParsedEmail:       parsedEmail,
+	}
+}
+
 func analyzeEmailsConcurrently(ctx context.Context, analyzer Analyzer, emails []EmailForAnalysisTarget) []analysisExecutionResult {
 	results := make([]analysisExecutionResult, len(emails))
 
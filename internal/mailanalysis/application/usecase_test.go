@@ -456,6 +456,60 @@ func TestUseCaseExecute_DependencyMissingReturnsError(t *testing.T) {
 	}
 }
 
+func TestUseCaseExecute_DryRunSkipsSaveAndAcceptsUnsavedEmails(t *testing.T) {
+	t.Parallel()
+
+	uc := NewUseCase(
+		&mockClock{now: time.Date(2026, 3, 24, 14, 0, 0, 0, time.UTC)},
+		&mockAnalyzerFactory{
+			create: func(ctx context.Context, spec AnalyzerSpec) (Analyzer, error) {
+				return &mockAnalyzer{
+					analyze: func(ctx context.Context, email EmailForAnalysisTarget) (domain.AnalysisOutput, error) {
+						return domain.AnalysisOutput{
+							ParsedEmails: []commondomain.ParsedEmail{
+								{VendorName: stringPtr("Example Vendor")},
+							},
+							PromptVersion: "emailanalysis_v1",
+						}, nil
+					},
+				}, nil
+			},
+		},
+		&mockParsedEmailRepository{
+			saveAll: func(ctx context.Context, input domain.SaveInput) ([]domain.ParsedEmailRecord, error) {
+				t.Fatal("parsed emails must not be saved for a dry run")
+				return nil, nil
+			},
+		},
+		logger.NewNop(),
+	)
+
+	result, err := uc.Execute(context.Background(), Command{
+		UserID: 3,
+		Emails: []EmailForAnalysisTarget{
+			{ExternalMessageID: "msg-1", Body: "body", BodyDigest: "abcd"},
+			{ExternalMessageID: "msg-2"},
+		},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.ParsedEmailCount != 1 || len(result.ParsedEmails) != 1 {
+		t.Fatalf("unexpected parsed emails: %+v", result)
+	}
+	item := result.ParsedEmails[0]
+	if item.ParsedEmailID != 0 || item.ExternalMessageID != "msg-1" {
+		t.Fatalf("unexpected dry-run item: %+v", item)
+	}
+	if item.ParsedEmail.BillingNumber == nil || *item.ParsedEmail.BillingNumber != "digest_abcd" {
+		t.Fatalf("fallback billing number should still be applied: %+v", item.ParsedEmail)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Code != domain.FailureCodeInvalidEmailInput {
+		t.Fatalf("unexpected failures: %+v", result.Failures)
+	}
+}
+
 func stringPtr(value string) *string {
 	return &value
 }
@@ -463,3 +517,28 @@ func stringPtr(value string) *string {
 func float64Ptr(value float64) *float64 {
 	return &value
 }
+
+func TestEmailForAnalysisTarget_Validate_AcceptsAttachmentOnlyEmail(t *testing.T) {
+	t.Parallel()
+
+	withText := EmailForAnalysisTarget{
+		EmailID:           1,
+		ExternalMessageID: "msg-1",
+		Attachments:       []AttachmentForAnalysis{{Filename: "invoice.pdf", Text: " 合計 12,000円 "}},
+	}.Normalize()
+	if err := withText.Validate(); err != nil {
+		t.Fatalf("expected attachment text to stand in for the body, got %v", err)
+	}
+	if withText.Attachments[0].Text != "合計 12,000円" {
+		t.Fatalf("expected attachment text to be trimmed, got %q", withText.Attachments[0].Text)
+	}
+
+	unreadable := EmailForAnalysisTarget{
+		EmailID:           2,
+		ExternalMessageID: "msg-2",
+		Attachments:       []AttachmentForAnalysis{{Filename: "scan.pdf", FailureReason: "no_text"}},
+	}.Normalize()
+	if err := unreadable.Validate(); !errors.Is(err, domain.ErrEmailForAnalysisInvalid) {
+		t.Fatalf("expected ErrEmailForAnalysisInvalid, got %v", err)
+	}
+}
@@ -90,7 +90,16 @@ type parsedEmailResponseEnvelope struct {
 }
 
 func buildPrompt(email maapp.EmailForAnalysisTarget) string {
-	return openailib.BuildParsedEmailPrompt(email.Subject, email.From, email.ReceivedAt, email.Body)
+	attachments := make([]openailib.PromptAttachment, 0, len(email.Attachments))
+	for _, attachment := range email.Attachments {
+		attachments = append(attachments, openailib.PromptAttachment{
+			Filename:      attachment.Filename,
+			Text:          attachment.Text,
+			Truncated:     attachment.Truncated,
+			FailureReason: attachment.FailureReason,
+		})
+	}
+	return openailib.BuildParsedEmailPrompt(email.Subject, email.From, email.ReceivedAt, email.Body, attachments...)
 }
 
 func parseAnalysisResponse(raw string) ([]commondomain.ParsedEmail, error) {
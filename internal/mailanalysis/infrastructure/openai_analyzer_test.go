@@ -5,6 +5,7 @@ import (
 	"business/internal/mailanalysis/domain"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -200,3 +201,42 @@ func TestOpenAIAnalyzerAdapter_Analyze_AllNullDraftsBecomeEmpty(t *testing.T) {
 		t.Fatalf("expected empty parsed emails, got %+v", output.ParsedEmails)
 	}
 }
+
+func TestOpenAIAnalyzerAdapter_Analyze_SendsAttachmentSection(t *testing.T) {
+	t.Parallel()
+
+	var sent string
+	adapter := NewOpenAIAnalyzerAdapter(&mockOpenAIClient{
+		chat: func(ctx context.Context, prompt string) (string, error) {
+			sent = prompt
+			return `{"parsedEmails":[]}`, nil
+		},
+	}, nil)
+
+	_, err := adapter.Analyze(context.Background(), maapp.EmailForAnalysisTarget{
+		EmailID:           1,
+		ExternalMessageID: "msg-1",
+		Subject:           "請求書のご案内",
+		From:              "billing@example.com",
+		ReceivedAt:        time.Date(2026, 3, 24, 10, 0, 0, 0, time.UTC),
+		Body:              "請求書を添付します。",
+		Attachments: []maapp.AttachmentForAnalysis{
+			{Filename: "invoice.pdf", Text: "合計 12,000円", Truncated: true},
+			{Filename: "scan.pdf", FailureReason: "no_text"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+
+	bodyAt := strings.Index(sent, "請求書を添付します。")
+	sectionAt := strings.Index(sent, "attachments")
+	if bodyAt < 0 || sectionAt < bodyAt {
+		t.Fatalf("expected attachment section after the body:\n%s", sent)
+	}
+	for _, want := range []string{"--- invoice.pdf ---\n合計 12,000円\n", "省略されています", "--- scan.pdf (読み取れませんでした: no_text) ---"} {
+		if !strings.Contains(sent[sectionAt:], want) {
+			t.Fatalf("expected %q in attachment section:\n%s", want, sent[sectionAt:])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EmailBodyRepository reads stored email bodies and removes those past their retention period.
+type EmailBodyRepository interface {
+	// FindBodies returns the unexpired bodies of the user's emails. Emails without one are left out.
+	FindBodies(ctx context.Context, userID uint, emailIDs []uint, now time.Time) ([]mfdomain.EmailBody, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// EmailBodyUseCase reads the stored bodies of saved emails, for reanalysis and for showing an email's source.
+type EmailBodyUseCase interface {
+	// GetBody returns ErrEmailBodyNotFound when the email has no stored body.
+	GetBody(ctx context.Context, userID, emailID uint) (mfdomain.EmailBody, error)
+	// GetBodies returns the bodies that are stored, in the order of emailIDs.
+	GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error)
+	// PurgeExpired deletes the bodies whose retention period has ended and returns how many were deleted.
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type emailBodyUseCase struct {
+	repo  EmailBodyRepository
+	clock timewrapper.ClockInterface
+	log   logger.Interface
+}
+
+// NewEmailBodyUseCase creates a stored email body use case.
+func NewEmailBodyUseCase(repo EmailBodyRepository, clock timewrapper.ClockInterface, log logger.Interface) EmailBodyUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &emailBodyUseCase{
+		repo:  repo,
+		clock: clock,
+		log:   log.With(logger.Component("email_body_usecase")),
+	}
+}
+
+func (uc *emailBodyUseCase) GetBody(ctx context.Context, userID, emailID uint) (mfdomain.EmailBody, error) {
+	if emailID == 0 {
+		return mfdomain.EmailBody{}, fmt.Errorf("%w: email_id is required", mfdomain.ErrInvalidCommand)
+	}
+	bodies, err := uc.GetBodies(ctx, userID, []uint{emailID})
+	if err != nil {
+		return mfdomain.EmailBody{}, err
+	}
+	if len(bodies) == 0 {
+		return mfdomain.EmailBody{}, mfdomain.ErrEmailBodyNotFound
+	}
+	return bodies[0], nil
+}
+
+func (uc *emailBodyUseCase) GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if userID == 0 {
+		return nil, fmt.Errorf("%w: user_id is required", mfdomain.ErrInvalidCommand)
+	}
+	if uc.repo == nil {
+		return nil, errors.New("email body repository is not configured")
+	}
+	if len(emailIDs) == 0 {
+		return nil, nil
+	}
+
+	found, err := uc.repo.FindBodies(ctx, userID, emailIDs, uc.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	byEmailID := make(map[uint]mfdomain.EmailBody, len(found))
+	for _, body := range found {
+		byEmailID[body.EmailID] = body
+	}
+
+	bodies := make([]mfdomain.EmailBody, 0, len(found))
+	seen := make(map[uint]struct{}, len(emailIDs))
+	for _, emailID := range emailIDs {
+		body, ok := byEmailID[emailID]
+		if _, dup := seen[emailID]; !ok || dup {
+			continue
+		}
+		seen[emailID] = struct{}{}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+func (uc *emailBodyUseCase) PurgeExpired(ctx context.Context) (int, error) {
+	if ctx == nil {
+		return 0, logger.ErrNilContext
+	}
+	if uc.repo == nil {
+		return 0, errors.New("email body repository is not configured")
+	}
+	return uc.repo.DeleteExpired(ctx, uc.clock.Now())
+}
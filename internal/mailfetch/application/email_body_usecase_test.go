@@ -0,0 +1,91 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubEmailBodyRepository struct {
+	bodies      []mfdomain.EmailBody
+	findErr     error
+	gotUserID   uint
+	gotEmailIDs []uint
+	gotNow      time.Time
+	deleted     int
+}
+
+func (s *stubEmailBodyRepository) FindBodies(ctx context.Context, userID uint, emailIDs []uint, now time.Time) ([]mfdomain.EmailBody, error) {
+	s.gotUserID = userID
+	s.gotEmailIDs = emailIDs
+	s.gotNow = now
+	return s.bodies, s.findErr
+}
+
+func (s *stubEmailBodyRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	s.gotNow = now
+	return s.deleted, nil
+}
+
+func TestEmailBodyUseCase_GetBodies(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	repo := &stubEmailBodyRepository{bodies: []mfdomain.EmailBody{
+		{EmailID: 3, Body: "third"},
+		{EmailID: 1, Body: "first"},
+	}}
+	uc := NewEmailBodyUseCase(repo, &fixedClock{now: now}, logger.NewNop())
+
+	bodies, err := uc.GetBodies(context.Background(), 5, []uint{1, 2, 3, 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bodies) != 2 || bodies[0].EmailID != 1 || bodies[1].EmailID != 3 {
+		t.Fatalf("bodies must follow the requested order without duplicates: %+v", bodies)
+	}
+	if repo.gotUserID != 5 || !repo.gotNow.Equal(now) {
+		t.Fatalf("unexpected repository call: user=%d now=%s", repo.gotUserID, repo.gotNow)
+	}
+}
+
+func TestEmailBodyUseCase_GetBody(t *testing.T) {
+	t.Parallel()
+
+	repo := &stubEmailBodyRepository{bodies: []mfdomain.EmailBody{{EmailID: 7, Body: "stored"}}}
+	uc := NewEmailBodyUseCase(repo, nil, nil)
+
+	body, err := uc.GetBody(context.Background(), 5, 7)
+	if err != nil || body.Body != "stored" {
+		t.Fatalf("unexpected body: %+v err=%v", body, err)
+	}
+
+	repo.bodies = nil
+	if _, err := uc.GetBody(context.Background(), 5, 7); !errors.Is(err, mfdomain.ErrEmailBodyNotFound) {
+		t.Fatalf("expected ErrEmailBodyNotFound, got %v", err)
+	}
+	if _, err := uc.GetBody(context.Background(), 5, 0); !errors.Is(err, mfdomain.ErrInvalidCommand) {
+		t.Fatalf("expected ErrInvalidCommand, got %v", err)
+	}
+
+	repo.findErr = errors.New("db down")
+	if _, err := uc.GetBody(context.Background(), 5, 7); err == nil || errors.Is(err, mfdomain.ErrEmailBodyNotFound) {
+		t.Fatalf("repository errors must be returned as is, got %v", err)
+	}
+}
+
+func TestEmailBodyUseCase_PurgeExpired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	repo := &stubEmailBodyRepository{deleted: 4}
+	uc := NewEmailBodyUseCase(repo, &fixedClock{now: now}, nil)
+
+	deleted, err := uc.PurgeExpired(context.Background())
+	if err != nil || deleted != 4 || !repo.gotNow.Equal(now) {
+		t.Fatalf("unexpected purge: deleted=%d err=%v now=%s", deleted, err, repo.gotNow)
+	}
+}
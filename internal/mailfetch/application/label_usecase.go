@@ -0,0 +1,111 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MailLabelLister lists the labels of a resolved mail-account connection.
+type MailLabelLister interface {
+	ListLabels(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, error)
+}
+
+// MailLabelCache keeps recently listed labels per connection for a short time.
+type MailLabelCache interface {
+	FindLabels(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, bool, error)
+	SaveLabels(ctx context.Context, conn mfdomain.ConnectionRef, labels []mfdomain.MailLabel) error
+}
+
+// LabelUseCase lists the provider labels of a user's mail-account connection.
+type LabelUseCase interface {
+	ListLabels(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error)
+}
+
+type labelUseCase struct {
+	connectionRepo ConnectionRepository
+	lister         MailLabelLister
+	cache          MailLabelCache
+	log            logger.Interface
+}
+
+// NewLabelUseCase creates a label listing use case.
+// A nil cache asks the provider on every call.
+func NewLabelUseCase(
+	connectionRepo ConnectionRepository,
+	lister MailLabelLister,
+	cache MailLabelCache,
+	log logger.Interface,
+) LabelUseCase {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &labelUseCase{
+		connectionRepo: connectionRepo,
+		lister:         lister,
+		cache:          cache,
+		log:            log.With(logger.Component("mail_label_usecase")),
+	}
+}
+
+// ListLabels returns the labels of the connection, from the cache when it still holds them.
+// Cache failures are logged and the labels are listed from the provider instead.
+func (uc *labelUseCase) ListLabels(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if userID == 0 {
+		return nil, fmt.Errorf("%w: user_id is required", mfdomain.ErrInvalidCommand)
+	}
+	if connectionID == 0 {
+		return nil, fmt.Errorf("%w: connection_id is required", mfdomain.ErrInvalidCommand)
+	}
+	if uc.connectionRepo == nil {
+		return nil, errors.New("connection repository is not configured")
+	}
+	if uc.lister == nil {
+		return nil, errors.New("mail label lister is not configured")
+	}
+
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+
+	conn, err := uc.connectionRepo.FindUsableConnection(ctx, userID, connectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.cache != nil {
+		labels, found, err := uc.cache.FindLabels(ctx, conn)
+		if err != nil {
+			reqLog.Warn("mail_label_cache_read_failed",
+				logger.UserID(userID),
+				logger.Uint("connection_id", connectionID),
+				logger.Err(err),
+			)
+		} else if found {
+			return labels, nil
+		}
+	}
+
+	labels, err := uc.lister.ListLabels(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.cache != nil {
+		if err := uc.cache.SaveLabels(ctx, conn, labels); err != nil {
+			reqLog.Warn("mail_label_cache_write_failed",
+				logger.UserID(userID),
+				logger.Uint("connection_id", connectionID),
+				logger.Err(err),
+			)
+		}
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,119 @@
+package application
+
+import (
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockMailLabelLister struct {
+	listLabels func(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, error)
+}
+
+func (m *mockMailLabelLister) ListLabels(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, error) {
+	return m.listLabels(ctx, conn)
+}
+
+type mockMailLabelCache struct {
+	labels  []mfdomain.MailLabel
+	found   bool
+	findErr error
+	saved   []mfdomain.MailLabel
+	saveErr error
+}
+
+func (m *mockMailLabelCache) FindLabels(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, bool, error) {
+	return m.labels, m.found, m.findErr
+}
+
+func (m *mockMailLabelCache) SaveLabels(ctx context.Context, conn mfdomain.ConnectionRef, labels []mfdomain.MailLabel) error {
+	m.saved = labels
+	return m.saveErr
+}
+
+func newLabelTestConnectionRepository(t *testing.T) *mockConnectionRepository {
+	t.Helper()
+	return &mockConnectionRepository{
+		findUsableConnection: func(ctx context.Context, userID, connectionID uint) (mfdomain.ConnectionRef, error) {
+			if userID != 7 || connectionID != 12 {
+				t.Fatalf("unexpected connection lookup: user=%d connection=%d", userID, connectionID)
+			}
+			return mfdomain.ConnectionRef{ConnectionID: connectionID, UserID: userID, Provider: "gmail", AccountIdentifier: "billing@example.com"}, nil
+		},
+	}
+}
+
+func TestLabelUseCase_ListLabels_ReturnsCachedLabels(t *testing.T) {
+	t.Parallel()
+
+	cache := &mockMailLabelCache{labels: []mfdomain.MailLabel{{Name: "billing"}}, found: true}
+	uc := NewLabelUseCase(newLabelTestConnectionRepository(t), &mockMailLabelLister{
+		listLabels: func(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, error) {
+			t.Fatal("provider must not be asked on a cache hit")
+			return nil, nil
+		},
+	}, cache, nil)
+
+	labels, err := uc.ListLabels(context.Background(), 7, 12)
+	if err != nil {
+		t.Fatalf("ListLabels returned error: %v", err)
+	}
+	if len(labels) != 1 || labels[0].Name != "billing" {
+		t.Fatalf("unexpected labels: %+v", labels)
+	}
+}
+
+func TestLabelUseCase_ListLabels_ListsAndCachesOnMiss(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		cache *mockMailLabelCache
+	}{
+		{name: "cache miss", cache: &mockMailLabelCache{}},
+		{name: "cache read failure", cache: &mockMailLabelCache{findErr: errors.New("redis down")}},
+		{name: "cache write failure", cache: &mockMailLabelCache{saveErr: errors.New("redis down")}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewLabelUseCase(newLabelTestConnectionRepository(t), &mockMailLabelLister{
+				listLabels: func(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, error) {
+					if conn.AccountIdentifier != "billing@example.com" {
+						t.Fatalf("unexpected connection: %+v", conn)
+					}
+					return []mfdomain.MailLabel{{Name: "INBOX", System: true}, {Name: "billing"}}, nil
+				},
+			}, tt.cache, nil)
+
+			labels, err := uc.ListLabels(context.Background(), 7, 12)
+			if err != nil {
+				t.Fatalf("ListLabels returned error: %v", err)
+			}
+			if len(labels) != 2 || len(tt.cache.saved) != 2 {
+				t.Fatalf("unexpected labels: %+v cached: %+v", labels, tt.cache.saved)
+			}
+		})
+	}
+}
+
+func TestLabelUseCase_ListLabels_Errors(t *testing.T) {
+	t.Parallel()
+
+	uc := NewLabelUseCase(&mockConnectionRepository{
+		findUsableConnection: func(ctx context.Context, userID, connectionID uint) (mfdomain.ConnectionRef, error) {
+			return mfdomain.ConnectionRef{}, mfdomain.ErrConnectionNotFound
+		},
+	}, &mockMailLabelLister{}, nil, nil)
+
+	if _, err := uc.ListLabels(context.Background(), 7, 0); !errors.Is(err, mfdomain.ErrInvalidCommand) {
+		t.Fatalf("expected ErrInvalidCommand, got %v", err)
+	}
+	if _, err := uc.ListLabels(context.Background(), 7, 12); !errors.Is(err, mfdomain.ErrConnectionNotFound) {
+		t.Fatalf("expected ErrConnectionNotFound, got %v", err)
+	}
+}
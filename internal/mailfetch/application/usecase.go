@@ -3,6 +3,7 @@ package application
 import (
 	cd "business/internal/common/domain"
 	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
 	mfdomain "business/internal/mailfetch/domain"
 	"context"
 	"crypto/sha256"
@@ -27,16 +28,37 @@ type MailFetcher interface {
 	Fetch(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error)
 }
 
+// IncrementalMailFetcher is implemented by fetchers that can resume from a provider sync checkpoint.
+type IncrementalMailFetcher interface {
+	FetchIncremental(ctx context.Context, cond mfdomain.FetchCondition, checkpoint *mfdomain.SyncCheckpoint) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, mfdomain.SyncCursor, error)
+}
+
+// SyncCheckpointRepository stores the provider sync cursor per connection and label.
+type SyncCheckpointRepository interface {
+	FindCheckpoint(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error)
+	SaveCheckpoint(ctx context.Context, checkpoint mfdomain.SyncCheckpoint) error
+}
+
 // EmailRepository persists fetched email metadata idempotently.
 type EmailRepository interface {
 	SaveAllIfAbsent(ctx context.Context, userID uint, source mfdomain.EmailSource, dtos []cd.FetchedEmailDTO) ([]mfdomain.SaveResult, []mfdomain.MessageFailure, error)
 }
 
+// EmailBodyWriter keeps the fetched content of saved emails for reprocessing.
+type EmailBodyWriter interface {
+	SaveBodies(ctx context.Context, userID uint, bodies []mfdomain.EmailBody) error
+}
+
 // Command is the input contract for the manual mail fetch stage.
 type Command struct {
 	UserID       uint
 	ConnectionID uint
 	Condition    mfdomain.FetchCondition
+	// IncludeExistingEmails also returns the fetched payload of emails that were already saved.
+	IncludeExistingEmails bool
+	// DryRun skips saving emails and the sync checkpoint.
+	// Every normalized email is returned in CreatedEmails with a zero EmailID.
+	DryRun bool
 }
 
 // CreatedEmail is a downstream-facing payload for newly persisted emails.
@@ -49,6 +71,8 @@ type CreatedEmail struct {
 	Date              time.Time
 	Body              string
 	BodyDigest        string
+	// Attachments carries the text extracted from PDF attachments. Only the Gmail provider fills it.
+	Attachments []cd.FetchedAttachmentDTO
 }
 
 // Result is the output contract for the manual mail fetch stage.
@@ -58,7 +82,9 @@ type Result struct {
 	MatchedMessageCount int
 	CreatedEmails       []CreatedEmail
 	ExistingEmailIDs    []uint
-	Failures            []mfdomain.MessageFailure
+	// ExistingEmails is filled only when Command.IncludeExistingEmails is set.
+	ExistingEmails []CreatedEmail
+	Failures       []mfdomain.MessageFailure
 }
 
 // UseCase executes the manual mail fetch stage.
@@ -70,16 +96,27 @@ type useCase struct {
 	connectionRepo ConnectionRepository
 	fetcherFactory MailFetcherFactory
 	emailRepo      EmailRepository
+	checkpointRepo SyncCheckpointRepository
+	bodyWriter     EmailBodyWriter
+	clock          timewrapper.ClockInterface
 	log            logger.Interface
 }
 
 // NewUseCase creates a manual mail fetch use case.
+// A nil checkpointRepo disables incremental sync and every fetch lists the whole label.
+// A nil bodyWriter keeps only the body digest of saved emails.
 func NewUseCase(
 	connectionRepo ConnectionRepository,
 	fetcherFactory MailFetcherFactory,
 	emailRepo EmailRepository,
+	checkpointRepo SyncCheckpointRepository,
+	bodyWriter EmailBodyWriter,
+	clock timewrapper.ClockInterface,
 	log logger.Interface,
 ) UseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
 	if log == nil {
 		log = logger.NewNop()
 	}
@@ -87,6 +124,9 @@ func NewUseCase(
 		connectionRepo: connectionRepo,
 		fetcherFactory: fetcherFactory,
 		emailRepo:      emailRepo,
+		checkpointRepo: checkpointRepo,
+		bodyWriter:     bodyWriter,
+		clock:          clock,
 		log:            log.With(logger.Component("manual_mail_fetch_usecase")),
 	}
 }
@@ -118,7 +158,7 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 		return Result{}, err
 	}
 
-	dtos, failures, err := fetcher.Fetch(ctx, cmd.Condition)
+	dtos, failures, pendingCheckpoint, err := uc.fetch(ctx, reqLog, conn, fetcher, cmd.Condition, cmd.DryRun)
 	if err != nil {
 		return Result{}, err
 	}
@@ -162,6 +202,23 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 		saveTargetsByMessageID[externalMessageID] = dto
 	}
 
+	// dry-run は emails を汚さないよう保存を行わず、正規化済みのメールをそのまま後続へ渡す。
+	if cmd.DryRun {
+		for _, dto := range saveTargets {
+			result.CreatedEmails = append(result.CreatedEmails, newCreatedEmail(mfdomain.SaveResult{ExternalMessageID: dto.ID}, dto))
+		}
+
+		reqLog.Info("manual_mail_fetch_dry_run_succeeded",
+			logger.UserID(cmd.UserID),
+			logger.Uint("connection_id", cmd.ConnectionID),
+			logger.String("provider", result.Provider),
+			logger.Int("matched_message_count", result.MatchedMessageCount),
+			logger.Int("created_email_count", len(result.CreatedEmails)),
+			logger.Int("failure_count", len(result.Failures)),
+		)
+		return result, nil
+	}
+
 	saveResults, saveFailures, saveErr := uc.emailRepo.SaveAllIfAbsent(ctx, cmd.UserID, source, saveTargets)
 	if saveErr != nil {
 		reqLog.Error("manual_mail_fetch_save_failed",
@@ -186,18 +243,21 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 				)
 				continue
 			}
-			result.CreatedEmails = append(result.CreatedEmails, CreatedEmail{
-				EmailID:           saveResult.EmailID,
-				ExternalMessageID: saveResult.ExternalMessageID,
-				Subject:           dto.Subject,
-				From:              dto.From,
-				To:                append([]string(nil), dto.To...),
-				Date:              dto.Date,
-				Body:              dto.Body,
-				BodyDigest:        dto.BodyDigest,
-			})
+			result.CreatedEmails = append(result.CreatedEmails, newCreatedEmail(saveResult, dto))
 		case mfdomain.SaveStatusExisting:
 			result.ExistingEmailIDs = append(result.ExistingEmailIDs, saveResult.EmailID)
+			if !cmd.IncludeExistingEmails {
+				continue
+			}
+			dto, ok := saveTargetsByMessageID[saveResult.ExternalMessageID]
+			if !ok {
+				reqLog.Error("manual_mail_fetch_existing_email_payload_missing",
+					logger.Uint("email_id", saveResult.EmailID),
+					logger.String("external_message_id", saveResult.ExternalMessageID),
+				)
+				continue
+			}
+			result.ExistingEmails = append(result.ExistingEmails, newCreatedEmail(saveResult, dto))
 		default:
 			reqLog.Error("manual_mail_fetch_save_status_invalid",
 				logger.String("external_message_id", saveResult.ExternalMessageID),
@@ -212,10 +272,31 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 		}
 	}
 
+	uc.saveBodies(ctx, reqLog, cmd.UserID, result)
+
+	// 保存まで成功した場合のみチェックポイントを進め、保存失敗時は次回も同じ範囲を取得し直す。
+	// 詳細取得や保存に失敗したメールがあると、進めた historyId 以降の差分には二度と現れないため進めない。
+	if pendingCheckpoint != nil && hasRetryableFailure(result.Failures) {
+		reqLog.Warn("manual_mail_fetch_checkpoint_skipped",
+			logger.UserID(cmd.UserID),
+			logger.Uint("connection_id", cmd.ConnectionID),
+			logger.Int("failure_count", len(result.Failures)),
+		)
+	} else if pendingCheckpoint != nil {
+		if err := uc.checkpointRepo.SaveCheckpoint(ctx, pendingCheckpoint.SyncCheckpoint); err != nil {
+			reqLog.Warn("manual_mail_fetch_checkpoint_save_failed",
+				logger.UserID(cmd.UserID),
+				logger.Uint("connection_id", cmd.ConnectionID),
+				logger.Err(err),
+			)
+		}
+	}
+
 	reqLog.Info("manual_mail_fetch_succeeded",
 		logger.UserID(cmd.UserID),
 		logger.Uint("connection_id", cmd.ConnectionID),
 		logger.String("provider", result.Provider),
+		logger.Bool("incremental_sync", pendingCheckpoint != nil && pendingCheckpoint.Incremental),
 		logger.Int("matched_message_count", result.MatchedMessageCount),
 		logger.Int("created_email_count", len(result.CreatedEmails)),
 		logger.Int("existing_email_count", len(result.ExistingEmailIDs)),
@@ -225,6 +306,94 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 	return result, nil
 }
 
+// fetch lists provider messages, resuming from the stored checkpoint when it covers cond.
+// The returned checkpoint is nil when incremental sync is not available for this fetch.
+// A dry run always lists the whole condition so that the stored checkpoint is neither used nor advanced.
+func (uc *useCase) fetch(
+	ctx context.Context,
+	reqLog logger.Interface,
+	conn mfdomain.ConnectionRef,
+	fetcher MailFetcher,
+	cond mfdomain.FetchCondition,
+	dryRun bool,
+) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, *pendingSyncCheckpoint, error) {
+	incrementalFetcher, ok := fetcher.(IncrementalMailFetcher)
+	// checkpoint はラベル単位なので、検索クエリやラベルで絞り込んだ取得は常にラベル全体を一覧する。
+	if !ok || uc.checkpointRepo == nil || len(cond.MessageIDs) > 0 || !cond.Filter.IsZero() || dryRun {
+		dtos, failures, err := fetcher.Fetch(ctx, cond)
+		return dtos, failures, nil, err
+	}
+
+	var previous *mfdomain.SyncCheckpoint
+	checkpoint, found, err := uc.checkpointRepo.FindCheckpoint(ctx, conn, cond.LabelName)
+	switch {
+	case err != nil:
+		reqLog.Warn("manual_mail_fetch_checkpoint_find_failed",
+			logger.Uint("connection_id", conn.ConnectionID),
+			logger.Err(err),
+		)
+	case found && checkpoint.Covers(cond):
+		previous = &checkpoint
+	}
+
+	dtos, failures, cursor, err := incrementalFetcher.FetchIncremental(ctx, cond, previous)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cursor.HistoryID == 0 {
+		return dtos, failures, nil, nil
+	}
+
+	// 取得完了時刻を記録し、次回は until がこの時刻以降まで届いていた場合のみ差分取得する。
+	next := mfdomain.SyncCheckpoint{
+		ConnectionID:      conn.ConnectionID,
+		AccountIdentifier: conn.AccountIdentifier,
+		LabelName:         cond.LabelName,
+		HistoryID:         cursor.HistoryID,
+		CoveredSince:      cond.Since,
+		CoveredUntil:      cond.Until,
+		SyncedAt:          uc.clock.Now(),
+	}
+
+	return dtos, failures, &pendingSyncCheckpoint{SyncCheckpoint: next, Incremental: cursor.Incremental}, nil
+}
+
+// saveBodies stores the content of the saved emails that this fetch returned.
+// Existing emails are stored again so that their retention restarts; a failure only loses the reprocessing copy.
+func (uc *useCase) saveBodies(ctx context.Context, reqLog logger.Interface, userID uint, result Result) {
+	if uc.bodyWriter == nil {
+		return
+	}
+
+	bodies := make([]mfdomain.EmailBody, 0, len(result.CreatedEmails)+len(result.ExistingEmails))
+	for _, emails := range [][]CreatedEmail{result.CreatedEmails, result.ExistingEmails} {
+		for _, email := range emails {
+			bodies = append(bodies, mfdomain.EmailBody{
+				EmailID:     email.EmailID,
+				Body:        email.Body,
+				BodyDigest:  email.BodyDigest,
+				Attachments: email.Attachments,
+			})
+		}
+	}
+	if len(bodies) == 0 {
+		return
+	}
+
+	if err := uc.bodyWriter.SaveBodies(ctx, userID, bodies); err != nil {
+		reqLog.Warn("manual_mail_fetch_body_save_failed",
+			logger.UserID(userID),
+			logger.Int("email_count", len(bodies)),
+			logger.Err(err),
+		)
+	}
+}
+
+type pendingSyncCheckpoint struct {
+	mfdomain.SyncCheckpoint
+	Incremental bool
+}
+
 func validateCommand(cmd Command) error {
 	if cmd.UserID == 0 {
 		return fmt.Errorf("%w: user_id is required", mfdomain.ErrInvalidCommand)
@@ -238,6 +407,20 @@ func validateCommand(cmd Command) error {
 	return nil
 }
 
+func newCreatedEmail(saveResult mfdomain.SaveResult, dto cd.FetchedEmailDTO) CreatedEmail {
+	return CreatedEmail{
+		EmailID:           saveResult.EmailID,
+		ExternalMessageID: saveResult.ExternalMessageID,
+		Subject:           dto.Subject,
+		From:              dto.From,
+		To:                append([]string(nil), dto.To...),
+		Date:              dto.Date,
+		Body:              dto.Body,
+		BodyDigest:        dto.BodyDigest,
+		Attachments:       append([]cd.FetchedAttachmentDTO(nil), dto.Attachments...),
+	}
+}
+
 func computeBodyDigest(body string) string {
 	sum := sha256.Sum256([]byte(body))
 	return hex.EncodeToString(sum[:])
@@ -266,3 +449,14 @@ func externalMessageIDOrFallback(externalMessageID string) string {
 	}
 	return externalMessageID
 }
+
+// hasRetryableFailure reports whether a message failed to be fetched or saved, so that fetching the same range again can recover it.
+// Normalize failures are excluded because fetching the message again yields the same invalid payload.
+func hasRetryableFailure(failures []mfdomain.MessageFailure) bool {
+	for _, failure := range failures {
+		if failure.Stage == mfdomain.FailureStageFetchDetail || failure.Stage == mfdomain.FailureStageSave {
+			return true
+		}
+	}
+	return false
+}
@@ -142,6 +142,9 @@ func TestUseCaseExecute_CreatesAndReturnsExistingIDs(t *testing.T) {
 				}, nil, nil
 			},
 		},
+		nil,
+		nil,
+		nil,
 		logger.NewNop(),
 	)
 
@@ -190,6 +193,69 @@ func TestUseCaseExecute_CreatesAndReturnsExistingIDs(t *testing.T) {
 	}
 }
 
+func TestUseCaseExecute_IncludeExistingEmailsReturnsPayload(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 10, 0, 0, 0, time.UTC)
+	uc := NewUseCase(
+		&mockConnectionRepository{
+			findUsableConnection: func(ctx context.Context, userID, connectionID uint) (mfdomain.ConnectionRef, error) {
+				return mfdomain.ConnectionRef{ConnectionID: 10, UserID: 5, Provider: "gmail", AccountIdentifier: "user@gmail.com"}, nil
+			},
+		},
+		&mockMailFetcherFactory{
+			create: func(ctx context.Context, conn mfdomain.ConnectionRef) (MailFetcher, error) {
+				return &mockMailFetcher{
+					fetch: func(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
+						if len(cond.MessageIDs) != 1 || cond.MessageIDs[0] != "msg-2" {
+							t.Fatalf("unexpected message ids: %+v", cond.MessageIDs)
+						}
+						return []cd.FetchedEmailDTO{
+							{ID: "msg-2", Subject: "b", From: "from2", To: []string{"to2"}, Date: now, Body: "body-2"},
+						}, nil, nil
+					},
+				}, nil
+			},
+		},
+		&mockEmailRepository{
+			saveAllIfAbsent: func(ctx context.Context, userID uint, source mfdomain.EmailSource, dtos []cd.FetchedEmailDTO) ([]mfdomain.SaveResult, []mfdomain.MessageFailure, error) {
+				return []mfdomain.SaveResult{
+					{EmailID: 202, ExternalMessageID: "msg-2", Status: mfdomain.SaveStatusExisting},
+				}, nil, nil
+			},
+		},
+		nil,
+		nil,
+		nil,
+		logger.NewNop(),
+	)
+
+	result, err := uc.Execute(context.Background(), Command{
+		UserID:       5,
+		ConnectionID: 10,
+		Condition: mfdomain.FetchCondition{
+			LabelName:  "billing",
+			Since:      now.Add(-time.Hour),
+			Until:      now.Add(time.Hour),
+			MessageIDs: []string{" msg-2 ", "msg-2", ""},
+		},
+		IncludeExistingEmails: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if len(result.CreatedEmails) != 0 {
+		t.Fatalf("unexpected created emails: %+v", result.CreatedEmails)
+	}
+	if len(result.ExistingEmailIDs) != 1 || result.ExistingEmailIDs[0] != 202 {
+		t.Fatalf("unexpected existing ids: %+v", result.ExistingEmailIDs)
+	}
+	if len(result.ExistingEmails) != 1 || result.ExistingEmails[0].Body != "body-2" || result.ExistingEmails[0].BodyDigest != computeBodyDigest("body-2") {
+		t.Fatalf("unexpected existing email payload: %+v", result.ExistingEmails)
+	}
+}
+
 func TestUseCaseExecute_InvalidCondition(t *testing.T) {
 	t.Parallel()
 
@@ -212,6 +278,9 @@ func TestUseCaseExecute_InvalidCondition(t *testing.T) {
 				return nil, nil, nil
 			},
 		},
+		nil,
+		nil,
+		nil,
 		logger.NewNop(),
 	)
 
@@ -251,11 +320,11 @@ func TestUseCaseExecute_PartialFailuresContinue(t *testing.T) {
 				return &mockMailFetcher{
 					fetch: func(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
 						return []cd.FetchedEmailDTO{
-								{ID: "msg-1", Subject: "ok", From: "from1", To: []string{"to1"}, Date: now, Body: "body-1"},
-								{ID: "msg-2", Subject: "ng", From: "from2", To: []string{"to2"}, Date: now, Body: "body-2"},
-							}, []mfdomain.MessageFailure{
-								{ExternalMessageID: "msg-0", Stage: mfdomain.FailureStageFetchDetail, Code: mfdomain.FailureCodeFetchDetailFailed, Message: "Gmail本文の取得に失敗しました。メールID=msg-0"},
-							}, nil
+							{ID: "msg-1", Subject: "ok", From: "from1", To: []string{"to1"}, Date: now, Body: "body-1"},
+							{ID: "msg-2", Subject: "ng", From: "from2", To: []string{"to2"}, Date: now, Body: "body-2"},
+						}, []mfdomain.MessageFailure{
+							{ExternalMessageID: "msg-0", Stage: mfdomain.FailureStageFetchDetail, Code: mfdomain.FailureCodeFetchDetailFailed, Message: "Gmail本文の取得に失敗しました。メールID=msg-0"},
+						}, nil
 					},
 				}, nil
 			},
@@ -263,12 +332,15 @@ func TestUseCaseExecute_PartialFailuresContinue(t *testing.T) {
 		&mockEmailRepository{
 			saveAllIfAbsent: func(ctx context.Context, userID uint, source mfdomain.EmailSource, dtos []cd.FetchedEmailDTO) ([]mfdomain.SaveResult, []mfdomain.MessageFailure, error) {
 				return []mfdomain.SaveResult{
-						{EmailID: 55, ExternalMessageID: "msg-1", Status: mfdomain.SaveStatusCreated},
-					}, []mfdomain.MessageFailure{
-						{ExternalMessageID: "msg-2", Stage: mfdomain.FailureStageSave, Code: mfdomain.FailureCodeEmailSaveFailed, Message: "取得メール(msg-2)の保存に失敗しました。"},
-					}, nil
+					{EmailID: 55, ExternalMessageID: "msg-1", Status: mfdomain.SaveStatusCreated},
+				}, []mfdomain.MessageFailure{
+					{ExternalMessageID: "msg-2", Stage: mfdomain.FailureStageSave, Code: mfdomain.FailureCodeEmailSaveFailed, Message: "取得メール(msg-2)の保存に失敗しました。"},
+				}, nil
 			},
 		},
+		nil,
+		nil,
+		nil,
 		logger.NewNop(),
 	)
 
@@ -341,6 +413,9 @@ func TestUseCaseExecute_ZeroReceivedAtBecomesNormalizeFailure(t *testing.T) {
 				}, nil, nil
 			},
 		},
+		nil,
+		nil,
+		nil,
 		logger.NewNop(),
 	)
 
@@ -407,6 +482,9 @@ func TestUseCaseExecute_DoesNotLogAccountIdentifierOnSuccess(t *testing.T) {
 				}, nil, nil
 			},
 		},
+		nil,
+		nil,
+		nil,
 		log,
 	)
 
@@ -462,6 +540,9 @@ func TestUseCaseExecute_DoesNotLogAccountIdentifierOnError(t *testing.T) {
 				return nil, nil, errors.New("db down")
 			},
 		},
+		nil,
+		nil,
+		nil,
 		log,
 	)
 
@@ -482,3 +563,494 @@ func TestUseCaseExecute_DoesNotLogAccountIdentifierOnError(t *testing.T) {
 		t.Fatalf("account_identifier should not be logged: info=%+v error=%+v", log.infoEntries, log.errorEntries)
 	}
 }
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fixedClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.now.Add(d)
+	return ch
+}
+
+type mockIncrementalMailFetcher struct {
+	mockMailFetcher
+	fetchIncremental func(ctx context.Context, cond mfdomain.FetchCondition, checkpoint *mfdomain.SyncCheckpoint) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, mfdomain.SyncCursor, error)
+}
+
+func (m *mockIncrementalMailFetcher) FetchIncremental(ctx context.Context, cond mfdomain.FetchCondition, checkpoint *mfdomain.SyncCheckpoint) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, mfdomain.SyncCursor, error) {
+	return m.fetchIncremental(ctx, cond, checkpoint)
+}
+
+type mockSyncCheckpointRepository struct {
+	findCheckpoint func(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error)
+	saved          []mfdomain.SyncCheckpoint
+}
+
+func (m *mockSyncCheckpointRepository) FindCheckpoint(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+	return m.findCheckpoint(ctx, conn, labelName)
+}
+
+func (m *mockSyncCheckpointRepository) SaveCheckpoint(ctx context.Context, checkpoint mfdomain.SyncCheckpoint) error {
+	m.saved = append(m.saved, checkpoint)
+	return nil
+}
+
+func newIncrementalSyncTestUseCase(
+	t *testing.T,
+	checkpointRepo SyncCheckpointRepository,
+	fetcher MailFetcher,
+	saveErr error,
+	now time.Time,
+) UseCase {
+	t.Helper()
+
+	return NewUseCase(
+		&mockConnectionRepository{
+			findUsableConnection: func(ctx context.Context, userID, connectionID uint) (mfdomain.ConnectionRef, error) {
+				return mfdomain.ConnectionRef{ConnectionID: connectionID, UserID: userID, Provider: "gmail", AccountIdentifier: "user@gmail.com"}, nil
+			},
+		},
+		&mockMailFetcherFactory{
+			create: func(ctx context.Context, conn mfdomain.ConnectionRef) (MailFetcher, error) {
+				return fetcher, nil
+			},
+		},
+		&mockEmailRepository{
+			saveAllIfAbsent: func(ctx context.Context, userID uint, source mfdomain.EmailSource, dtos []cd.FetchedEmailDTO) ([]mfdomain.SaveResult, []mfdomain.MessageFailure, error) {
+				if saveErr != nil {
+					return nil, nil, saveErr
+				}
+				results := make([]mfdomain.SaveResult, 0, len(dtos))
+				for idx, dto := range dtos {
+					results = append(results, mfdomain.SaveResult{EmailID: uint(idx + 1), ExternalMessageID: dto.ID, Status: mfdomain.SaveStatusCreated})
+				}
+				return results, nil, nil
+			},
+		},
+		checkpointRepo,
+		nil,
+		&fixedClock{now: now},
+		logger.NewNop(),
+	)
+}
+
+func TestUseCaseExecute_IncrementalSyncUsesCoveringCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	stored := mfdomain.SyncCheckpoint{
+		ConnectionID: 9,
+		LabelName:    "billing",
+		HistoryID:    100,
+		CoveredSince: now.Add(-48 * time.Hour),
+		CoveredUntil: now.Add(-time.Hour),
+		SyncedAt:     now.Add(-2 * time.Hour),
+	}
+	checkpointRepo := &mockSyncCheckpointRepository{
+		findCheckpoint: func(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+			if conn.ConnectionID != 9 || labelName != "billing" {
+				t.Fatalf("unexpected checkpoint lookup: conn=%+v label=%s", conn, labelName)
+			}
+			return stored, true, nil
+		},
+	}
+	fetcher := &mockIncrementalMailFetcher{
+		fetchIncremental: func(ctx context.Context, cond mfdomain.FetchCondition, checkpoint *mfdomain.SyncCheckpoint) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, mfdomain.SyncCursor, error) {
+			if checkpoint == nil || checkpoint.HistoryID != 100 {
+				t.Fatalf("expected stored checkpoint to be passed, got %+v", checkpoint)
+			}
+			return []cd.FetchedEmailDTO{{ID: "msg-new", Date: now}}, nil, mfdomain.SyncCursor{HistoryID: 150, Incremental: true}, nil
+		},
+	}
+
+	uc := newIncrementalSyncTestUseCase(t, checkpointRepo, fetcher, nil, now)
+	result, err := uc.Execute(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 9,
+		Condition:    mfdomain.FetchCondition{LabelName: "billing", Since: now.Add(-24 * time.Hour), Until: now.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.MatchedMessageCount != 1 || len(result.CreatedEmails) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(checkpointRepo.saved) != 1 {
+		t.Fatalf("expected one saved checkpoint, got %+v", checkpointRepo.saved)
+	}
+	saved := checkpointRepo.saved[0]
+	if saved.HistoryID != 150 || saved.AccountIdentifier != "user@gmail.com" || !saved.SyncedAt.Equal(now) {
+		t.Fatalf("unexpected saved checkpoint: %+v", saved)
+	}
+	if !saved.CoveredSince.Equal(now.Add(-24*time.Hour)) || !saved.CoveredUntil.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected covered window: %+v", saved)
+	}
+}
+
+func TestUseCaseExecute_IncrementalSyncIgnoresCheckpointOutsideWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		checkpoint mfdomain.SyncCheckpoint
+	}{
+		{
+			name: "since before covered window",
+			checkpoint: mfdomain.SyncCheckpoint{
+				HistoryID: 100, CoveredSince: now.Add(-time.Hour), CoveredUntil: now, SyncedAt: now.Add(-time.Minute),
+			},
+		},
+		{
+			name: "previous until ended before the sync",
+			checkpoint: mfdomain.SyncCheckpoint{
+				HistoryID: 100, CoveredSince: now.Add(-48 * time.Hour), CoveredUntil: now.Add(-time.Hour), SyncedAt: now.Add(-time.Minute),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checkpointRepo := &mockSyncCheckpointRepository{
+				findCheckpoint: func(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+					return tt.checkpoint, true, nil
+				},
+			}
+			fetcher := &mockIncrementalMailFetcher{
+				fetchIncremental: func(ctx context.Context, cond mfdomain.FetchCondition, checkpoint *mfdomain.SyncCheckpoint) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, mfdomain.SyncCursor, error) {
+					if checkpoint != nil {
+						t.Fatalf("checkpoint must not be used: %+v", checkpoint)
+					}
+					return nil, nil, mfdomain.SyncCursor{HistoryID: 200}, nil
+				},
+			}
+
+			uc := newIncrementalSyncTestUseCase(t, checkpointRepo, fetcher, nil, now)
+			_, err := uc.Execute(context.Background(), Command{
+				UserID:       7,
+				ConnectionID: 9,
+				Condition:    mfdomain.FetchCondition{LabelName: "billing", Since: now.Add(-24 * time.Hour), Until: now.Add(time.Hour)},
+			})
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if len(checkpointRepo.saved) != 1 || checkpointRepo.saved[0].HistoryID != 200 {
+				t.Fatalf("expected full listing cursor to be saved, got %+v", checkpointRepo.saved)
+			}
+		})
+	}
+}
+
+func TestUseCaseExecute_IncrementalSyncKeepsCheckpointWhenSaveFails(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	checkpointRepo := &mockSyncCheckpointRepository{
+		findCheckpoint: func(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+			return mfdomain.SyncCheckpoint{}, false, errors.New("db down")
+		},
+	}
+	fetcher := &mockIncrementalMailFetcher{
+		fetchIncremental: func(ctx context.Context, cond mfdomain.FetchCondition, checkpoint *mfdomain.SyncCheckpoint) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, mfdomain.SyncCursor, error) {
+			return []cd.FetchedEmailDTO{{ID: "msg-1", Date: now}}, nil, mfdomain.SyncCursor{HistoryID: 300}, nil
+		},
+	}
+
+	uc := newIncrementalSyncTestUseCase(t, checkpointRepo, fetcher, errors.New("insert failed"), now)
+	_, err := uc.Execute(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 9,
+		Condition:    mfdomain.FetchCondition{LabelName: "billing", Since: now.Add(-24 * time.Hour), Until: now.Add(time.Hour)},
+	})
+	if err == nil {
+		t.Fatal("expected save error")
+	}
+	if len(checkpointRepo.saved) != 0 {
+		t.Fatalf("checkpoint must not advance when emails were not saved: %+v", checkpointRepo.saved)
+	}
+}
+
+func TestUseCaseExecute_IncrementalSyncKeepsCheckpointWhenDetailFetchFails(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	checkpointRepo := &mockSyncCheckpointRepository{
+		findCheckpoint: func(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+			return mfdomain.SyncCheckpoint{}, false, nil
+		},
+	}
+	fetcher := &mockIncrementalMailFetcher{
+		fetchIncremental: func(ctx context.Context, cond mfdomain.FetchCondition, checkpoint *mfdomain.SyncCheckpoint) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, mfdomain.SyncCursor, error) {
+			return []cd.FetchedEmailDTO{{ID: "msg-1", Date: now}}, []mfdomain.MessageFailure{{
+				ExternalMessageID: "msg-2",
+				Stage:             mfdomain.FailureStageFetchDetail,
+				Code:              mfdomain.FailureCodeFetchDetailFailed,
+			}}, mfdomain.SyncCursor{HistoryID: 300}, nil
+		},
+	}
+
+	uc := newIncrementalSyncTestUseCase(t, checkpointRepo, fetcher, nil, now)
+	result, err := uc.Execute(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 9,
+		Condition:    mfdomain.FetchCondition{LabelName: "billing", Since: now.Add(-24 * time.Hour), Until: now.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(result.CreatedEmails) != 1 || len(result.Failures) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(checkpointRepo.saved) != 0 {
+		t.Fatalf("checkpoint must not advance past a message that failed to be fetched: %+v", checkpointRepo.saved)
+	}
+}
+
+func TestUseCaseExecute_MessageIDsBypassIncrementalSync(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	checkpointRepo := &mockSyncCheckpointRepository{
+		findCheckpoint: func(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+			t.Fatal("checkpoint must not be looked up for a retry fetch")
+			return mfdomain.SyncCheckpoint{}, false, nil
+		},
+	}
+	fetcher := &mockIncrementalMailFetcher{
+		mockMailFetcher: mockMailFetcher{
+			fetch: func(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
+				return []cd.FetchedEmailDTO{{ID: "msg-1", Date: now}}, nil, nil
+			},
+		},
+	}
+
+	uc := newIncrementalSyncTestUseCase(t, checkpointRepo, fetcher, nil, now)
+	_, err := uc.Execute(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 9,
+		Condition: mfdomain.FetchCondition{
+			LabelName:  "billing",
+			Since:      now.Add(-24 * time.Hour),
+			Until:      now.Add(time.Hour),
+			MessageIDs: []string{"msg-1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(checkpointRepo.saved) != 0 {
+		t.Fatalf("retry fetch must not save a checkpoint: %+v", checkpointRepo.saved)
+	}
+}
+
+func TestUseCaseExecute_FilterBypassesIncrementalSync(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	checkpointRepo := &mockSyncCheckpointRepository{
+		findCheckpoint: func(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+			t.Fatal("checkpoint must not be looked up for a filtered fetch")
+			return mfdomain.SyncCheckpoint{}, false, nil
+		},
+	}
+	fetcher := &mockIncrementalMailFetcher{
+		mockMailFetcher: mockMailFetcher{
+			fetch: func(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
+				if cond.Filter.Query != "from:billing@example.com" || len(cond.Filter.ExcludeLabelNames) != 1 {
+					t.Fatalf("unexpected filter: %+v", cond.Filter)
+				}
+				return []cd.FetchedEmailDTO{{ID: "msg-1", Date: now}}, nil, nil
+			},
+		},
+	}
+
+	uc := newIncrementalSyncTestUseCase(t, checkpointRepo, fetcher, nil, now)
+	_, err := uc.Execute(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 9,
+		Condition: mfdomain.FetchCondition{
+			LabelName: "billing",
+			Since:     now.Add(-24 * time.Hour),
+			Until:     now.Add(time.Hour),
+			Filter: mfdomain.FetchFilter{
+				Query:             " from:billing@example.com ",
+				ExcludeLabelNames: []string{"archived", " archived"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(checkpointRepo.saved) != 0 {
+		t.Fatalf("filtered fetch must not save a checkpoint: %+v", checkpointRepo.saved)
+	}
+}
+
+func TestUseCaseExecute_DryRunSkipsSaveAndCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 12, 0, 0, 0, time.UTC)
+	checkpointRepo := &mockSyncCheckpointRepository{
+		findCheckpoint: func(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+			t.Fatal("checkpoint must not be looked up for a dry run")
+			return mfdomain.SyncCheckpoint{}, false, nil
+		},
+	}
+	fetcher := &mockIncrementalMailFetcher{
+		mockMailFetcher: mockMailFetcher{
+			fetch: func(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
+				return []cd.FetchedEmailDTO{
+					{ID: "msg-1", Date: now, Body: "body-1"},
+					{ID: "msg-1", Date: now, Body: "body-1"},
+				}, nil, nil
+			},
+		},
+	}
+
+	uc := NewUseCase(
+		&mockConnectionRepository{
+			findUsableConnection: func(ctx context.Context, userID, connectionID uint) (mfdomain.ConnectionRef, error) {
+				return mfdomain.ConnectionRef{ConnectionID: connectionID, UserID: userID, Provider: "gmail", AccountIdentifier: "user@gmail.com"}, nil
+			},
+		},
+		&mockMailFetcherFactory{
+			create: func(ctx context.Context, conn mfdomain.ConnectionRef) (MailFetcher, error) {
+				return fetcher, nil
+			},
+		},
+		&mockEmailRepository{
+			saveAllIfAbsent: func(ctx context.Context, userID uint, source mfdomain.EmailSource, dtos []cd.FetchedEmailDTO) ([]mfdomain.SaveResult, []mfdomain.MessageFailure, error) {
+				t.Fatal("emails must not be saved for a dry run")
+				return nil, nil, nil
+			},
+		},
+		checkpointRepo,
+		nil,
+		&fixedClock{now: now},
+		logger.NewNop(),
+	)
+
+	result, err := uc.Execute(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 9,
+		Condition:    mfdomain.FetchCondition{LabelName: "billing", Since: now.Add(-24 * time.Hour), Until: now.Add(time.Hour)},
+		DryRun:       true,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(result.CreatedEmails) != 1 || result.CreatedEmails[0].EmailID != 0 || result.CreatedEmails[0].ExternalMessageID != "msg-1" {
+		t.Fatalf("unexpected created emails: %+v", result.CreatedEmails)
+	}
+	if result.CreatedEmails[0].BodyDigest != computeBodyDigest("body-1") {
+		t.Fatalf("unexpected body digest: %+v", result.CreatedEmails[0])
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Code != mfdomain.FailureCodeDuplicateExternalMessageID {
+		t.Fatalf("unexpected failures: %+v", result.Failures)
+	}
+	if len(checkpointRepo.saved) != 0 {
+		t.Fatalf("checkpoint must not advance for a dry run: %+v", checkpointRepo.saved)
+	}
+}
+
+type mockEmailBodyWriter struct {
+	saved []mfdomain.EmailBody
+	err   error
+}
+
+func (m *mockEmailBodyWriter) SaveBodies(ctx context.Context, userID uint, bodies []mfdomain.EmailBody) error {
+	m.saved = append(m.saved, bodies...)
+	return m.err
+}
+
+func TestUseCaseExecute_SavesBodiesOfReturnedEmails(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 23, 10, 0, 0, 0, time.UTC)
+	newUseCase := func(writer EmailBodyWriter) UseCase {
+		return NewUseCase(
+			&mockConnectionRepository{
+				findUsableConnection: func(ctx context.Context, userID, connectionID uint) (mfdomain.ConnectionRef, error) {
+					return mfdomain.ConnectionRef{ConnectionID: connectionID, UserID: userID, Provider: "gmail", AccountIdentifier: "user@gmail.com"}, nil
+				},
+			},
+			&mockMailFetcherFactory{
+				create: func(ctx context.Context, conn mfdomain.ConnectionRef) (MailFetcher, error) {
+					return &mockMailFetcher{
+						fetch: func(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
+							return []cd.FetchedEmailDTO{
+								{ID: "msg-new", Subject: "new", Body: "new body", Date: now, Attachments: []cd.FetchedAttachmentDTO{{Filename: "invoice.pdf", Text: "Total 100"}}},
+								{ID: "msg-old", Subject: "old", Body: "old body", Date: now},
+							}, nil, nil
+						},
+					}, nil
+				},
+			},
+			&mockEmailRepository{
+				saveAllIfAbsent: func(ctx context.Context, userID uint, source mfdomain.EmailSource, dtos []cd.FetchedEmailDTO) ([]mfdomain.SaveResult, []mfdomain.MessageFailure, error) {
+					return []mfdomain.SaveResult{
+						{EmailID: 1, ExternalMessageID: "msg-new", Status: mfdomain.SaveStatusCreated},
+						{EmailID: 2, ExternalMessageID: "msg-old", Status: mfdomain.SaveStatusExisting},
+					}, nil, nil
+				},
+			},
+			nil,
+			writer,
+			&fixedClock{now: now},
+			logger.NewNop(),
+		)
+	}
+	cmd := Command{
+		UserID:       5,
+		ConnectionID: 10,
+		Condition:    mfdomain.FetchCondition{LabelName: "billing", Since: now.Add(-time.Hour), Until: now.Add(time.Hour)},
+	}
+
+	writer := &mockEmailBodyWriter{}
+	if _, err := newUseCase(writer).Execute(context.Background(), cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writer.saved) != 1 || writer.saved[0].EmailID != 1 || writer.saved[0].Body != "new body" ||
+		writer.saved[0].BodyDigest != computeBodyDigest("new body") || len(writer.saved[0].Attachments) != 1 {
+		t.Fatalf("unexpected saved bodies: %+v", writer.saved)
+	}
+
+	// 既存メールは payload を受け取った場合だけ保存し直す。
+	writer = &mockEmailBodyWriter{}
+	cmd.IncludeExistingEmails = true
+	if _, err := newUseCase(writer).Execute(context.Background(), cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writer.saved) != 2 || writer.saved[1].EmailID != 2 {
+		t.Fatalf("unexpected saved bodies: %+v", writer.saved)
+	}
+
+	// 本文の保存に失敗しても取得結果は返す。
+	writer = &mockEmailBodyWriter{err: errors.New("store unavailable")}
+	result, err := newUseCase(writer).Execute(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("body save failure must not fail the fetch: %v", err)
+	}
+	if len(result.CreatedEmails) != 1 || len(result.Failures) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// dry-run は本文も保存しない。
+	writer = &mockEmailBodyWriter{}
+	cmd.DryRun = true
+	if _, err := newUseCase(writer).Execute(context.Background(), cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writer.saved) != 0 {
+		t.Fatalf("dry run must not save bodies: %+v", writer.saved)
+	}
+}
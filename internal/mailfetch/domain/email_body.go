@@ -0,0 +1,20 @@
+package domain
+
+import (
+	cd "business/internal/common/domain"
+	"time"
+)
+
+// EmailBody is the fetched content of a saved email, kept encrypted so that it can be analyzed
+// again without the provider connection.
+type EmailBody struct {
+	EmailID uint
+	// Body is the normalized text that BodyDigest was computed from.
+	Body       string
+	BodyDigest string
+	// Attachments carries the text extracted from PDF attachments at fetch time.
+	Attachments []cd.FetchedAttachmentDTO
+	StoredAt    time.Time
+	// ExpiresAt is when the retention period ends and the body is purged.
+	ExpiresAt time.Time
+}
@@ -11,14 +11,20 @@ var (
 	ErrConnectionNotFound = errors.New("mail account connection not found")
 	// ErrConnectionUnavailable is returned when the requested connection cannot be used for fetching.
 	ErrConnectionUnavailable = errors.New("mail account connection is unavailable")
+	// ErrFetchFilterUnsupported is returned when a provider cannot apply the search query or label filters.
+	ErrFetchFilterUnsupported = errors.New("fetch filter is unsupported by the mail provider")
 	// ErrProviderUnsupported is returned when a provider has no fetcher implementation.
 	ErrProviderUnsupported = errors.New("mail provider is unsupported")
 	// ErrProviderLabelNotFound is returned when the requested provider label does not exist.
 	ErrProviderLabelNotFound = errors.New("mail provider label not found")
 	// ErrProviderSessionBuildFailed is returned when a provider session cannot be initialized.
 	ErrProviderSessionBuildFailed = errors.New("mail provider session build failed")
+	// ErrLabelListUnsupported is returned when a provider has no labels to list.
+	ErrLabelListUnsupported = errors.New("label listing is unsupported by the mail provider")
 	// ErrProviderListFailed is returned when the provider list API call fails.
 	ErrProviderListFailed = errors.New("mail provider list failed")
+	// ErrEmailBodyNotFound is returned when the body of an email is not stored, or its retention period has ended.
+	ErrEmailBodyNotFound = errors.New("email body not found")
 	// ErrEmailSourceInvalid is returned when provider/account source metadata is missing.
 	ErrEmailSourceInvalid = errors.New("email source is invalid")
 )
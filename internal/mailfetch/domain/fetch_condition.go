@@ -4,21 +4,56 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// MaxFetchQueryLength is the longest search query a fetch condition accepts.
+const MaxFetchQueryLength = 1024
+
 // FetchCondition represents the required mail fetch condition.
 type FetchCondition struct {
 	LabelName string
 	Since     time.Time
 	Until     time.Time
+	// MessageIDs limits the fetch to these provider message IDs instead of listing the label.
+	MessageIDs []string
+	// Filter narrows the label listing. Only the Gmail fetcher supports it.
+	Filter FetchFilter
+}
+
+// FetchFilter narrows which messages of the label are listed.
+type FetchFilter struct {
+	// Query is a provider search expression such as "from:billing@example.com subject:invoice".
+	Query string
+	// IncludeLabelNames are listed together with LabelName; a message needs only one of the labels.
+	IncludeLabelNames []string
+	// ExcludeLabelNames drops messages that carry any of these labels.
+	ExcludeLabelNames []string
+}
+
+// IsZero reports whether the filter leaves the label listing as is.
+func (f FetchFilter) IsZero() bool {
+	return strings.TrimSpace(f.Query) == "" && len(f.IncludeLabelNames) == 0 && len(f.ExcludeLabelNames) == 0
 }
 
 // Normalize trims free-form fields while preserving the provided timestamps.
 func (c FetchCondition) Normalize() FetchCondition {
 	c.LabelName = strings.TrimSpace(c.LabelName)
+	c.MessageIDs = normalizeValues(c.MessageIDs)
+	c.Filter = FetchFilter{
+		Query:             strings.TrimSpace(c.Filter.Query),
+		IncludeLabelNames: normalizeValues(c.Filter.IncludeLabelNames),
+		ExcludeLabelNames: normalizeValues(c.Filter.ExcludeLabelNames),
+	}
 	return c
 }
 
+// LabelNames returns LabelName followed by the included labels, without duplicates.
+func (c FetchCondition) LabelNames() []string {
+	normalized := c.Normalize()
+	return normalizeValues(append([]string{normalized.LabelName}, normalized.Filter.IncludeLabelNames...))
+}
+
 // Validate enforces the v1 fetch-condition invariants.
 func (c FetchCondition) Validate() error {
 	normalized := c.Normalize()
@@ -34,5 +69,40 @@ func (c FetchCondition) Validate() error {
 	if !normalized.Since.Before(normalized.Until) {
 		return fmt.Errorf("%w: since must be before until", ErrFetchConditionInvalid)
 	}
+	if utf8.RuneCountInString(normalized.Filter.Query) > MaxFetchQueryLength {
+		return fmt.Errorf("%w: query must be at most %d characters", ErrFetchConditionInvalid, MaxFetchQueryLength)
+	}
+	for _, excluded := range normalized.Filter.ExcludeLabelNames {
+		for _, included := range normalized.LabelNames() {
+			if excluded == included {
+				return fmt.Errorf("%w: label %s is both included and excluded", ErrFetchConditionInvalid, excluded)
+			}
+		}
+	}
 	return nil
 }
+
+// normalizeValues trims values and drops empty and repeated ones, keeping the first occurrence.
+func normalizeValues(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		normalized = append(normalized, value)
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+	return normalized
+}
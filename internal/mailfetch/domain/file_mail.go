@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// FileMail is one message of an uploaded .eml or mbox file, kept until a workflow fetches it.
+type FileMail struct {
+	ExternalMessageID string
+	// ReceivedAt is the message's Date header, or nil when the header is missing or unreadable.
+	ReceivedAt *time.Time
+	Raw        []byte
+}
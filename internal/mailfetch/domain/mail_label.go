@@ -0,0 +1,10 @@
+package domain
+
+// MailLabel is a provider label that a fetch condition can name.
+// MessagesTotal and MessagesUnread are nil when the provider did not report them.
+type MailLabel struct {
+	Name           string
+	System         bool
+	MessagesTotal  *int64
+	MessagesUnread *int64
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// SyncCheckpoint records how far a connection+label has been synchronized with the provider.
+type SyncCheckpoint struct {
+	ConnectionID      uint
+	AccountIdentifier string
+	LabelName         string
+	// HistoryID is the provider history cursor captured at SyncedAt.
+	HistoryID uint64
+	// CoveredSince and CoveredUntil are the received-at window that has been fetched up to the cursor.
+	CoveredSince time.Time
+	CoveredUntil time.Time
+	SyncedAt     time.Time
+}
+
+// Covers reports whether an incremental fetch from this checkpoint satisfies cond.
+// The previous window must start no later than cond.Since and reach the time the cursor was taken,
+// otherwise messages between the two windows would be missed.
+func (c SyncCheckpoint) Covers(cond FetchCondition) bool {
+	if c.HistoryID == 0 || c.CoveredSince.IsZero() || c.SyncedAt.IsZero() {
+		return false
+	}
+	if cond.Since.Before(c.CoveredSince) {
+		return false
+	}
+	return !c.CoveredUntil.Before(c.SyncedAt)
+}
+
+// SyncCursor is the provider cursor returned by a fetch.
+type SyncCursor struct {
+	HistoryID uint64
+	// Incremental is true when only messages added after the previous checkpoint were listed.
+	Incremental bool
+}
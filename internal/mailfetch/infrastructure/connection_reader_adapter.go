@@ -12,6 +12,7 @@ import (
 
 type connectionCredentialReader interface {
 	FindCredentialByIDAndUser(ctx context.Context, credentialID, userID uint) (macdomain.EmailCredential, error)
+	ListCredentialsByUser(ctx context.Context, userID uint) ([]macdomain.EmailCredential, error)
 }
 
 // MailAccountConnectionReaderAdapter resolves fetchable connection metadata from email_credentials.
@@ -41,12 +42,42 @@ func (a *MailAccountConnectionReaderAdapter) FindUsableConnection(ctx context.Co
 		return mfdomain.ConnectionRef{}, fmt.Errorf("failed to resolve mail account connection: %w", err)
 	}
 
+	ref, ok := usableConnectionRef(credential)
+	if !ok {
+		return mfdomain.ConnectionRef{}, mfdomain.ErrConnectionUnavailable
+	}
+	return ref, nil
+}
+
+// ListUsableConnections returns the user's connections that FindUsableConnection would accept, in creation order.
+// All of them are read with one query so that a sync-all request does not look them up one by one.
+func (a *MailAccountConnectionReaderAdapter) ListUsableConnections(ctx context.Context, userID uint) ([]mfdomain.ConnectionRef, error) {
+	credentials, err := a.repo.ListCredentialsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mail account connections: %w", err)
+	}
+
+	refs := make([]mfdomain.ConnectionRef, 0, len(credentials))
+	for _, credential := range credentials {
+		if ref, ok := usableConnectionRef(credential); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// usableConnectionRef returns ok=false when the credential lacks what fetching needs.
+func usableConnectionRef(credential macdomain.EmailCredential) (mfdomain.ConnectionRef, bool) {
 	provider := strings.ToLower(strings.TrimSpace(credential.Type))
 	accountIdentifier := strings.ToLower(strings.TrimSpace(credential.GmailAddress))
-	if provider == "" || accountIdentifier == "" ||
-		strings.TrimSpace(credential.AccessToken) == "" ||
-		strings.TrimSpace(credential.RefreshToken) == "" {
-		return mfdomain.ConnectionRef{}, mfdomain.ErrConnectionUnavailable
+	if provider == "" || accountIdentifier == "" {
+		return mfdomain.ConnectionRef{}, false
+	}
+	// IMAP connections keep their login in imap_connection_settings instead of OAuth tokens,
+	// and the file connection only reads uploaded messages.
+	if provider != "imap" && provider != macdomain.FileConnectionType &&
+		(strings.TrimSpace(credential.AccessToken) == "" || strings.TrimSpace(credential.RefreshToken) == "") {
+		return mfdomain.ConnectionRef{}, false
 	}
 
 	return mfdomain.ConnectionRef{
@@ -54,5 +85,5 @@ func (a *MailAccountConnectionReaderAdapter) FindUsableConnection(ctx context.Co
 		UserID:            credential.UserID,
 		Provider:          provider,
 		AccountIdentifier: accountIdentifier,
-	}, nil
+	}, true
 }
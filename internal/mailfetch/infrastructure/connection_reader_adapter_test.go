@@ -0,0 +1,65 @@
+package infrastructure
+
+import (
+	macdomain "business/internal/mailaccountconnection/domain"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stubConnectionCredentialReader struct {
+	credentials []macdomain.EmailCredential
+	listErr     error
+}
+
+func (s *stubConnectionCredentialReader) FindCredentialByIDAndUser(ctx context.Context, credentialID, userID uint) (macdomain.EmailCredential, error) {
+	for _, credential := range s.credentials {
+		if credential.ID == credentialID && credential.UserID == userID {
+			return credential, nil
+		}
+	}
+	return macdomain.EmailCredential{}, macdomain.ErrCredentialNotFound
+}
+
+func (s *stubConnectionCredentialReader) ListCredentialsByUser(ctx context.Context, userID uint) ([]macdomain.EmailCredential, error) {
+	return s.credentials, s.listErr
+}
+
+func TestMailAccountConnectionReaderAdapter_ListUsableConnections(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewMailAccountConnectionReaderAdapter(&stubConnectionCredentialReader{credentials: []macdomain.EmailCredential{
+		{ID: 30, UserID: 10, Type: "Gmail", GmailAddress: "A@example.com", AccessToken: "access", RefreshToken: "refresh"},
+		{ID: 31, UserID: 10, Type: "gmail", GmailAddress: "b@example.com"},
+		{ID: 32, UserID: 10, Type: "imap", GmailAddress: "c@example.com"},
+		{ID: 33, UserID: 10, Type: macdomain.FileConnectionType, GmailAddress: "uploaded-files"},
+		{ID: 34, UserID: 10, Type: "outlook", GmailAddress: ""},
+	}}, nil)
+	ctx := context.Background()
+
+	refs, err := adapter.ListUsableConnections(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, []mfdomain.ConnectionRef{
+		{ConnectionID: 30, UserID: 10, Provider: "gmail", AccountIdentifier: "a@example.com"},
+		{ConnectionID: 32, UserID: 10, Provider: "imap", AccountIdentifier: "c@example.com"},
+		{ConnectionID: 33, UserID: 10, Provider: macdomain.FileConnectionType, AccountIdentifier: "uploaded-files"},
+	}, refs)
+
+	// 一覧と 1 件の取得は同じ条件で利用できる連携を判定する。
+	_, err = adapter.FindUsableConnection(ctx, 10, 31)
+	require.ErrorIs(t, err, mfdomain.ErrConnectionUnavailable)
+	_, err = adapter.FindUsableConnection(ctx, 10, 99)
+	require.ErrorIs(t, err, mfdomain.ErrConnectionNotFound)
+}
+
+func TestMailAccountConnectionReaderAdapter_ListUsableConnections_ReturnsListError(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewMailAccountConnectionReaderAdapter(&stubConnectionCredentialReader{listErr: errors.New("db down")}, nil)
+
+	_, err := adapter.ListUsableConnections(context.Background(), 10)
+	require.Error(t, err)
+}
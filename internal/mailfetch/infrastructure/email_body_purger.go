@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	mfapp "business/internal/mailfetch/application"
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultEmailBodyPurgeInterval = time.Hour
+
+// EmailBodyPurger periodically deletes stored email bodies whose retention period has ended.
+// Every instance may run one; deleting an already deleted body is a no-op.
+type EmailBodyPurger struct {
+	bodies   mfapp.EmailBodyUseCase
+	clock    timewrapper.ClockInterface
+	interval time.Duration
+	log      logger.Interface
+}
+
+// NewEmailBodyPurger creates an in-process purger. A non-positive interval purges hourly.
+func NewEmailBodyPurger(
+	bodies mfapp.EmailBodyUseCase,
+	clock timewrapper.ClockInterface,
+	interval time.Duration,
+	log logger.Interface,
+) *EmailBodyPurger {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if interval <= 0 {
+		interval = defaultEmailBodyPurgeInterval
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &EmailBodyPurger{
+		bodies:   bodies,
+		clock:    clock,
+		interval: interval,
+		log:      log.With(logger.Component("email_body_purger")),
+	}
+}
+
+// Run purges expired bodies every interval until ctx is cancelled.
+func (p *EmailBodyPurger) Run(ctx context.Context) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if p.bodies == nil {
+		return errors.New("email body usecase is not configured")
+	}
+
+	p.log.Info("email_body_purger_started", logger.String("interval", p.interval.String()))
+
+	for {
+		purged, err := p.bodies.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			p.log.Error("email_body_purge_failed", logger.Err(err))
+		} else if purged > 0 {
+			p.log.Info("email_body_purged", logger.Int("purged_count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			p.log.Info("email_body_purger_stopping")
+			return nil
+		case <-p.clock.After(p.interval):
+		}
+	}
+}
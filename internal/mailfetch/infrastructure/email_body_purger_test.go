@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type stubEmailBodyUseCase struct {
+	purges atomic.Int32
+	err    error
+}
+
+func (s *stubEmailBodyUseCase) GetBody(ctx context.Context, userID, emailID uint) (mfdomain.EmailBody, error) {
+	return mfdomain.EmailBody{}, mfdomain.ErrEmailBodyNotFound
+}
+
+func (s *stubEmailBodyUseCase) GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error) {
+	return nil, nil
+}
+
+func (s *stubEmailBodyUseCase) PurgeExpired(ctx context.Context) (int, error) {
+	s.purges.Add(1)
+	return 1, s.err
+}
+
+func TestEmailBodyPurger_Run_PurgesUntilCancelled(t *testing.T) {
+	t.Parallel()
+
+	bodies := &stubEmailBodyUseCase{err: errors.New("temporary")}
+	purger := NewEmailBodyPurger(bodies, nil, 5*time.Millisecond, logger.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- purger.Run(ctx)
+	}()
+
+	deadline := time.After(time.Second)
+	for bodies.purges.Load() < 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("purger did not keep polling after an error: purges=%d", bodies.purges.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("purger did not stop after cancel")
+	}
+}
+
+func TestEmailBodyPurger_Run_RequiresUseCase(t *testing.T) {
+	t.Parallel()
+
+	purger := NewEmailBodyPurger(nil, nil, 0, nil)
+	if err := purger.Run(context.Background()); err == nil {
+		t.Fatal("expected an error without email body usecase")
+	}
+}
@@ -0,0 +1,341 @@
+package infrastructure
+
+import (
+	cd "business/internal/common/domain"
+	"business/internal/library/blobstore"
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultEmailBodyRetention is how long a stored body is kept when no retention is configured.
+	DefaultEmailBodyRetention = 90 * 24 * time.Hour
+	emailBodyObjectPrefix     = "email-bodies/"
+	emailBodyPurgeBatchSize   = 100
+)
+
+type emailBodyRecord struct {
+	EmailID    uint      `gorm:"column:email_id;primaryKey;autoIncrement:false"`
+	UserID     uint      `gorm:"column:user_id;not null;index:idx_email_bodies_user_id"`
+	ObjectKey  string    `gorm:"column:object_key;size:255;not null"`
+	BodyDigest string    `gorm:"column:body_digest;size:64;not null"`
+	StoredAt   time.Time `gorm:"column:stored_at;not null"`
+	ExpiresAt  time.Time `gorm:"column:expires_at;not null;index:idx_email_bodies_expires_at"`
+	CreatedAt  time.Time `gorm:"column:created_at;not null"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;not null"`
+}
+
+func (emailBodyRecord) TableName() string {
+	return "email_bodies"
+}
+
+// emailBodyPayload is the plaintext of a stored object, encrypted as a whole.
+type emailBodyPayload struct {
+	Body        string                    `json:"body"`
+	Attachments []cd.FetchedAttachmentDTO `json:"attachments,omitempty"`
+}
+
+type emailBodyCipher interface {
+	Encrypt(plaintext string) ([]byte, error)
+	Decrypt(ciphertext []byte) (string, error)
+}
+
+// EncryptedEmailBodyStore keeps email bodies encrypted with crypto.Vault in a blob store,
+// and their owner and retention in the email_bodies table.
+// A store without a blob store is disabled: nothing is saved and nothing is found.
+type EncryptedEmailBodyStore struct {
+	db        *gorm.DB
+	blobs     blobstore.Store
+	cipher    emailBodyCipher
+	retention time.Duration
+	clock     timewrapper.ClockInterface
+	log       logger.Interface
+}
+
+// NewEncryptedEmailBodyStore creates an email body store. A non-positive retention uses DefaultEmailBodyRetention.
+func NewEncryptedEmailBodyStore(
+	db *gorm.DB,
+	blobs blobstore.Store,
+	cipher emailBodyCipher,
+	retention time.Duration,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) *EncryptedEmailBodyStore {
+	if retention <= 0 {
+		retention = DefaultEmailBodyRetention
+	}
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &EncryptedEmailBodyStore{
+		db:        db,
+		blobs:     blobs,
+		cipher:    cipher,
+		retention: retention,
+		clock:     clock,
+		log:       log.With(logger.Component("email_body_store")),
+	}
+}
+
+// Enabled reports whether bodies are stored.
+func (s *EncryptedEmailBodyStore) Enabled() bool {
+	return s.blobs != nil
+}
+
+// SaveBodies encrypts and uploads each body, then records it with a fresh retention period.
+// Bodies that fail to upload are skipped and reported in the returned error.
+func (s *EncryptedEmailBodyStore) SaveBodies(ctx context.Context, userID uint, bodies []mfdomain.EmailBody) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if !s.Enabled() || len(bodies) == 0 {
+		return nil
+	}
+	if s.db == nil || s.cipher == nil {
+		return errors.New("email body store is not configured")
+	}
+
+	now := s.clock.Now().UTC()
+	records := make([]emailBodyRecord, 0, len(bodies))
+	var errs []error
+	for _, body := range bodies {
+		if body.EmailID == 0 {
+			continue
+		}
+		key := emailBodyObjectKey(body.EmailID)
+		if err := s.putBody(ctx, key, body); err != nil {
+			errs = append(errs, fmt.Errorf("email %d: %w", body.EmailID, err))
+			continue
+		}
+		records = append(records, emailBodyRecord{
+			EmailID:    body.EmailID,
+			UserID:     userID,
+			ObjectKey:  key,
+			BodyDigest: body.BodyDigest,
+			StoredAt:   now,
+			ExpiresAt:  now.Add(s.retention),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+
+	if len(records) > 0 {
+		err := s.db.WithContext(ctx).
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "email_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"object_key", "body_digest", "stored_at", "expires_at", "updated_at"}),
+			}).
+			Create(&records).Error
+		if err != nil {
+			s.logDBError(ctx, "save_bodies", err)
+			errs = append(errs, fmt.Errorf("failed to save email bodies: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *EncryptedEmailBodyStore) putBody(ctx context.Context, key string, body mfdomain.EmailBody) error {
+	plaintext, err := json.Marshal(emailBodyPayload{Body: body.Body, Attachments: body.Attachments})
+	if err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	ciphertext, err := s.cipher.Encrypt(string(plaintext))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt email body: %w", err)
+	}
+	if err := s.blobs.Put(ctx, key, ciphertext); err != nil {
+		return fmt.Errorf("failed to upload email body: %w", err)
+	}
+	return nil
+}
+
+// FindBodies returns the bodies of the user's emails whose retention period has not ended.
+// A body whose object is missing or does not match its digest is left out and logged.
+func (s *EncryptedEmailBodyStore) FindBodies(ctx context.Context, userID uint, emailIDs []uint, now time.Time) ([]mfdomain.EmailBody, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if !s.Enabled() || len(emailIDs) == 0 {
+		return nil, nil
+	}
+	if s.db == nil || s.cipher == nil {
+		return nil, errors.New("email body store is not configured")
+	}
+
+	reqLog := s.log
+	if withContext, err := s.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+
+	var records []emailBodyRecord
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND email_id IN ? AND expires_at > ?", userID, emailIDs, now.UTC()).
+		Order("email_id ASC").
+		Find(&records).Error
+	if err != nil {
+		s.logDBError(ctx, "find_bodies", err)
+		return nil, fmt.Errorf("failed to find email bodies: %w", err)
+	}
+
+	bodies := make([]mfdomain.EmailBody, 0, len(records))
+	for _, record := range records {
+		body, err := s.readBody(ctx, record)
+		if errors.Is(err, blobstore.ErrNotFound) {
+			reqLog.Warn("email_body_object_missing", logger.Uint("email_id", record.EmailID))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if computeEmailBodyDigest(body.Body) != record.BodyDigest {
+			reqLog.Error("email_body_digest_mismatch", logger.Uint("email_id", record.EmailID))
+			continue
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+func (s *EncryptedEmailBodyStore) readBody(ctx context.Context, record emailBodyRecord) (mfdomain.EmailBody, error) {
+	ciphertext, err := s.blobs.Get(ctx, record.ObjectKey)
+	if err != nil {
+		return mfdomain.EmailBody{}, fmt.Errorf("failed to download email body %d: %w", record.EmailID, err)
+	}
+	plaintext, err := s.cipher.Decrypt(ciphertext)
+	if err != nil {
+		return mfdomain.EmailBody{}, fmt.Errorf("failed to decrypt email body %d: %w", record.EmailID, err)
+	}
+	var payload emailBodyPayload
+	if err := json.Unmarshal([]byte(plaintext), &payload); err != nil {
+		return mfdomain.EmailBody{}, fmt.Errorf("failed to decode email body %d: %w", record.EmailID, err)
+	}
+	return mfdomain.EmailBody{
+		EmailID:     record.EmailID,
+		Body:        payload.Body,
+		BodyDigest:  record.BodyDigest,
+		Attachments: payload.Attachments,
+		StoredAt:    record.StoredAt,
+		ExpiresAt:   record.ExpiresAt,
+	}, nil
+}
+
+// DeleteExpired removes the objects and rows of bodies whose retention period ended at or before now.
+// A row is kept when its object cannot be deleted, so that the next run retries it.
+func (s *EncryptedEmailBodyStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	if ctx == nil {
+		return 0, logger.ErrNilContext
+	}
+	if !s.Enabled() {
+		return 0, nil
+	}
+	if s.db == nil {
+		return 0, errors.New("email body store is not configured")
+	}
+
+	deleted := 0
+	lastEmailID := uint(0)
+	for {
+		var found, removed int
+		var err error
+		//nolint:nplusonecheck // Expired bodies are purged in batches to bound each transaction.
+		lastEmailID, found, removed, err = s.deleteExpiredBatch(ctx, now.UTC(), lastEmailID)
+		deleted += removed
+		if err != nil {
+			return deleted, err
+		}
+		if found < emailBodyPurgeBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// deleteExpiredBatch locks one batch of expired rows while their objects are deleted, so that SaveBodies
+// cannot extend the retention of a row between the expiry check and the object deletion.
+// Only the rows whose objects were deleted are removed.
+func (s *EncryptedEmailBodyStore) deleteExpiredBatch(ctx context.Context, now time.Time, afterEmailID uint) (lastEmailID uint, found int, removed int, err error) {
+	reqLog := s.log
+	if withContext, withCtxErr := s.log.WithContext(ctx); withCtxErr == nil {
+		reqLog = withContext
+	}
+
+	lastEmailID = afterEmailID
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var records []emailBodyRecord
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("expires_at <= ? AND email_id > ?", now, afterEmailID).
+			Order("email_id ASC").
+			Limit(emailBodyPurgeBatchSize).
+			Find(&records).Error; err != nil {
+			s.logDBError(ctx, "find_expired_bodies", err)
+			return fmt.Errorf("failed to find expired email bodies: %w", err)
+		}
+		found = len(records)
+		if found == 0 {
+			return nil
+		}
+		lastEmailID = records[found-1].EmailID
+
+		emailIDs := make([]uint, 0, len(records))
+		for _, record := range records {
+			if err := s.blobs.Delete(ctx, record.ObjectKey); err != nil {
+				reqLog.Warn("email_body_object_delete_failed", logger.Uint("email_id", record.EmailID), logger.Err(err))
+				continue
+			}
+			emailIDs = append(emailIDs, record.EmailID)
+		}
+		if len(emailIDs) == 0 {
+			return nil
+		}
+
+		result := tx.Where("email_id IN ?", emailIDs).Delete(&emailBodyRecord{})
+		if result.Error != nil {
+			s.logDBError(ctx, "delete_expired_bodies", result.Error)
+			return fmt.Errorf("failed to delete expired email bodies: %w", result.Error)
+		}
+		removed = int(result.RowsAffected)
+		return nil
+	})
+	if err != nil {
+		return lastEmailID, found, 0, err
+	}
+	return lastEmailID, found, removed, nil
+}
+
+func (s *EncryptedEmailBodyStore) logDBError(ctx context.Context, operation string, err error) {
+	reqLog := s.log
+	if withContext, withCtxErr := s.log.WithContext(ctx); withCtxErr == nil {
+		reqLog = withContext
+	}
+
+	reqLog.Error("db_query_failed",
+		logger.String("db_system", "mysql"),
+		logger.String("table", "email_bodies"),
+		logger.String("operation", operation),
+		logger.Err(err),
+	)
+}
+
+func emailBodyObjectKey(emailID uint) string {
+	return emailBodyObjectPrefix + strconv.FormatUint(uint64(emailID), 10)
+}
+
+// computeEmailBodyDigest matches the body digest that the fetch use case stores in emails.
+func computeEmailBodyDigest(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
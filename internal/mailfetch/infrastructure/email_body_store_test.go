@@ -0,0 +1,104 @@
+package infrastructure
+
+import (
+	cd "business/internal/common/domain"
+	"business/internal/library/blobstore"
+	"business/internal/library/crypto"
+	"business/internal/library/logger"
+	"business/internal/library/mysql"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newEmailBodyTestVault(t *testing.T) *crypto.Vault {
+	t.Helper()
+
+	vault, err := crypto.NewVault(crypto.VaultConfig{
+		KeyMaterial: []byte("this-is-a-32-byte-key-material!!"),
+		Salt:        []byte("test-salt"),
+		Info:        "email-body-store-test",
+		BcryptCost:  bcrypt.MinCost,
+	})
+	require.NoError(t, err)
+	return vault
+}
+
+func TestEncryptedEmailBodyStore_SaveFindAndPurge(t *testing.T) {
+	t.Parallel()
+
+	mysqlConn, cleanup, err := mysql.CreateNewTestDB()
+	if err != nil {
+		skipIfEmailRepoDBUnavailable(t, err)
+	}
+	require.NoError(t, err)
+	defer cleanup()
+	require.NoError(t, mysqlConn.DB.AutoMigrate(&emailBodyRecord{}))
+
+	dir := t.TempDir()
+	blobs, err := blobstore.NewLocal(dir)
+	require.NoError(t, err)
+	nowUTC := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	store := NewEncryptedEmailBodyStore(mysqlConn.DB, blobs, newEmailBodyTestVault(t), 30*24*time.Hour, &emailRepoFixedClock{now: nowUTC}, logger.NewNop())
+	ctx := context.Background()
+
+	body := mfdomain.EmailBody{
+		EmailID:     11,
+		Body:        "ご請求金額 1,000円",
+		BodyDigest:  computeEmailBodyDigest("ご請求金額 1,000円"),
+		Attachments: []cd.FetchedAttachmentDTO{{Filename: "invoice.pdf", Text: "Total 1,000"}},
+	}
+	require.NoError(t, store.SaveBodies(ctx, 5, []mfdomain.EmailBody{body, {EmailID: 12, Body: "other", BodyDigest: computeEmailBodyDigest("other")}}))
+
+	// 保存先には平文を置かない。
+	raw, err := os.ReadFile(filepath.Join(dir, "email-bodies", "11"))
+	require.NoError(t, err)
+	require.False(t, strings.Contains(string(raw), "1,000"))
+
+	found, err := store.FindBodies(ctx, 5, []uint{11, 12, 13}, nowUTC)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, body.Body, found[0].Body)
+	require.Equal(t, body.Attachments, found[0].Attachments)
+	require.True(t, found[0].ExpiresAt.Equal(nowUTC.Add(30*24*time.Hour)))
+
+	// 他のユーザーのメール本文は返さない。
+	found, err = store.FindBodies(ctx, 6, []uint{11}, nowUTC)
+	require.NoError(t, err)
+	require.Empty(t, found)
+
+	// 保存期間を過ぎた本文は読めず、回収で削除される。
+	expiredAt := nowUTC.Add(31 * 24 * time.Hour)
+	found, err = store.FindBodies(ctx, 5, []uint{11}, expiredAt)
+	require.NoError(t, err)
+	require.Empty(t, found)
+
+	deleted, err := store.DeleteExpired(ctx, expiredAt)
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+	_, err = blobs.Get(ctx, "email-bodies/11")
+	require.ErrorIs(t, err, blobstore.ErrNotFound)
+}
+
+func TestEncryptedEmailBodyStore_DisabledWithoutBlobStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewEncryptedEmailBodyStore(nil, nil, nil, 0, nil, nil)
+	ctx := context.Background()
+
+	require.False(t, store.Enabled())
+	require.NoError(t, store.SaveBodies(ctx, 5, []mfdomain.EmailBody{{EmailID: 1, Body: "body"}}))
+	found, err := store.FindBodies(ctx, 5, []uint{1}, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, found)
+	deleted, err := store.DeleteExpired(ctx, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+}
@@ -0,0 +1,123 @@
+package infrastructure
+
+import (
+	cd "business/internal/common/domain"
+	gmaillib "business/internal/library/gmail"
+	"business/internal/library/logger"
+	"business/internal/library/mailmime"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"fmt"
+	"strings"
+)
+
+type fileMailReader interface {
+	ListFileMails(ctx context.Context, connectionID uint, labelName string, messageIDs []string) ([]mfdomain.FileMail, error)
+}
+
+// FileMailFetcherAdapter reads the messages of one upload, named by FetchCondition.LabelName,
+// from the user's file connection.
+type FileMailFetcherAdapter struct {
+	conn           mfdomain.ConnectionRef
+	reader         fileMailReader
+	bodyPreference mailmime.BodyPreference
+	log            logger.Interface
+}
+
+// NewFileMailFetcherAdapter creates a fetcher for uploaded .eml and mbox files.
+func NewFileMailFetcherAdapter(
+	conn mfdomain.ConnectionRef,
+	reader fileMailReader,
+	log logger.Interface,
+) *FileMailFetcherAdapter {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &FileMailFetcherAdapter{
+		conn:           conn,
+		reader:         reader,
+		bodyPreference: mailmime.PreferPlainText,
+		log:            log.With(logger.Component("manual_mail_fetch_file_fetcher")),
+	}
+}
+
+// WithBodyPreference selects the body of multipart/alternative messages by p.
+func (f *FileMailFetcherAdapter) WithBodyPreference(p mailmime.BodyPreference) *FileMailFetcherAdapter {
+	f.bodyPreference = p
+	return f
+}
+
+// Fetch parses the upload's messages received in the requested period.
+// Messages without a readable Date header are reported as normalize failures whatever the period is,
+// because the upload's period was taken from the messages that have one.
+func (f *FileMailFetcherAdapter) Fetch(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
+	if !cond.Filter.IsZero() {
+		return nil, nil, fmt.Errorf("%w: file", mfdomain.ErrFetchFilterUnsupported)
+	}
+	if f.reader == nil {
+		return nil, nil, fmt.Errorf("%w: file mail reader is not configured", mfdomain.ErrProviderSessionBuildFailed)
+	}
+
+	mails, err := f.reader.ListFileMails(ctx, f.conn.ConnectionID, cond.LabelName, cond.MessageIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
+	}
+
+	failures := make([]mfdomain.MessageFailure, 0)
+	if len(cond.MessageIDs) > 0 {
+		found := make(map[string]struct{}, len(mails))
+		for _, mail := range mails {
+			found[mail.ExternalMessageID] = struct{}{}
+		}
+		for _, messageID := range cond.MessageIDs {
+			if _, ok := found[messageID]; !ok {
+				failures = append(failures, fileFetchDetailFailure(messageID))
+			}
+		}
+	} else if len(mails) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", mfdomain.ErrProviderLabelNotFound, cond.LabelName)
+	}
+
+	fetched := make([]cd.FetchedEmailDTO, 0, len(mails))
+	for _, mail := range mails {
+		parsed, parseErr := mailmime.ParseWithPreference(mail.Raw, f.bodyPreference)
+		if parseErr != nil || parsed.Date.IsZero() {
+			failures = append(failures, mfdomain.MessageFailure{
+				ExternalMessageID: mail.ExternalMessageID,
+				Stage:             mfdomain.FailureStageNormalize,
+				Code:              mfdomain.FailureCodeInvalidFetchedEmail,
+				Message:           normalizeRawMessageFailureMessage(mail.ExternalMessageID, parseErr != nil),
+			})
+			continue
+		}
+
+		if parsed.Date.Before(cond.Since) || !parsed.Date.Before(cond.Until) {
+			continue
+		}
+
+		fetched = append(fetched, cd.FetchedEmailDTO{
+			ID:      mail.ExternalMessageID,
+			Subject: parsed.Subject,
+			From:    parsed.From,
+			To:      parsed.To,
+			Date:    parsed.Date,
+			// Strip HTML the same way as the Gmail client so that body digests mean the same for every provider.
+			Body: gmaillib.StripHTMLTags(parsed.Body),
+		})
+	}
+
+	return fetched, failures, nil
+}
+
+func fileFetchDetailFailure(externalMessageID string) mfdomain.MessageFailure {
+	externalMessageID = strings.TrimSpace(externalMessageID)
+	if externalMessageID == "" {
+		externalMessageID = "unknown"
+	}
+	return mfdomain.MessageFailure{
+		ExternalMessageID: externalMessageID,
+		Stage:             mfdomain.FailureStageFetchDetail,
+		Code:              mfdomain.FailureCodeFetchDetailFailed,
+		Message:           "取り込んだメールが見つかりませんでした。メールID=" + externalMessageID,
+	}
+}
@@ -0,0 +1,186 @@
+package infrastructure
+
+import (
+	"business/internal/library/mailmime"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubFileMailReader struct {
+	mails []mfdomain.FileMail
+	err   error
+
+	gotConnectionID uint
+	gotLabelName    string
+	gotMessageIDs   []string
+}
+
+func (s *stubFileMailReader) ListFileMails(ctx context.Context, connectionID uint, labelName string, messageIDs []string) ([]mfdomain.FileMail, error) {
+	s.gotConnectionID = connectionID
+	s.gotLabelName = labelName
+	s.gotMessageIDs = messageIDs
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(messageIDs) == 0 {
+		return s.mails, nil
+	}
+	wanted := make(map[string]struct{}, len(messageIDs))
+	for _, messageID := range messageIDs {
+		wanted[messageID] = struct{}{}
+	}
+	var mails []mfdomain.FileMail
+	for _, mail := range s.mails {
+		if _, ok := wanted[mail.ExternalMessageID]; ok {
+			mails = append(mails, mail)
+		}
+	}
+	return mails, nil
+}
+
+func fileTestMail(id string, raw []byte) mfdomain.FileMail {
+	return mfdomain.FileMail{ExternalMessageID: id, Raw: raw}
+}
+
+func newFileFetcherTestReader(since time.Time) *stubFileMailReader {
+	return &stubFileMailReader{mails: []mfdomain.FileMail{
+		fileTestMail("file:old", imapTestMessage(since.Add(-48*time.Hour), "old", "text/plain", "old")),
+		fileTestMail("file:invoice", imapTestMessage(since.Add(2*time.Hour), "invoice", "text/html; charset=utf-8", "<p>Total <b>1,000</b> JPY</p>")),
+		fileTestMail("file:undated", []byte(strings.Join([]string{"Subject: no date", "", "body"}, "\r\n"))),
+	}}
+}
+
+func newFileFetcherForReader(reader fileMailReader) *FileMailFetcherAdapter {
+	return NewFileMailFetcherAdapter(
+		mfdomain.ConnectionRef{ConnectionID: 7, UserID: 2, Provider: "file", AccountIdentifier: "uploaded-files"},
+		reader,
+		nil,
+	)
+}
+
+func TestFileMailFetcherAdapter_Fetch(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	reader := newFileFetcherTestReader(since)
+
+	fetched, failures, err := newFileFetcherForReader(reader).Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName: "imports/01",
+		Since:     since,
+		Until:     since.Add(24 * time.Hour),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), reader.gotConnectionID)
+	assert.Equal(t, "imports/01", reader.gotLabelName)
+
+	require.Len(t, fetched, 1)
+	assert.Equal(t, "file:invoice", fetched[0].ID)
+	assert.Equal(t, "invoice", fetched[0].Subject)
+	assert.Equal(t, "Billing <billing@vendor.example>", fetched[0].From)
+	assert.Equal(t, []string{"billing@example.com"}, fetched[0].To)
+	assert.True(t, fetched[0].Date.Equal(since.Add(2*time.Hour)))
+	assert.Equal(t, "Total 1,000 JPY", fetched[0].Body)
+
+	require.Len(t, failures, 1)
+	assert.Equal(t, "file:undated", failures[0].ExternalMessageID)
+	assert.Equal(t, mfdomain.FailureStageNormalize, failures[0].Stage)
+	assert.Equal(t, mfdomain.FailureCodeInvalidFetchedEmail, failures[0].Code)
+}
+
+func TestFileMailFetcherAdapter_Fetch_MessageIDs(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	reader := newFileFetcherTestReader(since)
+
+	fetched, failures, err := newFileFetcherForReader(reader).Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName:  "imports/01",
+		Since:      since,
+		Until:      since.Add(24 * time.Hour),
+		MessageIDs: []string{"file:invoice", "file:missing"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file:invoice", "file:missing"}, reader.gotMessageIDs)
+	require.Len(t, fetched, 1)
+	assert.Equal(t, "file:invoice", fetched[0].ID)
+
+	require.Len(t, failures, 1)
+	assert.Equal(t, "file:missing", failures[0].ExternalMessageID)
+	assert.Equal(t, mfdomain.FailureStageFetchDetail, failures[0].Stage)
+	assert.Equal(t, mfdomain.FailureCodeFetchDetailFailed, failures[0].Code)
+}
+
+func TestFileMailFetcherAdapter_Fetch_SelectsBodyByPreference(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	alternative := `multipart/alternative; boundary="alt"`
+	body := strings.Join([]string{
+		"--alt",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"See the HTML version",
+		"--alt",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<p>Total 1,000 JPY</p>",
+		"--alt--",
+	}, "\r\n")
+	reader := &stubFileMailReader{mails: []mfdomain.FileMail{
+		fileTestMail("file:alternative", imapTestMessage(since.Add(time.Hour), "invoice", alternative, body)),
+	}}
+	cond := mfdomain.FetchCondition{LabelName: "imports/01", Since: since, Until: since.Add(24 * time.Hour)}
+
+	fetched, _, err := newFileFetcherForReader(reader).Fetch(context.Background(), cond)
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	assert.Equal(t, "See the HTML version", fetched[0].Body)
+
+	fetched, _, err = newFileFetcherForReader(reader).WithBodyPreference(mailmime.PreferHTML).Fetch(context.Background(), cond)
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	assert.Equal(t, "Total 1,000 JPY", fetched[0].Body)
+}
+
+func TestFileMailFetcherAdapter_Fetch_ProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	cond := mfdomain.FetchCondition{LabelName: "imports/unknown", Since: since, Until: since.Add(time.Hour)}
+
+	t.Run("unknown upload is a label not found error", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := newFileFetcherForReader(&stubFileMailReader{}).Fetch(context.Background(), cond)
+
+		assert.ErrorIs(t, err, mfdomain.ErrProviderLabelNotFound)
+	})
+
+	t.Run("read failure is a list error", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := newFileFetcherForReader(&stubFileMailReader{err: errors.New("db down")}).Fetch(context.Background(), cond)
+
+		assert.ErrorIs(t, err, mfdomain.ErrProviderListFailed)
+	})
+
+	t.Run("search query is unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		filtered := cond
+		filtered.Filter = mfdomain.FetchFilter{Query: "from:billing@example.com"}
+
+		_, _, err := newFileFetcherForReader(&stubFileMailReader{}).Fetch(context.Background(), filtered)
+
+		assert.ErrorIs(t, err, mfdomain.ErrFetchFilterUnsupported)
+	})
+}
@@ -0,0 +1,167 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mailImportMessageRecord struct {
+	ID                uint64     `gorm:"column:id;primaryKey;autoIncrement"`
+	EmailCredentialID uint       `gorm:"column:email_credential_id;not null;uniqueIndex:uni_mail_import_messages_credential_label_message"`
+	LabelName         string     `gorm:"column:label_name;size:255;not null;uniqueIndex:uni_mail_import_messages_credential_label_message"`
+	ExternalMessageID string     `gorm:"column:external_message_id;size:255;not null;uniqueIndex:uni_mail_import_messages_credential_label_message"`
+	ReceivedAt        *time.Time `gorm:"column:received_at"`
+	RawMessage        []byte     `gorm:"column:raw_message;type:longblob;not null"`
+	CreatedAt         time.Time  `gorm:"column:created_at;not null"`
+}
+
+func (mailImportMessageRecord) TableName() string {
+	return "mail_import_messages"
+}
+
+// GormFileMailRepository keeps the messages of uploaded .eml and mbox files in the mail_import_messages table.
+// The label name identifies one upload within the user's file connection.
+type GormFileMailRepository struct {
+	db    *gorm.DB
+	clock timewrapper.ClockInterface
+	log   logger.Interface
+}
+
+// NewGormFileMailRepository creates a Gorm-backed repository for imported messages.
+func NewGormFileMailRepository(
+	db *gorm.DB,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) *GormFileMailRepository {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &GormFileMailRepository{
+		db:    db,
+		clock: clock,
+		log:   log.With(logger.Component("manual_mail_fetch_file_mail_repository")),
+	}
+}
+
+// SaveFileMails stores the messages of one upload. A message whose external ID is already stored under
+// the same label, such as the same .eml uploaded twice in one request, is kept once.
+func (r *GormFileMailRepository) SaveFileMails(ctx context.Context, connectionID uint, labelName string, mails []mfdomain.FileMail) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+	if len(mails) == 0 {
+		return nil
+	}
+
+	now := r.clock.Now().UTC()
+	records := make([]mailImportMessageRecord, 0, len(mails))
+	for _, mail := range mails {
+		record := mailImportMessageRecord{
+			EmailCredentialID: connectionID,
+			LabelName:         strings.TrimSpace(labelName),
+			ExternalMessageID: strings.TrimSpace(mail.ExternalMessageID),
+			RawMessage:        mail.Raw,
+			CreatedAt:         now,
+		}
+		if mail.ReceivedAt != nil {
+			receivedAt := mail.ReceivedAt.UTC()
+			record.ReceivedAt = &receivedAt
+		}
+		records = append(records, record)
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(records); start += saveBatchSize {
+			end := min(start+saveBatchSize, len(records))
+			batch := records[start:end]
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&batch).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logDBError(ctx, "mail_import_messages", "create", err)
+		return fmt.Errorf("failed to save imported messages: %w", err)
+	}
+	return nil
+}
+
+// ListFileMails returns the messages of one upload in upload order.
+// When messageIDs is set, only those messages are returned.
+func (r *GormFileMailRepository) ListFileMails(ctx context.Context, connectionID uint, labelName string, messageIDs []string) ([]mfdomain.FileMail, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+
+	query := r.db.WithContext(ctx).
+		Where("email_credential_id = ? AND label_name = ?", connectionID, strings.TrimSpace(labelName))
+	if len(messageIDs) > 0 {
+		query = query.Where("external_message_id IN ?", messageIDs)
+	}
+
+	var records []mailImportMessageRecord
+	if err := query.Order("id ASC").Find(&records).Error; err != nil {
+		r.logDBError(ctx, "mail_import_messages", "list", err)
+		return nil, fmt.Errorf("failed to list imported messages: %w", err)
+	}
+
+	mails := make([]mfdomain.FileMail, 0, len(records))
+	for _, record := range records {
+		mails = append(mails, mfdomain.FileMail{
+			ExternalMessageID: record.ExternalMessageID,
+			ReceivedAt:        record.ReceivedAt,
+			Raw:               record.RawMessage,
+		})
+	}
+	return mails, nil
+}
+
+// DeleteFileMails removes the messages of one upload, such as one whose workflow was not accepted.
+func (r *GormFileMailRepository) DeleteFileMails(ctx context.Context, connectionID uint, labelName string) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	if err := r.db.WithContext(ctx).
+		Where("email_credential_id = ? AND label_name = ?", connectionID, strings.TrimSpace(labelName)).
+		Delete(&mailImportMessageRecord{}).Error; err != nil {
+		r.logDBError(ctx, "mail_import_messages", "delete", err)
+		return fmt.Errorf("failed to delete imported messages: %w", err)
+	}
+	return nil
+}
+
+func (r *GormFileMailRepository) logDBError(ctx context.Context, table string, operation string, err error) {
+	reqLog := r.log
+	if withContext, withCtxErr := r.log.WithContext(ctx); withCtxErr == nil {
+		reqLog = withContext
+	}
+
+	reqLog.Error("db_query_failed",
+		logger.String("db_system", "mysql"),
+		logger.String("table", table),
+		logger.String("operation", operation),
+		logger.Err(err),
+	)
+}
@@ -0,0 +1,67 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/mysql"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormFileMailRepository_SaveAndList(t *testing.T) {
+	t.Parallel()
+
+	mysqlConn, cleanup, err := mysql.CreateNewTestDB()
+	if err != nil {
+		skipIfEmailRepoDBUnavailable(t, err)
+	}
+	require.NoError(t, err)
+	defer cleanup()
+	require.NoError(t, mysqlConn.DB.AutoMigrate(&mailImportMessageRecord{}))
+
+	nowUTC := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	repo := NewGormFileMailRepository(mysqlConn.DB, &emailRepoFixedClock{now: nowUTC}, logger.NewNop())
+	ctx := context.Background()
+	receivedAt := nowUTC.Add(-24 * time.Hour)
+
+	require.NoError(t, repo.SaveFileMails(ctx, 7, "imports/01", []mfdomain.FileMail{
+		{ExternalMessageID: "file:a", ReceivedAt: &receivedAt, Raw: []byte("Subject: a\r\n\r\na")},
+		{ExternalMessageID: "file:b", Raw: []byte("Subject: b\r\n\r\nb")},
+		// 同じアップロード内の重複は 1 件だけ残す。
+		{ExternalMessageID: "file:a", ReceivedAt: &receivedAt, Raw: []byte("Subject: a\r\n\r\na")},
+	}))
+	require.NoError(t, repo.SaveFileMails(ctx, 7, "imports/02", []mfdomain.FileMail{
+		{ExternalMessageID: "file:a", ReceivedAt: &receivedAt, Raw: []byte("Subject: a\r\n\r\na")},
+	}))
+
+	mails, err := repo.ListFileMails(ctx, 7, "imports/01", nil)
+	require.NoError(t, err)
+	require.Len(t, mails, 2)
+	assert.Equal(t, "file:a", mails[0].ExternalMessageID)
+	require.NotNil(t, mails[0].ReceivedAt)
+	assert.True(t, mails[0].ReceivedAt.Equal(receivedAt))
+	assert.Equal(t, "Subject: a\r\n\r\na", string(mails[0].Raw))
+	assert.Nil(t, mails[1].ReceivedAt)
+
+	mails, err = repo.ListFileMails(ctx, 7, "imports/01", []string{"file:b", "file:missing"})
+	require.NoError(t, err)
+	require.Len(t, mails, 1)
+	assert.Equal(t, "file:b", mails[0].ExternalMessageID)
+
+	mails, err = repo.ListFileMails(ctx, 8, "imports/01", nil)
+	require.NoError(t, err)
+	assert.Empty(t, mails)
+
+	// 削除は同じアップロードのメールだけを対象にする。
+	require.NoError(t, repo.DeleteFileMails(ctx, 7, "imports/01"))
+	mails, err = repo.ListFileMails(ctx, 7, "imports/01", nil)
+	require.NoError(t, err)
+	assert.Empty(t, mails)
+	mails, err = repo.ListFileMails(ctx, 7, "imports/02", nil)
+	require.NoError(t, err)
+	assert.Len(t, mails, 1)
+}
@@ -48,20 +48,98 @@ func NewGmailMailFetcherAdapter(
 }
 
 // Fetch loads message details for the configured connection and filters them by the requested period.
+// When cond.MessageIDs is set, the label listing is skipped and only those messages are loaded.
 func (f *GmailMailFetcherAdapter) Fetch(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
 	client, err := f.builder.Build(ctx, f.conn.ConnectionID, f.conn.UserID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderSessionBuildFailed, err)
 	}
 
-	messageIDs, err := client.GetMessagesByLabelName(ctx, cond.LabelName, cond.Since)
+	messageIDs := cond.MessageIDs
+	if len(messageIDs) == 0 {
+		messageIDs, err = listGmailMessageIDs(ctx, client, cond)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	fetched, failures := loadGmailMessages(ctx, client, messageIDs, cond)
+	return fetched, failures, nil
+}
+
+// FetchIncremental lists only messages added to the label after checkpoint.HistoryID.
+// Without a checkpoint, or when Gmail no longer keeps that history, it falls back to a full listing
+// and captures the current history ID before listing so that the next call can resume from it.
+func (f *GmailMailFetcherAdapter) FetchIncremental(
+	ctx context.Context,
+	cond mfdomain.FetchCondition,
+	checkpoint *mfdomain.SyncCheckpoint,
+) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, mfdomain.SyncCursor, error) {
+	client, err := f.builder.Build(ctx, f.conn.ConnectionID, f.conn.UserID)
+	if err != nil {
+		return nil, nil, mfdomain.SyncCursor{}, fmt.Errorf("%w: %v", mfdomain.ErrProviderSessionBuildFailed, err)
+	}
+
+	reqLog := f.log
+	if withContext, logErr := f.log.WithContext(ctx); logErr == nil {
+		reqLog = withContext
+	}
+
+	if checkpoint != nil && checkpoint.HistoryID != 0 {
+		messageIDs, historyID, listErr := client.ListAddedMessageIDs(ctx, cond.LabelName, checkpoint.HistoryID)
+		switch {
+		case listErr == nil:
+			fetched, failures := loadGmailMessages(ctx, client, messageIDs, cond)
+			return fetched, failures, mfdomain.SyncCursor{HistoryID: historyID, Incremental: true}, nil
+		case errors.Is(listErr, gmaillib.ErrLabelNotFound):
+			return nil, nil, mfdomain.SyncCursor{}, fmt.Errorf("%w: %s", mfdomain.ErrProviderLabelNotFound, cond.LabelName)
+		case errors.Is(listErr, gmaillib.ErrHistoryExpired):
+			reqLog.Warn("manual_mail_fetch_history_expired",
+				logger.Uint("connection_id", f.conn.ConnectionID),
+				logger.String("label_name", cond.LabelName),
+			)
+		default:
+			return nil, nil, mfdomain.SyncCursor{}, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, listErr)
+		}
+	}
+
+	historyID, err := client.GetCurrentHistoryID(ctx)
+	if err != nil {
+		return nil, nil, mfdomain.SyncCursor{}, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
+	}
+	messageIDs, err := listGmailMessageIDs(ctx, client, cond)
+	if err != nil {
+		return nil, nil, mfdomain.SyncCursor{}, err
+	}
+
+	fetched, failures := loadGmailMessages(ctx, client, messageIDs, cond)
+	return fetched, failures, mfdomain.SyncCursor{HistoryID: historyID}, nil
+}
+
+func listGmailMessageIDs(ctx context.Context, client gmailMessageClient, cond mfdomain.FetchCondition) ([]string, error) {
+	// Until is sent as before: so that backfilling a closed period does not list every newer message.
+	messageIDs, err := client.ListMessageIDs(ctx, gmaillib.MessageQuery{
+		LabelNames:        cond.LabelNames(),
+		ExcludeLabelNames: cond.Filter.ExcludeLabelNames,
+		Since:             cond.Since,
+		Until:             cond.Until,
+		Query:             cond.Filter.Query,
+	})
 	if err != nil {
 		if errors.Is(err, gmaillib.ErrLabelNotFound) {
-			return nil, nil, fmt.Errorf("%w: %s", mfdomain.ErrProviderLabelNotFound, cond.LabelName)
+			return nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderLabelNotFound, err)
 		}
-		return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
+		return nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
 	}
+	return messageIDs, nil
+}
 
+func loadGmailMessages(
+	ctx context.Context,
+	client gmailMessageClient,
+	messageIDs []string,
+	cond mfdomain.FetchCondition,
+) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure) {
 	fetched := make([]cd.FetchedEmailDTO, 0, len(messageIDs))
 	failures := make([]mfdomain.MessageFailure, 0)
 	detailResults := fetchGmailDetailsConcurrently(ctx, client, messageIDs)
@@ -96,7 +174,7 @@ func (f *GmailMailFetcherAdapter) Fetch(ctx context.Context, cond mfdomain.Fetch
 		fetched = append(fetched, dto)
 	}
 
-	return fetched, failures, nil
+	return fetched, failures
 }
 
 func fetchGmailDetailsConcurrently(ctx context.Context, client gmailMessageClient, messageIDs []string) []gmailDetailFetchResult {
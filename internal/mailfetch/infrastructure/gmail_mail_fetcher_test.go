@@ -19,18 +19,33 @@ func (s *stubGmailClientBuilder) Build(ctx context.Context, connectionID, userID
 }
 
 type stubGmailMessageClient struct {
-	list   func(ctx context.Context, labelName string, startDate time.Time) ([]string, error)
-	detail func(ctx context.Context, id string) (cd.FetchedEmailDTO, error)
+	list           func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error)
+	detail         func(ctx context.Context, id string) (cd.FetchedEmailDTO, error)
+	currentHistory func(ctx context.Context) (uint64, error)
+	history        func(ctx context.Context, labelName string, startHistoryID uint64) ([]string, uint64, error)
+	labels         func(ctx context.Context) ([]gmaillib.Label, error)
 }
 
-func (s *stubGmailMessageClient) GetMessagesByLabelName(ctx context.Context, labelName string, startDate time.Time) ([]string, error) {
-	return s.list(ctx, labelName, startDate)
+func (s *stubGmailMessageClient) ListMessageIDs(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
+	return s.list(ctx, q)
 }
 
 func (s *stubGmailMessageClient) GetGmailDetail(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
 	return s.detail(ctx, id)
 }
 
+func (s *stubGmailMessageClient) GetCurrentHistoryID(ctx context.Context) (uint64, error) {
+	return s.currentHistory(ctx)
+}
+
+func (s *stubGmailMessageClient) ListAddedMessageIDs(ctx context.Context, labelName string, startHistoryID uint64) ([]string, uint64, error) {
+	return s.history(ctx, labelName, startHistoryID)
+}
+
+func (s *stubGmailMessageClient) ListLabels(ctx context.Context) ([]gmaillib.Label, error) {
+	return s.labels(ctx)
+}
+
 func TestGmailMailFetcherAdapter_Fetch_FiltersUntilAndNormalizeFailures(t *testing.T) {
 	t.Parallel()
 
@@ -42,7 +57,7 @@ func TestGmailMailFetcherAdapter_Fetch_FiltersUntilAndNormalizeFailures(t *testi
 		&stubGmailClientBuilder{
 			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
 				return &stubGmailMessageClient{
-					list: func(ctx context.Context, labelName string, startDate time.Time) ([]string, error) {
+					list: func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
 						return []string{"msg-1", "msg-2", "msg-3", "msg-4"}, nil
 					},
 					detail: func(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
@@ -94,7 +109,7 @@ func TestGmailMailFetcherAdapter_Fetch_DetailFailureContinues(t *testing.T) {
 		&stubGmailClientBuilder{
 			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
 				return &stubGmailMessageClient{
-					list: func(ctx context.Context, labelName string, startDate time.Time) ([]string, error) {
+					list: func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
 						return []string{"msg-1", "msg-2"}, nil
 					},
 					detail: func(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
@@ -129,6 +144,49 @@ func TestGmailMailFetcherAdapter_Fetch_DetailFailureContinues(t *testing.T) {
 	}
 }
 
+func TestGmailMailFetcherAdapter_Fetch_MessageIDsSkipLabelListing(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	adapter := NewGmailMailFetcherAdapter(
+		mfdomain.ConnectionRef{ConnectionID: 1, UserID: 2, Provider: "gmail", AccountIdentifier: "user@gmail.com"},
+		&stubGmailClientBuilder{
+			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
+				return &stubGmailMessageClient{
+					list: func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
+						t.Fatal("label listing must be skipped when message ids are given")
+						return nil, nil
+					},
+					detail: func(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
+						if id == "msg-9" {
+							return cd.FetchedEmailDTO{}, errors.New("not found")
+						}
+						return cd.FetchedEmailDTO{ID: id, Date: now}, nil
+					},
+				}, nil
+			},
+		},
+		nil,
+	)
+
+	fetched, failures, err := adapter.Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName:  "billing",
+		Since:      now.Add(-time.Hour),
+		Until:      now.Add(time.Hour),
+		MessageIDs: []string{"msg-3", "msg-9"},
+	})
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+
+	if len(fetched) != 1 || fetched[0].ID != "msg-3" {
+		t.Fatalf("unexpected fetched messages: %+v", fetched)
+	}
+	if len(failures) != 1 || failures[0].ExternalMessageID != "msg-9" || failures[0].Code != mfdomain.FailureCodeFetchDetailFailed {
+		t.Fatalf("unexpected failures: %+v", failures)
+	}
+}
+
 func TestGmailMailFetcherAdapter_Fetch_LoadsDetailsConcurrentlyAndKeepsOrder(t *testing.T) {
 	t.Parallel()
 
@@ -142,7 +200,7 @@ func TestGmailMailFetcherAdapter_Fetch_LoadsDetailsConcurrentlyAndKeepsOrder(t *
 		&stubGmailClientBuilder{
 			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
 				return &stubGmailMessageClient{
-					list: func(ctx context.Context, labelName string, startDate time.Time) ([]string, error) {
+					list: func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
 						return []string{"msg-1", "msg-2", "msg-3"}, nil
 					},
 					detail: func(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
@@ -235,7 +293,7 @@ func TestGmailMailFetcherAdapter_Fetch_LabelNotFound(t *testing.T) {
 		&stubGmailClientBuilder{
 			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
 				return &stubGmailMessageClient{
-					list: func(ctx context.Context, labelName string, startDate time.Time) ([]string, error) {
+					list: func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
 						return nil, gmaillib.ErrLabelNotFound
 					},
 					detail: func(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
@@ -256,3 +314,141 @@ func TestGmailMailFetcherAdapter_Fetch_LabelNotFound(t *testing.T) {
 		t.Fatalf("expected ErrProviderLabelNotFound, got %v", err)
 	}
 }
+
+func TestGmailMailFetcherAdapter_Fetch_SendsFilterAndUntil(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 21, 0, 0, 0, 0, time.UTC)
+	adapter := NewGmailMailFetcherAdapter(
+		mfdomain.ConnectionRef{ConnectionID: 1, UserID: 2, Provider: "gmail", AccountIdentifier: "user@gmail.com"},
+		&stubGmailClientBuilder{
+			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
+				return &stubGmailMessageClient{
+					list: func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
+						if len(q.LabelNames) != 2 || q.LabelNames[0] != "billing" || q.LabelNames[1] != "receipts" {
+							t.Fatalf("unexpected labels: %v", q.LabelNames)
+						}
+						if len(q.ExcludeLabelNames) != 1 || q.ExcludeLabelNames[0] != "archived" {
+							t.Fatalf("unexpected excluded labels: %v", q.ExcludeLabelNames)
+						}
+						if !q.Since.Equal(since) || !q.Until.Equal(until) || q.Query != "from:billing@example.com" {
+							t.Fatalf("unexpected query: %+v", q)
+						}
+						return nil, nil
+					},
+				}, nil
+			},
+		},
+		nil,
+	)
+
+	_, _, err := adapter.Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName: "billing",
+		Since:     since,
+		Until:     until,
+		Filter: mfdomain.FetchFilter{
+			Query:             "from:billing@example.com",
+			IncludeLabelNames: []string{"receipts", "billing"},
+			ExcludeLabelNames: []string{"archived"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+}
+
+func TestGmailMailFetcherAdapter_FetchIncremental_UsesHistory(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	adapter := NewGmailMailFetcherAdapter(
+		mfdomain.ConnectionRef{ConnectionID: 1, UserID: 2, Provider: "gmail", AccountIdentifier: "user@gmail.com"},
+		&stubGmailClientBuilder{
+			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
+				return &stubGmailMessageClient{
+					list: func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
+						t.Fatal("label listing must be skipped while the checkpoint is valid")
+						return nil, nil
+					},
+					history: func(ctx context.Context, labelName string, startHistoryID uint64) ([]string, uint64, error) {
+						if labelName != "billing" || startHistoryID != 100 {
+							t.Fatalf("unexpected history request: label=%s start=%d", labelName, startHistoryID)
+						}
+						return []string{"msg-new"}, 120, nil
+					},
+					detail: func(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
+						return cd.FetchedEmailDTO{ID: id, Date: now}, nil
+					},
+				}, nil
+			},
+		},
+		nil,
+	)
+
+	fetched, failures, cursor, err := adapter.FetchIncremental(context.Background(), mfdomain.FetchCondition{
+		LabelName: "billing",
+		Since:     now.Add(-time.Hour),
+		Until:     now.Add(time.Hour),
+	}, &mfdomain.SyncCheckpoint{HistoryID: 100})
+	if err != nil {
+		t.Fatalf("FetchIncremental returned error: %v", err)
+	}
+	if len(fetched) != 1 || fetched[0].ID != "msg-new" || len(failures) != 0 {
+		t.Fatalf("unexpected result: fetched=%+v failures=%+v", fetched, failures)
+	}
+	if cursor != (mfdomain.SyncCursor{HistoryID: 120, Incremental: true}) {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+}
+
+func TestGmailMailFetcherAdapter_FetchIncremental_FallsBackWhenHistoryExpired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	var calls []string
+	adapter := NewGmailMailFetcherAdapter(
+		mfdomain.ConnectionRef{ConnectionID: 1, UserID: 2, Provider: "gmail", AccountIdentifier: "user@gmail.com"},
+		&stubGmailClientBuilder{
+			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
+				return &stubGmailMessageClient{
+					history: func(ctx context.Context, labelName string, startHistoryID uint64) ([]string, uint64, error) {
+						calls = append(calls, "history")
+						return nil, 0, gmaillib.ErrHistoryExpired
+					},
+					currentHistory: func(ctx context.Context) (uint64, error) {
+						calls = append(calls, "current")
+						return 500, nil
+					},
+					list: func(ctx context.Context, q gmaillib.MessageQuery) ([]string, error) {
+						calls = append(calls, "list")
+						return []string{"msg-1", "msg-2"}, nil
+					},
+					detail: func(ctx context.Context, id string) (cd.FetchedEmailDTO, error) {
+						return cd.FetchedEmailDTO{ID: id, Date: now}, nil
+					},
+				}, nil
+			},
+		},
+		nil,
+	)
+
+	fetched, _, cursor, err := adapter.FetchIncremental(context.Background(), mfdomain.FetchCondition{
+		LabelName: "billing",
+		Since:     now.Add(-time.Hour),
+		Until:     now.Add(time.Hour),
+	}, &mfdomain.SyncCheckpoint{HistoryID: 1})
+	if err != nil {
+		t.Fatalf("FetchIncremental returned error: %v", err)
+	}
+	if len(fetched) != 2 {
+		t.Fatalf("expected full listing result, got %+v", fetched)
+	}
+	if cursor != (mfdomain.SyncCursor{HistoryID: 500}) {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+	// 取りこぼしを防ぐため、現在の historyId は一覧取得より前に取得する。
+	if len(calls) != 3 || calls[0] != "history" || calls[1] != "current" || calls[2] != "list" {
+		t.Fatalf("unexpected call order: %v", calls)
+	}
+}
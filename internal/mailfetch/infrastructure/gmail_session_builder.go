@@ -8,15 +8,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
 
 	"golang.org/x/oauth2"
 	gmailapi "google.golang.org/api/gmail/v1"
 )
 
 type gmailMessageClient interface {
-	GetMessagesByLabelName(ctx context.Context, labelName string, startDate time.Time) ([]string, error)
+	ListMessageIDs(ctx context.Context, q gmail.MessageQuery) ([]string, error)
 	GetGmailDetail(ctx context.Context, id string) (cd.FetchedEmailDTO, error)
+	GetCurrentHistoryID(ctx context.Context) (uint64, error)
+	ListAddedMessageIDs(ctx context.Context, labelName string, startHistoryID uint64) ([]string, uint64, error)
+	ListLabels(ctx context.Context) ([]gmail.Label, error)
 }
 
 type credentialReader interface {
@@ -0,0 +1,219 @@
+package infrastructure
+
+import (
+	cd "business/internal/common/domain"
+	gmaillib "business/internal/library/gmail"
+	"business/internal/library/imap"
+	"business/internal/library/logger"
+	"business/internal/library/mailmime"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const imapExternalMessageIDPrefix = "imap:"
+
+type imapClientBuilder interface {
+	Build(ctx context.Context, connectionID, userID uint) (imapMailboxSession, error)
+}
+
+// IMAPMailFetcherAdapter fetches the messages of one IMAP mailbox, named by FetchCondition.LabelName,
+// for a single mail-account connection.
+type IMAPMailFetcherAdapter struct {
+	conn           mfdomain.ConnectionRef
+	builder        imapClientBuilder
+	bodyPreference mailmime.BodyPreference
+	log            logger.Interface
+}
+
+// NewIMAPMailFetcherAdapter creates an IMAP-backed mail fetcher.
+func NewIMAPMailFetcherAdapter(
+	conn mfdomain.ConnectionRef,
+	builder imapClientBuilder,
+	log logger.Interface,
+) *IMAPMailFetcherAdapter {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &IMAPMailFetcherAdapter{
+		conn:           conn,
+		builder:        builder,
+		bodyPreference: mailmime.PreferPlainText,
+		log:            log.With(logger.Component("manual_mail_fetch_imap_fetcher")),
+	}
+}
+
+// WithBodyPreference selects the body of multipart/alternative messages by p.
+func (f *IMAPMailFetcherAdapter) WithBodyPreference(p mailmime.BodyPreference) *IMAPMailFetcherAdapter {
+	f.bodyPreference = p
+	return f
+}
+
+// Fetch loads the messages of the mailbox received in the requested period.
+// When cond.MessageIDs is set, the search is skipped and only those messages are loaded.
+func (f *IMAPMailFetcherAdapter) Fetch(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
+	if !cond.Filter.IsZero() {
+		return nil, nil, fmt.Errorf("%w: imap", mfdomain.ErrFetchFilterUnsupported)
+	}
+	session, err := f.builder.Build(ctx, f.conn.ConnectionID, f.conn.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderSessionBuildFailed, err)
+	}
+	defer func() {
+		if logoutErr := session.Logout(context.WithoutCancel(ctx)); logoutErr != nil {
+			f.log.Warn("manual_mail_fetch_imap_logout_failed",
+				logger.Uint("connection_id", f.conn.ConnectionID),
+				logger.Err(logoutErr),
+			)
+		}
+	}()
+
+	mailbox, err := session.Select(ctx, cond.LabelName)
+	if err != nil {
+		if errors.Is(err, imap.ErrMailboxNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s", mfdomain.ErrProviderLabelNotFound, cond.LabelName)
+		}
+		return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
+	}
+
+	mailboxKey := imapMailboxKey(f.conn.AccountIdentifier, cond.LabelName)
+	failures := make([]mfdomain.MessageFailure, 0)
+
+	var uids []uint32
+	if len(cond.MessageIDs) > 0 {
+		for _, messageID := range cond.MessageIDs {
+			uid, ok := parseIMAPExternalMessageID(messageID, mailboxKey, mailbox.UIDValidity)
+			if !ok {
+				failures = append(failures, imapFetchDetailFailure(messageID))
+				continue
+			}
+			uids = append(uids, uid)
+		}
+	} else {
+		// SEARCH compares the server's internal date by day, so the window is widened by a day on both sides
+		// and the exact period is applied to the Date header below, as for Gmail.
+		uids, err = session.SearchUIDs(ctx, cond.Since.AddDate(0, 0, -1), cond.Until.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
+		}
+	}
+
+	if len(uids) == 0 {
+		return []cd.FetchedEmailDTO{}, failures, nil
+	}
+
+	fetched := make([]cd.FetchedEmailDTO, 0, len(uids))
+	fetchFailed := false
+	// Raw messages are fetched and parsed one batch at a time so that only one batch is held in memory.
+	for start := 0; start < len(uids); start += imap.FetchBatchSize {
+		batch := uids[start:min(start+imap.FetchBatchSize, len(uids))]
+
+		rawMessages := map[uint32][]byte{}
+		if !fetchFailed {
+			rawMessages, err = session.FetchMessages(ctx, batch)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, nil, ctx.Err()
+				}
+				// A failed FETCH closes the session, so the remaining batches are reported as failures too.
+				f.log.Warn("manual_mail_fetch_imap_fetch_failed",
+					logger.Uint("connection_id", f.conn.ConnectionID),
+					logger.Int("message_count", len(uids)-start),
+					logger.Err(err),
+				)
+				fetchFailed = true
+				rawMessages = map[uint32][]byte{}
+			}
+		}
+
+		for _, uid := range batch {
+			externalMessageID := imapExternalMessageID(mailboxKey, mailbox.UIDValidity, uid)
+			raw, ok := rawMessages[uid]
+			if !ok {
+				failures = append(failures, imapFetchDetailFailure(externalMessageID))
+				continue
+			}
+
+			parsed, parseErr := mailmime.ParseWithPreference(raw, f.bodyPreference)
+			if parseErr != nil || parsed.Date.IsZero() {
+				failures = append(failures, mfdomain.MessageFailure{
+					ExternalMessageID: externalMessageID,
+					Stage:             mfdomain.FailureStageNormalize,
+					Code:              mfdomain.FailureCodeInvalidFetchedEmail,
+					Message:           normalizeRawMessageFailureMessage(externalMessageID, parseErr != nil),
+				})
+				continue
+			}
+
+			if parsed.Date.Before(cond.Since) || !parsed.Date.Before(cond.Until) {
+				continue
+			}
+
+			fetched = append(fetched, cd.FetchedEmailDTO{
+				ID:      externalMessageID,
+				Subject: parsed.Subject,
+				From:    parsed.From,
+				To:      parsed.To,
+				Date:    parsed.Date,
+				// Strip HTML the same way as the Gmail client so that body digests mean the same for every provider.
+				Body: gmaillib.StripHTMLTags(parsed.Body),
+			})
+		}
+	}
+
+	return fetched, failures, nil
+}
+
+// imapMailboxKey identifies the account and mailbox in external message IDs without making them
+// longer than the emails.external_message_id column.
+func imapMailboxKey(accountIdentifier, mailbox string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(accountIdentifier)) + "\x00" + mailbox))
+	return hex.EncodeToString(sum[:8])
+}
+
+// imapExternalMessageID is "imap:<mailbox key>:<UIDVALIDITY>:<UID>", which stays the same for a message
+// as long as the server keeps the mailbox's UIDVALIDITY.
+func imapExternalMessageID(mailboxKey string, uidValidity, uid uint32) string {
+	return fmt.Sprintf("%s%s:%d:%d", imapExternalMessageIDPrefix, mailboxKey, uidValidity, uid)
+}
+
+func parseIMAPExternalMessageID(externalMessageID, mailboxKey string, uidValidity uint32) (uint32, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(externalMessageID), imapExternalMessageIDPrefix)
+	if !ok {
+		return 0, false
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) != 3 || parts[0] != mailboxKey || parts[1] != strconv.FormatUint(uint64(uidValidity), 10) {
+		return 0, false
+	}
+	uid, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil || uid == 0 {
+		return 0, false
+	}
+	return uint32(uid), true
+}
+
+func imapFetchDetailFailure(externalMessageID string) mfdomain.MessageFailure {
+	externalMessageID = strings.TrimSpace(externalMessageID)
+	if externalMessageID == "" {
+		externalMessageID = "unknown"
+	}
+	return mfdomain.MessageFailure{
+		ExternalMessageID: externalMessageID,
+		Stage:             mfdomain.FailureStageFetchDetail,
+		Code:              mfdomain.FailureCodeFetchDetailFailed,
+		Message:           "IMAPメール本文の取得に失敗しました。メールID=" + externalMessageID,
+	}
+}
+
+func normalizeRawMessageFailureMessage(externalMessageID string, malformed bool) string {
+	if malformed {
+		return "取得メール(" + externalMessageID + ")の形式が不正でした。"
+	}
+	return "取得メール(" + externalMessageID + ")の受信日時が不正でした。"
+}
@@ -0,0 +1,223 @@
+package infrastructure
+
+import (
+	"business/internal/library/imap"
+	"business/internal/library/imap/imaptest"
+	macdomain "business/internal/mailaccountconnection/domain"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubIMAPSettingsReader struct {
+	settings macdomain.IMAPSettings
+	err      error
+}
+
+func (s *stubIMAPSettingsReader) FindCredentialByIDAndUser(ctx context.Context, credentialID, userID uint) (macdomain.EmailCredential, error) {
+	if userID != 2 {
+		return macdomain.EmailCredential{}, macdomain.ErrCredentialNotFound
+	}
+	return macdomain.EmailCredential{ID: credentialID, UserID: userID, Type: "imap", GmailAddress: "billing@example.com"}, nil
+}
+
+func (s *stubIMAPSettingsReader) FindIMAPSettingsByCredentialID(ctx context.Context, credentialID uint) (macdomain.IMAPSettings, error) {
+	return s.settings, s.err
+}
+
+type reversingDecryptor struct{}
+
+func (reversingDecryptor) DecryptFromString(ciphertext string) (string, error) {
+	runes := []rune(ciphertext)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes), nil
+}
+
+func imapTestMessage(date time.Time, subject, contentType, body string) []byte {
+	return []byte(strings.Join([]string{
+		"Subject: " + subject,
+		"From: Billing <billing@vendor.example>",
+		"To: billing@example.com",
+		"Date: " + date.Format(time.RFC1123Z),
+		"Content-Type: " + contentType,
+		"",
+		body,
+	}, "\r\n"))
+}
+
+func newIMAPFetcherTestServer(t *testing.T, since time.Time) *imaptest.Server {
+	t.Helper()
+
+	server, err := imaptest.NewServer("billing@example.com", "secret", map[string]imaptest.Mailbox{
+		"請求書": {
+			UIDValidity: 42,
+			Messages: []imaptest.Message{
+				{UID: 1, InternalDate: since.Add(-48 * time.Hour), Raw: imapTestMessage(since.Add(-48*time.Hour), "old", "text/plain", "old")},
+				{UID: 2, InternalDate: since.Add(2 * time.Hour), Raw: imapTestMessage(since.Add(2*time.Hour), "invoice", "text/html; charset=utf-8", "<p>Total <b>1,000</b> JPY</p>")},
+				{UID: 3, InternalDate: since.Add(-time.Hour), Raw: imapTestMessage(since.Add(-time.Hour), "before since", "text/plain", "skip")},
+				{UID: 4, InternalDate: since.Add(3 * time.Hour), Raw: []byte("Subject: no date\r\n\r\nbody")},
+			},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newIMAPFetcherForServer(server *imaptest.Server) *IMAPMailFetcherAdapter {
+	builder := NewIMAPSessionBuilder(
+		&stubIMAPSettingsReader{settings: macdomain.IMAPSettings{
+			Host:     server.Host(),
+			Port:     server.Port(),
+			Security: imap.SecurityNone,
+			Username: "billing@example.com",
+			Password: "terces",
+		}},
+		reversingDecryptor{},
+		imap.NewDialer(time.Second, nil).WithPrivateAddresses(true),
+		nil,
+	)
+	return NewIMAPMailFetcherAdapter(
+		mfdomain.ConnectionRef{ConnectionID: 1, UserID: 2, Provider: "imap", AccountIdentifier: "billing@example.com"},
+		builder,
+		nil,
+	)
+}
+
+func expectedIMAPMessageID(uid string) string {
+	sum := sha256.Sum256([]byte("billing@example.com\x00請求書"))
+	return "imap:" + hex.EncodeToString(sum[:8]) + ":42:" + uid
+}
+
+func TestIMAPMailFetcherAdapter_Fetch_AgainstLocalServer(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	server := newIMAPFetcherTestServer(t, since)
+
+	fetched, failures, err := newIMAPFetcherForServer(server).Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName: "請求書",
+		Since:     since,
+		Until:     until,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	assert.Equal(t, expectedIMAPMessageID("2"), fetched[0].ID)
+	assert.Equal(t, "invoice", fetched[0].Subject)
+	assert.Equal(t, "Billing <billing@vendor.example>", fetched[0].From)
+	assert.Equal(t, []string{"billing@example.com"}, fetched[0].To)
+	assert.True(t, fetched[0].Date.Equal(since.Add(2*time.Hour)))
+	assert.Equal(t, "Total 1,000 JPY", fetched[0].Body)
+
+	require.Len(t, failures, 1)
+	assert.Equal(t, expectedIMAPMessageID("4"), failures[0].ExternalMessageID)
+	assert.Equal(t, mfdomain.FailureStageNormalize, failures[0].Stage)
+	assert.Equal(t, mfdomain.FailureCodeInvalidFetchedEmail, failures[0].Code)
+
+	assert.Contains(t, server.Commands(), `EXAMINE "&istsQmb4-"`)
+	assert.Contains(t, server.Commands(), "UID SEARCH SINCE 9-Oct-2026 BEFORE 13-Oct-2026")
+	assert.Contains(t, server.Commands(), "LOGOUT")
+}
+
+func TestIMAPMailFetcherAdapter_Fetch_FetchesInBatches(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	messages := make([]imaptest.Message, 0, imap.FetchBatchSize+1)
+	for uid := uint32(1); uid <= imap.FetchBatchSize+1; uid++ {
+		date := since.Add(time.Duration(uid) * time.Hour)
+		messages = append(messages, imaptest.Message{UID: uid, InternalDate: date, Raw: imapTestMessage(date, "invoice", "text/plain", "body")})
+	}
+	server, err := imaptest.NewServer("billing@example.com", "secret", map[string]imaptest.Mailbox{
+		"請求書": {UIDValidity: 42, Messages: messages},
+	})
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	fetched, failures, err := newIMAPFetcherForServer(server).Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName: "請求書",
+		Since:     since,
+		Until:     since.Add(24 * time.Hour),
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, fetched, imap.FetchBatchSize+1)
+	assert.Empty(t, failures)
+	fetchCommands := 0
+	for _, command := range server.Commands() {
+		if strings.HasPrefix(command, "UID FETCH ") {
+			fetchCommands++
+		}
+	}
+	assert.Equal(t, 2, fetchCommands)
+}
+
+func TestIMAPMailFetcherAdapter_Fetch_MessageIDs(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	server := newIMAPFetcherTestServer(t, since)
+
+	fetched, failures, err := newIMAPFetcherForServer(server).Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName:  "請求書",
+		Since:      since,
+		Until:      since.Add(24 * time.Hour),
+		MessageIDs: []string{expectedIMAPMessageID("2"), "imap:0000000000000000:42:2", expectedIMAPMessageID("9")},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	assert.Equal(t, expectedIMAPMessageID("2"), fetched[0].ID)
+
+	require.Len(t, failures, 2)
+	assert.Equal(t, "imap:0000000000000000:42:2", failures[0].ExternalMessageID)
+	assert.Equal(t, expectedIMAPMessageID("9"), failures[1].ExternalMessageID)
+	assert.Equal(t, mfdomain.FailureCodeFetchDetailFailed, failures[1].Code)
+
+	for _, command := range server.Commands() {
+		assert.NotContains(t, command, "SEARCH")
+	}
+}
+
+func TestIMAPMailFetcherAdapter_Fetch_ProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("unknown mailbox is a label not found error", func(t *testing.T) {
+		t.Parallel()
+		server := newIMAPFetcherTestServer(t, since)
+
+		_, _, err := newIMAPFetcherForServer(server).Fetch(context.Background(), mfdomain.FetchCondition{
+			LabelName: "Missing",
+			Since:     since,
+			Until:     since.Add(24 * time.Hour),
+		})
+
+		assert.ErrorIs(t, err, mfdomain.ErrProviderLabelNotFound)
+	})
+
+	t.Run("missing settings is a session build error", func(t *testing.T) {
+		t.Parallel()
+		adapter := NewIMAPMailFetcherAdapter(
+			mfdomain.ConnectionRef{ConnectionID: 1, UserID: 2, Provider: "imap", AccountIdentifier: "billing@example.com"},
+			NewIMAPSessionBuilder(&stubIMAPSettingsReader{err: macdomain.ErrIMAPSettingsNotFound}, reversingDecryptor{}, imap.NewDialer(time.Second, nil).WithPrivateAddresses(true), nil),
+			nil,
+		)
+
+		_, _, err := adapter.Fetch(context.Background(), mfdomain.FetchCondition{LabelName: "INBOX", Since: since, Until: since.Add(time.Hour)})
+
+		assert.ErrorIs(t, err, mfdomain.ErrProviderSessionBuildFailed)
+	})
+}
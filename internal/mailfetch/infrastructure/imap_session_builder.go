@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"business/internal/library/imap"
+	"business/internal/library/logger"
+	macdomain "business/internal/mailaccountconnection/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type imapMailboxSession interface {
+	Select(ctx context.Context, mailbox string) (imap.Mailbox, error)
+	SearchUIDs(ctx context.Context, since, before time.Time) ([]uint32, error)
+	FetchMessages(ctx context.Context, uids []uint32) (map[uint32][]byte, error)
+	Logout(ctx context.Context) error
+}
+
+type imapSettingsReader interface {
+	FindCredentialByIDAndUser(ctx context.Context, credentialID, userID uint) (macdomain.EmailCredential, error)
+	FindIMAPSettingsByCredentialID(ctx context.Context, credentialID uint) (macdomain.IMAPSettings, error)
+}
+
+type imapSessionDialer interface {
+	Dial(ctx context.Context, cfg imap.Config) (*imap.Session, error)
+}
+
+// IMAPSessionBuilder logs in to the IMAP server of a stored mail-account connection.
+type IMAPSessionBuilder struct {
+	settingsReader imapSettingsReader
+	vault          tokenDecryptor
+	dialer         imapSessionDialer
+	log            logger.Interface
+}
+
+// NewIMAPSessionBuilder creates an IMAP session builder.
+func NewIMAPSessionBuilder(
+	settingsReader imapSettingsReader,
+	vault tokenDecryptor,
+	dialer imapSessionDialer,
+	log logger.Interface,
+) *IMAPSessionBuilder {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &IMAPSessionBuilder{
+		settingsReader: settingsReader,
+		vault:          vault,
+		dialer:         dialer,
+		log:            log.With(logger.Component("manual_mail_fetch_imap_session_builder")),
+	}
+}
+
+// Build opens a logged-in session for the requested connection. The caller must Logout it.
+func (b *IMAPSessionBuilder) Build(ctx context.Context, connectionID, userID uint) (imapMailboxSession, error) {
+	credential, err := b.settingsReader.FindCredentialByIDAndUser(ctx, connectionID, userID)
+	if err != nil {
+		if errors.Is(err, macdomain.ErrCredentialNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to load credential: %w", err)
+	}
+
+	settings, err := b.settingsReader.FindIMAPSettingsByCredentialID(ctx, credential.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load imap settings: %w", err)
+	}
+
+	password, err := b.vault.DecryptFromString(settings.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt imap password: %w", err)
+	}
+
+	session, err := b.dialer.Dial(ctx, imap.Config{
+		Host:     settings.Host,
+		Port:     settings.Port,
+		Security: settings.Security,
+		Username: settings.Username,
+		Password: password,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to log in to imap server: %w", err)
+	}
+	return session, nil
+}
@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"business/internal/library/logger"
+	"business/internal/library/mailmime"
 	mfapp "business/internal/mailfetch/application"
 	mfdomain "business/internal/mailfetch/domain"
 	"context"
@@ -11,27 +12,53 @@ import (
 
 // DefaultMailFetcherFactory creates provider-specific fetchers for manualmailfetch.
 type DefaultMailFetcherFactory struct {
-	gmailBuilder *GmailSessionBuilder
-	log          logger.Interface
+	gmailBuilder   *GmailSessionBuilder
+	imapBuilder    *IMAPSessionBuilder
+	outlookBuilder *OutlookSessionBuilder
+	fileMails      *GormFileMailRepository
+	bodyPreference mailmime.BodyPreference
+	log            logger.Interface
 }
 
 // NewDefaultMailFetcherFactory creates a default fetcher factory.
-func NewDefaultMailFetcherFactory(gmailBuilder *GmailSessionBuilder, log logger.Interface) *DefaultMailFetcherFactory {
+func NewDefaultMailFetcherFactory(
+	gmailBuilder *GmailSessionBuilder,
+	imapBuilder *IMAPSessionBuilder,
+	outlookBuilder *OutlookSessionBuilder,
+	fileMails *GormFileMailRepository,
+	log logger.Interface,
+) *DefaultMailFetcherFactory {
 	if log == nil {
 		log = logger.NewNop()
 	}
 	return &DefaultMailFetcherFactory{
-		gmailBuilder: gmailBuilder,
-		log:          log.With(logger.Component("manual_mail_fetch_factory")),
+		gmailBuilder:   gmailBuilder,
+		imapBuilder:    imapBuilder,
+		outlookBuilder: outlookBuilder,
+		fileMails:      fileMails,
+		bodyPreference: mailmime.PreferPlainText,
+		log:            log.With(logger.Component("manual_mail_fetch_factory")),
 	}
 }
 
+// WithBodyPreference makes the IMAP and file fetchers select message bodies by p, as the Gmail client does.
+func (f *DefaultMailFetcherFactory) WithBodyPreference(p mailmime.BodyPreference) *DefaultMailFetcherFactory {
+	f.bodyPreference = p
+	return f
+}
+
 // Create chooses a fetcher implementation for the given provider.
 func (f *DefaultMailFetcherFactory) Create(ctx context.Context, conn mfdomain.ConnectionRef) (mfapp.MailFetcher, error) {
 	_ = ctx
 	switch strings.ToLower(strings.TrimSpace(conn.Provider)) {
 	case "gmail":
 		return NewGmailMailFetcherAdapter(conn, f.gmailBuilder, f.log), nil
+	case "imap":
+		return NewIMAPMailFetcherAdapter(conn, f.imapBuilder, f.log).WithBodyPreference(f.bodyPreference), nil
+	case "outlook":
+		return NewOutlookMailFetcherAdapter(conn, f.outlookBuilder, f.log), nil
+	case "file":
+		return NewFileMailFetcherAdapter(conn, f.fileMails, f.log).WithBodyPreference(f.bodyPreference), nil
 	default:
 		return nil, fmt.Errorf("%w: %s", mfdomain.ErrProviderUnsupported, conn.Provider)
 	}
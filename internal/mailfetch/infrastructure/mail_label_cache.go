@@ -0,0 +1,96 @@
+package infrastructure
+
+import (
+	redisclient "business/internal/library/redis"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultMailLabelCacheTTL = 5 * time.Minute
+	mailLabelCacheKeyPrefix  = "mail_fetch:labels:"
+)
+
+type mailLabelCacheEntry struct {
+	Name           string `json:"name"`
+	System         bool   `json:"system"`
+	MessagesTotal  *int64 `json:"messages_total,omitempty"`
+	MessagesUnread *int64 `json:"messages_unread,omitempty"`
+}
+
+// RedisMailLabelCache keeps listed labels in Redis for a short TTL.
+// The key includes the account identifier so that a relinked connection to another mailbox is listed again.
+type RedisMailLabelCache struct {
+	client redisclient.ClientInterface
+	ttl    time.Duration
+}
+
+// NewRedisMailLabelCache creates a Redis-backed label cache.
+func NewRedisMailLabelCache(client redisclient.ClientInterface, ttl time.Duration) *RedisMailLabelCache {
+	if ttl <= 0 {
+		ttl = defaultMailLabelCacheTTL
+	}
+	return &RedisMailLabelCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// FindLabels returns the cached labels of the connection. found is false when nothing is cached.
+func (c *RedisMailLabelCache) FindLabels(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, bool, error) {
+	if c.client == nil {
+		return nil, false, &redisclient.ErrRedisUnavailable{Err: errors.New("redis client is not configured")}
+	}
+
+	raw, found, err := c.client.GetString(ctx, mailLabelCacheKey(conn))
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	var entries []mailLabelCacheEntry
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cached labels: %w", err)
+	}
+
+	labels := make([]mfdomain.MailLabel, 0, len(entries))
+	for _, entry := range entries {
+		labels = append(labels, mfdomain.MailLabel{
+			Name:           entry.Name,
+			System:         entry.System,
+			MessagesTotal:  entry.MessagesTotal,
+			MessagesUnread: entry.MessagesUnread,
+		})
+	}
+	return labels, true, nil
+}
+
+// SaveLabels caches the labels of the connection for the configured TTL.
+func (c *RedisMailLabelCache) SaveLabels(ctx context.Context, conn mfdomain.ConnectionRef, labels []mfdomain.MailLabel) error {
+	if c.client == nil {
+		return &redisclient.ErrRedisUnavailable{Err: errors.New("redis client is not configured")}
+	}
+
+	entries := make([]mailLabelCacheEntry, 0, len(labels))
+	for _, label := range labels {
+		entries = append(entries, mailLabelCacheEntry{
+			Name:           label.Name,
+			System:         label.System,
+			MessagesTotal:  label.MessagesTotal,
+			MessagesUnread: label.MessagesUnread,
+		})
+	}
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode labels: %w", err)
+	}
+
+	return c.client.SetString(ctx, mailLabelCacheKey(conn), string(raw), c.ttl)
+}
+
+func mailLabelCacheKey(conn mfdomain.ConnectionRef) string {
+	return fmt.Sprintf("%s%d:%s", mailLabelCacheKeyPrefix, conn.ConnectionID, conn.AccountIdentifier)
+}
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	redisclient "business/internal/library/redis"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+type stubStringRedisClient struct {
+	redisclient.ClientInterface
+	values map[string]string
+	ttls   map[string]time.Duration
+}
+
+func (s *stubStringRedisClient) GetString(ctx context.Context, key string) (string, bool, error) {
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+func (s *stubStringRedisClient) SetString(ctx context.Context, key, value string, ttl time.Duration) error {
+	s.values[key] = value
+	s.ttls[key] = ttl
+	return nil
+}
+
+func TestRedisMailLabelCache_SaveAndFind(t *testing.T) {
+	t.Parallel()
+
+	client := &stubStringRedisClient{values: map[string]string{}, ttls: map[string]time.Duration{}}
+	cache := NewRedisMailLabelCache(client, 0)
+	ctx := context.Background()
+	conn := mfdomain.ConnectionRef{ConnectionID: 12, UserID: 7, Provider: "gmail", AccountIdentifier: "billing@example.com"}
+
+	if _, found, err := cache.FindLabels(ctx, conn); err != nil || found {
+		t.Fatalf("expected cache miss, found=%v err=%v", found, err)
+	}
+
+	total := int64(42)
+	if err := cache.SaveLabels(ctx, conn, []mfdomain.MailLabel{
+		{Name: "INBOX", System: true},
+		{Name: "billing", MessagesTotal: &total},
+	}); err != nil {
+		t.Fatalf("SaveLabels returned error: %v", err)
+	}
+	if ttl := client.ttls["mail_fetch:labels:12:billing@example.com"]; ttl != defaultMailLabelCacheTTL {
+		t.Fatalf("unexpected ttl: %s", ttl)
+	}
+
+	labels, found, err := cache.FindLabels(ctx, conn)
+	if err != nil || !found {
+		t.Fatalf("expected cache hit, found=%v err=%v", found, err)
+	}
+	if len(labels) != 2 || !labels[0].System || labels[1].MessagesTotal == nil || *labels[1].MessagesTotal != 42 || labels[1].MessagesUnread != nil {
+		t.Fatalf("unexpected cached labels: %+v", labels)
+	}
+
+	relinked := conn
+	relinked.AccountIdentifier = "other@example.com"
+	if _, found, _ := cache.FindLabels(ctx, relinked); found {
+		t.Fatal("labels of another mailbox must not be returned")
+	}
+}
@@ -0,0 +1,60 @@
+package infrastructure
+
+import (
+	"business/internal/library/gmail"
+	"business/internal/library/logger"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultMailLabelLister lists provider labels through the same sessions as the fetchers.
+// Only Gmail has labels; IMAP folders, Outlook folders and uploaded files are not listed.
+type DefaultMailLabelLister struct {
+	gmailBuilder gmailClientBuilder
+	log          logger.Interface
+}
+
+// NewDefaultMailLabelLister creates a label lister.
+func NewDefaultMailLabelLister(gmailBuilder gmailClientBuilder, log logger.Interface) *DefaultMailLabelLister {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &DefaultMailLabelLister{
+		gmailBuilder: gmailBuilder,
+		log:          log.With(logger.Component("mail_label_lister")),
+	}
+}
+
+// ListLabels returns the labels of the connection's mailbox.
+func (l *DefaultMailLabelLister) ListLabels(ctx context.Context, conn mfdomain.ConnectionRef) ([]mfdomain.MailLabel, error) {
+	if strings.ToLower(strings.TrimSpace(conn.Provider)) != "gmail" {
+		return nil, fmt.Errorf("%w: %s", mfdomain.ErrLabelListUnsupported, conn.Provider)
+	}
+
+	client, err := l.gmailBuilder.Build(ctx, conn.ConnectionID, conn.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderSessionBuildFailed, err)
+	}
+
+	gmailLabels, err := client.ListLabels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
+	}
+
+	labels := make([]mfdomain.MailLabel, 0, len(gmailLabels))
+	for _, label := range gmailLabels {
+		labels = append(labels, toMailLabel(label))
+	}
+	return labels, nil
+}
+
+func toMailLabel(label gmail.Label) mfdomain.MailLabel {
+	return mfdomain.MailLabel{
+		Name:           label.Name,
+		System:         label.Type == "system",
+		MessagesTotal:  label.MessagesTotal,
+		MessagesUnread: label.MessagesUnread,
+	}
+}
@@ -0,0 +1,99 @@
+package infrastructure
+
+import (
+	gmaillib "business/internal/library/gmail"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDefaultMailLabelLister_ListLabels_MapsGmailLabels(t *testing.T) {
+	t.Parallel()
+
+	total, unread := int64(42), int64(3)
+	lister := NewDefaultMailLabelLister(&stubGmailClientBuilder{
+		build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
+			if connectionID != 12 || userID != 7 {
+				t.Fatalf("unexpected connection: %d user: %d", connectionID, userID)
+			}
+			return &stubGmailMessageClient{
+				labels: func(ctx context.Context) ([]gmaillib.Label, error) {
+					return []gmaillib.Label{
+						{ID: "INBOX", Name: "INBOX", Type: "system"},
+						{ID: "Label_1", Name: "billing", Type: "user", MessagesTotal: &total, MessagesUnread: &unread},
+					}, nil
+				},
+			}, nil
+		},
+	}, nil)
+
+	labels, err := lister.ListLabels(context.Background(), mfdomain.ConnectionRef{ConnectionID: 12, UserID: 7, Provider: "gmail"})
+	if err != nil {
+		t.Fatalf("ListLabels returned error: %v", err)
+	}
+	if len(labels) != 2 || labels[0].Name != "INBOX" || !labels[0].System || labels[0].MessagesTotal != nil {
+		t.Fatalf("unexpected labels: %+v", labels)
+	}
+	if labels[1].Name != "billing" || labels[1].System || *labels[1].MessagesTotal != 42 || *labels[1].MessagesUnread != 3 {
+		t.Fatalf("unexpected user label: %+v", labels[1])
+	}
+}
+
+func TestDefaultMailLabelLister_ListLabels_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider string
+		build    func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error)
+		wantErr  error
+	}{
+		{
+			name:     "provider without labels",
+			provider: "imap",
+			wantErr:  mfdomain.ErrLabelListUnsupported,
+		},
+		{
+			name:     "session build failure",
+			provider: "gmail",
+			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
+				return nil, errors.New("failed to decrypt access token")
+			},
+			wantErr: mfdomain.ErrProviderSessionBuildFailed,
+		},
+		{
+			name:     "labels.list failure",
+			provider: "gmail",
+			build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
+				return &stubGmailMessageClient{
+					labels: func(ctx context.Context) ([]gmaillib.Label, error) {
+						return nil, errors.New("boom")
+					},
+				}, nil
+			},
+			wantErr: mfdomain.ErrProviderListFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lister := NewDefaultMailLabelLister(&stubGmailClientBuilder{
+				build: func(ctx context.Context, connectionID, userID uint) (gmailMessageClient, error) {
+					if tt.build == nil {
+						t.Fatal("Build must not be called")
+					}
+					return tt.build(ctx, connectionID, userID)
+				},
+			}, nil)
+
+			_, err := lister.ListLabels(context.Background(), mfdomain.ConnectionRef{ConnectionID: 12, UserID: 7, Provider: tt.provider})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unexpected error: got=%v want=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
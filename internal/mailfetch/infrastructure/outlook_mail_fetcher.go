@@ -0,0 +1,150 @@
+package infrastructure
+
+import (
+	cd "business/internal/common/domain"
+	gmaillib "business/internal/library/gmail"
+	"business/internal/library/logger"
+	"business/internal/library/msgraph"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type outlookClientBuilder interface {
+	Build(ctx context.Context, connectionID, userID uint) (outlookMailClient, error)
+}
+
+// OutlookMailFetcherAdapter fetches the messages of one Outlook mail folder, named by
+// FetchCondition.LabelName, for a single mail-account connection.
+type OutlookMailFetcherAdapter struct {
+	conn    mfdomain.ConnectionRef
+	builder outlookClientBuilder
+	log     logger.Interface
+}
+
+// NewOutlookMailFetcherAdapter creates a Graph-backed mail fetcher.
+func NewOutlookMailFetcherAdapter(
+	conn mfdomain.ConnectionRef,
+	builder outlookClientBuilder,
+	log logger.Interface,
+) *OutlookMailFetcherAdapter {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &OutlookMailFetcherAdapter{
+		conn:    conn,
+		builder: builder,
+		log:     log.With(logger.Component("manual_mail_fetch_outlook_fetcher")),
+	}
+}
+
+// Fetch loads the folder's messages received in the requested period.
+// When cond.MessageIDs is set, the folder listing is skipped and only those messages are loaded.
+func (f *OutlookMailFetcherAdapter) Fetch(ctx context.Context, cond mfdomain.FetchCondition) ([]cd.FetchedEmailDTO, []mfdomain.MessageFailure, error) {
+	if !cond.Filter.IsZero() {
+		return nil, nil, fmt.Errorf("%w: outlook", mfdomain.ErrFetchFilterUnsupported)
+	}
+	client, err := f.builder.Build(ctx, f.conn.ConnectionID, f.conn.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderSessionBuildFailed, err)
+	}
+
+	failures := make([]mfdomain.MessageFailure, 0)
+	var messages []msgraph.Message
+	if len(cond.MessageIDs) > 0 {
+		for _, messageID := range cond.MessageIDs {
+			message, err := client.GetMessage(ctx, messageID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, nil, ctx.Err()
+				}
+				failures = append(failures, outlookFetchDetailFailure(messageID))
+				continue
+			}
+			messages = append(messages, message)
+		}
+	} else {
+		folder, err := client.FindMailFolder(ctx, cond.LabelName)
+		if err != nil {
+			if errors.Is(err, msgraph.ErrMailFolderNotFound) {
+				return nil, nil, fmt.Errorf("%w: %s", mfdomain.ErrProviderLabelNotFound, cond.LabelName)
+			}
+			return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
+		}
+
+		messages, err = client.ListMessages(ctx, folder.ID, cond.Since, cond.Until)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", mfdomain.ErrProviderListFailed, err)
+		}
+	}
+
+	fetched := make([]cd.FetchedEmailDTO, 0, len(messages))
+	for _, message := range messages {
+		externalMessageID := strings.TrimSpace(message.ID)
+		if externalMessageID == "" || message.ReceivedDateTime.IsZero() {
+			failureID := fallbackExternalMessageID(externalMessageID, message.InternetMessageID)
+			if failureID == "" {
+				failureID = "unknown"
+			}
+			failures = append(failures, mfdomain.MessageFailure{
+				ExternalMessageID: failureID,
+				Stage:             mfdomain.FailureStageNormalize,
+				Code:              mfdomain.FailureCodeInvalidFetchedEmail,
+				Message:           normalizeFetchedEmailFailureMessage(failureID, message.ReceivedDateTime.IsZero()),
+			})
+			continue
+		}
+
+		if message.ReceivedDateTime.Before(cond.Since) || !message.ReceivedDateTime.Before(cond.Until) {
+			continue
+		}
+
+		to := make([]string, 0, len(message.ToRecipients))
+		for _, recipient := range message.ToRecipients {
+			if formatted := formatGraphRecipient(recipient); formatted != "" {
+				to = append(to, formatted)
+			}
+		}
+
+		fetched = append(fetched, cd.FetchedEmailDTO{
+			ID:      externalMessageID,
+			Subject: message.Subject,
+			From:    formatGraphRecipient(message.From),
+			To:      to,
+			Date:    message.ReceivedDateTime,
+			// Strip HTML the same way as the Gmail client so that body digests mean the same for every provider.
+			Body: gmaillib.StripHTMLTags(message.Body.Content),
+		})
+	}
+
+	return fetched, failures, nil
+}
+
+// formatGraphRecipient renders a recipient like the From / To headers the Gmail client returns.
+func formatGraphRecipient(recipient msgraph.Recipient) string {
+	name := strings.TrimSpace(recipient.EmailAddress.Name)
+	address := strings.TrimSpace(recipient.EmailAddress.Address)
+	switch {
+	case address == "":
+		return name
+	case name == "" || strings.EqualFold(name, address):
+		return address
+	default:
+		return name + " <" + address + ">"
+	}
+}
+
+func outlookFetchDetailFailure(externalMessageID string) mfdomain.MessageFailure {
+	externalMessageID = strings.TrimSpace(externalMessageID)
+	if externalMessageID == "" {
+		externalMessageID = "unknown"
+	}
+	return mfdomain.MessageFailure{
+		ExternalMessageID: externalMessageID,
+		Stage:             mfdomain.FailureStageFetchDetail,
+		Code:              mfdomain.FailureCodeFetchDetailFailed,
+		Message:           "Outlookメール本文の取得に失敗しました。メールID=" + externalMessageID,
+	}
+}
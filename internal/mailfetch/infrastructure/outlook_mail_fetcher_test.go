@@ -0,0 +1,243 @@
+package infrastructure
+
+import (
+	"business/internal/library/msgraph"
+	"business/internal/library/msgraph/graphtest"
+	macdomain "business/internal/mailaccountconnection/domain"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+type stubOutlookCredentialStore struct {
+	credential macdomain.EmailCredential
+
+	mu      sync.Mutex
+	updates []macdomain.EmailCredential
+}
+
+func (s *stubOutlookCredentialStore) FindCredentialByIDAndUser(ctx context.Context, credentialID, userID uint) (macdomain.EmailCredential, error) {
+	if credentialID != s.credential.ID || userID != s.credential.UserID {
+		return macdomain.EmailCredential{}, macdomain.ErrCredentialNotFound
+	}
+	return s.credential, nil
+}
+
+func (s *stubOutlookCredentialStore) UpdateCredentialTokens(ctx context.Context, cred macdomain.EmailCredential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = append(s.updates, cred)
+	return nil
+}
+
+func (s *stubOutlookCredentialStore) Updates() []macdomain.EmailCredential {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]macdomain.EmailCredential(nil), s.updates...)
+}
+
+type prefixVault struct{}
+
+func (prefixVault) DecryptFromString(ciphertext string) (string, error) {
+	return strings.TrimPrefix(ciphertext, "enc:"), nil
+}
+
+func (prefixVault) EncryptToString(plaintext string) (string, error) {
+	return "enc:" + plaintext, nil
+}
+
+func (prefixVault) DigestToString(plaintext string) (string, error) {
+	return "digest:" + plaintext, nil
+}
+
+type staticOutlookOAuthConfig struct {
+	cfg *oauth2.Config
+}
+
+func (s staticOutlookOAuthConfig) GetOutlookOAuthConfig(ctx context.Context) (*oauth2.Config, error) {
+	return s.cfg, nil
+}
+
+func newOutlookFetcherTestServer(t *testing.T, since time.Time) *graphtest.Server {
+	t.Helper()
+
+	server := graphtest.NewServer(msgraph.User{Mail: "billing@contoso.com"}, "access-0", "refresh-0", []graphtest.Folder{
+		{
+			ID:            "inbox-id",
+			DisplayName:   "受信トレイ",
+			WellKnownName: "inbox",
+			Children: []graphtest.Folder{
+				{
+					ID:          "invoices-id",
+					DisplayName: "請求書",
+					Messages: []msgraph.Message{
+						{ID: "old", Subject: "old", ReceivedDateTime: since.Add(-48 * time.Hour)},
+						{
+							ID:               "AAMk/invoice=",
+							Subject:          "invoice",
+							From:             msgraph.Recipient{EmailAddress: msgraph.EmailAddress{Name: "Billing", Address: "billing@vendor.example"}},
+							ToRecipients:     []msgraph.Recipient{{EmailAddress: msgraph.EmailAddress{Address: "billing@contoso.com"}}},
+							ReceivedDateTime: since.Add(2 * time.Hour),
+							Body:             msgraph.ItemBody{ContentType: "html", Content: "<p>Total <b>1,000</b> JPY</p>"},
+						},
+					},
+				},
+			},
+		},
+	})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newOutlookFetcherForServer(server *graphtest.Server, store *stubOutlookCredentialStore) *OutlookMailFetcherAdapter {
+	builder := NewOutlookSessionBuilder(
+		store,
+		prefixVault{},
+		staticOutlookOAuthConfig{cfg: &oauth2.Config{ClientID: "client-id", Endpoint: oauth2.Endpoint{TokenURL: server.TokenURL()}}},
+		msgraph.NewClientFactory(server.BaseURL(), nil, nil),
+		nil,
+	)
+	return NewOutlookMailFetcherAdapter(
+		mfdomain.ConnectionRef{ConnectionID: 1, UserID: 2, Provider: "outlook", AccountIdentifier: "billing@contoso.com"},
+		builder,
+		nil,
+	)
+}
+
+func outlookTestCredential(accessToken, refreshToken string, expiry time.Time) macdomain.EmailCredential {
+	return macdomain.EmailCredential{
+		ID:           1,
+		UserID:       2,
+		Type:         "outlook",
+		GmailAddress: "billing@contoso.com",
+		AccessToken:  "enc:" + accessToken,
+		RefreshToken: "enc:" + refreshToken,
+		TokenExpiry:  &expiry,
+	}
+}
+
+func TestOutlookMailFetcherAdapter_Fetch_AgainstLocalServer(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	server := newOutlookFetcherTestServer(t, since)
+	store := &stubOutlookCredentialStore{credential: outlookTestCredential("access-0", "refresh-0", time.Now().Add(time.Hour))}
+
+	fetched, failures, err := newOutlookFetcherForServer(server, store).Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName: "Inbox/請求書",
+		Since:     since,
+		Until:     until,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	require.Len(t, fetched, 1)
+	assert.Equal(t, "AAMk/invoice=", fetched[0].ID)
+	assert.Equal(t, "invoice", fetched[0].Subject)
+	assert.Equal(t, "Billing <billing@vendor.example>", fetched[0].From)
+	assert.Equal(t, []string{"billing@contoso.com"}, fetched[0].To)
+	assert.True(t, fetched[0].Date.Equal(since.Add(2*time.Hour)))
+	assert.Equal(t, "Total 1,000 JPY", fetched[0].Body)
+	assert.Empty(t, store.Updates(), "a valid access token must not be rewritten")
+}
+
+func TestOutlookMailFetcherAdapter_Fetch_PersistsRotatedTokens(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	server := newOutlookFetcherTestServer(t, since)
+	credential := outlookTestCredential("stale-access", "refresh-0", time.Now().Add(-time.Minute))
+	store := &stubOutlookCredentialStore{credential: credential}
+
+	fetched, _, err := newOutlookFetcherForServer(server, store).Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName: "Inbox/請求書",
+		Since:     since,
+		Until:     since.Add(24 * time.Hour),
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, fetched, 1)
+
+	updates := store.Updates()
+	require.Len(t, updates, 1)
+	assert.Equal(t, "enc:"+server.AccessToken(), updates[0].AccessToken)
+	assert.Equal(t, "digest:"+server.AccessToken(), updates[0].AccessTokenDigest)
+	assert.Equal(t, "enc:"+server.RefreshToken(), updates[0].RefreshToken)
+	assert.Equal(t, "digest:"+server.RefreshToken(), updates[0].RefreshTokenDigest)
+	assert.NotEqual(t, "refresh-0", server.RefreshToken())
+	require.NotNil(t, updates[0].TokenExpiry)
+	assert.True(t, updates[0].TokenExpiry.After(time.Now()))
+	assert.Equal(t, credential.UpdatedAt, updates[0].UpdatedAt)
+}
+
+func TestOutlookMailFetcherAdapter_Fetch_MessageIDs(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+	server := newOutlookFetcherTestServer(t, since)
+	store := &stubOutlookCredentialStore{credential: outlookTestCredential("access-0", "refresh-0", time.Now().Add(time.Hour))}
+
+	fetched, failures, err := newOutlookFetcherForServer(server, store).Fetch(context.Background(), mfdomain.FetchCondition{
+		LabelName:  "Inbox/請求書",
+		Since:      since,
+		Until:      since.Add(24 * time.Hour),
+		MessageIDs: []string{"AAMk/invoice=", "missing"},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	assert.Equal(t, "AAMk/invoice=", fetched[0].ID)
+
+	require.Len(t, failures, 1)
+	assert.Equal(t, "missing", failures[0].ExternalMessageID)
+	assert.Equal(t, mfdomain.FailureStageFetchDetail, failures[0].Stage)
+	assert.Equal(t, mfdomain.FailureCodeFetchDetailFailed, failures[0].Code)
+
+	for _, request := range server.Requests() {
+		assert.NotContains(t, request, "mailFolders")
+	}
+}
+
+func TestOutlookMailFetcherAdapter_Fetch_ProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("unknown folder is a label not found error", func(t *testing.T) {
+		t.Parallel()
+		server := newOutlookFetcherTestServer(t, since)
+		store := &stubOutlookCredentialStore{credential: outlookTestCredential("access-0", "refresh-0", time.Now().Add(time.Hour))}
+
+		_, _, err := newOutlookFetcherForServer(server, store).Fetch(context.Background(), mfdomain.FetchCondition{
+			LabelName: "Missing",
+			Since:     since,
+			Until:     since.Add(24 * time.Hour),
+		})
+
+		assert.ErrorIs(t, err, mfdomain.ErrProviderLabelNotFound)
+	})
+
+	t.Run("revoked refresh token is a session build error", func(t *testing.T) {
+		t.Parallel()
+		server := newOutlookFetcherTestServer(t, since)
+		store := &stubOutlookCredentialStore{credential: outlookTestCredential("stale-access", "revoked", time.Now().Add(-time.Minute))}
+
+		_, _, err := newOutlookFetcherForServer(server, store).Fetch(context.Background(), mfdomain.FetchCondition{
+			LabelName: "Inbox/請求書",
+			Since:     since,
+			Until:     since.Add(24 * time.Hour),
+		})
+
+		assert.ErrorIs(t, err, mfdomain.ErrProviderSessionBuildFailed)
+		assert.Empty(t, store.Updates())
+		assert.Empty(t, server.Requests())
+	})
+}
@@ -0,0 +1,197 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/msgraph"
+	macdomain "business/internal/mailaccountconnection/domain"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type outlookMailClient interface {
+	FindMailFolder(ctx context.Context, path string) (msgraph.MailFolder, error)
+	ListMessages(ctx context.Context, folderID string, since, until time.Time) ([]msgraph.Message, error)
+	GetMessage(ctx context.Context, messageID string) (msgraph.Message, error)
+}
+
+type outlookCredentialStore interface {
+	FindCredentialByIDAndUser(ctx context.Context, credentialID, userID uint) (macdomain.EmailCredential, error)
+	UpdateCredentialTokens(ctx context.Context, cred macdomain.EmailCredential) error
+}
+
+type outlookTokenVault interface {
+	DecryptFromString(ciphertext string) (string, error)
+	EncryptToString(plaintext string) (string, error)
+	DigestToString(plaintext string) (string, error)
+}
+
+type outlookOAuthConfigProvider interface {
+	GetOutlookOAuthConfig(ctx context.Context) (*oauth2.Config, error)
+}
+
+type graphClientFactory interface {
+	NewClient(httpClient *http.Client) *msgraph.Client
+}
+
+// OutlookSessionBuilder restores a Graph session from a stored Outlook mail-account connection.
+type OutlookSessionBuilder struct {
+	store       outlookCredentialStore
+	vault       outlookTokenVault
+	oauthConfig outlookOAuthConfigProvider
+	graph       graphClientFactory
+	log         logger.Interface
+}
+
+// NewOutlookSessionBuilder creates an Outlook session builder.
+func NewOutlookSessionBuilder(
+	store outlookCredentialStore,
+	vault outlookTokenVault,
+	oauthConfig outlookOAuthConfigProvider,
+	graph graphClientFactory,
+	log logger.Interface,
+) *OutlookSessionBuilder {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &OutlookSessionBuilder{
+		store:       store,
+		vault:       vault,
+		oauthConfig: oauthConfig,
+		graph:       graph,
+		log:         log.With(logger.Component("manual_mail_fetch_outlook_session_builder")),
+	}
+}
+
+// Build restores a Graph client for the requested connection. An expired access token is refreshed
+// here, so a revoked grant fails the build rather than the first Graph call.
+func (b *OutlookSessionBuilder) Build(ctx context.Context, connectionID, userID uint) (outlookMailClient, error) {
+	credential, err := b.store.FindCredentialByIDAndUser(ctx, connectionID, userID)
+	if err != nil {
+		if errors.Is(err, macdomain.ErrCredentialNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to load credential: %w", err)
+	}
+
+	accessToken, err := b.vault.DecryptFromString(credential.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt access token: %w", err)
+	}
+
+	refreshToken, err := b.vault.DecryptFromString(credential.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt refresh token: %w", err)
+	}
+
+	cfg, err := b.oauthConfig.GetOutlookOAuthConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load outlook oauth config: %w", err)
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+	}
+	if credential.TokenExpiry != nil {
+		token.Expiry = *credential.TokenExpiry
+	}
+
+	tokenSource := &persistingTokenSource{
+		ctx:             context.WithoutCancel(ctx),
+		base:            cfg.TokenSource(ctx, token),
+		lastAccessToken: accessToken,
+		credential:      credential,
+		store:           b.store,
+		vault:           b.vault,
+		log:             b.log,
+	}
+	if _, err := tokenSource.Token(); err != nil {
+		return nil, fmt.Errorf("failed to refresh outlook token: %w", err)
+	}
+
+	return b.graph.NewClient(oauth2.NewClient(ctx, tokenSource)), nil
+}
+
+// persistingTokenSource stores every newly issued token. Microsoft rotates the refresh token on
+// refresh, so keeping only the first one would eventually break the connection.
+type persistingTokenSource struct {
+	ctx  context.Context
+	base oauth2.TokenSource
+
+	mu              sync.Mutex
+	lastAccessToken string
+	credential      macdomain.EmailCredential
+
+	store outlookCredentialStore
+	vault outlookTokenVault
+	log   logger.Interface
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token.AccessToken == s.lastAccessToken {
+		return token, nil
+	}
+	s.lastAccessToken = token.AccessToken
+
+	// A failed save only costs a refresh on the next run while the previous refresh token is still valid.
+	if err := s.save(token); err != nil {
+		s.log.Warn("manual_mail_fetch_outlook_token_save_failed",
+			logger.Uint("connection_id", s.credential.ID),
+			logger.Err(err),
+		)
+	}
+	return token, nil
+}
+
+func (s *persistingTokenSource) save(token *oauth2.Token) error {
+	credential := s.credential
+
+	encAccess, err := s.vault.EncryptToString(token.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+	digestAccess, err := s.vault.DigestToString(token.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to digest access token: %w", err)
+	}
+	credential.AccessToken = encAccess
+	credential.AccessTokenDigest = digestAccess
+
+	if token.RefreshToken != "" {
+		encRefresh, err := s.vault.EncryptToString(token.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt refresh token: %w", err)
+		}
+		digestRefresh, err := s.vault.DigestToString(token.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("failed to digest refresh token: %w", err)
+		}
+		credential.RefreshToken = encRefresh
+		credential.RefreshTokenDigest = digestRefresh
+	}
+
+	expiry := token.Expiry
+	credential.TokenExpiry = &expiry
+
+	// updated_at is kept: the connection list shows it as the time the user last connected the account.
+	if err := s.store.UpdateCredentialTokens(s.ctx, credential); err != nil {
+		return err
+	}
+	s.credential = credential
+	return nil
+}
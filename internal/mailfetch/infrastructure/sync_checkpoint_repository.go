@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mailSyncCheckpointRecord struct {
+	ID                uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	ConnectionID      uint      `gorm:"column:connection_id;not null;uniqueIndex:uni_mail_sync_checkpoints_connection_label"`
+	AccountIdentifier string    `gorm:"column:account_identifier;size:255;not null"`
+	LabelName         string    `gorm:"column:label_name;size:255;not null;uniqueIndex:uni_mail_sync_checkpoints_connection_label"`
+	HistoryID         uint64    `gorm:"column:history_id;not null"`
+	CoveredSince      time.Time `gorm:"column:covered_since;not null"`
+	CoveredUntil      time.Time `gorm:"column:covered_until;not null"`
+	SyncedAt          time.Time `gorm:"column:synced_at;not null"`
+	CreatedAt         time.Time `gorm:"column:created_at;not null"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;not null"`
+}
+
+func (mailSyncCheckpointRecord) TableName() string {
+	return "mail_sync_checkpoints"
+}
+
+// GormSyncCheckpointRepository stores provider sync cursors in the mail_sync_checkpoints table.
+type GormSyncCheckpointRepository struct {
+	db    *gorm.DB
+	clock timewrapper.ClockInterface
+	log   logger.Interface
+}
+
+// NewGormSyncCheckpointRepository creates a Gorm-backed sync checkpoint repository.
+func NewGormSyncCheckpointRepository(
+	db *gorm.DB,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) *GormSyncCheckpointRepository {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &GormSyncCheckpointRepository{
+		db:    db,
+		clock: clock,
+		log:   log.With(logger.Component("manual_mail_fetch_sync_checkpoint_repository")),
+	}
+}
+
+// FindCheckpoint returns the checkpoint of the connection and label.
+// A checkpoint recorded for another mailbox address is treated as missing because its history ID is meaningless.
+func (r *GormSyncCheckpointRepository) FindCheckpoint(ctx context.Context, conn mfdomain.ConnectionRef, labelName string) (mfdomain.SyncCheckpoint, bool, error) {
+	if ctx == nil {
+		return mfdomain.SyncCheckpoint{}, false, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return mfdomain.SyncCheckpoint{}, false, fmt.Errorf("gorm db is not configured")
+	}
+
+	var records []mailSyncCheckpointRecord
+	err := r.db.WithContext(ctx).
+		Where("connection_id = ? AND label_name = ?", conn.ConnectionID, strings.TrimSpace(labelName)).
+		Limit(1).
+		Find(&records).Error
+	if err != nil {
+		r.logDBError(ctx, "mail_sync_checkpoints", "find_checkpoint", err)
+		return mfdomain.SyncCheckpoint{}, false, fmt.Errorf("failed to find mail sync checkpoint: %w", err)
+	}
+	if len(records) == 0 || records[0].AccountIdentifier != strings.TrimSpace(conn.AccountIdentifier) {
+		return mfdomain.SyncCheckpoint{}, false, nil
+	}
+
+	record := records[0]
+	return mfdomain.SyncCheckpoint{
+		ConnectionID:      record.ConnectionID,
+		AccountIdentifier: record.AccountIdentifier,
+		LabelName:         record.LabelName,
+		HistoryID:         record.HistoryID,
+		CoveredSince:      record.CoveredSince,
+		CoveredUntil:      record.CoveredUntil,
+		SyncedAt:          record.SyncedAt,
+	}, true, nil
+}
+
+// SaveCheckpoint inserts or replaces the checkpoint of the connection and label.
+func (r *GormSyncCheckpointRepository) SaveCheckpoint(ctx context.Context, checkpoint mfdomain.SyncCheckpoint) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	now := r.clock.Now().UTC()
+	record := mailSyncCheckpointRecord{
+		ConnectionID:      checkpoint.ConnectionID,
+		AccountIdentifier: strings.TrimSpace(checkpoint.AccountIdentifier),
+		LabelName:         strings.TrimSpace(checkpoint.LabelName),
+		HistoryID:         checkpoint.HistoryID,
+		CoveredSince:      checkpoint.CoveredSince.UTC(),
+		CoveredUntil:      checkpoint.CoveredUntil.UTC(),
+		SyncedAt:          checkpoint.SyncedAt.UTC(),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "connection_id"}, {Name: "label_name"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"account_identifier": record.AccountIdentifier,
+				"history_id":         record.HistoryID,
+				"covered_since":      record.CoveredSince,
+				"covered_until":      record.CoveredUntil,
+				"synced_at":          record.SyncedAt,
+				"updated_at":         record.UpdatedAt,
+			}),
+		}).
+		Create(&record).Error
+	if err != nil {
+		r.logDBError(ctx, "mail_sync_checkpoints", "save_checkpoint", err)
+		return fmt.Errorf("failed to save mail sync checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (r *GormSyncCheckpointRepository) logDBError(ctx context.Context, table string, operation string, err error) {
+	reqLog := r.log
+	if withContext, withCtxErr := r.log.WithContext(ctx); withCtxErr == nil {
+		reqLog = withContext
+	}
+
+	reqLog.Error("db_query_failed",
+		logger.String("db_system", "mysql"),
+		logger.String("table", table),
+		logger.String("operation", operation),
+		logger.Err(err),
+	)
+}
@@ -0,0 +1,60 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/mysql"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormSyncCheckpointRepository_SaveAndFind(t *testing.T) {
+	t.Parallel()
+
+	mysqlConn, cleanup, err := mysql.CreateNewTestDB()
+	if err != nil {
+		skipIfEmailRepoDBUnavailable(t, err)
+	}
+	require.NoError(t, err)
+	defer cleanup()
+	require.NoError(t, mysqlConn.DB.AutoMigrate(&mailSyncCheckpointRecord{}))
+
+	nowUTC := time.Date(2026, 3, 24, 12, 0, 0, 0, time.UTC)
+	repo := NewGormSyncCheckpointRepository(mysqlConn.DB, &emailRepoFixedClock{now: nowUTC}, logger.NewNop())
+	ctx := context.Background()
+	conn := mfdomain.ConnectionRef{ConnectionID: 10, UserID: 5, Provider: "gmail", AccountIdentifier: "user@gmail.com"}
+
+	_, found, err := repo.FindCheckpoint(ctx, conn, "billing")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	checkpoint := mfdomain.SyncCheckpoint{
+		ConnectionID:      10,
+		AccountIdentifier: "user@gmail.com",
+		LabelName:         "billing",
+		HistoryID:         100,
+		CoveredSince:      nowUTC.Add(-24 * time.Hour),
+		CoveredUntil:      nowUTC.Add(time.Hour),
+		SyncedAt:          nowUTC,
+	}
+	require.NoError(t, repo.SaveCheckpoint(ctx, checkpoint))
+
+	checkpoint.HistoryID = 200
+	checkpoint.SyncedAt = nowUTC.Add(time.Minute)
+	require.NoError(t, repo.SaveCheckpoint(ctx, checkpoint))
+
+	got, found, err := repo.FindCheckpoint(ctx, conn, " billing ")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(200), got.HistoryID)
+	require.True(t, got.SyncedAt.Equal(nowUTC.Add(time.Minute)))
+
+	// 連携先のメールアドレスが変わった場合は historyId を引き継がない。
+	conn.AccountIdentifier = "other@gmail.com"
+	_, found, err = repo.FindCheckpoint(ctx, conn, "billing")
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -0,0 +1,105 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrWorkflowCancelled is the context cause used when a user cancels a running workflow.
+	ErrWorkflowCancelled = errors.New("manual mail workflow cancelled")
+	// ErrWorkflowHistoryNotFound indicates the workflow does not exist for the user.
+	ErrWorkflowHistoryNotFound = errors.New("manual mail workflow history not found")
+	// ErrWorkflowNotCancellable indicates the workflow has already finished.
+	ErrWorkflowNotCancellable = errors.New("manual mail workflow is not cancellable")
+)
+
+// CancelCommand identifies the workflow the user wants to stop.
+type CancelCommand struct {
+	UserID     uint
+	WorkflowID string
+}
+
+// CancelResult reports the workflow status right after the cancel request.
+// Status is cancelled when the workflow had not started yet, and running when the
+// runner still has to observe the request and stop at the current stage.
+type CancelResult struct {
+	WorkflowID string
+	Status     string
+}
+
+// WorkflowCancelRepository records cancel requests on workflow history rows.
+type WorkflowCancelRepository interface {
+	RequestCancel(ctx context.Context, userID uint, workflowID string, requestedAt time.Time) (CancelResult, error)
+}
+
+// CancelUseCase accepts cancel requests for manual mail workflows.
+type CancelUseCase interface {
+	Cancel(ctx context.Context, cmd CancelCommand) (CancelResult, error)
+}
+
+type cancelUseCase struct {
+	repository WorkflowCancelRepository
+	clock      timewrapper.ClockInterface
+	log        logger.Interface
+}
+
+// NewCancelUseCase creates a workflow cancel use case.
+func NewCancelUseCase(
+	repository WorkflowCancelRepository,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) CancelUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &cancelUseCase{
+		repository: repository,
+		clock:      clock,
+		log:        log.With(logger.Component("manual_mail_workflow_cancel_usecase")),
+	}
+}
+
+// Cancel stops a queued workflow immediately or asks the runner to stop a running one.
+func (uc *cancelUseCase) Cancel(ctx context.Context, cmd CancelCommand) (CancelResult, error) {
+	if ctx == nil {
+		return CancelResult{}, logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return CancelResult{}, errors.New("workflow_cancel_repository is not configured")
+	}
+
+	cmd.WorkflowID = strings.TrimSpace(cmd.WorkflowID)
+	if cmd.UserID == 0 {
+		return CancelResult{}, fmt.Errorf("%w: user_id is required", ErrInvalidCommand)
+	}
+	if cmd.WorkflowID == "" {
+		return CancelResult{}, fmt.Errorf("%w: workflow_id is required", ErrInvalidCommand)
+	}
+
+	result, err := uc.repository.RequestCancel(ctx, cmd.UserID, cmd.WorkflowID, uc.clock.Now().UTC())
+	if err != nil {
+		return CancelResult{}, err
+	}
+
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+	reqLog.Info("manual_mail_workflow_cancel_requested",
+		logger.UserID(cmd.UserID),
+		logger.String("workflow_id", result.WorkflowID),
+		logger.String("status", result.Status),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubWorkflowCancelRepository struct {
+	requestCancel func(ctx context.Context, userID uint, workflowID string, requestedAt time.Time) (CancelResult, error)
+}
+
+func (s *stubWorkflowCancelRepository) RequestCancel(ctx context.Context, userID uint, workflowID string, requestedAt time.Time) (CancelResult, error) {
+	return s.requestCancel(ctx, userID, workflowID, requestedAt)
+}
+
+func TestCancelUseCase_Cancel_RequestsCancel(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)
+	uc := NewCancelUseCase(&stubWorkflowCancelRepository{
+		requestCancel: func(ctx context.Context, userID uint, workflowID string, requestedAt time.Time) (CancelResult, error) {
+			if userID != 7 || workflowID != "wf-1" {
+				t.Fatalf("unexpected cancel target: user=%d workflow=%q", userID, workflowID)
+			}
+			if !requestedAt.Equal(now) {
+				t.Fatalf("unexpected requested_at: %v", requestedAt)
+			}
+			return CancelResult{WorkflowID: workflowID, Status: WorkflowStatusRunning}, nil
+		},
+	}, &fixedClock{now: now}, logger.NewNop())
+
+	result, err := uc.Cancel(context.Background(), CancelCommand{UserID: 7, WorkflowID: " wf-1 "})
+	if err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+	if result.Status != WorkflowStatusRunning {
+		t.Fatalf("unexpected status: %q", result.Status)
+	}
+}
+
+func TestCancelUseCase_Cancel_InvalidCommand(t *testing.T) {
+	t.Parallel()
+
+	uc := NewCancelUseCase(&stubWorkflowCancelRepository{
+		requestCancel: func(ctx context.Context, userID uint, workflowID string, requestedAt time.Time) (CancelResult, error) {
+			t.Fatal("repository should not be called")
+			return CancelResult{}, nil
+		},
+	}, nil, logger.NewNop())
+
+	_, err := uc.Cancel(context.Background(), CancelCommand{UserID: 7, WorkflowID: " "})
+	if !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("expected ErrInvalidCommand, got %v", err)
+	}
+}
+
+func TestCancelUseCase_Cancel_PropagatesRepositoryError(t *testing.T) {
+	t.Parallel()
+
+	uc := NewCancelUseCase(&stubWorkflowCancelRepository{
+		requestCancel: func(ctx context.Context, userID uint, workflowID string, requestedAt time.Time) (CancelResult, error) {
+			return CancelResult{}, ErrWorkflowNotCancellable
+		},
+	}, nil, logger.NewNop())
+
+	_, err := uc.Cancel(context.Background(), CancelCommand{UserID: 7, WorkflowID: "wf-1"})
+	if !errors.Is(err, ErrWorkflowNotCancellable) {
+		t.Fatalf("expected ErrWorkflowNotCancellable, got %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWorkflowFailureListLimit  = 50
+	defaultWorkflowFailureListOffset = 0
+	maxWorkflowFailureListLimit      = 200
+)
+
+const (
+	// WorkflowFailureSortCreatedAtAsc orders failure rows from oldest to newest.
+	WorkflowFailureSortCreatedAtAsc = "created_at_asc"
+	// WorkflowFailureSortCreatedAtDesc orders failure rows from newest to oldest.
+	WorkflowFailureSortCreatedAtDesc = "created_at_desc"
+	// WorkflowFailureSortReasonCodeAsc groups failure rows by reason_code.
+	WorkflowFailureSortReasonCodeAsc = "reason_code_asc"
+)
+
+var (
+	// ErrInvalidDetailQuery indicates the workflow history detail query is invalid.
+	ErrInvalidDetailQuery = errors.New("manual mail workflow detail query is invalid")
+)
+
+// DetailQuery is the input contract for the workflow history detail API.
+// Stage and ReasonCode filter the failure rows only; stage counts are always returned in full.
+type DetailQuery struct {
+	UserID     uint
+	WorkflowID string
+	Stage      *string
+	ReasonCode *string
+	Sort       string
+	Limit      int
+	Offset     int
+	HasLimit   bool
+	HasOffset  bool
+}
+
+// Normalize trims free-form values and applies default pagination and sort.
+func (q DetailQuery) Normalize() DetailQuery {
+	q.WorkflowID = strings.TrimSpace(q.WorkflowID)
+	q.Stage = trimOptionalString(q.Stage)
+	q.ReasonCode = trimOptionalString(q.ReasonCode)
+	q.Sort = strings.TrimSpace(q.Sort)
+	if q.Sort == "" {
+		q.Sort = WorkflowFailureSortCreatedAtAsc
+	}
+	if !q.HasLimit {
+		q.Limit = defaultWorkflowFailureListLimit
+	}
+	if !q.HasOffset {
+		q.Offset = defaultWorkflowFailureListOffset
+	}
+
+	return q
+}
+
+// Validate checks whether the detail query satisfies the API contract.
+func (q DetailQuery) Validate() error {
+	if q.UserID == 0 {
+		return fmt.Errorf("%w: user_id is required", ErrInvalidDetailQuery)
+	}
+	if q.WorkflowID == "" {
+		return fmt.Errorf("%w: workflow_id is required", ErrInvalidDetailQuery)
+	}
+	if q.Stage != nil && !isWorkflowStage(*q.Stage) {
+		return fmt.Errorf("%w: unsupported stage %q", ErrInvalidDetailQuery, *q.Stage)
+	}
+	if q.ReasonCode != nil && *q.ReasonCode == "" {
+		return fmt.Errorf("%w: reason_code must not be empty", ErrInvalidDetailQuery)
+	}
+	if !isWorkflowFailureSort(q.Sort) {
+		return fmt.Errorf("%w: unsupported sort %q", ErrInvalidDetailQuery, q.Sort)
+	}
+	if q.Limit < 1 || q.Limit > maxWorkflowFailureListLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidDetailQuery, maxWorkflowFailureListLimit)
+	}
+	if q.Offset < 0 {
+		return fmt.Errorf("%w: offset must be greater than or equal to zero", ErrInvalidDetailQuery)
+	}
+
+	return nil
+}
+
+// StageCountView is the per-stage success and failure counts of one workflow.
+type StageCountView struct {
+	SuccessCount          int
+	BusinessFailureCount  int
+	TechnicalFailureCount int
+}
+
+// AdditionalStageCountView is the counts of a stage added to the pipeline, which has no header columns.
+type AdditionalStageCountView struct {
+	Stage string
+	StageCountView
+}
+
+// StageTimelineView is one stage run of a workflow, in the order the runner entered the stages.
+// FinishedAt and DurationMillis are nil while the stage is still running.
+type StageTimelineView struct {
+	Stage          string
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+	DurationMillis *int64
+}
+
+// WorkflowStageFailureItem is one failure row returned by the detail API.
+type WorkflowStageFailureItem struct {
+	Stage             string
+	ExternalMessageID *string
+	ReasonCode        string
+	Message           string
+	CreatedAt         time.Time
+}
+
+// WorkflowChildSummary is one per-connection child of a sync-all parent workflow.
+type WorkflowChildSummary struct {
+	WorkflowID        string
+	Provider          string
+	AccountIdentifier string
+	Status            string
+	CurrentStage      *string
+	FinishedAt        *time.Time
+	ErrorMessage      *string
+}
+
+// WorkflowHistoryDetail is the workflow header with its filtered, paginated failure rows.
+// Children is set only for a sync-all parent, whose failure rows stay on each child.
+type WorkflowHistoryDetail struct {
+	WorkflowID         string
+	RetryOfWorkflowID  *string
+	ParentWorkflowID   *string
+	FanOut             bool
+	DryRun             bool
+	Provider           string
+	AccountIdentifier  string
+	LabelName          string
+	Since              time.Time
+	Until              time.Time
+	Filter             FetchFilter
+	Status             string
+	CurrentStage       *string
+	QueuedAt           time.Time
+	FinishedAt         *time.Time
+	ErrorMessage       *string
+	Fetch              StageCountView
+	Analysis           StageCountView
+	VendorResolution   StageCountView
+	BillingEligibility StageCountView
+	Billing            StageCountView
+	AdditionalStages   []AdditionalStageCountView
+	Timeline           []StageTimelineView
+	Failures           []WorkflowStageFailureItem
+	FailureTotalCount  int64
+	Children           []WorkflowChildSummary
+}
+
+// WorkflowHistoryDetailRepository loads one workflow history with its failure rows.
+type WorkflowHistoryDetailRepository interface {
+	Detail(ctx context.Context, query DetailQuery) (WorkflowHistoryDetail, error)
+}
+
+// DetailUseCase loads one manual mail workflow history for the authenticated user.
+type DetailUseCase interface {
+	Get(ctx context.Context, query DetailQuery) (WorkflowHistoryDetail, error)
+}
+
+type detailUseCase struct {
+	repository WorkflowHistoryDetailRepository
+	log        logger.Interface
+}
+
+// NewDetailUseCase creates a workflow history detail use case.
+func NewDetailUseCase(repository WorkflowHistoryDetailRepository, log logger.Interface) DetailUseCase {
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &detailUseCase{
+		repository: repository,
+		log:        log.With(logger.Component("manual_mail_workflow_detail_usecase")),
+	}
+}
+
+// Get normalizes and validates the query before loading the workflow history detail.
+func (uc *detailUseCase) Get(ctx context.Context, query DetailQuery) (WorkflowHistoryDetail, error) {
+	if ctx == nil {
+		return WorkflowHistoryDetail{}, logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return WorkflowHistoryDetail{}, fmt.Errorf("workflow_history_detail_repository is not configured")
+	}
+
+	query = query.Normalize()
+	if err := query.Validate(); err != nil {
+		return WorkflowHistoryDetail{}, err
+	}
+
+	detail, err := uc.repository.Detail(ctx, query)
+	if err != nil {
+		return WorkflowHistoryDetail{}, err
+	}
+	if detail.Failures == nil {
+		detail.Failures = []WorkflowStageFailureItem{}
+	}
+
+	return detail, nil
+}
+
+func isWorkflowStage(stage string) bool {
+	switch stage {
+	case workflowStageFetch,
+		workflowStageAnalysis,
+		workflowStageVendorResolution,
+		workflowStageBillingEligibility,
+		workflowStageBilling:
+		return true
+	default:
+		return false
+	}
+}
+
+func isWorkflowFailureSort(sort string) bool {
+	switch sort {
+	case WorkflowFailureSortCreatedAtAsc,
+		WorkflowFailureSortCreatedAtDesc,
+		WorkflowFailureSortReasonCodeAsc:
+		return true
+	default:
+		return false
+	}
+}
+
+func trimOptionalString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
@@ -0,0 +1,111 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubWorkflowHistoryDetailRepository struct {
+	detail func(ctx context.Context, query DetailQuery) (WorkflowHistoryDetail, error)
+}
+
+func (s *stubWorkflowHistoryDetailRepository) Detail(ctx context.Context, query DetailQuery) (WorkflowHistoryDetail, error) {
+	return s.detail(ctx, query)
+}
+
+func TestDetailUseCase_Get_NormalizesAndDefaultsQuery(t *testing.T) {
+	t.Parallel()
+
+	uc := NewDetailUseCase(&stubWorkflowHistoryDetailRepository{
+		detail: func(ctx context.Context, query DetailQuery) (WorkflowHistoryDetail, error) {
+			if query.UserID != 7 || query.WorkflowID != "wf-1" {
+				t.Fatalf("unexpected target: user=%d workflow=%q", query.UserID, query.WorkflowID)
+			}
+			if query.Stage == nil || *query.Stage != workflowStageAnalysis {
+				t.Fatalf("unexpected normalized stage: %+v", query.Stage)
+			}
+			if query.ReasonCode == nil || *query.ReasonCode != "analysis_failed" {
+				t.Fatalf("unexpected normalized reason_code: %+v", query.ReasonCode)
+			}
+			if query.Sort != WorkflowFailureSortCreatedAtAsc {
+				t.Fatalf("unexpected default sort: %q", query.Sort)
+			}
+			if query.Limit != defaultWorkflowFailureListLimit || query.Offset != defaultWorkflowFailureListOffset {
+				t.Fatalf("unexpected default pagination: limit=%d offset=%d", query.Limit, query.Offset)
+			}
+
+			return WorkflowHistoryDetail{
+				WorkflowID: query.WorkflowID,
+				Analysis:   StageCountView{SuccessCount: 2, TechnicalFailureCount: 1},
+			}, nil
+		},
+	}, logger.NewNop())
+
+	detail, err := uc.Get(context.Background(), DetailQuery{
+		UserID:     7,
+		WorkflowID: " wf-1 ",
+		Stage:      stringPtr(" analysis "),
+		ReasonCode: stringPtr(" analysis_failed "),
+	})
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if detail.Analysis.TechnicalFailureCount != 1 {
+		t.Fatalf("unexpected analysis counts: %+v", detail.Analysis)
+	}
+	if detail.Failures == nil {
+		t.Fatal("expected failures to be an empty slice")
+	}
+}
+
+func TestDetailUseCase_Get_RejectsInvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query DetailQuery
+	}{
+		{name: "missing workflow id", query: DetailQuery{UserID: 7}},
+		{name: "unknown stage", query: DetailQuery{UserID: 7, WorkflowID: "wf-1", Stage: stringPtr("mailfetch")}},
+		{name: "empty reason code", query: DetailQuery{UserID: 7, WorkflowID: "wf-1", ReasonCode: stringPtr(" ")}},
+		{name: "unknown sort", query: DetailQuery{UserID: 7, WorkflowID: "wf-1", Sort: "message_asc"}},
+		{name: "limit too large", query: DetailQuery{UserID: 7, WorkflowID: "wf-1", Limit: maxWorkflowFailureListLimit + 1, HasLimit: true}},
+		{name: "negative offset", query: DetailQuery{UserID: 7, WorkflowID: "wf-1", Offset: -1, HasOffset: true}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewDetailUseCase(&stubWorkflowHistoryDetailRepository{
+				detail: func(ctx context.Context, query DetailQuery) (WorkflowHistoryDetail, error) {
+					t.Fatal("repository must not be called for an invalid query")
+					return WorkflowHistoryDetail{}, nil
+				},
+			}, logger.NewNop())
+
+			_, err := uc.Get(context.Background(), tt.query)
+			if !errors.Is(err, ErrInvalidDetailQuery) {
+				t.Fatalf("expected ErrInvalidDetailQuery, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDetailUseCase_Get_PropagatesNotFound(t *testing.T) {
+	t.Parallel()
+
+	uc := NewDetailUseCase(&stubWorkflowHistoryDetailRepository{
+		detail: func(ctx context.Context, query DetailQuery) (WorkflowHistoryDetail, error) {
+			return WorkflowHistoryDetail{}, ErrWorkflowHistoryNotFound
+		},
+	}, logger.NewNop())
+
+	_, err := uc.Get(context.Background(), DetailQuery{UserID: 7, WorkflowID: "wf-1"})
+	if !errors.Is(err, ErrWorkflowHistoryNotFound) {
+		t.Fatalf("expected ErrWorkflowHistoryNotFound, got %v", err)
+	}
+}
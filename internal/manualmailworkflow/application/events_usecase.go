@@ -0,0 +1,272 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// WorkflowEventSnapshot carries the persisted state at the time the stream was opened.
+	WorkflowEventSnapshot = "snapshot"
+	// WorkflowEventQueued is emitted when a workflow interrupted by a shutdown goes back to the queue (Requeue).
+	WorkflowEventQueued = "queued"
+	// WorkflowEventStageStarted is emitted when the runner enters a stage (MarkRunning).
+	WorkflowEventStageStarted = "stage_started"
+	// WorkflowEventStageProgress is emitted when a stage summary is saved (SaveStageProgress).
+	WorkflowEventStageProgress = "stage_progress"
+	// WorkflowEventCompleted is emitted when the workflow finishes as succeeded or partial_success.
+	WorkflowEventCompleted = "completed"
+	// WorkflowEventFailed is emitted when the workflow finishes as failed.
+	WorkflowEventFailed = "failed"
+	// WorkflowEventCancelled is emitted when the workflow stops because of a cancel request.
+	WorkflowEventCancelled = "cancelled"
+)
+
+var (
+	// ErrInvalidEventsQuery indicates the workflow events query is invalid.
+	ErrInvalidEventsQuery = errors.New("manual mail workflow events query is invalid")
+)
+
+// WorkflowEventFailureReason aggregates the failure rows of one stage by reason_code.
+type WorkflowEventFailureReason struct {
+	ReasonCode string
+	Message    string
+	Count      int
+}
+
+// WorkflowEvent is one status transition of a workflow delivered to live subscribers.
+// Stage, Counts and FailureReasons are set only on stage_progress; Stages only on snapshot.
+type WorkflowEvent struct {
+	Type           string
+	HistoryID      uint64
+	Status         string
+	CurrentStage   string
+	Stage          string
+	Counts         StageCountView
+	FailureReasons []WorkflowEventFailureReason
+	Stages         map[string]StageCountView
+	ErrorMessage   string
+	OccurredAt     time.Time
+}
+
+// IsTerminal reports whether no further events follow this one.
+func (e WorkflowEvent) IsTerminal() bool {
+	switch e.Type {
+	case WorkflowEventCompleted, WorkflowEventFailed, WorkflowEventCancelled:
+		return true
+	case WorkflowEventSnapshot:
+		return isTerminalWorkflowStatus(e.Status)
+	default:
+		return false
+	}
+}
+
+// WorkflowEventPublisher delivers workflow events to subscribers in any process.
+type WorkflowEventPublisher interface {
+	Publish(ctx context.Context, event WorkflowEvent) error
+}
+
+// WorkflowEventSubscription is a live stream of events for one workflow history.
+type WorkflowEventSubscription interface {
+	Events() <-chan WorkflowEvent
+	Close() error
+}
+
+// WorkflowEventSubscriber opens live event streams per workflow history.
+type WorkflowEventSubscriber interface {
+	Subscribe(ctx context.Context, historyID uint64) (WorkflowEventSubscription, error)
+}
+
+// WorkflowProgress is the persisted state of one workflow used for the initial snapshot.
+type WorkflowProgress struct {
+	HistoryID    uint64
+	WorkflowID   string
+	Status       string
+	CurrentStage *string
+	ErrorMessage *string
+	UpdatedAt    time.Time
+	Stages       map[string]StageCountView
+}
+
+// WorkflowProgressRepository loads the persisted progress of one workflow owned by the user.
+type WorkflowProgressRepository interface {
+	FindProgress(ctx context.Context, userID uint, workflowID string) (WorkflowProgress, error)
+}
+
+// EventsQuery identifies the workflow whose events the user wants to follow.
+type EventsQuery struct {
+	UserID     uint
+	WorkflowID string
+}
+
+// EventStream is the snapshot at subscription time followed by live events.
+// Subscription is nil when the workflow had already finished.
+type EventStream struct {
+	Snapshot     WorkflowEvent
+	Subscription WorkflowEventSubscription
+}
+
+// EventsUseCase opens live progress streams for manual mail workflows.
+type EventsUseCase interface {
+	Subscribe(ctx context.Context, query EventsQuery) (EventStream, error)
+}
+
+type eventsUseCase struct {
+	repository WorkflowProgressRepository
+	subscriber WorkflowEventSubscriber
+	log        logger.Interface
+}
+
+// NewEventsUseCase creates a workflow events use case.
+func NewEventsUseCase(
+	repository WorkflowProgressRepository,
+	subscriber WorkflowEventSubscriber,
+	log logger.Interface,
+) EventsUseCase {
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &eventsUseCase{
+		repository: repository,
+		subscriber: subscriber,
+		log:        log.With(logger.Component("manual_mail_workflow_events_usecase")),
+	}
+}
+
+// Subscribe checks ownership, subscribes to the workflow and returns the snapshot taken after subscribing,
+// so that a transition between the snapshot and the subscription is never lost.
+func (uc *eventsUseCase) Subscribe(ctx context.Context, query EventsQuery) (EventStream, error) {
+	if ctx == nil {
+		return EventStream{}, logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return EventStream{}, errors.New("workflow_progress_repository is not configured")
+	}
+	if uc.subscriber == nil {
+		return EventStream{}, errors.New("workflow_event_subscriber is not configured")
+	}
+
+	query.WorkflowID = strings.TrimSpace(query.WorkflowID)
+	if query.UserID == 0 {
+		return EventStream{}, fmt.Errorf("%w: user_id is required", ErrInvalidEventsQuery)
+	}
+	if query.WorkflowID == "" {
+		return EventStream{}, fmt.Errorf("%w: workflow_id is required", ErrInvalidEventsQuery)
+	}
+
+	progress, err := uc.repository.FindProgress(ctx, query.UserID, query.WorkflowID)
+	if err != nil {
+		return EventStream{}, err
+	}
+	if isTerminalWorkflowStatus(progress.Status) {
+		return EventStream{Snapshot: snapshotEvent(progress)}, nil
+	}
+
+	subscription, err := uc.subscriber.Subscribe(ctx, progress.HistoryID)
+	if err != nil {
+		return EventStream{}, fmt.Errorf("failed to subscribe workflow events: %w", err)
+	}
+
+	progress, err = uc.repository.FindProgress(ctx, query.UserID, query.WorkflowID)
+	if err != nil {
+		uc.closeSubscription(ctx, subscription)
+		return EventStream{}, err
+	}
+	snapshot := snapshotEvent(progress)
+	if snapshot.IsTerminal() {
+		uc.closeSubscription(ctx, subscription)
+		return EventStream{Snapshot: snapshot}, nil
+	}
+
+	return EventStream{Snapshot: snapshot, Subscription: subscription}, nil
+}
+
+func (uc *eventsUseCase) closeSubscription(ctx context.Context, subscription WorkflowEventSubscription) {
+	if err := subscription.Close(); err != nil {
+		reqLog := uc.log
+		if withContext, withCtxErr := uc.log.WithContext(ctx); withCtxErr == nil {
+			reqLog = withContext
+		}
+		reqLog.Warn("manual_mail_workflow_event_subscription_close_failed", logger.Err(err))
+	}
+}
+
+func snapshotEvent(progress WorkflowProgress) WorkflowEvent {
+	currentStage := ""
+	if progress.CurrentStage != nil {
+		currentStage = *progress.CurrentStage
+	}
+	errorMessage := ""
+	if progress.ErrorMessage != nil {
+		errorMessage = *progress.ErrorMessage
+	}
+
+	// 組み込み stage は未実行でも 0 件として含め、pipeline に追加した stage は記録済みのものだけを含める。
+	stages := make(map[string]StageCountView, len(workflowStages)+len(progress.Stages))
+	for _, stage := range workflowStages {
+		stages[stage] = progress.Stages[stage]
+	}
+	for stage, counts := range progress.Stages {
+		stages[stage] = counts
+	}
+
+	return WorkflowEvent{
+		Type:         WorkflowEventSnapshot,
+		HistoryID:    progress.HistoryID,
+		Status:       progress.Status,
+		CurrentStage: currentStage,
+		Stages:       stages,
+		ErrorMessage: errorMessage,
+		OccurredAt:   progress.UpdatedAt,
+	}
+}
+
+// StageProgressEvent builds the stage_progress event for a saved stage summary.
+func StageProgressEvent(progress StageProgress, occurredAt time.Time) WorkflowEvent {
+	reasons := make([]WorkflowEventFailureReason, 0)
+	indexByReason := make(map[string]int)
+	for _, record := range progress.FailureRecords {
+		if idx, ok := indexByReason[record.ReasonCode]; ok {
+			reasons[idx].Count++
+			continue
+		}
+		indexByReason[record.ReasonCode] = len(reasons)
+		reasons = append(reasons, WorkflowEventFailureReason{
+			ReasonCode: record.ReasonCode,
+			Message:    record.Message,
+			Count:      1,
+		})
+	}
+
+	return WorkflowEvent{
+		Type:         WorkflowEventStageProgress,
+		HistoryID:    progress.HistoryID,
+		Status:       WorkflowStatusRunning,
+		CurrentStage: progress.Stage,
+		Stage:        progress.Stage,
+		Counts: StageCountView{
+			SuccessCount:          progress.SuccessCount,
+			BusinessFailureCount:  progress.BusinessFailureCount,
+			TechnicalFailureCount: progress.TechnicalFailureCount,
+		},
+		FailureReasons: reasons,
+		OccurredAt:     occurredAt,
+	}
+}
+
+func isTerminalWorkflowStatus(status string) bool {
+	switch status {
+	case WorkflowStatusSucceeded,
+		WorkflowStatusPartialSuccess,
+		WorkflowStatusFailed,
+		WorkflowStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,221 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubWorkflowProgressRepository struct {
+	findProgress func(ctx context.Context, userID uint, workflowID string) (WorkflowProgress, error)
+}
+
+func (s *stubWorkflowProgressRepository) FindProgress(ctx context.Context, userID uint, workflowID string) (WorkflowProgress, error) {
+	return s.findProgress(ctx, userID, workflowID)
+}
+
+type stubWorkflowEventSubscriber struct {
+	subscribe func(ctx context.Context, historyID uint64) (WorkflowEventSubscription, error)
+}
+
+func (s *stubWorkflowEventSubscriber) Subscribe(ctx context.Context, historyID uint64) (WorkflowEventSubscription, error) {
+	return s.subscribe(ctx, historyID)
+}
+
+type stubWorkflowEventSubscription struct {
+	events chan WorkflowEvent
+	closed bool
+}
+
+func (s *stubWorkflowEventSubscription) Events() <-chan WorkflowEvent {
+	return s.events
+}
+
+func (s *stubWorkflowEventSubscription) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestEventsUseCase_Subscribe_SnapshotAfterSubscribing(t *testing.T) {
+	t.Parallel()
+
+	subscription := &stubWorkflowEventSubscription{events: make(chan WorkflowEvent)}
+	reads := 0
+	subscribed := false
+	uc := NewEventsUseCase(
+		&stubWorkflowProgressRepository{
+			findProgress: func(ctx context.Context, userID uint, workflowID string) (WorkflowProgress, error) {
+				if userID != 7 || workflowID != "wf-1" {
+					t.Fatalf("unexpected lookup: user=%d workflow=%q", userID, workflowID)
+				}
+				reads++
+				progress := WorkflowProgress{HistoryID: 42, WorkflowID: workflowID, Status: WorkflowStatusQueued}
+				if subscribed {
+					// 購読後に読み直した状態を snapshot として返す。
+					progress.Status = WorkflowStatusRunning
+					progress.CurrentStage = stringPtr(workflowStageAnalysis)
+					progress.Stages = map[string]StageCountView{workflowStageFetch: {SuccessCount: 3}}
+				}
+				return progress, nil
+			},
+		},
+		&stubWorkflowEventSubscriber{
+			subscribe: func(ctx context.Context, historyID uint64) (WorkflowEventSubscription, error) {
+				if historyID != 42 {
+					t.Fatalf("unexpected history id: %d", historyID)
+				}
+				subscribed = true
+				return subscription, nil
+			},
+		},
+		logger.NewNop(),
+	)
+
+	stream, err := uc.Subscribe(context.Background(), EventsQuery{UserID: 7, WorkflowID: " wf-1 "})
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+	if reads != 2 {
+		t.Fatalf("expected progress to be read before and after subscribing, got %d reads", reads)
+	}
+	if stream.Subscription != subscription || subscription.closed {
+		t.Fatalf("expected the open subscription to be returned: %+v", stream)
+	}
+	snapshot := stream.Snapshot
+	if snapshot.Type != WorkflowEventSnapshot || snapshot.Status != WorkflowStatusRunning || snapshot.CurrentStage != workflowStageAnalysis {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+	if len(snapshot.Stages) != len(workflowStages) || snapshot.Stages[workflowStageFetch].SuccessCount != 3 {
+		t.Fatalf("expected every stage in snapshot: %+v", snapshot.Stages)
+	}
+}
+
+func TestEventsUseCase_Subscribe_FinishedWorkflowReturnsSnapshotOnly(t *testing.T) {
+	t.Parallel()
+
+	uc := NewEventsUseCase(
+		&stubWorkflowProgressRepository{
+			findProgress: func(ctx context.Context, userID uint, workflowID string) (WorkflowProgress, error) {
+				return WorkflowProgress{HistoryID: 42, Status: WorkflowStatusFailed, ErrorMessage: stringPtr("メール解析に失敗しました。")}, nil
+			},
+		},
+		&stubWorkflowEventSubscriber{
+			subscribe: func(ctx context.Context, historyID uint64) (WorkflowEventSubscription, error) {
+				t.Fatal("finished workflows must not be subscribed")
+				return nil, nil
+			},
+		},
+		nil,
+	)
+
+	stream, err := uc.Subscribe(context.Background(), EventsQuery{UserID: 7, WorkflowID: "wf-1"})
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+	if stream.Subscription != nil || !stream.Snapshot.IsTerminal() || stream.Snapshot.ErrorMessage != "メール解析に失敗しました。" {
+		t.Fatalf("unexpected stream: %+v", stream)
+	}
+}
+
+func TestEventsUseCase_Subscribe_ClosesWhenFinishedWhileSubscribing(t *testing.T) {
+	t.Parallel()
+
+	subscription := &stubWorkflowEventSubscription{events: make(chan WorkflowEvent)}
+	status := WorkflowStatusRunning
+	uc := NewEventsUseCase(
+		&stubWorkflowProgressRepository{
+			findProgress: func(ctx context.Context, userID uint, workflowID string) (WorkflowProgress, error) {
+				return WorkflowProgress{HistoryID: 42, Status: status}, nil
+			},
+		},
+		&stubWorkflowEventSubscriber{
+			subscribe: func(ctx context.Context, historyID uint64) (WorkflowEventSubscription, error) {
+				status = WorkflowStatusSucceeded
+				return subscription, nil
+			},
+		},
+		nil,
+	)
+
+	stream, err := uc.Subscribe(context.Background(), EventsQuery{UserID: 7, WorkflowID: "wf-1"})
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+	if stream.Subscription != nil || !subscription.closed || stream.Snapshot.Status != WorkflowStatusSucceeded {
+		t.Fatalf("expected a closed subscription and terminal snapshot: %+v closed=%v", stream, subscription.closed)
+	}
+}
+
+func TestEventsUseCase_Subscribe_Errors(t *testing.T) {
+	t.Parallel()
+
+	subscribeErr := errors.New("redis down")
+	tests := []struct {
+		name    string
+		query   EventsQuery
+		findErr error
+		wantErr error
+	}{
+		{name: "missing workflow id", query: EventsQuery{UserID: 7, WorkflowID: " "}, wantErr: ErrInvalidEventsQuery},
+		{name: "missing user", query: EventsQuery{WorkflowID: "wf-1"}, wantErr: ErrInvalidEventsQuery},
+		{name: "not found", query: EventsQuery{UserID: 7, WorkflowID: "wf-1"}, findErr: ErrWorkflowHistoryNotFound, wantErr: ErrWorkflowHistoryNotFound},
+		{name: "subscribe failure", query: EventsQuery{UserID: 7, WorkflowID: "wf-1"}, wantErr: subscribeErr},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewEventsUseCase(
+				&stubWorkflowProgressRepository{
+					findProgress: func(ctx context.Context, userID uint, workflowID string) (WorkflowProgress, error) {
+						return WorkflowProgress{HistoryID: 42, Status: WorkflowStatusRunning}, tt.findErr
+					},
+				},
+				&stubWorkflowEventSubscriber{
+					subscribe: func(ctx context.Context, historyID uint64) (WorkflowEventSubscription, error) {
+						return nil, subscribeErr
+					},
+				},
+				nil,
+			)
+
+			_, err := uc.Subscribe(context.Background(), tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestStageProgressEvent_AggregatesFailureReasons(t *testing.T) {
+	t.Parallel()
+
+	occurredAt := time.Date(2026, 3, 25, 15, 0, 0, 0, time.UTC)
+	event := StageProgressEvent(StageProgress{
+		HistoryID:             42,
+		Stage:                 workflowStageBillingEligibility,
+		SuccessCount:          1,
+		BusinessFailureCount:  2,
+		TechnicalFailureCount: 1,
+		FailureRecords: []StageFailureRecord{
+			{ReasonCode: "amount_missing", Message: "金額を特定できませんでした。"},
+			{ReasonCode: "internal_error", Message: "請求成立判定に失敗しました。"},
+			{ReasonCode: "amount_missing", Message: "金額を特定できませんでした。"},
+		},
+	}, occurredAt)
+
+	if event.Type != WorkflowEventStageProgress || event.Stage != workflowStageBillingEligibility || event.IsTerminal() {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	want := []WorkflowEventFailureReason{
+		{ReasonCode: "amount_missing", Message: "金額を特定できませんでした。", Count: 2},
+		{ReasonCode: "internal_error", Message: "請求成立判定に失敗しました。", Count: 1},
+	}
+	if len(event.FailureReasons) != len(want) || event.FailureReasons[0] != want[0] || event.FailureReasons[1] != want[1] {
+		t.Fatalf("unexpected failure reasons: %+v", event.FailureReasons)
+	}
+}
@@ -0,0 +1,223 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrNoUsableConnection indicates the user has no mail-account connection a sync-all workflow can run on.
+	ErrNoUsableConnection = errors.New("no usable mail account connection")
+	// ErrWorkflowFanOutParent indicates the operation must target one of the per-connection child workflows instead.
+	ErrWorkflowFanOutParent = errors.New("manual mail workflow is a fan-out parent")
+)
+
+// UsableConnection is a connection a workflow can fetch from.
+type UsableConnection struct {
+	ConnectionID uint
+	Provider     string
+}
+
+// SupportsFetchFilter reports whether the provider can apply a FetchFilter. Only Gmail has a search query and labels.
+func (c UsableConnection) SupportsFetchFilter() bool {
+	return c.Provider == "gmail"
+}
+
+// UsableConnectionLister lists the connections a sync-all workflow fans out to and finds the one a request targets.
+// Connections that cannot be fetched from, such as ones missing OAuth tokens, are left out.
+type UsableConnectionLister interface {
+	ListUsableConnections(ctx context.Context, userID uint) ([]UsableConnection, error)
+	// FindUsableConnection returns found=false when the connection does not exist or cannot be fetched from.
+	FindUsableConnection(ctx context.Context, userID, connectionID uint) (connection UsableConnection, found bool, err error)
+}
+
+// DeriveFanOutStatus は子 workflow の状態から全接続同期の親 workflow の状態を決める。
+// 子が 1 件でも未完了なら queued / running のまま、すべて終わったら成功した子の有無で完了状態を決める。
+func DeriveFanOutStatus(childStatuses []string) string {
+	counts := make(map[string]int, len(childStatuses))
+	for _, status := range childStatuses {
+		counts[status]++
+	}
+	total := len(childStatuses)
+
+	switch {
+	case total == 0 || counts[WorkflowStatusQueued] == total:
+		return WorkflowStatusQueued
+	case counts[WorkflowStatusQueued]+counts[WorkflowStatusRunning] > 0:
+		return WorkflowStatusRunning
+	case counts[WorkflowStatusSucceeded] == total:
+		return WorkflowStatusSucceeded
+	case counts[WorkflowStatusCancelled] == total:
+		return WorkflowStatusCancelled
+	case counts[WorkflowStatusSucceeded]+counts[WorkflowStatusPartialSuccess] > 0:
+		return WorkflowStatusPartialSuccess
+	default:
+		return WorkflowStatusFailed
+	}
+}
+
+// FanOutErrorMessage は失敗した子 workflow があるとき、親 workflow に表示するメッセージを返す。
+func FanOutErrorMessage(childStatuses []string) string {
+	failed := 0
+	for _, status := range childStatuses {
+		if status == WorkflowStatusFailed {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d 件のメール連携のうち %d 件のメール取得ワークフローが失敗しました。各メール連携のワークフローを確認してください。", len(childStatuses), failed)
+}
+
+// startFanOut accepts a sync-all request: one parent history plus one child workflow per usable connection.
+// Every connection's mailbox is checked for the requested labels and, unless it is a dry run, every connection
+// is locked and checked for conflicts before anything is queued, so the request is either accepted for all
+// connections or rejected as a whole.
+// A request with a FetchFilter skips the connections whose provider cannot apply it.
+func (uc *startUseCase) startFanOut(ctx context.Context, cmd Command, reqLog logger.Interface) (StartResult, error) {
+	if uc.connections == nil {
+		return StartResult{}, errors.New("usable_connection_lister is not configured")
+	}
+
+	connections, err := uc.connections.ListUsableConnections(ctx, cmd.UserID)
+	if err != nil {
+		return StartResult{}, err
+	}
+	if len(connections) == 0 {
+		return StartResult{}, ErrNoUsableConnection
+	}
+	connectionIDs := make([]uint, 0, len(connections))
+	for _, connection := range connections {
+		if !cmd.Condition.Filter.IsZero() && !connection.SupportsFetchFilter() {
+			reqLog.Info("manual_mail_workflow_fan_out_connection_skipped",
+				logger.UserID(cmd.UserID),
+				logger.Uint("connection_id", connection.ConnectionID),
+				logger.String("provider", connection.Provider),
+				logger.String("reason", "fetch_filter_unsupported"),
+			)
+			continue
+		}
+		connectionIDs = append(connectionIDs, connection.ConnectionID)
+	}
+	if len(connectionIDs) == 0 {
+		return StartResult{}, fmt.Errorf("%w: no gmail connection", ErrFetchFilterUnsupported)
+	}
+	for _, connectionID := range connectionIDs {
+		if err := uc.checkLabels(ctx, cmd, connectionID, reqLog); err != nil {
+			return StartResult{}, err
+		}
+	}
+
+	parentWorkflowID, err := newWorkflowID()
+	if err != nil {
+		return StartResult{}, fmt.Errorf("failed to generate workflow id: %w", err)
+	}
+
+	if !cmd.DryRun {
+		for _, connectionID := range connectionIDs {
+			release, err := uc.acquireConnection(ctx, cmd, connectionID, parentWorkflowID, reqLog)
+			if release != nil {
+				defer release()
+			}
+			if err != nil {
+				return StartResult{}, err
+			}
+		}
+	}
+
+	queuedAt := uc.clock.Now().UTC()
+	parentRef, err := uc.repository.CreateQueued(ctx, QueuedWorkflowHistory{
+		WorkflowID: parentWorkflowID,
+		UserID:     cmd.UserID,
+		LabelName:  cmd.Condition.LabelName,
+		SinceAt:    cmd.Condition.Since,
+		UntilAt:    cmd.Condition.Until,
+		Filter:     cmd.Condition.Filter,
+		QueuedAt:   queuedAt,
+		DryRun:     cmd.DryRun,
+		FanOut:     true,
+	})
+	if err != nil {
+		return StartResult{}, err
+	}
+
+	accepted := 0
+	var lastErr error
+	for _, connectionID := range connectionIDs {
+		child, err := uc.enqueueFanOutChild(ctx, cmd, connectionID, parentRef.WorkflowID, queuedAt, reqLog)
+		if err != nil {
+			// 作成済みの子は実行を続け、親の状態は失敗した子も含めて集計される。
+			reqLog.Error("manual_mail_workflow_fan_out_child_failed",
+				logger.UserID(cmd.UserID),
+				logger.Uint("connection_id", connectionID),
+				logger.String("parent_workflow_id", parentRef.WorkflowID),
+				logger.Err(err),
+			)
+			lastErr = err
+			continue
+		}
+		accepted++
+		reqLog.Info("manual_mail_workflow_accepted",
+			logger.UserID(cmd.UserID),
+			logger.Uint("connection_id", connectionID),
+			logger.String("workflow_id", child.WorkflowID),
+			logger.String("parent_workflow_id", parentRef.WorkflowID),
+			logger.Bool("dry_run", cmd.DryRun),
+		)
+	}
+
+	if accepted == 0 {
+		if failErr := uc.repository.Fail(ctx, parentRef.HistoryID, "", uc.clock.Now().UTC(), localizedWorkflowErrorMessage("", lastErr)); failErr != nil {
+			reqLog.Error("manual_mail_workflow_dispatch_failed_to_mark_history",
+				logger.UserID(cmd.UserID),
+				logger.String("workflow_id", parentRef.WorkflowID),
+				logger.Err(failErr),
+			)
+		}
+		return StartResult{}, lastErr
+	}
+
+	reqLog.Info("manual_mail_workflow_fan_out_accepted",
+		logger.UserID(cmd.UserID),
+		logger.String("workflow_id", parentRef.WorkflowID),
+		logger.Int("connection_count", len(connectionIDs)),
+		logger.Int("accepted_count", accepted),
+		logger.Bool("dry_run", cmd.DryRun),
+	)
+
+	return StartResult{
+		WorkflowID: parentRef.WorkflowID,
+		Status:     WorkflowStatusQueued,
+	}, nil
+}
+
+func (uc *startUseCase) enqueueFanOutChild(
+	ctx context.Context,
+	cmd Command,
+	connectionID uint,
+	parentWorkflowID string,
+	queuedAt time.Time,
+	reqLog logger.Interface,
+) (StartResult, error) {
+	workflowID, err := newWorkflowID()
+	if err != nil {
+		return StartResult{}, fmt.Errorf("failed to generate workflow id: %w", err)
+	}
+
+	return enqueueWorkflow(ctx, uc.repository, uc.dispatcher, uc.clock, reqLog, QueuedWorkflowHistory{
+		WorkflowID:       workflowID,
+		ParentWorkflowID: parentWorkflowID,
+		UserID:           cmd.UserID,
+		ConnectionID:     connectionID,
+		LabelName:        cmd.Condition.LabelName,
+		SinceAt:          cmd.Condition.Since,
+		UntilAt:          cmd.Condition.Until,
+		Filter:           cmd.Condition.Filter,
+		QueuedAt:         queuedAt,
+		DryRun:           cmd.DryRun,
+	})
+}
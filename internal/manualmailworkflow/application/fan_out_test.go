@@ -0,0 +1,303 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubUsableConnectionLister struct {
+	connectionIDs []uint
+	// providers defaults to gmail for connections it does not list.
+	providers map[uint]string
+	err       error
+}
+
+func (s *stubUsableConnectionLister) ListUsableConnections(ctx context.Context, userID uint) ([]UsableConnection, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	connections := make([]UsableConnection, 0, len(s.connectionIDs))
+	for _, connectionID := range s.connectionIDs {
+		connection, _, _ := s.FindUsableConnection(ctx, userID, connectionID)
+		connections = append(connections, connection)
+	}
+	return connections, nil
+}
+
+func (s *stubUsableConnectionLister) FindUsableConnection(ctx context.Context, userID, connectionID uint) (UsableConnection, bool, error) {
+	if s.err != nil {
+		return UsableConnection{}, false, s.err
+	}
+	provider, ok := s.providers[connectionID]
+	if !ok {
+		provider = "gmail"
+	}
+	return UsableConnection{ConnectionID: connectionID, Provider: provider}, true, nil
+}
+
+func TestDeriveFanOutStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{name: "all queued", statuses: []string{WorkflowStatusQueued, WorkflowStatusQueued}, want: WorkflowStatusQueued},
+		{name: "one running", statuses: []string{WorkflowStatusQueued, WorkflowStatusRunning}, want: WorkflowStatusRunning},
+		{name: "one finished and one queued", statuses: []string{WorkflowStatusSucceeded, WorkflowStatusQueued}, want: WorkflowStatusRunning},
+		{name: "all succeeded", statuses: []string{WorkflowStatusSucceeded, WorkflowStatusSucceeded}, want: WorkflowStatusSucceeded},
+		{name: "succeeded and partial", statuses: []string{WorkflowStatusSucceeded, WorkflowStatusPartialSuccess}, want: WorkflowStatusPartialSuccess},
+		{name: "succeeded and failed", statuses: []string{WorkflowStatusSucceeded, WorkflowStatusFailed}, want: WorkflowStatusPartialSuccess},
+		{name: "succeeded and cancelled", statuses: []string{WorkflowStatusSucceeded, WorkflowStatusCancelled}, want: WorkflowStatusPartialSuccess},
+		{name: "all failed", statuses: []string{WorkflowStatusFailed, WorkflowStatusFailed}, want: WorkflowStatusFailed},
+		{name: "failed and cancelled", statuses: []string{WorkflowStatusFailed, WorkflowStatusCancelled}, want: WorkflowStatusFailed},
+		{name: "all cancelled", statuses: []string{WorkflowStatusCancelled, WorkflowStatusCancelled}, want: WorkflowStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := DeriveFanOutStatus(tt.statuses); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFanOutErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	if got := FanOutErrorMessage([]string{WorkflowStatusSucceeded, WorkflowStatusPartialSuccess}); got != "" {
+		t.Fatalf("expected no message without failed children, got %q", got)
+	}
+	want := "3 件のメール連携のうち 1 件のメール取得ワークフローが失敗しました。各メール連携のワークフローを確認してください。"
+	if got := FanOutErrorMessage([]string{WorkflowStatusSucceeded, WorkflowStatusFailed, WorkflowStatusCancelled}); got != want {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
+
+func TestStartUseCase_Start_AllConnectionsQueuesParentAndChildren(t *testing.T) {
+	t.Parallel()
+
+	var queued []QueuedWorkflowHistory
+	var dispatched []DispatchJob
+	lock := &stubWorkflowConnectionLock{}
+	now := time.Date(2026, 3, 25, 10, 0, 0, 0, time.UTC)
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error {
+			dispatched = append(dispatched, job)
+			return nil
+		},
+	}, &stubWorkflowStatusRepository{
+		createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+			queued = append(queued, cmd)
+			return WorkflowHistoryRef{HistoryID: uint64(len(queued)), WorkflowID: cmd.WorkflowID}, nil
+		},
+	}, &stubWorkflowConflictRepository{}, lock, &stubUsableConnectionLister{connectionIDs: []uint{12, 13}}, nil, &fixedClock{now: now}, logger.NewNop())
+
+	result, err := uc.Start(context.Background(), Command{
+		UserID:         7,
+		AllConnections: true,
+		Condition: FetchCondition{
+			LabelName: " billing ",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     now,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if len(queued) != 3 {
+		t.Fatalf("expected a parent and 2 children, got %+v", queued)
+	}
+	parent := queued[0]
+	if !parent.FanOut || parent.ConnectionID != 0 || parent.WorkflowID != result.WorkflowID || parent.LabelName != "billing" {
+		t.Fatalf("unexpected parent history: %+v", parent)
+	}
+	for idx, child := range queued[1:] {
+		if child.FanOut || child.ParentWorkflowID != result.WorkflowID || child.ConnectionID != []uint{12, 13}[idx] {
+			t.Fatalf("unexpected child history: %+v", child)
+		}
+	}
+	if len(dispatched) != 2 || dispatched[0].HistoryID != 2 || dispatched[1].HistoryID != 3 {
+		t.Fatalf("expected only the children to be dispatched, got %+v", dispatched)
+	}
+	if len(lock.held) != 0 || len(lock.released) != 2 {
+		t.Fatalf("expected every connection lock to be released, held=%v released=%v", lock.held, lock.released)
+	}
+}
+
+func TestStartUseCase_Start_AllConnectionsSkipsConnectionsThatCannotFilter(t *testing.T) {
+	t.Parallel()
+
+	var queued []QueuedWorkflowHistory
+	lock := &stubWorkflowConnectionLock{}
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error { return nil },
+	}, &stubWorkflowStatusRepository{
+		createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+			queued = append(queued, cmd)
+			return WorkflowHistoryRef{HistoryID: uint64(len(queued)), WorkflowID: cmd.WorkflowID}, nil
+		},
+	}, &stubWorkflowConflictRepository{}, lock, &stubUsableConnectionLister{
+		connectionIDs: []uint{12, 13, 14},
+		providers:     map[uint]string{13: "imap"},
+	}, nil, &fixedClock{now: time.Date(2026, 3, 25, 10, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+	_, err := uc.Start(context.Background(), Command{
+		UserID:         7,
+		AllConnections: true,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+			Filter:    FetchFilter{Query: "from:shop@example.com"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if len(queued) != 3 || queued[1].ConnectionID != 12 || queued[2].ConnectionID != 14 {
+		t.Fatalf("expected a parent and children for the gmail connections only, got %+v", queued)
+	}
+	if len(lock.released) != 2 {
+		t.Fatalf("expected only the gmail connections to be locked, released=%v", lock.released)
+	}
+}
+
+func TestStartUseCase_Start_AllConnectionsRejectsWhenAnyConnectionConflicts(t *testing.T) {
+	t.Parallel()
+
+	lock := &stubWorkflowConnectionLock{}
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error {
+			t.Fatal("dispatch should not be called for a conflicting request")
+			return nil
+		},
+	}, &stubWorkflowStatusRepository{
+		createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+			t.Fatal("history should not be created for a conflicting request")
+			return WorkflowHistoryRef{}, nil
+		},
+	}, &stubWorkflowConflictRepository{
+		findActiveOverlapping: func(ctx context.Context, query ActiveWorkflowQuery) (string, bool, error) {
+			return "wf-active", query.ConnectionID == 13, nil
+		},
+	}, lock, &stubUsableConnectionLister{connectionIDs: []uint{12, 13}}, nil, &fixedClock{now: time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+	_, err := uc.Start(context.Background(), Command{
+		UserID:         7,
+		AllConnections: true,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	if !errors.Is(err, ErrWorkflowConflict) {
+		t.Fatalf("expected ErrWorkflowConflict, got %v", err)
+	}
+	if len(lock.held) != 0 || len(lock.released) != 2 {
+		t.Fatalf("expected every acquired lock to be released, held=%v released=%v", lock.held, lock.released)
+	}
+}
+
+func TestStartUseCase_Start_AllConnectionsRejectsWhenAnyMailboxLacksLabel(t *testing.T) {
+	t.Parallel()
+
+	lock := &stubWorkflowConnectionLock{}
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error {
+			t.Fatal("dispatch should not be called for an unknown label")
+			return nil
+		},
+	}, &stubWorkflowStatusRepository{
+		createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+			t.Fatal("history should not be created for an unknown label")
+			return WorkflowHistoryRef{}, nil
+		},
+	}, &stubWorkflowConflictRepository{}, lock, &stubUsableConnectionLister{connectionIDs: []uint{12, 13}}, &stubMailLabelReader{
+		namesByConnection: map[uint][]string{
+			12: {"INBOX", "billing"},
+			13: {"INBOX"},
+		},
+		ok: true,
+	}, &fixedClock{now: time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+	_, err := uc.Start(context.Background(), Command{
+		UserID:         7,
+		AllConnections: true,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	if !errors.Is(err, ErrLabelNotFound) {
+		t.Fatalf("expected ErrLabelNotFound, got %v", err)
+	}
+	if len(lock.held) != 0 || len(lock.released) != 0 {
+		t.Fatalf("expected no connection to be locked, held=%v released=%v", lock.held, lock.released)
+	}
+}
+
+func TestStartUseCase_Start_AllConnectionsValidation(t *testing.T) {
+	t.Parallel()
+
+	condition := FetchCondition{
+		LabelName: "billing",
+		Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+		Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+	}
+	filtered := condition
+	filtered.Filter = FetchFilter{Query: "from:shop@example.com"}
+	tests := []struct {
+		name        string
+		cmd         Command
+		connections *stubUsableConnectionLister
+		wantErr     error
+	}{
+		{
+			name:        "connection id with all connections",
+			cmd:         Command{UserID: 7, ConnectionID: 12, AllConnections: true, Condition: condition},
+			connections: &stubUsableConnectionLister{connectionIDs: []uint{12}},
+			wantErr:     ErrInvalidCommand,
+		},
+		{
+			name:        "no usable connection",
+			cmd:         Command{UserID: 7, AllConnections: true, Condition: condition},
+			connections: &stubUsableConnectionLister{},
+			wantErr:     ErrNoUsableConnection,
+		},
+		{
+			name:        "filter without gmail connection",
+			cmd:         Command{UserID: 7, AllConnections: true, Condition: filtered},
+			connections: &stubUsableConnectionLister{connectionIDs: []uint{12, 13}, providers: map[uint]string{12: "imap", 13: "outlook"}},
+			wantErr:     ErrFetchFilterUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewStartUseCase(&stubWorkflowDispatcher{
+				dispatch: func(ctx context.Context, job DispatchJob) error {
+					t.Fatal("dispatch should not be called")
+					return nil
+				},
+			}, &stubWorkflowStatusRepository{}, &stubWorkflowConflictRepository{}, &stubWorkflowConnectionLock{}, tt.connections, nil, &fixedClock{now: time.Date(2026, 3, 25, 10, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+			if _, err := uc.Start(context.Background(), tt.cmd); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
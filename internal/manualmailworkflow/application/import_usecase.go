@@ -0,0 +1,213 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxMailImportFiles は 1 回のアップロードで受け付けるファイル数の上限。
+	MaxMailImportFiles = 20
+	// MaxMailImportMessages は 1 回のアップロードで取り込むメール数の上限。
+	// 取り込んだメールはすべて analysis に渡るため、一度に OpenAI を呼ぶ件数を抑える。
+	MaxMailImportMessages = 500
+
+	mailImportLabelPrefix = "imports/"
+)
+
+var (
+	// ErrMailImportInvalid はアップロードされたファイルからメールを読み取れないときに返る。
+	ErrMailImportInvalid = errors.New("mail import file is invalid")
+	// ErrMailImportTooLarge はアップロードに含まれるファイルまたはメールが上限を超えたときに返る。
+	ErrMailImportTooLarge = errors.New("mail import is too large")
+)
+
+// MailImportFile はアップロードされた .eml ファイルまたは mbox アーカイブ。
+type MailImportFile struct {
+	Name    string
+	Content []byte
+}
+
+// ImportCommand はファイル取り込みの入力。
+type ImportCommand struct {
+	UserID uint
+	Files  []MailImportFile
+	// DryRun は通常の workflow と同じく、請求を作成せずにプレビューだけを残す。
+	DryRun bool
+}
+
+// MailImportRequest は取り込み 1 回分のメールを保存するための入力。
+type MailImportRequest struct {
+	UserID uint
+	// LabelName はこの取り込みを "file" provider のメール連携の中で識別するラベル。
+	LabelName string
+	Files     []MailImportFile
+}
+
+// StoredMailImport は保存した取り込みを workflow の取得条件に対応させたもの。
+type StoredMailImport struct {
+	ConnectionID uint
+	MessageCount int
+	// OldestReceivedAt と LatestReceivedAt は Date ヘッダーを読み取れたメールの範囲。1 件も無ければ zero。
+	OldestReceivedAt time.Time
+	LatestReceivedAt time.Time
+}
+
+// MailImportStore はファイルをメールに分割し、ユーザーの "file" provider のメール連携に保存する。
+type MailImportStore interface {
+	Store(ctx context.Context, req MailImportRequest) (StoredMailImport, error)
+	// Discard は workflow を受け付けられなかった取り込みのメールを削除する。
+	Discard(ctx context.Context, connectionID uint, labelName string) error
+}
+
+// ImportResult はファイル取り込みの受付結果。
+type ImportResult struct {
+	WorkflowID   string
+	Status       string
+	LabelName    string
+	MessageCount int
+}
+
+// ImportUseCase はアップロードされたメールを保存し、通常の workflow として受け付ける。
+type ImportUseCase interface {
+	Import(ctx context.Context, cmd ImportCommand) (ImportResult, error)
+}
+
+type importUseCase struct {
+	store   MailImportStore
+	starter StartUseCase
+	clock   timewrapper.ClockInterface
+	log     logger.Interface
+}
+
+// NewImportUseCase はファイル取り込みの usecase を生成する。
+// 取り込んだメールは starter で workflow として受け付けるため、履歴・失敗明細・retry は他の provider と同じになる。
+func NewImportUseCase(
+	store MailImportStore,
+	starter StartUseCase,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) ImportUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &importUseCase{
+		store:   store,
+		starter: starter,
+		clock:   clock,
+		log:     log.With(logger.Component("manual_mail_workflow_import_usecase")),
+	}
+}
+
+// Import はファイルのメールを取り込みごとのラベルで保存し、そのラベルを取得する workflow を受け付ける。
+func (uc *importUseCase) Import(ctx context.Context, cmd ImportCommand) (ImportResult, error) {
+	if ctx == nil {
+		return ImportResult{}, logger.ErrNilContext
+	}
+	if err := validateImportCommand(cmd); err != nil {
+		return ImportResult{}, err
+	}
+	if uc.store == nil {
+		return ImportResult{}, errors.New("mail_import_store is not configured")
+	}
+	if uc.starter == nil {
+		return ImportResult{}, errors.New("start_usecase is not configured")
+	}
+
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+
+	importID, err := newWorkflowID()
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to generate import id: %w", err)
+	}
+	labelName := mailImportLabelPrefix + importID
+
+	stored, err := uc.store.Store(ctx, MailImportRequest{
+		UserID:    cmd.UserID,
+		LabelName: labelName,
+		Files:     cmd.Files,
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	since, until := importPeriod(stored, uc.clock.Now().UTC())
+	started, err := uc.starter.Start(ctx, Command{
+		UserID:       cmd.UserID,
+		ConnectionID: stored.ConnectionID,
+		Condition: FetchCondition{
+			LabelName: labelName,
+			Since:     since,
+			Until:     until,
+		},
+		DryRun: cmd.DryRun,
+	})
+	if err != nil {
+		// 重複実行などで受け付けられなかった取り込みは、どの workflow からも読まれないので残さない。
+		if discardErr := uc.store.Discard(context.WithoutCancel(ctx), stored.ConnectionID, labelName); discardErr != nil {
+			reqLog.Error("manual_mail_workflow_import_discard_failed",
+				logger.UserID(cmd.UserID),
+				logger.Uint("connection_id", stored.ConnectionID),
+				logger.String("label_name", labelName),
+				logger.Err(discardErr),
+			)
+		}
+		return ImportResult{}, err
+	}
+
+	reqLog.Info("manual_mail_workflow_import_accepted",
+		logger.UserID(cmd.UserID),
+		logger.Uint("connection_id", stored.ConnectionID),
+		logger.String("workflow_id", started.WorkflowID),
+		logger.String("label_name", labelName),
+		logger.Int("file_count", len(cmd.Files)),
+		logger.Int("message_count", stored.MessageCount),
+	)
+
+	return ImportResult{
+		WorkflowID:   started.WorkflowID,
+		Status:       started.Status,
+		LabelName:    labelName,
+		MessageCount: stored.MessageCount,
+	}, nil
+}
+
+// importPeriod は Date ヘッダーを読み取れたメールがすべて入る取得期間を返す。
+// until は排他的なので最新のメールより 1 秒後にする。日時の無いメールしか無い場合は受付時刻の 1 秒間とし、
+// それらのメールは fetch stage で正規化失敗として記録される。
+func importPeriod(stored StoredMailImport, now time.Time) (time.Time, time.Time) {
+	if stored.OldestReceivedAt.IsZero() || stored.LatestReceivedAt.IsZero() {
+		return now, now.Add(time.Second)
+	}
+	return stored.OldestReceivedAt.UTC(), stored.LatestReceivedAt.UTC().Add(time.Second)
+}
+
+func validateImportCommand(cmd ImportCommand) error {
+	if cmd.UserID == 0 {
+		return fmt.Errorf("%w: user_id is required", ErrInvalidCommand)
+	}
+	if len(cmd.Files) == 0 {
+		return fmt.Errorf("%w: at least one file is required", ErrMailImportInvalid)
+	}
+	if len(cmd.Files) > MaxMailImportFiles {
+		return fmt.Errorf("%w: up to %d files can be uploaded at once", ErrMailImportTooLarge, MaxMailImportFiles)
+	}
+	for _, file := range cmd.Files {
+		if len(strings.TrimSpace(string(file.Content))) == 0 {
+			return fmt.Errorf("%w: %s is empty", ErrMailImportInvalid, file.Name)
+		}
+	}
+	return nil
+}
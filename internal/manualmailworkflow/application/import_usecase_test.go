@@ -0,0 +1,224 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubMailImportStore struct {
+	store   func(ctx context.Context, req MailImportRequest) (StoredMailImport, error)
+	discard func(ctx context.Context, connectionID uint, labelName string) error
+}
+
+func (s *stubMailImportStore) Store(ctx context.Context, req MailImportRequest) (StoredMailImport, error) {
+	return s.store(ctx, req)
+}
+
+func (s *stubMailImportStore) Discard(ctx context.Context, connectionID uint, labelName string) error {
+	if s.discard == nil {
+		return nil
+	}
+	return s.discard(ctx, connectionID, labelName)
+}
+
+func TestImportUseCase_Import_StartsWorkflowForStoredLabel(t *testing.T) {
+	t.Parallel()
+
+	oldest := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	latest := time.Date(2026, 9, 30, 12, 0, 0, 0, time.UTC)
+	var storedLabel string
+	uc := NewImportUseCase(
+		&stubMailImportStore{
+			store: func(ctx context.Context, req MailImportRequest) (StoredMailImport, error) {
+				if req.UserID != 7 || len(req.Files) != 1 {
+					t.Fatalf("unexpected store request: %+v", req)
+				}
+				if !strings.HasPrefix(req.LabelName, mailImportLabelPrefix) {
+					t.Fatalf("unexpected label name: %s", req.LabelName)
+				}
+				storedLabel = req.LabelName
+				return StoredMailImport{ConnectionID: 31, MessageCount: 2, OldestReceivedAt: oldest, LatestReceivedAt: latest}, nil
+			},
+		},
+		&stubStartUseCase{
+			start: func(ctx context.Context, cmd Command) (StartResult, error) {
+				if cmd.UserID != 7 || cmd.ConnectionID != 31 || !cmd.DryRun {
+					t.Fatalf("unexpected command: %+v", cmd)
+				}
+				if cmd.Condition.LabelName != storedLabel {
+					t.Fatalf("unexpected label: got=%s want=%s", cmd.Condition.LabelName, storedLabel)
+				}
+				if !cmd.Condition.Since.Equal(oldest) || !cmd.Condition.Until.Equal(latest.Add(time.Second)) {
+					t.Fatalf("unexpected period: %s - %s", cmd.Condition.Since, cmd.Condition.Until)
+				}
+				return StartResult{WorkflowID: "wf-1", Status: "queued"}, nil
+			},
+		},
+		&fixedClock{now: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		nil,
+	)
+
+	result, err := uc.Import(context.Background(), ImportCommand{
+		UserID: 7,
+		Files:  []MailImportFile{{Name: "invoices.mbox", Content: []byte("From a@example.com\n\nSubject: a\n\nbody\n")}},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if result.WorkflowID != "wf-1" || result.Status != "queued" || result.LabelName != storedLabel || result.MessageCount != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestImportUseCase_Import_UsesAcceptedTimeWhenNoMessageHasDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	uc := NewImportUseCase(
+		&stubMailImportStore{
+			store: func(ctx context.Context, req MailImportRequest) (StoredMailImport, error) {
+				return StoredMailImport{ConnectionID: 31, MessageCount: 1}, nil
+			},
+		},
+		&stubStartUseCase{
+			start: func(ctx context.Context, cmd Command) (StartResult, error) {
+				if !cmd.Condition.Since.Equal(now) || !cmd.Condition.Until.Equal(now.Add(time.Second)) {
+					t.Fatalf("unexpected period: %s - %s", cmd.Condition.Since, cmd.Condition.Until)
+				}
+				return StartResult{WorkflowID: "wf-1", Status: "queued"}, nil
+			},
+		},
+		&fixedClock{now: now},
+		nil,
+	)
+
+	if _, err := uc.Import(context.Background(), ImportCommand{
+		UserID: 7,
+		Files:  []MailImportFile{{Name: "undated.eml", Content: []byte("Subject: a\n\nbody\n")}},
+	}); err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+}
+
+func TestImportUseCase_Import_DiscardsStoredMessagesWhenStartFails(t *testing.T) {
+	t.Parallel()
+
+	var storedLabel, discardedLabel string
+	var discardedConnectionID uint
+	uc := NewImportUseCase(
+		&stubMailImportStore{
+			store: func(ctx context.Context, req MailImportRequest) (StoredMailImport, error) {
+				storedLabel = req.LabelName
+				return StoredMailImport{ConnectionID: 31, MessageCount: 1}, nil
+			},
+			discard: func(ctx context.Context, connectionID uint, labelName string) error {
+				if ctx.Err() != nil {
+					t.Fatalf("discard must not use the cancelled request context: %v", ctx.Err())
+				}
+				discardedConnectionID, discardedLabel = connectionID, labelName
+				return nil
+			},
+		},
+		&stubStartUseCase{
+			start: func(ctx context.Context, cmd Command) (StartResult, error) {
+				return StartResult{}, ErrWorkflowConflict
+			},
+		},
+		&fixedClock{now: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)},
+		nil,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := uc.Import(ctx, ImportCommand{
+		UserID: 7,
+		Files:  []MailImportFile{{Name: "a.eml", Content: []byte("Subject: a\n\nbody\n")}},
+	})
+	if !errors.Is(err, ErrWorkflowConflict) {
+		t.Fatalf("expected ErrWorkflowConflict, got %v", err)
+	}
+	if discardedConnectionID != 31 || discardedLabel != storedLabel {
+		t.Fatalf("expected the stored import to be discarded, got connection=%d label=%s want label=%s", discardedConnectionID, discardedLabel, storedLabel)
+	}
+}
+
+func TestImportUseCase_Import_RejectsInvalidUploads(t *testing.T) {
+	t.Parallel()
+
+	tooMany := make([]MailImportFile, MaxMailImportFiles+1)
+	for i := range tooMany {
+		tooMany[i] = MailImportFile{Name: "a.eml", Content: []byte("Subject: a\n\nbody\n")}
+	}
+
+	tests := []struct {
+		name    string
+		cmd     ImportCommand
+		wantErr error
+	}{
+		{name: "missing user", cmd: ImportCommand{Files: tooMany[:1]}, wantErr: ErrInvalidCommand},
+		{name: "no file", cmd: ImportCommand{UserID: 7}, wantErr: ErrMailImportInvalid},
+		{name: "empty file", cmd: ImportCommand{UserID: 7, Files: []MailImportFile{{Name: "empty.eml", Content: []byte(" \n")}}}, wantErr: ErrMailImportInvalid},
+		{name: "too many files", cmd: ImportCommand{UserID: 7, Files: tooMany}, wantErr: ErrMailImportTooLarge},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewImportUseCase(
+				&stubMailImportStore{
+					store: func(ctx context.Context, req MailImportRequest) (StoredMailImport, error) {
+						t.Fatal("Store must not be called")
+						return StoredMailImport{}, nil
+					},
+				},
+				&stubStartUseCase{
+					start: func(ctx context.Context, cmd Command) (StartResult, error) {
+						t.Fatal("Start must not be called")
+						return StartResult{}, nil
+					},
+				},
+				nil,
+				nil,
+			)
+
+			_, err := uc.Import(context.Background(), tt.cmd)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unexpected error: got=%v want=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportUseCase_Import_ReturnsStoreError(t *testing.T) {
+	t.Parallel()
+
+	uc := NewImportUseCase(
+		&stubMailImportStore{
+			store: func(ctx context.Context, req MailImportRequest) (StoredMailImport, error) {
+				return StoredMailImport{}, ErrMailImportTooLarge
+			},
+		},
+		&stubStartUseCase{
+			start: func(ctx context.Context, cmd Command) (StartResult, error) {
+				t.Fatal("Start must not be called")
+				return StartResult{}, nil
+			},
+		},
+		nil,
+		nil,
+	)
+
+	_, err := uc.Import(context.Background(), ImportCommand{
+		UserID: 7,
+		Files:  []MailImportFile{{Name: "a.eml", Content: []byte("Subject: a\n\nbody\n")}},
+	})
+	if !errors.Is(err, ErrMailImportTooLarge) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -36,14 +36,25 @@ type StageSummaryView struct {
 	Failures              []StageFailureView
 }
 
+// AdditionalStageSummaryView is the summary of a stage added to the pipeline, in execution order.
+type AdditionalStageSummaryView struct {
+	Stage string
+	StageSummaryView
+}
+
 // WorkflowHistoryListItem is one workflow row returned by the list API.
 type WorkflowHistoryListItem struct {
 	WorkflowID         string
+	RetryOfWorkflowID  *string
+	ParentWorkflowID   *string
+	FanOut             bool
+	DryRun             bool
 	Provider           string
 	AccountIdentifier  string
 	LabelName          string
 	Since              time.Time
 	Until              time.Time
+	Filter             FetchFilter
 	Status             string
 	CurrentStage       *string
 	QueuedAt           time.Time
@@ -54,6 +65,8 @@ type WorkflowHistoryListItem struct {
 	VendorResolution   StageSummaryView
 	BillingEligibility StageSummaryView
 	Billing            StageSummaryView
+	AdditionalStages   []AdditionalStageSummaryView
+	Timeline           []StageTimelineView
 }
 
 // ListQuery is the input contract for the workflow history list API.
@@ -165,7 +178,8 @@ func isWorkflowStatusFilter(status string) bool {
 		WorkflowStatusRunning,
 		WorkflowStatusSucceeded,
 		WorkflowStatusPartialSuccess,
-		WorkflowStatusFailed:
+		WorkflowStatusFailed,
+		WorkflowStatusCancelled:
 		return true
 	default:
 		return false
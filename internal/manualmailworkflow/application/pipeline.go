@@ -0,0 +1,378 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrInvalidStagePipeline indicates the stage registry cannot build a runnable pipeline.
+var ErrInvalidStagePipeline = errors.New("invalid manual mail workflow stage pipeline")
+
+const (
+	// StageArtifactEmails は fetch stage が出力する取得済みメール。
+	StageArtifactEmails = "emails"
+	// StageArtifactParsedEmails は analysis stage が出力する解析済みメール。
+	StageArtifactParsedEmails = "parsed_emails"
+	// StageArtifactResolvedItems は vendorresolution stage が出力する支払先解決済みの item。
+	StageArtifactResolvedItems = "resolved_items"
+	// StageArtifactEligibleItems は billingeligibility stage が出力する請求成立済みの item。
+	StageArtifactEligibleItems = "eligible_items"
+	// StageArtifactBillings は billing stage が出力する作成済みの請求。
+	StageArtifactBillings = "billings"
+)
+
+// stageNamePattern は履歴・failure row の stage 列（varchar(32)）に保存できる stage 名。
+var stageNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// StageContract は stage が読む入力と書き出す出力を宣言する。
+// 入力は同じ pipeline の前の stage が出力するものでなければならない。
+type StageContract struct {
+	Consumes []string
+	Produces []string
+	// RunsInDryRun が false の stage は dry-run では実行しない。外部への通知など、書き込みを伴う stage は false にする。
+	RunsInDryRun bool
+}
+
+// PipelineStage は workflow の pipeline に登録する 1 stage。
+type PipelineStage interface {
+	// Name は履歴・進捗イベント・failure row に記録する stage 名。
+	Name() string
+	Contract() StageContract
+	// Ready は stage に渡す入力があるかを返す。false の stage は実行も記録もしない。
+	Ready(state *PipelineState) bool
+	// Run は state から入力を読み、出力を state に書いて、履歴に保存する件数と failure row を返す。
+	// HistoryID と Stage は runner が埋める。
+	Run(ctx context.Context, state *PipelineState) (StageProgress, error)
+}
+
+// PipelineState は 1 回の workflow 実行で stage 間に受け渡す状態。
+type PipelineState struct {
+	job    DispatchJob
+	result *Result
+	seeds  stageSeeds
+}
+
+// Job は実行中の job を返す。
+func (s *PipelineState) Job() DispatchJob {
+	return s.job
+}
+
+// Result はここまでに実行した stage の結果を返す。
+func (s *PipelineState) Result() Result {
+	return *s.result
+}
+
+// Emails は analysis に渡す取得済みメールを返す。retry run では analysis で失敗した取得済みメールも含む。
+func (s *PipelineState) Emails() []CreatedEmail {
+	emails := make([]CreatedEmail, 0, len(s.result.Fetch.CreatedEmails)+len(s.result.Fetch.ExistingEmails))
+	emails = append(emails, s.result.Fetch.CreatedEmails...)
+	return append(emails, s.result.Fetch.ExistingEmails...)
+}
+
+// ParsedEmails は vendorresolution に渡す解析済みメールを返す。retry run や再開では途中から投入したものも含む。
+func (s *PipelineState) ParsedEmails() []ParsedEmail {
+	return append(append([]ParsedEmail(nil), s.result.Analysis.ParsedEmails...), s.seeds.parsedEmails...)
+}
+
+// ResolvedItems は billingeligibility に渡す支払先解決済みの item を返す。
+func (s *PipelineState) ResolvedItems() []ResolvedItem {
+	return append(append([]ResolvedItem(nil), s.result.VendorResolution.ResolvedItems...), s.seeds.resolvedItems...)
+}
+
+// EligibleItems は billing に渡す請求成立済みの item を返す。
+func (s *PipelineState) EligibleItems() []EligibleItem {
+	return append(append([]EligibleItem(nil), s.result.BillingEligibility.EligibleItems...), s.seeds.eligibleItems...)
+}
+
+// Output は追加した stage が key で書き出した出力を返す。
+func (s *PipelineState) Output(key string) (any, bool) {
+	value, ok := s.result.StageOutputs[key]
+	return value, ok
+}
+
+// SetOutput は追加した stage の出力を、後続の stage と workflow の Result に渡す。
+func (s *PipelineState) SetOutput(key string, value any) {
+	if s.result.StageOutputs == nil {
+		s.result.StageOutputs = make(map[string]any)
+	}
+	s.result.StageOutputs[key] = value
+}
+
+// BuiltinStages は pipeline の骨格となる組み込み stage の port。
+// retry run と dry-run のプレビューは、pipeline の外からもこれらを呼んで stage 入力を組み立て直す。
+type BuiltinStages struct {
+	Fetch              FetchStage
+	Analyze            AnalyzeStage
+	VendorResolution   VendorResolutionStage
+	BillingEligibility BillingEligibilityStage
+	Billing            BillingStage
+}
+
+// StagePlacement は After で指定した stage の直後に Stage を追加する。
+// After には組み込み stage だけでなく、追加した stage も指定できる。同じ After を持つ placement は登録順に並ぶ。
+type StagePlacement struct {
+	After string
+	Stage PipelineStage
+}
+
+// StagePipeline は workflow が実行する stage を実行順に並べた registry。
+type StagePipeline struct {
+	builtin BuiltinStages
+	stages  []PipelineStage
+}
+
+// NewStagePipeline は組み込み stage の間に追加の stage を差し込んだ pipeline を組み立てる。
+// stage 名の重複や、前の stage が出力しない入力を読む stage があれば ErrInvalidStagePipeline を返す。
+func NewStagePipeline(builtin BuiltinStages, placements ...StagePlacement) (StagePipeline, error) {
+	for _, placement := range placements {
+		if placement.Stage == nil {
+			return StagePipeline{}, fmt.Errorf("%w: stage placed after %q is nil", ErrInvalidStagePipeline, placement.After)
+		}
+	}
+
+	builtinStages := builtinPipelineStages(builtin)
+	stages := make([]PipelineStage, 0, len(builtinStages)+len(placements))
+	placed := make([]bool, len(placements))
+	var appendWithFollowers func(stage PipelineStage)
+	appendWithFollowers = func(stage PipelineStage) {
+		stages = append(stages, stage)
+		for idx, placement := range placements {
+			if !placed[idx] && placement.After == stage.Name() {
+				placed[idx] = true
+				appendWithFollowers(placement.Stage)
+			}
+		}
+	}
+	for _, stage := range builtinStages {
+		appendWithFollowers(stage)
+	}
+	for idx, placement := range placements {
+		if !placed[idx] {
+			return StagePipeline{}, fmt.Errorf("%w: stage %q is placed after unknown stage %q", ErrInvalidStagePipeline, placement.Stage.Name(), placement.After)
+		}
+	}
+
+	if err := validatePipelineStages(stages); err != nil {
+		return StagePipeline{}, err
+	}
+	return StagePipeline{builtin: builtin, stages: stages}, nil
+}
+
+// StageNames は pipeline の stage 名を実行順に返す。
+func (p StagePipeline) StageNames() []string {
+	names := make([]string, 0, len(p.stages))
+	for _, stage := range p.stages {
+		names = append(names, stage.Name())
+	}
+	return names
+}
+
+func validatePipelineStages(stages []PipelineStage) error {
+	seen := make(map[string]struct{}, len(stages))
+	available := make(map[string]struct{})
+	for _, stage := range stages {
+		name := stage.Name()
+		if !stageNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: stage name %q must be lower snake case up to 32 characters", ErrInvalidStagePipeline, name)
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("%w: stage %q is registered twice", ErrInvalidStagePipeline, name)
+		}
+		seen[name] = struct{}{}
+
+		contract := stage.Contract()
+		for _, input := range contract.Consumes {
+			if _, ok := available[input]; !ok {
+				return fmt.Errorf("%w: stage %q consumes %q before any stage produces it", ErrInvalidStagePipeline, name, input)
+			}
+		}
+		for _, output := range contract.Produces {
+			available[output] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func isBuiltinWorkflowStage(stage string) bool {
+	return workflowStageIndex(stage) >= 0
+}
+
+func builtinPipelineStages(builtin BuiltinStages) []PipelineStage {
+	return []PipelineStage{
+		fetchPipelineStage{stage: builtin.Fetch},
+		analysisPipelineStage{stage: builtin.Analyze},
+		vendorResolutionPipelineStage{stage: builtin.VendorResolution},
+		billingEligibilityPipelineStage{stage: builtin.BillingEligibility},
+		billingPipelineStage{stage: builtin.Billing},
+	}
+}
+
+type fetchPipelineStage struct {
+	stage FetchStage
+}
+
+func (fetchPipelineStage) Name() string {
+	return workflowStageFetch
+}
+
+func (fetchPipelineStage) Contract() StageContract {
+	return StageContract{Produces: []string{StageArtifactEmails}, RunsInDryRun: true}
+}
+
+// Ready は retry run や再開では、取得し直すメールがあるときだけ fetch を実行させる。
+func (fetchPipelineStage) Ready(state *PipelineState) bool {
+	return (!state.seeds.retry && state.seeds.resumedFrom == "") || len(state.seeds.fetchMessageIDs) > 0
+}
+
+func (s fetchPipelineStage) Run(ctx context.Context, state *PipelineState) (StageProgress, error) {
+	result, err := s.stage.Execute(ctx, FetchCommand{
+		UserID:                state.job.UserID,
+		ConnectionID:          state.job.ConnectionID,
+		Condition:             state.job.Condition,
+		MessageIDs:            append([]string(nil), state.seeds.fetchMessageIDs...),
+		IncludeExistingEmails: state.seeds.retry,
+		DryRun:                state.job.DryRun,
+	})
+	if err != nil {
+		return StageProgress{}, err
+	}
+	state.result.Fetch = result
+	return buildFetchStageProgress(state.job.HistoryID, result), nil
+}
+
+type analysisPipelineStage struct {
+	stage AnalyzeStage
+}
+
+func (analysisPipelineStage) Name() string {
+	return workflowStageAnalysis
+}
+
+func (analysisPipelineStage) Contract() StageContract {
+	return StageContract{Consumes: []string{StageArtifactEmails}, Produces: []string{StageArtifactParsedEmails}, RunsInDryRun: true}
+}
+
+func (analysisPipelineStage) Ready(state *PipelineState) bool {
+	return len(state.Emails()) > 0
+}
+
+func (s analysisPipelineStage) Run(ctx context.Context, state *PipelineState) (StageProgress, error) {
+	result, err := s.stage.Execute(ctx, AnalyzeCommand{
+		UserID: state.job.UserID,
+		Emails: state.Emails(),
+		DryRun: state.job.DryRun,
+	})
+	if err != nil {
+		return StageProgress{}, err
+	}
+	state.result.Analysis = result
+	return buildAnalysisStageProgress(state.job.HistoryID, result), nil
+}
+
+type vendorResolutionPipelineStage struct {
+	stage VendorResolutionStage
+}
+
+func (vendorResolutionPipelineStage) Name() string {
+	return workflowStageVendorResolution
+}
+
+func (vendorResolutionPipelineStage) Contract() StageContract {
+	return StageContract{Consumes: []string{StageArtifactParsedEmails}, Produces: []string{StageArtifactResolvedItems}, RunsInDryRun: true}
+}
+
+// Ready は持ち越した技術的失敗しかない場合も、件数を記録するため stage を実行させる。
+func (vendorResolutionPipelineStage) Ready(state *PipelineState) bool {
+	return len(state.ParsedEmails()) > 0 || len(state.seeds.vendorResolutionFailures) > 0
+}
+
+func (s vendorResolutionPipelineStage) Run(ctx context.Context, state *PipelineState) (StageProgress, error) {
+	parsedEmails := state.ParsedEmails()
+	var result VendorResolutionResult
+	if len(parsedEmails) > 0 {
+		var err error
+		result, err = s.stage.Execute(ctx, VendorResolutionCommand{
+			UserID:       state.job.UserID,
+			ParsedEmails: append([]ParsedEmail(nil), parsedEmails...),
+			DryRun:       state.job.DryRun,
+		})
+		if err != nil {
+			return StageProgress{}, err
+		}
+	}
+	result.Failures = append(result.Failures, state.seeds.vendorResolutionFailures...)
+	state.result.VendorResolution = result
+	return buildVendorResolutionStageProgress(state.job.HistoryID, parsedEmails, result), nil
+}
+
+type billingEligibilityPipelineStage struct {
+	stage BillingEligibilityStage
+}
+
+func (billingEligibilityPipelineStage) Name() string {
+	return workflowStageBillingEligibility
+}
+
+func (billingEligibilityPipelineStage) Contract() StageContract {
+	return StageContract{Consumes: []string{StageArtifactResolvedItems}, Produces: []string{StageArtifactEligibleItems}, RunsInDryRun: true}
+}
+
+func (billingEligibilityPipelineStage) Ready(state *PipelineState) bool {
+	return len(state.ResolvedItems()) > 0 || len(state.seeds.billingEligibilityFailures) > 0
+}
+
+func (s billingEligibilityPipelineStage) Run(ctx context.Context, state *PipelineState) (StageProgress, error) {
+	resolvedItems := state.ResolvedItems()
+	var result BillingEligibilityResult
+	if len(resolvedItems) > 0 {
+		var err error
+		result, err = s.stage.Execute(ctx, BillingEligibilityCommand{
+			UserID:        state.job.UserID,
+			ResolvedItems: resolvedItems,
+			DryRun:        state.job.DryRun,
+		})
+		if err != nil {
+			return StageProgress{}, err
+		}
+	}
+	result.Failures = append(result.Failures, state.seeds.billingEligibilityFailures...)
+	state.result.BillingEligibility = result
+	return buildBillingEligibilityStageProgress(state.job.HistoryID, result), nil
+}
+
+type billingPipelineStage struct {
+	stage BillingStage
+}
+
+func (billingPipelineStage) Name() string {
+	return workflowStageBilling
+}
+
+// Contract は dry-run では billing を実行させない。代わりに runner がプレビューを保存する。
+func (billingPipelineStage) Contract() StageContract {
+	return StageContract{Consumes: []string{StageArtifactEligibleItems}, Produces: []string{StageArtifactBillings}}
+}
+
+func (billingPipelineStage) Ready(state *PipelineState) bool {
+	return len(state.EligibleItems()) > 0 || len(state.seeds.billingFailures) > 0
+}
+
+func (s billingPipelineStage) Run(ctx context.Context, state *PipelineState) (StageProgress, error) {
+	eligibleItems := state.EligibleItems()
+	var result BillingResult
+	if len(eligibleItems) > 0 {
+		var err error
+		result, err = s.stage.Execute(ctx, BillingCommand{
+			UserID:        state.job.UserID,
+			EligibleItems: eligibleItems,
+		})
+		if err != nil {
+			return StageProgress{}, err
+		}
+	}
+	result.Failures = append(result.Failures, state.seeds.billingFailures...)
+	state.result.Billing = result
+	return buildBillingStageProgress(state.job.HistoryID, result), nil
+}
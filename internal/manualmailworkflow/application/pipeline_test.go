@@ -0,0 +1,250 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stubPipelineStage struct {
+	name     string
+	contract StageContract
+	ready    func(state *PipelineState) bool
+	run      func(ctx context.Context, state *PipelineState) (StageProgress, error)
+}
+
+func (s *stubPipelineStage) Name() string {
+	return s.name
+}
+
+func (s *stubPipelineStage) Contract() StageContract {
+	return s.contract
+}
+
+func (s *stubPipelineStage) Ready(state *PipelineState) bool {
+	if s.ready == nil {
+		return true
+	}
+	return s.ready(state)
+}
+
+func (s *stubPipelineStage) Run(ctx context.Context, state *PipelineState) (StageProgress, error) {
+	if s.run == nil {
+		return StageProgress{}, nil
+	}
+	return s.run(ctx, state)
+}
+
+func TestNewStagePipeline_PlacesStagesAfterTheirAnchor(t *testing.T) {
+	t.Parallel()
+
+	pipeline, err := NewStagePipeline(BuiltinStages{},
+		StagePlacement{After: workflowStageBilling, Stage: &stubPipelineStage{name: "notification"}},
+		StagePlacement{After: workflowStageAnalysis, Stage: &stubPipelineStage{name: "categorization", contract: StageContract{Consumes: []string{StageArtifactParsedEmails}, Produces: []string{"categories"}}}},
+		StagePlacement{After: "categorization", Stage: &stubPipelineStage{name: "subscription_detection", contract: StageContract{Consumes: []string{"categories"}}}},
+		StagePlacement{After: workflowStageAnalysis, Stage: &stubPipelineStage{name: "tagging"}},
+	)
+	if err != nil {
+		t.Fatalf("NewStagePipeline returned error: %v", err)
+	}
+
+	want := []string{
+		workflowStageFetch,
+		workflowStageAnalysis,
+		"categorization",
+		"subscription_detection",
+		"tagging",
+		workflowStageVendorResolution,
+		workflowStageBillingEligibility,
+		workflowStageBilling,
+		"notification",
+	}
+	if got := pipeline.StageNames(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected stage order: %v", got)
+	}
+}
+
+func TestNewStagePipeline_RejectsInvalidRegistrations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		placement StagePlacement
+	}{
+		{name: "nil stage", placement: StagePlacement{After: workflowStageFetch}},
+		{name: "unknown anchor", placement: StagePlacement{After: "mailfetch", Stage: &stubPipelineStage{name: "categorization"}}},
+		{name: "duplicate name", placement: StagePlacement{After: workflowStageBilling, Stage: &stubPipelineStage{name: workflowStageAnalysis}}},
+		{name: "invalid name", placement: StagePlacement{After: workflowStageBilling, Stage: &stubPipelineStage{name: "Notify Slack"}}},
+		{
+			name: "input produced later",
+			placement: StagePlacement{After: workflowStageFetch, Stage: &stubPipelineStage{
+				name:     "categorization",
+				contract: StageContract{Consumes: []string{StageArtifactParsedEmails}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := NewStagePipeline(BuiltinStages{}, tt.placement); !errors.Is(err, ErrInvalidStagePipeline) {
+				t.Fatalf("expected ErrInvalidStagePipeline, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPipelineUseCaseExecute_RunsAdditionalStage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	stageOrder := make([]string, 0, 3)
+	savedProgress := make(map[string]StageProgress)
+	completedStatus := ""
+
+	categorization := &stubPipelineStage{
+		name:     "categorization",
+		contract: StageContract{Consumes: []string{StageArtifactParsedEmails}, Produces: []string{"categories"}, RunsInDryRun: true},
+		ready: func(state *PipelineState) bool {
+			return len(state.ParsedEmails()) > 0
+		},
+		run: func(ctx context.Context, state *PipelineState) (StageProgress, error) {
+			parsedEmails := state.ParsedEmails()
+			state.SetOutput("categories", map[string]string{parsedEmails[0].ExternalMessageID: "saas"})
+			return StageProgress{
+				SuccessCount:         1,
+				BusinessFailureCount: 1,
+				FailureRecords:       []StageFailureRecord{{ExternalMessageID: stringPtr("msg-2"), ReasonCode: "category_unknown", Message: "分類できませんでした。"}},
+			}, nil
+		},
+	}
+	pipeline, err := NewStagePipeline(BuiltinStages{
+		Fetch: &stubFetchStage{execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+			return FetchResult{CreatedEmails: []CreatedEmail{{EmailID: 101, ExternalMessageID: "msg-1"}}}, nil
+		}},
+		Analyze: &stubAnalyzeStage{execute: func(ctx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+			return AnalyzeResult{ParsedEmails: []ParsedEmail{{ParsedEmailID: 9001, EmailID: 101, ExternalMessageID: "msg-1"}}, ParsedEmailCount: 1}, nil
+		}},
+		VendorResolution: &stubVendorResolutionStage{execute: func(ctx context.Context, cmd VendorResolutionCommand) (VendorResolutionResult, error) {
+			return VendorResolutionResult{}, nil
+		}},
+		BillingEligibility: &stubBillingEligibilityStage{execute: func(ctx context.Context, cmd BillingEligibilityCommand) (BillingEligibilityResult, error) {
+			t.Fatal("billing eligibility should be skipped when nothing is resolved")
+			return BillingEligibilityResult{}, nil
+		}},
+		Billing: &stubBillingStage{execute: func(ctx context.Context, cmd BillingCommand) (BillingResult, error) {
+			t.Fatal("billing should be skipped when nothing is resolved")
+			return BillingResult{}, nil
+		}},
+	}, StagePlacement{After: workflowStageAnalysis, Stage: categorization})
+	if err != nil {
+		t.Fatalf("NewStagePipeline returned error: %v", err)
+	}
+
+	uc := NewPipelineUseCase(pipeline, &stubWorkflowStatusRepository{
+		markRunning: func(ctx context.Context, historyID uint64, currentStage string) error {
+			stageOrder = append(stageOrder, currentStage)
+			return nil
+		},
+		saveStage: func(ctx context.Context, progress StageProgress) error {
+			savedProgress[progress.Stage] = progress
+			return nil
+		},
+		complete: func(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error {
+			completedStatus = status
+			return nil
+		},
+	}, nil, nil, nil, &fixedClock{now: now}, logger.NewNop())
+
+	result, err := uc.Execute(context.Background(), DispatchJob{
+		HistoryID:    77,
+		WorkflowID:   "wf-pipeline",
+		UserID:       1,
+		ConnectionID: 2,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     now.Add(-time.Hour),
+			Until:     now.Add(time.Hour),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	wantOrder := []string{workflowStageFetch, workflowStageAnalysis, "categorization", workflowStageVendorResolution}
+	if !reflect.DeepEqual(stageOrder, wantOrder) {
+		t.Fatalf("unexpected stage order: %v", stageOrder)
+	}
+	progress, ok := savedProgress["categorization"]
+	if !ok {
+		t.Fatal("categorization progress was not saved")
+	}
+	if progress.HistoryID != 77 || progress.Handoff != nil || progress.FailureRecords[0].Stage != "categorization" {
+		t.Fatalf("unexpected categorization progress: %+v", progress)
+	}
+	if savedProgress[workflowStageAnalysis].Handoff == nil {
+		t.Fatal("builtin stages should keep saving handoffs")
+	}
+	if categories, ok := result.StageOutputs["categories"].(map[string]string); !ok || categories["msg-1"] != "saas" {
+		t.Fatalf("unexpected stage outputs: %+v", result.StageOutputs)
+	}
+	if completedStatus != WorkflowStatusPartialSuccess {
+		t.Fatalf("expected failures in an additional stage to make the workflow partial_success, got %s", completedStatus)
+	}
+}
+
+func TestPipelineUseCaseExecute_SkipsWritingStageInDryRun(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	notification := &stubPipelineStage{
+		name: "notification",
+		run: func(ctx context.Context, state *PipelineState) (StageProgress, error) {
+			t.Fatal("a stage that does not run in dry-run should be skipped")
+			return StageProgress{}, nil
+		},
+	}
+	pipeline, err := NewStagePipeline(BuiltinStages{
+		Fetch: &stubFetchStage{execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+			return FetchResult{}, nil
+		}},
+		Analyze:            &stubAnalyzeStage{},
+		VendorResolution:   &stubVendorResolutionStage{},
+		BillingEligibility: &stubBillingEligibilityStage{},
+		Billing:            &stubBillingStage{},
+	}, StagePlacement{After: workflowStageFetch, Stage: notification})
+	if err != nil {
+		t.Fatalf("NewStagePipeline returned error: %v", err)
+	}
+
+	completedStatus := ""
+	uc := NewPipelineUseCase(pipeline, &stubWorkflowStatusRepository{
+		complete: func(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error {
+			completedStatus = status
+			return nil
+		},
+	}, nil, nil, &stubWorkflowPreviewRepository{}, &fixedClock{now: now}, logger.NewNop())
+
+	if _, err := uc.Execute(context.Background(), DispatchJob{
+		HistoryID:    78,
+		WorkflowID:   "wf-dry-run",
+		UserID:       1,
+		ConnectionID: 2,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     now.Add(-time.Hour),
+			Until:     now.Add(time.Hour),
+		},
+		DryRun: true,
+	}); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if completedStatus != WorkflowStatusSucceeded {
+		t.Fatalf("unexpected completed status: %s", completedStatus)
+	}
+}
@@ -0,0 +1,264 @@
+package application
+
+import (
+	commondomain "business/internal/common/domain"
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrWorkflowNotDryRun indicates the workflow was started normally and has no preview.
+	ErrWorkflowNotDryRun = errors.New("manual mail workflow is not a dry run")
+	// ErrWorkflowPreviewNotReady indicates the dry run has not finished building its preview.
+	ErrWorkflowPreviewNotReady = errors.New("manual mail workflow preview is not ready")
+)
+
+// PreviewBillingItem is one billing a dry run would create.
+// VendorID is zero when the vendor does not exist yet and would be auto-registered by a normal run.
+type PreviewBillingItem struct {
+	ExternalMessageID   string
+	VendorID            uint
+	VendorName          string
+	VendorWouldRegister bool
+	MatchedBy           string
+	ProductNameDisplay  *string
+	BillingNumber       string
+	InvoiceNumber       *string
+	Amount              float64
+	Currency            string
+	BillingDate         *time.Time
+	PaymentCycle        string
+}
+
+// PreviewDuplicateItem is a billing a normal run would skip as duplicate_billing.
+// ExistingBillingID is zero when the duplicate is another message of the same dry run.
+type PreviewDuplicateItem struct {
+	PreviewBillingItem
+	ExistingBillingID uint
+	ReasonCode        string
+	Message           string
+}
+
+// PreviewUnresolvedItem is a parsed email whose vendor could not be resolved nor registered.
+type PreviewUnresolvedItem struct {
+	ExternalMessageID   string
+	CandidateVendorName string
+	ReasonCode          string
+	Message             string
+}
+
+// PreviewIneligibleItem is a resolved item that does not satisfy the billing conditions.
+type PreviewIneligibleItem struct {
+	ExternalMessageID string
+	VendorName        string
+	ReasonCode        string
+	Message           string
+}
+
+// WorkflowPreview is what a dry run found: the billings a normal run would create, and why the rest would not be.
+type WorkflowPreview struct {
+	WouldCreateItems []PreviewBillingItem
+	DuplicateItems   []PreviewDuplicateItem
+	UnresolvedItems  []PreviewUnresolvedItem
+	IneligibleItems  []PreviewIneligibleItem
+	GeneratedAt      time.Time
+}
+
+// BillingIdentity is the unique key of a billing row per user.
+type BillingIdentity struct {
+	VendorID      uint
+	BillingNumber string
+}
+
+// WorkflowPreviewView is the dry-run workflow header with its preview.
+// Preview is nil until the dry run completes. A sync-all parent never has one; its children do.
+type WorkflowPreviewView struct {
+	WorkflowID string
+	Status     string
+	DryRun     bool
+	FanOut     bool
+	Preview    *WorkflowPreview
+}
+
+// WorkflowPreviewRepository persists dry-run previews and looks up existing billings without writing them.
+type WorkflowPreviewRepository interface {
+	// FindExistingBillingIDs returns the billing ID of each identity that already exists for the user.
+	FindExistingBillingIDs(ctx context.Context, userID uint, identities []BillingIdentity) (map[BillingIdentity]uint, error)
+	SavePreview(ctx context.Context, historyID uint64, preview WorkflowPreview) error
+	FindPreview(ctx context.Context, userID uint, workflowID string) (WorkflowPreviewView, error)
+}
+
+// PreviewQuery identifies the dry-run workflow whose preview the user wants to see.
+type PreviewQuery struct {
+	UserID     uint
+	WorkflowID string
+}
+
+// PreviewUseCase loads the preview of a dry-run workflow for the authenticated user.
+type PreviewUseCase interface {
+	Get(ctx context.Context, query PreviewQuery) (WorkflowPreviewView, error)
+}
+
+type previewUseCase struct {
+	repository WorkflowPreviewRepository
+	log        logger.Interface
+}
+
+// NewPreviewUseCase creates a dry-run preview read use case.
+func NewPreviewUseCase(repository WorkflowPreviewRepository, log logger.Interface) PreviewUseCase {
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &previewUseCase{
+		repository: repository,
+		log:        log.With(logger.Component("manual_mail_workflow_preview_usecase")),
+	}
+}
+
+// Get returns the preview once the dry run has completed.
+func (uc *previewUseCase) Get(ctx context.Context, query PreviewQuery) (WorkflowPreviewView, error) {
+	if ctx == nil {
+		return WorkflowPreviewView{}, logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return WorkflowPreviewView{}, errors.New("workflow_preview_repository is not configured")
+	}
+
+	query.WorkflowID = strings.TrimSpace(query.WorkflowID)
+	if query.UserID == 0 {
+		return WorkflowPreviewView{}, fmt.Errorf("%w: user_id is required", ErrInvalidCommand)
+	}
+	if query.WorkflowID == "" {
+		return WorkflowPreviewView{}, fmt.Errorf("%w: workflow_id is required", ErrInvalidCommand)
+	}
+
+	view, err := uc.repository.FindPreview(ctx, query.UserID, query.WorkflowID)
+	if err != nil {
+		return WorkflowPreviewView{}, err
+	}
+	if !view.DryRun {
+		return WorkflowPreviewView{}, ErrWorkflowNotDryRun
+	}
+	if view.FanOut {
+		return WorkflowPreviewView{}, ErrWorkflowFanOutParent
+	}
+	if view.Preview == nil {
+		return WorkflowPreviewView{}, fmt.Errorf("%w: status=%s", ErrWorkflowPreviewNotReady, view.Status)
+	}
+
+	return view, nil
+}
+
+// buildWorkflowPreview は dry-run の billingeligibility までの結果から、通常実行した場合の billing stage の結果を組み立てる。
+// 既存の請求は読み取りだけで照合し、同じ実行内で請求番号が重なるものも billing stage と同じく重複として扱う。
+func (uc *useCase) buildWorkflowPreview(ctx context.Context, userID uint, result Result) (WorkflowPreview, error) {
+	preview := WorkflowPreview{GeneratedAt: uc.clock.Now().UTC()}
+
+	identities := make([]BillingIdentity, 0, len(result.BillingEligibility.EligibleItems))
+	for _, item := range result.BillingEligibility.EligibleItems {
+		if item.VendorID != 0 {
+			identities = append(identities, billingIdentityFor(item))
+		}
+	}
+	existingIDs := map[BillingIdentity]uint{}
+	if len(identities) > 0 {
+		found, err := uc.previewRepository.FindExistingBillingIDs(ctx, userID, identities)
+		if err != nil {
+			return WorkflowPreview{}, err
+		}
+		existingIDs = found
+	}
+
+	// 自動登録予定の vendor は ID がないため、同じ実行内の重複は vendor 名で判定する。
+	seenInRun := make(map[string]struct{}, len(result.BillingEligibility.EligibleItems))
+	for _, item := range result.BillingEligibility.EligibleItems {
+		billing := newPreviewBillingItem(item)
+		identity := billingIdentityFor(item)
+		if existingID, found := existingIDs[identity]; found && item.VendorID != 0 {
+			preview.DuplicateItems = append(preview.DuplicateItems, newPreviewDuplicateItem(billing, existingID))
+			continue
+		}
+		runKey := previewRunKey(item, identity)
+		if _, seen := seenInRun[runKey]; seen {
+			preview.DuplicateItems = append(preview.DuplicateItems, newPreviewDuplicateItem(billing, 0))
+			continue
+		}
+		seenInRun[runKey] = struct{}{}
+		preview.WouldCreateItems = append(preview.WouldCreateItems, billing)
+	}
+
+	for _, item := range result.VendorResolution.UnresolvedItems {
+		preview.UnresolvedItems = append(preview.UnresolvedItems, PreviewUnresolvedItem{
+			ExternalMessageID:   item.ExternalMessageID,
+			CandidateVendorName: item.CandidateVendorName,
+			ReasonCode:          item.ReasonCode,
+			Message:             stageMessageOrFallback(item.Message, messageForVendorResolutionFailure(item.ReasonCode)),
+		})
+	}
+	for _, item := range result.BillingEligibility.IneligibleItems {
+		preview.IneligibleItems = append(preview.IneligibleItems, PreviewIneligibleItem{
+			ExternalMessageID: item.ExternalMessageID,
+			VendorName:        item.VendorName,
+			ReasonCode:        item.ReasonCode,
+			Message:           stageMessageOrFallback(item.Message, messageForBillingEligibilityReason(item.ReasonCode)),
+		})
+	}
+
+	return preview, nil
+}
+
+func newPreviewBillingItem(item EligibleItem) PreviewBillingItem {
+	return PreviewBillingItem{
+		ExternalMessageID:   item.ExternalMessageID,
+		VendorID:            item.VendorID,
+		VendorName:          item.VendorName,
+		VendorWouldRegister: item.VendorID == 0,
+		MatchedBy:           item.MatchedBy,
+		ProductNameDisplay:  item.ProductNameDisplay,
+		BillingNumber:       billingIdentityFor(item).BillingNumber,
+		InvoiceNumber:       item.InvoiceNumber,
+		Amount:              item.Amount,
+		Currency:            item.Currency,
+		BillingDate:         item.BillingDate,
+		PaymentCycle:        item.PaymentCycle,
+	}
+}
+
+func newPreviewDuplicateItem(billing PreviewBillingItem, existingBillingID uint) PreviewDuplicateItem {
+	return PreviewDuplicateItem{
+		PreviewBillingItem: billing,
+		ExistingBillingID:  existingBillingID,
+		ReasonCode:         reasonCodeDuplicateBilling,
+		Message:            messageForPreviewDuplicate(billing, existingBillingID),
+	}
+}
+
+// billingIdentityFor は billing repository と同じ正規化で請求番号を比較キーにする。
+func billingIdentityFor(item EligibleItem) BillingIdentity {
+	billingNumber, err := commondomain.NewBillingNumber(item.BillingNumber)
+	if err != nil {
+		return BillingIdentity{VendorID: item.VendorID, BillingNumber: strings.TrimSpace(item.BillingNumber)}
+	}
+	return BillingIdentity{VendorID: item.VendorID, BillingNumber: billingNumber.String()}
+}
+
+func previewRunKey(item EligibleItem, identity BillingIdentity) string {
+	if item.VendorID != 0 {
+		return fmt.Sprintf("id:%d/%s", item.VendorID, identity.BillingNumber)
+	}
+	return "name:" + commondomain.NormalizeLooseText(item.VendorName) + "/" + identity.BillingNumber
+}
+
+func messageForPreviewDuplicate(billing PreviewBillingItem, existingBillingID uint) string {
+	if existingBillingID != 0 {
+		return fmt.Sprintf("%s の請求番号「%s」は登録済みの請求(ID %d)と重複するため、請求は作成されません。",
+			externalMessageIDText(billing.ExternalMessageID), billing.BillingNumber, existingBillingID)
+	}
+	return fmt.Sprintf("%s の請求番号「%s」は同じ実行内の別のメールと重複するため、請求は作成されません。",
+		externalMessageIDText(billing.ExternalMessageID), billing.BillingNumber)
+}
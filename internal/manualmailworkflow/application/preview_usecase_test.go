@@ -0,0 +1,250 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubWorkflowPreviewRepository struct {
+	findExistingBillingIDs func(ctx context.Context, userID uint, identities []BillingIdentity) (map[BillingIdentity]uint, error)
+	savePreview            func(ctx context.Context, historyID uint64, preview WorkflowPreview) error
+	findPreview            func(ctx context.Context, userID uint, workflowID string) (WorkflowPreviewView, error)
+}
+
+func (s *stubWorkflowPreviewRepository) FindExistingBillingIDs(ctx context.Context, userID uint, identities []BillingIdentity) (map[BillingIdentity]uint, error) {
+	if s.findExistingBillingIDs == nil {
+		return map[BillingIdentity]uint{}, nil
+	}
+	return s.findExistingBillingIDs(ctx, userID, identities)
+}
+
+func (s *stubWorkflowPreviewRepository) SavePreview(ctx context.Context, historyID uint64, preview WorkflowPreview) error {
+	if s.savePreview == nil {
+		return nil
+	}
+	return s.savePreview(ctx, historyID, preview)
+}
+
+func (s *stubWorkflowPreviewRepository) FindPreview(ctx context.Context, userID uint, workflowID string) (WorkflowPreviewView, error) {
+	return s.findPreview(ctx, userID, workflowID)
+}
+
+func TestPreviewUseCase_Get(t *testing.T) {
+	t.Parallel()
+
+	preview := &WorkflowPreview{GeneratedAt: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name    string
+		query   PreviewQuery
+		view    WorkflowPreviewView
+		findErr error
+		wantErr error
+	}{
+		{
+			name:  "completed dry run",
+			query: PreviewQuery{UserID: 7, WorkflowID: " wf-1 "},
+			view:  WorkflowPreviewView{WorkflowID: "wf-1", Status: WorkflowStatusSucceeded, DryRun: true, Preview: preview},
+		},
+		{
+			name:    "missing workflow id",
+			query:   PreviewQuery{UserID: 7, WorkflowID: " "},
+			wantErr: ErrInvalidCommand,
+		},
+		{
+			name:    "not found",
+			query:   PreviewQuery{UserID: 7, WorkflowID: "wf-1"},
+			findErr: ErrWorkflowHistoryNotFound,
+			wantErr: ErrWorkflowHistoryNotFound,
+		},
+		{
+			name:    "normal run",
+			query:   PreviewQuery{UserID: 7, WorkflowID: "wf-1"},
+			view:    WorkflowPreviewView{WorkflowID: "wf-1", Status: WorkflowStatusSucceeded},
+			wantErr: ErrWorkflowNotDryRun,
+		},
+		{
+			name:    "fan-out parent",
+			query:   PreviewQuery{UserID: 7, WorkflowID: "wf-1"},
+			view:    WorkflowPreviewView{WorkflowID: "wf-1", Status: WorkflowStatusSucceeded, DryRun: true, FanOut: true},
+			wantErr: ErrWorkflowFanOutParent,
+		},
+		{
+			name:    "still running",
+			query:   PreviewQuery{UserID: 7, WorkflowID: "wf-1"},
+			view:    WorkflowPreviewView{WorkflowID: "wf-1", Status: WorkflowStatusRunning, DryRun: true},
+			wantErr: ErrWorkflowPreviewNotReady,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewPreviewUseCase(&stubWorkflowPreviewRepository{
+				findPreview: func(ctx context.Context, userID uint, workflowID string) (WorkflowPreviewView, error) {
+					if userID != 7 || workflowID != "wf-1" {
+						t.Fatalf("unexpected preview lookup: user_id=%d workflow_id=%q", userID, workflowID)
+					}
+					return tt.view, tt.findErr
+				},
+			}, logger.NewNop())
+
+			view, err := uc.Get(context.Background(), tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && view.Preview != preview {
+				t.Fatalf("unexpected view: %+v", view)
+			}
+		})
+	}
+}
+
+func TestUseCaseExecute_DryRunSavesPreviewWithoutBilling(t *testing.T) {
+	t.Parallel()
+
+	var savedPreview WorkflowPreview
+	var completedStatus string
+	var lookedUp []BillingIdentity
+
+	uc := NewUseCase(
+		&stubFetchStage{
+			execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+				if !cmd.DryRun {
+					t.Fatal("fetch stage must run as a dry run")
+				}
+				return FetchResult{CreatedEmails: []CreatedEmail{
+					{ExternalMessageID: "msg-1", Body: "body-1"},
+					{ExternalMessageID: "msg-2", Body: "body-2"},
+					{ExternalMessageID: "msg-3", Body: "body-3"},
+					{ExternalMessageID: "msg-4", Body: "body-4"},
+				}}, nil
+			},
+		},
+		&stubAnalyzeStage{
+			execute: func(ctx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+				if !cmd.DryRun {
+					t.Fatal("analysis stage must run as a dry run")
+				}
+				parsedEmails := make([]ParsedEmail, 0, len(cmd.Emails))
+				for _, email := range cmd.Emails {
+					parsedEmails = append(parsedEmails, ParsedEmail{ExternalMessageID: email.ExternalMessageID})
+				}
+				return AnalyzeResult{ParsedEmails: parsedEmails, ParsedEmailCount: len(parsedEmails)}, nil
+			},
+		},
+		&stubVendorResolutionStage{
+			execute: func(ctx context.Context, cmd VendorResolutionCommand) (VendorResolutionResult, error) {
+				if !cmd.DryRun {
+					t.Fatal("vendor resolution stage must run as a dry run")
+				}
+				return VendorResolutionResult{
+					ResolvedItems: []ResolvedItem{
+						{ExternalMessageID: "msg-1", VendorID: 5, VendorName: "AWS"},
+						{ExternalMessageID: "msg-2", VendorName: "New Vendor"},
+						{ExternalMessageID: "msg-3", VendorName: "new vendor"},
+					},
+					ResolvedCount: 3,
+					UnresolvedItems: []UnresolvedItem{
+						{ExternalMessageID: "msg-4", ReasonCode: "vendor_unresolved", CandidateVendorName: "Unknown"},
+					},
+					UnresolvedCount: 1,
+				}, nil
+			},
+		},
+		&stubBillingEligibilityStage{
+			execute: func(ctx context.Context, cmd BillingEligibilityCommand) (BillingEligibilityResult, error) {
+				if !cmd.DryRun {
+					t.Fatal("billing eligibility stage must run as a dry run")
+				}
+				return BillingEligibilityResult{
+					EligibleItems: []EligibleItem{
+						{ExternalMessageID: "msg-1", VendorID: 5, VendorName: "AWS", BillingNumber: " INV-1 ", Amount: 100, Currency: "USD"},
+						{ExternalMessageID: "msg-2", VendorName: "New Vendor", BillingNumber: "INV-2", Amount: 200, Currency: "JPY"},
+						{ExternalMessageID: "msg-3", VendorName: "new vendor", BillingNumber: "INV-2", Amount: 200, Currency: "JPY"},
+					},
+					EligibleCount: 3,
+				}, nil
+			},
+		},
+		&stubBillingStage{
+			execute: func(ctx context.Context, cmd BillingCommand) (BillingResult, error) {
+				t.Fatal("billing stage must not run for a dry run")
+				return BillingResult{}, nil
+			},
+		},
+		&stubWorkflowStatusRepository{
+			saveStage: func(ctx context.Context, progress StageProgress) error {
+				if progress.Handoff != nil {
+					t.Fatalf("dry run must not save a stage handoff: %+v", progress.Handoff)
+				}
+				return nil
+			},
+			complete: func(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error {
+				completedStatus = status
+				return nil
+			},
+		},
+		&stubWorkflowRetryRepository{},
+		&stubWorkflowResumeRepository{},
+		&stubWorkflowPreviewRepository{
+			findExistingBillingIDs: func(ctx context.Context, userID uint, identities []BillingIdentity) (map[BillingIdentity]uint, error) {
+				lookedUp = identities
+				return map[BillingIdentity]uint{{VendorID: 5, BillingNumber: "INV-1"}: 42}, nil
+			},
+			savePreview: func(ctx context.Context, historyID uint64, preview WorkflowPreview) error {
+				if historyID != 9 {
+					t.Fatalf("unexpected history id: %d", historyID)
+				}
+				savedPreview = preview
+				return nil
+			},
+		},
+		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
+		logger.NewNop(),
+	)
+
+	result, err := uc.Execute(context.Background(), DispatchJob{
+		HistoryID:    9,
+		WorkflowID:   "wf-dry-run",
+		UserID:       7,
+		ConnectionID: 12,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Preview == nil {
+		t.Fatal("expected the preview on the result")
+	}
+	if len(lookedUp) != 1 || lookedUp[0] != (BillingIdentity{VendorID: 5, BillingNumber: "INV-1"}) {
+		t.Fatalf("only billings of existing vendors must be looked up: %+v", lookedUp)
+	}
+	if len(savedPreview.WouldCreateItems) != 1 || savedPreview.WouldCreateItems[0].ExternalMessageID != "msg-2" || !savedPreview.WouldCreateItems[0].VendorWouldRegister {
+		t.Fatalf("unexpected would-create items: %+v", savedPreview.WouldCreateItems)
+	}
+	if len(savedPreview.DuplicateItems) != 2 {
+		t.Fatalf("expected 2 duplicates, got %+v", savedPreview.DuplicateItems)
+	}
+	if savedPreview.DuplicateItems[0].ExternalMessageID != "msg-1" || savedPreview.DuplicateItems[0].ExistingBillingID != 42 {
+		t.Fatalf("unexpected existing duplicate: %+v", savedPreview.DuplicateItems[0])
+	}
+	if savedPreview.DuplicateItems[1].ExternalMessageID != "msg-3" || savedPreview.DuplicateItems[1].ExistingBillingID != 0 {
+		t.Fatalf("unexpected in-run duplicate: %+v", savedPreview.DuplicateItems[1])
+	}
+	if len(savedPreview.UnresolvedItems) != 1 || savedPreview.UnresolvedItems[0].CandidateVendorName != "Unknown" {
+		t.Fatalf("unexpected unresolved items: %+v", savedPreview.UnresolvedItems)
+	}
+	if completedStatus != WorkflowStatusPartialSuccess {
+		t.Fatalf("expected partial_success, got %q", completedStatus)
+	}
+}
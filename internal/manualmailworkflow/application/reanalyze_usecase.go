@@ -0,0 +1,661 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxReanalysisEmails は 1 回の再解析で対象にできるメール数の上限。
+	// 再解析はリクエストの中で OpenAI を呼ぶため、workflow より小さく抑える。
+	MaxReanalysisEmails = 50
+
+	// ReanalysisBillingNone は再解析の結果を parsed_emails に追加するだけで、請求には反映しない。
+	ReanalysisBillingNone = "none"
+	// ReanalysisBillingPreview は支払先解決と請求判定を dry-run で実行し、登録済みの請求との差分だけを返す。
+	ReanalysisBillingPreview = "preview"
+	// ReanalysisBillingApply は請求の作成まで実行し、登録済みの請求との差分を返す。
+	ReanalysisBillingApply = "apply"
+
+	// ReanalysisChangeAdded は登録済みの請求に無い請求が見つかったことを表す。
+	ReanalysisChangeAdded = "added"
+	// ReanalysisChangeChanged は同じ支払先・請求番号の請求の内容が変わったことを表す。
+	ReanalysisChangeChanged = "changed"
+	// ReanalysisChangeUnchanged は登録済みの請求と同じ内容だったことを表す。
+	ReanalysisChangeUnchanged = "unchanged"
+	// ReanalysisChangeMissing は登録済みの請求が再解析では見つからなかったことを表す。
+	ReanalysisChangeMissing = "missing"
+
+	reasonCodeEmailBodyUnavailable = "email_body_unavailable"
+
+	// reanalysisRefetchLabelName は本文を取得し直すときの取得条件のラベル。
+	// MessageIDs を指定した Gmail / Outlook の取得はラベルを使わないが、取得条件として必須のため固定値を渡す。
+	reanalysisRefetchLabelName = "INBOX"
+	// reanalysisRefetchMargin は取得し直すメールの受信日時の前後に取る取得期間の余白。
+	reanalysisRefetchMargin = 24 * time.Hour
+	// reanalysisAmountTolerance は金額を同じとみなす差。金額は小数 3 桁で保存する。
+	reanalysisAmountTolerance = 0.0005
+)
+
+var (
+	// ErrReanalysisTooManyEmails は対象のメールが MaxReanalysisEmails を超えたときに返る。
+	ErrReanalysisTooManyEmails = errors.New("too many emails to reanalyze")
+
+	// reanalysisRefetchProviders はメッセージ ID だけで本文を取得し直せる provider。
+	// IMAP はフォルダごとの UID、file はアップロード時のラベルが必要なため取得し直さない。
+	reanalysisRefetchProviders = []string{"gmail", "outlook"}
+)
+
+// ReanalyzeCommand は保存済みメールの再解析の入力。EmailIDs・受信期間・VendorID はすべて AND 条件で、1 つ以上指定する。
+type ReanalyzeCommand struct {
+	UserID   uint
+	EmailIDs []uint
+	// ReceivedSince 以上 ReceivedUntil 未満に受信したメールを対象にする。片方だけでもよい。
+	ReceivedSince time.Time
+	ReceivedUntil time.Time
+	// VendorID はその支払先の請求が登録済みのメールを対象にする。
+	VendorID uint
+	// BillingMode は ReanalysisBillingNone（省略時）/ ReanalysisBillingPreview / ReanalysisBillingApply。
+	BillingMode string
+}
+
+// ReanalysisTargetQuery は再解析するメールの検索条件。
+type ReanalysisTargetQuery struct {
+	UserID        uint
+	EmailIDs      []uint
+	ReceivedSince time.Time
+	ReceivedUntil time.Time
+	VendorID      uint
+	Limit         int
+}
+
+// ReanalysisTarget は再解析する保存済みメールのメタデータ。
+type ReanalysisTarget struct {
+	EmailID           uint
+	ExternalMessageID string
+	Subject           string
+	From              string
+	To                []string
+	ReceivedAt        time.Time
+	BodyDigest        string
+	Provider          string
+	AccountIdentifier string
+}
+
+// StoredEmailBody は保存済みのメール本文と添付ファイルのテキスト。
+type StoredEmailBody struct {
+	EmailID     uint
+	Body        string
+	Attachments []EmailAttachment
+}
+
+// ExistingBilling は再解析の結果と比較する登録済みの請求。
+// Amount は明細の通貨が 1 種類のときだけ合計が入る。
+type ExistingBilling struct {
+	BillingID          uint
+	EmailID            uint
+	VendorID           uint
+	VendorName         string
+	ProductNameDisplay *string
+	BillingNumber      string
+	InvoiceNumber      *string
+	Amount             *float64
+	Currency           string
+	BillingDate        *time.Time
+	PaymentCycle       string
+}
+
+// ReanalysisRepository は再解析の対象と、比較する登録済みの請求を読み取る。
+type ReanalysisRepository interface {
+	// FindReanalysisTargets は受信日時の新しい順に最大 query.Limit 件を返す。
+	FindReanalysisTargets(ctx context.Context, query ReanalysisTargetQuery) ([]ReanalysisTarget, error)
+	// FindConnectionID は取得元 mailbox のメール連携の ID を返す。連携が無ければ 0 を返す。
+	FindConnectionID(ctx context.Context, userID uint, provider, accountIdentifier string) (uint, error)
+	FindBillingsByEmailIDs(ctx context.Context, userID uint, emailIDs []uint) ([]ExistingBilling, error)
+}
+
+// ReanalysisBodyReader は保存済みのメール本文を読む。本文が保存されていないメールは返さない。
+type ReanalysisBodyReader interface {
+	GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]StoredEmailBody, error)
+}
+
+// ReanalysisSkippedEmail は本文が無いため再解析しなかったメール。
+type ReanalysisSkippedEmail struct {
+	EmailID           uint
+	ExternalMessageID string
+	ReasonCode        string
+	Message           string
+}
+
+// ReanalysisBillingChange は再解析の結果と登録済みの請求の 1 件分の差分。
+type ReanalysisBillingChange struct {
+	Change            string
+	EmailID           uint
+	ExternalMessageID string
+	// Existing は比較した登録済みの請求。added では nil。
+	Existing *ExistingBilling
+	// Reanalyzed は再解析で請求成立と判定された item。missing では nil。
+	Reanalyzed *EligibleItem
+	// ChangedFields は changed のときに値が変わった項目名。
+	ChangedFields []string
+	// CreatedBillingID は apply で作成した請求の ID。
+	CreatedBillingID uint
+}
+
+// ReanalyzeResult は再解析の結果。BillingMode に応じて実行しなかった stage の結果は空のままになる。
+type ReanalyzeResult struct {
+	TargetCount int
+	// RefetchedCount は本文が保存されていなかったため provider から取得し直したメールの数。
+	RefetchedCount     int
+	Skipped            []ReanalysisSkippedEmail
+	Analysis           AnalyzeResult
+	VendorResolution   VendorResolutionResult
+	BillingEligibility BillingEligibilityResult
+	Billing            BillingResult
+	BillingChanges     []ReanalysisBillingChange
+}
+
+// ReanalyzeUseCase は保存済みメールを現在のプロンプトで解析し直し、新しい解析 run として parsed_emails に追加する。
+type ReanalyzeUseCase interface {
+	Reanalyze(ctx context.Context, cmd ReanalyzeCommand) (ReanalyzeResult, error)
+}
+
+type reanalyzeUseCase struct {
+	repository ReanalysisRepository
+	bodies     ReanalysisBodyReader
+	stages     BuiltinStages
+	clock      timewrapper.ClockInterface
+	log        logger.Interface
+}
+
+// NewReanalyzeUseCase は再解析の usecase を生成する。
+// stages の Fetch は本文が保存されていないメールを取得し直すときだけ使う。bodies が nil のときはすべて取得し直す。
+func NewReanalyzeUseCase(
+	repository ReanalysisRepository,
+	bodies ReanalysisBodyReader,
+	stages BuiltinStages,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) ReanalyzeUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &reanalyzeUseCase{
+		repository: repository,
+		bodies:     bodies,
+		stages:     stages,
+		clock:      clock,
+		log:        log.With(logger.Component("manual_mail_workflow_reanalyze_usecase")),
+	}
+}
+
+// Reanalyze は対象のメールの本文を集めて解析し直し、BillingMode に応じて請求まで流して差分を返す。
+func (uc *reanalyzeUseCase) Reanalyze(ctx context.Context, cmd ReanalyzeCommand) (ReanalyzeResult, error) {
+	if ctx == nil {
+		return ReanalyzeResult{}, logger.ErrNilContext
+	}
+	cmd, err := normalizeReanalyzeCommand(cmd)
+	if err != nil {
+		return ReanalyzeResult{}, err
+	}
+	if err := uc.validateDependencies(cmd.BillingMode); err != nil {
+		return ReanalyzeResult{}, err
+	}
+
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+
+	// 上限を 1 件超えて読み、超えた場合は一部だけ解析せずに拒否する。
+	targets, err := uc.repository.FindReanalysisTargets(ctx, ReanalysisTargetQuery{
+		UserID:        cmd.UserID,
+		EmailIDs:      cmd.EmailIDs,
+		ReceivedSince: cmd.ReceivedSince,
+		ReceivedUntil: cmd.ReceivedUntil,
+		VendorID:      cmd.VendorID,
+		Limit:         MaxReanalysisEmails + 1,
+	})
+	if err != nil {
+		return ReanalyzeResult{}, err
+	}
+	if len(targets) > MaxReanalysisEmails {
+		return ReanalyzeResult{}, fmt.Errorf("%w: up to %d emails can be reanalyzed at once", ErrReanalysisTooManyEmails, MaxReanalysisEmails)
+	}
+
+	result := ReanalyzeResult{TargetCount: len(targets)}
+	if len(targets) == 0 {
+		return result, nil
+	}
+
+	emails, refetchedCount, skipped, err := uc.loadEmails(ctx, cmd.UserID, targets, reqLog)
+	if err != nil {
+		return ReanalyzeResult{}, err
+	}
+	result.RefetchedCount = refetchedCount
+	result.Skipped = skipped
+
+	if len(emails) > 0 {
+		result.Analysis, err = uc.stages.Analyze.Execute(ctx, AnalyzeCommand{UserID: cmd.UserID, Emails: emails})
+		if err != nil {
+			return ReanalyzeResult{}, err
+		}
+	}
+
+	if cmd.BillingMode != ReanalysisBillingNone && len(result.Analysis.ParsedEmails) > 0 {
+		if err := uc.runBillingStages(ctx, cmd, &result); err != nil {
+			return ReanalyzeResult{}, err
+		}
+		analyzedEmailIDs := make([]uint, 0, len(emails))
+		for _, email := range emails {
+			analyzedEmailIDs = append(analyzedEmailIDs, email.EmailID)
+		}
+		existing, err := uc.repository.FindBillingsByEmailIDs(ctx, cmd.UserID, analyzedEmailIDs)
+		if err != nil {
+			return ReanalyzeResult{}, err
+		}
+		result.BillingChanges = compareReanalyzedBillings(emails, existing, result.BillingEligibility.EligibleItems, result.Billing)
+	}
+
+	reqLog.Info("manual_mail_workflow_reanalysis_completed",
+		logger.UserID(cmd.UserID),
+		logger.String("billing_mode", cmd.BillingMode),
+		logger.Int("target_count", result.TargetCount),
+		logger.Int("refetched_count", result.RefetchedCount),
+		logger.Int("skipped_count", len(result.Skipped)),
+		logger.Int("parsed_email_count", result.Analysis.ParsedEmailCount),
+		logger.Int("billing_change_count", len(result.BillingChanges)),
+	)
+
+	return result, nil
+}
+
+// runBillingStages は解析結果を支払先解決・請求判定に流し、apply のときだけ請求を作成する。
+// preview では vendor の自動登録も行わない。
+func (uc *reanalyzeUseCase) runBillingStages(ctx context.Context, cmd ReanalyzeCommand, result *ReanalyzeResult) error {
+	dryRun := cmd.BillingMode == ReanalysisBillingPreview
+
+	vendorResolution, err := uc.stages.VendorResolution.Execute(ctx, VendorResolutionCommand{
+		UserID:       cmd.UserID,
+		ParsedEmails: append([]ParsedEmail(nil), result.Analysis.ParsedEmails...),
+		DryRun:       dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	result.VendorResolution = vendorResolution
+	if len(vendorResolution.ResolvedItems) == 0 {
+		return nil
+	}
+
+	eligibility, err := uc.stages.BillingEligibility.Execute(ctx, BillingEligibilityCommand{
+		UserID:        cmd.UserID,
+		ResolvedItems: vendorResolution.ResolvedItems,
+		DryRun:        dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	result.BillingEligibility = eligibility
+	if dryRun || len(eligibility.EligibleItems) == 0 {
+		return nil
+	}
+
+	billing, err := uc.stages.Billing.Execute(ctx, BillingCommand{
+		UserID:        cmd.UserID,
+		EligibleItems: eligibility.EligibleItems,
+	})
+	if err != nil {
+		return err
+	}
+	result.Billing = billing
+	return nil
+}
+
+// loadEmails は保存済みの本文を読み、無いメールは取得元のメール連携から取得し直して analysis の入力にする。
+// 取得し直せなかったメールは skipped として返し、他のメールの再解析は続ける。
+func (uc *reanalyzeUseCase) loadEmails(
+	ctx context.Context,
+	userID uint,
+	targets []ReanalysisTarget,
+	reqLog logger.Interface,
+) ([]CreatedEmail, int, []ReanalysisSkippedEmail, error) {
+	targetIDs := make([]uint, 0, len(targets))
+	for _, target := range targets {
+		targetIDs = append(targetIDs, target.EmailID)
+	}
+
+	bodies := make(map[uint]StoredEmailBody, len(targets))
+	if uc.bodies != nil {
+		stored, err := uc.bodies.GetBodies(ctx, userID, targetIDs)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		for _, body := range stored {
+			bodies[body.EmailID] = body
+		}
+	}
+
+	var missing []ReanalysisTarget
+	for _, target := range targets {
+		if _, ok := bodies[target.EmailID]; !ok {
+			missing = append(missing, target)
+		}
+	}
+	refetched, skipped := uc.refetchBodies(ctx, userID, missing, reqLog)
+	for emailID, body := range refetched {
+		bodies[emailID] = body
+	}
+
+	emails := make([]CreatedEmail, 0, len(targets))
+	for _, target := range targets {
+		body, ok := bodies[target.EmailID]
+		if !ok {
+			continue
+		}
+		emails = append(emails, CreatedEmail{
+			EmailID:           target.EmailID,
+			ExternalMessageID: target.ExternalMessageID,
+			Subject:           target.Subject,
+			From:              target.From,
+			To:                append([]string(nil), target.To...),
+			ReceivedAt:        target.ReceivedAt,
+			Body:              body.Body,
+			BodyDigest:        target.BodyDigest,
+			Attachments:       append([]EmailAttachment(nil), body.Attachments...),
+		})
+	}
+	return emails, len(refetched), skipped, nil
+}
+
+// refetchBodies は取得元の mailbox ごとに、メッセージ ID を指定して fetch stage で本文を取得し直す。
+// 取得済みメールとして返るため emails は増えず、本文の保存が有効ならその本文も保存し直される。
+func (uc *reanalyzeUseCase) refetchBodies(
+	ctx context.Context,
+	userID uint,
+	targets []ReanalysisTarget,
+	reqLog logger.Interface,
+) (map[uint]StoredEmailBody, []ReanalysisSkippedEmail) {
+	refetched := make(map[uint]StoredEmailBody, len(targets))
+	var skipped []ReanalysisSkippedEmail
+	if len(targets) == 0 {
+		return refetched, skipped
+	}
+
+	type mailbox struct {
+		provider          string
+		accountIdentifier string
+	}
+	var mailboxes []mailbox
+	groups := make(map[mailbox][]ReanalysisTarget)
+	for _, target := range targets {
+		key := mailbox{provider: strings.ToLower(strings.TrimSpace(target.Provider)), accountIdentifier: target.AccountIdentifier}
+		if _, ok := groups[key]; !ok {
+			mailboxes = append(mailboxes, key)
+		}
+		groups[key] = append(groups[key], target)
+	}
+
+	for _, key := range mailboxes {
+		group := groups[key]
+		if uc.stages.Fetch == nil || !slices.Contains(reanalysisRefetchProviders, key.provider) {
+			skipped = append(skipped, skippedForMissingBody(group)...)
+			continue
+		}
+
+		connectionID, err := uc.repository.FindConnectionID(ctx, userID, key.provider, key.accountIdentifier)
+		if err != nil || connectionID == 0 {
+			if err != nil {
+				reqLog.Warn("manual_mail_workflow_reanalysis_connection_lookup_failed",
+					logger.UserID(userID),
+					logger.String("provider", key.provider),
+					logger.Err(err),
+				)
+			}
+			skipped = append(skipped, skippedForMissingBody(group)...)
+			continue
+		}
+
+		messageIDs := make([]string, 0, len(group))
+		since, until := group[0].ReceivedAt, group[0].ReceivedAt
+		for _, target := range group {
+			messageIDs = append(messageIDs, target.ExternalMessageID)
+			if target.ReceivedAt.Before(since) {
+				since = target.ReceivedAt
+			}
+			if target.ReceivedAt.After(until) {
+				until = target.ReceivedAt
+			}
+		}
+
+		fetched, err := uc.stages.Fetch.Execute(ctx, FetchCommand{
+			UserID:       userID,
+			ConnectionID: connectionID,
+			Condition: FetchCondition{
+				LabelName: reanalysisRefetchLabelName,
+				Since:     since.UTC().Add(-reanalysisRefetchMargin),
+				Until:     until.UTC().Add(reanalysisRefetchMargin),
+			},
+			MessageIDs:            messageIDs,
+			IncludeExistingEmails: true,
+		})
+		if err != nil {
+			reqLog.Warn("manual_mail_workflow_reanalysis_refetch_failed",
+				logger.UserID(userID),
+				logger.Uint("connection_id", connectionID),
+				logger.Int("message_count", len(messageIDs)),
+				logger.Err(err),
+			)
+			skipped = append(skipped, skippedForMissingBody(group)...)
+			continue
+		}
+
+		byMessageID := make(map[string]CreatedEmail, len(fetched.CreatedEmails)+len(fetched.ExistingEmails))
+		for _, email := range append(append([]CreatedEmail(nil), fetched.CreatedEmails...), fetched.ExistingEmails...) {
+			byMessageID[email.ExternalMessageID] = email
+		}
+		for _, target := range group {
+			email, ok := byMessageID[target.ExternalMessageID]
+			if !ok {
+				skipped = append(skipped, skippedForMissingBody([]ReanalysisTarget{target})...)
+				continue
+			}
+			refetched[target.EmailID] = StoredEmailBody{
+				EmailID:     target.EmailID,
+				Body:        email.Body,
+				Attachments: email.Attachments,
+			}
+		}
+	}
+
+	return refetched, skipped
+}
+
+func skippedForMissingBody(targets []ReanalysisTarget) []ReanalysisSkippedEmail {
+	skipped := make([]ReanalysisSkippedEmail, 0, len(targets))
+	for _, target := range targets {
+		skipped = append(skipped, ReanalysisSkippedEmail{
+			EmailID:           target.EmailID,
+			ExternalMessageID: target.ExternalMessageID,
+			ReasonCode:        reasonCodeEmailBodyUnavailable,
+			Message: fmt.Sprintf("%s の本文は保存されておらず、メール連携から取得し直すこともできなかったため、再解析しませんでした。",
+				externalMessageIDText(target.ExternalMessageID)),
+		})
+	}
+	return skipped
+}
+
+// compareReanalyzedBillings は再解析で請求成立と判定された item を、同じメールの登録済みの請求と支払先・請求番号で突き合わせる。
+// 登録済みの請求は更新も削除もしないため、changed と missing は確認用の差分として返すだけになる。
+func compareReanalyzedBillings(emails []CreatedEmail, existing []ExistingBilling, eligibleItems []EligibleItem, billing BillingResult) []ReanalysisBillingChange {
+	existingByEmail := make(map[uint][]ExistingBilling, len(emails))
+	for _, item := range existing {
+		existingByEmail[item.EmailID] = append(existingByEmail[item.EmailID], item)
+	}
+	eligibleByEmail := make(map[uint][]EligibleItem, len(emails))
+	for _, item := range eligibleItems {
+		eligibleByEmail[item.EmailID] = append(eligibleByEmail[item.EmailID], item)
+	}
+	createdIDs := make(map[BillingIdentity]uint, len(billing.CreatedItems))
+	for _, item := range billing.CreatedItems {
+		createdIDs[billingIdentityFor(EligibleItem{VendorID: item.VendorID, BillingNumber: item.BillingNumber})] = item.BillingID
+	}
+
+	changes := make([]ReanalysisBillingChange, 0, len(existing)+len(eligibleItems))
+	for _, email := range emails {
+		emailID := email.EmailID
+		billings := existingByEmail[emailID]
+		matched := make([]bool, len(billings))
+		for _, item := range eligibleByEmail[emailID] {
+			item := item
+			identity := billingIdentityFor(item)
+			change := ReanalysisBillingChange{
+				Change:            ReanalysisChangeAdded,
+				EmailID:           emailID,
+				ExternalMessageID: item.ExternalMessageID,
+				Reanalyzed:        &item,
+			}
+			for idx := range billings {
+				if matched[idx] || item.VendorID == 0 || billings[idx].VendorID != identity.VendorID || billings[idx].BillingNumber != identity.BillingNumber {
+					continue
+				}
+				matched[idx] = true
+				existingBilling := billings[idx]
+				change.Existing = &existingBilling
+				change.ChangedFields = changedBillingFields(existingBilling, item)
+				change.Change = ReanalysisChangeUnchanged
+				if len(change.ChangedFields) > 0 {
+					change.Change = ReanalysisChangeChanged
+				}
+				break
+			}
+			if change.Change == ReanalysisChangeAdded {
+				change.CreatedBillingID = createdIDs[identity]
+			}
+			changes = append(changes, change)
+		}
+		for idx := range billings {
+			if matched[idx] {
+				continue
+			}
+			existingBilling := billings[idx]
+			changes = append(changes, ReanalysisBillingChange{
+				Change:            ReanalysisChangeMissing,
+				EmailID:           emailID,
+				ExternalMessageID: email.ExternalMessageID,
+				Existing:          &existingBilling,
+			})
+		}
+	}
+	return changes
+}
+
+// changedBillingFields は登録済みの請求と再解析の item で値が異なる項目名を返す。
+func changedBillingFields(existing ExistingBilling, item EligibleItem) []string {
+	var fields []string
+	if existing.Amount == nil || math.Abs(*existing.Amount-item.Amount) > reanalysisAmountTolerance {
+		fields = append(fields, "amount")
+	}
+	if !strings.EqualFold(strings.TrimSpace(existing.Currency), strings.TrimSpace(item.Currency)) {
+		fields = append(fields, "currency")
+	}
+	if !sameOptionalTime(existing.BillingDate, item.BillingDate) {
+		fields = append(fields, "billing_date")
+	}
+	if existing.PaymentCycle != item.PaymentCycle {
+		fields = append(fields, "payment_cycle")
+	}
+	if !sameOptionalString(existing.ProductNameDisplay, item.ProductNameDisplay) {
+		fields = append(fields, "product_name_display")
+	}
+	if !sameOptionalString(existing.InvoiceNumber, item.InvoiceNumber) {
+		fields = append(fields, "invoice_number")
+	}
+	return fields
+}
+
+func sameOptionalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func sameOptionalString(a, b *string) bool {
+	left, right := "", ""
+	if a != nil {
+		left = strings.TrimSpace(*a)
+	}
+	if b != nil {
+		right = strings.TrimSpace(*b)
+	}
+	return left == right
+}
+
+func (uc *reanalyzeUseCase) validateDependencies(billingMode string) error {
+	if uc.repository == nil {
+		return errors.New("reanalysis_repository is not configured")
+	}
+	if uc.stages.Analyze == nil {
+		return errors.New("analyze_stage is not configured")
+	}
+	if billingMode == ReanalysisBillingNone {
+		return nil
+	}
+	if uc.stages.VendorResolution == nil || uc.stages.BillingEligibility == nil {
+		return errors.New("vendor_resolution_stage and billing_eligibility_stage are not configured")
+	}
+	if billingMode == ReanalysisBillingApply && uc.stages.Billing == nil {
+		return errors.New("billing_stage is not configured")
+	}
+	return nil
+}
+
+// normalizeReanalyzeCommand は EmailIDs の重複を除き、BillingMode の省略を none にしてから検証する。
+func normalizeReanalyzeCommand(cmd ReanalyzeCommand) (ReanalyzeCommand, error) {
+	if cmd.UserID == 0 {
+		return ReanalyzeCommand{}, fmt.Errorf("%w: user_id is required", ErrInvalidCommand)
+	}
+
+	emailIDs := make([]uint, 0, len(cmd.EmailIDs))
+	for _, emailID := range cmd.EmailIDs {
+		if emailID == 0 {
+			return ReanalyzeCommand{}, fmt.Errorf("%w: email_ids must be positive", ErrInvalidCommand)
+		}
+		if !slices.Contains(emailIDs, emailID) {
+			emailIDs = append(emailIDs, emailID)
+		}
+	}
+	if len(emailIDs) > MaxReanalysisEmails {
+		return ReanalyzeCommand{}, fmt.Errorf("%w: up to %d emails can be reanalyzed at once", ErrReanalysisTooManyEmails, MaxReanalysisEmails)
+	}
+	cmd.EmailIDs = emailIDs
+
+	if len(cmd.EmailIDs) == 0 && cmd.ReceivedSince.IsZero() && cmd.ReceivedUntil.IsZero() && cmd.VendorID == 0 {
+		return ReanalyzeCommand{}, fmt.Errorf("%w: email_ids, received period or vendor_id is required", ErrInvalidCommand)
+	}
+	if !cmd.ReceivedSince.IsZero() && !cmd.ReceivedUntil.IsZero() && !cmd.ReceivedSince.Before(cmd.ReceivedUntil) {
+		return ReanalyzeCommand{}, fmt.Errorf("%w: received_since must be before received_until", ErrInvalidCommand)
+	}
+
+	cmd.BillingMode = strings.ToLower(strings.TrimSpace(cmd.BillingMode))
+	switch cmd.BillingMode {
+	case "":
+		cmd.BillingMode = ReanalysisBillingNone
+	case ReanalysisBillingNone, ReanalysisBillingPreview, ReanalysisBillingApply:
+	default:
+		return ReanalyzeCommand{}, fmt.Errorf("%w: billing_mode must be none, preview or apply", ErrInvalidCommand)
+	}
+	return cmd, nil
+}
@@ -0,0 +1,298 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stubReanalysisRepository struct {
+	findReanalysisTargets  func(ctx context.Context, query ReanalysisTargetQuery) ([]ReanalysisTarget, error)
+	findConnectionID       func(ctx context.Context, userID uint, provider, accountIdentifier string) (uint, error)
+	findBillingsByEmailIDs func(ctx context.Context, userID uint, emailIDs []uint) ([]ExistingBilling, error)
+}
+
+func (s *stubReanalysisRepository) FindReanalysisTargets(ctx context.Context, query ReanalysisTargetQuery) ([]ReanalysisTarget, error) {
+	return s.findReanalysisTargets(ctx, query)
+}
+
+func (s *stubReanalysisRepository) FindConnectionID(ctx context.Context, userID uint, provider, accountIdentifier string) (uint, error) {
+	if s.findConnectionID == nil {
+		return 0, nil
+	}
+	return s.findConnectionID(ctx, userID, provider, accountIdentifier)
+}
+
+func (s *stubReanalysisRepository) FindBillingsByEmailIDs(ctx context.Context, userID uint, emailIDs []uint) ([]ExistingBilling, error) {
+	if s.findBillingsByEmailIDs == nil {
+		return nil, nil
+	}
+	return s.findBillingsByEmailIDs(ctx, userID, emailIDs)
+}
+
+type stubReanalysisBodyReader struct {
+	getBodies func(ctx context.Context, userID uint, emailIDs []uint) ([]StoredEmailBody, error)
+}
+
+func (s *stubReanalysisBodyReader) GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]StoredEmailBody, error) {
+	return s.getBodies(ctx, userID, emailIDs)
+}
+
+func reanalysisAnalyzeStage(t *testing.T, analyzed *[]CreatedEmail) *stubAnalyzeStage {
+	t.Helper()
+
+	return &stubAnalyzeStage{
+		execute: func(ctx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+			if cmd.DryRun {
+				t.Fatal("reanalysis must save a new analysis run")
+			}
+			*analyzed = append(*analyzed, cmd.Emails...)
+			parsedEmails := make([]ParsedEmail, 0, len(cmd.Emails))
+			for idx, email := range cmd.Emails {
+				parsedEmails = append(parsedEmails, ParsedEmail{ParsedEmailID: uint(100 + idx), EmailID: email.EmailID, ExternalMessageID: email.ExternalMessageID})
+			}
+			return AnalyzeResult{ParsedEmails: parsedEmails, ParsedEmailCount: len(parsedEmails)}, nil
+		},
+	}
+}
+
+func TestReanalyzeUseCase_InvalidCommand(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	tooMany := make([]uint, 0, MaxReanalysisEmails+1)
+	for idx := 1; idx <= MaxReanalysisEmails+1; idx++ {
+		tooMany = append(tooMany, uint(idx))
+	}
+	tests := []struct {
+		name    string
+		cmd     ReanalyzeCommand
+		wantErr error
+	}{
+		{name: "missing user", cmd: ReanalyzeCommand{EmailIDs: []uint{1}}, wantErr: ErrInvalidCommand},
+		{name: "no selector", cmd: ReanalyzeCommand{UserID: 7}, wantErr: ErrInvalidCommand},
+		{name: "zero email id", cmd: ReanalyzeCommand{UserID: 7, EmailIDs: []uint{0}}, wantErr: ErrInvalidCommand},
+		{name: "inverted period", cmd: ReanalyzeCommand{UserID: 7, ReceivedSince: since, ReceivedUntil: since}, wantErr: ErrInvalidCommand},
+		{name: "unknown billing mode", cmd: ReanalyzeCommand{UserID: 7, VendorID: 3, BillingMode: "update"}, wantErr: ErrInvalidCommand},
+		{name: "too many email ids", cmd: ReanalyzeCommand{UserID: 7, EmailIDs: tooMany}, wantErr: ErrReanalysisTooManyEmails},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewReanalyzeUseCase(&stubReanalysisRepository{
+				findReanalysisTargets: func(ctx context.Context, query ReanalysisTargetQuery) ([]ReanalysisTarget, error) {
+					t.Fatal("targets must not be loaded for an invalid command")
+					return nil, nil
+				},
+			}, nil, BuiltinStages{Analyze: &stubAnalyzeStage{}}, nil, logger.NewNop())
+
+			_, err := uc.Reanalyze(context.Background(), tt.cmd)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReanalyzeUseCase_RejectsTooManyTargets(t *testing.T) {
+	t.Parallel()
+
+	uc := NewReanalyzeUseCase(&stubReanalysisRepository{
+		findReanalysisTargets: func(ctx context.Context, query ReanalysisTargetQuery) ([]ReanalysisTarget, error) {
+			if query.Limit != MaxReanalysisEmails+1 || query.VendorID != 3 {
+				t.Fatalf("unexpected query: %+v", query)
+			}
+			return make([]ReanalysisTarget, query.Limit), nil
+		},
+	}, nil, BuiltinStages{
+		Analyze: &stubAnalyzeStage{execute: func(ctx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+			t.Fatal("analysis must not run when targets exceed the limit")
+			return AnalyzeResult{}, nil
+		}},
+	}, nil, logger.NewNop())
+
+	_, err := uc.Reanalyze(context.Background(), ReanalyzeCommand{UserID: 7, VendorID: 3})
+	if !errors.Is(err, ErrReanalysisTooManyEmails) {
+		t.Fatalf("expected ErrReanalysisTooManyEmails, got %v", err)
+	}
+}
+
+func TestReanalyzeUseCase_UsesStoredBodiesAndRefetchesMissing(t *testing.T) {
+	t.Parallel()
+
+	receivedAt := time.Date(2026, 10, 10, 9, 0, 0, 0, time.UTC)
+	targets := []ReanalysisTarget{
+		{EmailID: 1, ExternalMessageID: "msg-1", Subject: "請求書", ReceivedAt: receivedAt, BodyDigest: "digest-1", Provider: "gmail", AccountIdentifier: "a@example.com"},
+		{EmailID: 2, ExternalMessageID: "msg-2", ReceivedAt: receivedAt.Add(-time.Hour), Provider: "gmail", AccountIdentifier: "a@example.com"},
+		{EmailID: 3, ExternalMessageID: "msg-3", ReceivedAt: receivedAt, Provider: "imap", AccountIdentifier: "b@example.com"},
+	}
+
+	var fetchCmd FetchCommand
+	var analyzed []CreatedEmail
+	uc := NewReanalyzeUseCase(&stubReanalysisRepository{
+		findReanalysisTargets: func(ctx context.Context, query ReanalysisTargetQuery) ([]ReanalysisTarget, error) {
+			if !reflect.DeepEqual(query.EmailIDs, []uint{1, 2, 3}) {
+				t.Fatalf("email ids must be deduplicated: %v", query.EmailIDs)
+			}
+			return targets, nil
+		},
+		findConnectionID: func(ctx context.Context, userID uint, provider, accountIdentifier string) (uint, error) {
+			if provider != "gmail" || accountIdentifier != "a@example.com" {
+				t.Fatalf("unexpected connection lookup: %s %s", provider, accountIdentifier)
+			}
+			return 9, nil
+		},
+		findBillingsByEmailIDs: func(ctx context.Context, userID uint, emailIDs []uint) ([]ExistingBilling, error) {
+			t.Fatal("billings must not be compared without a billing mode")
+			return nil, nil
+		},
+	}, &stubReanalysisBodyReader{
+		getBodies: func(ctx context.Context, userID uint, emailIDs []uint) ([]StoredEmailBody, error) {
+			return []StoredEmailBody{{EmailID: 1, Body: "stored body", Attachments: []EmailAttachment{{Filename: "invoice.pdf", Text: "Total"}}}}, nil
+		},
+	}, BuiltinStages{
+		Fetch: &stubFetchStage{execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+			fetchCmd = cmd
+			return FetchResult{ExistingEmails: []CreatedEmail{{EmailID: 2, ExternalMessageID: "msg-2", Body: "refetched body"}}}, nil
+		}},
+		Analyze: reanalysisAnalyzeStage(t, &analyzed),
+		VendorResolution: &stubVendorResolutionStage{execute: func(ctx context.Context, cmd VendorResolutionCommand) (VendorResolutionResult, error) {
+			t.Fatal("vendor resolution must not run without a billing mode")
+			return VendorResolutionResult{}, nil
+		}},
+	}, nil, logger.NewNop())
+
+	result, err := uc.Reanalyze(context.Background(), ReanalyzeCommand{UserID: 7, EmailIDs: []uint{1, 2, 3, 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fetchCmd.ConnectionID != 9 || !fetchCmd.IncludeExistingEmails || fetchCmd.DryRun || !reflect.DeepEqual(fetchCmd.MessageIDs, []string{"msg-2"}) {
+		t.Fatalf("unexpected fetch command: %+v", fetchCmd)
+	}
+	if !fetchCmd.Condition.Since.Equal(receivedAt.Add(-25*time.Hour)) || !fetchCmd.Condition.Until.Equal(receivedAt.Add(23*time.Hour)) {
+		t.Fatalf("unexpected fetch period: %+v", fetchCmd.Condition)
+	}
+	if len(analyzed) != 2 || analyzed[0].Body != "stored body" || analyzed[0].Subject != "請求書" || len(analyzed[0].Attachments) != 1 || analyzed[1].Body != "refetched body" {
+		t.Fatalf("unexpected analyzed emails: %+v", analyzed)
+	}
+	if result.TargetCount != 3 || result.RefetchedCount != 1 || result.Analysis.ParsedEmailCount != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].EmailID != 3 || result.Skipped[0].ReasonCode != reasonCodeEmailBodyUnavailable {
+		t.Fatalf("unexpected skipped emails: %+v", result.Skipped)
+	}
+	if result.BillingChanges != nil {
+		t.Fatalf("billing changes must be empty: %+v", result.BillingChanges)
+	}
+}
+
+func TestReanalyzeUseCase_ComparesWithExistingBillings(t *testing.T) {
+	t.Parallel()
+
+	billingDate := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	targets := []ReanalysisTarget{
+		{EmailID: 1, ExternalMessageID: "msg-1", Provider: "gmail"},
+		{EmailID: 2, ExternalMessageID: "msg-2", Provider: "gmail"},
+	}
+	eligible := []EligibleItem{
+		{ParsedEmailID: 100, EmailID: 1, ExternalMessageID: "msg-1", VendorID: 3, BillingNumber: "INV-1", Amount: 1000, Currency: "JPY", BillingDate: &billingDate, PaymentCycle: "one_time"},
+		{ParsedEmailID: 100, EmailID: 1, ExternalMessageID: "msg-1", VendorID: 3, BillingNumber: "INV-2", Amount: 500, Currency: "JPY", PaymentCycle: "one_time"},
+		{ParsedEmailID: 101, EmailID: 2, ExternalMessageID: "msg-2", VendorID: 4, BillingNumber: "INV-3", Amount: 2000, Currency: "usd", PaymentCycle: "recurring"},
+	}
+	existingAmount := 1000.0
+	changedAmount := 1800.0
+	existing := []ExistingBilling{
+		{BillingID: 50, EmailID: 1, VendorID: 3, BillingNumber: "INV-1", Amount: &existingAmount, Currency: "JPY", BillingDate: &billingDate, PaymentCycle: "one_time"},
+		{BillingID: 51, EmailID: 1, VendorID: 3, BillingNumber: "INV-9", Amount: &existingAmount, Currency: "JPY", PaymentCycle: "one_time"},
+		{BillingID: 52, EmailID: 2, VendorID: 4, BillingNumber: "INV-3", Amount: &changedAmount, Currency: "USD", PaymentCycle: "one_time"},
+	}
+
+	tests := []struct {
+		name          string
+		billingMode   string
+		wantDryRun    bool
+		wantBilling   bool
+		wantCreatedID uint
+	}{
+		{name: "preview", billingMode: ReanalysisBillingPreview, wantDryRun: true},
+		{name: "apply", billingMode: ReanalysisBillingApply, wantBilling: true, wantCreatedID: 60},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var analyzed []CreatedEmail
+			billingCalled := false
+			uc := NewReanalyzeUseCase(&stubReanalysisRepository{
+				findReanalysisTargets: func(ctx context.Context, query ReanalysisTargetQuery) ([]ReanalysisTarget, error) {
+					return targets, nil
+				},
+				findBillingsByEmailIDs: func(ctx context.Context, userID uint, emailIDs []uint) ([]ExistingBilling, error) {
+					if !reflect.DeepEqual(emailIDs, []uint{1, 2}) {
+						t.Fatalf("unexpected email ids: %v", emailIDs)
+					}
+					return existing, nil
+				},
+			}, &stubReanalysisBodyReader{
+				getBodies: func(ctx context.Context, userID uint, emailIDs []uint) ([]StoredEmailBody, error) {
+					return []StoredEmailBody{{EmailID: 1, Body: "body-1"}, {EmailID: 2, Body: "body-2"}}, nil
+				},
+			}, BuiltinStages{
+				Analyze: reanalysisAnalyzeStage(t, &analyzed),
+				VendorResolution: &stubVendorResolutionStage{execute: func(ctx context.Context, cmd VendorResolutionCommand) (VendorResolutionResult, error) {
+					if cmd.DryRun != tt.wantDryRun || len(cmd.ParsedEmails) != 2 {
+						t.Fatalf("unexpected vendor resolution command: %+v", cmd)
+					}
+					return VendorResolutionResult{ResolvedItems: []ResolvedItem{{ParsedEmailID: 100}, {ParsedEmailID: 101}}, ResolvedCount: 2}, nil
+				}},
+				BillingEligibility: &stubBillingEligibilityStage{execute: func(ctx context.Context, cmd BillingEligibilityCommand) (BillingEligibilityResult, error) {
+					if cmd.DryRun != tt.wantDryRun {
+						t.Fatalf("unexpected billing eligibility command: %+v", cmd)
+					}
+					return BillingEligibilityResult{EligibleItems: eligible, EligibleCount: len(eligible)}, nil
+				}},
+				Billing: &stubBillingStage{execute: func(ctx context.Context, cmd BillingCommand) (BillingResult, error) {
+					billingCalled = true
+					return BillingResult{
+						CreatedItems:   []BillingCreatedItem{{BillingID: 60, EmailID: 1, VendorID: 3, BillingNumber: "INV-2"}},
+						DuplicateItems: []BillingDuplicateItem{{ExistingBillingID: 50}, {ExistingBillingID: 52}},
+					}, nil
+				}},
+			}, nil, logger.NewNop())
+
+			result, err := uc.Reanalyze(context.Background(), ReanalyzeCommand{UserID: 7, EmailIDs: []uint{1, 2}, BillingMode: tt.billingMode})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if billingCalled != tt.wantBilling {
+				t.Fatalf("billing stage called=%v, want %v", billingCalled, tt.wantBilling)
+			}
+
+			changes := result.BillingChanges
+			if len(changes) != 4 {
+				t.Fatalf("unexpected billing changes: %+v", changes)
+			}
+			if changes[0].Change != ReanalysisChangeUnchanged || changes[0].Existing.BillingID != 50 {
+				t.Fatalf("unexpected unchanged billing: %+v", changes[0])
+			}
+			if changes[1].Change != ReanalysisChangeAdded || changes[1].Existing != nil || changes[1].CreatedBillingID != tt.wantCreatedID {
+				t.Fatalf("unexpected added billing: %+v", changes[1])
+			}
+			if changes[2].Change != ReanalysisChangeMissing || changes[2].Existing.BillingID != 51 || changes[2].ExternalMessageID != "msg-1" {
+				t.Fatalf("unexpected missing billing: %+v", changes[2])
+			}
+			if changes[3].Change != ReanalysisChangeChanged || !reflect.DeepEqual(changes[3].ChangedFields, []string{"amount", "payment_cycle"}) {
+				t.Fatalf("unexpected changed billing: %+v", changes[3])
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultReapBatchSize = 50
+
+	staleWorkflowErrorMessage = "メール取得ワークフローが長時間進まなかったため、処理を打ち切りました。"
+)
+
+// StaleWorkflow は一定時間更新されないまま queued / running に残っている workflow。
+type StaleWorkflow struct {
+	HistoryID    uint64
+	WorkflowID   string
+	UserID       uint
+	Status       string
+	CurrentStage string
+	UpdatedAt    time.Time
+}
+
+// StaleWorkflowRepository は staleBefore より前から更新されていない queued / running の workflow を取り出す。
+// 実行中の worker が lease を保持している workflow は、更新が止まっていても返さない。
+// ClaimStale は返す workflow の job を再実行されない状態にしてから確定させるため、
+// 複数インスタンスが同時に呼んでも同じ workflow を二重に返さない。
+type StaleWorkflowRepository interface {
+	ClaimStale(ctx context.Context, staleBefore time.Time, limit int) ([]StaleWorkflow, error)
+}
+
+// ReapUseCase は終わらないまま放置された workflow を failed にする。
+type ReapUseCase interface {
+	ReapStale(ctx context.Context, staleAfter time.Duration) (int, error)
+}
+
+type reapUseCase struct {
+	repository StaleWorkflowRepository
+	statuses   WorkflowStatusRepository
+	clock      timewrapper.ClockInterface
+	log        logger.Interface
+}
+
+// NewReapUseCase creates a use case that fails workflows left queued or running for too long.
+func NewReapUseCase(
+	repository StaleWorkflowRepository,
+	statuses WorkflowStatusRepository,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) ReapUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &reapUseCase{
+		repository: repository,
+		statuses:   statuses,
+		clock:      clock,
+		log:        log.With(logger.Component("manual_mail_workflow_reap_usecase")),
+	}
+}
+
+// ReapStale fails every workflow that has not been updated for staleAfter, keeping its last
+// current_stage, and returns the number of workflows failed. A workflow that cannot be failed
+// is logged and does not stop the remaining ones.
+func (uc *reapUseCase) ReapStale(ctx context.Context, staleAfter time.Duration) (int, error) {
+	if ctx == nil {
+		return 0, logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return 0, errors.New("stale_workflow_repository is not configured")
+	}
+	if uc.statuses == nil {
+		return 0, errors.New("workflow_status_repository is not configured")
+	}
+	if staleAfter <= 0 {
+		return 0, fmt.Errorf("%w: stale_after must be positive", ErrInvalidCommand)
+	}
+
+	now := uc.clock.Now().UTC()
+	workflows, err := uc.repository.ClaimStale(ctx, now.Add(-staleAfter), defaultReapBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	reaped := 0
+	for _, workflow := range workflows {
+		if ctx.Err() != nil {
+			return reaped, ctx.Err()
+		}
+		if uc.reap(ctx, workflow, now) {
+			reaped++
+		}
+	}
+
+	return reaped, nil
+}
+
+func (uc *reapUseCase) reap(ctx context.Context, workflow StaleWorkflow, now time.Time) bool {
+	workflowCtx := ctx
+	if next, err := logger.ContextWithUserID(ctx, workflow.UserID); err == nil {
+		workflowCtx = next
+	}
+	if next, err := logger.ContextWithJobID(workflowCtx, workflow.WorkflowID); err == nil {
+		workflowCtx = next
+	}
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(workflowCtx); err == nil {
+		reqLog = withContext
+	}
+
+	if err := uc.statuses.Fail(workflowCtx, workflow.HistoryID, workflow.CurrentStage, now, staleWorkflowErrorMessage); err != nil {
+		reqLog.Error("manual_mail_workflow_reap_failed",
+			logger.String("workflow_id", workflow.WorkflowID),
+			logger.Uint("history_id", uint(workflow.HistoryID)),
+			logger.Err(err),
+		)
+		return false
+	}
+
+	reqLog.Warn("manual_mail_workflow_reaped",
+		logger.String("workflow_id", workflow.WorkflowID),
+		logger.Uint("history_id", uint(workflow.HistoryID)),
+		logger.String("previous_status", workflow.Status),
+		logger.String("current_stage", workflow.CurrentStage),
+		logger.String("last_updated_at", workflow.UpdatedAt.UTC().Format(time.RFC3339)),
+		logger.Int("stale_seconds", int(now.Sub(workflow.UpdatedAt).Seconds())),
+	)
+	return true
+}
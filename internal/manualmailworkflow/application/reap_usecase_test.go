@@ -0,0 +1,90 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubStaleWorkflowRepository struct {
+	claimStale func(ctx context.Context, staleBefore time.Time, limit int) ([]StaleWorkflow, error)
+}
+
+func (s *stubStaleWorkflowRepository) ClaimStale(ctx context.Context, staleBefore time.Time, limit int) ([]StaleWorkflow, error) {
+	return s.claimStale(ctx, staleBefore, limit)
+}
+
+func TestReapUseCase_ReapStale_FailsStaleWorkflowsWithLastStage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	type failCall struct {
+		historyID    uint64
+		currentStage string
+		errorMessage string
+	}
+	var calls []failCall
+	uc := NewReapUseCase(
+		&stubStaleWorkflowRepository{
+			claimStale: func(ctx context.Context, staleBefore time.Time, limit int) ([]StaleWorkflow, error) {
+				if !staleBefore.Equal(now.Add(-3*time.Hour)) || limit != defaultReapBatchSize {
+					t.Fatalf("unexpected claim: staleBefore=%s limit=%d", staleBefore, limit)
+				}
+				return []StaleWorkflow{
+					{HistoryID: 1, WorkflowID: "wf-running", UserID: 7, Status: WorkflowStatusRunning, CurrentStage: workflowStageAnalysis, UpdatedAt: now.Add(-4 * time.Hour)},
+					{HistoryID: 2, WorkflowID: "wf-broken", UserID: 7, Status: WorkflowStatusRunning, CurrentStage: workflowStageFetch, UpdatedAt: now.Add(-5 * time.Hour)},
+					{HistoryID: 3, WorkflowID: "wf-queued", UserID: 8, Status: WorkflowStatusQueued, UpdatedAt: now.Add(-6 * time.Hour)},
+				}, nil
+			},
+		},
+		&stubWorkflowStatusRepository{
+			fail: func(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time, errorMessage string) error {
+				if !finishedAt.Equal(now) {
+					t.Fatalf("unexpected finishedAt: %s", finishedAt)
+				}
+				if jobID, ok := logger.JobIDFromContext(ctx); !ok || jobID == "" {
+					t.Fatalf("expected workflow id on the context, got %q %v", jobID, ok)
+				}
+				if historyID == 2 {
+					return errors.New("db down")
+				}
+				calls = append(calls, failCall{historyID: historyID, currentStage: currentStage, errorMessage: errorMessage})
+				return nil
+			},
+		},
+		&fixedClock{now: now},
+		logger.NewNop(),
+	)
+
+	reaped, err := uc.ReapStale(context.Background(), 3*time.Hour)
+	if err != nil {
+		t.Fatalf("ReapStale returned error: %v", err)
+	}
+	if reaped != 2 {
+		t.Fatalf("expected 2 reaped workflows, got %d", reaped)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("unexpected fail calls: %+v", calls)
+	}
+	if calls[0].historyID != 1 || calls[0].currentStage != workflowStageAnalysis || calls[0].errorMessage != staleWorkflowErrorMessage {
+		t.Fatalf("unexpected first fail call: %+v", calls[0])
+	}
+	if calls[1].historyID != 3 || calls[1].currentStage != "" {
+		t.Fatalf("unexpected second fail call: %+v", calls[1])
+	}
+}
+
+func TestReapUseCase_ReapStale_RejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	uc := NewReapUseCase(&stubStaleWorkflowRepository{}, &stubWorkflowStatusRepository{}, nil, nil)
+
+	if _, err := uc.ReapStale(nil, time.Hour); !errors.Is(err, logger.ErrNilContext) {
+		t.Fatalf("expected ErrNilContext, got %v", err)
+	}
+	if _, err := uc.ReapStale(context.Background(), 0); !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("expected ErrInvalidCommand, got %v", err)
+	}
+}
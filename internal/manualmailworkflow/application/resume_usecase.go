@@ -0,0 +1,445 @@
+package application
+
+import (
+	commondomain "business/internal/common/domain"
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const reasonCodeResumeSourceMissing = "resume_source_missing"
+
+var (
+	// ErrWorkflowNotResumable indicates the workflow did not fail, or cannot continue without calling Gmail or OpenAI again.
+	ErrWorkflowNotResumable = errors.New("manual mail workflow is not resumable")
+)
+
+// HandoffItem is one parsed email handed to a later stage, recorded by ID.
+// LineItems are kept because parsed_emails does not persist them; Vendor* is set once the vendor is resolved.
+type HandoffItem struct {
+	ParsedEmailID     uint
+	EmailID           uint
+	ExternalMessageID string
+	VendorID          uint
+	VendorName        string
+	MatchedBy         string
+	LineItems         []commondomain.ParsedEmailLineItem
+}
+
+// HandoffFailure is a technical failure carried over to a later stage, such as a retry target whose source was missing.
+type HandoffFailure struct {
+	Stage             string
+	ExternalMessageID string
+	Code              string
+	Message           string
+}
+
+// StageHandoff records the inputs still pending for the later stages at the time Stage finished.
+// ParsedEmails / ResolvedItems are rebuilt from emails and parsed_emails on resume, so Gmail and OpenAI are not called again.
+type StageHandoff struct {
+	Stage string
+	// EmailIDs are the emails handed to analysis. Set only on the fetch handoff.
+	EmailIDs []uint
+	// ParsedEmails are the vendorresolution input.
+	ParsedEmails []HandoffItem
+	// ResolvedItems are the billingeligibility input.
+	ResolvedItems []HandoffItem
+	// EligibleItems are the billing input as is, because the billing fields are not persisted before billing.
+	EligibleItems []EligibleItem
+	Failures      []HandoffFailure
+}
+
+// ResumeCommand identifies the failed workflow the user wants to continue from its last finished stage.
+type ResumeCommand struct {
+	UserID     uint
+	WorkflowID string
+}
+
+// WorkflowResumeSource is the persisted state a workflow is resumed from.
+// ConnectionID comes from the queued job and is zero when the workflow has no job row.
+type WorkflowResumeSource struct {
+	HistoryID         uint64
+	WorkflowID        string
+	RetryOfWorkflowID string
+	FanOut            bool
+	Status            string
+	CurrentStage      *string
+	ConnectionID      uint
+	Condition         FetchCondition
+	Stages            map[string]StageCountView
+	Handoffs          []StageHandoff
+}
+
+// WorkflowResumeRepository loads stage handoffs and rebuilds stage inputs from persisted rows.
+type WorkflowResumeRepository interface {
+	FindResumeSource(ctx context.Context, userID uint, workflowID string) (WorkflowResumeSource, error)
+	// FindParsedEmailsByIDs returns the ParsedEmail rows with their source email. LineItems are not set.
+	FindParsedEmailsByIDs(ctx context.Context, userID uint, parsedEmailIDs []uint) ([]ParsedEmail, error)
+	// Reopen puts a failed history back to queued while keeping current_stage and the saved stage counts.
+	Reopen(ctx context.Context, historyID uint64) error
+}
+
+// ResumeUseCase accepts requests to continue a failed workflow from its last stage handoff.
+type ResumeUseCase interface {
+	Resume(ctx context.Context, cmd ResumeCommand) (StartResult, error)
+}
+
+type resumeUseCase struct {
+	resumeRepository   WorkflowResumeRepository
+	dispatcher         WorkflowDispatcher
+	repository         WorkflowStatusRepository
+	conflictRepository WorkflowConflictRepository
+	lock               WorkflowConnectionLock
+	clock              timewrapper.ClockInterface
+	log                logger.Interface
+}
+
+// NewResumeUseCase creates a use case that re-queues a failed workflow under the same workflow_id.
+// Like Start, the workflow is re-queued under the connection lock only when no active workflow overlaps it.
+func NewResumeUseCase(
+	resumeRepository WorkflowResumeRepository,
+	dispatcher WorkflowDispatcher,
+	repository WorkflowStatusRepository,
+	conflictRepository WorkflowConflictRepository,
+	lock WorkflowConnectionLock,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) ResumeUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &resumeUseCase{
+		resumeRepository:   resumeRepository,
+		dispatcher:         dispatcher,
+		repository:         repository,
+		conflictRepository: conflictRepository,
+		lock:               lock,
+		clock:              clock,
+		log:                log.With(logger.Component("manual_mail_workflow_resume_usecase")),
+	}
+}
+
+// Resume validates that the failed workflow finished analysis and re-queues it.
+// The runner then starts from the stage after the last handoff instead of fetching and analyzing again.
+func (uc *resumeUseCase) Resume(ctx context.Context, cmd ResumeCommand) (StartResult, error) {
+	if ctx == nil {
+		return StartResult{}, logger.ErrNilContext
+	}
+	if uc.resumeRepository == nil {
+		return StartResult{}, errors.New("workflow_resume_repository is not configured")
+	}
+	if uc.dispatcher == nil {
+		return StartResult{}, errors.New("workflow_dispatcher is not configured")
+	}
+	if uc.repository == nil {
+		return StartResult{}, errors.New("workflow_status_repository is not configured")
+	}
+	if uc.conflictRepository == nil {
+		return StartResult{}, errors.New("workflow_conflict_repository is not configured")
+	}
+	if uc.lock == nil {
+		return StartResult{}, errors.New("workflow_connection_lock is not configured")
+	}
+
+	cmd.WorkflowID = strings.TrimSpace(cmd.WorkflowID)
+	if cmd.UserID == 0 {
+		return StartResult{}, fmt.Errorf("%w: user_id is required", ErrInvalidCommand)
+	}
+	if cmd.WorkflowID == "" {
+		return StartResult{}, fmt.Errorf("%w: workflow_id is required", ErrInvalidCommand)
+	}
+
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+
+	source, err := uc.resumeRepository.FindResumeSource(ctx, cmd.UserID, cmd.WorkflowID)
+	if err != nil {
+		return StartResult{}, err
+	}
+	if source.FanOut {
+		return StartResult{}, ErrWorkflowFanOutParent
+	}
+	if source.Status != WorkflowStatusFailed {
+		return StartResult{}, fmt.Errorf("%w: status=%s", ErrWorkflowNotResumable, source.Status)
+	}
+	handoff, ok := latestResumableHandoff(source.Handoffs)
+	if !ok {
+		return StartResult{}, fmt.Errorf("%w: analysis has not finished", ErrWorkflowNotResumable)
+	}
+	if source.ConnectionID == 0 {
+		return StartResult{}, fmt.Errorf("%w: workflow job not found", ErrWorkflowNotResumable)
+	}
+
+	condition := source.Condition.Normalize()
+	release, err := acquireWorkflowConnection(ctx, uc.lock, uc.conflictRepository,
+		activeWorkflowQuery(cmd.UserID, source.ConnectionID, condition), source.WorkflowID, reqLog)
+	if release != nil {
+		defer release()
+	}
+	if err != nil {
+		return StartResult{}, err
+	}
+
+	if err := uc.resumeRepository.Reopen(ctx, source.HistoryID); err != nil {
+		return StartResult{}, err
+	}
+
+	currentStage := ""
+	if source.CurrentStage != nil {
+		currentStage = *source.CurrentStage
+	}
+	if err := uc.dispatcher.Dispatch(ctx, DispatchJob{
+		HistoryID:         source.HistoryID,
+		WorkflowID:        source.WorkflowID,
+		UserID:            cmd.UserID,
+		ConnectionID:      source.ConnectionID,
+		Condition:         condition,
+		RetryOfWorkflowID: source.RetryOfWorkflowID,
+	}); err != nil {
+		if failErr := uc.repository.Fail(ctx, source.HistoryID, currentStage, uc.clock.Now().UTC(), localizedWorkflowErrorMessage("", err)); failErr != nil {
+			reqLog.Error("manual_mail_workflow_dispatch_failed_to_mark_history",
+				logger.UserID(cmd.UserID),
+				logger.String("workflow_id", source.WorkflowID),
+				logger.Err(failErr),
+			)
+		}
+		return StartResult{}, err
+	}
+
+	reqLog.Info("manual_mail_workflow_resume_accepted",
+		logger.UserID(cmd.UserID),
+		logger.Uint("connection_id", source.ConnectionID),
+		logger.String("workflow_id", source.WorkflowID),
+		logger.String("current_stage", currentStage),
+		logger.String("resumed_from", handoff.Stage),
+	)
+
+	return StartResult{
+		WorkflowID: source.WorkflowID,
+		Status:     WorkflowStatusQueued,
+	}, nil
+}
+
+// latestResumableHandoff returns the handoff of the last finished stage when it is analysis or later.
+// A fetch handoff is not resumable because analysis would call OpenAI again.
+func latestResumableHandoff(handoffs []StageHandoff) (StageHandoff, bool) {
+	latestIndex := -1
+	var latest StageHandoff
+	for _, handoff := range handoffs {
+		if idx := workflowStageIndex(handoff.Stage); idx > latestIndex {
+			latestIndex = idx
+			latest = handoff
+		}
+	}
+	if latestIndex < workflowStageIndex(workflowStageAnalysis) {
+		return StageHandoff{}, false
+	}
+	return latest, true
+}
+
+// loadResumeSeeds は前回の実行が analysis 以降まで進んでいれば、最後の handoff から後続 stage の入力を組み立て直す。
+// worker の lease 切れで拾い直した job と、Resume で queued に戻した job の両方がここを通る。
+// handoff がなければ resumedFrom が空の seeds を返し、通常どおり先頭から実行する。
+func (uc *useCase) loadResumeSeeds(ctx context.Context, job DispatchJob) (stageSeeds, error) {
+	if uc.resumeRepository == nil {
+		return stageSeeds{}, nil
+	}
+
+	source, err := uc.resumeRepository.FindResumeSource(ctx, job.UserID, job.WorkflowID)
+	if err != nil {
+		return stageSeeds{}, err
+	}
+	handoff, ok := latestResumableHandoff(source.Handoffs)
+	if !ok {
+		return stageSeeds{}, nil
+	}
+
+	seeds := stageSeeds{
+		resumedFrom:   handoff.Stage,
+		priorFailures: hasStageFailuresThrough(source.Stages, handoff.Stage),
+		eligibleItems: append([]EligibleItem(nil), handoff.EligibleItems...),
+	}
+	for _, failure := range handoff.Failures {
+		seeds.addFailure(failure)
+	}
+
+	parsedEmailIDs := make([]uint, 0, len(handoff.ParsedEmails)+len(handoff.ResolvedItems))
+	for _, item := range handoff.ParsedEmails {
+		parsedEmailIDs = append(parsedEmailIDs, item.ParsedEmailID)
+	}
+	for _, item := range handoff.ResolvedItems {
+		parsedEmailIDs = append(parsedEmailIDs, item.ParsedEmailID)
+	}
+	if len(parsedEmailIDs) == 0 {
+		return seeds, nil
+	}
+
+	parsedEmails, err := uc.resumeRepository.FindParsedEmailsByIDs(ctx, job.UserID, parsedEmailIDs)
+	if err != nil {
+		return stageSeeds{}, err
+	}
+	parsedByID := make(map[uint]ParsedEmail, len(parsedEmails))
+	for _, parsedEmail := range parsedEmails {
+		parsedByID[parsedEmail.ParsedEmailID] = parsedEmail
+	}
+
+	for _, item := range handoff.ParsedEmails {
+		parsedEmail, found := parsedByID[item.ParsedEmailID]
+		if !found {
+			seeds.addFailure(resumeSourceMissingFailure(workflowStageVendorResolution, item.ExternalMessageID))
+			continue
+		}
+		parsedEmail.Data.LineItems = append([]commondomain.ParsedEmailLineItem(nil), item.LineItems...)
+		seeds.parsedEmails = append(seeds.parsedEmails, parsedEmail)
+	}
+	for _, item := range handoff.ResolvedItems {
+		parsedEmail, found := parsedByID[item.ParsedEmailID]
+		if !found {
+			seeds.addFailure(resumeSourceMissingFailure(workflowStageBillingEligibility, item.ExternalMessageID))
+			continue
+		}
+		parsedEmail.Data.LineItems = append([]commondomain.ParsedEmailLineItem(nil), item.LineItems...)
+		seeds.resolvedItems = append(seeds.resolvedItems, ResolvedItem{
+			ParsedEmailID:     parsedEmail.ParsedEmailID,
+			EmailID:           parsedEmail.EmailID,
+			ExternalMessageID: parsedEmail.ExternalMessageID,
+			VendorID:          item.VendorID,
+			VendorName:        item.VendorName,
+			MatchedBy:         item.MatchedBy,
+			Data:              parsedEmail.Data,
+		})
+	}
+
+	return seeds, nil
+}
+
+// newStageHandoff は stage の完了時点で、後続 stage に残っている入力を記録する。
+// result にはその stage までの出力、seeds には retry / 再開で後続 stage に直接投入する入力が入っている。
+func newStageHandoff(stage string, result Result, seeds stageSeeds) *StageHandoff {
+	stageIndex := workflowStageIndex(stage)
+	handoff := &StageHandoff{Stage: stage}
+
+	if stage == workflowStageFetch {
+		for _, email := range result.Fetch.CreatedEmails {
+			handoff.EmailIDs = append(handoff.EmailIDs, email.EmailID)
+		}
+		for _, email := range result.Fetch.ExistingEmails {
+			handoff.EmailIDs = append(handoff.EmailIDs, email.EmailID)
+		}
+	}
+	if stageIndex < workflowStageIndex(workflowStageVendorResolution) {
+		if stage == workflowStageAnalysis {
+			handoff.ParsedEmails = appendParsedEmailHandoffItems(handoff.ParsedEmails, result.Analysis.ParsedEmails)
+		}
+		handoff.ParsedEmails = appendParsedEmailHandoffItems(handoff.ParsedEmails, seeds.parsedEmails)
+	}
+	if stageIndex < workflowStageIndex(workflowStageBillingEligibility) {
+		if stage == workflowStageVendorResolution {
+			handoff.ResolvedItems = appendResolvedHandoffItems(handoff.ResolvedItems, result.VendorResolution.ResolvedItems)
+		}
+		handoff.ResolvedItems = appendResolvedHandoffItems(handoff.ResolvedItems, seeds.resolvedItems)
+	}
+	if stageIndex < workflowStageIndex(workflowStageBilling) {
+		if stage == workflowStageBillingEligibility {
+			handoff.EligibleItems = append(handoff.EligibleItems, result.BillingEligibility.EligibleItems...)
+		}
+		handoff.EligibleItems = append(handoff.EligibleItems, seeds.eligibleItems...)
+	}
+	for _, failure := range seeds.pendingFailures() {
+		if workflowStageIndex(failure.Stage) > stageIndex {
+			handoff.Failures = append(handoff.Failures, failure)
+		}
+	}
+
+	return handoff
+}
+
+// pendingFailures は後続 stage に持ち越している技術的失敗を handoff 用の形で返す。
+func (s stageSeeds) pendingFailures() []HandoffFailure {
+	failures := make([]HandoffFailure, 0, len(s.vendorResolutionFailures)+len(s.billingEligibilityFailures)+len(s.billingFailures))
+	for _, failure := range s.vendorResolutionFailures {
+		failures = append(failures, HandoffFailure{
+			Stage:             workflowStageVendorResolution,
+			ExternalMessageID: failure.ExternalMessageID,
+			Code:              failure.Code,
+			Message:           failure.Message,
+		})
+	}
+	for _, failure := range s.billingEligibilityFailures {
+		failures = append(failures, HandoffFailure{
+			Stage:             workflowStageBillingEligibility,
+			ExternalMessageID: failure.ExternalMessageID,
+			Code:              failure.Code,
+			Message:           failure.Message,
+		})
+	}
+	for _, failure := range s.billingFailures {
+		failures = append(failures, HandoffFailure{
+			Stage:             workflowStageBilling,
+			ExternalMessageID: failure.ExternalMessageID,
+			Code:              failure.Code,
+			Message:           failure.Message,
+		})
+	}
+	return failures
+}
+
+func appendParsedEmailHandoffItems(items []HandoffItem, parsedEmails []ParsedEmail) []HandoffItem {
+	for _, parsedEmail := range parsedEmails {
+		items = append(items, HandoffItem{
+			ParsedEmailID:     parsedEmail.ParsedEmailID,
+			EmailID:           parsedEmail.EmailID,
+			ExternalMessageID: parsedEmail.ExternalMessageID,
+			LineItems:         append([]commondomain.ParsedEmailLineItem(nil), parsedEmail.Data.LineItems...),
+		})
+	}
+	return items
+}
+
+func appendResolvedHandoffItems(items []HandoffItem, resolvedItems []ResolvedItem) []HandoffItem {
+	for _, resolved := range resolvedItems {
+		items = append(items, HandoffItem{
+			ParsedEmailID:     resolved.ParsedEmailID,
+			EmailID:           resolved.EmailID,
+			ExternalMessageID: resolved.ExternalMessageID,
+			VendorID:          resolved.VendorID,
+			VendorName:        resolved.VendorName,
+			MatchedBy:         resolved.MatchedBy,
+			LineItems:         append([]commondomain.ParsedEmailLineItem(nil), resolved.Data.LineItems...),
+		})
+	}
+	return items
+}
+
+// hasStageFailuresThrough は stage までに完了した stage に失敗が記録されているかを返す。
+func hasStageFailuresThrough(stages map[string]StageCountView, stage string) bool {
+	lastIndex := workflowStageIndex(stage)
+	for idx, candidate := range workflowStages {
+		if idx > lastIndex {
+			break
+		}
+		counts := stages[candidate]
+		if counts.BusinessFailureCount+counts.TechnicalFailureCount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func resumeSourceMissingFailure(stage string, externalMessageID string) HandoffFailure {
+	return HandoffFailure{
+		Stage:             stage,
+		ExternalMessageID: externalMessageID,
+		Code:              reasonCodeResumeSourceMissing,
+		Message:           externalMessageIDText(externalMessageID) + " の再開に必要な前回の処理結果が見つかりませんでした。",
+	}
+}
@@ -0,0 +1,473 @@
+package application
+
+import (
+	commondomain "business/internal/common/domain"
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stubWorkflowResumeRepository struct {
+	findResumeSource      func(ctx context.Context, userID uint, workflowID string) (WorkflowResumeSource, error)
+	findParsedEmailsByIDs func(ctx context.Context, userID uint, parsedEmailIDs []uint) ([]ParsedEmail, error)
+	reopen                func(ctx context.Context, historyID uint64) error
+}
+
+func (s *stubWorkflowResumeRepository) FindResumeSource(ctx context.Context, userID uint, workflowID string) (WorkflowResumeSource, error) {
+	if s.findResumeSource == nil {
+		return WorkflowResumeSource{}, nil
+	}
+	return s.findResumeSource(ctx, userID, workflowID)
+}
+
+func (s *stubWorkflowResumeRepository) FindParsedEmailsByIDs(ctx context.Context, userID uint, parsedEmailIDs []uint) ([]ParsedEmail, error) {
+	if s.findParsedEmailsByIDs == nil {
+		return nil, nil
+	}
+	return s.findParsedEmailsByIDs(ctx, userID, parsedEmailIDs)
+}
+
+func (s *stubWorkflowResumeRepository) Reopen(ctx context.Context, historyID uint64) error {
+	if s.reopen == nil {
+		return nil
+	}
+	return s.reopen(ctx, historyID)
+}
+
+func resumeSourceFixture(status string, handoffs ...StageHandoff) WorkflowResumeSource {
+	return WorkflowResumeSource{
+		HistoryID:    41,
+		WorkflowID:   "wf-failed",
+		Status:       status,
+		CurrentStage: stringPtr(workflowStageBilling),
+		ConnectionID: 12,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+		Handoffs: handoffs,
+	}
+}
+
+func TestResumeUseCase_Resume_ReopensAndDispatchesSameHistory(t *testing.T) {
+	t.Parallel()
+
+	var reopened uint64
+	var dispatched DispatchJob
+	uc := NewResumeUseCase(
+		&stubWorkflowResumeRepository{
+			findResumeSource: func(ctx context.Context, userID uint, workflowID string) (WorkflowResumeSource, error) {
+				if userID != 7 || workflowID != "wf-failed" {
+					t.Fatalf("unexpected resume source target: user=%d workflow=%q", userID, workflowID)
+				}
+				return resumeSourceFixture(WorkflowStatusFailed,
+					StageHandoff{Stage: workflowStageFetch, EmailIDs: []uint{101}},
+					StageHandoff{Stage: workflowStageBillingEligibility, EligibleItems: []EligibleItem{{ParsedEmailID: 1}}},
+				), nil
+			},
+			reopen: func(ctx context.Context, historyID uint64) error {
+				reopened = historyID
+				return nil
+			},
+		},
+		&stubWorkflowDispatcher{
+			dispatch: func(ctx context.Context, job DispatchJob) error {
+				dispatched = job
+				return nil
+			},
+		},
+		&stubWorkflowStatusRepository{},
+		&stubWorkflowConflictRepository{},
+		&stubWorkflowConnectionLock{},
+		&fixedClock{now: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)},
+		logger.NewNop(),
+	)
+
+	result, err := uc.Resume(context.Background(), ResumeCommand{UserID: 7, WorkflowID: " wf-failed "})
+	if err != nil {
+		t.Fatalf("Resume returned error: %v", err)
+	}
+	if result.WorkflowID != "wf-failed" || result.Status != WorkflowStatusQueued {
+		t.Fatalf("unexpected resume result: %+v", result)
+	}
+	if reopened != 41 {
+		t.Fatalf("expected history 41 to be reopened, got %d", reopened)
+	}
+	if dispatched.HistoryID != 41 || dispatched.WorkflowID != "wf-failed" || dispatched.ConnectionID != 12 || dispatched.Condition.LabelName != "billing" {
+		t.Fatalf("unexpected dispatched job: %+v", dispatched)
+	}
+}
+
+func TestResumeUseCase_Resume_RejectsUnresumableWorkflow(t *testing.T) {
+	t.Parallel()
+
+	analysisHandoff := StageHandoff{Stage: workflowStageAnalysis, ParsedEmails: []HandoffItem{{ParsedEmailID: 1}}}
+	noJob := resumeSourceFixture(WorkflowStatusFailed, analysisHandoff)
+	noJob.ConnectionID = 0
+	fanOut := resumeSourceFixture(WorkflowStatusFailed)
+	fanOut.FanOut = true
+
+	tests := []struct {
+		name    string
+		cmd     ResumeCommand
+		source  WorkflowResumeSource
+		findErr error
+		wantErr error
+	}{
+		{
+			name:    "missing workflow id",
+			cmd:     ResumeCommand{UserID: 7, WorkflowID: " "},
+			wantErr: ErrInvalidCommand,
+		},
+		{
+			name:    "not found",
+			cmd:     ResumeCommand{UserID: 7, WorkflowID: "wf-failed"},
+			findErr: ErrWorkflowHistoryNotFound,
+			wantErr: ErrWorkflowHistoryNotFound,
+		},
+		{
+			name:    "not failed",
+			cmd:     ResumeCommand{UserID: 7, WorkflowID: "wf-failed"},
+			source:  resumeSourceFixture(WorkflowStatusPartialSuccess, analysisHandoff),
+			wantErr: ErrWorkflowNotResumable,
+		},
+		{
+			name:    "analysis not finished",
+			cmd:     ResumeCommand{UserID: 7, WorkflowID: "wf-failed"},
+			source:  resumeSourceFixture(WorkflowStatusFailed, StageHandoff{Stage: workflowStageFetch, EmailIDs: []uint{101}}),
+			wantErr: ErrWorkflowNotResumable,
+		},
+		{
+			name:    "job missing",
+			cmd:     ResumeCommand{UserID: 7, WorkflowID: "wf-failed"},
+			source:  noJob,
+			wantErr: ErrWorkflowNotResumable,
+		},
+		{
+			name:    "fan-out parent",
+			cmd:     ResumeCommand{UserID: 7, WorkflowID: "wf-failed"},
+			source:  fanOut,
+			wantErr: ErrWorkflowFanOutParent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewResumeUseCase(
+				&stubWorkflowResumeRepository{
+					findResumeSource: func(ctx context.Context, userID uint, workflowID string) (WorkflowResumeSource, error) {
+						return tt.source, tt.findErr
+					},
+					reopen: func(ctx context.Context, historyID uint64) error {
+						t.Fatal("Reopen must not be called")
+						return nil
+					},
+				},
+				&stubWorkflowDispatcher{
+					dispatch: func(ctx context.Context, job DispatchJob) error {
+						t.Fatal("Dispatch must not be called")
+						return nil
+					},
+				},
+				&stubWorkflowStatusRepository{},
+				&stubWorkflowConflictRepository{},
+				&stubWorkflowConnectionLock{},
+				nil,
+				logger.NewNop(),
+			)
+
+			_, err := uc.Resume(context.Background(), tt.cmd)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestResumeUseCase_Resume_MarksFailedWhenDispatchFails(t *testing.T) {
+	t.Parallel()
+
+	dispatchErr := errors.New("queue unavailable")
+	failedStage := ""
+	uc := NewResumeUseCase(
+		&stubWorkflowResumeRepository{
+			findResumeSource: func(ctx context.Context, userID uint, workflowID string) (WorkflowResumeSource, error) {
+				return resumeSourceFixture(WorkflowStatusFailed, StageHandoff{Stage: workflowStageAnalysis}), nil
+			},
+		},
+		&stubWorkflowDispatcher{
+			dispatch: func(ctx context.Context, job DispatchJob) error {
+				return dispatchErr
+			},
+		},
+		&stubWorkflowStatusRepository{
+			fail: func(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time, errorMessage string) error {
+				failedStage = currentStage
+				return nil
+			},
+		},
+		&stubWorkflowConflictRepository{},
+		&stubWorkflowConnectionLock{},
+		nil,
+		logger.NewNop(),
+	)
+
+	if _, err := uc.Resume(context.Background(), ResumeCommand{UserID: 7, WorkflowID: "wf-failed"}); !errors.Is(err, dispatchErr) {
+		t.Fatalf("expected dispatch error, got %v", err)
+	}
+	if failedStage != workflowStageBilling {
+		t.Fatalf("expected history to be failed again at billing, got %q", failedStage)
+	}
+}
+
+func TestResumeUseCase_Resume_RejectsOverlappingActiveWorkflow(t *testing.T) {
+	t.Parallel()
+
+	lock := &stubWorkflowConnectionLock{}
+	uc := NewResumeUseCase(
+		&stubWorkflowResumeRepository{
+			findResumeSource: func(ctx context.Context, userID uint, workflowID string) (WorkflowResumeSource, error) {
+				return resumeSourceFixture(WorkflowStatusFailed, StageHandoff{Stage: workflowStageAnalysis}), nil
+			},
+			reopen: func(ctx context.Context, historyID uint64) error {
+				t.Fatal("Reopen must not be called for a conflicting resume")
+				return nil
+			},
+		},
+		&stubWorkflowDispatcher{
+			dispatch: func(ctx context.Context, job DispatchJob) error {
+				t.Fatal("Dispatch must not be called for a conflicting resume")
+				return nil
+			},
+		},
+		&stubWorkflowStatusRepository{},
+		&stubWorkflowConflictRepository{
+			findActiveOverlapping: func(ctx context.Context, query ActiveWorkflowQuery) (string, bool, error) {
+				if _, held := lock.held[12]; !held {
+					t.Fatal("conflict check must run while the connection lock is held")
+				}
+				if query.UserID != 7 || query.ConnectionID != 12 || !reflect.DeepEqual(query.LabelNames, []string{"billing"}) {
+					t.Fatalf("unexpected conflict query: %+v", query)
+				}
+				return "wf-active", true, nil
+			},
+		},
+		lock,
+		nil,
+		logger.NewNop(),
+	)
+
+	_, err := uc.Resume(context.Background(), ResumeCommand{UserID: 7, WorkflowID: "wf-failed"})
+	if !errors.Is(err, ErrWorkflowConflict) {
+		t.Fatalf("expected ErrWorkflowConflict, got %v", err)
+	}
+	if !reflect.DeepEqual(lock.released, []string{"wf-failed"}) {
+		t.Fatalf("expected the lock to be released, got %v", lock.released)
+	}
+}
+
+func TestUseCaseExecute_ResumesFromLastHandoffWithoutFetchOrAnalysis(t *testing.T) {
+	t.Parallel()
+
+	lineItems := []commondomain.ParsedEmailLineItem{{ProductNameRaw: stringPtr("Pro plan")}}
+	var resolutionCommand VendorResolutionCommand
+	var eligibilityCommand BillingEligibilityCommand
+	var billedMessageIDs []string
+	var savedProgress []StageProgress
+	completedStatus := ""
+
+	uc := NewUseCase(
+		&stubFetchStage{
+			execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+				t.Fatal("fetch stage must not be called on resume")
+				return FetchResult{}, nil
+			},
+		},
+		&stubAnalyzeStage{
+			execute: func(ctx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+				t.Fatal("analysis stage must not be called on resume")
+				return AnalyzeResult{}, nil
+			},
+		},
+		&stubVendorResolutionStage{
+			execute: func(ctx context.Context, cmd VendorResolutionCommand) (VendorResolutionResult, error) {
+				resolutionCommand = cmd
+				return VendorResolutionResult{
+					ResolvedItems: []ResolvedItem{{
+						ParsedEmailID:     cmd.ParsedEmails[0].ParsedEmailID,
+						EmailID:           cmd.ParsedEmails[0].EmailID,
+						ExternalMessageID: cmd.ParsedEmails[0].ExternalMessageID,
+						VendorID:          9,
+						VendorName:        "Acme",
+						MatchedBy:         "name_exact",
+						Data:              cmd.ParsedEmails[0].Data,
+					}},
+					ResolvedCount: 1,
+				}, nil
+			},
+		},
+		&stubBillingEligibilityStage{
+			execute: func(ctx context.Context, cmd BillingEligibilityCommand) (BillingEligibilityResult, error) {
+				eligibilityCommand = cmd
+				result := BillingEligibilityResult{}
+				for _, item := range cmd.ResolvedItems {
+					result.EligibleItems = append(result.EligibleItems, EligibleItem{
+						ParsedEmailID:     item.ParsedEmailID,
+						EmailID:           item.EmailID,
+						ExternalMessageID: item.ExternalMessageID,
+						VendorID:          item.VendorID,
+					})
+				}
+				result.EligibleCount = len(result.EligibleItems)
+				return result, nil
+			},
+		},
+		&stubBillingStage{
+			execute: func(ctx context.Context, cmd BillingCommand) (BillingResult, error) {
+				result := BillingResult{}
+				for _, item := range cmd.EligibleItems {
+					billedMessageIDs = append(billedMessageIDs, item.ExternalMessageID)
+					result.CreatedItems = append(result.CreatedItems, BillingCreatedItem{ParsedEmailID: item.ParsedEmailID, ExternalMessageID: item.ExternalMessageID})
+				}
+				result.CreatedCount = len(result.CreatedItems)
+				return result, nil
+			},
+		},
+		&stubWorkflowStatusRepository{
+			saveStage: func(ctx context.Context, progress StageProgress) error {
+				savedProgress = append(savedProgress, progress)
+				return nil
+			},
+			complete: func(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error {
+				completedStatus = status
+				return nil
+			},
+		},
+		&stubWorkflowRetryRepository{
+			findRetrySource: func(ctx context.Context, userID uint, workflowID string) (WorkflowRetrySource, error) {
+				t.Fatal("retry source must not be loaded on resume")
+				return WorkflowRetrySource{}, nil
+			},
+		},
+		&stubWorkflowResumeRepository{
+			findResumeSource: func(ctx context.Context, userID uint, workflowID string) (WorkflowResumeSource, error) {
+				source := resumeSourceFixture(WorkflowStatusRunning,
+					StageHandoff{Stage: workflowStageFetch, EmailIDs: []uint{101, 102, 103}},
+					StageHandoff{
+						Stage:        workflowStageAnalysis,
+						ParsedEmails: []HandoffItem{{ParsedEmailID: 1, EmailID: 101, ExternalMessageID: "msg-1", LineItems: lineItems}},
+						ResolvedItems: []HandoffItem{
+							{ParsedEmailID: 2, EmailID: 102, ExternalMessageID: "msg-2", VendorID: 9, VendorName: "Acme", MatchedBy: "name_exact", LineItems: lineItems},
+							{ParsedEmailID: 3, EmailID: 103, ExternalMessageID: "msg-3", VendorID: 9},
+						},
+						EligibleItems: []EligibleItem{{ParsedEmailID: 4, ExternalMessageID: "msg-4"}},
+					},
+				)
+				source.Stages = map[string]StageCountView{
+					workflowStageFetch:    {SuccessCount: 3, TechnicalFailureCount: 1},
+					workflowStageAnalysis: {SuccessCount: 1},
+				}
+				return source, nil
+			},
+			findParsedEmailsByIDs: func(ctx context.Context, userID uint, parsedEmailIDs []uint) ([]ParsedEmail, error) {
+				if !reflect.DeepEqual(parsedEmailIDs, []uint{1, 2, 3}) {
+					t.Fatalf("unexpected parsed email lookup: %+v", parsedEmailIDs)
+				}
+				return []ParsedEmail{
+					{ParsedEmailID: 1, EmailID: 101, ExternalMessageID: "msg-1", Data: commondomain.ParsedEmail{BillingNumber: stringPtr("INV-1")}},
+					{ParsedEmailID: 2, EmailID: 102, ExternalMessageID: "msg-2", Data: commondomain.ParsedEmail{BillingNumber: stringPtr("INV-2")}},
+				}, nil
+			},
+		},
+		nil,
+		&fixedClock{now: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)},
+		logger.NewNop(),
+	)
+
+	result, err := uc.Execute(context.Background(), DispatchJob{
+		HistoryID:    41,
+		WorkflowID:   "wf-failed",
+		UserID:       7,
+		ConnectionID: 12,
+		Condition:    resumeSourceFixture(WorkflowStatusFailed).Condition,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if len(resolutionCommand.ParsedEmails) != 1 || resolutionCommand.ParsedEmails[0].ExternalMessageID != "msg-1" {
+		t.Fatalf("unexpected vendor resolution command: %+v", resolutionCommand)
+	}
+	if !reflect.DeepEqual(resolutionCommand.ParsedEmails[0].Data.LineItems, lineItems) {
+		t.Fatalf("expected line items to be restored from the handoff, got %+v", resolutionCommand.ParsedEmails[0].Data.LineItems)
+	}
+	if len(eligibilityCommand.ResolvedItems) != 2 || eligibilityCommand.ResolvedItems[1].ExternalMessageID != "msg-2" || eligibilityCommand.ResolvedItems[1].VendorName != "Acme" {
+		t.Fatalf("unexpected billing eligibility command: %+v", eligibilityCommand)
+	}
+	if !reflect.DeepEqual(billedMessageIDs, []string{"msg-1", "msg-2", "msg-4"}) {
+		t.Fatalf("unexpected billed messages: %+v", billedMessageIDs)
+	}
+	if len(result.BillingEligibility.Failures) != 1 || result.BillingEligibility.Failures[0].ExternalMessageID != "msg-3" || result.BillingEligibility.Failures[0].Code != reasonCodeResumeSourceMissing {
+		t.Fatalf("expected missing resume source failure for msg-3, got %+v", result.BillingEligibility.Failures)
+	}
+	if completedStatus != WorkflowStatusPartialSuccess {
+		t.Fatalf("expected partial_success because of prior failures, got %q", completedStatus)
+	}
+
+	if len(savedProgress) != 3 || savedProgress[0].Stage != workflowStageVendorResolution {
+		t.Fatalf("unexpected saved progress: %+v", savedProgress)
+	}
+	vendorHandoff := savedProgress[0].Handoff
+	if vendorHandoff == nil || len(vendorHandoff.ParsedEmails) != 0 || len(vendorHandoff.ResolvedItems) != 2 || len(vendorHandoff.EligibleItems) != 1 {
+		t.Fatalf("unexpected vendor resolution handoff: %+v", vendorHandoff)
+	}
+	if len(vendorHandoff.Failures) != 1 || vendorHandoff.Failures[0].Stage != workflowStageBillingEligibility {
+		t.Fatalf("expected the missing source failure to be handed to billing eligibility, got %+v", vendorHandoff.Failures)
+	}
+	billingHandoff := savedProgress[2].Handoff
+	if billingHandoff == nil || billingHandoff.Stage != workflowStageBilling || len(billingHandoff.EligibleItems) != 0 || len(billingHandoff.Failures) != 0 {
+		t.Fatalf("unexpected billing handoff: %+v", billingHandoff)
+	}
+}
+
+func TestNewStageHandoff_RecordsPendingInputsForLaterStages(t *testing.T) {
+	t.Parallel()
+
+	result := Result{
+		Fetch: FetchResult{
+			CreatedEmails:  []CreatedEmail{{EmailID: 101, ExternalMessageID: "msg-1"}},
+			ExistingEmails: []CreatedEmail{{EmailID: 102, ExternalMessageID: "msg-2"}},
+		},
+		Analysis: AnalyzeResult{ParsedEmails: []ParsedEmail{{ParsedEmailID: 1, EmailID: 101, ExternalMessageID: "msg-1"}}},
+	}
+	seeds := stageSeeds{
+		resolvedItems:   []ResolvedItem{{ParsedEmailID: 5, EmailID: 105, ExternalMessageID: "msg-5", VendorID: 9}},
+		billingFailures: []BillingFailure{{ExternalMessageID: "msg-6", Code: reasonCodeRetrySourceMissing}},
+	}
+
+	fetchHandoff := newStageHandoff(workflowStageFetch, result, seeds)
+	if !reflect.DeepEqual(fetchHandoff.EmailIDs, []uint{101, 102}) || len(fetchHandoff.ParsedEmails) != 0 || len(fetchHandoff.ResolvedItems) != 1 {
+		t.Fatalf("unexpected fetch handoff: %+v", fetchHandoff)
+	}
+
+	analysisHandoff := newStageHandoff(workflowStageAnalysis, result, seeds)
+	if len(analysisHandoff.EmailIDs) != 0 || len(analysisHandoff.ParsedEmails) != 1 || analysisHandoff.ParsedEmails[0].ParsedEmailID != 1 {
+		t.Fatalf("unexpected analysis handoff: %+v", analysisHandoff)
+	}
+	if len(analysisHandoff.ResolvedItems) != 1 || analysisHandoff.ResolvedItems[0].VendorID != 9 {
+		t.Fatalf("expected seeded resolved items to stay pending, got %+v", analysisHandoff.ResolvedItems)
+	}
+	if len(analysisHandoff.Failures) != 1 || analysisHandoff.Failures[0].Stage != workflowStageBilling {
+		t.Fatalf("expected billing failure to stay pending, got %+v", analysisHandoff.Failures)
+	}
+
+	if billingHandoff := newStageHandoff(workflowStageBilling, result, seeds); len(billingHandoff.Failures) != 0 || len(billingHandoff.ResolvedItems) != 0 {
+		t.Fatalf("expected nothing pending after billing, got %+v", billingHandoff)
+	}
+}
@@ -0,0 +1,407 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrWorkflowNotRetryable indicates the workflow is still queued/running, is a dry run, or finished without failures to retry.
+	ErrWorkflowNotRetryable = errors.New("manual mail workflow is not retryable")
+	// ErrWorkflowNoRetryTargets indicates the workflow has no failed messages that can be retried.
+	ErrWorkflowNoRetryTargets = errors.New("manual mail workflow has no retry targets")
+	// ErrWorkflowRetryConnectionUnavailable indicates the mail-account connection of the workflow is no longer usable.
+	ErrWorkflowRetryConnectionUnavailable = errors.New("manual mail workflow connection is unavailable for retry")
+)
+
+// RetryCommand identifies the workflow whose failed messages the user wants to run again.
+type RetryCommand struct {
+	UserID     uint
+	WorkflowID string
+}
+
+// RetryTarget is one failed message and the stage the retry run resumes it from.
+type RetryTarget struct {
+	ExternalMessageID string
+	Stage             string
+}
+
+// WorkflowRetrySource is the original workflow a retry run is created from.
+// ConnectionID is zero when the mail-account connection of the original run is no longer usable.
+type WorkflowRetrySource struct {
+	WorkflowID   string
+	Status       string
+	DryRun       bool
+	FanOut       bool
+	ConnectionID uint
+	Condition    FetchCondition
+	Failures     []WorkflowStageFailureItem
+}
+
+// WorkflowRetryRepository loads what a retry run needs from the original workflow and earlier stages.
+type WorkflowRetryRepository interface {
+	FindRetrySource(ctx context.Context, userID uint, workflowID string) (WorkflowRetrySource, error)
+	// FindParsedEmails returns the ParsedEmail rows of the latest analysis run for each message.
+	FindParsedEmails(ctx context.Context, userID uint, externalMessageIDs []string) ([]ParsedEmail, error)
+}
+
+// RetryUseCase accepts retry requests for the failed messages of a finished workflow.
+type RetryUseCase interface {
+	Retry(ctx context.Context, cmd RetryCommand) (StartResult, error)
+}
+
+type retryUseCase struct {
+	retryRepository    WorkflowRetryRepository
+	dispatcher         WorkflowDispatcher
+	repository         WorkflowStatusRepository
+	conflictRepository WorkflowConflictRepository
+	lock               WorkflowConnectionLock
+	clock              timewrapper.ClockInterface
+	log                logger.Interface
+}
+
+// NewRetryUseCase creates a use case that queues a new workflow linked to the original one.
+// Like Start, the retry run is accepted under the connection lock only when no active workflow overlaps it.
+func NewRetryUseCase(
+	retryRepository WorkflowRetryRepository,
+	dispatcher WorkflowDispatcher,
+	repository WorkflowStatusRepository,
+	conflictRepository WorkflowConflictRepository,
+	lock WorkflowConnectionLock,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) RetryUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &retryUseCase{
+		retryRepository:    retryRepository,
+		dispatcher:         dispatcher,
+		repository:         repository,
+		conflictRepository: conflictRepository,
+		lock:               lock,
+		clock:              clock,
+		log:                log.With(logger.Component("manual_mail_workflow_retry_usecase")),
+	}
+}
+
+// Retry validates that the original workflow has retryable failures and dispatches a linked retry run.
+// The retry run keeps the label and period of the original and only processes the failed messages.
+func (uc *retryUseCase) Retry(ctx context.Context, cmd RetryCommand) (StartResult, error) {
+	if ctx == nil {
+		return StartResult{}, logger.ErrNilContext
+	}
+	if uc.retryRepository == nil {
+		return StartResult{}, errors.New("workflow_retry_repository is not configured")
+	}
+	if uc.dispatcher == nil {
+		return StartResult{}, errors.New("workflow_dispatcher is not configured")
+	}
+	if uc.repository == nil {
+		return StartResult{}, errors.New("workflow_status_repository is not configured")
+	}
+	if uc.conflictRepository == nil {
+		return StartResult{}, errors.New("workflow_conflict_repository is not configured")
+	}
+	if uc.lock == nil {
+		return StartResult{}, errors.New("workflow_connection_lock is not configured")
+	}
+
+	cmd.WorkflowID = strings.TrimSpace(cmd.WorkflowID)
+	if cmd.UserID == 0 {
+		return StartResult{}, fmt.Errorf("%w: user_id is required", ErrInvalidCommand)
+	}
+	if cmd.WorkflowID == "" {
+		return StartResult{}, fmt.Errorf("%w: workflow_id is required", ErrInvalidCommand)
+	}
+
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+
+	source, err := uc.retryRepository.FindRetrySource(ctx, cmd.UserID, cmd.WorkflowID)
+	if err != nil {
+		return StartResult{}, err
+	}
+	if source.FanOut {
+		return StartResult{}, ErrWorkflowFanOutParent
+	}
+	if !isRetryableWorkflowStatus(source.Status) {
+		return StartResult{}, fmt.Errorf("%w: status=%s", ErrWorkflowNotRetryable, source.Status)
+	}
+	if source.DryRun {
+		return StartResult{}, fmt.Errorf("%w: dry run", ErrWorkflowNotRetryable)
+	}
+	targets := selectRetryTargets(source.Failures)
+	if len(targets) == 0 {
+		return StartResult{}, ErrWorkflowNoRetryTargets
+	}
+	if source.ConnectionID == 0 {
+		return StartResult{}, ErrWorkflowRetryConnectionUnavailable
+	}
+
+	workflowID, err := newWorkflowID()
+	if err != nil {
+		return StartResult{}, fmt.Errorf("failed to generate workflow id: %w", err)
+	}
+
+	condition := source.Condition.Normalize()
+	release, err := acquireWorkflowConnection(ctx, uc.lock, uc.conflictRepository,
+		activeWorkflowQuery(cmd.UserID, source.ConnectionID, condition), workflowID, reqLog)
+	if release != nil {
+		defer release()
+	}
+	if err != nil {
+		return StartResult{}, err
+	}
+
+	result, err := enqueueWorkflow(ctx, uc.repository, uc.dispatcher, uc.clock, reqLog, QueuedWorkflowHistory{
+		WorkflowID:        workflowID,
+		RetryOfWorkflowID: source.WorkflowID,
+		UserID:            cmd.UserID,
+		ConnectionID:      source.ConnectionID,
+		LabelName:         condition.LabelName,
+		SinceAt:           condition.Since,
+		UntilAt:           condition.Until,
+		Filter:            condition.Filter,
+		QueuedAt:          uc.clock.Now().UTC(),
+	})
+	if err != nil {
+		return StartResult{}, err
+	}
+
+	reqLog.Info("manual_mail_workflow_retry_accepted",
+		logger.UserID(cmd.UserID),
+		logger.Uint("connection_id", source.ConnectionID),
+		logger.String("workflow_id", result.WorkflowID),
+		logger.String("retry_of_workflow_id", source.WorkflowID),
+		logger.Int("retry_target_count", len(targets)),
+	)
+
+	return result, nil
+}
+
+func isRetryableWorkflowStatus(status string) bool {
+	switch status {
+	case WorkflowStatusPartialSuccess, WorkflowStatusFailed, WorkflowStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// selectRetryTargets picks, for each failed message, the earliest stage it failed in.
+// duplicate_billing is excluded because the billing already exists and a retry cannot change it.
+func selectRetryTargets(failures []WorkflowStageFailureItem) []RetryTarget {
+	stageIndexByMessageID := make(map[string]int, len(failures))
+	order := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		if failure.ExternalMessageID == nil || failure.ReasonCode == reasonCodeDuplicateBilling {
+			continue
+		}
+		externalMessageID := strings.TrimSpace(*failure.ExternalMessageID)
+		stageIndex := workflowStageIndex(failure.Stage)
+		if externalMessageID == "" || stageIndex < 0 {
+			continue
+		}
+
+		current, seen := stageIndexByMessageID[externalMessageID]
+		if !seen {
+			order = append(order, externalMessageID)
+			stageIndexByMessageID[externalMessageID] = stageIndex
+			continue
+		}
+		if stageIndex < current {
+			stageIndexByMessageID[externalMessageID] = stageIndex
+		}
+	}
+
+	targets := make([]RetryTarget, 0, len(order))
+	for _, externalMessageID := range order {
+		targets = append(targets, RetryTarget{
+			ExternalMessageID: externalMessageID,
+			Stage:             workflowStages[stageIndexByMessageID[externalMessageID]],
+		})
+	}
+	return targets
+}
+
+// stageSeeds は retry run や再開した run で各 stage に途中から投入する入力。
+type stageSeeds struct {
+	retry bool
+	// dryRun は dry-run の実行で、stage handoff を保存しない。
+	dryRun bool
+	// resumedFrom は再開に使った handoff を記録した完了済み stage。空なら再開ではない。
+	resumedFrom string
+	// priorFailures は再開前に完了した stage に失敗があったかどうか。最終 status の判定に使う。
+	priorFailures              bool
+	fetchMessageIDs            []string
+	parsedEmails               []ParsedEmail
+	resolvedItems              []ResolvedItem
+	eligibleItems              []EligibleItem
+	vendorResolutionFailures   []VendorResolutionFailure
+	billingEligibilityFailures []BillingEligibilityFailure
+	billingFailures            []BillingFailure
+}
+
+// loadRetrySeeds は元 workflow の失敗メールを、失敗した stage の入力に組み立て直す。
+// fetch / analysis で失敗したメールはメール本文を保存していないため、メールIDを指定して取得し直す。
+// billingeligibility / billing で失敗したメールは、保存済みの ParsedEmail から前の stage の出力を
+// 作り直す。vendorresolution は get-or-create、billingeligibility は副作用のない判定なので、
+// 組み立て直しのために呼んでも件数や結果は二重に記録されない。
+func (uc *useCase) loadRetrySeeds(ctx context.Context, job DispatchJob) (stageSeeds, error) {
+	retryOfWorkflowID := strings.TrimSpace(job.RetryOfWorkflowID)
+	if retryOfWorkflowID == "" {
+		return stageSeeds{}, nil
+	}
+	if uc.retryRepository == nil {
+		return stageSeeds{}, errors.New("workflow_retry_repository is not configured")
+	}
+
+	source, err := uc.retryRepository.FindRetrySource(ctx, job.UserID, retryOfWorkflowID)
+	if err != nil {
+		return stageSeeds{}, err
+	}
+
+	seeds := stageSeeds{retry: true}
+	laterTargets := make([]RetryTarget, 0)
+	for _, target := range selectRetryTargets(source.Failures) {
+		switch target.Stage {
+		case workflowStageFetch, workflowStageAnalysis:
+			seeds.fetchMessageIDs = append(seeds.fetchMessageIDs, target.ExternalMessageID)
+		default:
+			laterTargets = append(laterTargets, target)
+		}
+	}
+	if len(laterTargets) == 0 {
+		return seeds, nil
+	}
+
+	externalMessageIDs := make([]string, 0, len(laterTargets))
+	for _, target := range laterTargets {
+		externalMessageIDs = append(externalMessageIDs, target.ExternalMessageID)
+	}
+	parsedEmails, err := uc.retryRepository.FindParsedEmails(ctx, job.UserID, externalMessageIDs)
+	if err != nil {
+		return stageSeeds{}, err
+	}
+	parsedByMessageID := make(map[string][]ParsedEmail, len(parsedEmails))
+	for _, parsedEmail := range parsedEmails {
+		parsedByMessageID[parsedEmail.ExternalMessageID] = append(parsedByMessageID[parsedEmail.ExternalMessageID], parsedEmail)
+	}
+
+	resolutionInputs := make([]ParsedEmail, 0)
+	resolutionTargets := make([]RetryTarget, 0, len(laterTargets))
+	for _, target := range laterTargets {
+		parsed := parsedByMessageID[target.ExternalMessageID]
+		switch {
+		case len(parsed) == 0:
+			seeds.addMissingSource(target)
+		case target.Stage == workflowStageVendorResolution:
+			seeds.parsedEmails = append(seeds.parsedEmails, parsed...)
+		default:
+			resolutionInputs = append(resolutionInputs, parsed...)
+			resolutionTargets = append(resolutionTargets, target)
+		}
+	}
+	if len(resolutionTargets) == 0 {
+		return seeds, nil
+	}
+
+	vendorResolutionResult, err := uc.vendorResolutionStage.Execute(ctx, VendorResolutionCommand{
+		UserID:       job.UserID,
+		ParsedEmails: resolutionInputs,
+	})
+	if err != nil {
+		return stageSeeds{}, err
+	}
+	resolvedByMessageID := make(map[string][]ResolvedItem, len(vendorResolutionResult.ResolvedItems))
+	for _, item := range vendorResolutionResult.ResolvedItems {
+		resolvedByMessageID[item.ExternalMessageID] = append(resolvedByMessageID[item.ExternalMessageID], item)
+	}
+
+	eligibilityInputs := make([]ResolvedItem, 0)
+	billingTargets := make([]RetryTarget, 0, len(resolutionTargets))
+	for _, target := range resolutionTargets {
+		resolved := resolvedByMessageID[target.ExternalMessageID]
+		switch {
+		case len(resolved) == 0:
+			seeds.addMissingSource(target)
+		case target.Stage == workflowStageBillingEligibility:
+			seeds.resolvedItems = append(seeds.resolvedItems, resolved...)
+		default:
+			eligibilityInputs = append(eligibilityInputs, resolved...)
+			billingTargets = append(billingTargets, target)
+		}
+	}
+	if len(billingTargets) == 0 {
+		return seeds, nil
+	}
+
+	billingEligibilityResult, err := uc.billingEligibilityStage.Execute(ctx, BillingEligibilityCommand{
+		UserID:        job.UserID,
+		ResolvedItems: eligibilityInputs,
+	})
+	if err != nil {
+		return stageSeeds{}, err
+	}
+	eligibleByMessageID := make(map[string][]EligibleItem, len(billingEligibilityResult.EligibleItems))
+	for _, item := range billingEligibilityResult.EligibleItems {
+		eligibleByMessageID[item.ExternalMessageID] = append(eligibleByMessageID[item.ExternalMessageID], item)
+	}
+	for _, target := range billingTargets {
+		eligible := eligibleByMessageID[target.ExternalMessageID]
+		if len(eligible) == 0 {
+			seeds.addMissingSource(target)
+			continue
+		}
+		seeds.eligibleItems = append(seeds.eligibleItems, eligible...)
+	}
+
+	return seeds, nil
+}
+
+// addMissingSource は前回の処理結果から入力を組み立て直せなかったメールを、再開する stage の技術的失敗として残す。
+func (s *stageSeeds) addMissingSource(target RetryTarget) {
+	s.addFailure(HandoffFailure{
+		Stage:             target.Stage,
+		ExternalMessageID: target.ExternalMessageID,
+		Code:              reasonCodeRetrySourceMissing,
+		Message:           externalMessageIDText(target.ExternalMessageID) + " の再実行に必要な前回の処理結果が見つかりませんでした。",
+	})
+}
+
+// addFailure は後続 stage に持ち越す技術的失敗を、その stage の失敗として積む。
+func (s *stageSeeds) addFailure(failure HandoffFailure) {
+	switch failure.Stage {
+	case workflowStageVendorResolution:
+		s.vendorResolutionFailures = append(s.vendorResolutionFailures, VendorResolutionFailure{
+			ExternalMessageID: failure.ExternalMessageID,
+			Stage:             workflowStageVendorResolution,
+			Code:              failure.Code,
+			Message:           failure.Message,
+		})
+	case workflowStageBillingEligibility:
+		s.billingEligibilityFailures = append(s.billingEligibilityFailures, BillingEligibilityFailure{
+			ExternalMessageID: failure.ExternalMessageID,
+			Code:              failure.Code,
+			Message:           failure.Message,
+		})
+	case workflowStageBilling:
+		s.billingFailures = append(s.billingFailures, BillingFailure{
+			ExternalMessageID: failure.ExternalMessageID,
+			Stage:             workflowStageBilling,
+			Code:              failure.Code,
+			Message:           failure.Message,
+		})
+	}
+}
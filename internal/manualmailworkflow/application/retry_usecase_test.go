@@ -0,0 +1,452 @@
+package application
+
+import (
+	commondomain "business/internal/common/domain"
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stubWorkflowRetryRepository struct {
+	findRetrySource  func(ctx context.Context, userID uint, workflowID string) (WorkflowRetrySource, error)
+	findParsedEmails func(ctx context.Context, userID uint, externalMessageIDs []string) ([]ParsedEmail, error)
+}
+
+func (s *stubWorkflowRetryRepository) FindRetrySource(ctx context.Context, userID uint, workflowID string) (WorkflowRetrySource, error) {
+	return s.findRetrySource(ctx, userID, workflowID)
+}
+
+func (s *stubWorkflowRetryRepository) FindParsedEmails(ctx context.Context, userID uint, externalMessageIDs []string) ([]ParsedEmail, error) {
+	if s.findParsedEmails == nil {
+		return nil, nil
+	}
+	return s.findParsedEmails(ctx, userID, externalMessageIDs)
+}
+
+func retrySourceFixture(status string, failures ...WorkflowStageFailureItem) WorkflowRetrySource {
+	return WorkflowRetrySource{
+		WorkflowID:   "wf-original",
+		Status:       status,
+		ConnectionID: 12,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+		Failures: failures,
+	}
+}
+
+func retryFailureFixture(stage string, externalMessageID string, reasonCode string) WorkflowStageFailureItem {
+	return WorkflowStageFailureItem{
+		Stage:             stage,
+		ExternalMessageID: stringPtr(externalMessageID),
+		ReasonCode:        reasonCode,
+		Message:           "failed",
+	}
+}
+
+func TestRetryUseCase_Retry_QueuesLinkedWorkflow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	var queued QueuedWorkflowHistory
+	var dispatched DispatchJob
+	uc := NewRetryUseCase(
+		&stubWorkflowRetryRepository{
+			findRetrySource: func(ctx context.Context, userID uint, workflowID string) (WorkflowRetrySource, error) {
+				if userID != 7 || workflowID != "wf-original" {
+					t.Fatalf("unexpected retry source target: user=%d workflow=%q", userID, workflowID)
+				}
+				return retrySourceFixture(WorkflowStatusPartialSuccess,
+					retryFailureFixture(workflowStageFetch, "msg-1", "fetch_detail_failed"),
+				), nil
+			},
+		},
+		&stubWorkflowDispatcher{
+			dispatch: func(ctx context.Context, job DispatchJob) error {
+				dispatched = job
+				return nil
+			},
+		},
+		&stubWorkflowStatusRepository{
+			createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+				queued = cmd
+				return WorkflowHistoryRef{HistoryID: 55, WorkflowID: cmd.WorkflowID}, nil
+			},
+		},
+		&stubWorkflowConflictRepository{},
+		&stubWorkflowConnectionLock{},
+		&fixedClock{now: now},
+		logger.NewNop(),
+	)
+
+	result, err := uc.Retry(context.Background(), RetryCommand{UserID: 7, WorkflowID: " wf-original "})
+	if err != nil {
+		t.Fatalf("Retry returned error: %v", err)
+	}
+	if result.Status != WorkflowStatusQueued || result.WorkflowID == "" || result.WorkflowID == "wf-original" {
+		t.Fatalf("unexpected retry result: %+v", result)
+	}
+	if queued.RetryOfWorkflowID != "wf-original" || queued.ConnectionID != 12 || queued.LabelName != "billing" || !queued.QueuedAt.Equal(now) {
+		t.Fatalf("unexpected queued history: %+v", queued)
+	}
+	if dispatched.HistoryID != 55 || dispatched.RetryOfWorkflowID != "wf-original" || dispatched.WorkflowID != result.WorkflowID {
+		t.Fatalf("unexpected dispatched job: %+v", dispatched)
+	}
+}
+
+func TestRetryUseCase_Retry_RejectsUnretryableWorkflow(t *testing.T) {
+	t.Parallel()
+
+	unavailable := retrySourceFixture(WorkflowStatusFailed, retryFailureFixture(workflowStageAnalysis, "msg-1", "analysis_failed"))
+	unavailable.ConnectionID = 0
+	dryRun := retrySourceFixture(WorkflowStatusPartialSuccess, retryFailureFixture(workflowStageAnalysis, "msg-1", "analysis_failed"))
+	dryRun.DryRun = true
+	fanOut := retrySourceFixture(WorkflowStatusPartialSuccess)
+	fanOut.FanOut = true
+
+	tests := []struct {
+		name    string
+		cmd     RetryCommand
+		source  WorkflowRetrySource
+		wantErr error
+	}{
+		{
+			name:    "missing workflow id",
+			cmd:     RetryCommand{UserID: 7, WorkflowID: " "},
+			wantErr: ErrInvalidCommand,
+		},
+		{
+			name:    "still running",
+			cmd:     RetryCommand{UserID: 7, WorkflowID: "wf-original"},
+			source:  retrySourceFixture(WorkflowStatusRunning, retryFailureFixture(workflowStageFetch, "msg-1", "fetch_detail_failed")),
+			wantErr: ErrWorkflowNotRetryable,
+		},
+		{
+			name:    "succeeded",
+			cmd:     RetryCommand{UserID: 7, WorkflowID: "wf-original"},
+			source:  retrySourceFixture(WorkflowStatusSucceeded),
+			wantErr: ErrWorkflowNotRetryable,
+		},
+		{
+			name: "only duplicates and message-less failures",
+			cmd:  RetryCommand{UserID: 7, WorkflowID: "wf-original"},
+			source: retrySourceFixture(WorkflowStatusPartialSuccess,
+				retryFailureFixture(workflowStageBilling, "msg-1", reasonCodeDuplicateBilling),
+				WorkflowStageFailureItem{Stage: workflowStageFetch, ReasonCode: "fetch_list_failed"},
+			),
+			wantErr: ErrWorkflowNoRetryTargets,
+		},
+		{
+			name:    "dry run",
+			cmd:     RetryCommand{UserID: 7, WorkflowID: "wf-original"},
+			source:  dryRun,
+			wantErr: ErrWorkflowNotRetryable,
+		},
+		{
+			name:    "fan-out parent",
+			cmd:     RetryCommand{UserID: 7, WorkflowID: "wf-original"},
+			source:  fanOut,
+			wantErr: ErrWorkflowFanOutParent,
+		},
+		{
+			name:    "connection unavailable",
+			cmd:     RetryCommand{UserID: 7, WorkflowID: "wf-original"},
+			source:  unavailable,
+			wantErr: ErrWorkflowRetryConnectionUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewRetryUseCase(
+				&stubWorkflowRetryRepository{
+					findRetrySource: func(ctx context.Context, userID uint, workflowID string) (WorkflowRetrySource, error) {
+						return tt.source, nil
+					},
+				},
+				&stubWorkflowDispatcher{
+					dispatch: func(ctx context.Context, job DispatchJob) error {
+						t.Fatal("dispatcher must not be called")
+						return nil
+					},
+				},
+				&stubWorkflowStatusRepository{
+					createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+						t.Fatal("history must not be created")
+						return WorkflowHistoryRef{}, nil
+					},
+				},
+				&stubWorkflowConflictRepository{},
+				&stubWorkflowConnectionLock{},
+				&fixedClock{now: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)},
+				logger.NewNop(),
+			)
+
+			_, err := uc.Retry(context.Background(), tt.cmd)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRetryUseCase_Retry_RejectsOverlappingActiveWorkflow(t *testing.T) {
+	t.Parallel()
+
+	lock := &stubWorkflowConnectionLock{}
+	source := retrySourceFixture(WorkflowStatusFailed, retryFailureFixture(workflowStageAnalysis, "msg-1", "analysis_failed"))
+	source.Condition.Filter = FetchFilter{IncludeLabelNames: []string{"receipts"}}
+	uc := NewRetryUseCase(
+		&stubWorkflowRetryRepository{
+			findRetrySource: func(ctx context.Context, userID uint, workflowID string) (WorkflowRetrySource, error) {
+				return source, nil
+			},
+		},
+		&stubWorkflowDispatcher{
+			dispatch: func(ctx context.Context, job DispatchJob) error {
+				t.Fatal("dispatcher must not be called for a conflicting retry")
+				return nil
+			},
+		},
+		&stubWorkflowStatusRepository{
+			createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+				t.Fatal("history must not be created for a conflicting retry")
+				return WorkflowHistoryRef{}, nil
+			},
+		},
+		&stubWorkflowConflictRepository{
+			findActiveOverlapping: func(ctx context.Context, query ActiveWorkflowQuery) (string, bool, error) {
+				if _, held := lock.held[12]; !held {
+					t.Fatal("conflict check must run while the connection lock is held")
+				}
+				if query.ConnectionID != 12 || !reflect.DeepEqual(query.LabelNames, []string{"billing", "receipts"}) {
+					t.Fatalf("unexpected conflict query: %+v", query)
+				}
+				return "wf-active", true, nil
+			},
+		},
+		lock,
+		&fixedClock{now: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)},
+		logger.NewNop(),
+	)
+
+	_, err := uc.Retry(context.Background(), RetryCommand{UserID: 7, WorkflowID: "wf-original"})
+	if !errors.Is(err, ErrWorkflowConflict) {
+		t.Fatalf("expected ErrWorkflowConflict, got %v", err)
+	}
+	if len(lock.held) != 0 || len(lock.released) != 1 {
+		t.Fatalf("expected the lock to be released, held=%v released=%v", lock.held, lock.released)
+	}
+}
+
+func TestSelectRetryTargets_KeepsEarliestFailedStagePerMessage(t *testing.T) {
+	t.Parallel()
+
+	targets := selectRetryTargets([]WorkflowStageFailureItem{
+		retryFailureFixture(workflowStageBilling, "msg-1", "billing_failed"),
+		retryFailureFixture(workflowStageVendorResolution, "msg-1", "vendor_resolution_failed"),
+		retryFailureFixture(workflowStageAnalysis, " msg-2 ", "analysis_failed"),
+		retryFailureFixture(workflowStageBilling, "msg-3", reasonCodeDuplicateBilling),
+		retryFailureFixture("unknown", "msg-4", "unknown"),
+	})
+
+	want := []RetryTarget{
+		{ExternalMessageID: "msg-1", Stage: workflowStageVendorResolution},
+		{ExternalMessageID: "msg-2", Stage: workflowStageAnalysis},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Fatalf("unexpected retry targets: %+v", targets)
+	}
+}
+
+func TestUseCaseExecute_RetryResumesEachMessageFromFailedStage(t *testing.T) {
+	t.Parallel()
+
+	parsedEmail := func(id uint, externalMessageID string) ParsedEmail {
+		return ParsedEmail{
+			ParsedEmailID:     id,
+			EmailID:           id + 100,
+			ExternalMessageID: externalMessageID,
+			Data:              commondomain.ParsedEmail{BillingNumber: stringPtr("INV-" + externalMessageID)},
+		}
+	}
+	resolvedItem := func(parsed ParsedEmail) ResolvedItem {
+		return ResolvedItem{
+			ParsedEmailID:     parsed.ParsedEmailID,
+			EmailID:           parsed.EmailID,
+			ExternalMessageID: parsed.ExternalMessageID,
+			VendorID:          9,
+			Data:              parsed.Data,
+		}
+	}
+
+	var fetchCommand FetchCommand
+	var analyzedMessageIDs []string
+	var resolutionCalls [][]string
+	var eligibilityCalls [][]string
+	var billedMessageIDs []string
+	var savedProgress []StageProgress
+	completedStatus := ""
+
+	uc := NewUseCase(
+		&stubFetchStage{
+			execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+				fetchCommand = cmd
+				return FetchResult{
+					CreatedEmails:  []CreatedEmail{{EmailID: 201, ExternalMessageID: "msg-fetch"}},
+					ExistingEmails: []CreatedEmail{{EmailID: 202, ExternalMessageID: "msg-analysis"}},
+				}, nil
+			},
+		},
+		&stubAnalyzeStage{
+			execute: func(ctx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+				for _, email := range cmd.Emails {
+					analyzedMessageIDs = append(analyzedMessageIDs, email.ExternalMessageID)
+				}
+				return AnalyzeResult{
+					ParsedEmails:     []ParsedEmail{parsedEmail(1, "msg-fetch"), parsedEmail(2, "msg-analysis")},
+					ParsedEmailCount: 2,
+				}, nil
+			},
+		},
+		&stubVendorResolutionStage{
+			execute: func(ctx context.Context, cmd VendorResolutionCommand) (VendorResolutionResult, error) {
+				ids := make([]string, 0, len(cmd.ParsedEmails))
+				result := VendorResolutionResult{}
+				for _, parsed := range cmd.ParsedEmails {
+					ids = append(ids, parsed.ExternalMessageID)
+					result.ResolvedItems = append(result.ResolvedItems, resolvedItem(parsed))
+				}
+				result.ResolvedCount = len(result.ResolvedItems)
+				resolutionCalls = append(resolutionCalls, ids)
+				return result, nil
+			},
+		},
+		&stubBillingEligibilityStage{
+			execute: func(ctx context.Context, cmd BillingEligibilityCommand) (BillingEligibilityResult, error) {
+				ids := make([]string, 0, len(cmd.ResolvedItems))
+				result := BillingEligibilityResult{}
+				for _, item := range cmd.ResolvedItems {
+					ids = append(ids, item.ExternalMessageID)
+					result.EligibleItems = append(result.EligibleItems, EligibleItem{
+						ParsedEmailID:     item.ParsedEmailID,
+						EmailID:           item.EmailID,
+						ExternalMessageID: item.ExternalMessageID,
+						VendorID:          item.VendorID,
+						BillingNumber:     *item.Data.BillingNumber,
+					})
+				}
+				result.EligibleCount = len(result.EligibleItems)
+				eligibilityCalls = append(eligibilityCalls, ids)
+				return result, nil
+			},
+		},
+		&stubBillingStage{
+			execute: func(ctx context.Context, cmd BillingCommand) (BillingResult, error) {
+				result := BillingResult{}
+				for _, item := range cmd.EligibleItems {
+					billedMessageIDs = append(billedMessageIDs, item.ExternalMessageID)
+					result.CreatedItems = append(result.CreatedItems, BillingCreatedItem{
+						ParsedEmailID:     item.ParsedEmailID,
+						EmailID:           item.EmailID,
+						ExternalMessageID: item.ExternalMessageID,
+					})
+				}
+				result.CreatedCount = len(result.CreatedItems)
+				return result, nil
+			},
+		},
+		&stubWorkflowStatusRepository{
+			saveStage: func(ctx context.Context, progress StageProgress) error {
+				savedProgress = append(savedProgress, progress)
+				return nil
+			},
+			complete: func(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error {
+				completedStatus = status
+				return nil
+			},
+		},
+		&stubWorkflowRetryRepository{
+			findRetrySource: func(ctx context.Context, userID uint, workflowID string) (WorkflowRetrySource, error) {
+				if workflowID != "wf-original" {
+					t.Fatalf("unexpected retry source: %q", workflowID)
+				}
+				return retrySourceFixture(WorkflowStatusPartialSuccess,
+					retryFailureFixture(workflowStageFetch, "msg-fetch", "fetch_detail_failed"),
+					retryFailureFixture(workflowStageAnalysis, "msg-analysis", "analysis_failed"),
+					retryFailureFixture(workflowStageVendorResolution, "msg-vendor", "vendor_resolution_failed"),
+					retryFailureFixture(workflowStageBillingEligibility, "msg-eligibility", "billing_eligibility_failed"),
+					retryFailureFixture(workflowStageBilling, "msg-billing", "billing_failed"),
+					retryFailureFixture(workflowStageBilling, "msg-missing", "billing_failed"),
+				), nil
+			},
+			findParsedEmails: func(ctx context.Context, userID uint, externalMessageIDs []string) ([]ParsedEmail, error) {
+				want := []string{"msg-vendor", "msg-eligibility", "msg-billing", "msg-missing"}
+				if !reflect.DeepEqual(externalMessageIDs, want) {
+					t.Fatalf("unexpected parsed email lookup: %+v", externalMessageIDs)
+				}
+				return []ParsedEmail{parsedEmail(3, "msg-vendor"), parsedEmail(4, "msg-eligibility"), parsedEmail(5, "msg-billing")}, nil
+			},
+		},
+		nil,
+		nil,
+		&fixedClock{now: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)},
+		logger.NewNop(),
+	)
+
+	result, err := uc.Execute(context.Background(), DispatchJob{
+		HistoryID:         2,
+		WorkflowID:        "wf-retry",
+		RetryOfWorkflowID: "wf-original",
+		UserID:            7,
+		ConnectionID:      12,
+		Condition:         retrySourceFixture(WorkflowStatusPartialSuccess).Condition,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(fetchCommand.MessageIDs, []string{"msg-fetch", "msg-analysis"}) || !fetchCommand.IncludeExistingEmails {
+		t.Fatalf("unexpected fetch command: %+v", fetchCommand)
+	}
+	if !reflect.DeepEqual(analyzedMessageIDs, []string{"msg-fetch", "msg-analysis"}) {
+		t.Fatalf("unexpected analyzed messages: %+v", analyzedMessageIDs)
+	}
+	wantResolutionCalls := [][]string{
+		{"msg-eligibility", "msg-billing"},
+		{"msg-fetch", "msg-analysis", "msg-vendor"},
+	}
+	if !reflect.DeepEqual(resolutionCalls, wantResolutionCalls) {
+		t.Fatalf("unexpected vendor resolution calls: %+v", resolutionCalls)
+	}
+	wantEligibilityCalls := [][]string{
+		{"msg-billing"},
+		{"msg-fetch", "msg-analysis", "msg-vendor", "msg-eligibility"},
+	}
+	if !reflect.DeepEqual(eligibilityCalls, wantEligibilityCalls) {
+		t.Fatalf("unexpected billing eligibility calls: %+v", eligibilityCalls)
+	}
+	if !reflect.DeepEqual(billedMessageIDs, []string{"msg-fetch", "msg-analysis", "msg-vendor", "msg-eligibility", "msg-billing"}) {
+		t.Fatalf("unexpected billed messages: %+v", billedMessageIDs)
+	}
+
+	if len(result.Billing.Failures) != 1 || result.Billing.Failures[0].ExternalMessageID != "msg-missing" || result.Billing.Failures[0].Code != reasonCodeRetrySourceMissing {
+		t.Fatalf("expected missing source failure for msg-missing, got %+v", result.Billing.Failures)
+	}
+	if completedStatus != WorkflowStatusPartialSuccess {
+		t.Fatalf("expected partial_success, got %q", completedStatus)
+	}
+
+	billingProgress := savedProgress[len(savedProgress)-1]
+	if billingProgress.Stage != workflowStageBilling || billingProgress.SuccessCount != 5 || billingProgress.TechnicalFailureCount != 1 {
+		t.Fatalf("unexpected billing progress: %+v", billingProgress)
+	}
+}
@@ -0,0 +1,132 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultScheduleDispatchBatchSize = 20
+
+// WorkflowScheduleDispatchRepository は起動時刻を過ぎた schedule を取り出し、起動結果を記録する。
+// ClaimDue は返す schedule の next_run_at を次回時刻へ進めた状態で確定させるため、
+// 複数インスタンスが同時に呼んでも同じ起動枠を二重に返さない。返す schedule の NextRunAt は進めた後の値。
+type WorkflowScheduleDispatchRepository interface {
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]WorkflowSchedule, error)
+	RecordRun(ctx context.Context, scheduleID uint64, workflowID string, errorMessage string) error
+}
+
+// ScheduleDispatchUseCase は起動時刻を迎えた schedule を workflow として開始する。
+type ScheduleDispatchUseCase interface {
+	DispatchDue(ctx context.Context) (int, error)
+}
+
+type scheduleDispatchUseCase struct {
+	repository WorkflowScheduleDispatchRepository
+	starter    StartUseCase
+	clock      timewrapper.ClockInterface
+	log        logger.Interface
+}
+
+// NewScheduleDispatchUseCase creates a use case that starts workflows for due schedules.
+func NewScheduleDispatchUseCase(
+	repository WorkflowScheduleDispatchRepository,
+	starter StartUseCase,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) ScheduleDispatchUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &scheduleDispatchUseCase{
+		repository: repository,
+		starter:    starter,
+		clock:      clock,
+		log:        log.With(logger.Component("manual_mail_workflow_schedule_dispatch_usecase")),
+	}
+}
+
+// DispatchDue starts one workflow per due schedule through the normal start path and
+// returns the number of workflows accepted. A failed start is recorded on the schedule
+// and does not stop the remaining schedules.
+func (uc *scheduleDispatchUseCase) DispatchDue(ctx context.Context) (int, error) {
+	if ctx == nil {
+		return 0, logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return 0, errors.New("workflow_schedule_dispatch_repository is not configured")
+	}
+	if uc.starter == nil {
+		return 0, errors.New("start_usecase is not configured")
+	}
+
+	now := uc.clock.Now().UTC()
+	schedules, err := uc.repository.ClaimDue(ctx, now, defaultScheduleDispatchBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	started := 0
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			return started, ctx.Err()
+		}
+		if uc.dispatchSchedule(ctx, schedule, now) {
+			started++
+		}
+	}
+
+	return started, nil
+}
+
+func (uc *scheduleDispatchUseCase) dispatchSchedule(ctx context.Context, schedule WorkflowSchedule, now time.Time) bool {
+	scheduleCtx := ctx
+	if next, err := logger.ContextWithUserID(ctx, schedule.UserID); err == nil {
+		scheduleCtx = next
+	}
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(scheduleCtx); err == nil {
+		reqLog = withContext
+	}
+
+	result, startErr := uc.starter.Start(scheduleCtx, Command{
+		UserID:       schedule.UserID,
+		ConnectionID: schedule.ConnectionID,
+		Condition:    schedule.Condition(now),
+	})
+
+	errorMessage := ""
+	if startErr != nil {
+		errorMessage = localizedWorkflowErrorMessage("", startErr)
+		reqLog.Error("manual_mail_workflow_schedule_start_failed",
+			logger.Uint("schedule_id", uint(schedule.ID)),
+			logger.Uint("connection_id", schedule.ConnectionID),
+			logger.Err(startErr),
+		)
+	}
+	if err := uc.repository.RecordRun(scheduleCtx, schedule.ID, result.WorkflowID, errorMessage); err != nil {
+		reqLog.Error("manual_mail_workflow_schedule_record_run_failed",
+			logger.Uint("schedule_id", uint(schedule.ID)),
+			logger.String("workflow_id", result.WorkflowID),
+			logger.Err(err),
+		)
+	}
+	if startErr != nil {
+		return false
+	}
+
+	reqLog.Info("manual_mail_workflow_schedule_fired",
+		logger.Uint("schedule_id", uint(schedule.ID)),
+		logger.Uint("connection_id", schedule.ConnectionID),
+		logger.String("workflow_id", result.WorkflowID),
+		logger.String("next_run_at", schedule.NextRunAt.Format(time.RFC3339)),
+	)
+
+	return true
+}
@@ -0,0 +1,129 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubWorkflowScheduleDispatchRepository struct {
+	claimDue  func(ctx context.Context, now time.Time, limit int) ([]WorkflowSchedule, error)
+	recordRun func(ctx context.Context, scheduleID uint64, workflowID string, errorMessage string) error
+}
+
+func (s *stubWorkflowScheduleDispatchRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]WorkflowSchedule, error) {
+	return s.claimDue(ctx, now, limit)
+}
+
+func (s *stubWorkflowScheduleDispatchRepository) RecordRun(ctx context.Context, scheduleID uint64, workflowID string, errorMessage string) error {
+	if s.recordRun == nil {
+		return nil
+	}
+	return s.recordRun(ctx, scheduleID, workflowID, errorMessage)
+}
+
+type stubStartUseCase struct {
+	start func(ctx context.Context, cmd Command) (StartResult, error)
+}
+
+func (s *stubStartUseCase) Start(ctx context.Context, cmd Command) (StartResult, error) {
+	return s.start(ctx, cmd)
+}
+
+func TestScheduleDispatchUseCase_DispatchDue_StartsWorkflowPerSchedule(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 25, 0, 30, 12, 0, time.UTC)
+	schedules := []WorkflowSchedule{
+		{ID: 1, UserID: 7, ConnectionID: 12, LabelName: "billing", LookbackHours: 24},
+		{ID: 2, UserID: 8, ConnectionID: 13, LabelName: "invoice", LookbackHours: 168},
+	}
+	recorded := map[uint64][2]string{}
+	var commands []Command
+	uc := NewScheduleDispatchUseCase(
+		&stubWorkflowScheduleDispatchRepository{
+			claimDue: func(ctx context.Context, claimedAt time.Time, limit int) ([]WorkflowSchedule, error) {
+				if !claimedAt.Equal(now) || limit != defaultScheduleDispatchBatchSize {
+					t.Fatalf("unexpected claim: now=%s limit=%d", claimedAt, limit)
+				}
+				return schedules, nil
+			},
+			recordRun: func(ctx context.Context, scheduleID uint64, workflowID string, errorMessage string) error {
+				recorded[scheduleID] = [2]string{workflowID, errorMessage}
+				return nil
+			},
+		},
+		&stubStartUseCase{
+			start: func(ctx context.Context, cmd Command) (StartResult, error) {
+				if userID, ok := logger.UserIDFromContext(ctx); !ok || userID != cmd.UserID {
+					t.Fatalf("expected user id %d in context, got %d", cmd.UserID, userID)
+				}
+				commands = append(commands, cmd)
+				if cmd.UserID == 8 {
+					return StartResult{}, errors.New("failed to find connection snapshot")
+				}
+				return StartResult{WorkflowID: "wf-scheduled", Status: WorkflowStatusQueued}, nil
+			},
+		},
+		&fixedClock{now: now},
+		logger.NewNop(),
+	)
+
+	started, err := uc.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("DispatchDue returned error: %v", err)
+	}
+	if started != 1 {
+		t.Fatalf("expected 1 started workflow, got %d", started)
+	}
+	if len(commands) != 2 {
+		t.Fatalf("expected both schedules to be started, got %d", len(commands))
+	}
+
+	first := commands[0]
+	if first.ConnectionID != 12 || first.Condition.LabelName != "billing" {
+		t.Fatalf("unexpected command: %+v", first)
+	}
+	wantUntil := time.Date(2026, 3, 25, 0, 30, 12, 0, time.UTC)
+	if !first.Condition.Until.Equal(wantUntil) || !first.Condition.Since.Equal(wantUntil.Add(-24*time.Hour)) {
+		t.Fatalf("unexpected condition window: %+v", first.Condition)
+	}
+	if !commands[1].Condition.Since.Equal(wantUntil.Add(-168 * time.Hour)) {
+		t.Fatalf("unexpected weekly lookback: %+v", commands[1].Condition)
+	}
+
+	if recorded[1] != [2]string{"wf-scheduled", ""} {
+		t.Fatalf("unexpected success record: %+v", recorded[1])
+	}
+	if recorded[2][0] != "" || recorded[2][1] != "メール取得ワークフローの起動に失敗しました。" {
+		t.Fatalf("unexpected failure record: %+v", recorded[2])
+	}
+}
+
+func TestScheduleDispatchUseCase_DispatchDue_PropagatesClaimError(t *testing.T) {
+	t.Parallel()
+
+	claimErr := errors.New("db down")
+	uc := NewScheduleDispatchUseCase(
+		&stubWorkflowScheduleDispatchRepository{
+			claimDue: func(ctx context.Context, now time.Time, limit int) ([]WorkflowSchedule, error) {
+				return nil, claimErr
+			},
+		},
+		&stubStartUseCase{
+			start: func(ctx context.Context, cmd Command) (StartResult, error) {
+				t.Fatal("Start must not be called when claiming fails")
+				return StartResult{}, nil
+			},
+		},
+		nil,
+		logger.NewNop(),
+	)
+
+	_, err := uc.DispatchDue(context.Background())
+	if !errors.Is(err, claimErr) {
+		t.Fatalf("expected claim error, got %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // コンテナに zoneinfo が無くても schedule の timezone を解決できるようにする。
+)
+
+const (
+	// ScheduleFrequencyDaily は毎日同じ時刻に workflow を起動する schedule。
+	ScheduleFrequencyDaily = "daily"
+	// ScheduleFrequencyWeekly は毎週同じ曜日・時刻に workflow を起動する schedule。
+	ScheduleFrequencyWeekly = "weekly"
+
+	defaultScheduleTimezone = "Asia/Tokyo"
+	minScheduleLookbackHour = 1
+	maxScheduleLookbackHour = 31 * 24
+	scheduleTimeOfDayLayout = "15:04"
+)
+
+var (
+	// ErrInvalidScheduleCommand は schedule の入力が不正なときに返る。
+	ErrInvalidScheduleCommand = errors.New("manual mail workflow schedule command is invalid")
+	// ErrWorkflowScheduleNotFound は schedule がユーザーに存在しないときに返る。
+	ErrWorkflowScheduleNotFound = errors.New("manual mail workflow schedule not found")
+	// ErrScheduleConnectionNotFound は schedule 対象のメール連携がユーザーに存在しないときに返る。
+	ErrScheduleConnectionNotFound = errors.New("manual mail workflow schedule connection not found")
+)
+
+// WorkflowSchedule は connection ごとの定期実行設定。
+type WorkflowSchedule struct {
+	ID             uint64
+	UserID         uint
+	ConnectionID   uint
+	LabelName      string
+	Frequency      string
+	Weekday        *int
+	HourOfDay      int
+	MinuteOfHour   int
+	Timezone       string
+	LookbackHours  int
+	NextRunAt      time.Time
+	LastRunAt      *time.Time
+	LastWorkflowID *string
+	LastError      *string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TimeOfDay は起動時刻を HH:MM 形式で返す。
+func (s WorkflowSchedule) TimeOfDay() string {
+	return fmt.Sprintf("%02d:%02d", s.HourOfDay, s.MinuteOfHour)
+}
+
+// NextRunAfter は after より後で最初に到来する起動時刻を UTC で返す。
+// 起動時刻は schedule の timezone の壁時計で解釈する。
+func (s WorkflowSchedule) NextRunAfter(after time.Time) (time.Time, error) {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: timezone is invalid", ErrInvalidScheduleCommand)
+	}
+
+	local := after.In(location)
+	candidate := time.Date(local.Year(), local.Month(), local.Day(), s.HourOfDay, s.MinuteOfHour, 0, 0, location)
+	stepDays := 1
+	switch s.Frequency {
+	case ScheduleFrequencyDaily:
+	case ScheduleFrequencyWeekly:
+		if s.Weekday == nil {
+			return time.Time{}, fmt.Errorf("%w: weekday is required for weekly schedules", ErrInvalidScheduleCommand)
+		}
+		stepDays = 7
+		offset := (*s.Weekday - int(candidate.Weekday()) + 7) % 7
+		candidate = time.Date(candidate.Year(), candidate.Month(), candidate.Day()+offset, s.HourOfDay, s.MinuteOfHour, 0, 0, location)
+	default:
+		return time.Time{}, fmt.Errorf("%w: frequency is invalid", ErrInvalidScheduleCommand)
+	}
+
+	for !candidate.After(after) {
+		candidate = time.Date(candidate.Year(), candidate.Month(), candidate.Day()+stepDays, s.HourOfDay, s.MinuteOfHour, 0, 0, location)
+	}
+
+	return candidate.UTC(), nil
+}
+
+// Condition は起動時刻 now から lookback を遡った fetch 条件を返す。
+func (s WorkflowSchedule) Condition(now time.Time) FetchCondition {
+	until := now.UTC().Truncate(time.Second)
+	return FetchCondition{
+		LabelName: s.LabelName,
+		Since:     until.Add(-time.Duration(s.LookbackHours) * time.Hour),
+		Until:     until,
+	}
+}
+
+// CreateScheduleCommand は schedule 作成の入力。
+type CreateScheduleCommand struct {
+	UserID       uint
+	ConnectionID uint
+	LabelName    string
+	Frequency    string
+	// Weekday は weekly のときだけ必須。0 が日曜日。
+	Weekday *int
+	// TimeOfDay は Timezone の壁時計での起動時刻 (HH:MM)。
+	TimeOfDay     string
+	Timezone      string
+	LookbackHours int
+}
+
+// Normalize は文字列を整形し、timezone の既定値を補う。
+func (c CreateScheduleCommand) Normalize() CreateScheduleCommand {
+	c.LabelName = strings.TrimSpace(c.LabelName)
+	c.Frequency = strings.ToLower(strings.TrimSpace(c.Frequency))
+	c.TimeOfDay = strings.TrimSpace(c.TimeOfDay)
+	c.Timezone = strings.TrimSpace(c.Timezone)
+	if c.Timezone == "" {
+		c.Timezone = defaultScheduleTimezone
+	}
+	if c.Frequency == ScheduleFrequencyDaily {
+		c.Weekday = nil
+	}
+	return c
+}
+
+// Validate は schedule の入力を検証し、保存する WorkflowSchedule を組み立てる。
+func (c CreateScheduleCommand) Validate() (WorkflowSchedule, error) {
+	if c.UserID == 0 {
+		return WorkflowSchedule{}, fmt.Errorf("%w: user_id is required", ErrInvalidScheduleCommand)
+	}
+	if c.ConnectionID == 0 {
+		return WorkflowSchedule{}, fmt.Errorf("%w: connection_id is required", ErrInvalidScheduleCommand)
+	}
+	if c.LabelName == "" {
+		return WorkflowSchedule{}, fmt.Errorf("%w: label_name is required", ErrInvalidScheduleCommand)
+	}
+	switch c.Frequency {
+	case ScheduleFrequencyDaily:
+	case ScheduleFrequencyWeekly:
+		if c.Weekday == nil || *c.Weekday < int(time.Sunday) || *c.Weekday > int(time.Saturday) {
+			return WorkflowSchedule{}, fmt.Errorf("%w: weekday must be between 0 and 6", ErrInvalidScheduleCommand)
+		}
+	default:
+		return WorkflowSchedule{}, fmt.Errorf("%w: frequency is invalid", ErrInvalidScheduleCommand)
+	}
+	timeOfDay, err := time.Parse(scheduleTimeOfDayLayout, c.TimeOfDay)
+	if err != nil {
+		return WorkflowSchedule{}, fmt.Errorf("%w: time_of_day must be HH:MM", ErrInvalidScheduleCommand)
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return WorkflowSchedule{}, fmt.Errorf("%w: timezone is invalid", ErrInvalidScheduleCommand)
+	}
+	if c.LookbackHours < minScheduleLookbackHour || c.LookbackHours > maxScheduleLookbackHour {
+		return WorkflowSchedule{}, fmt.Errorf("%w: lookback_hours must be between %d and %d", ErrInvalidScheduleCommand, minScheduleLookbackHour, maxScheduleLookbackHour)
+	}
+
+	schedule := WorkflowSchedule{
+		UserID:        c.UserID,
+		ConnectionID:  c.ConnectionID,
+		LabelName:     c.LabelName,
+		Frequency:     c.Frequency,
+		HourOfDay:     timeOfDay.Hour(),
+		MinuteOfHour:  timeOfDay.Minute(),
+		Timezone:      c.Timezone,
+		LookbackHours: c.LookbackHours,
+	}
+	if c.Weekday != nil {
+		weekday := *c.Weekday
+		schedule.Weekday = &weekday
+	}
+
+	return schedule, nil
+}
+
+// DeleteScheduleCommand は削除対象の schedule を指定する。
+type DeleteScheduleCommand struct {
+	UserID     uint
+	ScheduleID uint64
+}
+
+// WorkflowScheduleRepository は schedule の登録・参照・削除を行う。
+// Create は connection がユーザーのものでなければ ErrScheduleConnectionNotFound を返す。
+type WorkflowScheduleRepository interface {
+	Create(ctx context.Context, schedule WorkflowSchedule) (WorkflowSchedule, error)
+	ListByUser(ctx context.Context, userID uint) ([]WorkflowSchedule, error)
+	Delete(ctx context.Context, userID uint, scheduleID uint64) error
+}
+
+// ScheduleUseCase は定期実行設定を管理する。
+type ScheduleUseCase interface {
+	Create(ctx context.Context, cmd CreateScheduleCommand) (WorkflowSchedule, error)
+	List(ctx context.Context, userID uint) ([]WorkflowSchedule, error)
+	Delete(ctx context.Context, cmd DeleteScheduleCommand) error
+}
+
+type scheduleUseCase struct {
+	repository WorkflowScheduleRepository
+	clock      timewrapper.ClockInterface
+	log        logger.Interface
+}
+
+// NewScheduleUseCase creates a workflow schedule management use case.
+func NewScheduleUseCase(
+	repository WorkflowScheduleRepository,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) ScheduleUseCase {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &scheduleUseCase{
+		repository: repository,
+		clock:      clock,
+		log:        log.With(logger.Component("manual_mail_workflow_schedule_usecase")),
+	}
+}
+
+// Create validates the schedule and stores it with the first run time.
+func (uc *scheduleUseCase) Create(ctx context.Context, cmd CreateScheduleCommand) (WorkflowSchedule, error) {
+	if ctx == nil {
+		return WorkflowSchedule{}, logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return WorkflowSchedule{}, errors.New("workflow_schedule_repository is not configured")
+	}
+
+	schedule, err := cmd.Normalize().Validate()
+	if err != nil {
+		return WorkflowSchedule{}, err
+	}
+	nextRunAt, err := schedule.NextRunAfter(uc.clock.Now().UTC())
+	if err != nil {
+		return WorkflowSchedule{}, err
+	}
+	schedule.NextRunAt = nextRunAt
+
+	created, err := uc.repository.Create(ctx, schedule)
+	if err != nil {
+		return WorkflowSchedule{}, err
+	}
+
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+	reqLog.Info("manual_mail_workflow_schedule_created",
+		logger.UserID(created.UserID),
+		logger.Uint("connection_id", created.ConnectionID),
+		logger.Uint("schedule_id", uint(created.ID)),
+		logger.String("frequency", created.Frequency),
+		logger.String("next_run_at", created.NextRunAt.Format(time.RFC3339)),
+	)
+
+	return created, nil
+}
+
+// List returns the schedules of the user.
+func (uc *scheduleUseCase) List(ctx context.Context, userID uint) ([]WorkflowSchedule, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return nil, errors.New("workflow_schedule_repository is not configured")
+	}
+	if userID == 0 {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidScheduleCommand)
+	}
+
+	schedules, err := uc.repository.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if schedules == nil {
+		schedules = []WorkflowSchedule{}
+	}
+
+	return schedules, nil
+}
+
+// Delete removes the schedule. Workflows already started by it are kept in the history.
+func (uc *scheduleUseCase) Delete(ctx context.Context, cmd DeleteScheduleCommand) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if uc.repository == nil {
+		return errors.New("workflow_schedule_repository is not configured")
+	}
+	if cmd.UserID == 0 {
+		return fmt.Errorf("%w: user_id is required", ErrInvalidScheduleCommand)
+	}
+	if cmd.ScheduleID == 0 {
+		return fmt.Errorf("%w: schedule_id is required", ErrInvalidScheduleCommand)
+	}
+
+	if err := uc.repository.Delete(ctx, cmd.UserID, cmd.ScheduleID); err != nil {
+		return err
+	}
+
+	reqLog := uc.log
+	if withContext, err := uc.log.WithContext(ctx); err == nil {
+		reqLog = withContext
+	}
+	reqLog.Info("manual_mail_workflow_schedule_deleted",
+		logger.UserID(cmd.UserID),
+		logger.Uint("schedule_id", uint(cmd.ScheduleID)),
+	)
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubWorkflowScheduleRepository struct {
+	create     func(ctx context.Context, schedule WorkflowSchedule) (WorkflowSchedule, error)
+	listByUser func(ctx context.Context, userID uint) ([]WorkflowSchedule, error)
+	delete     func(ctx context.Context, userID uint, scheduleID uint64) error
+}
+
+func (s *stubWorkflowScheduleRepository) Create(ctx context.Context, schedule WorkflowSchedule) (WorkflowSchedule, error) {
+	return s.create(ctx, schedule)
+}
+
+func (s *stubWorkflowScheduleRepository) ListByUser(ctx context.Context, userID uint) ([]WorkflowSchedule, error) {
+	return s.listByUser(ctx, userID)
+}
+
+func (s *stubWorkflowScheduleRepository) Delete(ctx context.Context, userID uint, scheduleID uint64) error {
+	return s.delete(ctx, userID, scheduleID)
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestWorkflowSchedule_NextRunAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		schedule WorkflowSchedule
+		after    time.Time
+		want     time.Time
+	}{
+		{
+			name:     "daily later today in Tokyo",
+			schedule: WorkflowSchedule{Frequency: ScheduleFrequencyDaily, HourOfDay: 9, MinuteOfHour: 30, Timezone: "Asia/Tokyo"},
+			after:    time.Date(2026, 3, 24, 23, 0, 0, 0, time.UTC), // 2026-03-25 08:00 JST
+			want:     time.Date(2026, 3, 25, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily exactly at run time moves to next day",
+			schedule: WorkflowSchedule{Frequency: ScheduleFrequencyDaily, HourOfDay: 9, MinuteOfHour: 30, Timezone: "Asia/Tokyo"},
+			after:    time.Date(2026, 3, 25, 0, 30, 0, 0, time.UTC),
+			want:     time.Date(2026, 3, 26, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly next monday",
+			schedule: WorkflowSchedule{Frequency: ScheduleFrequencyWeekly, Weekday: intPtr(int(time.Monday)), HourOfDay: 6, Timezone: "UTC"},
+			after:    time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC), // Wednesday
+			want:     time.Date(2026, 3, 30, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly same day before run time",
+			schedule: WorkflowSchedule{Frequency: ScheduleFrequencyWeekly, Weekday: intPtr(int(time.Wednesday)), HourOfDay: 18, Timezone: "UTC"},
+			after:    time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC),
+			want:     time.Date(2026, 3, 25, 18, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.schedule.NextRunAfter(tt.after)
+			if err != nil {
+				t.Fatalf("NextRunAfter returned error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("unexpected next run: got=%s want=%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleUseCase_Create_StoresFirstRun(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	var stored WorkflowSchedule
+	uc := NewScheduleUseCase(&stubWorkflowScheduleRepository{
+		create: func(ctx context.Context, schedule WorkflowSchedule) (WorkflowSchedule, error) {
+			stored = schedule
+			schedule.ID = 3
+			return schedule, nil
+		},
+	}, &fixedClock{now: now}, logger.NewNop())
+
+	created, err := uc.Create(context.Background(), CreateScheduleCommand{
+		UserID:        7,
+		ConnectionID:  12,
+		LabelName:     " billing ",
+		Frequency:     " Daily ",
+		Weekday:       intPtr(2),
+		TimeOfDay:     "07:15",
+		LookbackHours: 24,
+	})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if created.ID != 3 {
+		t.Fatalf("unexpected schedule id: %d", created.ID)
+	}
+	if stored.LabelName != "billing" || stored.Frequency != ScheduleFrequencyDaily || stored.Timezone != "Asia/Tokyo" {
+		t.Fatalf("unexpected normalized schedule: %+v", stored)
+	}
+	if stored.Weekday != nil {
+		t.Fatalf("daily schedule must not keep weekday: %v", *stored.Weekday)
+	}
+	if stored.HourOfDay != 7 || stored.MinuteOfHour != 15 {
+		t.Fatalf("unexpected time of day: %s", stored.TimeOfDay())
+	}
+	// 2026-03-25 21:00 JST の次は 2026-03-26 07:15 JST。
+	if want := time.Date(2026, 3, 25, 22, 15, 0, 0, time.UTC); !stored.NextRunAt.Equal(want) {
+		t.Fatalf("unexpected next run: got=%s want=%s", stored.NextRunAt, want)
+	}
+}
+
+func TestScheduleUseCase_Create_RejectsInvalidCommand(t *testing.T) {
+	t.Parallel()
+
+	valid := CreateScheduleCommand{
+		UserID:        7,
+		ConnectionID:  12,
+		LabelName:     "billing",
+		Frequency:     ScheduleFrequencyWeekly,
+		Weekday:       intPtr(1),
+		TimeOfDay:     "07:00",
+		Timezone:      "UTC",
+		LookbackHours: 168,
+	}
+	tests := []struct {
+		name   string
+		mutate func(cmd *CreateScheduleCommand)
+	}{
+		{name: "missing connection", mutate: func(cmd *CreateScheduleCommand) { cmd.ConnectionID = 0 }},
+		{name: "blank label", mutate: func(cmd *CreateScheduleCommand) { cmd.LabelName = " " }},
+		{name: "unknown frequency", mutate: func(cmd *CreateScheduleCommand) { cmd.Frequency = "monthly" }},
+		{name: "weekly without weekday", mutate: func(cmd *CreateScheduleCommand) { cmd.Weekday = nil }},
+		{name: "weekday out of range", mutate: func(cmd *CreateScheduleCommand) { cmd.Weekday = intPtr(7) }},
+		{name: "malformed time", mutate: func(cmd *CreateScheduleCommand) { cmd.TimeOfDay = "25:00" }},
+		{name: "unknown timezone", mutate: func(cmd *CreateScheduleCommand) { cmd.Timezone = "Mars/Olympus" }},
+		{name: "lookback too long", mutate: func(cmd *CreateScheduleCommand) { cmd.LookbackHours = maxScheduleLookbackHour + 1 }},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewScheduleUseCase(&stubWorkflowScheduleRepository{
+				create: func(ctx context.Context, schedule WorkflowSchedule) (WorkflowSchedule, error) {
+					t.Fatal("repository must not be called for an invalid command")
+					return WorkflowSchedule{}, nil
+				},
+			}, &fixedClock{now: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+			cmd := valid
+			tt.mutate(&cmd)
+			_, err := uc.Create(context.Background(), cmd)
+			if !errors.Is(err, ErrInvalidScheduleCommand) {
+				t.Fatalf("expected ErrInvalidScheduleCommand, got %v", err)
+			}
+		})
+	}
+}
+
+func TestScheduleUseCase_Delete_PropagatesNotFound(t *testing.T) {
+	t.Parallel()
+
+	uc := NewScheduleUseCase(&stubWorkflowScheduleRepository{
+		delete: func(ctx context.Context, userID uint, scheduleID uint64) error {
+			if userID != 7 || scheduleID != 3 {
+				t.Fatalf("unexpected target: user=%d schedule=%d", userID, scheduleID)
+			}
+			return ErrWorkflowScheduleNotFound
+		},
+	}, nil, logger.NewNop())
+
+	err := uc.Delete(context.Background(), DeleteScheduleCommand{UserID: 7, ScheduleID: 3})
+	if !errors.Is(err, ErrWorkflowScheduleNotFound) {
+		t.Fatalf("expected ErrWorkflowScheduleNotFound, got %v", err)
+	}
+}
@@ -6,10 +6,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aidarkhanov/nanoid/v2"
 )
 
+var (
+	// ErrWorkflowConflict indicates a queued or running workflow already covers the same connection, label and period.
+	ErrWorkflowConflict = errors.New("manual mail workflow conflicts with an active workflow")
+	// ErrLabelNotFound indicates the request names a label that the connection's mailbox does not have.
+	ErrLabelNotFound = errors.New("manual mail workflow label does not exist in the mailbox")
+	// ErrFetchFilterUnsupported indicates the request has a FetchFilter but the connection's provider cannot apply it.
+	ErrFetchFilterUnsupported = errors.New("manual mail workflow fetch filter is unsupported by the connection")
+)
+
 // StartResult is the accepted response payload for the manual mail workflow.
 type StartResult struct {
 	WorkflowID string
@@ -23,6 +33,10 @@ type DispatchJob struct {
 	UserID       uint
 	ConnectionID uint
 	Condition    FetchCondition
+	// RetryOfWorkflowID is set for retry runs; only the failed messages of that workflow are processed.
+	RetryOfWorkflowID string
+	// DryRun runs the stages up to billingeligibility without persisting and saves a preview instead of billings.
+	DryRun bool
 }
 
 // WorkflowDispatcher dispatches the workflow for background execution.
@@ -30,22 +44,62 @@ type WorkflowDispatcher interface {
 	Dispatch(ctx context.Context, job DispatchJob) error
 }
 
+// ActiveWorkflowQuery identifies workflows that would fetch the same messages as a new request.
+// LabelNames are the label and the include labels of the request; sharing any one of them is an overlap.
+type ActiveWorkflowQuery struct {
+	UserID       uint
+	ConnectionID uint
+	LabelNames   []string
+	Since        time.Time
+	Until        time.Time
+}
+
+// WorkflowConflictRepository finds queued or running workflows whose labels and period overlap the query.
+type WorkflowConflictRepository interface {
+	FindActiveOverlapping(ctx context.Context, query ActiveWorkflowQuery) (workflowID string, found bool, err error)
+}
+
+// MailLabelReader lists the label names of a connection's mailbox.
+type MailLabelReader interface {
+	// ListLabelNames returns ok=false when the provider has no labels to check the request against.
+	ListLabelNames(ctx context.Context, userID, connectionID uint) (names []string, ok bool, err error)
+}
+
+// WorkflowConnectionLock serializes workflow acceptance per mail-account connection across processes.
+type WorkflowConnectionLock interface {
+	Acquire(ctx context.Context, connectionID uint, token string) (bool, error)
+	Release(ctx context.Context, connectionID uint, token string) error
+}
+
 // StartUseCase validates and accepts a manual mail workflow request.
 type StartUseCase interface {
 	Start(ctx context.Context, cmd Command) (StartResult, error)
 }
 
 type startUseCase struct {
-	dispatcher WorkflowDispatcher
-	repository WorkflowStatusRepository
-	clock      timewrapper.ClockInterface
-	log        logger.Interface
+	dispatcher         WorkflowDispatcher
+	repository         WorkflowStatusRepository
+	conflictRepository WorkflowConflictRepository
+	lock               WorkflowConnectionLock
+	connections        UsableConnectionLister
+	labels             MailLabelReader
+	clock              timewrapper.ClockInterface
+	log                logger.Interface
 }
 
 // NewStartUseCase creates a start use case for background workflow acceptance.
+// The conflict check runs while lock is held so that concurrent requests cannot both pass it.
+// Dry runs skip both because they never write emails, vendors or billings.
+// connections is used for sync-all requests that fan out to every usable connection
+// and to reject a FetchFilter on a connection other than Gmail before anything is queued.
+// labels rejects unknown labels before queuing; a nil labels leaves them to the fetch stage.
 func NewStartUseCase(
 	dispatcher WorkflowDispatcher,
 	repository WorkflowStatusRepository,
+	conflictRepository WorkflowConflictRepository,
+	lock WorkflowConnectionLock,
+	connections UsableConnectionLister,
+	labels MailLabelReader,
 	clock timewrapper.ClockInterface,
 	log logger.Interface,
 ) StartUseCase {
@@ -57,10 +111,14 @@ func NewStartUseCase(
 	}
 
 	return &startUseCase{
-		dispatcher: dispatcher,
-		repository: repository,
-		clock:      clock,
-		log:        log.With(logger.Component("manual_mail_workflow_start_usecase")),
+		dispatcher:         dispatcher,
+		repository:         repository,
+		conflictRepository: conflictRepository,
+		lock:               lock,
+		connections:        connections,
+		labels:             labels,
+		clock:              clock,
+		log:                log.With(logger.Component("manual_mail_workflow_start_usecase")),
 	}
 }
 
@@ -78,6 +136,12 @@ func (uc *startUseCase) Start(ctx context.Context, cmd Command) (StartResult, er
 	if uc.repository == nil {
 		return StartResult{}, errors.New("workflow_status_repository is not configured")
 	}
+	if uc.conflictRepository == nil {
+		return StartResult{}, errors.New("workflow_conflict_repository is not configured")
+	}
+	if uc.lock == nil {
+		return StartResult{}, errors.New("workflow_connection_lock is not configured")
+	}
 
 	reqLog := uc.log
 	if withContext, err := uc.log.WithContext(ctx); err == nil {
@@ -85,41 +149,221 @@ func (uc *startUseCase) Start(ctx context.Context, cmd Command) (StartResult, er
 	}
 
 	cmd.Condition = cmd.Condition.Normalize()
+	if cmd.AllConnections {
+		return uc.startFanOut(ctx, cmd, reqLog)
+	}
+
+	if err := uc.checkFetchFilter(ctx, cmd); err != nil {
+		return StartResult{}, err
+	}
+	if err := uc.checkLabels(ctx, cmd, cmd.ConnectionID, reqLog); err != nil {
+		return StartResult{}, err
+	}
+
 	workflowID, err := newWorkflowID()
 	if err != nil {
 		return StartResult{}, fmt.Errorf("failed to generate workflow id: %w", err)
 	}
-	queuedAt := uc.clock.Now().UTC()
 
-	historyRef, err := uc.repository.CreateQueued(ctx, QueuedWorkflowHistory{
+	// A dry run saves nothing but its preview, so it may overlap an active workflow.
+	if !cmd.DryRun {
+		release, err := uc.acquireConnection(ctx, cmd, cmd.ConnectionID, workflowID, reqLog)
+		if release != nil {
+			defer release()
+		}
+		if err != nil {
+			return StartResult{}, err
+		}
+	}
+
+	result, err := enqueueWorkflow(ctx, uc.repository, uc.dispatcher, uc.clock, reqLog, QueuedWorkflowHistory{
 		WorkflowID:   workflowID,
 		UserID:       cmd.UserID,
 		ConnectionID: cmd.ConnectionID,
 		LabelName:    cmd.Condition.LabelName,
 		SinceAt:      cmd.Condition.Since,
 		UntilAt:      cmd.Condition.Until,
-		QueuedAt:     queuedAt,
+		Filter:       cmd.Condition.Filter,
+		QueuedAt:     uc.clock.Now().UTC(),
+		DryRun:       cmd.DryRun,
 	})
 	if err != nil {
 		return StartResult{}, err
 	}
 
+	reqLog.Info("manual_mail_workflow_accepted",
+		logger.UserID(cmd.UserID),
+		logger.Uint("connection_id", cmd.ConnectionID),
+		logger.String("workflow_id", result.WorkflowID),
+		logger.String("status", result.Status),
+		logger.Bool("dry_run", cmd.DryRun),
+	)
+
+	return result, nil
+}
+
+// checkFetchFilter は絞り込みを指定したリクエストが Gmail 以外のメール連携を対象にしていれば受け付けない。
+// メール連携が見つからない場合は受け付けを止めず、fetch stage のエラーとして記録させる。
+func (uc *startUseCase) checkFetchFilter(ctx context.Context, cmd Command) error {
+	if cmd.Condition.Filter.IsZero() {
+		return nil
+	}
+	if uc.connections == nil {
+		return errors.New("usable_connection_lister is not configured")
+	}
+
+	connection, found, err := uc.connections.FindUsableConnection(ctx, cmd.UserID, cmd.ConnectionID)
+	if err != nil {
+		return err
+	}
+	if found && !connection.SupportsFetchFilter() {
+		return fmt.Errorf("%w: %s (connection_id=%d)", ErrFetchFilterUnsupported, connection.Provider, cmd.ConnectionID)
+	}
+	return nil
+}
+
+// checkLabels はリクエストのラベルが接続先のメールボックスに存在することを確認する。
+// ラベル一覧を取得できない場合は受け付けを止めず、fetch stage のエラーとして記録させる。
+func (uc *startUseCase) checkLabels(ctx context.Context, cmd Command, connectionID uint, reqLog logger.Interface) error {
+	if uc.labels == nil {
+		return nil
+	}
+
+	names, ok, err := uc.labels.ListLabelNames(ctx, cmd.UserID, connectionID)
+	if err != nil {
+		reqLog.Warn("manual_mail_workflow_label_check_skipped",
+			logger.UserID(cmd.UserID),
+			logger.Uint("connection_id", connectionID),
+			logger.Err(err),
+		)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	existing := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		existing[name] = struct{}{}
+	}
+	requested := append(cmd.Condition.LabelNames(), cmd.Condition.Filter.ExcludeLabelNames...)
+	for _, name := range requested {
+		if _, found := existing[name]; !found {
+			return fmt.Errorf("%w: %s (connection_id=%d)", ErrLabelNotFound, name, connectionID)
+		}
+	}
+	return nil
+}
+
+// acquireConnection locks the connection and rejects the request when an active workflow overlaps it.
+// The returned release func is non-nil once the lock is held, even when the conflict check fails.
+func (uc *startUseCase) acquireConnection(
+	ctx context.Context,
+	cmd Command,
+	connectionID uint,
+	token string,
+	reqLog logger.Interface,
+) (func(), error) {
+	return acquireWorkflowConnection(ctx, uc.lock, uc.conflictRepository, activeWorkflowQuery(cmd.UserID, connectionID, cmd.Condition), token, reqLog)
+}
+
+// acquireWorkflowConnection is the lock and conflict check shared by start, retry and resume,
+// so that no two of them queue runs that fetch the same messages at the same time.
+func acquireWorkflowConnection(
+	ctx context.Context,
+	lock WorkflowConnectionLock,
+	conflictRepository WorkflowConflictRepository,
+	query ActiveWorkflowQuery,
+	token string,
+	reqLog logger.Interface,
+) (func(), error) {
+	acquired, err := lock.Acquire(ctx, query.ConnectionID, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire workflow connection lock: %w", err)
+	}
+	if !acquired {
+		reqLog.Info("manual_mail_workflow_conflicted",
+			logger.UserID(query.UserID),
+			logger.Uint("connection_id", query.ConnectionID),
+			logger.String("reason", "connection_locked"),
+		)
+		return nil, fmt.Errorf("%w: connection %d is being accepted by another request", ErrWorkflowConflict, query.ConnectionID)
+	}
+	release := func() {
+		if releaseErr := lock.Release(ctx, query.ConnectionID, token); releaseErr != nil {
+			reqLog.Warn("manual_mail_workflow_lock_release_failed",
+				logger.Uint("connection_id", query.ConnectionID),
+				logger.String("workflow_id", token),
+				logger.Err(releaseErr),
+			)
+		}
+	}
+
+	activeWorkflowID, found, err := conflictRepository.FindActiveOverlapping(ctx, query)
+	if err != nil {
+		return release, err
+	}
+	if found {
+		reqLog.Info("manual_mail_workflow_conflicted",
+			logger.UserID(query.UserID),
+			logger.Uint("connection_id", query.ConnectionID),
+			logger.String("reason", "active_workflow"),
+			logger.String("active_workflow_id", activeWorkflowID),
+		)
+		return release, fmt.Errorf("%w: workflow_id=%s", ErrWorkflowConflict, activeWorkflowID)
+	}
+
+	return release, nil
+}
+
+func activeWorkflowQuery(userID, connectionID uint, condition FetchCondition) ActiveWorkflowQuery {
+	return ActiveWorkflowQuery{
+		UserID:       userID,
+		ConnectionID: connectionID,
+		LabelNames:   condition.LabelNames(),
+		Since:        condition.Since,
+		Until:        condition.Until,
+	}
+}
+
+// enqueueWorkflow persists the queued header and dispatches the job.
+// When dispatch fails the header is marked failed so that it does not stay queued forever.
+func enqueueWorkflow(
+	ctx context.Context,
+	repository WorkflowStatusRepository,
+	dispatcher WorkflowDispatcher,
+	clock timewrapper.ClockInterface,
+	reqLog logger.Interface,
+	history QueuedWorkflowHistory,
+) (StartResult, error) {
+	historyRef, err := repository.CreateQueued(ctx, history)
+	if err != nil {
+		return StartResult{}, err
+	}
+
 	result := StartResult{
 		WorkflowID: historyRef.WorkflowID,
 		Status:     WorkflowStatusQueued,
 	}
 
-	if err := uc.dispatcher.Dispatch(ctx, DispatchJob{
+	if err := dispatcher.Dispatch(ctx, DispatchJob{
 		HistoryID:    historyRef.HistoryID,
 		WorkflowID:   result.WorkflowID,
-		UserID:       cmd.UserID,
-		ConnectionID: cmd.ConnectionID,
-		Condition:    cmd.Condition,
+		UserID:       history.UserID,
+		ConnectionID: history.ConnectionID,
+		Condition: FetchCondition{
+			LabelName: history.LabelName,
+			Since:     history.SinceAt,
+			Until:     history.UntilAt,
+			Filter:    history.Filter,
+		},
+		RetryOfWorkflowID: history.RetryOfWorkflowID,
+		DryRun:            history.DryRun,
 	}); err != nil {
-		if failErr := uc.repository.Fail(ctx, historyRef.HistoryID, "", uc.clock.Now().UTC(), localizedWorkflowErrorMessage("", err)); failErr != nil {
+		if failErr := repository.Fail(ctx, historyRef.HistoryID, "", clock.Now().UTC(), localizedWorkflowErrorMessage("", err)); failErr != nil {
 			reqLog.Error("manual_mail_workflow_dispatch_failed_to_mark_history",
-				logger.UserID(cmd.UserID),
-				logger.Uint("connection_id", cmd.ConnectionID),
+				logger.UserID(history.UserID),
+				logger.Uint("connection_id", history.ConnectionID),
 				logger.String("workflow_id", result.WorkflowID),
 				logger.Err(failErr),
 			)
@@ -127,13 +371,6 @@ func (uc *startUseCase) Start(ctx context.Context, cmd Command) (StartResult, er
 		return StartResult{}, err
 	}
 
-	reqLog.Info("manual_mail_workflow_accepted",
-		logger.UserID(cmd.UserID),
-		logger.Uint("connection_id", cmd.ConnectionID),
-		logger.String("workflow_id", result.WorkflowID),
-		logger.String("status", result.Status),
-	)
-
 	return result, nil
 }
 
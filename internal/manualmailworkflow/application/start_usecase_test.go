@@ -4,6 +4,8 @@ import (
 	"business/internal/library/logger"
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -15,6 +17,7 @@ type stubWorkflowStatusRepository struct {
 	complete     func(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error
 	fail         func(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time, errorMessage string) error
 	list         func(ctx context.Context, query ListQuery) (ListResult, error)
+	cancelled    func(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time) error
 }
 
 func (s *stubWorkflowStatusRepository) CreateQueued(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
@@ -58,6 +61,13 @@ func (s *stubWorkflowStatusRepository) Fail(
 	return s.fail(ctx, historyID, currentStage, finishedAt, errorMessage)
 }
 
+func (s *stubWorkflowStatusRepository) MarkCancelled(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time) error {
+	if s.cancelled == nil {
+		return nil
+	}
+	return s.cancelled(ctx, historyID, currentStage, finishedAt)
+}
+
 func (s *stubWorkflowStatusRepository) List(ctx context.Context, query ListQuery) (ListResult, error) {
 	if s.list == nil {
 		return ListResult{}, nil
@@ -79,6 +89,45 @@ func (c *fixedClock) After(d time.Duration) <-chan time.Time {
 	return ch
 }
 
+type stubWorkflowConflictRepository struct {
+	findActiveOverlapping func(ctx context.Context, query ActiveWorkflowQuery) (string, bool, error)
+}
+
+func (s *stubWorkflowConflictRepository) FindActiveOverlapping(ctx context.Context, query ActiveWorkflowQuery) (string, bool, error) {
+	if s.findActiveOverlapping == nil {
+		return "", false, nil
+	}
+	return s.findActiveOverlapping(ctx, query)
+}
+
+type stubWorkflowConnectionLock struct {
+	held     map[uint]string
+	released []string
+	err      error
+}
+
+func (s *stubWorkflowConnectionLock) Acquire(ctx context.Context, connectionID uint, token string) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	if s.held == nil {
+		s.held = map[uint]string{}
+	}
+	if _, ok := s.held[connectionID]; ok {
+		return false, nil
+	}
+	s.held[connectionID] = token
+	return true, nil
+}
+
+func (s *stubWorkflowConnectionLock) Release(ctx context.Context, connectionID uint, token string) error {
+	if s.held[connectionID] == token {
+		delete(s.held, connectionID)
+	}
+	s.released = append(s.released, token)
+	return nil
+}
+
 type stubWorkflowDispatcher struct {
 	dispatch func(ctx context.Context, job DispatchJob) error
 }
@@ -124,7 +173,7 @@ func TestStartUseCase_Start_DispatchesValidatedCommand(t *testing.T) {
 			}
 			return WorkflowHistoryRef{HistoryID: 99, WorkflowID: cmd.WorkflowID}, nil
 		},
-	}, &fixedClock{now: now}, logger.NewNop())
+	}, &stubWorkflowConflictRepository{}, &stubWorkflowConnectionLock{}, nil, nil, &fixedClock{now: now}, logger.NewNop())
 
 	result, err := uc.Start(context.Background(), Command{
 		UserID:       7,
@@ -160,7 +209,7 @@ func TestStartUseCase_Start_InvalidCommand(t *testing.T) {
 			t.Fatal("dispatch should not be called for invalid command")
 			return nil
 		},
-	}, &stubWorkflowStatusRepository{}, &fixedClock{now: time.Date(2026, 3, 25, 10, 0, 0, 0, time.UTC)}, logger.NewNop())
+	}, &stubWorkflowStatusRepository{}, &stubWorkflowConflictRepository{}, &stubWorkflowConnectionLock{}, nil, nil, &fixedClock{now: time.Date(2026, 3, 25, 10, 0, 0, 0, time.UTC)}, logger.NewNop())
 
 	_, err := uc.Start(context.Background(), Command{
 		UserID:       7,
@@ -176,6 +225,139 @@ func TestStartUseCase_Start_InvalidCommand(t *testing.T) {
 	}
 }
 
+func TestStartUseCase_Start_NormalizesFetchFilter(t *testing.T) {
+	t.Parallel()
+
+	var queued QueuedWorkflowHistory
+	var dispatched DispatchJob
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error {
+			dispatched = job
+			return nil
+		},
+	}, &stubWorkflowStatusRepository{
+		createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+			queued = cmd
+			return WorkflowHistoryRef{HistoryID: 99, WorkflowID: cmd.WorkflowID}, nil
+		},
+	}, &stubWorkflowConflictRepository{}, &stubWorkflowConnectionLock{}, &stubUsableConnectionLister{}, nil, &fixedClock{now: time.Date(2026, 3, 25, 10, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+	_, err := uc.Start(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 12,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+			Filter: FetchFilter{
+				Query:             " from:billing@example.com ",
+				IncludeLabelNames: []string{"billing", " receipts ", "receipts", ""},
+				ExcludeLabelNames: []string{" archived "},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+
+	want := FetchFilter{
+		Query:             "from:billing@example.com",
+		IncludeLabelNames: []string{"receipts"},
+		ExcludeLabelNames: []string{"archived"},
+	}
+	for name, got := range map[string]FetchFilter{"queued": queued.Filter, "dispatched": dispatched.Condition.Filter} {
+		if got.Query != want.Query ||
+			!slices.Equal(got.IncludeLabelNames, want.IncludeLabelNames) ||
+			!slices.Equal(got.ExcludeLabelNames, want.ExcludeLabelNames) {
+			t.Fatalf("unexpected %s filter: %+v", name, got)
+		}
+	}
+}
+
+func TestStartUseCase_Start_RejectsInvalidFetchFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		filter FetchFilter
+	}{
+		{name: "label is both included and excluded", filter: FetchFilter{IncludeLabelNames: []string{"receipts"}, ExcludeLabelNames: []string{"receipts"}}},
+		{name: "primary label is excluded", filter: FetchFilter{ExcludeLabelNames: []string{"billing"}}},
+		{name: "query is too long", filter: FetchFilter{Query: strings.Repeat("a", MaxFetchQueryLength+1)}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := NewStartUseCase(&stubWorkflowDispatcher{
+				dispatch: func(ctx context.Context, job DispatchJob) error {
+					t.Fatal("dispatch should not be called for invalid filter")
+					return nil
+				},
+			}, &stubWorkflowStatusRepository{}, &stubWorkflowConflictRepository{}, &stubWorkflowConnectionLock{}, nil, nil, &fixedClock{now: time.Date(2026, 3, 25, 10, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+			_, err := uc.Start(context.Background(), Command{
+				UserID:       7,
+				ConnectionID: 12,
+				Condition: FetchCondition{
+					LabelName: "billing",
+					Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+					Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+					Filter:    tt.filter,
+				},
+			})
+			if !errors.Is(err, ErrFetchConditionInvalid) {
+				t.Fatalf("expected ErrFetchConditionInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStartUseCase_Start_RejectsFetchFilterOnConnectionOtherThanGmail(t *testing.T) {
+	t.Parallel()
+
+	for _, provider := range []string{"imap", "outlook", "file"} {
+		provider := provider
+		t.Run(provider, func(t *testing.T) {
+			t.Parallel()
+
+			lock := &stubWorkflowConnectionLock{}
+			uc := NewStartUseCase(&stubWorkflowDispatcher{
+				dispatch: func(ctx context.Context, job DispatchJob) error {
+					t.Fatal("dispatch should not be called for an unsupported filter")
+					return nil
+				},
+			}, &stubWorkflowStatusRepository{
+				createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+					t.Fatal("history should not be created for an unsupported filter")
+					return WorkflowHistoryRef{}, nil
+				},
+			}, &stubWorkflowConflictRepository{}, lock, &stubUsableConnectionLister{
+				providers: map[uint]string{12: provider},
+			}, nil, &fixedClock{now: time.Date(2026, 3, 25, 10, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+			_, err := uc.Start(context.Background(), Command{
+				UserID:       7,
+				ConnectionID: 12,
+				Condition: FetchCondition{
+					LabelName: "billing",
+					Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+					Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+					Filter:    FetchFilter{ExcludeLabelNames: []string{"newsletter"}},
+				},
+			})
+			if !errors.Is(err, ErrFetchFilterUnsupported) {
+				t.Fatalf("expected ErrFetchFilterUnsupported, got %v", err)
+			}
+			if len(lock.released) != 0 {
+				t.Fatalf("expected the connection not to be locked, released=%v", lock.released)
+			}
+		})
+	}
+}
+
 func TestStartUseCase_Start_DispatchFailure(t *testing.T) {
 	t.Parallel()
 
@@ -206,7 +388,7 @@ func TestStartUseCase_Start_DispatchFailure(t *testing.T) {
 			}
 			return nil
 		},
-	}, &fixedClock{now: now}, logger.NewNop())
+	}, &stubWorkflowConflictRepository{}, &stubWorkflowConnectionLock{}, nil, nil, &fixedClock{now: now}, logger.NewNop())
 
 	_, err := uc.Start(context.Background(), Command{
 		UserID:       7,
@@ -224,3 +406,244 @@ func TestStartUseCase_Start_DispatchFailure(t *testing.T) {
 		t.Fatalf("expected 1 fail call, got %d", failCalls)
 	}
 }
+
+func TestStartUseCase_Start_RejectsOverlappingActiveWorkflow(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)
+	lock := &stubWorkflowConnectionLock{}
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error {
+			t.Fatal("dispatch must not be called for a conflicting request")
+			return nil
+		},
+	}, &stubWorkflowStatusRepository{
+		createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+			t.Fatal("history must not be created for a conflicting request")
+			return WorkflowHistoryRef{}, nil
+		},
+	}, &stubWorkflowConflictRepository{
+		findActiveOverlapping: func(ctx context.Context, query ActiveWorkflowQuery) (string, bool, error) {
+			if _, held := lock.held[12]; !held {
+				t.Fatal("conflict check must run while the connection lock is held")
+			}
+			if query.UserID != 7 || query.ConnectionID != 12 || !slices.Equal(query.LabelNames, []string{"billing"}) {
+				t.Fatalf("unexpected conflict query: %+v", query)
+			}
+			if !query.Since.Equal(since) || !query.Until.Equal(until) {
+				t.Fatalf("unexpected conflict period: %+v", query)
+			}
+			return "01JQ0B7N0M7H3X9C2J5K8V6P4", true, nil
+		},
+	}, lock, nil, nil, &fixedClock{now: until}, logger.NewNop())
+
+	_, err := uc.Start(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 12,
+		Condition:    FetchCondition{LabelName: " billing ", Since: since, Until: until},
+	})
+	if !errors.Is(err, ErrWorkflowConflict) {
+		t.Fatalf("expected ErrWorkflowConflict, got %v", err)
+	}
+	if len(lock.held) != 0 || len(lock.released) != 1 {
+		t.Fatalf("expected the lock to be released: held=%v released=%v", lock.held, lock.released)
+	}
+}
+
+func TestStartUseCase_Start_RejectsWhileConnectionLocked(t *testing.T) {
+	t.Parallel()
+
+	lock := &stubWorkflowConnectionLock{held: map[uint]string{12: "other-request"}}
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error {
+			t.Fatal("dispatch must not be called while another request holds the lock")
+			return nil
+		},
+	}, &stubWorkflowStatusRepository{}, &stubWorkflowConflictRepository{
+		findActiveOverlapping: func(ctx context.Context, query ActiveWorkflowQuery) (string, bool, error) {
+			t.Fatal("conflict check must not run without the lock")
+			return "", false, nil
+		},
+	}, lock, nil, nil, nil, logger.NewNop())
+
+	_, err := uc.Start(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 12,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	if !errors.Is(err, ErrWorkflowConflict) {
+		t.Fatalf("expected ErrWorkflowConflict, got %v", err)
+	}
+	if lock.held[12] != "other-request" || len(lock.released) != 0 {
+		t.Fatalf("lock of the other request must be kept: held=%v released=%v", lock.held, lock.released)
+	}
+}
+
+func TestStartUseCase_Start_DryRunSkipsLockAndConflictCheck(t *testing.T) {
+	t.Parallel()
+
+	var queued QueuedWorkflowHistory
+	var dispatched DispatchJob
+	lock := &stubWorkflowConnectionLock{held: map[uint]string{12: "other-request"}}
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error {
+			dispatched = job
+			return nil
+		},
+	}, &stubWorkflowStatusRepository{
+		createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+			queued = cmd
+			return WorkflowHistoryRef{HistoryID: 5, WorkflowID: cmd.WorkflowID}, nil
+		},
+	}, &stubWorkflowConflictRepository{
+		findActiveOverlapping: func(ctx context.Context, query ActiveWorkflowQuery) (string, bool, error) {
+			t.Fatal("conflict check must not run for a dry run")
+			return "", false, nil
+		},
+	}, lock, nil, nil, &fixedClock{now: time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)}, logger.NewNop())
+
+	result, err := uc.Start(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 12,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != WorkflowStatusQueued {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if !queued.DryRun || !dispatched.DryRun {
+		t.Fatalf("dry run must be kept on the history and the job: history=%+v job=%+v", queued, dispatched)
+	}
+	if lock.held[12] != "other-request" || len(lock.released) != 0 {
+		t.Fatalf("dry run must not touch the connection lock: held=%v released=%v", lock.held, lock.released)
+	}
+}
+
+func TestStartUseCase_Start_LockUnavailable(t *testing.T) {
+	t.Parallel()
+
+	lockErr := errors.New("redis down")
+	uc := NewStartUseCase(&stubWorkflowDispatcher{
+		dispatch: func(ctx context.Context, job DispatchJob) error {
+			t.Fatal("dispatch must not be called without the lock")
+			return nil
+		},
+	}, &stubWorkflowStatusRepository{}, &stubWorkflowConflictRepository{}, &stubWorkflowConnectionLock{err: lockErr}, nil, nil, nil, logger.NewNop())
+
+	_, err := uc.Start(context.Background(), Command{
+		UserID:       7,
+		ConnectionID: 12,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	if !errors.Is(err, lockErr) || errors.Is(err, ErrWorkflowConflict) {
+		t.Fatalf("expected lock error, got %v", err)
+	}
+}
+
+type stubMailLabelReader struct {
+	names             []string
+	namesByConnection map[uint][]string
+	ok                bool
+	err               error
+}
+
+func (s *stubMailLabelReader) ListLabelNames(ctx context.Context, userID, connectionID uint) ([]string, bool, error) {
+	if names, found := s.namesByConnection[connectionID]; found {
+		return names, s.ok, s.err
+	}
+	return s.names, s.ok, s.err
+}
+
+func TestStartUseCase_Start_ChecksLabelsBeforeQueuing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		labels     *stubMailLabelReader
+		filter     FetchFilter
+		wantErr    error
+		wantQueued bool
+	}{
+		{
+			name:       "known labels",
+			labels:     &stubMailLabelReader{names: []string{"INBOX", "billing", "vendors"}, ok: true},
+			filter:     FetchFilter{IncludeLabelNames: []string{"vendors"}, ExcludeLabelNames: []string{"INBOX"}},
+			wantQueued: true,
+		},
+		{
+			name:    "unknown label name",
+			labels:  &stubMailLabelReader{names: []string{"INBOX"}, ok: true},
+			wantErr: ErrLabelNotFound,
+		},
+		{
+			name:    "unknown exclude label",
+			labels:  &stubMailLabelReader{names: []string{"billing"}, ok: true},
+			filter:  FetchFilter{ExcludeLabelNames: []string{"newsletter"}},
+			wantErr: ErrLabelNotFound,
+		},
+		{
+			name:       "provider without labels",
+			labels:     &stubMailLabelReader{},
+			wantQueued: true,
+		},
+		{
+			name:       "label listing failure",
+			labels:     &stubMailLabelReader{err: errors.New("gmail down")},
+			wantQueued: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			queued := false
+			uc := NewStartUseCase(&stubWorkflowDispatcher{
+				dispatch: func(ctx context.Context, job DispatchJob) error { return nil },
+			}, &stubWorkflowStatusRepository{
+				createQueued: func(ctx context.Context, cmd QueuedWorkflowHistory) (WorkflowHistoryRef, error) {
+					queued = true
+					return WorkflowHistoryRef{HistoryID: 99, WorkflowID: cmd.WorkflowID}, nil
+				},
+			}, &stubWorkflowConflictRepository{}, &stubWorkflowConnectionLock{}, &stubUsableConnectionLister{}, tt.labels, nil, logger.NewNop())
+
+			_, err := uc.Start(context.Background(), Command{
+				UserID:       7,
+				ConnectionID: 12,
+				Condition: FetchCondition{
+					LabelName: "billing",
+					Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+					Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+					Filter:    tt.filter,
+				},
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("unexpected error: got=%v want=%v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Start returned error: %v", err)
+			}
+			if queued != tt.wantQueued {
+				t.Fatalf("unexpected queued: got=%v want=%v", queued, tt.wantQueued)
+			}
+		})
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 var (
@@ -16,21 +18,55 @@ var (
 	ErrInvalidCommand = errors.New("manual mail workflow command is invalid")
 	// ErrFetchConditionInvalid は fetch 条件が不正なときに返る。
 	ErrFetchConditionInvalid = errors.New("manual mail workflow fetch condition is invalid")
+	// ErrWorkflowInterrupted はプロセスの停止で実行中の workflow を中断するときの context cause。
+	ErrWorkflowInterrupted = errors.New("manual mail workflow interrupted by shutdown")
+	// ErrWorkflowAlreadyFinished は reaper などで終了済みになった workflow の状態を実行側が更新しようとしたときに返る。
+	ErrWorkflowAlreadyFinished = errors.New("manual mail workflow has already finished")
 )
 
+// MaxFetchQueryLength は fetch 条件の検索クエリの最大文字数。
+const MaxFetchQueryLength = 1024
+
 // FetchCondition は workflow endpoint が受け取るメール取得条件。
 type FetchCondition struct {
 	LabelName string
 	Since     time.Time
 	Until     time.Time
+	// Filter はラベルの一覧をさらに絞り込む。Gmail のメール連携でのみ指定できる。
+	Filter FetchFilter
+}
+
+// FetchFilter は LabelName のメール一覧を絞り込む任意の条件。
+type FetchFilter struct {
+	// Query は Gmail の検索構文（from: / subject: など）。
+	Query string
+	// IncludeLabelNames は LabelName と合わせて取得するラベル。いずれかのラベルが付いたメールを取得する。
+	IncludeLabelNames []string
+	// ExcludeLabelNames のいずれかが付いたメールは取得しない。
+	ExcludeLabelNames []string
+}
+
+// IsZero は絞り込みが指定されていないかを返す。
+func (f FetchFilter) IsZero() bool {
+	return strings.TrimSpace(f.Query) == "" && len(f.IncludeLabelNames) == 0 && len(f.ExcludeLabelNames) == 0
 }
 
 // Normalize は文字列だけを整形し、時刻はそのまま保持する。
 func (c FetchCondition) Normalize() FetchCondition {
 	c.LabelName = strings.TrimSpace(c.LabelName)
+	c.Filter = FetchFilter{
+		Query:             strings.TrimSpace(c.Filter.Query),
+		IncludeLabelNames: normalizeLabelNames(c.Filter.IncludeLabelNames, c.LabelName),
+		ExcludeLabelNames: normalizeLabelNames(c.Filter.ExcludeLabelNames, ""),
+	}
 	return c
 }
 
+// LabelNames は LabelName と IncludeLabelNames を合わせた、取得対象のラベルを返す。
+func (c FetchCondition) LabelNames() []string {
+	return append([]string{c.LabelName}, c.Filter.IncludeLabelNames...)
+}
+
 // Validate は workflow で必要な fetch 条件の最小不変条件を検証する。
 func (c FetchCondition) Validate() error {
 	normalized := c.Normalize()
@@ -46,14 +82,42 @@ func (c FetchCondition) Validate() error {
 	if !normalized.Since.Before(normalized.Until) {
 		return fmt.Errorf("%w: since must be before until", ErrFetchConditionInvalid)
 	}
+	if utf8.RuneCountInString(normalized.Filter.Query) > MaxFetchQueryLength {
+		return fmt.Errorf("%w: query must be at most %d characters", ErrFetchConditionInvalid, MaxFetchQueryLength)
+	}
+	included := normalized.LabelNames()
+	for _, excluded := range normalized.Filter.ExcludeLabelNames {
+		if slices.Contains(included, excluded) {
+			return fmt.Errorf("%w: label %s is both included and excluded", ErrFetchConditionInvalid, excluded)
+		}
+	}
 	return nil
 }
 
+// normalizeLabelNames は空白を除き、空・重複・primary と同じラベルを取り除く。1 件も残らなければ nil を返す。
+func normalizeLabelNames(labelNames []string, primary string) []string {
+	var normalized []string
+	for _, labelName := range labelNames {
+		labelName = strings.TrimSpace(labelName)
+		if labelName == "" || labelName == primary || slices.Contains(normalized, labelName) {
+			continue
+		}
+		normalized = append(normalized, labelName)
+	}
+	return normalized
+}
+
 // Command は manual mail workflow の入力。
 type Command struct {
-	UserID       uint
+	UserID uint
+	// ConnectionID は単一のメール連携を対象にするときに指定し、AllConnections と同時には指定できない。
 	ConnectionID uint
-	Condition    FetchCondition
+	// AllConnections は利用可能なすべてのメール連携を対象にし、連携ごとの子 workflow を束ねる親 workflow を作る。
+	AllConnections bool
+	Condition      FetchCondition
+	// DryRun は billingeligibility までをメモリ上で実行し、emails / parsed_emails / vendors / billings を保存せずに
+	// 作成される請求のプレビューだけを残す。
+	DryRun bool
 }
 
 // CreatedEmail は fetch から analysis に渡す workflow 内部 payload。
@@ -66,6 +130,16 @@ type CreatedEmail struct {
 	ReceivedAt        time.Time
 	Body              string
 	BodyDigest        string
+	// Attachments は本文とは別に analysis へ渡す添付ファイルのテキスト。
+	Attachments []EmailAttachment
+}
+
+// EmailAttachment は添付ファイルから抽出したテキスト。取得できなかった場合は FailureReason が入る。
+type EmailAttachment struct {
+	Filename      string
+	Text          string
+	Truncated     bool
+	FailureReason string
 }
 
 // FetchFailure は fetch stage から返る部分失敗。
@@ -89,7 +163,9 @@ type AnalysisFailure struct {
 type FetchResult struct {
 	CreatedEmails    []CreatedEmail
 	ExistingEmailIDs []uint
-	Failures         []FetchFailure
+	// ExistingEmails は FetchCommand.IncludeExistingEmails のときだけ返る取得済みメールの payload。
+	ExistingEmails []CreatedEmail
+	Failures       []FetchFailure
 }
 
 // AnalyzeResult は analysis stage の正規化済み出力。
@@ -257,6 +333,10 @@ type Result struct {
 	VendorResolution   VendorResolutionResult
 	BillingEligibility BillingEligibilityResult
 	Billing            BillingResult
+	// Preview は dry-run のときだけ、billing stage の代わりに組み立てたプレビューが入る。
+	Preview *WorkflowPreview
+	// StageOutputs は pipeline に追加した stage が PipelineState.SetOutput で書き出した出力。
+	StageOutputs map[string]any
 }
 
 // FetchCommand は workflow が所有する fetch stage 入力。
@@ -264,24 +344,36 @@ type FetchCommand struct {
 	UserID       uint
 	ConnectionID uint
 	Condition    FetchCondition
+	// MessageIDs を指定すると、ラベルの一覧取得をせずにそのメールだけを取得する。
+	MessageIDs []string
+	// IncludeExistingEmails は取得済みメールも analysis に渡せるよう payload を返させる。
+	IncludeExistingEmails bool
+	// DryRun はメールもチェックポイントも保存せず、取得したメールを EmailID なしで返させる。
+	DryRun bool
 }
 
 // AnalyzeCommand は workflow が所有する analysis stage 入力。
 type AnalyzeCommand struct {
 	UserID uint
 	Emails []CreatedEmail
+	// DryRun は ParsedEmail を保存せず、ParsedEmailID なしで解析結果を返させる。
+	DryRun bool
 }
 
 // VendorResolutionCommand は workflow が所有する vendorresolution stage 入力。
 type VendorResolutionCommand struct {
 	UserID       uint
 	ParsedEmails []ParsedEmail
+	// DryRun は vendor を自動登録せず、登録予定の vendor を VendorID 0 の解決済みとして返させる。
+	DryRun bool
 }
 
 // BillingEligibilityCommand は workflow が所有する billingeligibility stage 入力。
 type BillingEligibilityCommand struct {
 	UserID        uint
 	ResolvedItems []ResolvedItem
+	// DryRun は未保存のため ID を持たない item も判定対象にさせる。
+	DryRun bool
 }
 
 // BillingCommand is the workflow-owned billing stage input.
@@ -321,17 +413,23 @@ type UseCase interface {
 }
 
 type useCase struct {
+	pipeline                StagePipeline
 	fetchStage              FetchStage
 	analyzeStage            AnalyzeStage
 	vendorResolutionStage   VendorResolutionStage
 	billingEligibilityStage BillingEligibilityStage
 	billingStage            BillingStage
 	repository              WorkflowStatusRepository
+	retryRepository         WorkflowRetryRepository
+	resumeRepository        WorkflowResumeRepository
+	previewRepository       WorkflowPreviewRepository
 	clock                   timewrapper.ClockInterface
 	log                     logger.Interface
 }
 
-// NewUseCase は manual mail workflow の usecase を生成する。
+// NewUseCase は組み込み stage だけの pipeline で manual mail workflow の usecase を生成する。
+// resumeRepository が nil のときは、中断された workflow も常に先頭の stage から実行する。
+// previewRepository は dry-run の job を実行するときだけ使う。
 func NewUseCase(
 	fetchStage FetchStage,
 	analyzeStage AnalyzeStage,
@@ -339,6 +437,29 @@ func NewUseCase(
 	billingEligibilityStage BillingEligibilityStage,
 	billingStage BillingStage,
 	repository WorkflowStatusRepository,
+	retryRepository WorkflowRetryRepository,
+	resumeRepository WorkflowResumeRepository,
+	previewRepository WorkflowPreviewRepository,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) UseCase {
+	builtin := BuiltinStages{
+		Fetch:              fetchStage,
+		Analyze:            analyzeStage,
+		VendorResolution:   vendorResolutionStage,
+		BillingEligibility: billingEligibilityStage,
+		Billing:            billingStage,
+	}
+	return NewPipelineUseCase(StagePipeline{builtin: builtin, stages: builtinPipelineStages(builtin)}, repository, retryRepository, resumeRepository, previewRepository, clock, log)
+}
+
+// NewPipelineUseCase は NewStagePipeline で組み立てた pipeline の順に stage を実行する usecase を生成する。
+func NewPipelineUseCase(
+	pipeline StagePipeline,
+	repository WorkflowStatusRepository,
+	retryRepository WorkflowRetryRepository,
+	resumeRepository WorkflowResumeRepository,
+	previewRepository WorkflowPreviewRepository,
 	clock timewrapper.ClockInterface,
 	log logger.Interface,
 ) UseCase {
@@ -350,18 +471,25 @@ func NewUseCase(
 	}
 
 	return &useCase{
-		fetchStage:              fetchStage,
-		analyzeStage:            analyzeStage,
-		vendorResolutionStage:   vendorResolutionStage,
-		billingEligibilityStage: billingEligibilityStage,
-		billingStage:            billingStage,
+		pipeline:                pipeline,
+		fetchStage:              pipeline.builtin.Fetch,
+		analyzeStage:            pipeline.builtin.Analyze,
+		vendorResolutionStage:   pipeline.builtin.VendorResolution,
+		billingEligibilityStage: pipeline.builtin.BillingEligibility,
+		billingStage:            pipeline.builtin.Billing,
 		repository:              repository,
+		retryRepository:         retryRepository,
+		resumeRepository:        resumeRepository,
+		previewRepository:       previewRepository,
 		clock:                   clock,
 		log:                     log.With(logger.Component("manual_mail_workflow_usecase")),
 	}
 }
 
-// Execute は fetch -> analysis -> vendorresolution -> billingeligibility -> billing の順で workflow を進める。
+// Execute は pipeline の順（組み込み stage は fetch -> analysis -> vendorresolution -> billingeligibility -> billing）で workflow を進める。
+// retry run では元 workflow で失敗したメールだけを、失敗した stage から再開する。
+// 前回の実行が analysis 以降の stage handoff を残していれば、その次の stage から再開する。
+// dry-run では billing stage を実行せず、作成される請求のプレビューを保存して完了する。
 func (uc *useCase) Execute(ctx context.Context, job DispatchJob) (result Result, err error) {
 	if ctx == nil {
 		return Result{}, logger.ErrNilContext
@@ -393,129 +521,64 @@ func (uc *useCase) Execute(ctx context.Context, job DispatchJob) (result Result,
 
 	job.Condition = job.Condition.Normalize()
 
-	currentStage = workflowStageFetch
-	if err := uc.repository.MarkRunning(ctx, job.HistoryID, currentStage); err != nil {
-		return Result{}, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-	}
-
-	fetchResult, err := uc.fetchStage.Execute(ctx, FetchCommand{
-		UserID:       job.UserID,
-		ConnectionID: job.ConnectionID,
-		Condition:    job.Condition,
-	})
-	if err != nil {
-		return Result{}, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-	}
-
-	result = Result{Fetch: fetchResult}
-	if err := uc.repository.SaveStageProgress(ctx, buildFetchStageProgress(job.HistoryID, fetchResult)); err != nil {
-		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-	}
-	if len(fetchResult.CreatedEmails) == 0 {
-		finalStatus := workflowStatusForResult(result)
-		if err := uc.repository.Complete(ctx, job.HistoryID, finalStatus, uc.clock.Now().UTC()); err != nil {
-			return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
+	// dry-run は handoff を残さず retry もできないため、常に先頭の stage から実行する。
+	seeds := stageSeeds{dryRun: job.DryRun}
+	if !job.DryRun {
+		seeds, err = uc.loadResumeSeeds(ctx, job)
+		if err != nil {
+			return Result{}, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
 		}
-		reqLog.Info("manual_mail_workflow_completed",
-			logger.UserID(job.UserID),
-			logger.Uint("connection_id", job.ConnectionID),
+	}
+	if seeds.dryRun {
+		reqLog.Info("manual_mail_workflow_dry_run_started",
 			logger.String("workflow_id", job.WorkflowID),
-			logger.String("status", finalStatus),
-			logger.Int("created_email_count", len(fetchResult.CreatedEmails)),
-			logger.Int("parsed_email_count", 0),
-			logger.Int("resolved_vendor_count", 0),
-			logger.Int("unresolved_vendor_count", 0),
-			logger.Int("eligible_billing_count", 0),
-			logger.Int("ineligible_billing_count", 0),
-			logger.Int("created_billing_count", 0),
-			logger.Int("duplicate_billing_count", 0),
-			logger.Int("fetch_business_failure_count", 0),
-			logger.Int("fetch_technical_failure_count", len(fetchResult.Failures)),
-			logger.Int("analysis_business_failure_count", 0),
-			logger.Int("analysis_technical_failure_count", 0),
-			logger.Int("vendor_resolution_business_failure_count", 0),
-			logger.Int("vendor_resolution_technical_failure_count", 0),
-			logger.Int("billing_eligibility_business_failure_count", 0),
-			logger.Int("billing_eligibility_technical_failure_count", 0),
-			logger.Int("billing_business_failure_count", 0),
-			logger.Int("billing_technical_failure_count", 0),
 		)
-		return result, nil
-	}
-
-	emails := make([]CreatedEmail, len(fetchResult.CreatedEmails))
-	copy(emails, fetchResult.CreatedEmails)
-
-	currentStage = workflowStageAnalysis
-	if err := uc.repository.MarkRunning(ctx, job.HistoryID, currentStage); err != nil {
-		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-	}
-
-	analysisResult, err := uc.analyzeStage.Execute(ctx, AnalyzeCommand{
-		UserID: job.UserID,
-		Emails: emails,
-	})
-	if err != nil {
-		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
+	} else if seeds.resumedFrom != "" {
+		reqLog.Info("manual_mail_workflow_resumed",
+			logger.String("workflow_id", job.WorkflowID),
+			logger.String("resumed_from", seeds.resumedFrom),
+		)
+	} else {
+		seeds, err = uc.loadRetrySeeds(ctx, job)
+		if err != nil {
+			return Result{}, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
+		}
 	}
 
-	result.Analysis = analysisResult
-	if err := uc.repository.SaveStageProgress(ctx, buildAnalysisStageProgress(job.HistoryID, analysisResult)); err != nil {
-		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-	}
-	if len(analysisResult.ParsedEmails) > 0 {
-		currentStage = workflowStageVendorResolution
-		if err := uc.repository.MarkRunning(ctx, job.HistoryID, currentStage); err != nil {
+	state := &PipelineState{job: job, result: &result, seeds: seeds}
+	additionalStageFailures := false
+	for _, stage := range uc.pipeline.stages {
+		if job.DryRun && !stage.Contract().RunsInDryRun {
+			continue
+		}
+		if !stage.Ready(state) {
+			continue
+		}
+		if err := uc.enterStage(ctx, job.HistoryID, &currentStage, stage.Name()); err != nil {
 			return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
 		}
-		vendorResolutionResult, err := uc.vendorResolutionStage.Execute(ctx, VendorResolutionCommand{
-			UserID:       job.UserID,
-			ParsedEmails: append([]ParsedEmail(nil), analysisResult.ParsedEmails...),
-		})
+		progress, err := stage.Run(ctx, state)
 		if err != nil {
 			return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
 		}
-		result.VendorResolution = vendorResolutionResult
-		if err := uc.repository.SaveStageProgress(ctx, buildVendorResolutionStageProgress(job.HistoryID, analysisResult.ParsedEmails, vendorResolutionResult)); err != nil {
-			return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
+		progress = withPipelineStage(progress, job.HistoryID, stage.Name())
+		if !isBuiltinWorkflowStage(progress.Stage) && progress.BusinessFailureCount+progress.TechnicalFailureCount > 0 {
+			additionalStageFailures = true
 		}
-		if len(vendorResolutionResult.ResolvedItems) > 0 {
-			currentStage = workflowStageBillingEligibility
-			if err := uc.repository.MarkRunning(ctx, job.HistoryID, currentStage); err != nil {
-				return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-			}
-			billingEligibilityResult, err := uc.billingEligibilityStage.Execute(ctx, BillingEligibilityCommand{
-				UserID:        job.UserID,
-				ResolvedItems: append([]ResolvedItem(nil), vendorResolutionResult.ResolvedItems...),
-			})
-			if err != nil {
-				return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-			}
-			result.BillingEligibility = billingEligibilityResult
-			if err := uc.repository.SaveStageProgress(ctx, buildBillingEligibilityStageProgress(job.HistoryID, billingEligibilityResult)); err != nil {
-				return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-			}
-			if len(billingEligibilityResult.EligibleItems) > 0 {
-				currentStage = workflowStageBilling
-				if err := uc.repository.MarkRunning(ctx, job.HistoryID, currentStage); err != nil {
-					return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-				}
-				billingResult, err := uc.billingStage.Execute(ctx, BillingCommand{
-					UserID:        job.UserID,
-					EligibleItems: append([]EligibleItem(nil), billingEligibilityResult.EligibleItems...),
-				})
-				if err != nil {
-					return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-				}
-				result.Billing = billingResult
-				if err := uc.repository.SaveStageProgress(ctx, buildBillingStageProgress(job.HistoryID, billingResult)); err != nil {
-					return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
-				}
-			}
+		// stage の副作用は確定済みなので、cancel された後でも stage の件数は保存する。
+		if err := uc.repository.SaveStageProgress(context.WithoutCancel(ctx), withStageHandoff(progress, result, seeds)); err != nil {
+			return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
 		}
 	}
 
+	if job.DryRun {
+		return uc.completeDryRun(ctx, job, currentStage, result, additionalStageFailures, reqLog)
+	}
+
 	finalStatus := workflowStatusForResult(result)
+	if seeds.priorFailures || additionalStageFailures {
+		finalStatus = WorkflowStatusPartialSuccess
+	}
 	if err := uc.repository.Complete(ctx, job.HistoryID, finalStatus, uc.clock.Now().UTC()); err != nil {
 		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
 	}
@@ -524,9 +587,11 @@ func (uc *useCase) Execute(ctx context.Context, job DispatchJob) (result Result,
 		logger.UserID(job.UserID),
 		logger.Uint("connection_id", job.ConnectionID),
 		logger.String("workflow_id", job.WorkflowID),
+		logger.String("retry_of_workflow_id", job.RetryOfWorkflowID),
+		logger.String("resumed_from", seeds.resumedFrom),
 		logger.String("status", finalStatus),
-		logger.Int("created_email_count", len(fetchResult.CreatedEmails)),
-		logger.Int("parsed_email_count", len(analysisResult.ParsedEmails)),
+		logger.Int("created_email_count", len(result.Fetch.CreatedEmails)),
+		logger.Int("parsed_email_count", len(result.Analysis.ParsedEmails)),
 		logger.Int("resolved_vendor_count", result.VendorResolution.ResolvedCount),
 		logger.Int("unresolved_vendor_count", result.VendorResolution.UnresolvedCount),
 		logger.Int("eligible_billing_count", result.BillingEligibility.EligibleCount),
@@ -534,9 +599,9 @@ func (uc *useCase) Execute(ctx context.Context, job DispatchJob) (result Result,
 		logger.Int("created_billing_count", result.Billing.CreatedCount),
 		logger.Int("duplicate_billing_count", result.Billing.DuplicateCount),
 		logger.Int("fetch_business_failure_count", 0),
-		logger.Int("fetch_technical_failure_count", len(fetchResult.Failures)),
+		logger.Int("fetch_technical_failure_count", len(result.Fetch.Failures)),
 		logger.Int("analysis_business_failure_count", 0),
-		logger.Int("analysis_technical_failure_count", len(analysisResult.Failures)),
+		logger.Int("analysis_technical_failure_count", len(result.Analysis.Failures)),
 		logger.Int("vendor_resolution_business_failure_count", result.VendorResolution.UnresolvedCount),
 		logger.Int("vendor_resolution_technical_failure_count", len(result.VendorResolution.Failures)),
 		logger.Int("billing_eligibility_business_failure_count", result.BillingEligibility.IneligibleCount),
@@ -548,6 +613,79 @@ func (uc *useCase) Execute(ctx context.Context, job DispatchJob) (result Result,
 	return result, nil
 }
 
+// withPipelineStage は stage が返した件数を、実行中の履歴と stage 名に結びつける。
+func withPipelineStage(progress StageProgress, historyID uint64, stage string) StageProgress {
+	progress.HistoryID = historyID
+	progress.Stage = stage
+	for idx := range progress.FailureRecords {
+		if progress.FailureRecords[idx].Stage == "" {
+			progress.FailureRecords[idx].Stage = stage
+		}
+	}
+	return progress
+}
+
+// withStageHandoff は stage の件数と一緒に、後続 stage に渡す入力を保存させる。
+// dry-run の出力は ID を持たず再開にも使えないため、handoff は保存しない。
+// 追加した stage の出力は組み込み stage の入力にならないため、handoff は組み込み stage だけが保存する。
+func withStageHandoff(progress StageProgress, result Result, seeds stageSeeds) StageProgress {
+	if seeds.dryRun || !isBuiltinWorkflowStage(progress.Stage) {
+		return progress
+	}
+	progress.Handoff = newStageHandoff(progress.Stage, result, seeds)
+	return progress
+}
+
+// completeDryRun は billing stage の代わりにプレビューを保存して workflow を完了する。
+// 重複になる請求がある場合も、通常実行と同じく partial_success にする。
+func (uc *useCase) completeDryRun(ctx context.Context, job DispatchJob, currentStage string, result Result, additionalStageFailures bool, reqLog logger.Interface) (Result, error) {
+	if uc.previewRepository == nil {
+		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, errors.New("workflow_preview_repository is not configured"), reqLog)
+	}
+
+	preview, err := uc.buildWorkflowPreview(ctx, job.UserID, result)
+	if err != nil {
+		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
+	}
+	if err := uc.previewRepository.SavePreview(context.WithoutCancel(ctx), job.HistoryID, preview); err != nil {
+		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
+	}
+	result.Preview = &preview
+
+	finalStatus := workflowStatusForResult(result)
+	if len(preview.DuplicateItems) > 0 || additionalStageFailures {
+		finalStatus = WorkflowStatusPartialSuccess
+	}
+	if err := uc.repository.Complete(ctx, job.HistoryID, finalStatus, uc.clock.Now().UTC()); err != nil {
+		return result, uc.failWorkflow(ctx, job.HistoryID, currentStage, err, reqLog)
+	}
+
+	reqLog.Info("manual_mail_workflow_dry_run_completed",
+		logger.UserID(job.UserID),
+		logger.Uint("connection_id", job.ConnectionID),
+		logger.String("workflow_id", job.WorkflowID),
+		logger.String("status", finalStatus),
+		logger.Int("fetched_email_count", len(result.Fetch.CreatedEmails)),
+		logger.Int("parsed_email_count", len(result.Analysis.ParsedEmails)),
+		logger.Int("would_create_billing_count", len(preview.WouldCreateItems)),
+		logger.Int("duplicate_billing_count", len(preview.DuplicateItems)),
+		logger.Int("unresolved_vendor_count", len(preview.UnresolvedItems)),
+		logger.Int("ineligible_billing_count", len(preview.IneligibleItems)),
+	)
+
+	return result, nil
+}
+
+// enterStage は直前の stage の後に cancel されていないことを確かめてから、次の stage を running として記録する。
+// cancel されていた場合は currentStage を直前の stage のまま返す。
+func (uc *useCase) enterStage(ctx context.Context, historyID uint64, currentStage *string, stage string) error {
+	if *currentStage != "" && ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	*currentStage = stage
+	return uc.repository.MarkRunning(ctx, historyID, stage)
+}
+
 func (uc *useCase) validateDependencies() error {
 	if uc.fetchStage == nil {
 		return errors.New("fetch_stage is not configured")
@@ -567,6 +705,9 @@ func (uc *useCase) validateDependencies() error {
 	if uc.repository == nil {
 		return errors.New("workflow_status_repository is not configured")
 	}
+	if len(uc.pipeline.stages) == 0 {
+		return errors.New("stage_pipeline is not configured")
+	}
 	return nil
 }
 
@@ -577,8 +718,26 @@ func (uc *useCase) failWorkflow(
 	runErr error,
 	reqLog logger.Interface,
 ) error {
+	if errors.Is(runErr, ErrWorkflowCancelled) || errors.Is(context.Cause(ctx), ErrWorkflowCancelled) {
+		return uc.cancelWorkflow(ctx, historyID, currentStage, reqLog)
+	}
+	// 停止による中断は workflow の失敗ではないため、履歴を failed にせず job を積み直す側に任せる。
+	if errors.Is(runErr, ErrWorkflowInterrupted) || errors.Is(context.Cause(ctx), ErrWorkflowInterrupted) {
+		reqLog.Info("manual_mail_workflow_interrupted",
+			logger.String("current_stage", currentStage),
+			logger.Uint("history_id", uint(historyID)),
+		)
+		return ErrWorkflowInterrupted
+	}
+
 	finishedAt := uc.clock.Now().UTC()
-	if err := uc.repository.Fail(ctx, historyID, currentStage, finishedAt, localizedWorkflowErrorMessage(currentStage, runErr)); err != nil {
+	if err := uc.repository.Fail(ctx, historyID, currentStage, finishedAt, localizedWorkflowErrorMessage(currentStage, runErr)); errors.Is(err, ErrWorkflowAlreadyFinished) {
+		// reaper などが先に終了させた履歴は、その結果を残して上書きしない。
+		reqLog.Warn("manual_mail_workflow_already_finished",
+			logger.String("current_stage", currentStage),
+			logger.Uint("history_id", uint(historyID)),
+		)
+	} else if err != nil {
 		reqLog.Error("manual_mail_workflow_fail_persist_failed",
 			logger.String("current_stage", currentStage),
 			logger.Uint("history_id", uint(historyID)),
@@ -588,6 +747,28 @@ func (uc *useCase) failWorkflow(
 	return runErr
 }
 
+// cancelWorkflow は cancel 要求で止まった workflow を、止まった stage と保存済みの件数を残したまま cancelled にする。
+func (uc *useCase) cancelWorkflow(
+	ctx context.Context,
+	historyID uint64,
+	currentStage string,
+	reqLog logger.Interface,
+) error {
+	finishedAt := uc.clock.Now().UTC()
+	if err := uc.repository.MarkCancelled(context.WithoutCancel(ctx), historyID, currentStage, finishedAt); err != nil {
+		reqLog.Error("manual_mail_workflow_cancel_persist_failed",
+			logger.String("current_stage", currentStage),
+			logger.Uint("history_id", uint(historyID)),
+			logger.Err(err),
+		)
+	}
+	reqLog.Info("manual_mail_workflow_cancelled",
+		logger.String("current_stage", currentStage),
+		logger.Uint("history_id", uint(historyID)),
+	)
+	return ErrWorkflowCancelled
+}
+
 func validateDispatchJob(job DispatchJob) error {
 	if job.HistoryID == 0 {
 		return fmt.Errorf("%w: history_id is required", ErrInvalidCommand)
@@ -606,7 +787,10 @@ func validateCommand(cmd Command) error {
 	if cmd.UserID == 0 {
 		return fmt.Errorf("%w: user_id is required", ErrInvalidCommand)
 	}
-	if cmd.ConnectionID == 0 {
+	if cmd.AllConnections && cmd.ConnectionID != 0 {
+		return fmt.Errorf("%w: connection_id must be empty when all_connections is set", ErrInvalidCommand)
+	}
+	if !cmd.AllConnections && cmd.ConnectionID == 0 {
 		return fmt.Errorf("%w: connection_id is required", ErrInvalidCommand)
 	}
 	if err := cmd.Condition.Validate(); err != nil {
@@ -236,6 +236,9 @@ func TestUseCaseExecute_FetchThenAnalyze(t *testing.T) {
 			},
 		},
 		&stubWorkflowStatusRepository{},
+		nil,
+		nil,
+		nil,
 		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
 		logger.NewNop(),
 	)
@@ -334,6 +337,9 @@ func TestUseCaseExecute_SkipsAnalyzeWhenNoCreatedEmails(t *testing.T) {
 				return nil
 			},
 		},
+		nil,
+		nil,
+		nil,
 		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
 		logger.NewNop(),
 	)
@@ -435,6 +441,9 @@ func TestUseCaseExecute_SkipsVendorResolutionWhenNoParsedEmails(t *testing.T) {
 			},
 		},
 		&stubWorkflowStatusRepository{},
+		nil,
+		nil,
+		nil,
 		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
 		logger.NewNop(),
 	)
@@ -524,6 +533,9 @@ func TestUseCaseExecute_SkipsBillingEligibilityWhenNoResolvedItems(t *testing.T)
 			},
 		},
 		&stubWorkflowStatusRepository{},
+		nil,
+		nil,
+		nil,
 		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
 		logger.NewNop(),
 	)
@@ -621,6 +633,9 @@ func TestUseCaseExecute_SkipsBillingWhenNoEligibleItems(t *testing.T) {
 			},
 		},
 		&stubWorkflowStatusRepository{},
+		nil,
+		nil,
+		nil,
 		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
 		logger.NewNop(),
 	)
@@ -683,6 +698,9 @@ func TestUseCaseExecute_InvalidCommand(t *testing.T) {
 			},
 		},
 		&stubWorkflowStatusRepository{},
+		nil,
+		nil,
+		nil,
 		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
 		logger.NewNop(),
 	)
@@ -777,6 +795,9 @@ func TestUseCaseExecute_SavesProgressAndCompletesPartialSuccess(t *testing.T) {
 				return nil
 			},
 		},
+		nil,
+		nil,
+		nil,
 		&fixedClock{now: now},
 		logger.NewNop(),
 	)
@@ -881,6 +902,9 @@ func TestUseCaseExecute_FetchErrorMarksWorkflowFailedWithErrorMessage(t *testing
 				return nil
 			},
 		},
+		nil,
+		nil,
+		nil,
 		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
 		logger.NewNop(),
 	)
@@ -904,6 +928,223 @@ func TestUseCaseExecute_FetchErrorMarksWorkflowFailedWithErrorMessage(t *testing
 	}
 }
 
+func TestUseCaseExecute_CancelDuringAnalysisMarksWorkflowCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	savedStages := make([]string, 0, 2)
+	cancelledStage := ""
+
+	uc := NewUseCase(
+		&stubFetchStage{
+			execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+				return FetchResult{
+					CreatedEmails: []CreatedEmail{
+						{EmailID: 101, ExternalMessageID: "msg-1"},
+					},
+				}, nil
+			},
+		},
+		&stubAnalyzeStage{
+			execute: func(stageCtx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+				cancel(ErrWorkflowCancelled)
+				return AnalyzeResult{}, stageCtx.Err()
+			},
+		},
+		&stubVendorResolutionStage{
+			execute: func(ctx context.Context, cmd VendorResolutionCommand) (VendorResolutionResult, error) {
+				t.Fatal("vendor resolution stage should not be called")
+				return VendorResolutionResult{}, nil
+			},
+		},
+		&stubBillingEligibilityStage{
+			execute: func(ctx context.Context, cmd BillingEligibilityCommand) (BillingEligibilityResult, error) {
+				t.Fatal("billing eligibility stage should not be called")
+				return BillingEligibilityResult{}, nil
+			},
+		},
+		&stubBillingStage{
+			execute: func(ctx context.Context, cmd BillingCommand) (BillingResult, error) {
+				t.Fatal("billing stage should not be called")
+				return BillingResult{}, nil
+			},
+		},
+		&stubWorkflowStatusRepository{
+			saveStage: func(ctx context.Context, progress StageProgress) error {
+				savedStages = append(savedStages, progress.Stage)
+				return nil
+			},
+			fail: func(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time, errorMessage string) error {
+				t.Fatal("fail should not be called for a cancelled workflow")
+				return nil
+			},
+			cancelled: func(persistCtx context.Context, historyID uint64, currentStage string, finishedAt time.Time) error {
+				if persistCtx.Err() != nil {
+					t.Fatalf("expected cancel to be persisted with a live context, got %v", persistCtx.Err())
+				}
+				cancelledStage = currentStage
+				return nil
+			},
+		},
+		nil,
+		nil,
+		nil,
+		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
+		logger.NewNop(),
+	)
+
+	_, err := uc.Execute(ctx, DispatchJob{
+		HistoryID:    1,
+		WorkflowID:   "wf-cancelled",
+		UserID:       1,
+		ConnectionID: 2,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	if !errors.Is(err, ErrWorkflowCancelled) {
+		t.Fatalf("expected ErrWorkflowCancelled, got %v", err)
+	}
+	if cancelledStage != workflowStageAnalysis {
+		t.Fatalf("expected cancelled stage %q, got %q", workflowStageAnalysis, cancelledStage)
+	}
+	if len(savedStages) != 1 || savedStages[0] != workflowStageFetch {
+		t.Fatalf("expected fetch progress to be kept, got %v", savedStages)
+	}
+}
+
+func TestUseCaseExecute_StopsBeforeNextStageWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	cancelledStage := ""
+
+	uc := NewUseCase(
+		&stubFetchStage{
+			execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+				cancel(ErrWorkflowCancelled)
+				return FetchResult{
+					CreatedEmails: []CreatedEmail{
+						{EmailID: 101, ExternalMessageID: "msg-1"},
+					},
+				}, nil
+			},
+		},
+		&stubAnalyzeStage{
+			execute: func(ctx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+				t.Fatal("analyze stage should not be called")
+				return AnalyzeResult{}, nil
+			},
+		},
+		&stubVendorResolutionStage{},
+		&stubBillingEligibilityStage{},
+		&stubBillingStage{},
+		&stubWorkflowStatusRepository{
+			cancelled: func(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time) error {
+				cancelledStage = currentStage
+				return nil
+			},
+		},
+		nil,
+		nil,
+		nil,
+		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
+		logger.NewNop(),
+	)
+
+	_, err := uc.Execute(ctx, DispatchJob{
+		HistoryID:    1,
+		WorkflowID:   "wf-cancelled",
+		UserID:       1,
+		ConnectionID: 2,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	if !errors.Is(err, ErrWorkflowCancelled) {
+		t.Fatalf("expected ErrWorkflowCancelled, got %v", err)
+	}
+	if cancelledStage != workflowStageFetch {
+		t.Fatalf("expected cancelled stage %q, got %q", workflowStageFetch, cancelledStage)
+	}
+}
+
+func TestUseCaseExecute_InterruptedWorkflowIsNotFailed(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	savedStages := make([]string, 0, 1)
+
+	uc := NewUseCase(
+		&stubFetchStage{
+			execute: func(ctx context.Context, cmd FetchCommand) (FetchResult, error) {
+				cancel(ErrWorkflowInterrupted)
+				return FetchResult{
+					CreatedEmails: []CreatedEmail{
+						{EmailID: 101, ExternalMessageID: "msg-1"},
+					},
+				}, nil
+			},
+		},
+		&stubAnalyzeStage{
+			execute: func(ctx context.Context, cmd AnalyzeCommand) (AnalyzeResult, error) {
+				t.Fatal("analyze stage should not be called")
+				return AnalyzeResult{}, nil
+			},
+		},
+		&stubVendorResolutionStage{},
+		&stubBillingEligibilityStage{},
+		&stubBillingStage{},
+		&stubWorkflowStatusRepository{
+			saveStage: func(ctx context.Context, progress StageProgress) error {
+				savedStages = append(savedStages, progress.Stage)
+				return nil
+			},
+			fail: func(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time, errorMessage string) error {
+				t.Fatal("an interrupted workflow should not be failed")
+				return nil
+			},
+			cancelled: func(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time) error {
+				t.Fatal("an interrupted workflow should not be cancelled")
+				return nil
+			},
+		},
+		nil,
+		nil,
+		nil,
+		&fixedClock{now: time.Date(2026, 3, 25, 12, 30, 0, 0, time.UTC)},
+		logger.NewNop(),
+	)
+
+	_, err := uc.Execute(ctx, DispatchJob{
+		HistoryID:    1,
+		WorkflowID:   "wf-interrupted",
+		UserID:       1,
+		ConnectionID: 2,
+		Condition: FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	if !errors.Is(err, ErrWorkflowInterrupted) {
+		t.Fatalf("expected ErrWorkflowInterrupted, got %v", err)
+	}
+	if len(savedStages) != 1 || savedStages[0] != workflowStageFetch {
+		t.Fatalf("expected the finished fetch stage to be saved, got %v", savedStages)
+	}
+}
+
 func stringPtr(value string) *string {
 	return &value
 }
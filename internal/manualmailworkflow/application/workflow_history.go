@@ -19,6 +19,8 @@ const (
 	WorkflowStatusPartialSuccess = "partial_success"
 	// WorkflowStatusFailed indicates the workflow could not complete because of a top-level failure.
 	WorkflowStatusFailed = "failed"
+	// WorkflowStatusCancelled indicates the workflow was stopped by the user.
+	WorkflowStatusCancelled = "cancelled"
 )
 
 const (
@@ -31,8 +33,18 @@ const (
 	reasonCodeVendorUnresolved      = "vendor_unresolved"
 	reasonCodeDuplicateBilling      = "duplicate_billing"
 	reasonCodeExistingEmailsSkipped = "existing_emails_skipped"
+	reasonCodeRetrySourceMissing    = "retry_source_missing"
 )
 
+// workflowStages は workflow の stage を実行順に並べたもの。
+var workflowStages = []string{
+	workflowStageFetch,
+	workflowStageAnalysis,
+	workflowStageVendorResolution,
+	workflowStageBillingEligibility,
+	workflowStageBilling,
+}
+
 // WorkflowHistoryRef identifies a persisted workflow header row.
 type WorkflowHistoryRef struct {
 	HistoryID  uint64
@@ -41,13 +53,23 @@ type WorkflowHistoryRef struct {
 
 // QueuedWorkflowHistory is the header snapshot persisted when the workflow is accepted.
 type QueuedWorkflowHistory struct {
-	WorkflowID   string
-	UserID       uint
-	ConnectionID uint
-	LabelName    string
-	SinceAt      time.Time
-	UntilAt      time.Time
-	QueuedAt     time.Time
+	WorkflowID string
+	// RetryOfWorkflowID links a retry run to the workflow whose failures it re-runs.
+	RetryOfWorkflowID string
+	UserID            uint
+	ConnectionID      uint
+	LabelName         string
+	SinceAt           time.Time
+	UntilAt           time.Time
+	// Filter keeps the optional search query and label filters so that retries and resumes list the same messages.
+	Filter   FetchFilter
+	QueuedAt time.Time
+	// DryRun marks a preview run that never writes emails, vendors or billings.
+	DryRun bool
+	// FanOut marks the parent of a sync-all run. It has no connection of its own;
+	// its status and stage counts are derived from the children that link to it via ParentWorkflowID.
+	FanOut           bool
+	ParentWorkflowID string
 }
 
 // StageFailureRecord is the append-only failure row persisted for one workflow stage.
@@ -66,6 +88,8 @@ type StageProgress struct {
 	BusinessFailureCount  int
 	TechnicalFailureCount int
 	FailureRecords        []StageFailureRecord
+	// Handoff is saved in the same transaction as the summary so that a resumed run never repeats this stage.
+	Handoff *StageHandoff
 }
 
 // WorkflowStatusRepository persists workflow header/failure rows.
@@ -75,6 +99,16 @@ type WorkflowStatusRepository interface {
 	SaveStageProgress(ctx context.Context, progress StageProgress) error
 	Complete(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error
 	Fail(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time, errorMessage string) error
+	MarkCancelled(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time) error
+}
+
+func workflowStageIndex(stage string) int {
+	for idx, candidate := range workflowStages {
+		if candidate == stage {
+			return idx
+		}
+	}
+	return -1
 }
 
 func localizedWorkflowErrorMessage(currentStage string, err error) string {
@@ -84,6 +118,9 @@ func localizedWorkflowErrorMessage(currentStage string, err error) string {
 
 	switch strings.TrimSpace(currentStage) {
 	case "":
+		if errors.Is(err, ErrWorkflowConflict) {
+			return "同じメール連携・ラベル・期間のメール取得ワークフローが実行中のため起動しませんでした。"
+		}
 		return "メール取得ワークフローの起動に失敗しました。"
 	case workflowStageFetch:
 		return localizedFetchWorkflowErrorMessage(err)
@@ -111,6 +148,8 @@ func localizedFetchWorkflowErrorMessage(err error) string {
 		return "指定したメール連携サービスには対応していません。"
 	case errors.Is(err, mfdomain.ErrProviderLabelNotFound):
 		return "指定したGmailラベルが見つかりませんでした。"
+	case errors.Is(err, mfdomain.ErrFetchFilterUnsupported):
+		return "検索クエリとラベルの絞り込みはGmail連携でのみ指定できます。"
 	case errors.Is(err, mfdomain.ErrProviderListFailed):
 		return "Gmailからメール一覧を取得できませんでした。時間をおいて再試行してください。"
 	case errors.Is(err, mfdomain.ErrProviderSessionBuildFailed):
@@ -145,7 +184,7 @@ func localizedGmailSessionBuildErrorMessage(raw string) string {
 
 func buildFetchStageProgress(historyID uint64, result FetchResult) StageProgress {
 	failureRecords := make([]StageFailureRecord, 0, len(result.Failures)+1)
-	if len(result.CreatedEmails) == 0 && len(result.ExistingEmailIDs) > 0 {
+	if len(result.CreatedEmails) == 0 && len(result.ExistingEmails) == 0 && len(result.ExistingEmailIDs) > 0 {
 		failureRecords = append(failureRecords, stageFailureRecord(
 			workflowStageFetch,
 			"",
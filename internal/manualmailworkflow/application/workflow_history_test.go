@@ -3,6 +3,7 @@ package application
 import (
 	commondomain "business/internal/common/domain"
 	mfdomain "business/internal/mailfetch/domain"
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -170,3 +171,15 @@ func TestLocalizedWorkflowErrorMessage_FetchStageUsesJapaneseMessages(t *testing
 		})
 	}
 }
+
+func TestLocalizedWorkflowErrorMessage_StartConflict(t *testing.T) {
+	t.Parallel()
+
+	got := localizedWorkflowErrorMessage("", fmt.Errorf("%w: workflow_id=wf-1", ErrWorkflowConflict))
+	if want := "同じメール連携・ラベル・期間のメール取得ワークフローが実行中のため起動しませんでした。"; got != want {
+		t.Fatalf("unexpected localized message: got=%q want=%q", got, want)
+	}
+	if got := localizedWorkflowErrorMessage("", errors.New("boom")); got != "メール取得ワークフローの起動に失敗しました。" {
+		t.Fatalf("unexpected localized message: %q", got)
+	}
+}
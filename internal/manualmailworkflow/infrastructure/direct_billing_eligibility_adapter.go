@@ -41,6 +41,7 @@ func (a *DirectBillingEligibilityAdapter) Execute(ctx context.Context, cmd manua
 	result, err := a.usecase.Execute(ctx, beapp.Command{
 		UserID:        cmd.UserID,
 		ResolvedItems: targets,
+		DryRun:        cmd.DryRun,
 	})
 	if err != nil {
 		return manualapp.BillingEligibilityResult{}, err
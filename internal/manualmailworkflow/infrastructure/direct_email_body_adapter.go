@@ -0,0 +1,40 @@
+package infrastructure
+
+import (
+	mfapp "business/internal/mailfetch/application"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+)
+
+// DirectEmailBodyAdapter は mailfetch の保存済み本文 usecase を直接呼び出す。
+type DirectEmailBodyAdapter struct {
+	usecase mfapp.EmailBodyUseCase
+}
+
+// NewDirectEmailBodyAdapter は direct な保存済み本文 adapter を生成する。
+func NewDirectEmailBodyAdapter(usecase mfapp.EmailBodyUseCase) *DirectEmailBodyAdapter {
+	return &DirectEmailBodyAdapter{usecase: usecase}
+}
+
+// GetBodies は保存期間内の本文を返す。本文が保存されていないメールは返さない。
+func (a *DirectEmailBodyAdapter) GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]manualapp.StoredEmailBody, error) {
+	if a.usecase == nil {
+		return nil, errors.New("email body usecase is not configured")
+	}
+
+	bodies, err := a.usecase.GetBodies(ctx, userID, emailIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make([]manualapp.StoredEmailBody, 0, len(bodies))
+	for _, body := range bodies {
+		stored = append(stored, manualapp.StoredEmailBody{
+			EmailID:     body.EmailID,
+			Body:        body.Body,
+			Attachments: toWorkflowAttachments(body.Attachments),
+		})
+	}
+	return stored, nil
+}
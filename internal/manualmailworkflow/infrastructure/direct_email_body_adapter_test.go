@@ -0,0 +1,62 @@
+package infrastructure
+
+import (
+	cd "business/internal/common/domain"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubEmailBodyUseCase struct {
+	getBodies func(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error)
+}
+
+func (s *stubEmailBodyUseCase) GetBody(ctx context.Context, userID, emailID uint) (mfdomain.EmailBody, error) {
+	return mfdomain.EmailBody{}, mfdomain.ErrEmailBodyNotFound
+}
+
+func (s *stubEmailBodyUseCase) GetBodies(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error) {
+	return s.getBodies(ctx, userID, emailIDs)
+}
+
+func (s *stubEmailBodyUseCase) PurgeExpired(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func TestDirectEmailBodyAdapter_GetBodies(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewDirectEmailBodyAdapter(&stubEmailBodyUseCase{
+		getBodies: func(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error) {
+			if userID != 7 || len(emailIDs) != 2 {
+				t.Fatalf("unexpected request: user=%d emails=%v", userID, emailIDs)
+			}
+			return []mfdomain.EmailBody{{
+				EmailID:     11,
+				Body:        "ご請求金額 1,000円",
+				Attachments: []cd.FetchedAttachmentDTO{{Filename: "invoice.pdf", Text: "Total 1,000", Truncated: true}},
+			}}, nil
+		},
+	})
+
+	bodies, err := adapter.GetBodies(context.Background(), 7, []uint{11, 12})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bodies) != 1 || bodies[0].EmailID != 11 || bodies[0].Body != "ご請求金額 1,000円" {
+		t.Fatalf("unexpected bodies: %+v", bodies)
+	}
+	if len(bodies[0].Attachments) != 1 || bodies[0].Attachments[0].Filename != "invoice.pdf" || !bodies[0].Attachments[0].Truncated {
+		t.Fatalf("unexpected attachments: %+v", bodies[0].Attachments)
+	}
+
+	failing := NewDirectEmailBodyAdapter(&stubEmailBodyUseCase{
+		getBodies: func(ctx context.Context, userID uint, emailIDs []uint) ([]mfdomain.EmailBody, error) {
+			return nil, mfdomain.ErrInvalidCommand
+		},
+	})
+	if _, err := failing.GetBodies(context.Background(), 0, []uint{11}); !errors.Is(err, mfdomain.ErrInvalidCommand) {
+		t.Fatalf("expected invalid command, got %v", err)
+	}
+}
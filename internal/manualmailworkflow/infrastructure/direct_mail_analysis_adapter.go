@@ -34,12 +34,14 @@ func (a *DirectMailAnalysisAdapter) Execute(ctx context.Context, cmd manualapp.A
 			ReceivedAt:        email.ReceivedAt,
 			Body:              email.Body,
 			BodyDigest:        email.BodyDigest,
+			Attachments:       toAnalysisAttachments(email.Attachments),
 		})
 	}
 
 	result, err := a.usecase.Execute(ctx, maapp.Command{
 		UserID: cmd.UserID,
 		Emails: emails,
+		DryRun: cmd.DryRun,
 	})
 	if err != nil {
 		return manualapp.AnalyzeResult{}, err
@@ -76,3 +78,19 @@ func (a *DirectMailAnalysisAdapter) Execute(ctx context.Context, cmd manualapp.A
 		Failures:         failures,
 	}, nil
 }
+
+func toAnalysisAttachments(attachments []manualapp.EmailAttachment) []maapp.AttachmentForAnalysis {
+	if len(attachments) == 0 {
+		return nil
+	}
+	converted := make([]maapp.AttachmentForAnalysis, 0, len(attachments))
+	for _, attachment := range attachments {
+		converted = append(converted, maapp.AttachmentForAnalysis{
+			Filename:      attachment.Filename,
+			Text:          attachment.Text,
+			Truncated:     attachment.Truncated,
+			FailureReason: attachment.FailureReason,
+		})
+	}
+	return converted
+}
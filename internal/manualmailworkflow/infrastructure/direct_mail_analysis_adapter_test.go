@@ -25,6 +25,9 @@ func TestDirectMailAnalysisAdapter_Execute_MapsFailureMessages(t *testing.T) {
 	receivedAt := time.Date(2026, 3, 24, 12, 0, 0, 0, time.UTC)
 	adapter := NewDirectMailAnalysisAdapter(&stubMailAnalysisUseCase{
 		execute: func(ctx context.Context, cmd maapp.Command) (maapp.Result, error) {
+			if attachments := cmd.Emails[0].Attachments; len(attachments) != 1 || attachments[0].Filename != "invoice.pdf" || !attachments[0].Truncated {
+				t.Fatalf("unexpected attachments: %+v", attachments)
+			}
 			return maapp.Result{
 				ParsedEmails: []maapp.ParsedEmailResultItem{
 					{
@@ -66,6 +69,7 @@ func TestDirectMailAnalysisAdapter_Execute_MapsFailureMessages(t *testing.T) {
 				ReceivedAt:        receivedAt,
 				Body:              "body",
 				BodyDigest:        "digest-msg-1",
+				Attachments:       []manualapp.EmailAttachment{{Filename: "invoice.pdf", Text: "Total 120.00", Truncated: true}},
 			},
 		},
 	})
@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	cd "business/internal/common/domain"
 	mfapp "business/internal/mailfetch/application"
 	mfdomain "business/internal/mailfetch/domain"
 	manualapp "business/internal/manualmailworkflow/application"
@@ -28,28 +29,24 @@ func (a *DirectManualMailFetchAdapter) Execute(ctx context.Context, cmd manualap
 		UserID:       cmd.UserID,
 		ConnectionID: cmd.ConnectionID,
 		Condition: mfdomain.FetchCondition{
-			LabelName: cmd.Condition.LabelName,
-			Since:     cmd.Condition.Since,
-			Until:     cmd.Condition.Until,
+			LabelName:  cmd.Condition.LabelName,
+			Since:      cmd.Condition.Since,
+			Until:      cmd.Condition.Until,
+			MessageIDs: append([]string(nil), cmd.MessageIDs...),
+			Filter: mfdomain.FetchFilter{
+				Query:             cmd.Condition.Filter.Query,
+				IncludeLabelNames: append([]string(nil), cmd.Condition.Filter.IncludeLabelNames...),
+				ExcludeLabelNames: append([]string(nil), cmd.Condition.Filter.ExcludeLabelNames...),
+			},
 		},
+		IncludeExistingEmails: cmd.IncludeExistingEmails,
+		DryRun:                cmd.DryRun,
 	})
 	if err != nil {
 		return manualapp.FetchResult{}, err
 	}
 
-	createdEmails := make([]manualapp.CreatedEmail, 0, len(result.CreatedEmails))
-	for _, createdEmail := range result.CreatedEmails {
-		createdEmails = append(createdEmails, manualapp.CreatedEmail{
-			EmailID:           createdEmail.EmailID,
-			ExternalMessageID: createdEmail.ExternalMessageID,
-			Subject:           createdEmail.Subject,
-			From:              createdEmail.From,
-			To:                append([]string{}, createdEmail.To...),
-			ReceivedAt:        createdEmail.Date,
-			Body:              createdEmail.Body,
-			BodyDigest:        createdEmail.BodyDigest,
-		})
-	}
+	createdEmails := toWorkflowCreatedEmails(result.CreatedEmails)
 
 	existingEmailIDs := make([]uint, 0, len(result.ExistingEmailIDs))
 	existingEmailIDs = append(existingEmailIDs, result.ExistingEmailIDs...)
@@ -64,9 +61,48 @@ func (a *DirectManualMailFetchAdapter) Execute(ctx context.Context, cmd manualap
 		})
 	}
 
-	return manualapp.FetchResult{
+	fetchResult := manualapp.FetchResult{
 		CreatedEmails:    createdEmails,
 		ExistingEmailIDs: existingEmailIDs,
 		Failures:         failures,
-	}, nil
+	}
+	if cmd.IncludeExistingEmails {
+		fetchResult.ExistingEmails = toWorkflowCreatedEmails(result.ExistingEmails)
+	}
+
+	return fetchResult, nil
+}
+
+func toWorkflowCreatedEmails(emails []mfapp.CreatedEmail) []manualapp.CreatedEmail {
+	createdEmails := make([]manualapp.CreatedEmail, 0, len(emails))
+	for _, createdEmail := range emails {
+		createdEmails = append(createdEmails, manualapp.CreatedEmail{
+			EmailID:           createdEmail.EmailID,
+			ExternalMessageID: createdEmail.ExternalMessageID,
+			Subject:           createdEmail.Subject,
+			From:              createdEmail.From,
+			To:                append([]string{}, createdEmail.To...),
+			ReceivedAt:        createdEmail.Date,
+			Body:              createdEmail.Body,
+			BodyDigest:        createdEmail.BodyDigest,
+			Attachments:       toWorkflowAttachments(createdEmail.Attachments),
+		})
+	}
+	return createdEmails
+}
+
+func toWorkflowAttachments(attachments []cd.FetchedAttachmentDTO) []manualapp.EmailAttachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+	converted := make([]manualapp.EmailAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		converted = append(converted, manualapp.EmailAttachment{
+			Filename:      attachment.Filename,
+			Text:          attachment.Text,
+			Truncated:     attachment.Truncated,
+			FailureReason: attachment.FailureReason,
+		})
+	}
+	return converted
 }
@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	cd "business/internal/common/domain"
 	mfapp "business/internal/mailfetch/application"
 	mfdomain "business/internal/mailfetch/domain"
 	manualapp "business/internal/manualmailworkflow/application"
@@ -34,6 +35,10 @@ func TestDirectManualMailFetchAdapter_Execute_MapsFailureMessages(t *testing.T)
 						Date:              receivedAt,
 						Body:              "body",
 						BodyDigest:        "digest-msg-1",
+						Attachments: []cd.FetchedAttachmentDTO{
+							{Filename: "invoice.pdf", MimeType: "application/pdf", Size: 2048, Text: "Total 120.00"},
+							{Filename: "scan.pdf", MimeType: "application/pdf", FailureReason: cd.AttachmentFailureNoText},
+						},
 					},
 				},
 				ExistingEmailIDs: []uint{202},
@@ -65,7 +70,83 @@ func TestDirectManualMailFetchAdapter_Execute_MapsFailureMessages(t *testing.T)
 	if len(result.CreatedEmails) != 1 || result.CreatedEmails[0].BodyDigest != "digest-msg-1" {
 		t.Fatalf("unexpected created emails: %+v", result.CreatedEmails)
 	}
+	if attachments := result.CreatedEmails[0].Attachments; len(attachments) != 2 ||
+		attachments[0].Text != "Total 120.00" || attachments[1].FailureReason != cd.AttachmentFailureNoText {
+		t.Fatalf("unexpected attachments: %+v", attachments)
+	}
 	if len(result.Failures) != 1 || result.Failures[0].Message != "msg-2 の取得メール保存に失敗しました。" {
 		t.Fatalf("expected failure message to be mapped, got %+v", result.Failures)
 	}
 }
+
+func TestDirectManualMailFetchAdapter_Execute_PassesRetryMessageIDs(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewDirectManualMailFetchAdapter(&stubMailFetchUseCase{
+		execute: func(ctx context.Context, cmd mfapp.Command) (mfapp.Result, error) {
+			if len(cmd.Condition.MessageIDs) != 2 || cmd.Condition.MessageIDs[0] != "msg-1" || cmd.Condition.MessageIDs[1] != "msg-2" {
+				t.Fatalf("unexpected message ids: %+v", cmd.Condition.MessageIDs)
+			}
+			if !cmd.IncludeExistingEmails {
+				t.Fatal("expected existing emails to be requested")
+			}
+			return mfapp.Result{
+				ExistingEmailIDs: []uint{202},
+				ExistingEmails: []mfapp.CreatedEmail{
+					{EmailID: 202, ExternalMessageID: "msg-2", Body: "body", BodyDigest: "digest-msg-2"},
+				},
+			}, nil
+		},
+	})
+
+	result, err := adapter.Execute(context.Background(), manualapp.FetchCommand{
+		UserID:       1,
+		ConnectionID: 2,
+		Condition: manualapp.FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+		MessageIDs:            []string{"msg-1", "msg-2"},
+		IncludeExistingEmails: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(result.ExistingEmails) != 1 || result.ExistingEmails[0].EmailID != 202 || result.ExistingEmails[0].Body != "body" {
+		t.Fatalf("unexpected existing emails: %+v", result.ExistingEmails)
+	}
+}
+
+func TestDirectManualMailFetchAdapter_Execute_PassesFetchFilter(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewDirectManualMailFetchAdapter(&stubMailFetchUseCase{
+		execute: func(ctx context.Context, cmd mfapp.Command) (mfapp.Result, error) {
+			filter := cmd.Condition.Filter
+			if filter.Query != "from:billing@example.com" ||
+				len(filter.IncludeLabelNames) != 1 || filter.IncludeLabelNames[0] != "receipts" ||
+				len(filter.ExcludeLabelNames) != 1 || filter.ExcludeLabelNames[0] != "archived" {
+				t.Fatalf("unexpected filter: %+v", filter)
+			}
+			return mfapp.Result{}, nil
+		},
+	})
+
+	if _, err := adapter.Execute(context.Background(), manualapp.FetchCommand{
+		UserID:       1,
+		ConnectionID: 2,
+		Condition: manualapp.FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+			Filter: manualapp.FetchFilter{
+				Query:             "from:billing@example.com",
+				IncludeLabelNames: []string{"receipts"},
+				ExcludeLabelNames: []string{"archived"},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package infrastructure
+
+import (
+	"business/internal/library/mailarchive"
+	"business/internal/library/mailmime"
+	"business/internal/library/timewrapper"
+	macdomain "business/internal/mailaccountconnection/domain"
+	mfdomain "business/internal/mailfetch/domain"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// importedMessageIDPrefix は取り込んだメールの外部メール ID の接頭辞。
+const importedMessageIDPrefix = "file:"
+
+type fileConnectionEnsurer interface {
+	EnsureFileConnection(ctx context.Context, userID uint, now time.Time) (macdomain.EmailCredential, error)
+}
+
+type fileMailSaver interface {
+	SaveFileMails(ctx context.Context, connectionID uint, labelName string, mails []mfdomain.FileMail) error
+	DeleteFileMails(ctx context.Context, connectionID uint, labelName string) error
+}
+
+// DirectMailImportAdapter はアップロードされたファイルを mailaccountconnection と mailfetch の repository に直接保存する。
+type DirectMailImportAdapter struct {
+	connections fileConnectionEnsurer
+	mails       fileMailSaver
+	clock       timewrapper.ClockInterface
+}
+
+// NewDirectMailImportAdapter は direct なメール取り込み adapter を生成する。
+func NewDirectMailImportAdapter(
+	connections fileConnectionEnsurer,
+	mails fileMailSaver,
+	clock timewrapper.ClockInterface,
+) *DirectMailImportAdapter {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	return &DirectMailImportAdapter{
+		connections: connections,
+		mails:       mails,
+		clock:       clock,
+	}
+}
+
+// Store は .eml / mbox ファイルをメールに分割し、ユーザーの "file" provider のメール連携へ保存する。
+// 読み取れないメールもそのまま保存し、fetch stage で正規化失敗として記録させる。
+func (a *DirectMailImportAdapter) Store(ctx context.Context, req manualapp.MailImportRequest) (manualapp.StoredMailImport, error) {
+	if a.connections == nil {
+		return manualapp.StoredMailImport{}, errors.New("file connection repository is not configured")
+	}
+	if a.mails == nil {
+		return manualapp.StoredMailImport{}, errors.New("file mail repository is not configured")
+	}
+
+	var (
+		stored manualapp.StoredMailImport
+		mails  []mfdomain.FileMail
+		seen   = make(map[string]struct{})
+	)
+	for _, file := range req.Files {
+		messages, err := mailarchive.Split(file.Content)
+		if err != nil {
+			return manualapp.StoredMailImport{}, fmt.Errorf("%w: %s: %v", manualapp.ErrMailImportInvalid, file.Name, err)
+		}
+
+		for _, raw := range messages {
+			mail := toFileMail(raw)
+			if _, ok := seen[mail.ExternalMessageID]; ok {
+				continue
+			}
+			seen[mail.ExternalMessageID] = struct{}{}
+
+			if len(mails) >= manualapp.MaxMailImportMessages {
+				return manualapp.StoredMailImport{}, fmt.Errorf("%w: up to %d messages can be imported at once", manualapp.ErrMailImportTooLarge, manualapp.MaxMailImportMessages)
+			}
+			mails = append(mails, mail)
+
+			if mail.ReceivedAt == nil {
+				continue
+			}
+			if stored.OldestReceivedAt.IsZero() || mail.ReceivedAt.Before(stored.OldestReceivedAt) {
+				stored.OldestReceivedAt = *mail.ReceivedAt
+			}
+			if mail.ReceivedAt.After(stored.LatestReceivedAt) {
+				stored.LatestReceivedAt = *mail.ReceivedAt
+			}
+		}
+	}
+
+	connection, err := a.connections.EnsureFileConnection(ctx, req.UserID, a.clock.Now().UTC())
+	if err != nil {
+		return manualapp.StoredMailImport{}, err
+	}
+	if err := a.mails.SaveFileMails(ctx, connection.ID, req.LabelName, mails); err != nil {
+		return manualapp.StoredMailImport{}, err
+	}
+
+	stored.ConnectionID = connection.ID
+	stored.MessageCount = len(mails)
+	return stored, nil
+}
+
+// Discard は Store で保存した取り込みのメールを削除する。
+func (a *DirectMailImportAdapter) Discard(ctx context.Context, connectionID uint, labelName string) error {
+	if a.mails == nil {
+		return errors.New("file mail repository is not configured")
+	}
+	return a.mails.DeleteFileMails(ctx, connectionID, labelName)
+}
+
+// toFileMail は Message-ID、無ければ本文全体から外部メール ID を決める。
+// 同じメールを別の mbox から取り込んでも同じ ID になるため、既存メールとして扱われる。
+func toFileMail(raw []byte) mfdomain.FileMail {
+	mail := mfdomain.FileMail{Raw: raw}
+
+	source := raw
+	if parsed, err := mailmime.Parse(raw); err == nil {
+		if parsed.MessageID != "" {
+			source = []byte(parsed.MessageID)
+		}
+		if !parsed.Date.IsZero() {
+			receivedAt := parsed.Date.UTC()
+			mail.ReceivedAt = &receivedAt
+		}
+	}
+
+	sum := sha256.Sum256(source)
+	mail.ExternalMessageID = importedMessageIDPrefix + hex.EncodeToString(sum[:16])
+	return mail
+}
@@ -0,0 +1,181 @@
+package infrastructure
+
+import (
+	macdomain "business/internal/mailaccountconnection/domain"
+	mfdomain "business/internal/mailfetch/domain"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubFileConnectionEnsurer struct {
+	ensure func(ctx context.Context, userID uint, now time.Time) (macdomain.EmailCredential, error)
+}
+
+func (s *stubFileConnectionEnsurer) EnsureFileConnection(ctx context.Context, userID uint, now time.Time) (macdomain.EmailCredential, error) {
+	return s.ensure(ctx, userID, now)
+}
+
+type stubFileMailSaver struct {
+	save       func(ctx context.Context, connectionID uint, labelName string, mails []mfdomain.FileMail) error
+	deleteMail func(ctx context.Context, connectionID uint, labelName string) error
+}
+
+func (s *stubFileMailSaver) SaveFileMails(ctx context.Context, connectionID uint, labelName string, mails []mfdomain.FileMail) error {
+	return s.save(ctx, connectionID, labelName, mails)
+}
+
+func (s *stubFileMailSaver) DeleteFileMails(ctx context.Context, connectionID uint, labelName string) error {
+	return s.deleteMail(ctx, connectionID, labelName)
+}
+
+func TestDirectMailImportAdapter_Store_SplitsFilesIntoMessages(t *testing.T) {
+	t.Parallel()
+
+	eml := "Message-ID: <a@example.com>\r\nSubject: first\r\nDate: Tue, 15 Sep 2026 09:00:00 +0900\r\n\r\nbody\r\n"
+	mbox := strings.Join([]string{
+		"From billing@example.com Tue Sep 01 00:00:00 2026",
+		"Message-ID: <b@example.com>",
+		"Subject: second",
+		"Date: Tue, 01 Sep 2026 09:00:00 +0000",
+		"",
+		"body",
+		"",
+		"From billing@example.com Tue Sep 01 00:00:00 2026",
+		"Subject: undated",
+		"",
+		"body",
+		"",
+		"From billing@example.com Tue Sep 01 00:00:00 2026",
+		"Message-ID: <a@example.com>",
+		"Subject: first again",
+		"Date: Tue, 15 Sep 2026 09:00:00 +0900",
+		"",
+		"body",
+		"",
+	}, "\n")
+
+	var saved []mfdomain.FileMail
+	adapter := NewDirectMailImportAdapter(
+		&stubFileConnectionEnsurer{
+			ensure: func(ctx context.Context, userID uint, now time.Time) (macdomain.EmailCredential, error) {
+				if userID != 7 {
+					t.Fatalf("unexpected user id: %d", userID)
+				}
+				return macdomain.EmailCredential{ID: 31, UserID: userID, Type: macdomain.FileConnectionType}, nil
+			},
+		},
+		&stubFileMailSaver{
+			save: func(ctx context.Context, connectionID uint, labelName string, mails []mfdomain.FileMail) error {
+				if connectionID != 31 || labelName != "imports/wf" {
+					t.Fatalf("unexpected save target: connection=%d label=%s", connectionID, labelName)
+				}
+				saved = mails
+				return nil
+			},
+		},
+		nil,
+	)
+
+	stored, err := adapter.Store(context.Background(), manualapp.MailImportRequest{
+		UserID:    7,
+		LabelName: "imports/wf",
+		Files: []manualapp.MailImportFile{
+			{Name: "first.eml", Content: []byte(eml)},
+			{Name: "archive.mbox", Content: []byte(mbox)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Store returned error: %v", err)
+	}
+
+	if stored.ConnectionID != 31 || stored.MessageCount != 3 || len(saved) != 3 {
+		t.Fatalf("unexpected stored import: %+v saved=%d", stored, len(saved))
+	}
+	if !stored.OldestReceivedAt.Equal(time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)) ||
+		!stored.LatestReceivedAt.Equal(time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected period: %s - %s", stored.OldestReceivedAt, stored.LatestReceivedAt)
+	}
+	for _, mail := range saved {
+		if !strings.HasPrefix(mail.ExternalMessageID, importedMessageIDPrefix) || len(mail.ExternalMessageID) != len(importedMessageIDPrefix)+32 {
+			t.Fatalf("unexpected external message id: %s", mail.ExternalMessageID)
+		}
+	}
+	if saved[2].ReceivedAt != nil {
+		t.Fatalf("undated message must not have a received time: %v", saved[2].ReceivedAt)
+	}
+}
+
+func TestDirectMailImportAdapter_Store_RejectsInvalidUploads(t *testing.T) {
+	t.Parallel()
+
+	var tooMany strings.Builder
+	for i := 0; i <= manualapp.MaxMailImportMessages; i++ {
+		tooMany.WriteString("From billing@example.com Tue Sep 01 00:00:00 2026\n")
+		tooMany.WriteString("Message-ID: <" + strings.Repeat("x", i+1) + "@example.com>\n\nbody\n\n")
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{name: "empty mbox", content: "From billing@example.com Tue Sep 01 00:00:00 2026\n\n", wantErr: manualapp.ErrMailImportInvalid},
+		{name: "too many messages", content: tooMany.String(), wantErr: manualapp.ErrMailImportTooLarge},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			adapter := NewDirectMailImportAdapter(
+				&stubFileConnectionEnsurer{
+					ensure: func(ctx context.Context, userID uint, now time.Time) (macdomain.EmailCredential, error) {
+						t.Fatal("EnsureFileConnection must not be called")
+						return macdomain.EmailCredential{}, nil
+					},
+				},
+				&stubFileMailSaver{
+					save: func(ctx context.Context, connectionID uint, labelName string, mails []mfdomain.FileMail) error {
+						t.Fatal("SaveFileMails must not be called")
+						return nil
+					},
+				},
+				nil,
+			)
+
+			_, err := adapter.Store(context.Background(), manualapp.MailImportRequest{
+				UserID:    7,
+				LabelName: "imports/wf",
+				Files:     []manualapp.MailImportFile{{Name: "archive.mbox", Content: []byte(tt.content)}},
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unexpected error: got=%v want=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDirectMailImportAdapter_Discard_DeletesStoredMessages(t *testing.T) {
+	t.Parallel()
+
+	var deletedConnectionID uint
+	var deletedLabel string
+	adapter := NewDirectMailImportAdapter(nil, &stubFileMailSaver{
+		deleteMail: func(ctx context.Context, connectionID uint, labelName string) error {
+			deletedConnectionID, deletedLabel = connectionID, labelName
+			return nil
+		},
+	}, nil)
+
+	if err := adapter.Discard(context.Background(), 31, "imports/wf"); err != nil {
+		t.Fatalf("Discard returned error: %v", err)
+	}
+	if deletedConnectionID != 31 || deletedLabel != "imports/wf" {
+		t.Fatalf("unexpected delete target: connection=%d label=%s", deletedConnectionID, deletedLabel)
+	}
+}
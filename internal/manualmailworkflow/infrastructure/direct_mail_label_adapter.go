@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	mfapp "business/internal/mailfetch/application"
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+)
+
+// DirectMailLabelAdapter は mailfetch のラベル一覧 usecase を直接呼び出す。
+type DirectMailLabelAdapter struct {
+	usecase mfapp.LabelUseCase
+}
+
+// NewDirectMailLabelAdapter は direct なラベル一覧 adapter を生成する。
+func NewDirectMailLabelAdapter(usecase mfapp.LabelUseCase) *DirectMailLabelAdapter {
+	return &DirectMailLabelAdapter{usecase: usecase}
+}
+
+// ListLabelNames はメール連携のラベル名を返す。ラベルを持たない provider では ok=false を返す。
+func (a *DirectMailLabelAdapter) ListLabelNames(ctx context.Context, userID, connectionID uint) ([]string, bool, error) {
+	if a.usecase == nil {
+		return nil, false, errors.New("mail label usecase is not configured")
+	}
+
+	labels, err := a.usecase.ListLabels(ctx, userID, connectionID)
+	if err != nil {
+		if errors.Is(err, mfdomain.ErrLabelListUnsupported) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	return names, true, nil
+}
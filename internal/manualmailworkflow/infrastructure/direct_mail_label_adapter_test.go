@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	mfdomain "business/internal/mailfetch/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubMailLabelUseCase struct {
+	listLabels func(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error)
+}
+
+func (s *stubMailLabelUseCase) ListLabels(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error) {
+	return s.listLabels(ctx, userID, connectionID)
+}
+
+func TestDirectMailLabelAdapter_ListLabelNames(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewDirectMailLabelAdapter(&stubMailLabelUseCase{
+		listLabels: func(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error) {
+			if userID != 7 || connectionID != 12 {
+				t.Fatalf("unexpected request: user=%d connection=%d", userID, connectionID)
+			}
+			return []mfdomain.MailLabel{{Name: "INBOX", System: true}, {Name: "billing"}}, nil
+		},
+	})
+
+	names, ok, err := adapter.ListLabelNames(context.Background(), 7, 12)
+	if err != nil || !ok {
+		t.Fatalf("unexpected result: ok=%v err=%v", ok, err)
+	}
+	if len(names) != 2 || names[0] != "INBOX" || names[1] != "billing" {
+		t.Fatalf("unexpected names: %+v", names)
+	}
+}
+
+func TestDirectMailLabelAdapter_ListLabelNames_ProviderWithoutLabels(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewDirectMailLabelAdapter(&stubMailLabelUseCase{
+		listLabels: func(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error) {
+			return nil, mfdomain.ErrLabelListUnsupported
+		},
+	})
+
+	names, ok, err := adapter.ListLabelNames(context.Background(), 7, 12)
+	if err != nil || ok || names != nil {
+		t.Fatalf("unexpected result: names=%v ok=%v err=%v", names, ok, err)
+	}
+
+	failing := NewDirectMailLabelAdapter(&stubMailLabelUseCase{
+		listLabels: func(ctx context.Context, userID, connectionID uint) ([]mfdomain.MailLabel, error) {
+			return nil, mfdomain.ErrProviderListFailed
+		},
+	})
+	if _, _, err := failing.ListLabelNames(context.Background(), 7, 12); !errors.Is(err, mfdomain.ErrProviderListFailed) {
+		t.Fatalf("expected provider error, got %v", err)
+	}
+}
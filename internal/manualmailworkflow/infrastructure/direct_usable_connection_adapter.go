@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	macdomain "business/internal/mailaccountconnection/domain"
+	mfdomain "business/internal/mailfetch/domain"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+)
+
+type usableConnectionReader interface {
+	FindUsableConnection(ctx context.Context, userID, connectionID uint) (mfdomain.ConnectionRef, error)
+	ListUsableConnections(ctx context.Context, userID uint) ([]mfdomain.ConnectionRef, error)
+}
+
+// DirectUsableConnectionAdapter は mailfetch の connection reader を直接呼び出し、workflow が取得できるメール連携を返す。
+type DirectUsableConnectionAdapter struct {
+	connections usableConnectionReader
+}
+
+// NewDirectUsableConnectionAdapter は direct なメール連携一覧 adapter を生成する。
+func NewDirectUsableConnectionAdapter(connections usableConnectionReader) *DirectUsableConnectionAdapter {
+	return &DirectUsableConnectionAdapter{connections: connections}
+}
+
+// ListUsableConnections はユーザーの取得できるメール連携を作成順に返す。
+// file 連携は、取り込んだメールをアップロードごとの workflow でだけ取得するので含めない。
+func (a *DirectUsableConnectionAdapter) ListUsableConnections(ctx context.Context, userID uint) ([]manualapp.UsableConnection, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if a.connections == nil {
+		return nil, errors.New("connection reader is not configured")
+	}
+
+	refs, err := a.connections.ListUsableConnections(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	connections := make([]manualapp.UsableConnection, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Provider == macdomain.FileConnectionType {
+			continue
+		}
+		connections = append(connections, manualapp.UsableConnection{ConnectionID: ref.ConnectionID, Provider: ref.Provider})
+	}
+	return connections, nil
+}
+
+// FindUsableConnection は 1 件のメール連携の provider を返す。見つからない・利用できない連携では found=false を返す。
+func (a *DirectUsableConnectionAdapter) FindUsableConnection(ctx context.Context, userID, connectionID uint) (manualapp.UsableConnection, bool, error) {
+	if ctx == nil {
+		return manualapp.UsableConnection{}, false, logger.ErrNilContext
+	}
+	if a.connections == nil {
+		return manualapp.UsableConnection{}, false, errors.New("connection reader is not configured")
+	}
+
+	ref, err := a.connections.FindUsableConnection(ctx, userID, connectionID)
+	if err != nil {
+		if errors.Is(err, mfdomain.ErrConnectionNotFound) || errors.Is(err, mfdomain.ErrConnectionUnavailable) {
+			return manualapp.UsableConnection{}, false, nil
+		}
+		return manualapp.UsableConnection{}, false, err
+	}
+	return manualapp.UsableConnection{ConnectionID: ref.ConnectionID, Provider: ref.Provider}, true, nil
+}
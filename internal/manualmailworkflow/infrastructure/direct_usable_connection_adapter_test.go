@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	mfdomain "business/internal/mailfetch/domain"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stubUsableConnectionReader struct {
+	refs      []mfdomain.ConnectionRef
+	listCalls int
+	errs      map[uint]error
+}
+
+func (s *stubUsableConnectionReader) FindUsableConnection(ctx context.Context, userID, connectionID uint) (mfdomain.ConnectionRef, error) {
+	if err := s.errs[connectionID]; err != nil {
+		return mfdomain.ConnectionRef{}, err
+	}
+	for _, ref := range s.refs {
+		if ref.ConnectionID == connectionID {
+			return ref, nil
+		}
+	}
+	return mfdomain.ConnectionRef{}, mfdomain.ErrConnectionNotFound
+}
+
+func (s *stubUsableConnectionReader) ListUsableConnections(ctx context.Context, userID uint) ([]mfdomain.ConnectionRef, error) {
+	s.listCalls++
+	return s.refs, nil
+}
+
+func TestDirectUsableConnectionAdapter_ListUsableConnections(t *testing.T) {
+	t.Parallel()
+
+	reader := &stubUsableConnectionReader{refs: []mfdomain.ConnectionRef{
+		{ConnectionID: 30, UserID: 10, Provider: "gmail"},
+		{ConnectionID: 31, UserID: 10, Provider: "imap"},
+		{ConnectionID: 34, UserID: 10, Provider: "file"},
+	}}
+	adapter := NewDirectUsableConnectionAdapter(reader)
+
+	connections, err := adapter.ListUsableConnections(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, []manualapp.UsableConnection{
+		{ConnectionID: 30, Provider: "gmail"},
+		{ConnectionID: 31, Provider: "imap"},
+	}, connections)
+	require.Equal(t, 1, reader.listCalls)
+}
+
+func TestDirectUsableConnectionAdapter_FindUsableConnection(t *testing.T) {
+	t.Parallel()
+
+	reader := &stubUsableConnectionReader{
+		refs: []mfdomain.ConnectionRef{{ConnectionID: 31, UserID: 10, Provider: "imap"}},
+		errs: map[uint]error{
+			32: mfdomain.ErrConnectionUnavailable,
+			33: errors.New("db down"),
+		},
+	}
+	adapter := NewDirectUsableConnectionAdapter(reader)
+	ctx := context.Background()
+
+	connection, found, err := adapter.FindUsableConnection(ctx, 10, 31)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, manualapp.UsableConnection{ConnectionID: 31, Provider: "imap"}, connection)
+
+	for _, connectionID := range []uint{32, 40} {
+		_, found, err = adapter.FindUsableConnection(ctx, 10, connectionID)
+		require.NoError(t, err)
+		require.False(t, found)
+	}
+
+	_, _, err = adapter.FindUsableConnection(ctx, 10, 33)
+	require.Error(t, err)
+}
@@ -27,12 +27,18 @@ func (a *DirectVendorResolutionAdapter) Execute(ctx context.Context, cmd manuala
 
 	result, err := a.usecase.Execute(ctx, vrapp.Command{
 		UserID: cmd.UserID,
+		DryRun: cmd.DryRun,
 		ParsedEmails: func() []vrapp.ResolutionTarget {
 			targets := make([]vrapp.ResolutionTarget, 0, len(cmd.ParsedEmails))
-			for _, parsedEmail := range cmd.ParsedEmails {
-				parsedEmailMap[parsedEmail.ParsedEmailID] = parsedEmail
+			for idx, parsedEmail := range cmd.ParsedEmails {
+				// dry-run の parsed email は未保存で ID がすべて 0 のため、結果の対応付けには入力順の番号を使う。
+				key := parsedEmail.ParsedEmailID
+				if cmd.DryRun {
+					key = uint(idx + 1)
+				}
+				parsedEmailMap[key] = parsedEmail
 				targets = append(targets, vrapp.ResolutionTarget{
-					ParsedEmailID:     parsedEmail.ParsedEmailID,
+					ParsedEmailID:     key,
 					EmailID:           parsedEmail.EmailID,
 					ExternalMessageID: parsedEmail.ExternalMessageID,
 					Subject:           parsedEmail.Subject,
@@ -48,12 +54,18 @@ func (a *DirectVendorResolutionAdapter) Execute(ctx context.Context, cmd manuala
 	if err != nil {
 		return manualapp.VendorResolutionResult{}, err
 	}
+	originalParsedEmailID := func(key uint) uint {
+		if cmd.DryRun {
+			return parsedEmailMap[key].ParsedEmailID
+		}
+		return key
+	}
 
 	resolvedItems := make([]manualapp.ResolvedItem, 0, len(result.ResolvedItems))
 	for _, item := range result.ResolvedItems {
 		data := parsedEmailMap[item.ParsedEmailID]
 		resolvedItems = append(resolvedItems, manualapp.ResolvedItem{
-			ParsedEmailID:     item.ParsedEmailID,
+			ParsedEmailID:     originalParsedEmailID(item.ParsedEmailID),
 			EmailID:           item.EmailID,
 			ExternalMessageID: item.ExternalMessageID,
 			VendorID:          item.VendorID,
@@ -66,7 +78,7 @@ func (a *DirectVendorResolutionAdapter) Execute(ctx context.Context, cmd manuala
 	unresolvedItems := make([]manualapp.UnresolvedItem, 0, len(result.UnresolvedItems))
 	for _, item := range result.UnresolvedItems {
 		unresolvedItems = append(unresolvedItems, manualapp.UnresolvedItem{
-			ParsedEmailID:       item.ParsedEmailID,
+			ParsedEmailID:       originalParsedEmailID(item.ParsedEmailID),
 			EmailID:             item.EmailID,
 			ExternalMessageID:   item.ExternalMessageID,
 			ReasonCode:          item.ReasonCode,
@@ -78,7 +90,7 @@ func (a *DirectVendorResolutionAdapter) Execute(ctx context.Context, cmd manuala
 	failures := make([]manualapp.VendorResolutionFailure, 0, len(result.Failures))
 	for _, failure := range result.Failures {
 		failures = append(failures, manualapp.VendorResolutionFailure{
-			ParsedEmailID:     failure.ParsedEmailID,
+			ParsedEmailID:     originalParsedEmailID(failure.ParsedEmailID),
 			EmailID:           failure.EmailID,
 			ExternalMessageID: failure.ExternalMessageID,
 			Stage:             failure.Stage,
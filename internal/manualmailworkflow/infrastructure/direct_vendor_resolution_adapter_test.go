@@ -94,3 +94,49 @@ func TestDirectVendorResolutionAdapter_Execute_SupplementsParsedEmailData(t *tes
 		t.Fatalf("expected failure message to be mapped, got %+v", result.Failures)
 	}
 }
+
+func TestDirectVendorResolutionAdapter_Execute_DryRunKeepsUnsavedParsedEmailsApart(t *testing.T) {
+	t.Parallel()
+
+	firstNumber := "INV-001"
+	secondNumber := "INV-002"
+	adapter := NewDirectVendorResolutionAdapter(&stubVendorResolutionUseCase{
+		execute: func(ctx context.Context, cmd vrapp.Command) (vrapp.Result, error) {
+			if !cmd.DryRun || len(cmd.ParsedEmails) != 2 {
+				t.Fatalf("unexpected command: %+v", cmd)
+			}
+			if cmd.ParsedEmails[0].ParsedEmailID == cmd.ParsedEmails[1].ParsedEmailID {
+				t.Fatalf("unsaved parsed emails must get distinct keys: %+v", cmd.ParsedEmails)
+			}
+			return vrapp.Result{
+				ResolvedItems: []vrdomain.ResolvedItem{
+					{ParsedEmailID: cmd.ParsedEmails[1].ParsedEmailID, ExternalMessageID: "msg-2", VendorName: "Acme"},
+					{ParsedEmailID: cmd.ParsedEmails[0].ParsedEmailID, ExternalMessageID: "msg-1", VendorName: "Acme"},
+				},
+				ResolvedCount: 2,
+			}, nil
+		},
+	})
+
+	result, err := adapter.Execute(context.Background(), manualapp.VendorResolutionCommand{
+		UserID: 1,
+		ParsedEmails: []manualapp.ParsedEmail{
+			{ExternalMessageID: "msg-1", Data: commondomain.ParsedEmail{BillingNumber: &firstNumber}},
+			{ExternalMessageID: "msg-2", Data: commondomain.ParsedEmail{BillingNumber: &secondNumber}},
+		},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if len(result.ResolvedItems) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.ResolvedItems[0].ParsedEmailID != 0 || *result.ResolvedItems[0].Data.BillingNumber != secondNumber {
+		t.Fatalf("unexpected msg-2 item: %+v", result.ResolvedItems[0])
+	}
+	if result.ResolvedItems[1].ParsedEmailID != 0 || *result.ResolvedItems[1].Data.BillingNumber != firstNumber {
+		t.Fatalf("unexpected msg-1 item: %+v", result.ResolvedItems[1])
+	}
+}
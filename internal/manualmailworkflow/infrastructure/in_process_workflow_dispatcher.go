@@ -39,19 +39,7 @@ func (d *InProcessWorkflowDispatcher) Dispatch(ctx context.Context, job manualap
 }
 
 func (d *InProcessWorkflowDispatcher) run(requestCtx context.Context, job manualapp.DispatchJob) {
-	bgCtx := context.Background()
-
-	if requestID, ok := logger.RequestIDFromContext(requestCtx); ok {
-		if next, err := logger.ContextWithRequestID(bgCtx, requestID); err == nil {
-			bgCtx = next
-		}
-	}
-	if next, err := logger.ContextWithJobID(bgCtx, job.WorkflowID); err == nil {
-		bgCtx = next
-	}
-	if next, err := logger.ContextWithUserID(bgCtx, job.UserID); err == nil {
-		bgCtx = next
-	}
+	bgCtx := newBackgroundWorkflowContext(requestIDFromContext(requestCtx), job)
 
 	reqLog := d.log
 	if withContext, err := d.log.WithContext(bgCtx); err == nil {
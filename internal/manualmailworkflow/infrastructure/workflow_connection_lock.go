@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	redisclient "business/internal/library/redis"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultWorkflowConnectionLockTTL = 30 * time.Second
+	workflowConnectionLockKeyPrefix  = "manual_mail_workflow:connection_lock:"
+)
+
+// RedisWorkflowConnectionLock is a Redis lease keyed by mail-account connection.
+// The lease only guards workflow acceptance; the TTL releases it if the process dies before Release.
+type RedisWorkflowConnectionLock struct {
+	client redisclient.ClientInterface
+	ttl    time.Duration
+}
+
+// NewRedisWorkflowConnectionLock creates a connection lock backed by Redis leases.
+func NewRedisWorkflowConnectionLock(client redisclient.ClientInterface, ttl time.Duration) *RedisWorkflowConnectionLock {
+	if ttl <= 0 {
+		ttl = defaultWorkflowConnectionLockTTL
+	}
+
+	return &RedisWorkflowConnectionLock{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// Acquire takes the lease for the connection. It returns false while another token holds it.
+func (l *RedisWorkflowConnectionLock) Acquire(ctx context.Context, connectionID uint, token string) (bool, error) {
+	if l.client == nil {
+		return false, &redisclient.ErrRedisUnavailable{Err: errors.New("redis client is not configured")}
+	}
+
+	return l.client.AcquireLease(ctx, workflowConnectionLockKey(connectionID), token, l.ttl)
+}
+
+// Release drops the lease when it is still held by token.
+func (l *RedisWorkflowConnectionLock) Release(ctx context.Context, connectionID uint, token string) error {
+	if l.client == nil {
+		return &redisclient.ErrRedisUnavailable{Err: errors.New("redis client is not configured")}
+	}
+
+	return l.client.ReleaseLease(ctx, workflowConnectionLockKey(connectionID), token)
+}
+
+func workflowConnectionLockKey(connectionID uint) string {
+	return fmt.Sprintf("%s%d", workflowConnectionLockKeyPrefix, connectionID)
+}
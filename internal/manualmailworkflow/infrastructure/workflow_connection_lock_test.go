@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	redisclient "business/internal/library/redis"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubLeaseRedisClient struct {
+	redisclient.ClientInterface
+	leases map[string]string
+	ttls   map[string]time.Duration
+}
+
+func (s *stubLeaseRedisClient) AcquireLease(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	if _, ok := s.leases[key]; ok {
+		return false, nil
+	}
+	s.leases[key] = token
+	s.ttls[key] = ttl
+	return true, nil
+}
+
+func (s *stubLeaseRedisClient) ReleaseLease(ctx context.Context, key, token string) error {
+	if s.leases[key] == token {
+		delete(s.leases, key)
+	}
+	return nil
+}
+
+func TestRedisWorkflowConnectionLock_AcquireRelease(t *testing.T) {
+	t.Parallel()
+
+	client := &stubLeaseRedisClient{leases: map[string]string{}, ttls: map[string]time.Duration{}}
+	lock := NewRedisWorkflowConnectionLock(client, 0)
+	ctx := context.Background()
+
+	acquired, err := lock.Acquire(ctx, 12, "wf-1")
+	if err != nil || !acquired {
+		t.Fatalf("expected first acquire to succeed: acquired=%v err=%v", acquired, err)
+	}
+	if client.ttls["manual_mail_workflow:connection_lock:12"] != defaultWorkflowConnectionLockTTL {
+		t.Fatalf("unexpected lease: %+v", client.ttls)
+	}
+
+	acquired, err = lock.Acquire(ctx, 12, "wf-2")
+	if err != nil || acquired {
+		t.Fatalf("expected second acquire to be rejected: acquired=%v err=%v", acquired, err)
+	}
+	acquired, err = lock.Acquire(ctx, 13, "wf-3")
+	if err != nil || !acquired {
+		t.Fatalf("expected another connection to be independent: acquired=%v err=%v", acquired, err)
+	}
+
+	if err := lock.Release(ctx, 12, "wf-1"); err != nil {
+		t.Fatalf("Release returned error: %v", err)
+	}
+	acquired, err = lock.Acquire(ctx, 12, "wf-2")
+	if err != nil || !acquired {
+		t.Fatalf("expected acquire after release to succeed: acquired=%v err=%v", acquired, err)
+	}
+}
+
+func TestRedisWorkflowConnectionLock_RequiresClient(t *testing.T) {
+	t.Parallel()
+
+	lock := NewRedisWorkflowConnectionLock(nil, time.Second)
+	_, err := lock.Acquire(context.Background(), 12, "wf-1")
+
+	var redisErr *redisclient.ErrRedisUnavailable
+	if !errors.As(err, &redisErr) {
+		t.Fatalf("expected ErrRedisUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"strings"
+)
+
+// newBackgroundWorkflowContext creates a detached workflow context carrying request_id, job_id and user_id.
+func newBackgroundWorkflowContext(requestID string, job manualapp.DispatchJob) context.Context {
+	bgCtx := context.Background()
+
+	if requestID = strings.TrimSpace(requestID); requestID != "" {
+		if next, err := logger.ContextWithRequestID(bgCtx, requestID); err == nil {
+			bgCtx = next
+		}
+	}
+	if next, err := logger.ContextWithJobID(bgCtx, job.WorkflowID); err == nil {
+		bgCtx = next
+	}
+	if next, err := logger.ContextWithUserID(bgCtx, job.UserID); err == nil {
+		bgCtx = next
+	}
+
+	return bgCtx
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	if requestID, ok := logger.RequestIDFromContext(ctx); ok {
+		return requestID
+	}
+	return ""
+}
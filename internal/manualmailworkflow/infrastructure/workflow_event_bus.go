@@ -0,0 +1,231 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	redisclient "business/internal/library/redis"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const workflowEventChannelPrefix = "manual_mail_workflow:events:"
+
+// workflowEventMessage is the JSON payload published on the Redis channel.
+type workflowEventMessage struct {
+	Type           string                                `json:"type"`
+	HistoryID      uint64                                `json:"history_id"`
+	Status         string                                `json:"status"`
+	CurrentStage   string                                `json:"current_stage,omitempty"`
+	Stage          string                                `json:"stage,omitempty"`
+	Counts         workflowEventCountsMessage            `json:"counts"`
+	FailureReasons []workflowEventFailureReasonMessage   `json:"failure_reasons,omitempty"`
+	Stages         map[string]workflowEventCountsMessage `json:"stages,omitempty"`
+	ErrorMessage   string                                `json:"error_message,omitempty"`
+	OccurredAt     time.Time                             `json:"occurred_at"`
+}
+
+type workflowEventCountsMessage struct {
+	SuccessCount          int `json:"success_count"`
+	BusinessFailureCount  int `json:"business_failure_count"`
+	TechnicalFailureCount int `json:"technical_failure_count"`
+}
+
+type workflowEventFailureReasonMessage struct {
+	ReasonCode string `json:"reason_code"`
+	Message    string `json:"message"`
+	Count      int    `json:"count"`
+}
+
+// RedisWorkflowEventBus fans workflow events out through Redis pub/sub so that
+// the API process can stream transitions recorded by a worker in another process.
+type RedisWorkflowEventBus struct {
+	client redisclient.ClientInterface
+	log    logger.Interface
+}
+
+// NewRedisWorkflowEventBus creates a workflow event bus backed by Redis pub/sub.
+func NewRedisWorkflowEventBus(client redisclient.ClientInterface, log logger.Interface) *RedisWorkflowEventBus {
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &RedisWorkflowEventBus{
+		client: client,
+		log:    log.With(logger.Component("manual_mail_workflow_event_bus")),
+	}
+}
+
+// Publish sends the event to the channel of its workflow history.
+func (b *RedisWorkflowEventBus) Publish(ctx context.Context, event manualapp.WorkflowEvent) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if b.client == nil {
+		return &redisclient.ErrRedisUnavailable{Err: errors.New("redis client is not configured")}
+	}
+
+	payload, err := json.Marshal(toWorkflowEventMessage(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode workflow event: %w", err)
+	}
+
+	return b.client.Publish(ctx, workflowEventChannel(event.HistoryID), string(payload))
+}
+
+// Subscribe opens a live stream of the events published for the workflow history.
+func (b *RedisWorkflowEventBus) Subscribe(ctx context.Context, historyID uint64) (manualapp.WorkflowEventSubscription, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if b.client == nil {
+		return nil, &redisclient.ErrRedisUnavailable{Err: errors.New("redis client is not configured")}
+	}
+
+	source, err := b.client.Subscribe(ctx, workflowEventChannel(historyID))
+	if err != nil {
+		return nil, err
+	}
+
+	reqLog := b.log
+	if withContext, withCtxErr := b.log.WithContext(ctx); withCtxErr == nil {
+		reqLog = withContext
+	}
+
+	subscription := &redisWorkflowEventSubscription{
+		source: source,
+		events: make(chan manualapp.WorkflowEvent),
+		done:   make(chan struct{}),
+		log:    reqLog,
+	}
+	go subscription.decode()
+	return subscription, nil
+}
+
+type redisWorkflowEventSubscription struct {
+	source    redisclient.Subscription
+	events    chan manualapp.WorkflowEvent
+	done      chan struct{}
+	closeOnce sync.Once
+	log       logger.Interface
+}
+
+func (s *redisWorkflowEventSubscription) Events() <-chan manualapp.WorkflowEvent {
+	return s.events
+}
+
+func (s *redisWorkflowEventSubscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.source.Close()
+	})
+	return err
+}
+
+// decode forwards decoded events until the source ends or the subscription is closed.
+// Malformed payloads are skipped so that one bad publisher cannot stop the stream.
+func (s *redisWorkflowEventSubscription) decode() {
+	defer close(s.events)
+
+	messages := s.source.Messages()
+	for {
+		select {
+		case <-s.done:
+			return
+		case payload, ok := <-messages:
+			if !ok {
+				return
+			}
+			var message workflowEventMessage
+			if err := json.Unmarshal([]byte(payload), &message); err != nil {
+				s.log.Warn("manual_mail_workflow_event_decode_failed", logger.Err(err))
+				continue
+			}
+			select {
+			case s.events <- fromWorkflowEventMessage(message):
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+func workflowEventChannel(historyID uint64) string {
+	return fmt.Sprintf("%s%d", workflowEventChannelPrefix, historyID)
+}
+
+func toWorkflowEventMessage(event manualapp.WorkflowEvent) workflowEventMessage {
+	message := workflowEventMessage{
+		Type:         event.Type,
+		HistoryID:    event.HistoryID,
+		Status:       event.Status,
+		CurrentStage: event.CurrentStage,
+		Stage:        event.Stage,
+		Counts:       toWorkflowEventCountsMessage(event.Counts),
+		ErrorMessage: event.ErrorMessage,
+		OccurredAt:   event.OccurredAt.UTC(),
+	}
+	for _, reason := range event.FailureReasons {
+		message.FailureReasons = append(message.FailureReasons, workflowEventFailureReasonMessage{
+			ReasonCode: reason.ReasonCode,
+			Message:    reason.Message,
+			Count:      reason.Count,
+		})
+	}
+	if len(event.Stages) > 0 {
+		message.Stages = make(map[string]workflowEventCountsMessage, len(event.Stages))
+		for stage, counts := range event.Stages {
+			message.Stages[stage] = toWorkflowEventCountsMessage(counts)
+		}
+	}
+
+	return message
+}
+
+func fromWorkflowEventMessage(message workflowEventMessage) manualapp.WorkflowEvent {
+	event := manualapp.WorkflowEvent{
+		Type:         message.Type,
+		HistoryID:    message.HistoryID,
+		Status:       message.Status,
+		CurrentStage: message.CurrentStage,
+		Stage:        message.Stage,
+		Counts:       fromWorkflowEventCountsMessage(message.Counts),
+		ErrorMessage: message.ErrorMessage,
+		OccurredAt:   message.OccurredAt.UTC(),
+	}
+	for _, reason := range message.FailureReasons {
+		event.FailureReasons = append(event.FailureReasons, manualapp.WorkflowEventFailureReason{
+			ReasonCode: reason.ReasonCode,
+			Message:    reason.Message,
+			Count:      reason.Count,
+		})
+	}
+	if len(message.Stages) > 0 {
+		event.Stages = make(map[string]manualapp.StageCountView, len(message.Stages))
+		for stage, counts := range message.Stages {
+			event.Stages[stage] = fromWorkflowEventCountsMessage(counts)
+		}
+	}
+
+	return event
+}
+
+func toWorkflowEventCountsMessage(counts manualapp.StageCountView) workflowEventCountsMessage {
+	return workflowEventCountsMessage{
+		SuccessCount:          counts.SuccessCount,
+		BusinessFailureCount:  counts.BusinessFailureCount,
+		TechnicalFailureCount: counts.TechnicalFailureCount,
+	}
+}
+
+func fromWorkflowEventCountsMessage(counts workflowEventCountsMessage) manualapp.StageCountView {
+	return manualapp.StageCountView{
+		SuccessCount:          counts.SuccessCount,
+		BusinessFailureCount:  counts.BusinessFailureCount,
+		TechnicalFailureCount: counts.TechnicalFailureCount,
+	}
+}
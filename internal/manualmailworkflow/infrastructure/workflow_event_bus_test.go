@@ -0,0 +1,130 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	redisclient "business/internal/library/redis"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubPubSubRedisClient delivers published payloads to in-memory subscribers of the same channel.
+type stubPubSubRedisClient struct {
+	redisclient.ClientInterface
+	mu          sync.Mutex
+	subscribers map[string][]chan string
+}
+
+func (s *stubPubSubRedisClient) Publish(ctx context.Context, channel, payload string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, subscriber := range s.subscribers[channel] {
+		subscriber <- payload
+	}
+	return nil
+}
+
+func (s *stubPubSubRedisClient) Subscribe(ctx context.Context, channel string) (redisclient.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := make(chan string, 8)
+	if s.subscribers == nil {
+		s.subscribers = map[string][]chan string{}
+	}
+	s.subscribers[channel] = append(s.subscribers[channel], messages)
+	return &stubRedisSubscription{messages: messages}, nil
+}
+
+type stubRedisSubscription struct {
+	messages  chan string
+	closeOnce sync.Once
+}
+
+func (s *stubRedisSubscription) Messages() <-chan string {
+	return s.messages
+}
+
+func (s *stubRedisSubscription) Close() error {
+	s.closeOnce.Do(func() { close(s.messages) })
+	return nil
+}
+
+func TestRedisWorkflowEventBus_PublishSubscribeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	client := &stubPubSubRedisClient{}
+	bus := NewRedisWorkflowEventBus(client, logger.NewNop())
+	ctx := context.Background()
+
+	subscription, err := bus.Subscribe(ctx, 42)
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+	defer subscription.Close()
+
+	// 別の workflow のイベントと壊れた payload は届かない。
+	if err := bus.Publish(ctx, manualapp.WorkflowEvent{Type: manualapp.WorkflowEventStageStarted, HistoryID: 43}); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+	if err := client.Publish(ctx, "manual_mail_workflow:events:42", "{broken"); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	occurredAt := time.Date(2026, 3, 25, 15, 0, 0, 0, time.UTC)
+	published := manualapp.WorkflowEvent{
+		Type:         manualapp.WorkflowEventStageProgress,
+		HistoryID:    42,
+		Status:       manualapp.WorkflowStatusRunning,
+		CurrentStage: "vendorresolution",
+		Stage:        "vendorresolution",
+		Counts:       manualapp.StageCountView{SuccessCount: 3, BusinessFailureCount: 2},
+		FailureReasons: []manualapp.WorkflowEventFailureReason{
+			{ReasonCode: "vendor_unresolved", Message: "支払先を特定できませんでした。", Count: 2},
+		},
+		OccurredAt: occurredAt,
+	}
+	if err := bus.Publish(ctx, published); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	select {
+	case got := <-subscription.Events():
+		if got.Type != published.Type || got.HistoryID != 42 || got.Stage != "vendorresolution" {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+		if got.Counts != published.Counts || !got.OccurredAt.Equal(occurredAt) {
+			t.Fatalf("unexpected counts or time: %+v", got)
+		}
+		if len(got.FailureReasons) != 1 || got.FailureReasons[0] != published.FailureReasons[0] {
+			t.Fatalf("unexpected failure reasons: %+v", got.FailureReasons)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("published event was not delivered")
+	}
+
+	if err := subscription.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	select {
+	case _, ok := <-subscription.Events():
+		if ok {
+			t.Fatal("events must be closed after Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("events channel was not closed")
+	}
+}
+
+func TestRedisWorkflowEventBus_RequiresClient(t *testing.T) {
+	t.Parallel()
+
+	bus := NewRedisWorkflowEventBus(nil, nil)
+	if err := bus.Publish(context.Background(), manualapp.WorkflowEvent{HistoryID: 1}); err == nil {
+		t.Fatal("expected an error without redis client")
+	}
+	if _, err := bus.Subscribe(context.Background(), 1); err == nil {
+		t.Fatal("expected an error without redis client")
+	}
+}
@@ -0,0 +1,215 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"strings"
+	"time"
+)
+
+// PublishingWorkflowStatusRepository publishes a workflow event after each persisted status transition.
+// The history row stays the source of truth: a publish failure is only logged and never fails the transition.
+type PublishingWorkflowStatusRepository struct {
+	*GormWorkflowStatusRepository
+	publisher manualapp.WorkflowEventPublisher
+	clock     timewrapper.ClockInterface
+	log       logger.Interface
+}
+
+// NewPublishingWorkflowStatusRepository wraps the Gorm repository with event publishing.
+func NewPublishingWorkflowStatusRepository(
+	repository *GormWorkflowStatusRepository,
+	publisher manualapp.WorkflowEventPublisher,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) *PublishingWorkflowStatusRepository {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &PublishingWorkflowStatusRepository{
+		GormWorkflowStatusRepository: repository,
+		publisher:                    publisher,
+		clock:                        clock,
+		log:                          log.With(logger.Component("manual_mail_workflow_event_publisher")),
+	}
+}
+
+// MarkRunning records the stage transition and publishes stage_started.
+func (r *PublishingWorkflowStatusRepository) MarkRunning(ctx context.Context, historyID uint64, currentStage string) error {
+	if err := r.GormWorkflowStatusRepository.MarkRunning(ctx, historyID, currentStage); err != nil {
+		return err
+	}
+
+	currentStage = strings.TrimSpace(currentStage)
+	r.publish(ctx, manualapp.WorkflowEvent{
+		Type:         manualapp.WorkflowEventStageStarted,
+		HistoryID:    historyID,
+		Status:       manualapp.WorkflowStatusRunning,
+		CurrentStage: currentStage,
+		Stage:        currentStage,
+		OccurredAt:   r.clock.Now().UTC(),
+	})
+	return nil
+}
+
+// SaveStageProgress persists the stage summary and publishes stage_progress with its failure reasons.
+func (r *PublishingWorkflowStatusRepository) SaveStageProgress(ctx context.Context, progress manualapp.StageProgress) error {
+	if err := r.GormWorkflowStatusRepository.SaveStageProgress(ctx, progress); err != nil {
+		return err
+	}
+
+	r.publish(ctx, manualapp.StageProgressEvent(progress, r.clock.Now().UTC()))
+	return nil
+}
+
+// Complete finalizes the workflow and publishes completed.
+func (r *PublishingWorkflowStatusRepository) Complete(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error {
+	if err := r.GormWorkflowStatusRepository.Complete(ctx, historyID, status, finishedAt); err != nil {
+		return err
+	}
+
+	r.publish(ctx, manualapp.WorkflowEvent{
+		Type:       manualapp.WorkflowEventCompleted,
+		HistoryID:  historyID,
+		Status:     status,
+		OccurredAt: finishedAt.UTC(),
+	})
+	r.publishSettledFanOutParent(ctx, historyID)
+	return nil
+}
+
+// Fail finalizes the workflow as failed and publishes failed with the user-facing error message.
+func (r *PublishingWorkflowStatusRepository) Fail(
+	ctx context.Context,
+	historyID uint64,
+	currentStage string,
+	finishedAt time.Time,
+	errorMessage string,
+) error {
+	if err := r.GormWorkflowStatusRepository.Fail(ctx, historyID, currentStage, finishedAt, errorMessage); err != nil {
+		return err
+	}
+
+	r.publish(ctx, manualapp.WorkflowEvent{
+		Type:         manualapp.WorkflowEventFailed,
+		HistoryID:    historyID,
+		Status:       manualapp.WorkflowStatusFailed,
+		CurrentStage: strings.TrimSpace(currentStage),
+		ErrorMessage: strings.TrimSpace(errorMessage),
+		OccurredAt:   finishedAt.UTC(),
+	})
+	r.publishSettledFanOutParent(ctx, historyID)
+	return nil
+}
+
+// MarkCancelled finalizes the workflow as cancelled and publishes cancelled.
+func (r *PublishingWorkflowStatusRepository) MarkCancelled(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time) error {
+	if err := r.GormWorkflowStatusRepository.MarkCancelled(ctx, historyID, currentStage, finishedAt); err != nil {
+		return err
+	}
+
+	r.publish(ctx, manualapp.WorkflowEvent{
+		Type:         manualapp.WorkflowEventCancelled,
+		HistoryID:    historyID,
+		Status:       manualapp.WorkflowStatusCancelled,
+		CurrentStage: strings.TrimSpace(currentStage),
+		OccurredAt:   finishedAt.UTC(),
+	})
+	r.publishSettledFanOutParent(ctx, historyID)
+	return nil
+}
+
+// RequestCancel records the cancel request. A queued workflow is cancelled right here without a runner,
+// so cancelled is published for it; a running workflow publishes it later from MarkCancelled.
+func (r *PublishingWorkflowStatusRepository) RequestCancel(
+	ctx context.Context,
+	userID uint,
+	workflowID string,
+	requestedAt time.Time,
+) (manualapp.CancelResult, error) {
+	result, err := r.GormWorkflowStatusRepository.RequestCancel(ctx, userID, workflowID, requestedAt)
+	if err != nil || result.Status != manualapp.WorkflowStatusCancelled {
+		return result, err
+	}
+
+	progress, err := r.GormWorkflowStatusRepository.FindProgress(ctx, userID, result.WorkflowID)
+	if err != nil {
+		r.requestLogger(ctx).Warn("manual_mail_workflow_event_publish_failed",
+			logger.String("event_type", manualapp.WorkflowEventCancelled),
+			logger.String("workflow_id", result.WorkflowID),
+			logger.Err(err),
+		)
+		return result, nil
+	}
+
+	r.publish(ctx, manualapp.WorkflowEvent{
+		Type:       manualapp.WorkflowEventCancelled,
+		HistoryID:  progress.HistoryID,
+		Status:     manualapp.WorkflowStatusCancelled,
+		OccurredAt: requestedAt.UTC(),
+	})
+	r.publishSettledFanOutParent(ctx, progress.HistoryID)
+	return result, nil
+}
+
+// publishSettledFanOutParent publishes the terminal event of the sync-all parent once its last child finished.
+// The parent has no runner of its own, so this is the only place its subscribers learn that it is over.
+func (r *PublishingWorkflowStatusRepository) publishSettledFanOutParent(ctx context.Context, historyID uint64) {
+	parent, found, err := r.GormWorkflowStatusRepository.findSettledFanOutParent(ctx, historyID)
+	if err != nil {
+		r.requestLogger(ctx).Warn("manual_mail_workflow_event_publish_failed",
+			logger.String("event_type", "fan_out_parent"),
+			logger.Uint("history_id", uint(historyID)),
+			logger.Err(err),
+		)
+		return
+	}
+	if !found {
+		return
+	}
+
+	event := manualapp.WorkflowEvent{
+		Type:       manualapp.WorkflowEventCompleted,
+		HistoryID:  parent.HistoryID,
+		Status:     parent.Status,
+		OccurredAt: r.clock.Now().UTC(),
+	}
+	switch parent.Status {
+	case manualapp.WorkflowStatusFailed:
+		event.Type = manualapp.WorkflowEventFailed
+		if parent.ErrorMessage != nil {
+			event.ErrorMessage = *parent.ErrorMessage
+		}
+	case manualapp.WorkflowStatusCancelled:
+		event.Type = manualapp.WorkflowEventCancelled
+	}
+	r.publish(ctx, event)
+}
+
+func (r *PublishingWorkflowStatusRepository) publish(ctx context.Context, event manualapp.WorkflowEvent) {
+	if r.publisher == nil {
+		return
+	}
+
+	// 状態遷移は保存済みなので、呼び出し元の cancel とは関係なく通知する。
+	if err := r.publisher.Publish(context.WithoutCancel(ctx), event); err != nil {
+		r.requestLogger(ctx).Warn("manual_mail_workflow_event_publish_failed",
+			logger.String("event_type", event.Type),
+			logger.Uint("history_id", uint(event.HistoryID)),
+			logger.Err(err),
+		)
+	}
+}
+
+func (r *PublishingWorkflowStatusRepository) requestLogger(ctx context.Context) logger.Interface {
+	if withContext, err := r.log.WithContext(ctx); err == nil {
+		return withContext
+	}
+	return r.log
+}
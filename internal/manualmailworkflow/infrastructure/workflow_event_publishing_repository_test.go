@@ -0,0 +1,95 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingWorkflowEventPublisher struct {
+	events []manualapp.WorkflowEvent
+	err    error
+}
+
+func (p *recordingWorkflowEventPublisher) Publish(ctx context.Context, event manualapp.WorkflowEvent) error {
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func TestPublishingWorkflowStatusRepository_PublishesEachTransition(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	history := workflowHistoryRecordFixture(1, "wf-events", env.nowUTC, manualapp.WorkflowStatusQueued)
+	require.NoError(t, env.db.Create(&history).Error)
+
+	publisher := &recordingWorkflowEventPublisher{err: errors.New("redis down")}
+	repo := NewPublishingWorkflowStatusRepository(env.repo, publisher, &workflowStatusRepoFixedClock{now: env.nowUTC}, logger.NewNop())
+
+	// publish に失敗しても状態遷移そのものは成功する。
+	require.NoError(t, repo.MarkRunning(ctx, history.ID, "vendorresolution"))
+	require.NoError(t, repo.SaveStageProgress(ctx, manualapp.StageProgress{
+		HistoryID:            history.ID,
+		Stage:                "vendorresolution",
+		SuccessCount:         1,
+		BusinessFailureCount: 2,
+		FailureRecords: []manualapp.StageFailureRecord{
+			{Stage: "vendorresolution", ReasonCode: "vendor_unresolved", Message: "支払先を特定できませんでした。"},
+			{Stage: "vendorresolution", ReasonCode: "vendor_unresolved", Message: "支払先を特定できませんでした。"},
+		},
+	}))
+	finishedAt := env.nowUTC.Add(time.Minute)
+	require.NoError(t, repo.Complete(ctx, history.ID, manualapp.WorkflowStatusPartialSuccess, finishedAt))
+
+	require.Len(t, publisher.events, 3)
+	require.Equal(t, manualapp.WorkflowEventStageStarted, publisher.events[0].Type)
+	require.Equal(t, "vendorresolution", publisher.events[0].CurrentStage)
+	require.Equal(t, manualapp.WorkflowEventStageProgress, publisher.events[1].Type)
+	require.Equal(t, manualapp.StageCountView{SuccessCount: 1, BusinessFailureCount: 2}, publisher.events[1].Counts)
+	require.Equal(t, []manualapp.WorkflowEventFailureReason{
+		{ReasonCode: "vendor_unresolved", Message: "支払先を特定できませんでした。", Count: 2},
+	}, publisher.events[1].FailureReasons)
+	require.Equal(t, manualapp.WorkflowEventCompleted, publisher.events[2].Type)
+	require.Equal(t, manualapp.WorkflowStatusPartialSuccess, publisher.events[2].Status)
+	require.True(t, publisher.events[2].OccurredAt.Equal(finishedAt))
+
+	// 失敗した遷移は通知しない。
+	require.Error(t, repo.MarkRunning(ctx, history.ID+1000, "billing"))
+	require.Len(t, publisher.events, 3)
+}
+
+func TestPublishingWorkflowStatusRepository_RequestCancelPublishesForQueuedWorkflow(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	queued := workflowHistoryRecordFixture(1, "wf-queued-events", env.nowUTC, manualapp.WorkflowStatusQueued)
+	require.NoError(t, env.db.Create(&queued).Error)
+	running := workflowHistoryRecordFixture(1, "wf-running-events", env.nowUTC, manualapp.WorkflowStatusRunning)
+	require.NoError(t, env.db.Create(&running).Error)
+
+	publisher := &recordingWorkflowEventPublisher{}
+	repo := NewPublishingWorkflowStatusRepository(env.repo, publisher, nil, logger.NewNop())
+
+	result, err := repo.RequestCancel(ctx, 1, "wf-running-events", env.nowUTC)
+	require.NoError(t, err)
+	require.Equal(t, manualapp.WorkflowStatusRunning, result.Status)
+	require.Empty(t, publisher.events, "running workflows publish cancelled from MarkCancelled")
+
+	result, err = repo.RequestCancel(ctx, 1, "wf-queued-events", env.nowUTC)
+	require.NoError(t, err)
+	require.Equal(t, manualapp.WorkflowStatusCancelled, result.Status)
+	require.Len(t, publisher.events, 1)
+	require.Equal(t, manualapp.WorkflowEventCancelled, publisher.events[0].Type)
+	require.Equal(t, queued.ID, publisher.events[0].HistoryID)
+}
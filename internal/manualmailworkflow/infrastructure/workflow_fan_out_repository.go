@@ -0,0 +1,236 @@
+package infrastructure
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refreshFanOutParentOf recomputes the sync-all parent of the history, if any, after the history changed.
+// The child transition is already saved, so a failure here is only logged; the next transition of any child recomputes it again.
+func (r *GormWorkflowStatusRepository) refreshFanOutParentOf(ctx context.Context, historyID uint64) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return refreshFanOutParents(tx, []uint64{historyID}, r.clock.Now().UTC())
+	})
+	if err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "refresh_fan_out_parent", err)
+	}
+}
+
+// findSettledFanOutParent returns the progress of the sync-all parent of the history once all of its children finished.
+func (r *GormWorkflowStatusRepository) findSettledFanOutParent(ctx context.Context, historyID uint64) (manualapp.WorkflowProgress, bool, error) {
+	var parents []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Table("manual_mail_workflow_histories AS p").
+		Select("p.*").
+		Joins("JOIN manual_mail_workflow_histories AS c ON c.parent_workflow_id = p.workflow_id AND c.user_id = p.user_id").
+		Where("c.id = ? AND p.fan_out = ? AND p.status NOT IN ?", historyID, true, []string{manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning}).
+		Limit(1).
+		Find(&parents).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_settled_fan_out_parent", err)
+		return manualapp.WorkflowProgress{}, false, fmt.Errorf("failed to find fan-out parent: %w", err)
+	}
+	if len(parents) == 0 {
+		return manualapp.WorkflowProgress{}, false, nil
+	}
+
+	parent := parents[0]
+	return manualapp.WorkflowProgress{
+		HistoryID:    parent.ID,
+		WorkflowID:   parent.WorkflowID,
+		Status:       parent.Status,
+		ErrorMessage: cloneOptionalString(parent.ErrorMessage),
+		UpdatedAt:    parent.UpdatedAt.UTC(),
+		Stages:       workflowStageCounts(parent),
+	}, true, nil
+}
+
+// findFanOutChildren lists the per-connection children of a sync-all parent in acceptance order.
+func (r *GormWorkflowStatusRepository) findFanOutChildren(ctx context.Context, parent manualMailWorkflowHistoryRecord) ([]manualapp.WorkflowChildSummary, error) {
+	var records []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND parent_workflow_id = ?", parent.UserID, parent.WorkflowID).
+		Order("id ASC").
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_fan_out_children", err)
+		return nil, fmt.Errorf("failed to list fan-out children: %w", err)
+	}
+
+	children := make([]manualapp.WorkflowChildSummary, 0, len(records))
+	for _, record := range records {
+		children = append(children, manualapp.WorkflowChildSummary{
+			WorkflowID:        record.WorkflowID,
+			Provider:          record.Provider,
+			AccountIdentifier: record.AccountIdentifier,
+			Status:            record.Status,
+			CurrentStage:      cloneOptionalString(record.CurrentStage),
+			FinishedAt:        cloneOptionalTime(record.FinishedAt),
+			ErrorMessage:      cloneOptionalString(record.ErrorMessage),
+		})
+	}
+
+	return children, nil
+}
+
+// refreshFanOutParents recomputes the sync-all parents of the given histories from all of their children.
+func refreshFanOutParents(tx *gorm.DB, historyIDs []uint64, now time.Time) error {
+	var parentWorkflowIDs []string
+	if err := tx.Model(&manualMailWorkflowHistoryRecord{}).
+		Where("id IN ? AND parent_workflow_id IS NOT NULL", historyIDs).
+		Distinct().
+		Pluck("parent_workflow_id", &parentWorkflowIDs).Error; err != nil {
+		return err
+	}
+
+	for _, parentWorkflowID := range parentWorkflowIDs {
+		if err := refreshFanOutParent(tx, parentWorkflowID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshFanOutParent locks the parent before reading the children so that concurrent child transitions
+// are applied one after another and the last writer always sees every child's latest state.
+func refreshFanOutParent(tx *gorm.DB, parentWorkflowID string, now time.Time) error {
+	var parents []manualMailWorkflowHistoryRecord
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("workflow_id = ? AND fan_out = ?", parentWorkflowID, true).
+		Limit(1).
+		Find(&parents).Error; err != nil {
+		return err
+	}
+	if len(parents) == 0 {
+		return nil
+	}
+	parent := parents[0]
+
+	var children []manualMailWorkflowHistoryRecord
+	if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("user_id = ? AND parent_workflow_id = ?", parent.UserID, parent.WorkflowID).
+		Order("id ASC").
+		Find(&children).Error; err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	if err := tx.Model(&manualMailWorkflowHistoryRecord{}).
+		Where("id = ?", parent.ID).
+		Updates(fanOutParentUpdates(children, now)).Error; err != nil {
+		return err
+	}
+	return refreshFanOutParentStageCounts(tx, parent.ID, children, now)
+}
+
+// fanOutParentUpdates sums the children's stage counts and derives the parent status from theirs.
+// finished_at is the last child's once every child finished.
+func fanOutParentUpdates(children []manualMailWorkflowHistoryRecord, now time.Time) map[string]interface{} {
+	statuses := make([]string, 0, len(children))
+	var total manualMailWorkflowHistoryRecord
+	var finishedAt *time.Time
+	for _, child := range children {
+		statuses = append(statuses, child.Status)
+		total.FetchSuccessCount += child.FetchSuccessCount
+		total.FetchBusinessFailureCount += child.FetchBusinessFailureCount
+		total.FetchTechnicalFailureCount += child.FetchTechnicalFailureCount
+		total.AnalysisSuccessCount += child.AnalysisSuccessCount
+		total.AnalysisBusinessFailureCount += child.AnalysisBusinessFailureCount
+		total.AnalysisTechnicalFailureCount += child.AnalysisTechnicalFailureCount
+		total.VendorResolutionSuccessCount += child.VendorResolutionSuccessCount
+		total.VendorResolutionBusinessFailureCount += child.VendorResolutionBusinessFailureCount
+		total.VendorResolutionTechnicalFailureCount += child.VendorResolutionTechnicalFailureCount
+		total.BillingEligibilitySuccessCount += child.BillingEligibilitySuccessCount
+		total.BillingEligibilityBusinessFailureCount += child.BillingEligibilityBusinessFailureCount
+		total.BillingEligibilityTechnicalFailureCount += child.BillingEligibilityTechnicalFailureCount
+		total.BillingSuccessCount += child.BillingSuccessCount
+		total.BillingBusinessFailureCount += child.BillingBusinessFailureCount
+		total.BillingTechnicalFailureCount += child.BillingTechnicalFailureCount
+		if child.FinishedAt != nil && (finishedAt == nil || child.FinishedAt.After(*finishedAt)) {
+			finishedAt = cloneOptionalTime(child.FinishedAt)
+		}
+	}
+
+	status := manualapp.DeriveFanOutStatus(statuses)
+	if status == manualapp.WorkflowStatusQueued || status == manualapp.WorkflowStatusRunning {
+		finishedAt = nil
+	} else if finishedAt == nil {
+		finishedAt = &now
+	}
+
+	return map[string]interface{}{
+		"status":                                      status,
+		"finished_at":                                 finishedAt,
+		"error_message":                               optionalString(manualapp.FanOutErrorMessage(statuses)),
+		"fetch_success_count":                         total.FetchSuccessCount,
+		"fetch_business_failure_count":                total.FetchBusinessFailureCount,
+		"fetch_technical_failure_count":               total.FetchTechnicalFailureCount,
+		"analysis_success_count":                      total.AnalysisSuccessCount,
+		"analysis_business_failure_count":             total.AnalysisBusinessFailureCount,
+		"analysis_technical_failure_count":            total.AnalysisTechnicalFailureCount,
+		"vendor_resolution_success_count":             total.VendorResolutionSuccessCount,
+		"vendor_resolution_business_failure_count":    total.VendorResolutionBusinessFailureCount,
+		"vendor_resolution_technical_failure_count":   total.VendorResolutionTechnicalFailureCount,
+		"billing_eligibility_success_count":           total.BillingEligibilitySuccessCount,
+		"billing_eligibility_business_failure_count":  total.BillingEligibilityBusinessFailureCount,
+		"billing_eligibility_technical_failure_count": total.BillingEligibilityTechnicalFailureCount,
+		"billing_success_count":                       total.BillingSuccessCount,
+		"billing_business_failure_count":              total.BillingBusinessFailureCount,
+		"billing_technical_failure_count":             total.BillingTechnicalFailureCount,
+		"updated_at":                                  now,
+	}
+}
+
+// requestFanOutCancel cancels the queued children of a sync-all parent right away and flags the running ones,
+// then returns the parent status derived from them.
+func requestFanOutCancel(tx *gorm.DB, parent manualMailWorkflowHistoryRecord, requestedAt time.Time, now time.Time) (string, error) {
+	switch parent.Status {
+	case manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning:
+	case manualapp.WorkflowStatusCancelled:
+		return manualapp.WorkflowStatusCancelled, nil
+	default:
+		return "", manualapp.ErrWorkflowNotCancellable
+	}
+
+	children := tx.Model(&manualMailWorkflowHistoryRecord{}).
+		Where("user_id = ? AND parent_workflow_id = ?", parent.UserID, parent.WorkflowID)
+	if err := children.Session(&gorm.Session{}).
+		Where("status = ?", manualapp.WorkflowStatusQueued).
+		Updates(map[string]interface{}{
+			"status":              manualapp.WorkflowStatusCancelled,
+			"finished_at":         &requestedAt,
+			"cancel_requested_at": &requestedAt,
+			"updated_at":          now,
+		}).Error; err != nil {
+		return "", err
+	}
+	if err := children.Session(&gorm.Session{}).
+		Where("status = ? AND cancel_requested_at IS NULL", manualapp.WorkflowStatusRunning).
+		Updates(map[string]interface{}{
+			"cancel_requested_at": &requestedAt,
+			"updated_at":          now,
+		}).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Model(&manualMailWorkflowHistoryRecord{}).
+		Where("id = ? AND cancel_requested_at IS NULL", parent.ID).
+		Update("cancel_requested_at", &requestedAt).Error; err != nil {
+		return "", err
+	}
+	if err := refreshFanOutParent(tx, parent.WorkflowID, now); err != nil {
+		return "", err
+	}
+
+	var status string
+	if err := tx.Model(&manualMailWorkflowHistoryRecord{}).
+		Where("id = ?", parent.ID).
+		Pluck("status", &status).Error; err != nil {
+		return "", err
+	}
+	return status, nil
+}
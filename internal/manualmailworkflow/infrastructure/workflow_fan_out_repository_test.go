@@ -0,0 +1,138 @@
+package infrastructure
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormWorkflowStatusRepository_FanOutParentFollowsChildren(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{ID: 30, UserID: 10, Type: "gmail", GmailAddress: "a@example.com"})
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{ID: 31, UserID: 10, Type: "gmail", GmailAddress: "b@example.com"})
+
+	queued := manualapp.QueuedWorkflowHistory{
+		WorkflowID: "wf-parent",
+		UserID:     10,
+		LabelName:  "billing",
+		SinceAt:    time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+		UntilAt:    time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		QueuedAt:   env.nowUTC,
+		FanOut:     true,
+	}
+	parentRef, err := env.repo.CreateQueued(ctx, queued)
+	require.NoError(t, err)
+
+	queued.FanOut = false
+	queued.ParentWorkflowID = "wf-parent"
+	queued.WorkflowID, queued.ConnectionID = "wf-child-a", 30
+	childA, err := env.repo.CreateQueued(ctx, queued)
+	require.NoError(t, err)
+	queued.WorkflowID, queued.ConnectionID = "wf-child-b", 31
+	childB, err := env.repo.CreateQueued(ctx, queued)
+	require.NoError(t, err)
+
+	require.NoError(t, env.repo.MarkRunning(ctx, childA.HistoryID, "fetch"))
+	var parent manualMailWorkflowHistoryRecord
+	require.NoError(t, env.db.First(&parent, parentRef.HistoryID).Error)
+	require.Equal(t, manualapp.WorkflowStatusRunning, parent.Status)
+	require.Empty(t, parent.Provider)
+
+	require.NoError(t, env.repo.SaveStageProgress(ctx, manualapp.StageProgress{HistoryID: childA.HistoryID, Stage: "fetch", SuccessCount: 3}))
+	require.NoError(t, env.repo.SaveStageProgress(ctx, manualapp.StageProgress{HistoryID: childB.HistoryID, Stage: "fetch", SuccessCount: 2}))
+	require.NoError(t, env.repo.Complete(ctx, childA.HistoryID, manualapp.WorkflowStatusSucceeded, env.nowUTC.Add(time.Minute)))
+	require.NoError(t, env.db.First(&parent, parentRef.HistoryID).Error)
+	require.Equal(t, manualapp.WorkflowStatusRunning, parent.Status)
+	require.Nil(t, parent.FinishedAt)
+	require.Equal(t, 5, parent.FetchSuccessCount)
+
+	require.NoError(t, env.repo.Fail(ctx, childB.HistoryID, "analysis", env.nowUTC.Add(2*time.Minute), "メール解析に失敗しました。"))
+	require.NoError(t, env.db.First(&parent, parentRef.HistoryID).Error)
+	require.Equal(t, manualapp.WorkflowStatusPartialSuccess, parent.Status)
+	require.NotNil(t, parent.FinishedAt)
+	require.True(t, parent.FinishedAt.Equal(env.nowUTC.Add(2*time.Minute)))
+	require.NotNil(t, parent.ErrorMessage)
+	require.Contains(t, *parent.ErrorMessage, "2 件のメール連携のうち 1 件")
+
+	detail, err := env.repo.Detail(ctx, manualapp.DetailQuery{UserID: 10, WorkflowID: "wf-parent", Limit: 10})
+	require.NoError(t, err)
+	require.True(t, detail.FanOut)
+	require.Len(t, detail.Children, 2)
+	require.Equal(t, "wf-child-a", detail.Children[0].WorkflowID)
+	require.Equal(t, "a@example.com", detail.Children[0].AccountIdentifier)
+	require.Equal(t, manualapp.WorkflowStatusFailed, detail.Children[1].Status)
+
+	childDetail, err := env.repo.Detail(ctx, manualapp.DetailQuery{UserID: 10, WorkflowID: "wf-child-b", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, "wf-parent", *childDetail.ParentWorkflowID)
+	require.Empty(t, childDetail.Children)
+}
+
+func TestGormWorkflowStatusRepository_RequestCancelFanOutParent(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	parent := workflowHistoryRecordFixture(10, "wf-parent", env.nowUTC, manualapp.WorkflowStatusRunning)
+	parent.FanOut = true
+	queuedChild := workflowHistoryRecordFixture(10, "wf-child-queued", env.nowUTC, manualapp.WorkflowStatusQueued)
+	queuedChild.ParentWorkflowID = stringPtr("wf-parent")
+	runningChild := workflowHistoryRecordFixture(10, "wf-child-running", env.nowUTC, manualapp.WorkflowStatusRunning)
+	runningChild.ParentWorkflowID = stringPtr("wf-parent")
+	require.NoError(t, env.db.Create(&parent).Error)
+	require.NoError(t, env.db.Create(&queuedChild).Error)
+	require.NoError(t, env.db.Create(&runningChild).Error)
+
+	requestedAt := env.nowUTC.Add(time.Minute)
+	result, err := env.repo.RequestCancel(ctx, 10, "wf-parent", requestedAt)
+	require.NoError(t, err)
+	require.Equal(t, manualapp.WorkflowStatusRunning, result.Status)
+
+	require.NoError(t, env.db.First(&queuedChild, queuedChild.ID).Error)
+	require.Equal(t, manualapp.WorkflowStatusCancelled, queuedChild.Status)
+	require.NoError(t, env.db.First(&runningChild, runningChild.ID).Error)
+	require.Equal(t, manualapp.WorkflowStatusRunning, runningChild.Status)
+	require.NotNil(t, runningChild.CancelRequestedAt)
+
+	require.NoError(t, env.repo.MarkCancelled(ctx, runningChild.ID, "analysis", requestedAt.Add(time.Minute)))
+	require.NoError(t, env.db.First(&parent, parent.ID).Error)
+	require.Equal(t, manualapp.WorkflowStatusCancelled, parent.Status)
+
+	result, err = env.repo.RequestCancel(ctx, 10, "wf-parent", requestedAt)
+	require.NoError(t, err)
+	require.Equal(t, manualapp.WorkflowStatusCancelled, result.Status)
+}
+
+func TestFanOutParentUpdates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 25, 15, 0, 0, 0, time.UTC)
+	finishedAt := now.Add(-time.Minute)
+	children := []manualMailWorkflowHistoryRecord{
+		{Status: manualapp.WorkflowStatusSucceeded, FinishedAt: &finishedAt, FetchSuccessCount: 2, BillingSuccessCount: 1},
+		{Status: manualapp.WorkflowStatusRunning, FetchSuccessCount: 4, AnalysisBusinessFailureCount: 1},
+	}
+
+	updates := fanOutParentUpdates(children, now)
+	require.Equal(t, manualapp.WorkflowStatusRunning, updates["status"])
+	require.Nil(t, updates["finished_at"])
+	require.Nil(t, updates["error_message"])
+	require.Equal(t, 6, updates["fetch_success_count"])
+	require.Equal(t, 1, updates["analysis_business_failure_count"])
+	require.Equal(t, 1, updates["billing_success_count"])
+
+	children[1].Status = manualapp.WorkflowStatusCancelled
+	updates = fanOutParentUpdates(children, now)
+	require.Equal(t, manualapp.WorkflowStatusPartialSuccess, updates["status"])
+	require.Equal(t, &finishedAt, updates["finished_at"])
+}
@@ -0,0 +1,597 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	workflowJobStatusPending   = "pending"
+	workflowJobStatusRunning   = "running"
+	workflowJobStatusFinished  = "finished"
+	workflowJobStatusAbandoned = "abandoned"
+
+	defaultWorkflowJobMaxAttempts = 3
+
+	workflowJobAbandonedMessage   = "メール取得ワークフローの実行が繰り返し中断されたため、処理を打ち切りました。"
+	workflowJobUnrecoverableError = "中断されたメール取得ワークフローのメール連携が見つからないため、再開できませんでした。"
+)
+
+var (
+	// ErrWorkflowJobLeaseLost indicates the job lease expired and may have been claimed by another worker.
+	ErrWorkflowJobLeaseLost = errors.New("manual mail workflow job lease lost")
+)
+
+type manualMailWorkflowJobRecord struct {
+	ID                uint64                     `gorm:"column:id;primaryKey;autoIncrement"`
+	WorkflowHistoryID uint64                     `gorm:"column:workflow_history_id;not null;uniqueIndex:uni_manual_mail_workflow_jobs_workflow_history_id"`
+	WorkflowID        string                     `gorm:"column:workflow_id;type:char(26);not null"`
+	RetryOfWorkflowID *string                    `gorm:"column:retry_of_workflow_id;type:char(26)"`
+	DryRun            bool                       `gorm:"column:dry_run;not null;default:false"`
+	UserID            uint                       `gorm:"column:user_id;not null"`
+	ConnectionID      uint                       `gorm:"column:connection_id;not null"`
+	LabelName         string                     `gorm:"column:label_name;size:255;not null"`
+	SinceAt           time.Time                  `gorm:"column:since_at;not null"`
+	UntilAt           time.Time                  `gorm:"column:until_at;not null"`
+	FetchFilter       *workflowFetchFilterColumn `gorm:"column:fetch_filter;type:json;serializer:json"`
+	RequestID         *string                    `gorm:"column:request_id;size:64"`
+	Status            string                     `gorm:"column:status;size:32;not null;index:idx_manual_mail_workflow_jobs_status_available_at,priority:1;index:idx_manual_mail_workflow_jobs_status_lease_expires_at,priority:1"`
+	AttemptCount      int                        `gorm:"column:attempt_count;not null;default:0"`
+	MaxAttempts       int                        `gorm:"column:max_attempts;not null"`
+	AvailableAt       time.Time                  `gorm:"column:available_at;not null;index:idx_manual_mail_workflow_jobs_status_available_at,priority:2"`
+	LeaseOwner        *string                    `gorm:"column:lease_owner;size:128"`
+	LeaseExpiresAt    *time.Time                 `gorm:"column:lease_expires_at;index:idx_manual_mail_workflow_jobs_status_lease_expires_at,priority:2"`
+	LastError         *string                    `gorm:"column:last_error;type:text"`
+	CreatedAt         time.Time                  `gorm:"column:created_at;not null"`
+	UpdatedAt         time.Time                  `gorm:"column:updated_at;not null"`
+}
+
+func (manualMailWorkflowJobRecord) TableName() string {
+	return "manual_mail_workflow_jobs"
+}
+
+// ClaimedWorkflowJob is a workflow job leased to one worker.
+type ClaimedWorkflowJob struct {
+	JobID        uint64
+	Job          manualapp.DispatchJob
+	RequestID    string
+	AttemptCount int
+	// Exhausted is true when the job ran out of attempts and must be failed instead of executed.
+	Exhausted bool
+	// CurrentStage is the last stage recorded on the workflow history, used when failing exhausted jobs.
+	CurrentStage string
+}
+
+// GormWorkflowJobQueue is a MySQL-backed durable queue for manual mail workflow jobs.
+type GormWorkflowJobQueue struct {
+	db          *gorm.DB
+	clock       timewrapper.ClockInterface
+	maxAttempts int
+	publisher   manualapp.WorkflowEventPublisher
+	log         logger.Interface
+}
+
+// NewGormWorkflowJobQueue creates a Gorm-backed workflow job queue.
+func NewGormWorkflowJobQueue(
+	db *gorm.DB,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) *GormWorkflowJobQueue {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &GormWorkflowJobQueue{
+		db:          db,
+		clock:       clock,
+		maxAttempts: defaultWorkflowJobMaxAttempts,
+		log:         log.With(logger.Component("manual_mail_workflow_job_queue")),
+	}
+}
+
+// WithEventPublisher makes Requeue publish queued for a running workflow it puts back to the queue,
+// so that live subscribers do not keep showing it as running.
+func (q *GormWorkflowJobQueue) WithEventPublisher(publisher manualapp.WorkflowEventPublisher) *GormWorkflowJobQueue {
+	q.publisher = publisher
+	return q
+}
+
+// Dispatch persists the job so that any worker can pick it up, including after a restart.
+// Dispatching a history that already has a job, as a resumed workflow does, resets that job to pending.
+func (q *GormWorkflowJobQueue) Dispatch(ctx context.Context, job manualapp.DispatchJob) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if q.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+	if job.HistoryID == 0 {
+		return fmt.Errorf("%w: history_id is required", manualapp.ErrInvalidCommand)
+	}
+
+	now := q.clock.Now().UTC()
+	record := newWorkflowJobRecord(job, requestIDFromContext(ctx), q.maxAttempts, now)
+	if err := q.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "workflow_history_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"connection_id":    record.ConnectionID,
+			"request_id":       record.RequestID,
+			"status":           workflowJobStatusPending,
+			"attempt_count":    0,
+			"max_attempts":     record.MaxAttempts,
+			"available_at":     now,
+			"lease_owner":      nil,
+			"lease_expires_at": nil,
+			"last_error":       nil,
+			"updated_at":       now,
+		}),
+	}).Create(&record).Error; err != nil {
+		q.logDBError(ctx, "create", err)
+		return fmt.Errorf("failed to enqueue workflow job: %w", err)
+	}
+
+	return nil
+}
+
+// Claim leases the next runnable job. Pending jobs and running jobs whose lease expired are both claimable.
+func (q *GormWorkflowJobQueue) Claim(ctx context.Context, owner string, leaseDuration time.Duration) (ClaimedWorkflowJob, bool, error) {
+	if ctx == nil {
+		return ClaimedWorkflowJob{}, false, logger.ErrNilContext
+	}
+	if q.db == nil {
+		return ClaimedWorkflowJob{}, false, fmt.Errorf("gorm db is not configured")
+	}
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return ClaimedWorkflowJob{}, false, fmt.Errorf("lease owner is required")
+	}
+
+	now := q.clock.Now().UTC()
+	var claimed ClaimedWorkflowJob
+	found := false
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var record manualMailWorkflowJobRecord
+		findTx := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND available_at <= ?) OR (status = ? AND lease_expires_at < ?)",
+				workflowJobStatusPending, now,
+				workflowJobStatusRunning, now,
+			).
+			Order("available_at ASC").
+			Order("id ASC").
+			Limit(1).
+			Find(&record)
+		if findTx.Error != nil {
+			return findTx.Error
+		}
+		if findTx.RowsAffected == 0 {
+			return nil
+		}
+		found = true
+
+		if record.AttemptCount >= record.MaxAttempts {
+			var currentStage *string
+			if err := tx.Model(&manualMailWorkflowHistoryRecord{}).
+				Where("id = ?", record.WorkflowHistoryID).
+				Pluck("current_stage", &currentStage).Error; err != nil {
+				return err
+			}
+			lastError := workflowJobAbandonedMessage
+			if err := tx.Model(&manualMailWorkflowJobRecord{}).
+				Where("id = ?", record.ID).
+				Updates(map[string]interface{}{
+					"status":           workflowJobStatusAbandoned,
+					"lease_owner":      nil,
+					"lease_expires_at": nil,
+					"last_error":       &lastError,
+					"updated_at":       now,
+				}).Error; err != nil {
+				return err
+			}
+			claimed = buildClaimedWorkflowJob(record)
+			claimed.Exhausted = true
+			if currentStage != nil {
+				claimed.CurrentStage = *currentStage
+			}
+			return nil
+		}
+
+		leaseExpiresAt := now.Add(leaseDuration)
+		if err := tx.Model(&manualMailWorkflowJobRecord{}).
+			Where("id = ?", record.ID).
+			Updates(map[string]interface{}{
+				"status":           workflowJobStatusRunning,
+				"attempt_count":    record.AttemptCount + 1,
+				"lease_owner":      owner,
+				"lease_expires_at": &leaseExpiresAt,
+				"updated_at":       now,
+			}).Error; err != nil {
+			return err
+		}
+		record.AttemptCount++
+		claimed = buildClaimedWorkflowJob(record)
+		return nil
+	})
+	if err != nil {
+		q.logDBError(ctx, "claim", err)
+		return ClaimedWorkflowJob{}, false, fmt.Errorf("failed to claim workflow job: %w", err)
+	}
+
+	return claimed, found, nil
+}
+
+// Heartbeat extends the lease while the owner keeps executing the job.
+func (q *GormWorkflowJobQueue) Heartbeat(ctx context.Context, jobID uint64, owner string, leaseDuration time.Duration) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if q.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	now := q.clock.Now().UTC()
+	leaseExpiresAt := now.Add(leaseDuration)
+	tx := q.db.WithContext(ctx).
+		Model(&manualMailWorkflowJobRecord{}).
+		Where("id = ? AND status = ? AND lease_owner = ?", jobID, workflowJobStatusRunning, owner).
+		Updates(map[string]interface{}{
+			"lease_expires_at": &leaseExpiresAt,
+			"updated_at":       now,
+		})
+	if tx.Error != nil {
+		q.logDBError(ctx, "heartbeat", tx.Error)
+		return fmt.Errorf("failed to extend workflow job lease: %w", tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return ErrWorkflowJobLeaseLost
+	}
+
+	return nil
+}
+
+// Finish releases the lease once the workflow runner returned, whatever its outcome.
+func (q *GormWorkflowJobQueue) Finish(ctx context.Context, jobID uint64, owner string, runErr error) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if q.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	var lastError *string
+	if runErr != nil {
+		message := runErr.Error()
+		lastError = &message
+	}
+
+	now := q.clock.Now().UTC()
+	tx := q.db.WithContext(ctx).
+		Model(&manualMailWorkflowJobRecord{}).
+		Where("id = ? AND status = ? AND lease_owner = ?", jobID, workflowJobStatusRunning, owner).
+		Updates(map[string]interface{}{
+			"status":           workflowJobStatusFinished,
+			"lease_owner":      nil,
+			"lease_expires_at": nil,
+			"last_error":       lastError,
+			"updated_at":       now,
+		})
+	if tx.Error != nil {
+		q.logDBError(ctx, "finish", tx.Error)
+		return fmt.Errorf("failed to finish workflow job: %w", tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return ErrWorkflowJobLeaseLost
+	}
+
+	return nil
+}
+
+// Requeue puts a job interrupted by a shutdown back to pending without spending an attempt.
+// Its history returns to queued so that it is not mistaken for a running workflow; the next claim
+// resumes it from the last stage handoff.
+func (q *GormWorkflowJobQueue) Requeue(ctx context.Context, jobID uint64, owner string) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if q.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	now := q.clock.Now().UTC()
+	var requeuedHistoryID uint64
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var record manualMailWorkflowJobRecord
+		findTx := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ? AND lease_owner = ?", jobID, workflowJobStatusRunning, owner).
+			Limit(1).
+			Find(&record)
+		if findTx.Error != nil {
+			return findTx.Error
+		}
+		if findTx.RowsAffected == 0 {
+			return ErrWorkflowJobLeaseLost
+		}
+
+		if err := tx.Model(&manualMailWorkflowJobRecord{}).
+			Where("id = ?", record.ID).
+			Updates(map[string]interface{}{
+				"status":           workflowJobStatusPending,
+				"attempt_count":    max(record.AttemptCount-1, 0),
+				"available_at":     now,
+				"lease_owner":      nil,
+				"lease_expires_at": nil,
+				"updated_at":       now,
+			}).Error; err != nil {
+			return err
+		}
+
+		historyTx := tx.Model(&manualMailWorkflowHistoryRecord{}).
+			Where("id = ? AND status = ?", record.WorkflowHistoryID, manualapp.WorkflowStatusRunning).
+			Updates(map[string]interface{}{
+				"status":        manualapp.WorkflowStatusQueued,
+				"current_stage": nil,
+				"updated_at":    now,
+			})
+		if historyTx.Error != nil {
+			return historyTx.Error
+		}
+		if historyTx.RowsAffected > 0 {
+			requeuedHistoryID = record.WorkflowHistoryID
+		}
+		if err := finishOpenStageEvents(tx, []uint64{record.WorkflowHistoryID}, now); err != nil {
+			return err
+		}
+		return refreshFanOutParents(tx, []uint64{record.WorkflowHistoryID}, now)
+	})
+	if err != nil {
+		if errors.Is(err, ErrWorkflowJobLeaseLost) {
+			return err
+		}
+		q.logDBError(ctx, "requeue", err)
+		return fmt.Errorf("failed to requeue workflow job: %w", err)
+	}
+	if requeuedHistoryID != 0 {
+		q.publish(ctx, manualapp.WorkflowEvent{
+			Type:       manualapp.WorkflowEventQueued,
+			HistoryID:  requeuedHistoryID,
+			Status:     manualapp.WorkflowStatusQueued,
+			OccurredAt: now,
+		})
+	}
+
+	return nil
+}
+
+// RecoverOrphanedHistories enqueues jobs for queued/running histories that were never persisted to the queue,
+// such as runs accepted before the durable queue existed or a crash between CreateQueued and Dispatch.
+// Sync-all parents never have a job of their own and are left to their children.
+func (q *GormWorkflowJobQueue) RecoverOrphanedHistories(ctx context.Context) (int, error) {
+	if ctx == nil {
+		return 0, logger.ErrNilContext
+	}
+	if q.db == nil {
+		return 0, fmt.Errorf("gorm db is not configured")
+	}
+
+	var histories []manualMailWorkflowHistoryRecord
+	findTx := q.db.WithContext(ctx).
+		Table("manual_mail_workflow_histories AS h").
+		Select("h.*").
+		Joins("LEFT JOIN manual_mail_workflow_jobs AS j ON j.workflow_history_id = h.id").
+		Where("h.status IN ? AND h.fan_out = ? AND j.id IS NULL", []string{manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning}, false).
+		Order("h.id ASC").
+		Find(&histories)
+	if findTx.Error != nil {
+		q.logDBError(ctx, "find_orphaned_histories", findTx.Error)
+		return 0, fmt.Errorf("failed to find orphaned workflow histories: %w", findTx.Error)
+	}
+	if len(histories) == 0 {
+		return 0, nil
+	}
+
+	connectionIDs, err := q.resolveHistoryConnections(ctx, histories)
+	if err != nil {
+		return 0, err
+	}
+
+	now := q.clock.Now().UTC()
+	jobs := make([]manualMailWorkflowJobRecord, 0, len(histories))
+	unrecoverableIDs := make([]uint64, 0)
+	for _, history := range histories {
+		connectionID, ok := connectionIDs[history.ID]
+		if !ok {
+			unrecoverableIDs = append(unrecoverableIDs, history.ID)
+			continue
+		}
+		jobs = append(jobs, newWorkflowJobRecord(manualapp.DispatchJob{
+			HistoryID:    history.ID,
+			WorkflowID:   history.WorkflowID,
+			UserID:       history.UserID,
+			ConnectionID: connectionID,
+			Condition: manualapp.FetchCondition{
+				LabelName: history.LabelName,
+				Since:     history.SinceAt,
+				Until:     history.UntilAt,
+				Filter:    history.FetchFilter.toFetchFilter(),
+			},
+			RetryOfWorkflowID: stringValue(history.RetryOfWorkflowID),
+			DryRun:            history.DryRun,
+		}, "", q.maxAttempts, now))
+	}
+
+	err = q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(jobs) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&jobs).Error; err != nil {
+				return err
+			}
+		}
+		if len(unrecoverableIDs) > 0 {
+			errorMessage := workflowJobUnrecoverableError
+			if err := tx.Model(&manualMailWorkflowHistoryRecord{}).
+				Where("id IN ? AND status IN ?", unrecoverableIDs, []string{manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning}).
+				Updates(map[string]interface{}{
+					"status":        manualapp.WorkflowStatusFailed,
+					"finished_at":   &now,
+					"error_message": &errorMessage,
+					"updated_at":    now,
+				}).Error; err != nil {
+				return err
+			}
+			if err := refreshFanOutParents(tx, unrecoverableIDs, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		q.logDBError(ctx, "recover_orphaned_histories", err)
+		return 0, fmt.Errorf("failed to recover orphaned workflow histories: %w", err)
+	}
+
+	return len(jobs), nil
+}
+
+// resolveHistoryConnections maps history IDs to the still-connected credential they were accepted for.
+func (q *GormWorkflowJobQueue) resolveHistoryConnections(
+	ctx context.Context,
+	histories []manualMailWorkflowHistoryRecord,
+) (map[uint64]uint, error) {
+	userIDs := make([]uint, 0, len(histories))
+	seenUserIDs := make(map[uint]struct{}, len(histories))
+	for _, history := range histories {
+		if _, seen := seenUserIDs[history.UserID]; seen {
+			continue
+		}
+		seenUserIDs[history.UserID] = struct{}{}
+		userIDs = append(userIDs, history.UserID)
+	}
+
+	var credentials []emailCredentialSnapshotRecord
+	if err := q.db.WithContext(ctx).
+		Where("user_id IN ? AND o_auth_state IS NULL", userIDs).
+		Find(&credentials).Error; err != nil {
+		q.logDBError(ctx, "find_connection_snapshots", err)
+		return nil, fmt.Errorf("failed to find connection snapshots: %w", err)
+	}
+
+	credentialIDs := make(map[string]uint, len(credentials))
+	for _, credential := range credentials {
+		credentialIDs[connectionSnapshotKey(
+			credential.UserID,
+			credential.Type,
+			credential.GmailAddress,
+		)] = credential.ID
+	}
+
+	connectionIDs := make(map[uint64]uint, len(histories))
+	for _, history := range histories {
+		if id, ok := credentialIDs[connectionSnapshotKey(history.UserID, history.Provider, history.AccountIdentifier)]; ok {
+			connectionIDs[history.ID] = id
+		}
+	}
+
+	return connectionIDs, nil
+}
+
+func (q *GormWorkflowJobQueue) publish(ctx context.Context, event manualapp.WorkflowEvent) {
+	if q.publisher == nil {
+		return
+	}
+
+	// The transition is already committed, so a publish failure is only logged.
+	if err := q.publisher.Publish(context.WithoutCancel(ctx), event); err != nil {
+		reqLog := q.log
+		if withContext, withCtxErr := q.log.WithContext(ctx); withCtxErr == nil {
+			reqLog = withContext
+		}
+		reqLog.Warn("manual_mail_workflow_event_publish_failed",
+			logger.String("event_type", event.Type),
+			logger.Uint("history_id", uint(event.HistoryID)),
+			logger.Err(err),
+		)
+	}
+}
+
+func (q *GormWorkflowJobQueue) logDBError(ctx context.Context, operation string, err error) {
+	reqLog := q.log
+	if withContext, withCtxErr := q.log.WithContext(ctx); withCtxErr == nil {
+		reqLog = withContext
+	}
+
+	reqLog.Error("db_query_failed",
+		logger.String("db_system", "mysql"),
+		logger.String("table", "manual_mail_workflow_jobs"),
+		logger.String("operation", operation),
+		logger.Err(err),
+	)
+}
+
+func newWorkflowJobRecord(job manualapp.DispatchJob, requestID string, maxAttempts int, now time.Time) manualMailWorkflowJobRecord {
+	record := manualMailWorkflowJobRecord{
+		WorkflowHistoryID: job.HistoryID,
+		WorkflowID:        strings.TrimSpace(job.WorkflowID),
+		UserID:            job.UserID,
+		ConnectionID:      job.ConnectionID,
+		LabelName:         strings.TrimSpace(job.Condition.LabelName),
+		SinceAt:           job.Condition.Since.UTC(),
+		UntilAt:           job.Condition.Until.UTC(),
+		FetchFilter:       toWorkflowFetchFilterColumn(job.Condition.Filter),
+		DryRun:            job.DryRun,
+		Status:            workflowJobStatusPending,
+		MaxAttempts:       maxAttempts,
+		AvailableAt:       now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if requestID = strings.TrimSpace(requestID); requestID != "" {
+		record.RequestID = &requestID
+	}
+	record.RetryOfWorkflowID = optionalString(job.RetryOfWorkflowID)
+	return record
+}
+
+func buildClaimedWorkflowJob(record manualMailWorkflowJobRecord) ClaimedWorkflowJob {
+	claimed := ClaimedWorkflowJob{
+		JobID: record.ID,
+		Job: manualapp.DispatchJob{
+			HistoryID:    record.WorkflowHistoryID,
+			WorkflowID:   record.WorkflowID,
+			UserID:       record.UserID,
+			ConnectionID: record.ConnectionID,
+			Condition: manualapp.FetchCondition{
+				LabelName: record.LabelName,
+				Since:     record.SinceAt.UTC(),
+				Until:     record.UntilAt.UTC(),
+				Filter:    record.FetchFilter.toFetchFilter(),
+			},
+			DryRun: record.DryRun,
+		},
+		AttemptCount: record.AttemptCount,
+	}
+	if record.RequestID != nil {
+		claimed.RequestID = *record.RequestID
+	}
+	if record.RetryOfWorkflowID != nil {
+		claimed.Job.RetryOfWorkflowID = *record.RetryOfWorkflowID
+	}
+	return claimed
+}
+
+func connectionSnapshotKey(userID uint, provider string, accountIdentifier string) string {
+	return fmt.Sprintf("%d/%s/%s",
+		userID,
+		strings.ToLower(strings.TrimSpace(provider)),
+		strings.ToLower(strings.TrimSpace(accountIdentifier)),
+	)
+}
@@ -0,0 +1,242 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/mysql"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type workflowJobQueueTestEnv struct {
+	queue  *GormWorkflowJobQueue
+	db     *gorm.DB
+	clock  *workflowStatusRepoFixedClock
+	nowUTC time.Time
+	clean  func() error
+}
+
+func newWorkflowJobQueueTestEnv(t *testing.T) *workflowJobQueueTestEnv {
+	t.Helper()
+
+	mysqlConn, cleanup, err := mysql.CreateNewTestDB()
+	if err != nil {
+		skipIfWorkflowStatusRepoDBUnavailable(t, err)
+	}
+	require.NoError(t, err)
+	require.NoError(t, mysqlConn.DB.AutoMigrate(
+		&emailCredentialSnapshotRecord{},
+		&manualMailWorkflowHistoryRecord{},
+		&manualMailWorkflowStageEventRecord{},
+		&manualMailWorkflowJobRecord{},
+	))
+
+	nowUTC := time.Date(2026, 3, 25, 15, 0, 0, 0, time.UTC)
+	clock := &workflowStatusRepoFixedClock{now: nowUTC}
+	return &workflowJobQueueTestEnv{
+		queue:  NewGormWorkflowJobQueue(mysqlConn.DB, clock, logger.NewNop()),
+		db:     mysqlConn.DB,
+		clock:  clock,
+		nowUTC: nowUTC,
+		clean:  cleanup,
+	}
+}
+
+func TestGormWorkflowJobQueue_DispatchClaimHeartbeatAndFinish(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowJobQueueTestEnv(t)
+	defer env.clean()
+
+	requestCtx, err := logger.ContextWithRequestID(context.Background(), "req-123")
+	require.NoError(t, err)
+
+	job := manualapp.DispatchJob{
+		HistoryID:    44,
+		WorkflowID:   "wf-123",
+		UserID:       9,
+		ConnectionID: 21,
+		Condition: manualapp.FetchCondition{
+			LabelName: "billing",
+			Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	require.NoError(t, env.queue.Dispatch(requestCtx, job))
+
+	ctx := context.Background()
+	claimed, found, err := env.queue.Claim(ctx, "worker-a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, job, claimed.Job)
+	require.Equal(t, "req-123", claimed.RequestID)
+	require.Equal(t, 1, claimed.AttemptCount)
+	require.False(t, claimed.Exhausted)
+
+	_, found, err = env.queue.Claim(ctx, "worker-b", time.Minute)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, env.queue.Heartbeat(ctx, claimed.JobID, "worker-a", time.Minute))
+	require.ErrorIs(t, env.queue.Heartbeat(ctx, claimed.JobID, "worker-b", time.Minute), ErrWorkflowJobLeaseLost)
+
+	require.NoError(t, env.queue.Finish(ctx, claimed.JobID, "worker-a", nil))
+
+	var stored manualMailWorkflowJobRecord
+	require.NoError(t, env.db.First(&stored, claimed.JobID).Error)
+	require.Equal(t, workflowJobStatusFinished, stored.Status)
+	require.Nil(t, stored.LeaseOwner)
+	require.Nil(t, stored.LeaseExpiresAt)
+}
+
+func TestGormWorkflowJobQueue_Claim_RetakesExpiredLeaseAndAbandonsExhaustedJob(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowJobQueueTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	history := workflowHistoryRecordFixture(9, "wf-exhausted", env.nowUTC, manualapp.WorkflowStatusRunning)
+	history.CurrentStage = stringPtr("analysis")
+	require.NoError(t, env.db.Create(&history).Error)
+
+	require.NoError(t, env.queue.Dispatch(ctx, manualapp.DispatchJob{
+		HistoryID:    history.ID,
+		WorkflowID:   history.WorkflowID,
+		UserID:       history.UserID,
+		ConnectionID: 21,
+		Condition: manualapp.FetchCondition{
+			LabelName: history.LabelName,
+			Since:     history.SinceAt,
+			Until:     history.UntilAt,
+		},
+	}))
+
+	for attempt := 1; attempt <= defaultWorkflowJobMaxAttempts; attempt++ {
+		claimed, found, err := env.queue.Claim(ctx, "worker-a", time.Minute)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, attempt, claimed.AttemptCount)
+		require.False(t, claimed.Exhausted)
+		env.clock.now = env.clock.now.Add(2 * time.Minute)
+	}
+
+	claimed, found, err := env.queue.Claim(ctx, "worker-b", time.Minute)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.True(t, claimed.Exhausted)
+	require.Equal(t, "analysis", claimed.CurrentStage)
+
+	_, found, err = env.queue.Claim(ctx, "worker-b", time.Minute)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestGormWorkflowJobQueue_Requeue_ReturnsInterruptedJobToPending(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowJobQueueTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	history := workflowHistoryRecordFixture(9, "wf-interrupted", env.nowUTC, manualapp.WorkflowStatusRunning)
+	history.CurrentStage = stringPtr("analysis")
+	require.NoError(t, env.db.Create(&history).Error)
+
+	require.NoError(t, env.queue.Dispatch(ctx, manualapp.DispatchJob{
+		HistoryID:    history.ID,
+		WorkflowID:   history.WorkflowID,
+		UserID:       history.UserID,
+		ConnectionID: 21,
+		Condition: manualapp.FetchCondition{
+			LabelName: history.LabelName,
+			Since:     history.SinceAt,
+			Until:     history.UntilAt,
+		},
+	}))
+
+	publisher := &recordingWorkflowEventPublisher{}
+	env.queue.WithEventPublisher(publisher)
+
+	claimed, found, err := env.queue.Claim(ctx, "worker-a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.ErrorIs(t, env.queue.Requeue(ctx, claimed.JobID, "worker-b"), ErrWorkflowJobLeaseLost)
+	require.Empty(t, publisher.events)
+	require.NoError(t, env.queue.Requeue(ctx, claimed.JobID, "worker-a"))
+
+	require.Equal(t, []manualapp.WorkflowEvent{{
+		Type:       manualapp.WorkflowEventQueued,
+		HistoryID:  history.ID,
+		Status:     manualapp.WorkflowStatusQueued,
+		OccurredAt: env.nowUTC,
+	}}, publisher.events)
+
+	var storedJob manualMailWorkflowJobRecord
+	require.NoError(t, env.db.First(&storedJob, claimed.JobID).Error)
+	require.Equal(t, workflowJobStatusPending, storedJob.Status)
+	require.Equal(t, 0, storedJob.AttemptCount)
+	require.Nil(t, storedJob.LeaseOwner)
+
+	var storedHistory manualMailWorkflowHistoryRecord
+	require.NoError(t, env.db.First(&storedHistory, history.ID).Error)
+	require.Equal(t, manualapp.WorkflowStatusQueued, storedHistory.Status)
+	require.Nil(t, storedHistory.CurrentStage)
+	require.Nil(t, storedHistory.FinishedAt)
+
+	reclaimed, found, err := env.queue.Claim(ctx, "worker-b", time.Minute)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, 1, reclaimed.AttemptCount)
+}
+
+func TestGormWorkflowJobQueue_RecoverOrphanedHistories(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowJobQueueTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{
+		ID:           20,
+		UserID:       9,
+		Type:         "gmail",
+		GmailAddress: "billing@example.com",
+	})
+
+	recoverable := workflowHistoryRecordFixture(9, "wf-recoverable", env.nowUTC, manualapp.WorkflowStatusQueued)
+	require.NoError(t, env.db.Create(&recoverable).Error)
+	unrecoverable := workflowHistoryRecordFixture(10, "wf-unrecoverable", env.nowUTC, manualapp.WorkflowStatusRunning)
+	unrecoverable.CurrentStage = stringPtr("fetch")
+	require.NoError(t, env.db.Create(&unrecoverable).Error)
+	finished := workflowHistoryRecordFixture(9, "wf-finished", env.nowUTC, manualapp.WorkflowStatusSucceeded)
+	require.NoError(t, env.db.Create(&finished).Error)
+
+	recovered, err := env.queue.RecoverOrphanedHistories(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, recovered)
+
+	var jobs []manualMailWorkflowJobRecord
+	require.NoError(t, env.db.Order("id ASC").Find(&jobs).Error)
+	require.Len(t, jobs, 1)
+	require.Equal(t, recoverable.ID, jobs[0].WorkflowHistoryID)
+	require.Equal(t, uint(20), jobs[0].ConnectionID)
+	require.Equal(t, workflowJobStatusPending, jobs[0].Status)
+
+	var failedHistory manualMailWorkflowHistoryRecord
+	require.NoError(t, env.db.First(&failedHistory, unrecoverable.ID).Error)
+	require.Equal(t, manualapp.WorkflowStatusFailed, failedHistory.Status)
+	require.NotNil(t, failedHistory.CurrentStage)
+	require.Equal(t, "fetch", *failedHistory.CurrentStage)
+	require.NotNil(t, failedHistory.ErrorMessage)
+	require.Equal(t, workflowJobUnrecoverableError, *failedHistory.ErrorMessage)
+
+	recovered, err = env.queue.RecoverOrphanedHistories(ctx)
+	require.NoError(t, err)
+	require.Zero(t, recovered)
+}
@@ -0,0 +1,354 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWorkflowJobWorkerConcurrency       = 2
+	defaultWorkflowJobWorkerPollInterval      = 2 * time.Second
+	defaultWorkflowJobWorkerLeaseDuration     = 2 * time.Minute
+	defaultWorkflowJobWorkerHeartbeatInterval = 30 * time.Second
+	defaultWorkflowJobWorkerCancelInterval    = 5 * time.Second
+)
+
+type workflowJobQueue interface {
+	Claim(ctx context.Context, owner string, leaseDuration time.Duration) (ClaimedWorkflowJob, bool, error)
+	Heartbeat(ctx context.Context, jobID uint64, owner string, leaseDuration time.Duration) error
+	Finish(ctx context.Context, jobID uint64, owner string, runErr error) error
+	Requeue(ctx context.Context, jobID uint64, owner string) error
+	RecoverOrphanedHistories(ctx context.Context) (int, error)
+}
+
+type workflowHistoryStore interface {
+	Fail(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time, errorMessage string) error
+	IsCancelRequested(ctx context.Context, historyID uint64) (bool, error)
+}
+
+// WorkflowJobWorkerConfig controls polling and lease behavior of WorkflowJobWorker.
+type WorkflowJobWorkerConfig struct {
+	Concurrency       int
+	PollInterval      time.Duration
+	LeaseDuration     time.Duration
+	HeartbeatInterval time.Duration
+	// CancelCheckInterval is how often a running job checks whether the user cancelled the workflow.
+	CancelCheckInterval time.Duration
+}
+
+// DefaultWorkflowJobWorkerConfig returns the default worker configuration.
+func DefaultWorkflowJobWorkerConfig() WorkflowJobWorkerConfig {
+	return WorkflowJobWorkerConfig{
+		Concurrency:         defaultWorkflowJobWorkerConcurrency,
+		PollInterval:        defaultWorkflowJobWorkerPollInterval,
+		LeaseDuration:       defaultWorkflowJobWorkerLeaseDuration,
+		HeartbeatInterval:   defaultWorkflowJobWorkerHeartbeatInterval,
+		CancelCheckInterval: defaultWorkflowJobWorkerCancelInterval,
+	}
+}
+
+// WorkflowJobWorker claims queued workflow jobs and runs them while keeping their lease alive.
+type WorkflowJobWorker struct {
+	queue     workflowJobQueue
+	runner    manualapp.UseCase
+	histories workflowHistoryStore
+	clock     timewrapper.ClockInterface
+	cfg       WorkflowJobWorkerConfig
+	owner     string
+	log       logger.Interface
+}
+
+// NewWorkflowJobWorker creates a worker for the durable workflow job queue.
+func NewWorkflowJobWorker(
+	queue workflowJobQueue,
+	runner manualapp.UseCase,
+	histories workflowHistoryStore,
+	clock timewrapper.ClockInterface,
+	cfg WorkflowJobWorkerConfig,
+	log logger.Interface,
+) *WorkflowJobWorker {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	defaults := DefaultWorkflowJobWorkerConfig()
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaults.Concurrency
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = defaults.LeaseDuration
+	}
+	if cfg.HeartbeatInterval <= 0 || cfg.HeartbeatInterval >= cfg.LeaseDuration {
+		cfg.HeartbeatInterval = cfg.LeaseDuration / 3
+	}
+	if cfg.CancelCheckInterval <= 0 {
+		cfg.CancelCheckInterval = defaults.CancelCheckInterval
+	}
+
+	return &WorkflowJobWorker{
+		queue:     queue,
+		runner:    runner,
+		histories: histories,
+		clock:     clock,
+		cfg:       cfg,
+		owner:     newWorkflowJobOwnerID(),
+		log:       log.With(logger.Component("manual_mail_workflow_job_worker")),
+	}
+}
+
+// Run recovers orphaned histories, then polls the queue until ctx is cancelled.
+// Cancelling ctx also interrupts in-flight jobs, which are put back on the queue; Run returns once they are.
+func (w *WorkflowJobWorker) Run(ctx context.Context) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if w.queue == nil {
+		return errors.New("manual mail workflow job queue is not configured")
+	}
+	if w.runner == nil {
+		return errors.New("manual mail workflow runner is not configured")
+	}
+	if w.histories == nil {
+		return errors.New("workflow status repository is not configured")
+	}
+
+	recovered, err := w.queue.RecoverOrphanedHistories(ctx)
+	if err != nil {
+		w.log.Error("manual_mail_workflow_recovery_failed", logger.Err(err))
+	} else if recovered > 0 {
+		w.log.Info("manual_mail_workflow_recovered", logger.Int("recovered_count", recovered))
+	}
+
+	w.log.Info("manual_mail_workflow_worker_started",
+		logger.String("owner", w.owner),
+		logger.Int("concurrency", w.cfg.Concurrency),
+	)
+
+	slots := make(chan struct{}, w.cfg.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Info("manual_mail_workflow_worker_stopping", logger.String("owner", w.owner))
+			return nil
+		case slots <- struct{}{}:
+		}
+
+		claimed, found, err := w.queue.Claim(ctx, w.owner, w.cfg.LeaseDuration)
+		if err != nil || !found {
+			<-slots
+			if err != nil && ctx.Err() == nil {
+				w.log.Error("manual_mail_workflow_claim_failed", logger.Err(err))
+			}
+			select {
+			case <-ctx.Done():
+			case <-w.clock.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+
+		wg.Add(1)
+		//nolint:nplusonecheck // Each claimed job runs once; the loop is the worker's polling loop, not a per-row query.
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			w.process(ctx, claimed)
+		}()
+	}
+}
+
+func (w *WorkflowJobWorker) process(workerCtx context.Context, claimed ClaimedWorkflowJob) {
+	job := claimed.Job
+	jobCtx, cancel := context.WithCancelCause(newBackgroundWorkflowContext(claimed.RequestID, job))
+	defer cancel(nil)
+
+	reqLog := w.log
+	if withContext, err := w.log.WithContext(jobCtx); err == nil {
+		reqLog = withContext
+	}
+
+	var watchers sync.WaitGroup
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			reqLog.Error("manual_mail_workflow_panicked",
+				logger.Recovered(recovered),
+				logger.StackTrace(),
+				logger.Uint("connection_id", job.ConnectionID),
+			)
+			cancel(nil)
+			watchers.Wait()
+
+			// The runner fails the history itself for panics it recovers, so this one was raised outside
+			// a stage and the stage at claim time is the last one recorded. Failing the history here keeps
+			// it from blocking new runs on the connection until the reaper notices it.
+			stopCtx := context.WithoutCancel(jobCtx)
+			failErr := w.histories.Fail(stopCtx, job.HistoryID, claimed.CurrentStage, w.clock.Now(), fmt.Sprintf("panic: %v", recovered))
+			if failErr != nil && !errors.Is(failErr, manualapp.ErrWorkflowAlreadyFinished) {
+				reqLog.Error("manual_mail_workflow_fail_panicked_failed", logger.Err(failErr))
+			}
+			if err := w.queue.Finish(stopCtx, claimed.JobID, w.owner, fmt.Errorf("panic: %v", recovered)); err != nil {
+				reqLog.Error("manual_mail_workflow_job_finish_failed", logger.Err(err))
+			}
+		}
+	}()
+
+	if claimed.Exhausted {
+		reqLog.Error("manual_mail_workflow_abandoned",
+			logger.String("workflow_id", job.WorkflowID),
+			logger.Uint("connection_id", job.ConnectionID),
+			logger.Int("attempt_count", claimed.AttemptCount),
+			logger.String("current_stage", claimed.CurrentStage),
+		)
+		if err := w.histories.Fail(jobCtx, job.HistoryID, claimed.CurrentStage, w.clock.Now(), workflowJobAbandonedMessage); err != nil {
+			reqLog.Error("manual_mail_workflow_fail_abandoned_failed", logger.Err(err))
+		}
+		return
+	}
+
+	reqLog.Info("manual_mail_workflow_started",
+		logger.String("workflow_id", job.WorkflowID),
+		logger.Uint("connection_id", job.ConnectionID),
+		logger.Int("attempt_count", claimed.AttemptCount),
+	)
+
+	// Stopping the worker interrupts the job so that the process can exit; the job is requeued below.
+	stopInterrupt := context.AfterFunc(workerCtx, func() {
+		cancel(manualapp.ErrWorkflowInterrupted)
+	})
+	defer stopInterrupt()
+
+	watchers.Add(2)
+	go func() {
+		defer watchers.Done()
+		w.keepAlive(jobCtx, workerCtx, cancel, claimed.JobID, reqLog)
+	}()
+	go func() {
+		defer watchers.Done()
+		w.watchCancelRequest(jobCtx, cancel, job.HistoryID, reqLog)
+	}()
+
+	_, runErr := w.runner.Execute(jobCtx, job)
+	cancel(nil)
+	watchers.Wait()
+
+	if errors.Is(runErr, manualapp.ErrWorkflowInterrupted) {
+		reqLog.Info("manual_mail_workflow_requeued",
+			logger.String("workflow_id", job.WorkflowID),
+			logger.Uint("connection_id", job.ConnectionID),
+		)
+		if err := w.queue.Requeue(context.WithoutCancel(jobCtx), claimed.JobID, w.owner); err != nil {
+			reqLog.Error("manual_mail_workflow_job_requeue_failed", logger.Err(err))
+		}
+		return
+	}
+
+	if runErr != nil && !errors.Is(runErr, manualapp.ErrWorkflowCancelled) {
+		reqLog.Error("manual_mail_workflow_failed",
+			logger.String("workflow_id", job.WorkflowID),
+			logger.Uint("connection_id", job.ConnectionID),
+			logger.Err(runErr),
+		)
+	}
+
+	if err := w.queue.Finish(context.WithoutCancel(jobCtx), claimed.JobID, w.owner, runErr); err != nil {
+		reqLog.Error("manual_mail_workflow_job_finish_failed", logger.Err(err))
+	}
+}
+
+// keepAlive extends the lease until the job ends. Losing the lease cancels the job
+// because another worker may already have taken it over.
+func (w *WorkflowJobWorker) keepAlive(
+	jobCtx context.Context,
+	workerCtx context.Context,
+	cancel context.CancelCauseFunc,
+	jobID uint64,
+	reqLog logger.Interface,
+) {
+	for {
+		select {
+		case <-jobCtx.Done():
+			return
+		case <-w.clock.After(w.cfg.HeartbeatInterval):
+		}
+
+		err := w.queue.Heartbeat(jobCtx, jobID, w.owner, w.cfg.LeaseDuration)
+		if err == nil {
+			continue
+		}
+		if jobCtx.Err() != nil {
+			return
+		}
+		if errors.Is(err, ErrWorkflowJobLeaseLost) {
+			reqLog.Error("manual_mail_workflow_lease_lost", logger.Err(err))
+			cancel(ErrWorkflowJobLeaseLost)
+			return
+		}
+		reqLog.Warn("manual_mail_workflow_heartbeat_failed", logger.Err(err))
+		if workerCtx.Err() != nil {
+			return
+		}
+	}
+}
+
+// watchCancelRequest cancels the job with manualapp.ErrWorkflowCancelled once the user asks to stop it,
+// so the runner can record the stage where it stopped.
+func (w *WorkflowJobWorker) watchCancelRequest(
+	jobCtx context.Context,
+	cancel context.CancelCauseFunc,
+	historyID uint64,
+	reqLog logger.Interface,
+) {
+	for {
+		select {
+		case <-jobCtx.Done():
+			return
+		case <-w.clock.After(w.cfg.CancelCheckInterval):
+		}
+
+		//nolint:nplusonecheck // Polling one history row at a fixed interval is intentional.
+		cancelRequested, err := w.histories.IsCancelRequested(jobCtx, historyID)
+		if err != nil {
+			if jobCtx.Err() != nil {
+				return
+			}
+			reqLog.Warn("manual_mail_workflow_cancel_check_failed", logger.Err(err))
+			continue
+		}
+		if cancelRequested {
+			reqLog.Info("manual_mail_workflow_cancel_observed", logger.Uint("history_id", uint(historyID)))
+			cancel(manualapp.ErrWorkflowCancelled)
+			return
+		}
+	}
+}
+
+func newWorkflowJobOwnerID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
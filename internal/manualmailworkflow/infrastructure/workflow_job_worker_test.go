@@ -0,0 +1,439 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubWorkflowJobQueue struct {
+	mu        sync.Mutex
+	jobs      []ClaimedWorkflowJob
+	heartbeat func(jobID uint64) error
+	finished  chan workflowJobFinishCall
+	requeued  chan uint64
+	recovered int
+}
+
+type workflowJobFinishCall struct {
+	jobID  uint64
+	owner  string
+	runErr error
+}
+
+func (s *stubWorkflowJobQueue) Claim(ctx context.Context, owner string, leaseDuration time.Duration) (ClaimedWorkflowJob, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		return ClaimedWorkflowJob{}, false, nil
+	}
+	job := s.jobs[0]
+	s.jobs = s.jobs[1:]
+	return job, true, nil
+}
+
+func (s *stubWorkflowJobQueue) Heartbeat(ctx context.Context, jobID uint64, owner string, leaseDuration time.Duration) error {
+	if s.heartbeat == nil {
+		return nil
+	}
+	return s.heartbeat(jobID)
+}
+
+func (s *stubWorkflowJobQueue) Finish(ctx context.Context, jobID uint64, owner string, runErr error) error {
+	if s.finished != nil {
+		s.finished <- workflowJobFinishCall{jobID: jobID, owner: owner, runErr: runErr}
+	}
+	return nil
+}
+
+func (s *stubWorkflowJobQueue) Requeue(ctx context.Context, jobID uint64, owner string) error {
+	if s.requeued != nil {
+		s.requeued <- jobID
+	}
+	return nil
+}
+
+func (s *stubWorkflowJobQueue) RecoverOrphanedHistories(ctx context.Context) (int, error) {
+	return s.recovered, nil
+}
+
+type stubWorkflowHistoryStore struct {
+	fail            func(historyID uint64, currentStage string, errorMessage string) error
+	cancelRequested func(historyID uint64) (bool, error)
+}
+
+func (s *stubWorkflowHistoryStore) Fail(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time, errorMessage string) error {
+	return s.fail(historyID, currentStage, errorMessage)
+}
+
+func (s *stubWorkflowHistoryStore) IsCancelRequested(ctx context.Context, historyID uint64) (bool, error) {
+	if s.cancelRequested == nil {
+		return false, nil
+	}
+	return s.cancelRequested(historyID)
+}
+
+func newWorkflowJobWorkerTestConfig() WorkflowJobWorkerConfig {
+	return WorkflowJobWorkerConfig{
+		Concurrency:         1,
+		PollInterval:        5 * time.Millisecond,
+		LeaseDuration:       300 * time.Millisecond,
+		HeartbeatInterval:   10 * time.Millisecond,
+		CancelCheckInterval: 10 * time.Millisecond,
+	}
+}
+
+func claimedWorkflowJobFixture(jobID uint64) ClaimedWorkflowJob {
+	return ClaimedWorkflowJob{
+		JobID:        jobID,
+		RequestID:    "req-123",
+		AttemptCount: 1,
+		Job: manualapp.DispatchJob{
+			HistoryID:    44,
+			WorkflowID:   "wf-123",
+			UserID:       9,
+			ConnectionID: 21,
+			Condition: manualapp.FetchCondition{
+				LabelName: "billing",
+				Since:     time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+				Until:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func runWorkflowJobWorker(t *testing.T, worker *WorkflowJobWorker) (context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.Run(ctx)
+	}()
+	return cancel, done
+}
+
+func TestWorkflowJobWorker_Run_ExecutesClaimedJobAndFinishes(t *testing.T) {
+	t.Parallel()
+
+	queue := &stubWorkflowJobQueue{
+		jobs:     []ClaimedWorkflowJob{claimedWorkflowJobFixture(7)},
+		finished: make(chan workflowJobFinishCall, 1),
+	}
+	runErr := errors.New("analysis failed")
+	worker := NewWorkflowJobWorker(queue, &stubWorkflowRunner{
+		execute: func(ctx context.Context, job manualapp.DispatchJob) (manualapp.Result, error) {
+			if job.HistoryID != 44 || job.ConnectionID != 21 {
+				t.Errorf("unexpected dispatch job: %+v", job)
+			}
+			requestID, ok := logger.RequestIDFromContext(ctx)
+			if !ok || requestID != "req-123" {
+				t.Errorf("expected request id to be propagated, got %q %v", requestID, ok)
+			}
+			jobID, ok := logger.JobIDFromContext(ctx)
+			if !ok || jobID != "wf-123" {
+				t.Errorf("expected job id to be propagated, got %q %v", jobID, ok)
+			}
+			return manualapp.Result{}, runErr
+		},
+	}, &stubWorkflowHistoryStore{
+		fail: func(historyID uint64, currentStage string, errorMessage string) error {
+			t.Errorf("Fail must not be called for a runnable job")
+			return nil
+		},
+	}, timewrapper.NewClock(), newWorkflowJobWorkerTestConfig(), logger.NewNop())
+
+	cancel, done := runWorkflowJobWorker(t, worker)
+	defer cancel()
+
+	select {
+	case call := <-queue.finished:
+		if call.jobID != 7 {
+			t.Fatalf("unexpected finished job id: %d", call.jobID)
+		}
+		if call.owner != worker.owner {
+			t.Fatalf("expected owner %q, got %q", worker.owner, call.owner)
+		}
+		if !errors.Is(call.runErr, runErr) {
+			t.Fatalf("expected run error to be recorded, got %v", call.runErr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not finished")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("worker did not stop")
+	}
+}
+
+func TestWorkflowJobWorker_Run_FailsHistoryAndFinishesJobWhenRunnerPanics(t *testing.T) {
+	t.Parallel()
+
+	claimed := claimedWorkflowJobFixture(7)
+	claimed.CurrentStage = "fetch"
+	queue := &stubWorkflowJobQueue{
+		jobs:     []ClaimedWorkflowJob{claimed},
+		finished: make(chan workflowJobFinishCall, 1),
+	}
+	failed := make(chan string, 1)
+	worker := NewWorkflowJobWorker(queue, &stubWorkflowRunner{
+		execute: func(ctx context.Context, job manualapp.DispatchJob) (manualapp.Result, error) {
+			panic("nil vendor")
+		},
+	}, &stubWorkflowHistoryStore{
+		fail: func(historyID uint64, currentStage string, errorMessage string) error {
+			if historyID != 44 {
+				t.Errorf("unexpected history id: %d", historyID)
+			}
+			if errorMessage != "panic: nil vendor" {
+				t.Errorf("unexpected error message: %q", errorMessage)
+			}
+			failed <- currentStage
+			return nil
+		},
+	}, timewrapper.NewClock(), newWorkflowJobWorkerTestConfig(), logger.NewNop())
+
+	cancel, done := runWorkflowJobWorker(t, worker)
+	defer cancel()
+
+	select {
+	case call := <-queue.finished:
+		if call.jobID != 7 {
+			t.Fatalf("unexpected finished job id: %d", call.jobID)
+		}
+		if call.owner != worker.owner {
+			t.Fatalf("expected owner %q, got %q", worker.owner, call.owner)
+		}
+		if call.runErr == nil || call.runErr.Error() != "panic: nil vendor" {
+			t.Fatalf("expected panic to be recorded, got %v", call.runErr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not finished")
+	}
+	// The history is failed before the job is finished.
+	select {
+	case stage := <-failed:
+		if stage != "fetch" {
+			t.Fatalf("expected stage %q, got %q", "fetch", stage)
+		}
+	default:
+		t.Fatal("history was not failed")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("worker did not stop")
+	}
+}
+
+func TestWorkflowJobWorker_Run_FailsExhaustedJobWithLastStage(t *testing.T) {
+	t.Parallel()
+
+	claimed := claimedWorkflowJobFixture(8)
+	claimed.Exhausted = true
+	claimed.AttemptCount = 3
+	claimed.CurrentStage = "analysis"
+
+	failed := make(chan string, 1)
+	queue := &stubWorkflowJobQueue{jobs: []ClaimedWorkflowJob{claimed}}
+	worker := NewWorkflowJobWorker(queue, &stubWorkflowRunner{
+		execute: func(ctx context.Context, job manualapp.DispatchJob) (manualapp.Result, error) {
+			t.Errorf("exhausted job must not be executed")
+			return manualapp.Result{}, nil
+		},
+	}, &stubWorkflowHistoryStore{
+		fail: func(historyID uint64, currentStage string, errorMessage string) error {
+			if historyID != 44 {
+				t.Errorf("unexpected history id: %d", historyID)
+			}
+			if errorMessage != workflowJobAbandonedMessage {
+				t.Errorf("unexpected error message: %q", errorMessage)
+			}
+			failed <- currentStage
+			return nil
+		},
+	}, timewrapper.NewClock(), newWorkflowJobWorkerTestConfig(), logger.NewNop())
+
+	cancel, _ := runWorkflowJobWorker(t, worker)
+	defer cancel()
+
+	select {
+	case stage := <-failed:
+		if stage != "analysis" {
+			t.Fatalf("expected stage %q, got %q", "analysis", stage)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("exhausted job was not failed")
+	}
+}
+
+func TestWorkflowJobWorker_Run_CancelsJobWhenLeaseIsLost(t *testing.T) {
+	t.Parallel()
+
+	queue := &stubWorkflowJobQueue{
+		jobs:     []ClaimedWorkflowJob{claimedWorkflowJobFixture(9)},
+		finished: make(chan workflowJobFinishCall, 1),
+		heartbeat: func(jobID uint64) error {
+			return ErrWorkflowJobLeaseLost
+		},
+	}
+	worker := NewWorkflowJobWorker(queue, &stubWorkflowRunner{
+		execute: func(ctx context.Context, job manualapp.DispatchJob) (manualapp.Result, error) {
+			select {
+			case <-ctx.Done():
+				return manualapp.Result{}, ctx.Err()
+			case <-time.After(2 * time.Second):
+				t.Errorf("job context was not cancelled after lease loss")
+				return manualapp.Result{}, nil
+			}
+		},
+	}, &stubWorkflowHistoryStore{
+		fail: func(historyID uint64, currentStage string, errorMessage string) error {
+			return nil
+		},
+	}, timewrapper.NewClock(), newWorkflowJobWorkerTestConfig(), logger.NewNop())
+
+	cancel, _ := runWorkflowJobWorker(t, worker)
+	defer cancel()
+
+	select {
+	case call := <-queue.finished:
+		if !errors.Is(call.runErr, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", call.runErr)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("job was not finished")
+	}
+}
+
+func TestWorkflowJobWorker_Run_CancelsJobWithCauseWhenUserCancels(t *testing.T) {
+	t.Parallel()
+
+	queue := &stubWorkflowJobQueue{
+		jobs:     []ClaimedWorkflowJob{claimedWorkflowJobFixture(10)},
+		finished: make(chan workflowJobFinishCall, 1),
+	}
+	worker := NewWorkflowJobWorker(queue, &stubWorkflowRunner{
+		execute: func(ctx context.Context, job manualapp.DispatchJob) (manualapp.Result, error) {
+			select {
+			case <-ctx.Done():
+				if !errors.Is(context.Cause(ctx), manualapp.ErrWorkflowCancelled) {
+					t.Errorf("expected ErrWorkflowCancelled cause, got %v", context.Cause(ctx))
+				}
+				return manualapp.Result{}, manualapp.ErrWorkflowCancelled
+			case <-time.After(2 * time.Second):
+				t.Errorf("job context was not cancelled after cancel request")
+				return manualapp.Result{}, nil
+			}
+		},
+	}, &stubWorkflowHistoryStore{
+		fail: func(historyID uint64, currentStage string, errorMessage string) error {
+			return nil
+		},
+		cancelRequested: func(historyID uint64) (bool, error) {
+			return historyID == 44, nil
+		},
+	}, timewrapper.NewClock(), newWorkflowJobWorkerTestConfig(), logger.NewNop())
+
+	cancel, _ := runWorkflowJobWorker(t, worker)
+	defer cancel()
+
+	select {
+	case call := <-queue.finished:
+		if !errors.Is(call.runErr, manualapp.ErrWorkflowCancelled) {
+			t.Fatalf("expected ErrWorkflowCancelled, got %v", call.runErr)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("job was not finished")
+	}
+}
+
+func TestWorkflowJobWorker_Run_RequeuesJobInterruptedByShutdown(t *testing.T) {
+	t.Parallel()
+
+	queue := &stubWorkflowJobQueue{
+		jobs:     []ClaimedWorkflowJob{claimedWorkflowJobFixture(11)},
+		finished: make(chan workflowJobFinishCall, 1),
+		requeued: make(chan uint64, 1),
+	}
+	started := make(chan struct{})
+	worker := NewWorkflowJobWorker(queue, &stubWorkflowRunner{
+		execute: func(ctx context.Context, job manualapp.DispatchJob) (manualapp.Result, error) {
+			close(started)
+			select {
+			case <-ctx.Done():
+				if !errors.Is(context.Cause(ctx), manualapp.ErrWorkflowInterrupted) {
+					t.Errorf("expected ErrWorkflowInterrupted cause, got %v", context.Cause(ctx))
+				}
+				return manualapp.Result{}, manualapp.ErrWorkflowInterrupted
+			case <-time.After(2 * time.Second):
+				t.Errorf("job context was not cancelled on shutdown")
+				return manualapp.Result{}, nil
+			}
+		},
+	}, &stubWorkflowHistoryStore{
+		fail: func(historyID uint64, currentStage string, errorMessage string) error {
+			t.Errorf("Fail must not be called for an interrupted job")
+			return nil
+		},
+	}, timewrapper.NewClock(), newWorkflowJobWorkerTestConfig(), logger.NewNop())
+
+	cancel, done := runWorkflowJobWorker(t, worker)
+	defer cancel()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not started")
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("worker did not stop")
+	}
+	select {
+	case jobID := <-queue.requeued:
+		if jobID != 11 {
+			t.Fatalf("unexpected requeued job id: %d", jobID)
+		}
+	default:
+		t.Fatal("interrupted job was not requeued")
+	}
+	select {
+	case call := <-queue.finished:
+		t.Fatalf("interrupted job must not be finished: %+v", call)
+	default:
+	}
+}
+
+func TestWorkflowJobWorker_Run_RejectsNilContext(t *testing.T) {
+	t.Parallel()
+
+	worker := NewWorkflowJobWorker(&stubWorkflowJobQueue{}, &stubWorkflowRunner{}, &stubWorkflowHistoryStore{}, nil, WorkflowJobWorkerConfig{}, nil)
+
+	err := worker.Run(nil)
+	if !errors.Is(err, logger.ErrNilContext) {
+		t.Fatalf("expected ErrNilContext, got %v", err)
+	}
+}
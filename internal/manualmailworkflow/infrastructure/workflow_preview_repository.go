@@ -0,0 +1,332 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+type manualMailWorkflowPreviewRecord struct {
+	ID                uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	WorkflowHistoryID uint64    `gorm:"column:workflow_history_id;not null;uniqueIndex:uni_manual_mail_workflow_previews_workflow_history_id"`
+	PayloadJSON       string    `gorm:"column:payload_json;type:json;not null"`
+	CreatedAt         time.Time `gorm:"column:created_at;not null"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;not null"`
+}
+
+func (manualMailWorkflowPreviewRecord) TableName() string {
+	return "manual_mail_workflow_previews"
+}
+
+type billingIdentitySnapshotRecord struct {
+	ID            uint   `gorm:"column:id;primaryKey"`
+	UserID        uint   `gorm:"column:user_id;not null"`
+	VendorID      uint   `gorm:"column:vendor_id;not null"`
+	BillingNumber string `gorm:"column:billing_number;size:255;not null"`
+}
+
+func (billingIdentitySnapshotRecord) TableName() string {
+	return "billings"
+}
+
+// workflowPreviewPayload is the JSON stored in payload_json.
+type workflowPreviewPayload struct {
+	WouldCreateItems []workflowPreviewBillingPayload    `json:"would_create_items"`
+	DuplicateItems   []workflowPreviewDuplicatePayload  `json:"duplicate_items"`
+	UnresolvedItems  []workflowPreviewUnresolvedPayload `json:"unresolved_items"`
+	IneligibleItems  []workflowPreviewIneligiblePayload `json:"ineligible_items"`
+	GeneratedAt      time.Time                          `json:"generated_at"`
+}
+
+type workflowPreviewBillingPayload struct {
+	ExternalMessageID   string     `json:"external_message_id"`
+	VendorID            uint       `json:"vendor_id,omitempty"`
+	VendorName          string     `json:"vendor_name"`
+	VendorWouldRegister bool       `json:"vendor_would_register,omitempty"`
+	MatchedBy           string     `json:"matched_by"`
+	ProductNameDisplay  *string    `json:"product_name_display,omitempty"`
+	BillingNumber       string     `json:"billing_number"`
+	InvoiceNumber       *string    `json:"invoice_number,omitempty"`
+	Amount              float64    `json:"amount"`
+	Currency            string     `json:"currency"`
+	BillingDate         *time.Time `json:"billing_date,omitempty"`
+	PaymentCycle        string     `json:"payment_cycle"`
+}
+
+type workflowPreviewDuplicatePayload struct {
+	workflowPreviewBillingPayload
+	ExistingBillingID uint   `json:"existing_billing_id,omitempty"`
+	ReasonCode        string `json:"reason_code"`
+	Message           string `json:"message"`
+}
+
+type workflowPreviewUnresolvedPayload struct {
+	ExternalMessageID   string `json:"external_message_id"`
+	CandidateVendorName string `json:"candidate_vendor_name,omitempty"`
+	ReasonCode          string `json:"reason_code"`
+	Message             string `json:"message"`
+}
+
+type workflowPreviewIneligiblePayload struct {
+	ExternalMessageID string `json:"external_message_id"`
+	VendorName        string `json:"vendor_name"`
+	ReasonCode        string `json:"reason_code"`
+	Message           string `json:"message"`
+}
+
+// FindExistingBillingIDs looks up billings of the user by (vendor_id, billing_number) without locking or writing.
+func (r *GormWorkflowStatusRepository) FindExistingBillingIDs(
+	ctx context.Context,
+	userID uint,
+	identities []manualapp.BillingIdentity,
+) (map[manualapp.BillingIdentity]uint, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+	found := make(map[manualapp.BillingIdentity]uint, len(identities))
+	if len(identities) == 0 {
+		return found, nil
+	}
+
+	vendorIDs := make([]uint, 0, len(identities))
+	billingNumbers := make([]string, 0, len(identities))
+	for _, identity := range identities {
+		vendorIDs = append(vendorIDs, identity.VendorID)
+		billingNumbers = append(billingNumbers, identity.BillingNumber)
+	}
+
+	// The IN pair is wider than the requested identities, so rows are matched again below.
+	var records []billingIdentitySnapshotRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND vendor_id IN ? AND billing_number IN ?", userID, vendorIDs, billingNumbers).
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "billings", "find_existing_billing_ids", err)
+		return nil, fmt.Errorf("failed to find existing billings: %w", err)
+	}
+
+	requested := make(map[manualapp.BillingIdentity]struct{}, len(identities))
+	for _, identity := range identities {
+		requested[identity] = struct{}{}
+	}
+	for _, record := range records {
+		identity := manualapp.BillingIdentity{VendorID: record.VendorID, BillingNumber: record.BillingNumber}
+		if _, ok := requested[identity]; ok {
+			found[identity] = record.ID
+		}
+	}
+
+	return found, nil
+}
+
+// SavePreview upserts the preview of a dry-run workflow.
+func (r *GormWorkflowStatusRepository) SavePreview(
+	ctx context.Context,
+	historyID uint64,
+	preview manualapp.WorkflowPreview,
+) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	payload, err := json.Marshal(toWorkflowPreviewPayload(preview))
+	if err != nil {
+		return fmt.Errorf("failed to encode workflow preview: %w", err)
+	}
+
+	now := r.clock.Now().UTC()
+	record := manualMailWorkflowPreviewRecord{
+		WorkflowHistoryID: historyID,
+		PayloadJSON:       string(payload),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workflow_history_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"payload_json", "updated_at"}),
+	}).Create(&record).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_previews", "save_preview", err)
+		return fmt.Errorf("failed to save workflow preview: %w", err)
+	}
+
+	return nil
+}
+
+// FindPreview loads the workflow header and its preview, if the dry run has stored one.
+func (r *GormWorkflowStatusRepository) FindPreview(
+	ctx context.Context,
+	userID uint,
+	workflowID string,
+) (manualapp.WorkflowPreviewView, error) {
+	if ctx == nil {
+		return manualapp.WorkflowPreviewView{}, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return manualapp.WorkflowPreviewView{}, fmt.Errorf("gorm db is not configured")
+	}
+
+	var historyRecords []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND workflow_id = ?", userID, strings.TrimSpace(workflowID)).
+		Limit(1).
+		Find(&historyRecords).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_preview_history", err)
+		return manualapp.WorkflowPreviewView{}, fmt.Errorf("failed to find workflow history: %w", err)
+	}
+	if len(historyRecords) == 0 {
+		return manualapp.WorkflowPreviewView{}, manualapp.ErrWorkflowHistoryNotFound
+	}
+	record := historyRecords[0]
+
+	view := manualapp.WorkflowPreviewView{
+		WorkflowID: record.WorkflowID,
+		Status:     record.Status,
+		DryRun:     record.DryRun,
+		FanOut:     record.FanOut,
+	}
+	if !record.DryRun || record.FanOut {
+		return view, nil
+	}
+
+	var previewRecords []manualMailWorkflowPreviewRecord
+	if err := r.db.WithContext(ctx).
+		Where("workflow_history_id = ?", record.ID).
+		Limit(1).
+		Find(&previewRecords).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_previews", "find_preview", err)
+		return manualapp.WorkflowPreviewView{}, fmt.Errorf("failed to find workflow preview: %w", err)
+	}
+	if len(previewRecords) == 0 {
+		return view, nil
+	}
+
+	var payload workflowPreviewPayload
+	if err := json.Unmarshal([]byte(previewRecords[0].PayloadJSON), &payload); err != nil {
+		return manualapp.WorkflowPreviewView{}, fmt.Errorf("failed to decode workflow preview: history_id=%d: %w", record.ID, err)
+	}
+	preview := fromWorkflowPreviewPayload(payload)
+	view.Preview = &preview
+
+	return view, nil
+}
+
+func toWorkflowPreviewPayload(preview manualapp.WorkflowPreview) workflowPreviewPayload {
+	payload := workflowPreviewPayload{
+		WouldCreateItems: make([]workflowPreviewBillingPayload, 0, len(preview.WouldCreateItems)),
+		DuplicateItems:   make([]workflowPreviewDuplicatePayload, 0, len(preview.DuplicateItems)),
+		UnresolvedItems:  make([]workflowPreviewUnresolvedPayload, 0, len(preview.UnresolvedItems)),
+		IneligibleItems:  make([]workflowPreviewIneligiblePayload, 0, len(preview.IneligibleItems)),
+		GeneratedAt:      preview.GeneratedAt.UTC(),
+	}
+	for _, item := range preview.WouldCreateItems {
+		payload.WouldCreateItems = append(payload.WouldCreateItems, toWorkflowPreviewBillingPayload(item))
+	}
+	for _, item := range preview.DuplicateItems {
+		payload.DuplicateItems = append(payload.DuplicateItems, workflowPreviewDuplicatePayload{
+			workflowPreviewBillingPayload: toWorkflowPreviewBillingPayload(item.PreviewBillingItem),
+			ExistingBillingID:             item.ExistingBillingID,
+			ReasonCode:                    item.ReasonCode,
+			Message:                       item.Message,
+		})
+	}
+	for _, item := range preview.UnresolvedItems {
+		payload.UnresolvedItems = append(payload.UnresolvedItems, workflowPreviewUnresolvedPayload{
+			ExternalMessageID:   item.ExternalMessageID,
+			CandidateVendorName: item.CandidateVendorName,
+			ReasonCode:          item.ReasonCode,
+			Message:             item.Message,
+		})
+	}
+	for _, item := range preview.IneligibleItems {
+		payload.IneligibleItems = append(payload.IneligibleItems, workflowPreviewIneligiblePayload{
+			ExternalMessageID: item.ExternalMessageID,
+			VendorName:        item.VendorName,
+			ReasonCode:        item.ReasonCode,
+			Message:           item.Message,
+		})
+	}
+	return payload
+}
+
+func toWorkflowPreviewBillingPayload(item manualapp.PreviewBillingItem) workflowPreviewBillingPayload {
+	return workflowPreviewBillingPayload{
+		ExternalMessageID:   item.ExternalMessageID,
+		VendorID:            item.VendorID,
+		VendorName:          item.VendorName,
+		VendorWouldRegister: item.VendorWouldRegister,
+		MatchedBy:           item.MatchedBy,
+		ProductNameDisplay:  cloneOptionalString(item.ProductNameDisplay),
+		BillingNumber:       item.BillingNumber,
+		InvoiceNumber:       cloneOptionalString(item.InvoiceNumber),
+		Amount:              item.Amount,
+		Currency:            item.Currency,
+		BillingDate:         cloneOptionalTime(item.BillingDate),
+		PaymentCycle:        item.PaymentCycle,
+	}
+}
+
+func fromWorkflowPreviewPayload(payload workflowPreviewPayload) manualapp.WorkflowPreview {
+	preview := manualapp.WorkflowPreview{
+		WouldCreateItems: make([]manualapp.PreviewBillingItem, 0, len(payload.WouldCreateItems)),
+		DuplicateItems:   make([]manualapp.PreviewDuplicateItem, 0, len(payload.DuplicateItems)),
+		UnresolvedItems:  make([]manualapp.PreviewUnresolvedItem, 0, len(payload.UnresolvedItems)),
+		IneligibleItems:  make([]manualapp.PreviewIneligibleItem, 0, len(payload.IneligibleItems)),
+		GeneratedAt:      payload.GeneratedAt.UTC(),
+	}
+	for _, item := range payload.WouldCreateItems {
+		preview.WouldCreateItems = append(preview.WouldCreateItems, fromWorkflowPreviewBillingPayload(item))
+	}
+	for _, item := range payload.DuplicateItems {
+		preview.DuplicateItems = append(preview.DuplicateItems, manualapp.PreviewDuplicateItem{
+			PreviewBillingItem: fromWorkflowPreviewBillingPayload(item.workflowPreviewBillingPayload),
+			ExistingBillingID:  item.ExistingBillingID,
+			ReasonCode:         item.ReasonCode,
+			Message:            item.Message,
+		})
+	}
+	for _, item := range payload.UnresolvedItems {
+		preview.UnresolvedItems = append(preview.UnresolvedItems, manualapp.PreviewUnresolvedItem{
+			ExternalMessageID:   item.ExternalMessageID,
+			CandidateVendorName: item.CandidateVendorName,
+			ReasonCode:          item.ReasonCode,
+			Message:             item.Message,
+		})
+	}
+	for _, item := range payload.IneligibleItems {
+		preview.IneligibleItems = append(preview.IneligibleItems, manualapp.PreviewIneligibleItem{
+			ExternalMessageID: item.ExternalMessageID,
+			VendorName:        item.VendorName,
+			ReasonCode:        item.ReasonCode,
+			Message:           item.Message,
+		})
+	}
+	return preview
+}
+
+func fromWorkflowPreviewBillingPayload(item workflowPreviewBillingPayload) manualapp.PreviewBillingItem {
+	return manualapp.PreviewBillingItem{
+		ExternalMessageID:   item.ExternalMessageID,
+		VendorID:            item.VendorID,
+		VendorName:          item.VendorName,
+		VendorWouldRegister: item.VendorWouldRegister,
+		MatchedBy:           item.MatchedBy,
+		ProductNameDisplay:  cloneOptionalString(item.ProductNameDisplay),
+		BillingNumber:       item.BillingNumber,
+		InvoiceNumber:       cloneOptionalString(item.InvoiceNumber),
+		Amount:              item.Amount,
+		Currency:            item.Currency,
+		BillingDate:         cloneOptionalTime(item.BillingDate),
+		PaymentCycle:        item.PaymentCycle,
+	}
+}
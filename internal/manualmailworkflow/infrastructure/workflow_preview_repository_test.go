@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormWorkflowStatusRepository_SaveAndFindPreview(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	queuedAt := time.Date(2026, 3, 25, 14, 0, 0, 0, time.UTC)
+	history := workflowHistoryRecordFixture(10, "wf-dry-run", queuedAt, manualapp.WorkflowStatusRunning)
+	history.DryRun = true
+	require.NoError(t, env.db.WithContext(ctx).Create(&history).Error)
+
+	view, err := env.repo.FindPreview(ctx, 10, "wf-dry-run")
+	require.NoError(t, err)
+	require.True(t, view.DryRun)
+	require.Nil(t, view.Preview)
+
+	billingDate := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	preview := manualapp.WorkflowPreview{
+		WouldCreateItems: []manualapp.PreviewBillingItem{{
+			ExternalMessageID:   "msg-1",
+			VendorName:          "New Vendor",
+			VendorWouldRegister: true,
+			BillingNumber:       "INV-1",
+			Amount:              1200,
+			Currency:            "JPY",
+			BillingDate:         &billingDate,
+			PaymentCycle:        "one_time",
+		}},
+		DuplicateItems: []manualapp.PreviewDuplicateItem{{
+			PreviewBillingItem: manualapp.PreviewBillingItem{ExternalMessageID: "msg-2", VendorID: 9, VendorName: "Acme", BillingNumber: "INV-2"},
+			ExistingBillingID:  42,
+			ReasonCode:         "duplicate_billing",
+			Message:            "重複しています。",
+		}},
+		UnresolvedItems: []manualapp.PreviewUnresolvedItem{{ExternalMessageID: "msg-3", ReasonCode: "vendor_unresolved", Message: "特定できませんでした。"}},
+		IneligibleItems: []manualapp.PreviewIneligibleItem{{ExternalMessageID: "msg-4", VendorName: "Acme", ReasonCode: "amount_empty", Message: "金額がありません。"}},
+		GeneratedAt:     env.nowUTC,
+	}
+	require.NoError(t, env.repo.SavePreview(ctx, history.ID, preview))
+	require.NoError(t, env.repo.SavePreview(ctx, history.ID, preview))
+
+	view, err = env.repo.FindPreview(ctx, 10, "wf-dry-run")
+	require.NoError(t, err)
+	require.NotNil(t, view.Preview)
+	require.Equal(t, preview, *view.Preview)
+
+	_, err = env.repo.FindPreview(ctx, 11, "wf-dry-run")
+	require.ErrorIs(t, err, manualapp.ErrWorkflowHistoryNotFound)
+}
+
+func TestGormWorkflowStatusRepository_FindExistingBillingIDs(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	require.NoError(t, env.db.WithContext(ctx).Create(&[]billingIdentitySnapshotRecord{
+		{ID: 1, UserID: 10, VendorID: 9, BillingNumber: "INV-1"},
+		{ID: 2, UserID: 10, VendorID: 8, BillingNumber: "INV-2"},
+		{ID: 3, UserID: 11, VendorID: 9, BillingNumber: "INV-2"},
+	}).Error)
+
+	found, err := env.repo.FindExistingBillingIDs(ctx, 10, []manualapp.BillingIdentity{
+		{VendorID: 9, BillingNumber: "INV-1"},
+		{VendorID: 9, BillingNumber: "INV-2"},
+		{VendorID: 8, BillingNumber: "INV-1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[manualapp.BillingIdentity]uint{{VendorID: 9, BillingNumber: "INV-1"}: 1}, found)
+}
@@ -0,0 +1,205 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// reanalysisEmailRow is an emails row read for reanalysis, including the mailbox it was fetched from.
+type reanalysisEmailRow struct {
+	ID                uint
+	ExternalMessageID string
+	Subject           string
+	FromRaw           string
+	ToJSON            string
+	BodyDigest        string
+	ReceivedAt        time.Time
+	Provider          string
+	AccountIdentifier string
+}
+
+// reanalysisBillingRow is a billings row joined with its vendor name and line item totals.
+type reanalysisBillingRow struct {
+	ID                 uint
+	EmailID            uint
+	VendorID           uint
+	VendorName         string
+	ProductNameDisplay *string
+	BillingNumber      string
+	InvoiceNumber      *string
+	Amount             *float64
+	Currency           *string
+	BillingDate        *time.Time
+	PaymentCycle       string
+}
+
+// FindReanalysisTargets returns the user's emails matching every given condition, newest first.
+// A vendor condition selects emails that already have a billing of that vendor.
+func (r *GormWorkflowStatusRepository) FindReanalysisTargets(
+	ctx context.Context,
+	query manualapp.ReanalysisTargetQuery,
+) ([]manualapp.ReanalysisTarget, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+
+	tx := r.db.WithContext(ctx).
+		Table("emails").
+		Select("emails.id, emails.external_message_id, emails.subject, emails.from_raw, emails.to_json, emails.body_digest, emails.received_at, emails.provider, emails.account_identifier").
+		Where("emails.user_id = ?", query.UserID)
+	if len(query.EmailIDs) > 0 {
+		tx = tx.Where("emails.id IN ?", query.EmailIDs)
+	}
+	if !query.ReceivedSince.IsZero() {
+		tx = tx.Where("emails.received_at >= ?", query.ReceivedSince.UTC())
+	}
+	if !query.ReceivedUntil.IsZero() {
+		tx = tx.Where("emails.received_at < ?", query.ReceivedUntil.UTC())
+	}
+	if query.VendorID != 0 {
+		tx = tx.Where("EXISTS (SELECT 1 FROM billings WHERE billings.user_id = emails.user_id AND billings.email_id = emails.id AND billings.vendor_id = ?)", query.VendorID)
+	}
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit)
+	}
+
+	var rows []reanalysisEmailRow
+	if err := tx.Order("emails.received_at DESC, emails.id DESC").Scan(&rows).Error; err != nil {
+		r.logDBError(ctx, "emails", "find_reanalysis_targets", err)
+		return nil, fmt.Errorf("failed to find reanalysis targets: %w", err)
+	}
+
+	targets := make([]manualapp.ReanalysisTarget, 0, len(rows))
+	for _, row := range rows {
+		to := []string{}
+		if strings.TrimSpace(row.ToJSON) != "" {
+			if err := json.Unmarshal([]byte(row.ToJSON), &to); err != nil {
+				return nil, fmt.Errorf("failed to decode email recipients: email_id=%d: %w", row.ID, err)
+			}
+		}
+		targets = append(targets, manualapp.ReanalysisTarget{
+			EmailID:           row.ID,
+			ExternalMessageID: row.ExternalMessageID,
+			Subject:           row.Subject,
+			From:              row.FromRaw,
+			To:                to,
+			ReceivedAt:        row.ReceivedAt.UTC(),
+			BodyDigest:        row.BodyDigest,
+			Provider:          row.Provider,
+			AccountIdentifier: row.AccountIdentifier,
+		})
+	}
+	return targets, nil
+}
+
+// FindConnectionID returns the completed mail connection of the mailbox an email was fetched from, or 0 when there is none.
+func (r *GormWorkflowStatusRepository) FindConnectionID(
+	ctx context.Context,
+	userID uint,
+	provider string,
+	accountIdentifier string,
+) (uint, error) {
+	if ctx == nil {
+		return 0, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return 0, fmt.Errorf("gorm db is not configured")
+	}
+
+	var records []emailCredentialSnapshotRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ? AND gmail_address = ? AND o_auth_state IS NULL", userID, provider, accountIdentifier).
+		Order("id ASC").
+		Limit(1).
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "email_credentials", "find_reanalysis_connection", err)
+		return 0, fmt.Errorf("failed to find mail connection: %w", err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	return records[0].ID, nil
+}
+
+// FindBillingsByEmailIDs returns the user's billings created from the given emails.
+// Amount and Currency are set only when the line items use a single currency.
+func (r *GormWorkflowStatusRepository) FindBillingsByEmailIDs(
+	ctx context.Context,
+	userID uint,
+	emailIDs []uint,
+) ([]manualapp.ExistingBilling, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+	if len(emailIDs) == 0 {
+		return []manualapp.ExistingBilling{}, nil
+	}
+
+	var rows []reanalysisBillingRow
+	if err := r.db.WithContext(ctx).
+		Table("billings").
+		Select("billings.id, billings.email_id, billings.vendor_id, COALESCE(vendors.name, '') AS vendor_name, billings.product_name_display, billings.billing_number, billings.invoice_number, line_item_summary.amount, line_item_summary.currency, billings.billing_date, billings.payment_cycle").
+		Joins("LEFT JOIN vendors ON vendors.id = billings.vendor_id").
+		Joins("LEFT JOIN "+reanalysisLineItemSummarySubQuery()+" ON line_item_summary.billing_id = billings.id").
+		Where("billings.user_id = ? AND billings.email_id IN ?", userID, emailIDs).
+		Order("billings.email_id ASC, billings.id ASC").
+		Scan(&rows).Error; err != nil {
+		r.logDBError(ctx, "billings", "find_billings_by_email_ids", err)
+		return nil, fmt.Errorf("failed to find billings by email ids: %w", err)
+	}
+
+	billings := make([]manualapp.ExistingBilling, 0, len(rows))
+	for _, row := range rows {
+		billing := manualapp.ExistingBilling{
+			BillingID:          row.ID,
+			EmailID:            row.EmailID,
+			VendorID:           row.VendorID,
+			VendorName:         row.VendorName,
+			ProductNameDisplay: cloneOptionalString(row.ProductNameDisplay),
+			BillingNumber:      row.BillingNumber,
+			InvoiceNumber:      cloneOptionalString(row.InvoiceNumber),
+			Amount:             row.Amount,
+			PaymentCycle:       row.PaymentCycle,
+		}
+		if row.Currency != nil {
+			billing.Currency = *row.Currency
+		}
+		if row.BillingDate != nil {
+			billingDate := row.BillingDate.UTC()
+			billing.BillingDate = &billingDate
+		}
+		billings = append(billings, billing)
+	}
+	return billings, nil
+}
+
+// reanalysisLineItemSummarySubQuery totals line items per billing the same way the billing list does.
+func reanalysisLineItemSummarySubQuery() string {
+	return `(
+SELECT
+	billing_id,
+	CASE
+		WHEN COUNT(DISTINCT CASE WHEN currency IS NOT NULL AND TRIM(currency) <> '' THEN UPPER(TRIM(currency)) END) = 1
+			THEN SUM(CASE WHEN amount IS NOT NULL THEN amount ELSE 0 END)
+		ELSE NULL
+	END AS amount,
+	CASE
+		WHEN COUNT(DISTINCT CASE WHEN currency IS NOT NULL AND TRIM(currency) <> '' THEN UPPER(TRIM(currency)) END) = 1
+			THEN MAX(CASE WHEN currency IS NOT NULL AND TRIM(currency) <> '' THEN UPPER(TRIM(currency)) END)
+		ELSE NULL
+	END AS currency
+FROM billing_line_items
+GROUP BY billing_id
+) AS line_item_summary`
+}
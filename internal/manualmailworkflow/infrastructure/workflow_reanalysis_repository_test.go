@@ -0,0 +1,164 @@
+package infrastructure
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type reanalysisEmailFixture struct {
+	ID                uint      `gorm:"column:id;primaryKey"`
+	UserID            uint      `gorm:"column:user_id;not null"`
+	Provider          string    `gorm:"column:provider;size:50;not null"`
+	AccountIdentifier string    `gorm:"column:account_identifier;size:255;not null"`
+	ExternalMessageID string    `gorm:"column:external_message_id;size:255;not null"`
+	Subject           string    `gorm:"column:subject;type:text;not null"`
+	FromRaw           string    `gorm:"column:from_raw;type:text;not null"`
+	ToJSON            string    `gorm:"column:to_json;type:json;not null"`
+	BodyDigest        string    `gorm:"column:body_digest;size:64;not null"`
+	ReceivedAt        time.Time `gorm:"column:received_at;not null"`
+}
+
+func (reanalysisEmailFixture) TableName() string {
+	return "emails"
+}
+
+type reanalysisBillingFixture struct {
+	ID                 uint       `gorm:"column:id;primaryKey"`
+	UserID             uint       `gorm:"column:user_id;not null"`
+	VendorID           uint       `gorm:"column:vendor_id;not null"`
+	EmailID            uint       `gorm:"column:email_id;not null"`
+	ProductNameDisplay *string    `gorm:"column:product_name_display;size:255"`
+	BillingNumber      string     `gorm:"column:billing_number;size:255;not null"`
+	InvoiceNumber      *string    `gorm:"column:invoice_number;size:14"`
+	BillingDate        *time.Time `gorm:"column:billing_date"`
+	PaymentCycle       string     `gorm:"column:payment_cycle;size:32;not null"`
+}
+
+func (reanalysisBillingFixture) TableName() string {
+	return "billings"
+}
+
+type reanalysisLineItemFixture struct {
+	ID        uint     `gorm:"column:id;primaryKey"`
+	BillingID uint     `gorm:"column:billing_id;not null"`
+	UserID    uint     `gorm:"column:user_id;not null"`
+	Position  int      `gorm:"column:position;not null"`
+	Amount    *float64 `gorm:"column:amount;type:decimal(18,3)"`
+	Currency  *string  `gorm:"column:currency;type:char(3)"`
+}
+
+func (reanalysisLineItemFixture) TableName() string {
+	return "billing_line_items"
+}
+
+type reanalysisVendorFixture struct {
+	ID     uint   `gorm:"column:id;primaryKey"`
+	UserID uint   `gorm:"column:user_id;not null"`
+	Name   string `gorm:"column:name;size:255;not null"`
+}
+
+func (reanalysisVendorFixture) TableName() string {
+	return "vendors"
+}
+
+func TestGormWorkflowStatusRepository_FindReanalysisTargetsAndBillings(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+	require.NoError(t, env.db.AutoMigrate(
+		&reanalysisEmailFixture{},
+		&reanalysisBillingFixture{},
+		&reanalysisLineItemFixture{},
+		&reanalysisVendorFixture{},
+	))
+
+	ctx := context.Background()
+	receivedAt := time.Date(2026, 10, 10, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, env.db.WithContext(ctx).Create(&[]reanalysisEmailFixture{
+		{ID: 1, UserID: 7, Provider: "gmail", AccountIdentifier: "a@example.com", ExternalMessageID: "msg-1", Subject: "請求書", FromRaw: "billing@example.com", ToJSON: `["a@example.com"]`, BodyDigest: "digest-1", ReceivedAt: receivedAt},
+		{ID: 2, UserID: 7, Provider: "gmail", AccountIdentifier: "a@example.com", ExternalMessageID: "msg-2", ToJSON: `[]`, ReceivedAt: receivedAt.Add(24 * time.Hour)},
+		{ID: 3, UserID: 8, Provider: "gmail", AccountIdentifier: "b@example.com", ExternalMessageID: "msg-3", ToJSON: `[]`, ReceivedAt: receivedAt},
+	}).Error)
+	require.NoError(t, env.db.WithContext(ctx).Create(&reanalysisVendorFixture{ID: 4, UserID: 7, Name: "Acme"}).Error)
+	billingDate := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, env.db.WithContext(ctx).Create(&[]reanalysisBillingFixture{
+		{ID: 20, UserID: 7, VendorID: 4, EmailID: 1, BillingNumber: "INV-1", BillingDate: &billingDate, PaymentCycle: "one_time"},
+		{ID: 21, UserID: 7, VendorID: 4, EmailID: 1, BillingNumber: "INV-2", PaymentCycle: "recurring"},
+	}).Error)
+	jpy, usd := "JPY", "USD"
+	amount1, amount2, amount3 := 1000.0, 500.0, 10.0
+	require.NoError(t, env.db.WithContext(ctx).Create(&[]reanalysisLineItemFixture{
+		{ID: 1, BillingID: 20, UserID: 7, Position: 0, Amount: &amount1, Currency: &jpy},
+		{ID: 2, BillingID: 20, UserID: 7, Position: 1, Amount: &amount2, Currency: &jpy},
+		{ID: 3, BillingID: 21, UserID: 7, Position: 0, Amount: &amount1, Currency: &jpy},
+		{ID: 4, BillingID: 21, UserID: 7, Position: 1, Amount: &amount3, Currency: &usd},
+	}).Error)
+
+	targets, err := env.repo.FindReanalysisTargets(ctx, manualapp.ReanalysisTargetQuery{UserID: 7, ReceivedSince: receivedAt, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, uint(2), targets[0].EmailID)
+	require.Equal(t, manualapp.ReanalysisTarget{
+		EmailID:           1,
+		ExternalMessageID: "msg-1",
+		Subject:           "請求書",
+		From:              "billing@example.com",
+		To:                []string{"a@example.com"},
+		ReceivedAt:        receivedAt,
+		BodyDigest:        "digest-1",
+		Provider:          "gmail",
+		AccountIdentifier: "a@example.com",
+	}, targets[1])
+
+	targets, err = env.repo.FindReanalysisTargets(ctx, manualapp.ReanalysisTargetQuery{UserID: 7, VendorID: 4, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Equal(t, uint(1), targets[0].EmailID)
+
+	targets, err = env.repo.FindReanalysisTargets(ctx, manualapp.ReanalysisTargetQuery{UserID: 7, EmailIDs: []uint{1, 3}, ReceivedUntil: receivedAt, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, targets)
+
+	billings, err := env.repo.FindBillingsByEmailIDs(ctx, 7, []uint{1, 2})
+	require.NoError(t, err)
+	require.Len(t, billings, 2)
+	require.Equal(t, uint(20), billings[0].BillingID)
+	require.Equal(t, "Acme", billings[0].VendorName)
+	require.NotNil(t, billings[0].Amount)
+	require.InDelta(t, 1500.0, *billings[0].Amount, 0.0001)
+	require.Equal(t, "JPY", billings[0].Currency)
+	require.True(t, billings[0].BillingDate.Equal(billingDate))
+	require.Nil(t, billings[1].Amount)
+	require.Empty(t, billings[1].Currency)
+
+	billings, err = env.repo.FindBillingsByEmailIDs(ctx, 8, []uint{1})
+	require.NoError(t, err)
+	require.Empty(t, billings)
+}
+
+func TestGormWorkflowStatusRepository_FindConnectionID(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	pending := "state"
+	require.NoError(t, env.db.WithContext(ctx).Create(&[]emailCredentialSnapshotRecord{
+		{ID: 5, UserID: 7, Type: "gmail", GmailAddress: "a@example.com"},
+		{ID: 6, UserID: 7, Type: "gmail", GmailAddress: "pending@example.com", OAuthState: &pending},
+	}).Error)
+
+	connectionID, err := env.repo.FindConnectionID(ctx, 7, "gmail", "a@example.com")
+	require.NoError(t, err)
+	require.Equal(t, uint(5), connectionID)
+
+	connectionID, err = env.repo.FindConnectionID(ctx, 7, "gmail", "pending@example.com")
+	require.NoError(t, err)
+	require.Zero(t, connectionID)
+}
@@ -0,0 +1,100 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	defaultWorkflowReaperPollInterval = 5 * time.Minute
+	defaultWorkflowReaperStaleAfter   = 3 * time.Hour
+)
+
+// WorkflowReaperConfig controls how often WorkflowReaper runs and when a workflow counts as stale.
+type WorkflowReaperConfig struct {
+	PollInterval time.Duration
+	// StaleAfter is how long a queued or running workflow may go without any update before it is failed.
+	StaleAfter time.Duration
+}
+
+// DefaultWorkflowReaperConfig returns the default reaper configuration.
+func DefaultWorkflowReaperConfig() WorkflowReaperConfig {
+	return WorkflowReaperConfig{
+		PollInterval: defaultWorkflowReaperPollInterval,
+		StaleAfter:   defaultWorkflowReaperStaleAfter,
+	}
+}
+
+// WorkflowReaper periodically fails workflows that stopped making progress, such as runs
+// whose process disappeared without a worker ever reclaiming the job.
+// Every instance may run one; the repository claim keeps a workflow from being reaped twice.
+type WorkflowReaper struct {
+	reaper manualapp.ReapUseCase
+	clock  timewrapper.ClockInterface
+	cfg    WorkflowReaperConfig
+	log    logger.Interface
+}
+
+// NewWorkflowReaper creates an in-process reaper for stale workflows.
+func NewWorkflowReaper(
+	reaper manualapp.ReapUseCase,
+	clock timewrapper.ClockInterface,
+	cfg WorkflowReaperConfig,
+	log logger.Interface,
+) *WorkflowReaper {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	defaults := DefaultWorkflowReaperConfig()
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = defaults.StaleAfter
+	}
+
+	return &WorkflowReaper{
+		reaper: reaper,
+		clock:  clock,
+		cfg:    cfg,
+		log:    log.With(logger.Component("manual_mail_workflow_reaper")),
+	}
+}
+
+// Run reaps stale workflows every poll interval until ctx is cancelled.
+func (r *WorkflowReaper) Run(ctx context.Context) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.reaper == nil {
+		return errors.New("manual mail workflow reap usecase is not configured")
+	}
+
+	r.log.Info("manual_mail_workflow_reaper_started",
+		logger.String("poll_interval", r.cfg.PollInterval.String()),
+		logger.String("stale_after", r.cfg.StaleAfter.String()),
+	)
+
+	for {
+		reaped, err := r.reaper.ReapStale(ctx, r.cfg.StaleAfter)
+		if err != nil && ctx.Err() == nil {
+			r.log.Error("manual_mail_workflow_reap_stale_failed", logger.Err(err))
+		} else if reaped > 0 {
+			r.log.Info("manual_mail_workflow_stale_reaped", logger.Int("reaped_count", reaped))
+		}
+
+		select {
+		case <-ctx.Done():
+			r.log.Info("manual_mail_workflow_reaper_stopping")
+			return nil
+		case <-r.clock.After(r.cfg.PollInterval):
+		}
+	}
+}
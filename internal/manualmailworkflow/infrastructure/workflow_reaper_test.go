@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type stubReapUseCase struct {
+	calls      atomic.Int32
+	staleAfter atomic.Int64
+	err        error
+}
+
+func (s *stubReapUseCase) ReapStale(ctx context.Context, staleAfter time.Duration) (int, error) {
+	s.calls.Add(1)
+	s.staleAfter.Store(int64(staleAfter))
+	return 1, s.err
+}
+
+func TestWorkflowReaper_Run_ReapsUntilCancelled(t *testing.T) {
+	t.Parallel()
+
+	reapUseCase := &stubReapUseCase{err: errors.New("temporary")}
+	reaper := NewWorkflowReaper(reapUseCase, nil, WorkflowReaperConfig{PollInterval: 5 * time.Millisecond, StaleAfter: time.Hour}, logger.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- reaper.Run(ctx)
+	}()
+
+	deadline := time.After(time.Second)
+	for reapUseCase.calls.Load() < 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("reaper did not keep polling after an error: calls=%d", reapUseCase.calls.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reaper did not stop after cancel")
+	}
+	if got := time.Duration(reapUseCase.staleAfter.Load()); got != time.Hour {
+		t.Fatalf("expected stale threshold %s, got %s", time.Hour, got)
+	}
+}
+
+func TestWorkflowReaper_Run_RequiresReapUseCase(t *testing.T) {
+	t.Parallel()
+
+	reaper := NewWorkflowReaper(nil, nil, WorkflowReaperConfig{}, nil)
+	if err := reaper.Run(context.Background()); err == nil {
+		t.Fatal("expected an error without reap usecase")
+	}
+}
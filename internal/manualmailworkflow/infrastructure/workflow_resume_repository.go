@@ -0,0 +1,386 @@
+package infrastructure
+
+import (
+	commondomain "business/internal/common/domain"
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type manualMailWorkflowStageHandoffRecord struct {
+	ID                uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	WorkflowHistoryID uint64    `gorm:"column:workflow_history_id;not null;uniqueIndex:uni_manual_mail_workflow_stage_handoffs_history_stage,priority:1"`
+	Stage             string    `gorm:"column:stage;size:32;not null;uniqueIndex:uni_manual_mail_workflow_stage_handoffs_history_stage,priority:2"`
+	PayloadJSON       string    `gorm:"column:payload_json;type:json;not null"`
+	CreatedAt         time.Time `gorm:"column:created_at;not null"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;not null"`
+}
+
+func (manualMailWorkflowStageHandoffRecord) TableName() string {
+	return "manual_mail_workflow_stage_handoffs"
+}
+
+// stageHandoffPayload is the JSON stored in payload_json. Stage bodies are referenced by ID;
+// only the values that are not persisted elsewhere (line items and billing fields) are stored as is.
+type stageHandoffPayload struct {
+	EmailIDs      []uint                        `json:"email_ids,omitempty"`
+	ParsedEmails  []stageHandoffItemPayload     `json:"parsed_emails,omitempty"`
+	ResolvedItems []stageHandoffItemPayload     `json:"resolved_items,omitempty"`
+	EligibleItems []stageHandoffEligiblePayload `json:"eligible_items,omitempty"`
+	Failures      []stageHandoffFailurePayload  `json:"failures,omitempty"`
+}
+
+type stageHandoffItemPayload struct {
+	ParsedEmailID     uint                               `json:"parsed_email_id"`
+	EmailID           uint                               `json:"email_id"`
+	ExternalMessageID string                             `json:"external_message_id"`
+	VendorID          uint                               `json:"vendor_id,omitempty"`
+	VendorName        string                             `json:"vendor_name,omitempty"`
+	MatchedBy         string                             `json:"matched_by,omitempty"`
+	LineItems         []commondomain.ParsedEmailLineItem `json:"line_items,omitempty"`
+}
+
+type stageHandoffEligiblePayload struct {
+	ParsedEmailID      uint                          `json:"parsed_email_id"`
+	EmailID            uint                          `json:"email_id"`
+	ExternalMessageID  string                        `json:"external_message_id"`
+	VendorID           uint                          `json:"vendor_id"`
+	VendorName         string                        `json:"vendor_name"`
+	MatchedBy          string                        `json:"matched_by"`
+	ProductNameDisplay *string                       `json:"product_name_display,omitempty"`
+	BillingNumber      string                        `json:"billing_number"`
+	InvoiceNumber      *string                       `json:"invoice_number,omitempty"`
+	Amount             float64                       `json:"amount"`
+	BillingDate        *time.Time                    `json:"billing_date,omitempty"`
+	Currency           string                        `json:"currency"`
+	PaymentCycle       string                        `json:"payment_cycle"`
+	LineItems          []stageHandoffLineItemPayload `json:"line_items,omitempty"`
+}
+
+type stageHandoffLineItemPayload struct {
+	ProductNameRaw     *string  `json:"product_name_raw,omitempty"`
+	ProductNameDisplay *string  `json:"product_name_display,omitempty"`
+	Amount             *float64 `json:"amount,omitempty"`
+	Currency           *string  `json:"currency,omitempty"`
+}
+
+type stageHandoffFailurePayload struct {
+	Stage             string `json:"stage"`
+	ExternalMessageID string `json:"external_message_id"`
+	Code              string `json:"code"`
+	Message           string `json:"message"`
+}
+
+// saveStageHandoff upserts the handoff of one finished stage inside the SaveStageProgress transaction.
+func saveStageHandoff(tx *gorm.DB, historyID uint64, handoff manualapp.StageHandoff, now time.Time) error {
+	payload, err := json.Marshal(toStageHandoffPayload(handoff))
+	if err != nil {
+		return fmt.Errorf("failed to encode stage handoff: %w", err)
+	}
+
+	record := manualMailWorkflowStageHandoffRecord{
+		WorkflowHistoryID: historyID,
+		Stage:             strings.TrimSpace(handoff.Stage),
+		PayloadJSON:       string(payload),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workflow_history_id"}, {Name: "stage"}},
+		DoUpdates: clause.AssignmentColumns([]string{"payload_json", "updated_at"}),
+	}).Create(&record).Error
+}
+
+// FindResumeSource loads the workflow header, the connection of its queued job and all stage handoffs.
+func (r *GormWorkflowStatusRepository) FindResumeSource(
+	ctx context.Context,
+	userID uint,
+	workflowID string,
+) (manualapp.WorkflowResumeSource, error) {
+	if ctx == nil {
+		return manualapp.WorkflowResumeSource{}, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return manualapp.WorkflowResumeSource{}, fmt.Errorf("gorm db is not configured")
+	}
+
+	var historyRecords []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND workflow_id = ?", userID, strings.TrimSpace(workflowID)).
+		Limit(1).
+		Find(&historyRecords).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_resume_source", err)
+		return manualapp.WorkflowResumeSource{}, fmt.Errorf("failed to find workflow history: %w", err)
+	}
+	if len(historyRecords) == 0 {
+		return manualapp.WorkflowResumeSource{}, manualapp.ErrWorkflowHistoryNotFound
+	}
+	record := historyRecords[0]
+
+	var jobRecords []manualMailWorkflowJobRecord
+	if err := r.db.WithContext(ctx).
+		Where("workflow_history_id = ?", record.ID).
+		Limit(1).
+		Find(&jobRecords).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_jobs", "find_resume_job", err)
+		return manualapp.WorkflowResumeSource{}, fmt.Errorf("failed to find workflow job: %w", err)
+	}
+
+	var handoffRecords []manualMailWorkflowStageHandoffRecord
+	if err := r.db.WithContext(ctx).
+		Where("workflow_history_id = ?", record.ID).
+		Order("id ASC").
+		Find(&handoffRecords).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_stage_handoffs", "find_resume_handoffs", err)
+		return manualapp.WorkflowResumeSource{}, fmt.Errorf("failed to find workflow stage handoffs: %w", err)
+	}
+
+	source := manualapp.WorkflowResumeSource{
+		HistoryID:         record.ID,
+		WorkflowID:        record.WorkflowID,
+		RetryOfWorkflowID: stringValue(record.RetryOfWorkflowID),
+		FanOut:            record.FanOut,
+		Status:            record.Status,
+		CurrentStage:      cloneOptionalString(record.CurrentStage),
+		Condition: manualapp.FetchCondition{
+			LabelName: record.LabelName,
+			Since:     record.SinceAt.UTC(),
+			Until:     record.UntilAt.UTC(),
+			Filter:    record.FetchFilter.toFetchFilter(),
+		},
+		Stages:   workflowStageCounts(record),
+		Handoffs: make([]manualapp.StageHandoff, 0, len(handoffRecords)),
+	}
+	if len(jobRecords) > 0 {
+		source.ConnectionID = jobRecords[0].ConnectionID
+	}
+	for _, handoffRecord := range handoffRecords {
+		var payload stageHandoffPayload
+		if err := json.Unmarshal([]byte(handoffRecord.PayloadJSON), &payload); err != nil {
+			return manualapp.WorkflowResumeSource{}, fmt.Errorf("failed to decode stage handoff: history_id=%d stage=%s: %w", record.ID, handoffRecord.Stage, err)
+		}
+		source.Handoffs = append(source.Handoffs, fromStageHandoffPayload(handoffRecord.Stage, payload))
+	}
+
+	return source, nil
+}
+
+// FindParsedEmailsByIDs loads the ParsedEmail rows with their source email in parsed_email_id order.
+// Line items are not persisted, so the caller restores them from the stage handoff.
+func (r *GormWorkflowStatusRepository) FindParsedEmailsByIDs(
+	ctx context.Context,
+	userID uint,
+	parsedEmailIDs []uint,
+) ([]manualapp.ParsedEmail, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+	if len(parsedEmailIDs) == 0 {
+		return []manualapp.ParsedEmail{}, nil
+	}
+
+	var parsedRecords []parsedEmailSnapshotRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND id IN ?", userID, parsedEmailIDs).
+		Order("id ASC").
+		Find(&parsedRecords).Error; err != nil {
+		r.logDBError(ctx, "parsed_emails", "find_resume_parsed_emails", err)
+		return nil, fmt.Errorf("failed to find parsed emails: %w", err)
+	}
+	if len(parsedRecords) == 0 {
+		return []manualapp.ParsedEmail{}, nil
+	}
+
+	emailIDs := make([]uint, 0, len(parsedRecords))
+	for _, record := range parsedRecords {
+		emailIDs = append(emailIDs, record.EmailID)
+	}
+
+	var emailRecords []emailSnapshotRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND id IN ?", userID, emailIDs).
+		Find(&emailRecords).Error; err != nil {
+		r.logDBError(ctx, "emails", "find_resume_emails", err)
+		return nil, fmt.Errorf("failed to find emails: %w", err)
+	}
+	emailByID := make(map[uint]emailSnapshotRecord, len(emailRecords))
+	for _, email := range emailRecords {
+		emailByID[email.ID] = email
+	}
+
+	parsedEmails := make([]manualapp.ParsedEmail, 0, len(parsedRecords))
+	for _, record := range parsedRecords {
+		email, ok := emailByID[record.EmailID]
+		if !ok {
+			continue
+		}
+		parsedEmail, err := toWorkflowParsedEmail(email, record)
+		if err != nil {
+			return nil, err
+		}
+		parsedEmails = append(parsedEmails, parsedEmail)
+	}
+
+	return parsedEmails, nil
+}
+
+// Reopen puts a failed history back to queued so that the worker resumes it under the same workflow_id.
+// current_stage and the stage counts are kept because the resumed run continues after the saved stages.
+func (r *GormWorkflowStatusRepository) Reopen(ctx context.Context, historyID uint64) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	tx := r.db.WithContext(ctx).
+		Model(&manualMailWorkflowHistoryRecord{}).
+		Where("id = ? AND status = ?", historyID, manualapp.WorkflowStatusFailed).
+		Updates(map[string]interface{}{
+			"status":              manualapp.WorkflowStatusQueued,
+			"finished_at":         nil,
+			"cancel_requested_at": nil,
+			"error_message":       nil,
+			"updated_at":          r.clock.Now().UTC(),
+		})
+	if tx.Error != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "reopen", tx.Error)
+		return fmt.Errorf("failed to reopen workflow history: %w", tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return fmt.Errorf("%w: history is no longer failed", manualapp.ErrWorkflowNotResumable)
+	}
+	r.refreshFanOutParentOf(ctx, historyID)
+
+	return nil
+}
+
+func toStageHandoffPayload(handoff manualapp.StageHandoff) stageHandoffPayload {
+	payload := stageHandoffPayload{
+		EmailIDs:      append([]uint(nil), handoff.EmailIDs...),
+		ParsedEmails:  toStageHandoffItemPayloads(handoff.ParsedEmails),
+		ResolvedItems: toStageHandoffItemPayloads(handoff.ResolvedItems),
+	}
+	for _, item := range handoff.EligibleItems {
+		eligible := stageHandoffEligiblePayload{
+			ParsedEmailID:      item.ParsedEmailID,
+			EmailID:            item.EmailID,
+			ExternalMessageID:  item.ExternalMessageID,
+			VendorID:           item.VendorID,
+			VendorName:         item.VendorName,
+			MatchedBy:          item.MatchedBy,
+			ProductNameDisplay: cloneOptionalString(item.ProductNameDisplay),
+			BillingNumber:      item.BillingNumber,
+			InvoiceNumber:      cloneOptionalString(item.InvoiceNumber),
+			Amount:             item.Amount,
+			BillingDate:        cloneOptionalTime(item.BillingDate),
+			Currency:           item.Currency,
+			PaymentCycle:       item.PaymentCycle,
+		}
+		for _, lineItem := range item.LineItems {
+			eligible.LineItems = append(eligible.LineItems, stageHandoffLineItemPayload{
+				ProductNameRaw:     cloneOptionalString(lineItem.ProductNameRaw),
+				ProductNameDisplay: cloneOptionalString(lineItem.ProductNameDisplay),
+				Amount:             cloneOptionalFloat64(lineItem.Amount),
+				Currency:           cloneOptionalString(lineItem.Currency),
+			})
+		}
+		payload.EligibleItems = append(payload.EligibleItems, eligible)
+	}
+	for _, failure := range handoff.Failures {
+		payload.Failures = append(payload.Failures, stageHandoffFailurePayload{
+			Stage:             failure.Stage,
+			ExternalMessageID: failure.ExternalMessageID,
+			Code:              failure.Code,
+			Message:           failure.Message,
+		})
+	}
+	return payload
+}
+
+func fromStageHandoffPayload(stage string, payload stageHandoffPayload) manualapp.StageHandoff {
+	handoff := manualapp.StageHandoff{
+		Stage:         stage,
+		EmailIDs:      append([]uint(nil), payload.EmailIDs...),
+		ParsedEmails:  fromStageHandoffItemPayloads(payload.ParsedEmails),
+		ResolvedItems: fromStageHandoffItemPayloads(payload.ResolvedItems),
+	}
+	for _, eligible := range payload.EligibleItems {
+		item := manualapp.EligibleItem{
+			ParsedEmailID:      eligible.ParsedEmailID,
+			EmailID:            eligible.EmailID,
+			ExternalMessageID:  eligible.ExternalMessageID,
+			VendorID:           eligible.VendorID,
+			VendorName:         eligible.VendorName,
+			MatchedBy:          eligible.MatchedBy,
+			ProductNameDisplay: cloneOptionalString(eligible.ProductNameDisplay),
+			BillingNumber:      eligible.BillingNumber,
+			InvoiceNumber:      cloneOptionalString(eligible.InvoiceNumber),
+			Amount:             eligible.Amount,
+			BillingDate:        cloneOptionalTime(eligible.BillingDate),
+			Currency:           eligible.Currency,
+			PaymentCycle:       eligible.PaymentCycle,
+		}
+		for _, lineItem := range eligible.LineItems {
+			item.LineItems = append(item.LineItems, manualapp.EligibleLineItem{
+				ProductNameRaw:     cloneOptionalString(lineItem.ProductNameRaw),
+				ProductNameDisplay: cloneOptionalString(lineItem.ProductNameDisplay),
+				Amount:             cloneOptionalFloat64(lineItem.Amount),
+				Currency:           cloneOptionalString(lineItem.Currency),
+			})
+		}
+		handoff.EligibleItems = append(handoff.EligibleItems, item)
+	}
+	for _, failure := range payload.Failures {
+		handoff.Failures = append(handoff.Failures, manualapp.HandoffFailure{
+			Stage:             failure.Stage,
+			ExternalMessageID: failure.ExternalMessageID,
+			Code:              failure.Code,
+			Message:           failure.Message,
+		})
+	}
+	return handoff
+}
+
+func toStageHandoffItemPayloads(items []manualapp.HandoffItem) []stageHandoffItemPayload {
+	payloads := make([]stageHandoffItemPayload, 0, len(items))
+	for _, item := range items {
+		payloads = append(payloads, stageHandoffItemPayload{
+			ParsedEmailID:     item.ParsedEmailID,
+			EmailID:           item.EmailID,
+			ExternalMessageID: item.ExternalMessageID,
+			VendorID:          item.VendorID,
+			VendorName:        item.VendorName,
+			MatchedBy:         item.MatchedBy,
+			LineItems:         append([]commondomain.ParsedEmailLineItem(nil), item.LineItems...),
+		})
+	}
+	return payloads
+}
+
+func fromStageHandoffItemPayloads(payloads []stageHandoffItemPayload) []manualapp.HandoffItem {
+	items := make([]manualapp.HandoffItem, 0, len(payloads))
+	for _, payload := range payloads {
+		items = append(items, manualapp.HandoffItem{
+			ParsedEmailID:     payload.ParsedEmailID,
+			EmailID:           payload.EmailID,
+			ExternalMessageID: payload.ExternalMessageID,
+			VendorID:          payload.VendorID,
+			VendorName:        payload.VendorName,
+			MatchedBy:         payload.MatchedBy,
+			LineItems:         append([]commondomain.ParsedEmailLineItem(nil), payload.LineItems...),
+		})
+	}
+	return items
+}
@@ -0,0 +1,157 @@
+package infrastructure
+
+import (
+	commondomain "business/internal/common/domain"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormWorkflowStatusRepository_SaveStageProgressWithHandoffAndFindResumeSource(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	queuedAt := time.Date(2026, 3, 25, 14, 0, 0, 0, time.UTC)
+	history := workflowHistoryRecordFixture(10, "wf-failed", queuedAt, manualapp.WorkflowStatusRunning)
+	require.NoError(t, env.db.WithContext(ctx).Create(&history).Error)
+	job := newWorkflowJobRecord(manualapp.DispatchJob{
+		HistoryID:    history.ID,
+		WorkflowID:   history.WorkflowID,
+		UserID:       10,
+		ConnectionID: 30,
+		Condition:    manualapp.FetchCondition{LabelName: "billing", Since: history.SinceAt, Until: history.UntilAt},
+	}, "", 3, queuedAt)
+	require.NoError(t, env.db.WithContext(ctx).Create(&job).Error)
+
+	billingDate := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, env.repo.SaveStageProgress(ctx, manualapp.StageProgress{
+		HistoryID:    history.ID,
+		Stage:        "fetch",
+		SuccessCount: 2,
+		Handoff:      &manualapp.StageHandoff{Stage: "fetch", EmailIDs: []uint{101, 102}},
+	}))
+	require.NoError(t, env.repo.SaveStageProgress(ctx, manualapp.StageProgress{
+		HistoryID:             history.ID,
+		Stage:                 "billingeligibility",
+		SuccessCount:          1,
+		TechnicalFailureCount: 1,
+		FailureRecords: []manualapp.StageFailureRecord{
+			{Stage: "billingeligibility", ExternalMessageID: stringPtr("msg-2"), ReasonCode: "billing_eligibility_failed", Message: "請求対象の判定に失敗しました。"},
+		},
+		Handoff: &manualapp.StageHandoff{
+			Stage: "billingeligibility",
+			EligibleItems: []manualapp.EligibleItem{{
+				ParsedEmailID:     1,
+				EmailID:           101,
+				ExternalMessageID: "msg-1",
+				VendorID:          9,
+				VendorName:        "Acme",
+				BillingNumber:     "INV-1",
+				Amount:            1200,
+				BillingDate:       &billingDate,
+				Currency:          "JPY",
+				PaymentCycle:      "one_time",
+				LineItems:         []manualapp.EligibleLineItem{{ProductNameDisplay: stringPtr("Pro plan")}},
+			}},
+			Failures: []manualapp.HandoffFailure{{Stage: "billing", ExternalMessageID: "msg-3", Code: "retry_source_missing", Message: "missing"}},
+		},
+	}))
+	require.NoError(t, env.repo.Fail(ctx, history.ID, "billing", queuedAt.Add(time.Minute), "請求情報の作成に失敗しました。"))
+
+	source, err := env.repo.FindResumeSource(ctx, 10, "wf-failed")
+	require.NoError(t, err)
+	require.Equal(t, history.ID, source.HistoryID)
+	require.Equal(t, manualapp.WorkflowStatusFailed, source.Status)
+	require.Equal(t, uint(30), source.ConnectionID)
+	require.Equal(t, 1, source.Stages["billingeligibility"].TechnicalFailureCount)
+	require.Len(t, source.Handoffs, 2)
+	require.Equal(t, []uint{101, 102}, source.Handoffs[0].EmailIDs)
+
+	eligibility := source.Handoffs[1]
+	require.Equal(t, "billingeligibility", eligibility.Stage)
+	require.Len(t, eligibility.EligibleItems, 1)
+	require.Equal(t, "INV-1", eligibility.EligibleItems[0].BillingNumber)
+	require.True(t, eligibility.EligibleItems[0].BillingDate.Equal(billingDate))
+	require.Equal(t, "Pro plan", *eligibility.EligibleItems[0].LineItems[0].ProductNameDisplay)
+	require.Equal(t, "msg-3", eligibility.Failures[0].ExternalMessageID)
+
+	_, err = env.repo.FindResumeSource(ctx, 20, "wf-failed")
+	require.ErrorIs(t, err, manualapp.ErrWorkflowHistoryNotFound)
+
+	require.NoError(t, env.repo.Reopen(ctx, history.ID))
+	var reopened manualMailWorkflowHistoryRecord
+	require.NoError(t, env.db.WithContext(ctx).First(&reopened, history.ID).Error)
+	require.Equal(t, manualapp.WorkflowStatusQueued, reopened.Status)
+	require.Nil(t, reopened.FinishedAt)
+	require.Nil(t, reopened.ErrorMessage)
+	require.Equal(t, "billing", *reopened.CurrentStage)
+	require.Equal(t, 1, reopened.BillingEligibilitySuccessCount)
+
+	require.ErrorIs(t, env.repo.Reopen(ctx, history.ID), manualapp.ErrWorkflowNotResumable)
+}
+
+func TestGormWorkflowStatusRepository_SaveStageProgress_OverwritesHandoffOfSameStage(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	history := workflowHistoryRecordFixture(10, "wf-running", time.Date(2026, 3, 25, 14, 0, 0, 0, time.UTC), manualapp.WorkflowStatusRunning)
+	require.NoError(t, env.db.WithContext(ctx).Create(&history).Error)
+
+	for _, parsedEmailID := range []uint{1, 2} {
+		require.NoError(t, env.repo.SaveStageProgress(ctx, manualapp.StageProgress{
+			HistoryID: history.ID,
+			Stage:     "analysis",
+			Handoff: &manualapp.StageHandoff{
+				Stage:        "analysis",
+				ParsedEmails: []manualapp.HandoffItem{{ParsedEmailID: parsedEmailID, ExternalMessageID: "msg-1"}},
+			},
+		}))
+	}
+
+	var count int64
+	require.NoError(t, env.db.WithContext(ctx).Model(&manualMailWorkflowStageHandoffRecord{}).Where("workflow_history_id = ?", history.ID).Count(&count).Error)
+	require.Equal(t, int64(1), count)
+
+	source, err := env.repo.FindResumeSource(ctx, 10, "wf-running")
+	require.NoError(t, err)
+	require.Zero(t, source.ConnectionID)
+	require.Len(t, source.Handoffs, 1)
+	require.Equal(t, uint(2), source.Handoffs[0].ParsedEmails[0].ParsedEmailID)
+}
+
+func TestGormWorkflowStatusRepository_FindParsedEmailsByIDs(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	emails := []emailSnapshotRecord{
+		{UserID: 10, ExternalMessageID: "msg-1", Subject: "Invoice", FromRaw: "billing@example.com", ToJSON: `["user@example.com"]`, BodyDigest: "digest-1"},
+		{UserID: 20, ExternalMessageID: "msg-2", Subject: "Other user", FromRaw: "other@example.com", ToJSON: `[]`, BodyDigest: "digest-2"},
+	}
+	require.NoError(t, env.db.WithContext(ctx).Create(&emails).Error)
+	extractedAt := time.Date(2026, 3, 25, 13, 0, 0, 0, time.UTC)
+	parsed := []parsedEmailSnapshotRecord{
+		{UserID: 10, EmailID: emails[0].ID, AnalysisRunID: "run-1", Position: 0, BillingNumber: stringPtr("INV-1"), ExtractedAt: extractedAt},
+		{UserID: 20, EmailID: emails[1].ID, AnalysisRunID: "run-2", Position: 0, BillingNumber: stringPtr("INV-2"), ExtractedAt: extractedAt},
+	}
+	require.NoError(t, env.db.WithContext(ctx).Create(&parsed).Error)
+
+	parsedEmails, err := env.repo.FindParsedEmailsByIDs(ctx, 10, []uint{parsed[0].ID, parsed[1].ID, 9999})
+	require.NoError(t, err)
+	require.Len(t, parsedEmails, 1)
+	require.Equal(t, parsed[0].ID, parsedEmails[0].ParsedEmailID)
+	require.Equal(t, "msg-1", parsedEmails[0].ExternalMessageID)
+	require.Equal(t, "INV-1", *parsedEmails[0].Data.BillingNumber)
+	require.Equal(t, []commondomain.ParsedEmailLineItem(nil), parsedEmails[0].Data.LineItems)
+}
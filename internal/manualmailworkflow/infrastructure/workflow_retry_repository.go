@@ -0,0 +1,258 @@
+package infrastructure
+
+import (
+	commondomain "business/internal/common/domain"
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type emailSnapshotRecord struct {
+	ID                uint   `gorm:"column:id;primaryKey"`
+	UserID            uint   `gorm:"column:user_id;not null"`
+	ExternalMessageID string `gorm:"column:external_message_id;size:255;not null"`
+	Subject           string `gorm:"column:subject;type:text;not null"`
+	FromRaw           string `gorm:"column:from_raw;type:text;not null"`
+	ToJSON            string `gorm:"column:to_json;type:json;not null"`
+	BodyDigest        string `gorm:"column:body_digest;size:64;not null"`
+}
+
+func (emailSnapshotRecord) TableName() string {
+	return "emails"
+}
+
+type parsedEmailSnapshotRecord struct {
+	ID                 uint       `gorm:"column:id;primaryKey"`
+	UserID             uint       `gorm:"column:user_id;not null"`
+	EmailID            uint       `gorm:"column:email_id;not null"`
+	AnalysisRunID      string     `gorm:"column:analysis_run_id;type:char(36);not null"`
+	Position           int        `gorm:"column:position;not null"`
+	ProductNameRaw     *string    `gorm:"column:product_name_raw;type:text"`
+	ProductNameDisplay *string    `gorm:"column:product_name_display;size:255"`
+	VendorName         *string    `gorm:"column:vendor_name;type:text"`
+	BillingNumber      *string    `gorm:"column:billing_number;size:255"`
+	InvoiceNumber      *string    `gorm:"column:invoice_number;size:14"`
+	Amount             *float64   `gorm:"column:amount;type:decimal(18,3)"`
+	Currency           *string    `gorm:"column:currency;type:char(3)"`
+	BillingDate        *time.Time `gorm:"column:billing_date"`
+	PaymentCycle       *string    `gorm:"column:payment_cycle;size:32"`
+	ExtractedAt        time.Time  `gorm:"column:extracted_at;not null"`
+}
+
+func (parsedEmailSnapshotRecord) TableName() string {
+	return "parsed_emails"
+}
+
+// FindRetrySource loads the original workflow header and all of its failure rows.
+// The connection is resolved from the provider/account snapshot because histories keep no connection_id.
+func (r *GormWorkflowStatusRepository) FindRetrySource(
+	ctx context.Context,
+	userID uint,
+	workflowID string,
+) (manualapp.WorkflowRetrySource, error) {
+	if ctx == nil {
+		return manualapp.WorkflowRetrySource{}, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return manualapp.WorkflowRetrySource{}, fmt.Errorf("gorm db is not configured")
+	}
+
+	var historyRecords []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND workflow_id = ?", userID, strings.TrimSpace(workflowID)).
+		Limit(1).
+		Find(&historyRecords).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_retry_source", err)
+		return manualapp.WorkflowRetrySource{}, fmt.Errorf("failed to find workflow history: %w", err)
+	}
+	if len(historyRecords) == 0 {
+		return manualapp.WorkflowRetrySource{}, manualapp.ErrWorkflowHistoryNotFound
+	}
+	record := historyRecords[0]
+
+	var failureRecords []manualMailWorkflowStageFailureRecord
+	if err := r.db.WithContext(ctx).
+		Where("workflow_history_id = ?", record.ID).
+		Order("created_at ASC").
+		Order("stage ASC").
+		Order("external_message_id ASC").
+		Find(&failureRecords).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_stage_failures", "find_retry_failures", err)
+		return manualapp.WorkflowRetrySource{}, fmt.Errorf("failed to list workflow stage failures: %w", err)
+	}
+
+	var credentials []emailCredentialSnapshotRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND o_auth_state IS NULL", userID).
+		Find(&credentials).Error; err != nil {
+		r.logDBError(ctx, "email_credentials", "find_retry_connection", err)
+		return manualapp.WorkflowRetrySource{}, fmt.Errorf("failed to find connection snapshots: %w", err)
+	}
+
+	source := manualapp.WorkflowRetrySource{
+		WorkflowID: record.WorkflowID,
+		Status:     record.Status,
+		DryRun:     record.DryRun,
+		FanOut:     record.FanOut,
+		Condition: manualapp.FetchCondition{
+			LabelName: record.LabelName,
+			Since:     record.SinceAt.UTC(),
+			Until:     record.UntilAt.UTC(),
+			Filter:    record.FetchFilter.toFetchFilter(),
+		},
+		Failures: make([]manualapp.WorkflowStageFailureItem, 0, len(failureRecords)),
+	}
+	historyKey := connectionSnapshotKey(record.UserID, record.Provider, record.AccountIdentifier)
+	for _, credential := range credentials {
+		if connectionSnapshotKey(credential.UserID, credential.Type, credential.GmailAddress) == historyKey {
+			source.ConnectionID = credential.ID
+			break
+		}
+	}
+	for _, failure := range failureRecords {
+		source.Failures = append(source.Failures, manualapp.WorkflowStageFailureItem{
+			Stage:             failure.Stage,
+			ExternalMessageID: cloneOptionalString(failure.ExternalMessageID),
+			ReasonCode:        failure.ReasonCode,
+			Message:           failure.Message,
+			CreatedAt:         failure.CreatedAt.UTC(),
+		})
+	}
+
+	return source, nil
+}
+
+// FindParsedEmails loads the ParsedEmail rows of the latest analysis run for each message.
+// Line items are not persisted, so the returned ParsedEmail data has no LineItems.
+func (r *GormWorkflowStatusRepository) FindParsedEmails(
+	ctx context.Context,
+	userID uint,
+	externalMessageIDs []string,
+) ([]manualapp.ParsedEmail, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+	if len(externalMessageIDs) == 0 {
+		return []manualapp.ParsedEmail{}, nil
+	}
+
+	var emailRecords []emailSnapshotRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND external_message_id IN ?", userID, externalMessageIDs).
+		Order("id ASC").
+		Find(&emailRecords).Error; err != nil {
+		r.logDBError(ctx, "emails", "find_retry_emails", err)
+		return nil, fmt.Errorf("failed to find emails: %w", err)
+	}
+	if len(emailRecords) == 0 {
+		return []manualapp.ParsedEmail{}, nil
+	}
+
+	emailIDs := make([]uint, 0, len(emailRecords))
+	for _, email := range emailRecords {
+		emailIDs = append(emailIDs, email.ID)
+	}
+
+	var parsedRecords []parsedEmailSnapshotRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND email_id IN ?", userID, emailIDs).
+		Order("email_id ASC").
+		Order("id DESC").
+		Find(&parsedRecords).Error; err != nil {
+		r.logDBError(ctx, "parsed_emails", "find_retry_parsed_emails", err)
+		return nil, fmt.Errorf("failed to find parsed emails: %w", err)
+	}
+
+	latestByEmailID := latestParsedEmailRuns(parsedRecords)
+	parsedEmails := make([]manualapp.ParsedEmail, 0, len(parsedRecords))
+	for _, email := range emailRecords {
+		records := latestByEmailID[email.ID]
+		if len(records) == 0 {
+			continue
+		}
+
+		for _, record := range records {
+			parsedEmail, err := toWorkflowParsedEmail(email, record)
+			if err != nil {
+				return nil, err
+			}
+			parsedEmails = append(parsedEmails, parsedEmail)
+		}
+	}
+
+	return parsedEmails, nil
+}
+
+// toWorkflowParsedEmail combines a parsed_emails row with its source email. LineItems are not set.
+func toWorkflowParsedEmail(email emailSnapshotRecord, record parsedEmailSnapshotRecord) (manualapp.ParsedEmail, error) {
+	to := []string{}
+	if strings.TrimSpace(email.ToJSON) != "" {
+		if err := json.Unmarshal([]byte(email.ToJSON), &to); err != nil {
+			return manualapp.ParsedEmail{}, fmt.Errorf("failed to decode email recipients: email_id=%d: %w", email.ID, err)
+		}
+	}
+
+	return manualapp.ParsedEmail{
+		ParsedEmailID:     record.ID,
+		EmailID:           email.ID,
+		ExternalMessageID: email.ExternalMessageID,
+		Subject:           email.Subject,
+		From:              email.FromRaw,
+		To:                to,
+		BodyDigest:        email.BodyDigest,
+		Data: commondomain.ParsedEmail{
+			ProductNameRaw:     cloneOptionalString(record.ProductNameRaw),
+			ProductNameDisplay: cloneOptionalString(record.ProductNameDisplay),
+			VendorName:         cloneOptionalString(record.VendorName),
+			BillingNumber:      cloneOptionalString(record.BillingNumber),
+			InvoiceNumber:      cloneOptionalString(record.InvoiceNumber),
+			Amount:             cloneOptionalFloat64(record.Amount),
+			Currency:           cloneOptionalString(record.Currency),
+			BillingDate:        cloneOptionalTime(record.BillingDate),
+			PaymentCycle:       cloneOptionalString(record.PaymentCycle),
+			ExtractedAt:        record.ExtractedAt.UTC(),
+		},
+	}, nil
+}
+
+// latestParsedEmailRuns keeps only the rows of the newest analysis run per email, ordered by position.
+// records must be ordered by email_id ASC, id DESC.
+func latestParsedEmailRuns(records []parsedEmailSnapshotRecord) map[uint][]parsedEmailSnapshotRecord {
+	latestRunByEmailID := make(map[uint]string, len(records))
+	grouped := make(map[uint][]parsedEmailSnapshotRecord, len(records))
+	for _, record := range records {
+		runID, ok := latestRunByEmailID[record.EmailID]
+		if !ok {
+			runID = record.AnalysisRunID
+			latestRunByEmailID[record.EmailID] = runID
+		}
+		if record.AnalysisRunID != runID {
+			continue
+		}
+		grouped[record.EmailID] = append(grouped[record.EmailID], record)
+	}
+	for emailID := range grouped {
+		rows := grouped[emailID]
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Position < rows[j].Position
+		})
+	}
+
+	return grouped
+}
+
+func cloneOptionalFloat64(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	cloned := *value
+	return &cloned
+}
@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormWorkflowStatusRepository_FindRetrySource(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{
+		ID:           30,
+		UserID:       10,
+		Type:         "gmail",
+		GmailAddress: "Billing@example.com",
+	})
+	queuedAt := time.Date(2026, 3, 25, 14, 0, 0, 0, time.UTC)
+	original := workflowHistoryRecordFixture(10, "wf-original", queuedAt, manualapp.WorkflowStatusPartialSuccess)
+	disconnected := workflowHistoryRecordFixture(10, "wf-disconnected", queuedAt, manualapp.WorkflowStatusFailed)
+	disconnected.AccountIdentifier = "old@example.com"
+	require.NoError(t, env.db.WithContext(ctx).Create(&original).Error)
+	require.NoError(t, env.db.WithContext(ctx).Create(&disconnected).Error)
+	require.NoError(t, env.db.WithContext(ctx).Create(&[]manualMailWorkflowStageFailureRecord{
+		{WorkflowHistoryID: original.ID, Stage: "analysis", ExternalMessageID: stringPtr("msg-2"), ReasonCode: "analysis_failed", Message: "メール解析に失敗しました。", CreatedAt: queuedAt.Add(2 * time.Minute)},
+		{WorkflowHistoryID: original.ID, Stage: "fetch", ExternalMessageID: stringPtr("msg-1"), ReasonCode: "fetch_detail_failed", Message: "メールの取得に失敗しました。", CreatedAt: queuedAt.Add(1 * time.Minute)},
+	}).Error)
+
+	source, err := env.repo.FindRetrySource(ctx, 10, "wf-original")
+	require.NoError(t, err)
+	require.Equal(t, "wf-original", source.WorkflowID)
+	require.Equal(t, manualapp.WorkflowStatusPartialSuccess, source.Status)
+	require.Equal(t, uint(30), source.ConnectionID)
+	require.Equal(t, "billing", source.Condition.LabelName)
+	require.True(t, source.Condition.Since.Equal(original.SinceAt))
+	require.Len(t, source.Failures, 2)
+	require.Equal(t, "msg-1", *source.Failures[0].ExternalMessageID)
+	require.Equal(t, "analysis", source.Failures[1].Stage)
+
+	disconnectedSource, err := env.repo.FindRetrySource(ctx, 10, "wf-disconnected")
+	require.NoError(t, err)
+	require.Zero(t, disconnectedSource.ConnectionID)
+
+	_, err = env.repo.FindRetrySource(ctx, 20, "wf-original")
+	require.ErrorIs(t, err, manualapp.ErrWorkflowHistoryNotFound)
+
+	ref, err := env.repo.CreateQueued(ctx, manualapp.QueuedWorkflowHistory{
+		WorkflowID:        "wf-retry",
+		RetryOfWorkflowID: "wf-original",
+		UserID:            10,
+		ConnectionID:      30,
+		LabelName:         "billing",
+		SinceAt:           original.SinceAt,
+		UntilAt:           original.UntilAt,
+		QueuedAt:          queuedAt.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	var retryHistory manualMailWorkflowHistoryRecord
+	require.NoError(t, env.db.WithContext(ctx).First(&retryHistory, ref.HistoryID).Error)
+	require.NotNil(t, retryHistory.RetryOfWorkflowID)
+	require.Equal(t, "wf-original", *retryHistory.RetryOfWorkflowID)
+}
+
+func TestGormWorkflowStatusRepository_FindParsedEmails_ReturnsLatestAnalysisRun(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	emails := []emailSnapshotRecord{
+		{UserID: 10, ExternalMessageID: "msg-1", Subject: "Invoice", FromRaw: "billing@example.com", ToJSON: `["user@example.com"]`, BodyDigest: "digest-1"},
+		{UserID: 10, ExternalMessageID: "msg-2", Subject: "Receipt", FromRaw: "shop@example.com", ToJSON: `[]`, BodyDigest: "digest-2"},
+		{UserID: 20, ExternalMessageID: "msg-1", Subject: "Other user", FromRaw: "other@example.com", ToJSON: `[]`, BodyDigest: "digest-3"},
+	}
+	require.NoError(t, env.db.WithContext(ctx).Create(&emails).Error)
+
+	extractedAt := time.Date(2026, 3, 25, 13, 0, 0, 0, time.UTC)
+	require.NoError(t, env.db.WithContext(ctx).Create(&[]parsedEmailSnapshotRecord{
+		{UserID: 10, EmailID: emails[0].ID, AnalysisRunID: "run-old", Position: 0, BillingNumber: stringPtr("OLD-1"), ExtractedAt: extractedAt},
+		{UserID: 10, EmailID: emails[0].ID, AnalysisRunID: "run-new", Position: 1, BillingNumber: stringPtr("NEW-2"), ExtractedAt: extractedAt},
+		{UserID: 10, EmailID: emails[0].ID, AnalysisRunID: "run-new", Position: 0, BillingNumber: stringPtr("NEW-1"), ExtractedAt: extractedAt},
+		{UserID: 20, EmailID: emails[2].ID, AnalysisRunID: "run-other", Position: 0, BillingNumber: stringPtr("OTHER-1"), ExtractedAt: extractedAt},
+	}).Error)
+
+	parsedEmails, err := env.repo.FindParsedEmails(ctx, 10, []string{"msg-1", "msg-2", "msg-unknown"})
+	require.NoError(t, err)
+	require.Len(t, parsedEmails, 2)
+	require.Equal(t, "NEW-1", *parsedEmails[0].Data.BillingNumber)
+	require.Equal(t, "NEW-2", *parsedEmails[1].Data.BillingNumber)
+	require.Equal(t, emails[0].ID, parsedEmails[0].EmailID)
+	require.Equal(t, "msg-1", parsedEmails[0].ExternalMessageID)
+	require.Equal(t, "Invoice", parsedEmails[0].Subject)
+	require.Equal(t, []string{"user@example.com"}, parsedEmails[0].To)
+	require.Equal(t, "digest-1", parsedEmails[0].BodyDigest)
+	require.True(t, parsedEmails[0].Data.ExtractedAt.Equal(extractedAt))
+}
@@ -0,0 +1,291 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// invalidScheduleRetryDelay は次回時刻を計算できない schedule を再評価するまでの待ち時間。
+const invalidScheduleRetryDelay = 24 * time.Hour
+
+type manualMailWorkflowScheduleRecord struct {
+	ID             uint64     `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID         uint       `gorm:"column:user_id;not null;index:idx_manual_mail_workflow_schedules_user_id"`
+	ConnectionID   uint       `gorm:"column:connection_id;not null"`
+	LabelName      string     `gorm:"column:label_name;size:255;not null"`
+	Frequency      string     `gorm:"column:frequency;size:16;not null"`
+	Weekday        *int       `gorm:"column:weekday;type:tinyint"`
+	HourOfDay      int        `gorm:"column:hour_of_day;type:tinyint;not null"`
+	MinuteOfHour   int        `gorm:"column:minute_of_hour;type:tinyint;not null"`
+	Timezone       string     `gorm:"column:timezone;size:64;not null"`
+	LookbackHours  int        `gorm:"column:lookback_hours;not null"`
+	NextRunAt      time.Time  `gorm:"column:next_run_at;not null;index:idx_manual_mail_workflow_schedules_next_run_at"`
+	LastRunAt      *time.Time `gorm:"column:last_run_at"`
+	LastWorkflowID *string    `gorm:"column:last_workflow_id;type:char(26)"`
+	LastError      *string    `gorm:"column:last_error;type:text"`
+	CreatedAt      time.Time  `gorm:"column:created_at;not null"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;not null"`
+}
+
+func (manualMailWorkflowScheduleRecord) TableName() string {
+	return "manual_mail_workflow_schedules"
+}
+
+// GormWorkflowScheduleRepository persists workflow schedules into MySQL.
+type GormWorkflowScheduleRepository struct {
+	db    *gorm.DB
+	clock timewrapper.ClockInterface
+	log   logger.Interface
+}
+
+// NewGormWorkflowScheduleRepository creates a Gorm-backed workflow schedule repository.
+func NewGormWorkflowScheduleRepository(
+	db *gorm.DB,
+	clock timewrapper.ClockInterface,
+	log logger.Interface,
+) *GormWorkflowScheduleRepository {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return &GormWorkflowScheduleRepository{
+		db:    db,
+		clock: clock,
+		log:   log.With(logger.Component("manual_mail_workflow_schedule_repository")),
+	}
+}
+
+// Create stores the schedule after checking that the connection belongs to the user and is usable.
+func (r *GormWorkflowScheduleRepository) Create(ctx context.Context, schedule manualapp.WorkflowSchedule) (manualapp.WorkflowSchedule, error) {
+	if ctx == nil {
+		return manualapp.WorkflowSchedule{}, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return manualapp.WorkflowSchedule{}, fmt.Errorf("gorm db is not configured")
+	}
+
+	var credentials []emailCredentialSnapshotRecord
+	if err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND o_auth_state IS NULL", schedule.ConnectionID, schedule.UserID).
+		Limit(1).
+		Find(&credentials).Error; err != nil {
+		r.logDBError(ctx, "email_credentials", "find_schedule_connection", err)
+		return manualapp.WorkflowSchedule{}, fmt.Errorf("failed to find schedule connection: %w", err)
+	}
+	if len(credentials) == 0 {
+		return manualapp.WorkflowSchedule{}, manualapp.ErrScheduleConnectionNotFound
+	}
+
+	now := r.clock.Now().UTC()
+	record := manualMailWorkflowScheduleRecord{
+		UserID:        schedule.UserID,
+		ConnectionID:  schedule.ConnectionID,
+		LabelName:     strings.TrimSpace(schedule.LabelName),
+		Frequency:     schedule.Frequency,
+		Weekday:       cloneOptionalInt(schedule.Weekday),
+		HourOfDay:     schedule.HourOfDay,
+		MinuteOfHour:  schedule.MinuteOfHour,
+		Timezone:      schedule.Timezone,
+		LookbackHours: schedule.LookbackHours,
+		NextRunAt:     schedule.NextRunAt.UTC(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := r.db.WithContext(ctx).Create(&record).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_schedules", "create", err)
+		return manualapp.WorkflowSchedule{}, fmt.Errorf("failed to create workflow schedule: %w", err)
+	}
+
+	return buildWorkflowSchedule(record), nil
+}
+
+// ListByUser returns the schedules of the user ordered by creation.
+func (r *GormWorkflowScheduleRepository) ListByUser(ctx context.Context, userID uint) ([]manualapp.WorkflowSchedule, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+
+	var records []manualMailWorkflowScheduleRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_schedules", "list", err)
+		return nil, fmt.Errorf("failed to list workflow schedules: %w", err)
+	}
+
+	schedules := make([]manualapp.WorkflowSchedule, 0, len(records))
+	for _, record := range records {
+		schedules = append(schedules, buildWorkflowSchedule(record))
+	}
+
+	return schedules, nil
+}
+
+// Delete removes the schedule of the user.
+func (r *GormWorkflowScheduleRepository) Delete(ctx context.Context, userID uint, scheduleID uint64) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	tx := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", scheduleID, userID).
+		Delete(&manualMailWorkflowScheduleRecord{})
+	if tx.Error != nil {
+		r.logDBError(ctx, "manual_mail_workflow_schedules", "delete", tx.Error)
+		return fmt.Errorf("failed to delete workflow schedule: %w", tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return manualapp.ErrWorkflowScheduleNotFound
+	}
+
+	return nil
+}
+
+// ClaimDue locks due schedules with SKIP LOCKED and advances next_run_at in the same transaction,
+// so a schedule slot is handed to exactly one scheduler even when several instances poll at once.
+func (r *GormWorkflowScheduleRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]manualapp.WorkflowSchedule, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+	if limit <= 0 {
+		return []manualapp.WorkflowSchedule{}, nil
+	}
+
+	now = now.UTC()
+	claimed := make([]manualapp.WorkflowSchedule, 0, limit)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var records []manualMailWorkflowScheduleRecord
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_run_at <= ?", now).
+			Order("next_run_at ASC").
+			Order("id ASC").
+			Limit(limit).
+			Find(&records).Error; err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			schedule := buildWorkflowSchedule(record)
+			nextRunAt, nextErr := schedule.NextRunAfter(now)
+			if nextErr != nil {
+				nextRunAt = now.Add(invalidScheduleRetryDelay)
+				r.log.Error("manual_mail_workflow_schedule_next_run_failed",
+					logger.Uint("schedule_id", uint(record.ID)),
+					logger.Err(nextErr),
+				)
+			}
+			//nolint:nplusonecheck // Each locked schedule gets its own next_run_at; the batch is bounded by limit.
+			if err := tx.Model(&manualMailWorkflowScheduleRecord{}).
+				Where("id = ?", record.ID).
+				Updates(map[string]interface{}{
+					"next_run_at": nextRunAt,
+					"last_run_at": now,
+					"updated_at":  now,
+				}).Error; err != nil {
+				return err
+			}
+			if nextErr != nil {
+				continue
+			}
+			schedule.NextRunAt = nextRunAt
+			lastRunAt := now
+			schedule.LastRunAt = &lastRunAt
+			claimed = append(claimed, schedule)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_schedules", "claim_due", err)
+		return nil, fmt.Errorf("failed to claim due workflow schedules: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// RecordRun stores the workflow started by the latest run, or the reason it could not start.
+func (r *GormWorkflowScheduleRepository) RecordRun(ctx context.Context, scheduleID uint64, workflowID string, errorMessage string) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&manualMailWorkflowScheduleRecord{}).
+		Where("id = ?", scheduleID).
+		Updates(map[string]interface{}{
+			"last_workflow_id": optionalString(workflowID),
+			"last_error":       optionalString(errorMessage),
+			"updated_at":       r.clock.Now().UTC(),
+		}).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_schedules", "record_run", err)
+		return fmt.Errorf("failed to record workflow schedule run: %w", err)
+	}
+
+	return nil
+}
+
+func (r *GormWorkflowScheduleRepository) logDBError(ctx context.Context, table string, operation string, err error) {
+	reqLog := r.log
+	if withContext, withCtxErr := r.log.WithContext(ctx); withCtxErr == nil {
+		reqLog = withContext
+	}
+
+	reqLog.Error("db_query_failed",
+		logger.String("db_system", "mysql"),
+		logger.String("table", table),
+		logger.String("operation", operation),
+		logger.Err(err),
+	)
+}
+
+func buildWorkflowSchedule(record manualMailWorkflowScheduleRecord) manualapp.WorkflowSchedule {
+	return manualapp.WorkflowSchedule{
+		ID:             record.ID,
+		UserID:         record.UserID,
+		ConnectionID:   record.ConnectionID,
+		LabelName:      record.LabelName,
+		Frequency:      record.Frequency,
+		Weekday:        cloneOptionalInt(record.Weekday),
+		HourOfDay:      record.HourOfDay,
+		MinuteOfHour:   record.MinuteOfHour,
+		Timezone:       record.Timezone,
+		LookbackHours:  record.LookbackHours,
+		NextRunAt:      record.NextRunAt.UTC(),
+		LastRunAt:      cloneOptionalTime(record.LastRunAt),
+		LastWorkflowID: cloneOptionalString(record.LastWorkflowID),
+		LastError:      cloneOptionalString(record.LastError),
+		CreatedAt:      record.CreatedAt.UTC(),
+		UpdatedAt:      record.UpdatedAt.UTC(),
+	}
+}
+
+func cloneOptionalInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	cloned := *value
+	return &cloned
+}
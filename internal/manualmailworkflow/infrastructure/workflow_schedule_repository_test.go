@@ -0,0 +1,123 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/mysql"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newWorkflowScheduleRepoTestEnv(t *testing.T) (*GormWorkflowScheduleRepository, *workflowStatusRepoTestEnv) {
+	t.Helper()
+
+	mysqlConn, cleanup, err := mysql.CreateNewTestDB()
+	if err != nil {
+		skipIfWorkflowStatusRepoDBUnavailable(t, err)
+	}
+	require.NoError(t, err)
+	require.NoError(t, mysqlConn.DB.AutoMigrate(
+		&emailCredentialSnapshotRecord{},
+		&manualMailWorkflowScheduleRecord{},
+	))
+
+	nowUTC := time.Date(2026, 3, 25, 15, 0, 0, 0, time.UTC)
+	return NewGormWorkflowScheduleRepository(mysqlConn.DB, &workflowStatusRepoFixedClock{now: nowUTC}, logger.NewNop()),
+		&workflowStatusRepoTestEnv{db: mysqlConn.DB, nowUTC: nowUTC, clean: cleanup}
+}
+
+func TestGormWorkflowScheduleRepository_CreateListDelete(t *testing.T) {
+	t.Parallel()
+
+	repo, env := newWorkflowScheduleRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{
+		ID:           30,
+		UserID:       10,
+		Type:         "gmail",
+		GmailAddress: "billing@example.com",
+	})
+	schedule := manualapp.WorkflowSchedule{
+		UserID:        10,
+		ConnectionID:  30,
+		LabelName:     "billing",
+		Frequency:     manualapp.ScheduleFrequencyDaily,
+		HourOfDay:     9,
+		Timezone:      "Asia/Tokyo",
+		LookbackHours: 24,
+		NextRunAt:     time.Date(2026, 3, 26, 0, 0, 0, 0, time.UTC),
+	}
+
+	created, err := repo.Create(ctx, schedule)
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+
+	otherUser := schedule
+	otherUser.UserID = 20
+	_, err = repo.Create(ctx, otherUser)
+	require.ErrorIs(t, err, manualapp.ErrScheduleConnectionNotFound)
+
+	schedules, err := repo.ListByUser(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	require.Equal(t, "09:00", schedules[0].TimeOfDay())
+	require.True(t, schedules[0].NextRunAt.Equal(schedule.NextRunAt))
+
+	require.ErrorIs(t, repo.Delete(ctx, 20, created.ID), manualapp.ErrWorkflowScheduleNotFound)
+	require.NoError(t, repo.Delete(ctx, 10, created.ID))
+
+	schedules, err = repo.ListByUser(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, schedules)
+}
+
+func TestGormWorkflowScheduleRepository_ClaimDueAdvancesNextRun(t *testing.T) {
+	t.Parallel()
+
+	repo, env := newWorkflowScheduleRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	now := time.Date(2026, 3, 25, 0, 0, 30, 0, time.UTC)
+	due := manualMailWorkflowScheduleRecord{
+		UserID:        10,
+		ConnectionID:  30,
+		LabelName:     "billing",
+		Frequency:     manualapp.ScheduleFrequencyDaily,
+		HourOfDay:     9,
+		Timezone:      "Asia/Tokyo",
+		LookbackHours: 24,
+		NextRunAt:     time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	future := due
+	future.NextRunAt = now.Add(time.Hour)
+	require.NoError(t, env.db.WithContext(ctx).Create(&due).Error)
+	require.NoError(t, env.db.WithContext(ctx).Create(&future).Error)
+
+	claimed, err := repo.ClaimDue(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, due.ID, claimed[0].ID)
+	require.True(t, claimed[0].NextRunAt.Equal(time.Date(2026, 3, 26, 0, 0, 0, 0, time.UTC)))
+
+	// 同じ起動枠は二度取り出されない。
+	claimedAgain, err := repo.ClaimDue(ctx, now, 10)
+	require.NoError(t, err)
+	require.Empty(t, claimedAgain)
+
+	require.NoError(t, repo.RecordRun(ctx, due.ID, "01JQ0B7N0M7H3X9C2J5K8V6P4", ""))
+
+	var stored manualMailWorkflowScheduleRecord
+	require.NoError(t, env.db.WithContext(ctx).First(&stored, due.ID).Error)
+	require.NotNil(t, stored.LastRunAt)
+	require.NotNil(t, stored.LastWorkflowID)
+	require.Equal(t, "01JQ0B7N0M7H3X9C2J5K8V6P4", *stored.LastWorkflowID)
+	require.Nil(t, stored.LastError)
+}
@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"business/internal/library/timewrapper"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultWorkflowSchedulerPollInterval = time.Minute
+
+// WorkflowSchedulerConfig controls how often WorkflowScheduler looks for due schedules.
+type WorkflowSchedulerConfig struct {
+	PollInterval time.Duration
+}
+
+// DefaultWorkflowSchedulerConfig returns the default scheduler configuration.
+func DefaultWorkflowSchedulerConfig() WorkflowSchedulerConfig {
+	return WorkflowSchedulerConfig{
+		PollInterval: defaultWorkflowSchedulerPollInterval,
+	}
+}
+
+// WorkflowScheduler periodically starts workflows for due schedules.
+// Every instance may run one; the repository claim keeps a schedule slot from firing twice.
+type WorkflowScheduler struct {
+	dispatcher manualapp.ScheduleDispatchUseCase
+	clock      timewrapper.ClockInterface
+	cfg        WorkflowSchedulerConfig
+	log        logger.Interface
+}
+
+// NewWorkflowScheduler creates an in-process scheduler for workflow schedules.
+func NewWorkflowScheduler(
+	dispatcher manualapp.ScheduleDispatchUseCase,
+	clock timewrapper.ClockInterface,
+	cfg WorkflowSchedulerConfig,
+	log logger.Interface,
+) *WorkflowScheduler {
+	if clock == nil {
+		clock = timewrapper.NewClock()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultWorkflowSchedulerConfig().PollInterval
+	}
+
+	return &WorkflowScheduler{
+		dispatcher: dispatcher,
+		clock:      clock,
+		cfg:        cfg,
+		log:        log.With(logger.Component("manual_mail_workflow_scheduler")),
+	}
+}
+
+// Run dispatches due schedules every poll interval until ctx is cancelled.
+func (s *WorkflowScheduler) Run(ctx context.Context) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if s.dispatcher == nil {
+		return errors.New("manual mail workflow schedule dispatcher is not configured")
+	}
+
+	s.log.Info("manual_mail_workflow_scheduler_started",
+		logger.String("poll_interval", s.cfg.PollInterval.String()),
+	)
+
+	for {
+		started, err := s.dispatcher.DispatchDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.log.Error("manual_mail_workflow_schedule_dispatch_failed", logger.Err(err))
+		} else if started > 0 {
+			s.log.Info("manual_mail_workflow_schedules_dispatched", logger.Int("started_count", started))
+		}
+
+		select {
+		case <-ctx.Done():
+			s.log.Info("manual_mail_workflow_scheduler_stopping")
+			return nil
+		case <-s.clock.After(s.cfg.PollInterval):
+		}
+	}
+}
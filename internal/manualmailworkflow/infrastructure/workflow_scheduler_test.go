@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type stubScheduleDispatchUseCase struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (s *stubScheduleDispatchUseCase) DispatchDue(ctx context.Context) (int, error) {
+	s.calls.Add(1)
+	return 1, s.err
+}
+
+func TestWorkflowScheduler_Run_DispatchesUntilCancelled(t *testing.T) {
+	t.Parallel()
+
+	dispatcher := &stubScheduleDispatchUseCase{err: errors.New("temporary")}
+	scheduler := NewWorkflowScheduler(dispatcher, nil, WorkflowSchedulerConfig{PollInterval: 5 * time.Millisecond}, logger.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- scheduler.Run(ctx)
+	}()
+
+	deadline := time.After(time.Second)
+	for dispatcher.calls.Load() < 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("scheduler did not keep polling after an error: calls=%d", dispatcher.calls.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after cancel")
+	}
+}
+
+func TestWorkflowScheduler_Run_RequiresDispatcher(t *testing.T) {
+	t.Parallel()
+
+	scheduler := NewWorkflowScheduler(nil, nil, WorkflowSchedulerConfig{}, nil)
+	if err := scheduler.Run(context.Background()); err == nil {
+		t.Fatal("expected an error without dispatcher")
+	}
+}
@@ -0,0 +1,156 @@
+package infrastructure
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// manualMailWorkflowStageCountRecord holds the counts of a stage added to the pipeline.
+// The built-in stages keep their counts in the header columns of manual_mail_workflow_histories.
+type manualMailWorkflowStageCountRecord struct {
+	ID                    uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	WorkflowHistoryID     uint64    `gorm:"column:workflow_history_id;not null;uniqueIndex:uni_manual_mail_workflow_stage_counts_history_stage,priority:1"`
+	Stage                 string    `gorm:"column:stage;size:32;not null;uniqueIndex:uni_manual_mail_workflow_stage_counts_history_stage,priority:2"`
+	SuccessCount          int       `gorm:"column:success_count;not null;default:0"`
+	BusinessFailureCount  int       `gorm:"column:business_failure_count;not null;default:0"`
+	TechnicalFailureCount int       `gorm:"column:technical_failure_count;not null;default:0"`
+	CreatedAt             time.Time `gorm:"column:created_at;not null"`
+	UpdatedAt             time.Time `gorm:"column:updated_at;not null"`
+}
+
+func (manualMailWorkflowStageCountRecord) TableName() string {
+	return "manual_mail_workflow_stage_counts"
+}
+
+// saveAdditionalStageCount upserts the counts of one additional stage; a re-run of the same job overwrites them.
+func saveAdditionalStageCount(tx *gorm.DB, progress manualapp.StageProgress, now time.Time) error {
+	record := manualMailWorkflowStageCountRecord{
+		WorkflowHistoryID:     progress.HistoryID,
+		Stage:                 strings.TrimSpace(progress.Stage),
+		SuccessCount:          progress.SuccessCount,
+		BusinessFailureCount:  progress.BusinessFailureCount,
+		TechnicalFailureCount: progress.TechnicalFailureCount,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workflow_history_id"}, {Name: "stage"}},
+		DoUpdates: clause.AssignmentColumns([]string{"success_count", "business_failure_count", "technical_failure_count", "updated_at"}),
+	}).Create(&record).Error
+}
+
+// findAdditionalStageCounts loads the additional stage counts of the given histories, keyed by history ID.
+func (r *GormWorkflowStatusRepository) findAdditionalStageCounts(
+	ctx context.Context,
+	historyIDs []uint64,
+) (map[uint64][]manualMailWorkflowStageCountRecord, error) {
+	if len(historyIDs) == 0 {
+		return map[uint64][]manualMailWorkflowStageCountRecord{}, nil
+	}
+
+	var records []manualMailWorkflowStageCountRecord
+	if err := r.db.WithContext(ctx).
+		Where("workflow_history_id IN ?", historyIDs).
+		Order("workflow_history_id ASC").
+		Order("id ASC").
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_stage_counts", "find_stage_counts", err)
+		return nil, fmt.Errorf("failed to list workflow stage counts: %w", err)
+	}
+
+	countsByHistory := make(map[uint64][]manualMailWorkflowStageCountRecord, len(historyIDs))
+	for _, record := range records {
+		countsByHistory[record.WorkflowHistoryID] = append(countsByHistory[record.WorkflowHistoryID], record)
+	}
+	return countsByHistory, nil
+}
+
+// refreshFanOutParentStageCounts sums the children's additional stage counts onto the sync-all parent.
+func refreshFanOutParentStageCounts(tx *gorm.DB, parentHistoryID uint64, children []manualMailWorkflowHistoryRecord, now time.Time) error {
+	childIDs := make([]uint64, 0, len(children))
+	for _, child := range children {
+		childIDs = append(childIDs, child.ID)
+	}
+
+	var records []manualMailWorkflowStageCountRecord
+	if err := tx.Where("workflow_history_id IN ?", childIDs).Find(&records).Error; err != nil {
+		return err
+	}
+
+	totals := make(map[string]*manualapp.StageProgress)
+	for _, record := range records {
+		total, ok := totals[record.Stage]
+		if !ok {
+			total = &manualapp.StageProgress{HistoryID: parentHistoryID, Stage: record.Stage}
+			totals[record.Stage] = total
+		}
+		total.SuccessCount += record.SuccessCount
+		total.BusinessFailureCount += record.BusinessFailureCount
+		total.TechnicalFailureCount += record.TechnicalFailureCount
+	}
+
+	stages := make([]string, 0, len(totals))
+	for stage := range totals {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	for _, stage := range stages {
+		if err := saveAdditionalStageCount(tx, *totals[stage], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func additionalStageCountViews(records []manualMailWorkflowStageCountRecord) []manualapp.AdditionalStageCountView {
+	if len(records) == 0 {
+		return nil
+	}
+
+	views := make([]manualapp.AdditionalStageCountView, 0, len(records))
+	for _, record := range records {
+		views = append(views, manualapp.AdditionalStageCountView{
+			Stage:          record.Stage,
+			StageCountView: stageCountView(record),
+		})
+	}
+	return views
+}
+
+func additionalStageSummaryViews(
+	records []manualMailWorkflowStageCountRecord,
+	failuresByStage map[string][]manualapp.StageFailureView,
+) []manualapp.AdditionalStageSummaryView {
+	if len(records) == 0 {
+		return nil
+	}
+
+	views := make([]manualapp.AdditionalStageSummaryView, 0, len(records))
+	for _, record := range records {
+		views = append(views, manualapp.AdditionalStageSummaryView{
+			Stage: record.Stage,
+			StageSummaryView: manualapp.StageSummaryView{
+				SuccessCount:          record.SuccessCount,
+				BusinessFailureCount:  record.BusinessFailureCount,
+				TechnicalFailureCount: record.TechnicalFailureCount,
+				Failures:              stageFailureViews(failuresByStage, record.Stage),
+			},
+		})
+	}
+	return views
+}
+
+func stageCountView(record manualMailWorkflowStageCountRecord) manualapp.StageCountView {
+	return manualapp.StageCountView{
+		SuccessCount:          record.SuccessCount,
+		BusinessFailureCount:  record.BusinessFailureCount,
+		TechnicalFailureCount: record.TechnicalFailureCount,
+	}
+}
@@ -0,0 +1,112 @@
+package infrastructure
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// manualMailWorkflowStageEventRecord is one stage run of a workflow, from MarkRunning until the stage
+// progress was saved or the workflow stopped. A retried or resumed job appends new rows.
+type manualMailWorkflowStageEventRecord struct {
+	ID                uint64     `gorm:"column:id;primaryKey;autoIncrement"`
+	WorkflowHistoryID uint64     `gorm:"column:workflow_history_id;not null;index:idx_manual_mail_workflow_stage_events_history_started_at,priority:1"`
+	Stage             string     `gorm:"column:stage;size:32;not null"`
+	StartedAt         time.Time  `gorm:"column:started_at;not null;index:idx_manual_mail_workflow_stage_events_history_started_at,priority:2"`
+	FinishedAt        *time.Time `gorm:"column:finished_at"`
+	CreatedAt         time.Time  `gorm:"column:created_at;not null"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at;not null"`
+}
+
+func (manualMailWorkflowStageEventRecord) TableName() string {
+	return "manual_mail_workflow_stage_events"
+}
+
+// startStageEvent closes the stage the workflow was in, if any, and opens a row for the stage it enters.
+func startStageEvent(tx *gorm.DB, historyID uint64, stage string, now time.Time) error {
+	if err := finishOpenStageEvents(tx, []uint64{historyID}, now); err != nil {
+		return err
+	}
+	return tx.Create(&manualMailWorkflowStageEventRecord{
+		WorkflowHistoryID: historyID,
+		Stage:             stage,
+		StartedAt:         now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}).Error
+}
+
+// finishStageEvent closes the open row of the stage whose progress was saved.
+func finishStageEvent(tx *gorm.DB, historyID uint64, stage string, now time.Time) error {
+	return tx.Model(&manualMailWorkflowStageEventRecord{}).
+		Where("workflow_history_id = ? AND stage = ? AND finished_at IS NULL", historyID, stage).
+		Updates(map[string]interface{}{
+			"finished_at": now,
+			"updated_at":  now,
+		}).Error
+}
+
+// finishOpenStageEvents closes every open row of the histories, e.g. when a workflow stops in the middle of a stage.
+func finishOpenStageEvents(tx *gorm.DB, historyIDs []uint64, now time.Time) error {
+	if len(historyIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&manualMailWorkflowStageEventRecord{}).
+		Where("workflow_history_id IN ? AND finished_at IS NULL", historyIDs).
+		Updates(map[string]interface{}{
+			"finished_at": now,
+			"updated_at":  now,
+		}).Error
+}
+
+// findStageTimelines loads the stage runs of the given histories in the order they started, keyed by history ID.
+func (r *GormWorkflowStatusRepository) findStageTimelines(
+	ctx context.Context,
+	historyIDs []uint64,
+) (map[uint64][]manualapp.StageTimelineView, error) {
+	if len(historyIDs) == 0 {
+		return map[uint64][]manualapp.StageTimelineView{}, nil
+	}
+
+	var records []manualMailWorkflowStageEventRecord
+	if err := r.db.WithContext(ctx).
+		Where("workflow_history_id IN ?", historyIDs).
+		Order("workflow_history_id ASC").
+		Order("started_at ASC").
+		Order("id ASC").
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_stage_events", "find_stage_events", err)
+		return nil, fmt.Errorf("failed to list workflow stage events: %w", err)
+	}
+
+	timelines := make(map[uint64][]manualapp.StageTimelineView, len(historyIDs))
+	for _, record := range records {
+		timelines[record.WorkflowHistoryID] = append(timelines[record.WorkflowHistoryID], stageTimelineView(record))
+	}
+	return timelines, nil
+}
+
+func stageTimelineView(record manualMailWorkflowStageEventRecord) manualapp.StageTimelineView {
+	view := manualapp.StageTimelineView{
+		Stage:      record.Stage,
+		StartedAt:  record.StartedAt.UTC(),
+		FinishedAt: cloneOptionalTime(record.FinishedAt),
+	}
+	if view.FinishedAt != nil {
+		durationMillis := max(view.FinishedAt.Sub(view.StartedAt).Milliseconds(), 0)
+		view.DurationMillis = &durationMillis
+	}
+	return view
+}
+
+// stageTimelineOrEmpty keeps the timeline of a workflow that never entered a stage as an empty list.
+func stageTimelineOrEmpty(timelines map[uint64][]manualapp.StageTimelineView, historyID uint64) []manualapp.StageTimelineView {
+	timeline, ok := timelines[historyID]
+	if !ok {
+		return []manualapp.StageTimelineView{}
+	}
+	return timeline
+}
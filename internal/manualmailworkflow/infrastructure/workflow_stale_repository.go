@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"business/internal/library/logger"
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const workflowJobReapedMessage = "メール取得ワークフローが長時間進まなかったため、job を打ち切りました。"
+
+// ClaimStale locks queued/running histories last updated before staleBefore whose job no worker holds a live lease on.
+// In the same transaction it abandons their jobs so that no worker picks them up again, and touches the histories so
+// that a concurrent reaper does not claim them a second time. Sync-all parents are left to their children.
+func (r *GormWorkflowStatusRepository) ClaimStale(ctx context.Context, staleBefore time.Time, limit int) ([]manualapp.StaleWorkflow, error) {
+	if ctx == nil {
+		return nil, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("gorm db is not configured")
+	}
+	if limit <= 0 {
+		return []manualapp.StaleWorkflow{}, nil
+	}
+
+	now := r.clock.Now().UTC()
+	var stale []manualapp.StaleWorkflow
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var records []manualMailWorkflowHistoryRecord
+		if err := tx.Table("manual_mail_workflow_histories AS h").
+			Select("h.*").
+			Joins("LEFT JOIN manual_mail_workflow_jobs AS j ON j.workflow_history_id = h.id").
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "h"}, Options: "SKIP LOCKED"}).
+			Where("h.status IN ? AND h.fan_out = ? AND h.updated_at < ?",
+				[]string{manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning}, false, staleBefore.UTC()).
+			Where("(j.id IS NULL OR j.status <> ? OR j.lease_expires_at < ?)", workflowJobStatusRunning, now).
+			Order("h.updated_at ASC").
+			Order("h.id ASC").
+			Limit(limit).
+			Find(&records).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		historyIDs := make([]uint64, 0, len(records))
+		for _, record := range records {
+			historyIDs = append(historyIDs, record.ID)
+			stale = append(stale, manualapp.StaleWorkflow{
+				HistoryID:    record.ID,
+				WorkflowID:   record.WorkflowID,
+				UserID:       record.UserID,
+				Status:       record.Status,
+				CurrentStage: stringValue(record.CurrentStage),
+				UpdatedAt:    record.UpdatedAt.UTC(),
+			})
+		}
+
+		lastError := workflowJobReapedMessage
+		if err := tx.Model(&manualMailWorkflowJobRecord{}).
+			Where("workflow_history_id IN ? AND status IN ?", historyIDs, []string{workflowJobStatusPending, workflowJobStatusRunning}).
+			Updates(map[string]interface{}{
+				"status":           workflowJobStatusAbandoned,
+				"lease_owner":      nil,
+				"lease_expires_at": nil,
+				"last_error":       &lastError,
+				"updated_at":       now,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&manualMailWorkflowHistoryRecord{}).
+			Where("id IN ?", historyIDs).
+			Update("updated_at", now).Error
+	})
+	if err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories/manual_mail_workflow_jobs", "claim_stale", err)
+		return nil, fmt.Errorf("failed to claim stale workflows: %w", err)
+	}
+
+	return stale, nil
+}
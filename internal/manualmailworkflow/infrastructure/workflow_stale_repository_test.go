@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	manualapp "business/internal/manualmailworkflow/application"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormWorkflowStatusRepository_ClaimStale(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	staleAt := env.nowUTC.Add(-4 * time.Hour)
+
+	abandoned := workflowHistoryRecordFixture(1, "wf-abandoned", staleAt, manualapp.WorkflowStatusRunning)
+	abandoned.CurrentStage = stringPtr("analysis")
+	leased := workflowHistoryRecordFixture(1, "wf-leased", staleAt, manualapp.WorkflowStatusRunning)
+	queued := workflowHistoryRecordFixture(1, "wf-queued", staleAt.Add(time.Minute), manualapp.WorkflowStatusQueued)
+	recent := workflowHistoryRecordFixture(1, "wf-recent", env.nowUTC.Add(-time.Minute), manualapp.WorkflowStatusRunning)
+	finished := workflowHistoryRecordFixture(1, "wf-finished", staleAt, manualapp.WorkflowStatusSucceeded)
+	parent := workflowHistoryRecordFixture(1, "wf-parent", staleAt, manualapp.WorkflowStatusRunning)
+	parent.FanOut = true
+	for _, record := range []*manualMailWorkflowHistoryRecord{&abandoned, &leased, &queued, &recent, &finished, &parent} {
+		require.NoError(t, env.db.Create(record).Error)
+	}
+
+	liveLease := env.nowUTC.Add(time.Minute)
+	expiredLease := env.nowUTC.Add(-time.Hour)
+	for _, job := range []manualMailWorkflowJobRecord{
+		workflowJobRecordFixture(abandoned, workflowJobStatusRunning, &expiredLease),
+		workflowJobRecordFixture(leased, workflowJobStatusRunning, &liveLease),
+		workflowJobRecordFixture(queued, workflowJobStatusPending, nil),
+	} {
+		require.NoError(t, env.db.Create(&job).Error)
+	}
+
+	stale, err := env.repo.ClaimStale(ctx, env.nowUTC.Add(-3*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, stale, 2)
+	require.Equal(t, "wf-abandoned", stale[0].WorkflowID)
+	require.Equal(t, "analysis", stale[0].CurrentStage)
+	require.Equal(t, manualapp.WorkflowStatusRunning, stale[0].Status)
+	require.Equal(t, "wf-queued", stale[1].WorkflowID)
+	require.Empty(t, stale[1].CurrentStage)
+
+	var jobs []manualMailWorkflowJobRecord
+	require.NoError(t, env.db.Where("workflow_history_id IN ?", []uint64{abandoned.ID, queued.ID}).Find(&jobs).Error)
+	require.Len(t, jobs, 2)
+	for _, job := range jobs {
+		require.Equal(t, workflowJobStatusAbandoned, job.Status)
+		require.Nil(t, job.LeaseOwner)
+	}
+
+	stale, err = env.repo.ClaimStale(ctx, env.nowUTC.Add(-3*time.Hour), 10)
+	require.NoError(t, err)
+	require.Empty(t, stale)
+}
+
+func workflowJobRecordFixture(history manualMailWorkflowHistoryRecord, status string, leaseExpiresAt *time.Time) manualMailWorkflowJobRecord {
+	record := newWorkflowJobRecord(manualapp.DispatchJob{
+		HistoryID:    history.ID,
+		WorkflowID:   history.WorkflowID,
+		UserID:       history.UserID,
+		ConnectionID: 21,
+		Condition: manualapp.FetchCondition{
+			LabelName: history.LabelName,
+			Since:     history.SinceAt,
+			Until:     history.UntilAt,
+		},
+	}, "", defaultWorkflowJobMaxAttempts, history.QueuedAt)
+	record.Status = status
+	record.LeaseExpiresAt = leaseExpiresAt
+	if status == workflowJobStatusRunning {
+		record.LeaseOwner = stringPtr("worker-a")
+	}
+	return record
+}
@@ -5,50 +5,87 @@ import (
 	"business/internal/library/timewrapper"
 	manualapp "business/internal/manualmailworkflow/application"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type manualMailWorkflowHistoryRecord struct {
-	ID                                      uint64     `gorm:"column:id;primaryKey;autoIncrement"`
-	WorkflowID                              string     `gorm:"column:workflow_id;type:char(26);not null;uniqueIndex:uni_manual_mail_workflow_histories_workflow_id"`
-	UserID                                  uint       `gorm:"column:user_id;not null;index:idx_manual_mail_workflow_histories_user_queued_at,priority:1;index:idx_manual_mail_workflow_histories_user_status_queued_at,priority:1"`
-	Provider                                string     `gorm:"column:provider;size:50;not null"`
-	AccountIdentifier                       string     `gorm:"column:account_identifier;size:255;not null"`
-	LabelName                               string     `gorm:"column:label_name;size:255;not null"`
-	SinceAt                                 time.Time  `gorm:"column:since_at;not null"`
-	UntilAt                                 time.Time  `gorm:"column:until_at;not null"`
-	Status                                  string     `gorm:"column:status;size:32;not null;index:idx_manual_mail_workflow_histories_user_status_queued_at,priority:2"`
-	CurrentStage                            *string    `gorm:"column:current_stage;size:32"`
-	QueuedAt                                time.Time  `gorm:"column:queued_at;not null;index:idx_manual_mail_workflow_histories_user_queued_at,priority:2;index:idx_manual_mail_workflow_histories_user_status_queued_at,priority:3"`
-	FinishedAt                              *time.Time `gorm:"column:finished_at"`
-	ErrorMessage                            *string    `gorm:"column:error_message;type:text"`
-	FetchSuccessCount                       int        `gorm:"column:fetch_success_count;not null;default:0"`
-	FetchBusinessFailureCount               int        `gorm:"column:fetch_business_failure_count;not null;default:0"`
-	FetchTechnicalFailureCount              int        `gorm:"column:fetch_technical_failure_count;not null;default:0"`
-	AnalysisSuccessCount                    int        `gorm:"column:analysis_success_count;not null;default:0"`
-	AnalysisBusinessFailureCount            int        `gorm:"column:analysis_business_failure_count;not null;default:0"`
-	AnalysisTechnicalFailureCount           int        `gorm:"column:analysis_technical_failure_count;not null;default:0"`
-	VendorResolutionSuccessCount            int        `gorm:"column:vendor_resolution_success_count;not null;default:0"`
-	VendorResolutionBusinessFailureCount    int        `gorm:"column:vendor_resolution_business_failure_count;not null;default:0"`
-	VendorResolutionTechnicalFailureCount   int        `gorm:"column:vendor_resolution_technical_failure_count;not null;default:0"`
-	BillingEligibilitySuccessCount          int        `gorm:"column:billing_eligibility_success_count;not null;default:0"`
-	BillingEligibilityBusinessFailureCount  int        `gorm:"column:billing_eligibility_business_failure_count;not null;default:0"`
-	BillingEligibilityTechnicalFailureCount int        `gorm:"column:billing_eligibility_technical_failure_count;not null;default:0"`
-	BillingSuccessCount                     int        `gorm:"column:billing_success_count;not null;default:0"`
-	BillingBusinessFailureCount             int        `gorm:"column:billing_business_failure_count;not null;default:0"`
-	BillingTechnicalFailureCount            int        `gorm:"column:billing_technical_failure_count;not null;default:0"`
-	CreatedAt                               time.Time  `gorm:"column:created_at;not null"`
-	UpdatedAt                               time.Time  `gorm:"column:updated_at;not null"`
+	ID                                      uint64                     `gorm:"column:id;primaryKey;autoIncrement"`
+	WorkflowID                              string                     `gorm:"column:workflow_id;type:char(26);not null;uniqueIndex:uni_manual_mail_workflow_histories_workflow_id"`
+	RetryOfWorkflowID                       *string                    `gorm:"column:retry_of_workflow_id;type:char(26)"`
+	ParentWorkflowID                        *string                    `gorm:"column:parent_workflow_id;type:char(26);index:idx_manual_mail_workflow_histories_parent_workflow_id"`
+	FanOut                                  bool                       `gorm:"column:fan_out;not null;default:false"`
+	DryRun                                  bool                       `gorm:"column:dry_run;not null;default:false"`
+	UserID                                  uint                       `gorm:"column:user_id;not null;index:idx_manual_mail_workflow_histories_user_queued_at,priority:1;index:idx_manual_mail_workflow_histories_user_status_queued_at,priority:1"`
+	Provider                                string                     `gorm:"column:provider;size:50;not null"`
+	AccountIdentifier                       string                     `gorm:"column:account_identifier;size:255;not null"`
+	LabelName                               string                     `gorm:"column:label_name;size:255;not null"`
+	SinceAt                                 time.Time                  `gorm:"column:since_at;not null"`
+	UntilAt                                 time.Time                  `gorm:"column:until_at;not null"`
+	FetchFilter                             *workflowFetchFilterColumn `gorm:"column:fetch_filter;type:json;serializer:json"`
+	Status                                  string                     `gorm:"column:status;size:32;not null;index:idx_manual_mail_workflow_histories_user_status_queued_at,priority:2"`
+	CurrentStage                            *string                    `gorm:"column:current_stage;size:32"`
+	QueuedAt                                time.Time                  `gorm:"column:queued_at;not null;index:idx_manual_mail_workflow_histories_user_queued_at,priority:2;index:idx_manual_mail_workflow_histories_user_status_queued_at,priority:3"`
+	FinishedAt                              *time.Time                 `gorm:"column:finished_at"`
+	CancelRequestedAt                       *time.Time                 `gorm:"column:cancel_requested_at"`
+	ErrorMessage                            *string                    `gorm:"column:error_message;type:text"`
+	FetchSuccessCount                       int                        `gorm:"column:fetch_success_count;not null;default:0"`
+	FetchBusinessFailureCount               int                        `gorm:"column:fetch_business_failure_count;not null;default:0"`
+	FetchTechnicalFailureCount              int                        `gorm:"column:fetch_technical_failure_count;not null;default:0"`
+	AnalysisSuccessCount                    int                        `gorm:"column:analysis_success_count;not null;default:0"`
+	AnalysisBusinessFailureCount            int                        `gorm:"column:analysis_business_failure_count;not null;default:0"`
+	AnalysisTechnicalFailureCount           int                        `gorm:"column:analysis_technical_failure_count;not null;default:0"`
+	VendorResolutionSuccessCount            int                        `gorm:"column:vendor_resolution_success_count;not null;default:0"`
+	VendorResolutionBusinessFailureCount    int                        `gorm:"column:vendor_resolution_business_failure_count;not null;default:0"`
+	VendorResolutionTechnicalFailureCount   int                        `gorm:"column:vendor_resolution_technical_failure_count;not null;default:0"`
+	BillingEligibilitySuccessCount          int                        `gorm:"column:billing_eligibility_success_count;not null;default:0"`
+	BillingEligibilityBusinessFailureCount  int                        `gorm:"column:billing_eligibility_business_failure_count;not null;default:0"`
+	BillingEligibilityTechnicalFailureCount int                        `gorm:"column:billing_eligibility_technical_failure_count;not null;default:0"`
+	BillingSuccessCount                     int                        `gorm:"column:billing_success_count;not null;default:0"`
+	BillingBusinessFailureCount             int                        `gorm:"column:billing_business_failure_count;not null;default:0"`
+	BillingTechnicalFailureCount            int                        `gorm:"column:billing_technical_failure_count;not null;default:0"`
+	CreatedAt                               time.Time                  `gorm:"column:created_at;not null"`
+	UpdatedAt                               time.Time                  `gorm:"column:updated_at;not null"`
 }
 
 func (manualMailWorkflowHistoryRecord) TableName() string {
 	return "manual_mail_workflow_histories"
 }
 
+// workflowFetchFilterColumn is the JSON form of manualapp.FetchFilter. A workflow without a filter stores NULL.
+type workflowFetchFilterColumn struct {
+	Query             string   `json:"query,omitempty"`
+	IncludeLabelNames []string `json:"include_label_names,omitempty"`
+	ExcludeLabelNames []string `json:"exclude_label_names,omitempty"`
+}
+
+func toWorkflowFetchFilterColumn(filter manualapp.FetchFilter) *workflowFetchFilterColumn {
+	if filter.IsZero() {
+		return nil
+	}
+	return &workflowFetchFilterColumn{
+		Query:             filter.Query,
+		IncludeLabelNames: append([]string(nil), filter.IncludeLabelNames...),
+		ExcludeLabelNames: append([]string(nil), filter.ExcludeLabelNames...),
+	}
+}
+
+func (c *workflowFetchFilterColumn) toFetchFilter() manualapp.FetchFilter {
+	if c == nil {
+		return manualapp.FetchFilter{}
+	}
+	return manualapp.FetchFilter{
+		Query:             c.Query,
+		IncludeLabelNames: append([]string(nil), c.IncludeLabelNames...),
+		ExcludeLabelNames: append([]string(nil), c.ExcludeLabelNames...),
+	}
+}
+
 type manualMailWorkflowStageFailureRecord struct {
 	WorkflowHistoryID uint64    `gorm:"column:workflow_history_id;not null;index:idx_manual_mail_workflow_stage_failures_history_stage_created_at,priority:1"`
 	Stage             string    `gorm:"column:stage;size:32;not null;index:idx_manual_mail_workflow_stage_failures_history_stage_created_at,priority:2"`
@@ -110,20 +147,30 @@ func (r *GormWorkflowStatusRepository) CreateQueued(ctx context.Context, cmd man
 		return manualapp.WorkflowHistoryRef{}, fmt.Errorf("gorm db is not configured")
 	}
 
-	provider, accountIdentifier, err := r.resolveConnectionSnapshot(ctx, cmd.UserID, cmd.ConnectionID)
-	if err != nil {
-		return manualapp.WorkflowHistoryRef{}, err
+	// A sync-all parent has no connection of its own, so it keeps no mailbox snapshot.
+	provider, accountIdentifier := "", ""
+	if !cmd.FanOut {
+		var err error
+		provider, accountIdentifier, err = r.resolveConnectionSnapshot(ctx, cmd.UserID, cmd.ConnectionID)
+		if err != nil {
+			return manualapp.WorkflowHistoryRef{}, err
+		}
 	}
 
 	now := r.clock.Now().UTC()
 	record := manualMailWorkflowHistoryRecord{
 		WorkflowID:        strings.TrimSpace(cmd.WorkflowID),
+		RetryOfWorkflowID: optionalString(cmd.RetryOfWorkflowID),
+		ParentWorkflowID:  optionalString(cmd.ParentWorkflowID),
+		FanOut:            cmd.FanOut,
+		DryRun:            cmd.DryRun,
 		UserID:            cmd.UserID,
 		Provider:          provider,
 		AccountIdentifier: accountIdentifier,
 		LabelName:         strings.TrimSpace(cmd.LabelName),
 		SinceAt:           cmd.SinceAt.UTC(),
 		UntilAt:           cmd.UntilAt.UTC(),
+		FetchFilter:       toWorkflowFetchFilterColumn(cmd.Filter),
 		Status:            manualapp.WorkflowStatusQueued,
 		QueuedAt:          cmd.QueuedAt.UTC(),
 		CreatedAt:         now,
@@ -134,6 +181,9 @@ func (r *GormWorkflowStatusRepository) CreateQueued(ctx context.Context, cmd man
 		r.logDBError(ctx, "manual_mail_workflow_histories", "create", err)
 		return manualapp.WorkflowHistoryRef{}, fmt.Errorf("failed to create queued workflow history: %w", err)
 	}
+	if record.ParentWorkflowID != nil {
+		r.refreshFanOutParentOf(ctx, record.ID)
+	}
 
 	return manualapp.WorkflowHistoryRef{
 		HistoryID:  record.ID,
@@ -141,7 +191,8 @@ func (r *GormWorkflowStatusRepository) CreateQueued(ctx context.Context, cmd man
 	}, nil
 }
 
-// MarkRunning updates the workflow status/current stage when background execution advances.
+// MarkRunning updates the workflow status/current stage when background execution advances,
+// and records the time the workflow left the previous stage and entered this one.
 func (r *GormWorkflowStatusRepository) MarkRunning(ctx context.Context, historyID uint64, currentStage string) error {
 	if ctx == nil {
 		return logger.ErrNilContext
@@ -156,28 +207,47 @@ func (r *GormWorkflowStatusRepository) MarkRunning(ctx context.Context, historyI
 	}
 
 	now := r.clock.Now().UTC()
-	tx := r.db.WithContext(ctx).
-		Model(&manualMailWorkflowHistoryRecord{}).
-		Where("id = ?", historyID).
-		Updates(map[string]interface{}{
-			"status":        manualapp.WorkflowStatusRunning,
-			"current_stage": currentStage,
-			"error_message": nil,
-			"updated_at":    now,
-		})
-	if tx.Error != nil {
-		r.logDBError(ctx, "manual_mail_workflow_histories", "mark_running", tx.Error)
-		return fmt.Errorf("failed to mark workflow running: %w", tx.Error)
+	updated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updateResult := tx.Model(&manualMailWorkflowHistoryRecord{}).
+			Where("id = ? AND status IN ? AND cancel_requested_at IS NULL", historyID, []string{manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning}).
+			Updates(map[string]interface{}{
+				"status":        manualapp.WorkflowStatusRunning,
+				"current_stage": currentStage,
+				"error_message": nil,
+				"updated_at":    now,
+			})
+		if updateResult.Error != nil {
+			return updateResult.Error
+		}
+		if updateResult.RowsAffected == 0 {
+			return nil
+		}
+		updated = true
+		return startStageEvent(tx, historyID, currentStage, now)
+	})
+	if err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories/manual_mail_workflow_stage_events", "mark_running", err)
+		return fmt.Errorf("failed to mark workflow running: %w", err)
 	}
-	if tx.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+	if !updated {
+		cancelRequested, err := r.IsCancelRequested(ctx, historyID)
+		if err != nil {
+			return err
+		}
+		if cancelRequested {
+			return manualapp.ErrWorkflowCancelled
+		}
+		return r.finishedWorkflowConflict(ctx, historyID, "mark_running")
 	}
+	r.refreshFanOutParentOf(ctx, historyID)
 
 	return nil
 }
 
-// SaveStageProgress persists one stage summary and its append-only failure rows.
-func (r *GormWorkflowStatusRepository) SaveStageProgress(ctx context.Context, progress manualapp.StageProgress) error {
+// MarkCancelled finalizes a queued/running workflow as cancelled and keeps the stage where it stopped.
+// Workflows that already reached a terminal status are left untouched.
+func (r *GormWorkflowStatusRepository) MarkCancelled(ctx context.Context, historyID uint64, currentStage string, finishedAt time.Time) error {
 	if ctx == nil {
 		return logger.ErrNilContext
 	}
@@ -185,9 +255,193 @@ func (r *GormWorkflowStatusRepository) SaveStageProgress(ctx context.Context, pr
 		return fmt.Errorf("gorm db is not configured")
 	}
 
-	successColumn, businessFailureColumn, technicalFailureColumn, err := stageCountColumns(progress.Stage)
+	now := r.clock.Now().UTC()
+	finishedAt = finishedAt.UTC()
+	updates := map[string]interface{}{
+		"status":      manualapp.WorkflowStatusCancelled,
+		"finished_at": &finishedAt,
+		"updated_at":  now,
+	}
+	if currentStage = strings.TrimSpace(currentStage); currentStage != "" {
+		updates["current_stage"] = currentStage
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&manualMailWorkflowHistoryRecord{}).
+			Where("id = ? AND status IN ?", historyID, []string{manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning}).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		return finishOpenStageEvents(tx, []uint64{historyID}, now)
+	})
+	if err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories/manual_mail_workflow_stage_events", "mark_cancelled", err)
+		return fmt.Errorf("failed to mark workflow cancelled: %w", err)
+	}
+	r.refreshFanOutParentOf(ctx, historyID)
+
+	return nil
+}
+
+// RequestCancel cancels a queued workflow immediately and flags a running workflow so the runner stops it.
+func (r *GormWorkflowStatusRepository) RequestCancel(
+	ctx context.Context,
+	userID uint,
+	workflowID string,
+	requestedAt time.Time,
+) (manualapp.CancelResult, error) {
+	if ctx == nil {
+		return manualapp.CancelResult{}, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return manualapp.CancelResult{}, fmt.Errorf("gorm db is not configured")
+	}
+
+	requestedAt = requestedAt.UTC()
+	result := manualapp.CancelResult{WorkflowID: strings.TrimSpace(workflowID)}
+	var record manualMailWorkflowHistoryRecord
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND workflow_id = ?", userID, result.WorkflowID).
+			First(&record).Error; err != nil {
+			return err
+		}
+		if record.FanOut {
+			status, err := requestFanOutCancel(tx, record, requestedAt, r.clock.Now().UTC())
+			result.Status = status
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"updated_at": r.clock.Now().UTC(),
+		}
+		switch record.Status {
+		case manualapp.WorkflowStatusQueued:
+			updates["status"] = manualapp.WorkflowStatusCancelled
+			updates["finished_at"] = &requestedAt
+			updates["cancel_requested_at"] = &requestedAt
+			result.Status = manualapp.WorkflowStatusCancelled
+		case manualapp.WorkflowStatusRunning:
+			if record.CancelRequestedAt == nil {
+				updates["cancel_requested_at"] = &requestedAt
+			}
+			result.Status = manualapp.WorkflowStatusRunning
+		case manualapp.WorkflowStatusCancelled:
+			result.Status = manualapp.WorkflowStatusCancelled
+			return nil
+		default:
+			return manualapp.ErrWorkflowNotCancellable
+		}
+
+		return tx.Model(&manualMailWorkflowHistoryRecord{}).
+			Where("id = ?", record.ID).
+			Updates(updates).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return manualapp.CancelResult{}, manualapp.ErrWorkflowHistoryNotFound
+		case errors.Is(err, manualapp.ErrWorkflowNotCancellable):
+			return manualapp.CancelResult{}, err
+		}
+		r.logDBError(ctx, "manual_mail_workflow_histories", "request_cancel", err)
+		return manualapp.CancelResult{}, fmt.Errorf("failed to request workflow cancel: %w", err)
+	}
+	if record.ParentWorkflowID != nil {
+		r.refreshFanOutParentOf(ctx, record.ID)
+	}
+
+	return result, nil
+}
+
+// IsCancelRequested reports whether the user asked to stop the workflow.
+func (r *GormWorkflowStatusRepository) IsCancelRequested(ctx context.Context, historyID uint64) (bool, error) {
+	if ctx == nil {
+		return false, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return false, fmt.Errorf("gorm db is not configured")
+	}
+
+	var records []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Select("id", "status", "cancel_requested_at").
+		Where("id = ?", historyID).
+		Limit(1).
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_cancel_request", err)
+		return false, fmt.Errorf("failed to find workflow cancel request: %w", err)
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	return records[0].CancelRequestedAt != nil || records[0].Status == manualapp.WorkflowStatusCancelled, nil
+}
+
+// FindActiveOverlapping returns a queued or running workflow on the same mailbox that fetches any of the
+// query's labels in an overlapping period. A workflow's labels are its label_name and the include labels of its filter.
+// Dry runs are ignored because they do not write anything the new workflow could collide with.
+// Histories keep the mailbox snapshot instead of the connection ID, so the connection is resolved to it first.
+func (r *GormWorkflowStatusRepository) FindActiveOverlapping(ctx context.Context, query manualapp.ActiveWorkflowQuery) (string, bool, error) {
+	if ctx == nil {
+		return "", false, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return "", false, fmt.Errorf("gorm db is not configured")
+	}
+
+	provider, accountIdentifier, err := r.resolveConnectionSnapshot(ctx, query.UserID, query.ConnectionID)
 	if err != nil {
-		return err
+		return "", false, err
+	}
+
+	requested := make(map[string]struct{}, len(query.LabelNames))
+	for _, labelName := range query.LabelNames {
+		if labelName = strings.TrimSpace(labelName); labelName != "" {
+			requested[labelName] = struct{}{}
+		}
+	}
+	if len(requested) == 0 {
+		return "", false, nil
+	}
+
+	// The include labels live in a JSON column, so the few active workflows on the mailbox are matched here.
+	var records []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Select("id", "workflow_id", "label_name", "fetch_filter").
+		Where("user_id = ? AND provider = ? AND account_identifier = ?", query.UserID, provider, accountIdentifier).
+		Where("status IN ?", []string{manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning}).
+		Where("dry_run = ?", false).
+		Where("since_at < ? AND until_at > ?", query.Until.UTC(), query.Since.UTC()).
+		Order("queued_at ASC").
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_active_overlapping", err)
+		return "", false, fmt.Errorf("failed to find active overlapping workflow: %w", err)
+	}
+
+	for _, record := range records {
+		labelNames := append([]string{record.LabelName}, record.FetchFilter.toFetchFilter().IncludeLabelNames...)
+		for _, labelName := range labelNames {
+			if _, found := requested[labelName]; found {
+				return record.WorkflowID, true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
+// SaveStageProgress persists one stage summary, its append-only failure rows and the stage handoff.
+func (r *GormWorkflowStatusRepository) SaveStageProgress(ctx context.Context, progress manualapp.StageProgress) error {
+	if ctx == nil {
+		return logger.ErrNilContext
+	}
+	if r.db == nil {
+		return fmt.Errorf("gorm db is not configured")
+	}
+
+	if strings.TrimSpace(progress.Stage) == "" {
+		return fmt.Errorf("workflow stage is required")
 	}
 	totalFailureCount := progress.BusinessFailureCount + progress.TechnicalFailureCount
 	if countedFailureRecordCount(progress.FailureRecords) != totalFailureCount {
@@ -200,21 +454,38 @@ func (r *GormWorkflowStatusRepository) SaveStageProgress(ctx context.Context, pr
 	}
 
 	now := r.clock.Now().UTC()
-	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	successColumn, businessFailureColumn, technicalFailureColumn, hasHeaderColumns := stageCountColumns(progress.Stage)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"updated_at": now}
+		if hasHeaderColumns {
+			updates[successColumn] = progress.SuccessCount
+			updates[businessFailureColumn] = progress.BusinessFailureCount
+			updates[technicalFailureColumn] = progress.TechnicalFailureCount
+		}
 		updateResult := tx.Model(&manualMailWorkflowHistoryRecord{}).
 			Where("id = ?", progress.HistoryID).
-			Updates(map[string]interface{}{
-				successColumn:          progress.SuccessCount,
-				businessFailureColumn:  progress.BusinessFailureCount,
-				technicalFailureColumn: progress.TechnicalFailureCount,
-				"updated_at":           now,
-			})
+			Updates(updates)
 		if updateResult.Error != nil {
 			return updateResult.Error
 		}
 		if updateResult.RowsAffected == 0 {
 			return gorm.ErrRecordNotFound
 		}
+		if err := finishStageEvent(tx, progress.HistoryID, strings.TrimSpace(progress.Stage), now); err != nil {
+			return err
+		}
+		// pipeline に追加した stage は header に列を持たないため、stage ごとの行に件数を保存する。
+		if !hasHeaderColumns {
+			if err := saveAdditionalStageCount(tx, progress, now); err != nil {
+				return err
+			}
+		}
+
+		if progress.Handoff != nil {
+			if err := saveStageHandoff(tx, progress.HistoryID, *progress.Handoff, now); err != nil {
+				return err
+			}
+		}
 
 		if len(progress.FailureRecords) == 0 {
 			return nil
@@ -235,9 +506,10 @@ func (r *GormWorkflowStatusRepository) SaveStageProgress(ctx context.Context, pr
 		return tx.Create(&records).Error
 	})
 	if err != nil {
-		r.logDBError(ctx, "manual_mail_workflow_histories/manual_mail_workflow_stage_failures", "save_stage_progress", err)
+		r.logDBError(ctx, "manual_mail_workflow_histories/manual_mail_workflow_stage_counts/manual_mail_workflow_stage_events/manual_mail_workflow_stage_failures/manual_mail_workflow_stage_handoffs", "save_stage_progress", err)
 		return fmt.Errorf("failed to save workflow stage progress: %w", err)
 	}
+	r.refreshFanOutParentOf(ctx, progress.HistoryID)
 
 	return nil
 }
@@ -304,10 +576,23 @@ func (r *GormWorkflowStatusRepository) List(ctx context.Context, query manualapp
 		return manualapp.ListResult{}, fmt.Errorf("failed to list workflow stage failures: %w", failuresTx.Error)
 	}
 
+	stageCountsByHistory, err := r.findAdditionalStageCounts(ctx, historyIDs)
+	if err != nil {
+		return manualapp.ListResult{}, err
+	}
+
+	timelinesByHistory, err := r.findStageTimelines(ctx, historyIDs)
+	if err != nil {
+		return manualapp.ListResult{}, err
+	}
+
 	failureViewsByHistory := groupFailureViewsByHistory(failureRecords)
 	items := make([]manualapp.WorkflowHistoryListItem, 0, len(historyRecords))
 	for _, record := range historyRecords {
-		items = append(items, buildWorkflowHistoryListItem(record, failureViewsByHistory[record.ID]))
+		item := buildWorkflowHistoryListItem(record, failureViewsByHistory[record.ID])
+		item.AdditionalStages = additionalStageSummaryViews(stageCountsByHistory[record.ID], failureViewsByHistory[record.ID])
+		item.Timeline = stageTimelineOrEmpty(timelinesByHistory, record.ID)
+		items = append(items, item)
 	}
 
 	return manualapp.ListResult{
@@ -316,6 +601,134 @@ func (r *GormWorkflowStatusRepository) List(ctx context.Context, query manualapp
 	}, nil
 }
 
+// Detail loads one workflow history owned by the user with a filtered page of its failure rows.
+func (r *GormWorkflowStatusRepository) Detail(ctx context.Context, query manualapp.DetailQuery) (manualapp.WorkflowHistoryDetail, error) {
+	if ctx == nil {
+		return manualapp.WorkflowHistoryDetail{}, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return manualapp.WorkflowHistoryDetail{}, fmt.Errorf("gorm db is not configured")
+	}
+
+	var historyRecords []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND workflow_id = ?", query.UserID, query.WorkflowID).
+		Limit(1).
+		Find(&historyRecords).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_detail", err)
+		return manualapp.WorkflowHistoryDetail{}, fmt.Errorf("failed to find workflow history: %w", err)
+	}
+	if len(historyRecords) == 0 {
+		return manualapp.WorkflowHistoryDetail{}, manualapp.ErrWorkflowHistoryNotFound
+	}
+	record := historyRecords[0]
+
+	var failureTotalCount int64
+	countTx := r.buildFailureDetailQuery(ctx, record.ID, query).Count(&failureTotalCount)
+	if countTx.Error != nil {
+		r.logDBError(ctx, "manual_mail_workflow_stage_failures", "detail_count", countTx.Error)
+		return manualapp.WorkflowHistoryDetail{}, fmt.Errorf("failed to count workflow stage failures: %w", countTx.Error)
+	}
+
+	var failureRecords []manualMailWorkflowStageFailureRecord
+	pageTx := applyFailureSort(r.buildFailureDetailQuery(ctx, record.ID, query), query.Sort).
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&failureRecords)
+	if pageTx.Error != nil {
+		r.logDBError(ctx, "manual_mail_workflow_stage_failures", "detail_page", pageTx.Error)
+		return manualapp.WorkflowHistoryDetail{}, fmt.Errorf("failed to list workflow stage failures: %w", pageTx.Error)
+	}
+
+	failures := make([]manualapp.WorkflowStageFailureItem, 0, len(failureRecords))
+	for _, failure := range failureRecords {
+		failures = append(failures, manualapp.WorkflowStageFailureItem{
+			Stage:             failure.Stage,
+			ExternalMessageID: cloneOptionalString(failure.ExternalMessageID),
+			ReasonCode:        failure.ReasonCode,
+			Message:           failure.Message,
+			CreatedAt:         failure.CreatedAt.UTC(),
+		})
+	}
+
+	stageCountsByHistory, err := r.findAdditionalStageCounts(ctx, []uint64{record.ID})
+	if err != nil {
+		return manualapp.WorkflowHistoryDetail{}, err
+	}
+
+	timelinesByHistory, err := r.findStageTimelines(ctx, []uint64{record.ID})
+	if err != nil {
+		return manualapp.WorkflowHistoryDetail{}, err
+	}
+
+	detail := buildWorkflowHistoryDetail(record, failures, failureTotalCount)
+	detail.AdditionalStages = additionalStageCountViews(stageCountsByHistory[record.ID])
+	detail.Timeline = stageTimelineOrEmpty(timelinesByHistory, record.ID)
+	if record.FanOut {
+		children, err := r.findFanOutChildren(ctx, record)
+		if err != nil {
+			return manualapp.WorkflowHistoryDetail{}, err
+		}
+		detail.Children = children
+	}
+
+	return detail, nil
+}
+
+// FindProgress loads the status and per-stage counts of one workflow owned by the user.
+func (r *GormWorkflowStatusRepository) FindProgress(ctx context.Context, userID uint, workflowID string) (manualapp.WorkflowProgress, error) {
+	if ctx == nil {
+		return manualapp.WorkflowProgress{}, logger.ErrNilContext
+	}
+	if r.db == nil {
+		return manualapp.WorkflowProgress{}, fmt.Errorf("gorm db is not configured")
+	}
+
+	var records []manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND workflow_id = ?", userID, strings.TrimSpace(workflowID)).
+		Limit(1).
+		Find(&records).Error; err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories", "find_progress", err)
+		return manualapp.WorkflowProgress{}, fmt.Errorf("failed to find workflow progress: %w", err)
+	}
+	if len(records) == 0 {
+		return manualapp.WorkflowProgress{}, manualapp.ErrWorkflowHistoryNotFound
+	}
+
+	record := records[0]
+	stageCountsByHistory, err := r.findAdditionalStageCounts(ctx, []uint64{record.ID})
+	if err != nil {
+		return manualapp.WorkflowProgress{}, err
+	}
+
+	stages := workflowStageCounts(record)
+	for _, stageCount := range stageCountsByHistory[record.ID] {
+		stages[stageCount.Stage] = stageCountView(stageCount)
+	}
+	return manualapp.WorkflowProgress{
+		HistoryID:    record.ID,
+		WorkflowID:   record.WorkflowID,
+		Status:       record.Status,
+		CurrentStage: cloneOptionalString(record.CurrentStage),
+		ErrorMessage: cloneOptionalString(record.ErrorMessage),
+		UpdatedAt:    record.UpdatedAt.UTC(),
+		Stages:       stages,
+	}, nil
+}
+
+// workflowStageCounts maps the header count columns by stage name.
+func workflowStageCounts(record manualMailWorkflowHistoryRecord) map[string]manualapp.StageCountView {
+	detail := buildWorkflowHistoryDetail(record, nil, 0)
+	return map[string]manualapp.StageCountView{
+		"fetch":              detail.Fetch,
+		"analysis":           detail.Analysis,
+		"vendorresolution":   detail.VendorResolution,
+		"billingeligibility": detail.BillingEligibility,
+		"billing":            detail.Billing,
+	}
+}
+
 // Complete finalizes a workflow as succeeded or partial_success.
 func (r *GormWorkflowStatusRepository) Complete(ctx context.Context, historyID uint64, status string, finishedAt time.Time) error {
 	return r.updateTerminalStatus(ctx, historyID, status, nil, finishedAt, nil, "complete")
@@ -361,27 +774,63 @@ func (r *GormWorkflowStatusRepository) updateTerminalStatus(
 	now := r.clock.Now().UTC()
 	finishedAt = finishedAt.UTC()
 
-	tx := r.db.WithContext(ctx).
-		Model(&manualMailWorkflowHistoryRecord{}).
-		Where("id = ?", historyID).
-		Updates(map[string]interface{}{
-			"status":        status,
-			"current_stage": currentStage,
-			"finished_at":   &finishedAt,
-			"error_message": cloneOptionalString(errorMessage),
-			"updated_at":    now,
-		})
-	if tx.Error != nil {
-		r.logDBError(ctx, "manual_mail_workflow_histories", operation, tx.Error)
-		return fmt.Errorf("failed to %s workflow history: %w", operation, tx.Error)
+	updated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updateResult := tx.Model(&manualMailWorkflowHistoryRecord{}).
+			Where("id = ? AND status IN ?", historyID, []string{manualapp.WorkflowStatusQueued, manualapp.WorkflowStatusRunning}).
+			Updates(map[string]interface{}{
+				"status":        status,
+				"current_stage": currentStage,
+				"finished_at":   &finishedAt,
+				"error_message": cloneOptionalString(errorMessage),
+				"updated_at":    now,
+			})
+		if updateResult.Error != nil {
+			return updateResult.Error
+		}
+		if updateResult.RowsAffected == 0 {
+			return nil
+		}
+		updated = true
+		// A stage that ends the workflow without saving its progress (a failure or panic) stops here.
+		return finishOpenStageEvents(tx, []uint64{historyID}, finishedAt)
+	})
+	if err != nil {
+		r.logDBError(ctx, "manual_mail_workflow_histories/manual_mail_workflow_stage_events", operation, err)
+		return fmt.Errorf("failed to %s workflow history: %w", operation, err)
 	}
-	if tx.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+	if !updated {
+		return r.finishedWorkflowConflict(ctx, historyID, operation)
 	}
+	r.refreshFanOutParentOf(ctx, historyID)
 
 	return nil
 }
 
+// finishedWorkflowConflict explains why a status update matched no queued/running history.
+// A history that already reached a terminal status (for example one the reaper failed) is kept as it is.
+func (r *GormWorkflowStatusRepository) finishedWorkflowConflict(ctx context.Context, historyID uint64, operation string) error {
+	var record manualMailWorkflowHistoryRecord
+	if err := r.db.WithContext(ctx).Select("id", "status").Take(&record, historyID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		r.logDBError(ctx, "manual_mail_workflow_histories", operation, err)
+		return fmt.Errorf("failed to %s workflow history: %w", operation, err)
+	}
+
+	reqLog := r.log
+	if withContext, withCtxErr := r.log.WithContext(ctx); withCtxErr == nil {
+		reqLog = withContext
+	}
+	reqLog.Warn("manual_mail_workflow_status_update_skipped",
+		logger.Uint("history_id", uint(historyID)),
+		logger.String("operation", operation),
+		logger.String("status", record.Status),
+	)
+	return manualapp.ErrWorkflowAlreadyFinished
+}
+
 func (r *GormWorkflowStatusRepository) logDBError(ctx context.Context, table string, operation string, err error) {
 	reqLog := r.log
 	if withContext, withCtxErr := r.log.WithContext(ctx); withCtxErr == nil {
@@ -396,20 +845,21 @@ func (r *GormWorkflowStatusRepository) logDBError(ctx context.Context, table str
 	)
 }
 
-func stageCountColumns(stage string) (string, string, string, error) {
+// stageCountColumns returns the header count columns of a built-in stage; ok is false for an additional stage.
+func stageCountColumns(stage string) (string, string, string, bool) {
 	switch strings.TrimSpace(stage) {
 	case "fetch":
-		return "fetch_success_count", "fetch_business_failure_count", "fetch_technical_failure_count", nil
+		return "fetch_success_count", "fetch_business_failure_count", "fetch_technical_failure_count", true
 	case "analysis":
-		return "analysis_success_count", "analysis_business_failure_count", "analysis_technical_failure_count", nil
+		return "analysis_success_count", "analysis_business_failure_count", "analysis_technical_failure_count", true
 	case "vendorresolution":
-		return "vendor_resolution_success_count", "vendor_resolution_business_failure_count", "vendor_resolution_technical_failure_count", nil
+		return "vendor_resolution_success_count", "vendor_resolution_business_failure_count", "vendor_resolution_technical_failure_count", true
 	case "billingeligibility":
-		return "billing_eligibility_success_count", "billing_eligibility_business_failure_count", "billing_eligibility_technical_failure_count", nil
+		return "billing_eligibility_success_count", "billing_eligibility_business_failure_count", "billing_eligibility_technical_failure_count", true
 	case "billing":
-		return "billing_success_count", "billing_business_failure_count", "billing_technical_failure_count", nil
+		return "billing_success_count", "billing_business_failure_count", "billing_technical_failure_count", true
 	default:
-		return "", "", "", fmt.Errorf("unsupported workflow stage: %s", stage)
+		return "", "", "", false
 	}
 }
 
@@ -424,17 +874,106 @@ func (r *GormWorkflowStatusRepository) buildListBaseQuery(ctx context.Context, q
 	return tx
 }
 
+func (r *GormWorkflowStatusRepository) buildFailureDetailQuery(
+	ctx context.Context,
+	historyID uint64,
+	query manualapp.DetailQuery,
+) *gorm.DB {
+	tx := r.db.WithContext(ctx).
+		Model(&manualMailWorkflowStageFailureRecord{}).
+		Where("workflow_history_id = ?", historyID)
+	if query.Stage != nil {
+		tx = tx.Where("stage = ?", *query.Stage)
+	}
+	if query.ReasonCode != nil {
+		tx = tx.Where("reason_code = ?", *query.ReasonCode)
+	}
+
+	return tx
+}
+
+// applyFailureSort adds deterministic ordering because failure rows have no primary key.
+func applyFailureSort(tx *gorm.DB, sort string) *gorm.DB {
+	switch sort {
+	case manualapp.WorkflowFailureSortCreatedAtDesc:
+		tx = tx.Order("created_at DESC")
+	case manualapp.WorkflowFailureSortReasonCodeAsc:
+		tx = tx.Order("reason_code ASC").Order("created_at ASC")
+	default:
+		tx = tx.Order("created_at ASC")
+	}
+
+	return tx.Order("stage ASC").Order("external_message_id ASC")
+}
+
+func buildWorkflowHistoryDetail(
+	record manualMailWorkflowHistoryRecord,
+	failures []manualapp.WorkflowStageFailureItem,
+	failureTotalCount int64,
+) manualapp.WorkflowHistoryDetail {
+	return manualapp.WorkflowHistoryDetail{
+		WorkflowID:        record.WorkflowID,
+		RetryOfWorkflowID: cloneOptionalString(record.RetryOfWorkflowID),
+		ParentWorkflowID:  cloneOptionalString(record.ParentWorkflowID),
+		FanOut:            record.FanOut,
+		DryRun:            record.DryRun,
+		Provider:          record.Provider,
+		AccountIdentifier: record.AccountIdentifier,
+		LabelName:         record.LabelName,
+		Since:             record.SinceAt.UTC(),
+		Until:             record.UntilAt.UTC(),
+		Filter:            record.FetchFilter.toFetchFilter(),
+		Status:            record.Status,
+		CurrentStage:      cloneOptionalString(record.CurrentStage),
+		QueuedAt:          record.QueuedAt.UTC(),
+		FinishedAt:        cloneOptionalTime(record.FinishedAt),
+		ErrorMessage:      cloneOptionalString(record.ErrorMessage),
+		Fetch: manualapp.StageCountView{
+			SuccessCount:          record.FetchSuccessCount,
+			BusinessFailureCount:  record.FetchBusinessFailureCount,
+			TechnicalFailureCount: record.FetchTechnicalFailureCount,
+		},
+		Analysis: manualapp.StageCountView{
+			SuccessCount:          record.AnalysisSuccessCount,
+			BusinessFailureCount:  record.AnalysisBusinessFailureCount,
+			TechnicalFailureCount: record.AnalysisTechnicalFailureCount,
+		},
+		VendorResolution: manualapp.StageCountView{
+			SuccessCount:          record.VendorResolutionSuccessCount,
+			BusinessFailureCount:  record.VendorResolutionBusinessFailureCount,
+			TechnicalFailureCount: record.VendorResolutionTechnicalFailureCount,
+		},
+		BillingEligibility: manualapp.StageCountView{
+			SuccessCount:          record.BillingEligibilitySuccessCount,
+			BusinessFailureCount:  record.BillingEligibilityBusinessFailureCount,
+			TechnicalFailureCount: record.BillingEligibilityTechnicalFailureCount,
+		},
+		Billing: manualapp.StageCountView{
+			SuccessCount:          record.BillingSuccessCount,
+			BusinessFailureCount:  record.BillingBusinessFailureCount,
+			TechnicalFailureCount: record.BillingTechnicalFailureCount,
+		},
+		Failures:          failures,
+		FailureTotalCount: failureTotalCount,
+	}
+}
+
 func buildWorkflowHistoryListItem(
 	record manualMailWorkflowHistoryRecord,
 	failuresByStage map[string][]manualapp.StageFailureView,
 ) manualapp.WorkflowHistoryListItem {
 	return manualapp.WorkflowHistoryListItem{
 		WorkflowID:        record.WorkflowID,
+		RetryOfWorkflowID: cloneOptionalString(record.RetryOfWorkflowID),
+		ParentWorkflowID:  cloneOptionalString(record.ParentWorkflowID),
+		FanOut:            record.FanOut,
+		DryRun:            record.DryRun,
 		Provider:          record.Provider,
 		AccountIdentifier: record.AccountIdentifier,
 		LabelName:         record.LabelName,
 		Since:             record.SinceAt.UTC(),
 		Until:             record.UntilAt.UTC(),
+		Filter:            record.FetchFilter.toFetchFilter(),
 		Status:            record.Status,
 		CurrentStage:      cloneOptionalString(record.CurrentStage),
 		QueuedAt:          record.QueuedAt.UTC(),
@@ -540,6 +1079,21 @@ func cloneOptionalString(value *string) *string {
 	return &cloned
 }
 
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
 func cloneOptionalTime(value *time.Time) *time.Time {
 	if value == nil {
 		return nil
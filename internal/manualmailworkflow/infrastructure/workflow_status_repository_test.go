@@ -46,6 +46,14 @@ func newWorkflowStatusRepoTestEnv(t *testing.T) *workflowStatusRepoTestEnv {
 		&emailCredentialSnapshotRecord{},
 		&manualMailWorkflowHistoryRecord{},
 		&manualMailWorkflowStageFailureRecord{},
+		&manualMailWorkflowStageCountRecord{},
+		&manualMailWorkflowStageEventRecord{},
+		&emailSnapshotRecord{},
+		&parsedEmailSnapshotRecord{},
+		&manualMailWorkflowStageHandoffRecord{},
+		&manualMailWorkflowJobRecord{},
+		&manualMailWorkflowPreviewRecord{},
+		&billingIdentitySnapshotRecord{},
 	))
 
 	nowUTC := time.Date(2026, 3, 25, 15, 0, 0, 0, time.UTC)
@@ -201,6 +209,125 @@ func TestGormWorkflowStatusRepository_SaveStageProgress_AllowsNonCountedSkipReco
 	require.Equal(t, "対象メールはすでに取得済みだったため、後続の処理をスキップしました。", failures[0].Message)
 }
 
+func TestGormWorkflowStatusRepository_SaveStageProgress_StoresAdditionalStageCounts(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{
+		ID:           23,
+		UserID:       12,
+		Type:         "gmail",
+		GmailAddress: "pipeline@example.com",
+	})
+	ref, err := env.repo.CreateQueued(ctx, manualapp.QueuedWorkflowHistory{
+		WorkflowID:   "wf-additional-stage",
+		UserID:       12,
+		ConnectionID: 23,
+		LabelName:    "billing",
+		SinceAt:      time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+		UntilAt:      time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		QueuedAt:     time.Date(2026, 3, 25, 14, 56, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	progress := manualapp.StageProgress{
+		HistoryID:            ref.HistoryID,
+		Stage:                "categorization",
+		SuccessCount:         1,
+		BusinessFailureCount: 1,
+		FailureRecords: []manualapp.StageFailureRecord{
+			{Stage: "categorization", ReasonCode: "category_unknown", Message: "分類できませんでした。"},
+		},
+	}
+	require.NoError(t, env.repo.SaveStageProgress(ctx, progress))
+	progress.SuccessCount = 2
+	require.NoError(t, env.repo.SaveStageProgress(ctx, progress))
+
+	var counts []manualMailWorkflowStageCountRecord
+	require.NoError(t, env.db.WithContext(ctx).Where("workflow_history_id = ?", ref.HistoryID).Find(&counts).Error)
+	require.Len(t, counts, 1)
+	require.Equal(t, 2, counts[0].SuccessCount)
+
+	snapshot, err := env.repo.FindProgress(ctx, 12, "wf-additional-stage")
+	require.NoError(t, err)
+	require.Equal(t, manualapp.StageCountView{SuccessCount: 2, BusinessFailureCount: 1}, snapshot.Stages["categorization"])
+
+	detail, err := env.repo.Detail(ctx, manualapp.DetailQuery{UserID: 12, WorkflowID: "wf-additional-stage", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []manualapp.AdditionalStageCountView{
+		{Stage: "categorization", StageCountView: manualapp.StageCountView{SuccessCount: 2, BusinessFailureCount: 1}},
+	}, detail.AdditionalStages)
+
+	list, err := env.repo.List(ctx, manualapp.ListQuery{UserID: 12, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Len(t, list.Items[0].AdditionalStages, 1)
+	require.Equal(t, "categorization", list.Items[0].AdditionalStages[0].Stage)
+	require.Len(t, list.Items[0].AdditionalStages[0].Failures, 2)
+
+	require.Error(t, env.repo.SaveStageProgress(ctx, manualapp.StageProgress{HistoryID: ref.HistoryID, Stage: " "}))
+}
+
+func TestGormWorkflowStatusRepository_RecordsStageTimeline(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	clock := &workflowStatusRepoFixedClock{now: env.nowUTC}
+	repo := NewGormWorkflowStatusRepository(env.db, clock, logger.NewNop())
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{
+		ID:           24,
+		UserID:       13,
+		Type:         "gmail",
+		GmailAddress: "timeline@example.com",
+	})
+	ref, err := repo.CreateQueued(ctx, manualapp.QueuedWorkflowHistory{
+		WorkflowID:   "wf-stage-timeline",
+		UserID:       13,
+		ConnectionID: 24,
+		LabelName:    "billing",
+		SinceAt:      time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+		UntilAt:      time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		QueuedAt:     env.nowUTC,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.MarkRunning(ctx, ref.HistoryID, "fetch"))
+	clock.now = env.nowUTC.Add(2 * time.Second)
+	require.NoError(t, repo.SaveStageProgress(ctx, manualapp.StageProgress{HistoryID: ref.HistoryID, Stage: "fetch", SuccessCount: 1}))
+	clock.now = env.nowUTC.Add(3 * time.Second)
+	require.NoError(t, repo.MarkRunning(ctx, ref.HistoryID, "analysis"))
+
+	detail, err := repo.Detail(ctx, manualapp.DetailQuery{UserID: 13, WorkflowID: "wf-stage-timeline", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, detail.Timeline, 2)
+	require.Equal(t, "fetch", detail.Timeline[0].Stage)
+	require.NotNil(t, detail.Timeline[0].DurationMillis)
+	require.Equal(t, int64(2000), *detail.Timeline[0].DurationMillis)
+	require.Equal(t, "analysis", detail.Timeline[1].Stage)
+	require.True(t, detail.Timeline[1].StartedAt.Equal(env.nowUTC.Add(3*time.Second)))
+	require.Nil(t, detail.Timeline[1].FinishedAt)
+	require.Nil(t, detail.Timeline[1].DurationMillis)
+
+	// A stage that fails without saving its progress ends when the workflow does.
+	finishedAt := env.nowUTC.Add(10 * time.Second)
+	clock.now = finishedAt
+	require.NoError(t, repo.Fail(ctx, ref.HistoryID, "analysis", finishedAt, "openai unavailable"))
+
+	list, err := repo.List(ctx, manualapp.ListQuery{UserID: 13, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Len(t, list.Items[0].Timeline, 2)
+	require.NotNil(t, list.Items[0].Timeline[1].FinishedAt)
+	require.True(t, list.Items[0].Timeline[1].FinishedAt.Equal(finishedAt))
+	require.Equal(t, int64(7000), *list.Items[0].Timeline[1].DurationMillis)
+}
+
 func TestGormWorkflowStatusRepository_Fail(t *testing.T) {
 	t.Parallel()
 
@@ -241,6 +368,119 @@ func TestGormWorkflowStatusRepository_Fail(t *testing.T) {
 	require.Equal(t, "failed to create gmail service: invalid_grant", *history.ErrorMessage)
 }
 
+func TestGormWorkflowStatusRepository_LateCompleteKeepsReapedFailure(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{
+		ID:           2,
+		UserID:       1,
+		Type:         "gmail",
+		GmailAddress: "reaped@example.com",
+	})
+	ref, err := env.repo.CreateQueued(ctx, manualapp.QueuedWorkflowHistory{
+		WorkflowID:   "01JQ0B7N0M7H3X9C2J5K8V6R1",
+		UserID:       1,
+		ConnectionID: 2,
+		LabelName:    "billing",
+		SinceAt:      time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC),
+		UntilAt:      time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC),
+		QueuedAt:     time.Date(2026, 3, 25, 14, 55, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.NoError(t, env.repo.MarkRunning(ctx, ref.HistoryID, "analysis"))
+
+	// The reaper fails the stalled workflow before the original runner finishes.
+	reapedAt := time.Date(2026, 3, 25, 16, 0, 0, 0, time.UTC)
+	require.NoError(t, env.repo.Fail(ctx, ref.HistoryID, "analysis", reapedAt, workflowJobReapedMessage))
+
+	lateFinishedAt := time.Date(2026, 3, 25, 16, 5, 0, 0, time.UTC)
+	err = env.repo.Complete(ctx, ref.HistoryID, manualapp.WorkflowStatusSucceeded, lateFinishedAt)
+	require.ErrorIs(t, err, manualapp.ErrWorkflowAlreadyFinished)
+	err = env.repo.MarkRunning(ctx, ref.HistoryID, "billing")
+	require.ErrorIs(t, err, manualapp.ErrWorkflowAlreadyFinished)
+	err = env.repo.Fail(ctx, ref.HistoryID, "billing", lateFinishedAt, "late failure")
+	require.ErrorIs(t, err, manualapp.ErrWorkflowAlreadyFinished)
+
+	var history manualMailWorkflowHistoryRecord
+	require.NoError(t, env.db.WithContext(ctx).First(&history, ref.HistoryID).Error)
+	require.Equal(t, manualapp.WorkflowStatusFailed, history.Status)
+	require.NotNil(t, history.CurrentStage)
+	require.Equal(t, "analysis", *history.CurrentStage)
+	require.NotNil(t, history.FinishedAt)
+	require.True(t, history.FinishedAt.Equal(reapedAt))
+	require.NotNil(t, history.ErrorMessage)
+	require.Equal(t, workflowJobReapedMessage, *history.ErrorMessage)
+
+	err = env.repo.Complete(ctx, ref.HistoryID+1000, manualapp.WorkflowStatusSucceeded, lateFinishedAt)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGormWorkflowStatusRepository_RequestCancel_RunningWorkflowStopsAtCurrentStage(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	history := workflowHistoryRecordFixture(1, "wf-running", env.nowUTC, manualapp.WorkflowStatusRunning)
+	history.CurrentStage = stringPtr("analysis")
+	history.FetchSuccessCount = 3
+	require.NoError(t, env.db.Create(&history).Error)
+
+	result, err := env.repo.RequestCancel(ctx, 1, "wf-running", env.nowUTC)
+	require.NoError(t, err)
+	require.Equal(t, manualapp.WorkflowStatusRunning, result.Status)
+
+	cancelRequested, err := env.repo.IsCancelRequested(ctx, history.ID)
+	require.NoError(t, err)
+	require.True(t, cancelRequested)
+	require.ErrorIs(t, env.repo.MarkRunning(ctx, history.ID, "vendorresolution"), manualapp.ErrWorkflowCancelled)
+
+	finishedAt := env.nowUTC.Add(time.Minute)
+	require.NoError(t, env.repo.MarkCancelled(ctx, history.ID, "analysis", finishedAt))
+
+	var stored manualMailWorkflowHistoryRecord
+	require.NoError(t, env.db.First(&stored, history.ID).Error)
+	require.Equal(t, manualapp.WorkflowStatusCancelled, stored.Status)
+	require.NotNil(t, stored.CurrentStage)
+	require.Equal(t, "analysis", *stored.CurrentStage)
+	require.Equal(t, 3, stored.FetchSuccessCount)
+	require.NotNil(t, stored.FinishedAt)
+	require.True(t, stored.FinishedAt.Equal(finishedAt))
+}
+
+func TestGormWorkflowStatusRepository_RequestCancel_QueuedAndFinishedWorkflows(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	queued := workflowHistoryRecordFixture(1, "wf-queued", env.nowUTC, manualapp.WorkflowStatusQueued)
+	require.NoError(t, env.db.Create(&queued).Error)
+	succeeded := workflowHistoryRecordFixture(1, "wf-succeeded", env.nowUTC, manualapp.WorkflowStatusSucceeded)
+	require.NoError(t, env.db.Create(&succeeded).Error)
+
+	result, err := env.repo.RequestCancel(ctx, 1, "wf-queued", env.nowUTC)
+	require.NoError(t, err)
+	require.Equal(t, manualapp.WorkflowStatusCancelled, result.Status)
+
+	var stored manualMailWorkflowHistoryRecord
+	require.NoError(t, env.db.First(&stored, queued.ID).Error)
+	require.Equal(t, manualapp.WorkflowStatusCancelled, stored.Status)
+	require.NotNil(t, stored.FinishedAt)
+
+	_, err = env.repo.RequestCancel(ctx, 1, "wf-succeeded", env.nowUTC)
+	require.ErrorIs(t, err, manualapp.ErrWorkflowNotCancellable)
+
+	_, err = env.repo.RequestCancel(ctx, 2, "wf-queued", env.nowUTC)
+	require.ErrorIs(t, err, manualapp.ErrWorkflowHistoryNotFound)
+}
+
 func TestGormWorkflowStatusRepository_List(t *testing.T) {
 	t.Parallel()
 
@@ -396,6 +636,158 @@ func TestGormWorkflowStatusRepository_List_WithStatusFilterAndEmptyPage(t *testi
 	require.Equal(t, 2, queryCount)
 }
 
+func TestGormWorkflowStatusRepository_Detail_FiltersAndPaginatesFailures(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	queuedAt := time.Date(2026, 3, 25, 14, 0, 0, 0, time.UTC)
+	history := workflowHistoryRecordFixture(10, "wf-detail", queuedAt, manualapp.WorkflowStatusPartialSuccess)
+	history.FetchSuccessCount = 5
+	history.FetchTechnicalFailureCount = 3
+	history.AnalysisSuccessCount = 4
+	history.AnalysisTechnicalFailureCount = 1
+	otherUserHistory := workflowHistoryRecordFixture(20, "wf-other-user", queuedAt, manualapp.WorkflowStatusFailed)
+	require.NoError(t, env.db.WithContext(ctx).Create(&history).Error)
+	require.NoError(t, env.db.WithContext(ctx).Create(&otherUserHistory).Error)
+
+	failures := []manualMailWorkflowStageFailureRecord{
+		{WorkflowHistoryID: history.ID, Stage: "fetch", ExternalMessageID: stringPtr("msg-1"), ReasonCode: "fetch_detail_failed", Message: "メールの取得に失敗しました。", CreatedAt: queuedAt.Add(1 * time.Minute)},
+		{WorkflowHistoryID: history.ID, Stage: "fetch", ExternalMessageID: stringPtr("msg-2"), ReasonCode: "email_save_failed", Message: "メールの保存に失敗しました。", CreatedAt: queuedAt.Add(2 * time.Minute)},
+		{WorkflowHistoryID: history.ID, Stage: "fetch", ExternalMessageID: stringPtr("msg-3"), ReasonCode: "fetch_detail_failed", Message: "メールの取得に失敗しました。", CreatedAt: queuedAt.Add(3 * time.Minute)},
+		{WorkflowHistoryID: history.ID, Stage: "analysis", ExternalMessageID: stringPtr("msg-4"), ReasonCode: "analysis_failed", Message: "メール解析に失敗しました。", CreatedAt: queuedAt.Add(4 * time.Minute)},
+	}
+	require.NoError(t, env.db.WithContext(ctx).Create(&failures).Error)
+
+	detail, err := env.repo.Detail(ctx, manualapp.DetailQuery{
+		UserID:     10,
+		WorkflowID: "wf-detail",
+		Stage:      stringPtr("fetch"),
+		ReasonCode: stringPtr("fetch_detail_failed"),
+		Sort:       manualapp.WorkflowFailureSortCreatedAtDesc,
+		Limit:      1,
+		Offset:     0,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "wf-detail", detail.WorkflowID)
+	require.Equal(t, 5, detail.Fetch.SuccessCount)
+	require.Equal(t, 3, detail.Fetch.TechnicalFailureCount)
+	require.Equal(t, 1, detail.Analysis.TechnicalFailureCount)
+	require.EqualValues(t, 2, detail.FailureTotalCount)
+	require.Len(t, detail.Failures, 1)
+	require.Equal(t, "fetch", detail.Failures[0].Stage)
+	require.Equal(t, "msg-3", *detail.Failures[0].ExternalMessageID)
+
+	secondPage, err := env.repo.Detail(ctx, manualapp.DetailQuery{
+		UserID:     10,
+		WorkflowID: "wf-detail",
+		Sort:       manualapp.WorkflowFailureSortCreatedAtAsc,
+		Limit:      2,
+		Offset:     2,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 4, secondPage.FailureTotalCount)
+	require.Len(t, secondPage.Failures, 2)
+	require.Equal(t, "msg-3", *secondPage.Failures[0].ExternalMessageID)
+	require.Equal(t, "analysis", secondPage.Failures[1].Stage)
+
+	_, err = env.repo.Detail(ctx, manualapp.DetailQuery{
+		UserID:     10,
+		WorkflowID: "wf-other-user",
+		Sort:       manualapp.WorkflowFailureSortCreatedAtAsc,
+		Limit:      10,
+	})
+	require.ErrorIs(t, err, manualapp.ErrWorkflowHistoryNotFound)
+}
+
+func TestGormWorkflowStatusRepository_FindActiveOverlapping(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	mustCreateCredentialSnapshot(t, env.db, emailCredentialSnapshotRecord{
+		ID:           30,
+		UserID:       10,
+		Type:         "gmail",
+		GmailAddress: "billing@example.com",
+	})
+	queuedAt := time.Date(2026, 3, 25, 14, 0, 0, 0, time.UTC)
+	running := workflowHistoryRecordFixture(10, "01JQ0B7N0M7H3X9C2J5K8V6P4", queuedAt, manualapp.WorkflowStatusRunning)
+	finished := workflowHistoryRecordFixture(10, "01JQ0B7N0M7H3X9C2J5K8V6P5", queuedAt.Add(-time.Hour), manualapp.WorkflowStatusSucceeded)
+	otherLabel := workflowHistoryRecordFixture(10, "01JQ0B7N0M7H3X9C2J5K8V6P6", queuedAt, manualapp.WorkflowStatusQueued)
+	otherLabel.LabelName = "invoice"
+	otherLabel.FetchFilter = &workflowFetchFilterColumn{IncludeLabelNames: []string{"receipts"}}
+	require.NoError(t, env.db.WithContext(ctx).Create([]manualMailWorkflowHistoryRecord{running, finished, otherLabel}).Error)
+
+	query := manualapp.ActiveWorkflowQuery{
+		UserID:       10,
+		ConnectionID: 30,
+		LabelNames:   []string{"billing"},
+		Since:        time.Date(2026, 3, 24, 12, 0, 0, 0, time.UTC),
+		Until:        time.Date(2026, 3, 26, 0, 0, 0, 0, time.UTC),
+	}
+	workflowID, found, err := env.repo.FindActiveOverlapping(ctx, query)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, running.WorkflowID, workflowID)
+
+	// [since, until) が接するだけの期間は重複とみなさない。
+	query.Since = time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)
+	_, found, err = env.repo.FindActiveOverlapping(ctx, query)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	query.Since = time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC)
+	query.LabelNames = []string{"archive"}
+	_, found, err = env.repo.FindActiveOverlapping(ctx, query)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	// include ラベルも含めたラベル集合のどれかが重なれば重複とみなす。
+	query.LabelNames = []string{"archive", "receipts"}
+	workflowID, found, err = env.repo.FindActiveOverlapping(ctx, query)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, otherLabel.WorkflowID, workflowID)
+
+	query.LabelNames = []string{"promotions", "billing"}
+	workflowID, found, err = env.repo.FindActiveOverlapping(ctx, query)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, running.WorkflowID, workflowID)
+}
+
+func TestGormWorkflowStatusRepository_FindProgress(t *testing.T) {
+	t.Parallel()
+
+	env := newWorkflowStatusRepoTestEnv(t)
+	defer env.clean()
+
+	ctx := context.Background()
+	history := workflowHistoryRecordFixture(1, "wf-progress", env.nowUTC, manualapp.WorkflowStatusRunning)
+	history.CurrentStage = stringPtr("analysis")
+	history.FetchSuccessCount = 4
+	history.AnalysisTechnicalFailureCount = 1
+	require.NoError(t, env.db.Create(&history).Error)
+
+	progress, err := env.repo.FindProgress(ctx, 1, "wf-progress")
+	require.NoError(t, err)
+	require.Equal(t, history.ID, progress.HistoryID)
+	require.Equal(t, manualapp.WorkflowStatusRunning, progress.Status)
+	require.NotNil(t, progress.CurrentStage)
+	require.Equal(t, "analysis", *progress.CurrentStage)
+	require.Equal(t, manualapp.StageCountView{SuccessCount: 4}, progress.Stages["fetch"])
+	require.Equal(t, manualapp.StageCountView{TechnicalFailureCount: 1}, progress.Stages["analysis"])
+	require.Len(t, progress.Stages, 5)
+
+	_, err = env.repo.FindProgress(ctx, 2, "wf-progress")
+	require.ErrorIs(t, err, manualapp.ErrWorkflowHistoryNotFound)
+}
+
 func workflowHistoryRecordFixture(userID uint, workflowID string, queuedAt time.Time, status string) manualMailWorkflowHistoryRecord {
 	return manualMailWorkflowHistoryRecord{
 		WorkflowID:        workflowID,
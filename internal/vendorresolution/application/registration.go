@@ -8,12 +8,14 @@ import (
 )
 
 // ensureVendorByCandidateName は unresolved のときだけ policy の登録計画に従って master を補完する。
+// dry-run では登録せず、登録される予定の vendor を ID なしで解決済みとして扱う。
 func (uc *useCase) ensureVendorByCandidateName(
 	ctx context.Context,
 	userID uint,
 	target ResolutionTarget,
 	input commondomain.VendorResolutionInput,
 	decision domain.ResolutionDecision,
+	dryRun bool,
 	reqLog logger.Interface,
 ) (domain.ResolutionDecision, error) {
 	plan := uc.policy.BuildRegistrationPlan(input, decision)
@@ -22,6 +24,15 @@ func (uc *useCase) ensureVendorByCandidateName(
 	}
 	plan.UserID = userID
 
+	if dryRun {
+		reqLog.Info("vendor_resolution_dry_run_would_register",
+			logger.UserID(userID),
+			logger.String("external_message_id", target.ExternalMessageID),
+			logger.String("candidate_vendor_name", plan.VendorName),
+		)
+		return uc.policy.ResolveRegisteredVendor(commondomain.Vendor{UserID: userID, Name: plan.VendorName}), nil
+	}
+
 	vendor, err := uc.registrationRepository.EnsureByPlan(ctx, *plan)
 	if err != nil {
 		return domain.ResolutionDecision{}, err
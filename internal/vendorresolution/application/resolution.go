@@ -10,7 +10,7 @@ import (
 )
 
 // resolveTarget は repository で材料を集め、policy で 1 回の最終判定を行う。
-func (uc *useCase) resolveTarget(ctx context.Context, userID uint, target ResolutionTarget, reqLog logger.Interface) (domain.ResolutionDecision, *domain.Failure, error) {
+func (uc *useCase) resolveTarget(ctx context.Context, userID uint, target ResolutionTarget, dryRun bool, reqLog logger.Interface) (domain.ResolutionDecision, *domain.Failure, error) {
 	input := buildVendorResolutionInput(target)
 	plan := uc.policy.BuildFetchPlan(input)
 	plan.UserID = userID
@@ -25,7 +25,7 @@ func (uc *useCase) resolveTarget(ctx context.Context, userID uint, target Resolu
 		return decision, nil, nil
 	}
 
-	decision, err = uc.ensureVendorByCandidateName(ctx, userID, target, input, decision, reqLog)
+	decision, err = uc.ensureVendorByCandidateName(ctx, userID, target, input, decision, dryRun, reqLog)
 	if err != nil {
 		return domain.ResolutionDecision{}, newFailure(target, domain.FailureStageRegisterVendor, domain.FailureCodeVendorRegisterFail, messageForResolutionFailure(target, domain.FailureCodeVendorRegisterFail)), err
 	}
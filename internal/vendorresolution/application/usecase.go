@@ -58,6 +58,9 @@ func (t ResolutionTarget) Validate() error {
 type Command struct {
 	UserID       uint
 	ParsedEmails []ResolutionTarget
+	// DryRun は vendor の自動登録を行わず、登録計画が立つ候補を VendorID 0 の解決済みとして返す。
+	// 未保存の ParsedEmail を受け取るため ID の必須チェックも行わない。
+	DryRun bool
 }
 
 // Result は vendorresolution stage の出力。
@@ -119,7 +122,7 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 	for _, target := range cmd.ParsedEmails {
 		// workflow から渡された値をここで最終整形し、policy と repository に揺れの少ない入力だけを渡す。
 		target = target.Normalize()
-		if err := target.Validate(); err != nil {
+		if err := validateTarget(target, cmd.DryRun); err != nil {
 			result.Failures = append(result.Failures, domain.Failure{
 				ParsedEmailID:     target.ParsedEmailID,
 				EmailID:           target.EmailID,
@@ -131,7 +134,7 @@ func (uc *useCase) Execute(ctx context.Context, cmd Command) (Result, error) {
 			continue
 		}
 
-		decision, failure, err := uc.resolveTarget(ctx, cmd.UserID, target, reqLog)
+		decision, failure, err := uc.resolveTarget(ctx, cmd.UserID, target, cmd.DryRun, reqLog)
 		if err != nil {
 			// read/write repository の内部エラーは全体失敗にせず、対象メール単位の failure として集約する。
 			result.Failures = append(result.Failures, *failure)
@@ -203,6 +206,14 @@ func (uc *useCase) validateDependencies() error {
 	return nil
 }
 
+// validateTarget は dry-run では保存前の入力も受け付けるため ID の検証を省く。
+func validateTarget(target ResolutionTarget, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	return target.Validate()
+}
+
 // validateCommand は stage 全体として成立する最低条件だけを検証する。
 func validateCommand(cmd Command) error {
 	if cmd.UserID == 0 {
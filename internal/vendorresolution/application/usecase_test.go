@@ -318,6 +318,50 @@ func TestUseCaseExecute_AutoRegisterFailureBecomesFailure(t *testing.T) {
 	}
 }
 
+// 観点:
+// - dry-run では EnsureByPlan を呼ばず、登録予定の vendor 名を VendorID 0 で返すこと
+// - 保存前の入力のため ParsedEmailID / EmailID が 0 でも解決対象になること
+func TestUseCaseExecute_DryRunDoesNotRegisterVendor(t *testing.T) {
+	t.Parallel()
+
+	uc := NewUseCase(
+		&stubVendorResolutionRepository{
+			fetchFacts: func(ctx context.Context, plan domain.VendorResolutionFetchPlan) (domain.VendorResolutionFacts, error) {
+				return domain.VendorResolutionFacts{}, nil
+			},
+		},
+		&stubVendorRegistrationRepository{
+			ensureByPlan: func(ctx context.Context, plan domain.VendorRegistrationPlan) (*commondomain.Vendor, error) {
+				t.Fatal("vendor must not be registered for a dry run")
+				return nil, nil
+			},
+		},
+		logger.NewNop(),
+	)
+
+	result, err := uc.Execute(context.Background(), Command{
+		UserID: 1,
+		ParsedEmails: []ResolutionTarget{
+			{
+				ExternalMessageID: "msg-1",
+				ParsedEmail:       commondomain.ParsedEmail{VendorName: stringPtr("Acme")},
+			},
+		},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if result.ResolvedCount != 1 || len(result.Failures) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	item := result.ResolvedItems[0]
+	if item.VendorID != 0 || item.VendorName != "Acme" || item.MatchedBy != domain.MatchedByNameExact {
+		t.Fatalf("unexpected resolved item: %+v", item)
+	}
+}
+
 func aliasCandidate(aliasID uint, aliasType, normalizedValue string, vendor commondomain.Vendor, createdAt time.Time) commondomain.VendorAliasCandidate {
 	return commondomain.VendorAliasCandidate{
 		AliasID:         aliasID,
@@ -120,7 +120,10 @@ func newDashboardSummaryScenarioEnv(t *testing.T) *dashboardSummaryScenarioEnv {
 		return macpresentation.NewController(nil, log)
 	}))
 	require.NoError(t, container.Provide(func() *manualpresentation.Controller {
-		return manualpresentation.NewController(nil, nil, log)
+		return manualpresentation.NewController(nil, nil, nil, nil, nil, nil, nil, nil, log)
+	}))
+	require.NoError(t, container.Provide(func() *manualpresentation.ScheduleController {
+		return manualpresentation.NewScheduleController(nil, log)
 	}))
 	require.NoError(t, container.Provide(func() *billingpresentation.Controller {
 		return billingpresentation.NewController(nil, nil, nil, log)
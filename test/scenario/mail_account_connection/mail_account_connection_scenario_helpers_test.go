@@ -15,6 +15,7 @@ import (
 	dashboardqueryapp "business/internal/dashboardquery/application"
 	"business/internal/library/crypto"
 	"business/internal/library/gmailService"
+	"business/internal/library/imap"
 	"business/internal/library/logger"
 	"business/internal/library/mysql"
 	macapp "business/internal/mailaccountconnection/application"
@@ -137,7 +138,7 @@ func newMailAccountConnectionScenarioEnv(t *testing.T) *mailAccountConnectionSce
 		}
 	})
 
-	require.NoError(t, mysqlConn.DB.AutoMigrate(&model.User{}, &model.EmailCredential{}))
+	require.NoError(t, mysqlConn.DB.AutoMigrate(&model.User{}, &model.EmailCredential{}, &model.IMAPConnectionSetting{}))
 
 	osw := mocklibrary.NewOsWrapperMock(map[string]string{
 		"APP":                       "test",
@@ -167,10 +168,10 @@ func newMailAccountConnectionScenarioEnv(t *testing.T) *mailAccountConnectionSce
 	require.NoError(t, err)
 
 	macRepo := macinfra.NewRepository(mysqlConn.DB, log)
-	macUseCase := macapp.NewUseCase(macRepo, oauthCfg, exchanger, profileFetcher, vault, nil, log)
+	macUseCase := macapp.NewUseCase(macRepo, oauthCfg, exchanger, profileFetcher, macinfra.NewIMAPLoginVerifier(imap.NewDialer(0, log), log), nil, nil, vault, nil, log)
 	macController := macpresentation.NewController(macUseCase, log)
 	manualUseCase := &scenarioStubManualMailWorkflowUseCase{}
-	manualController := manualpresentation.NewController(manualUseCase, manualUseCase, log)
+	manualController := manualpresentation.NewController(manualUseCase, manualUseCase, nil, nil, nil, nil, nil, nil, log)
 	billingController := billingpresentation.NewController(
 		&scenarioStubBillingListUseCase{},
 		&scenarioStubBillingMonthlyTrendUseCase{},
@@ -185,6 +186,9 @@ func newMailAccountConnectionScenarioEnv(t *testing.T) *mailAccountConnectionSce
 	require.NoError(t, container.Provide(func() *middleware.AuthMiddleware { return authMiddleware }))
 	require.NoError(t, container.Provide(func() *macpresentation.Controller { return macController }))
 	require.NoError(t, container.Provide(func() *manualpresentation.Controller { return manualController }))
+	require.NoError(t, container.Provide(func() *manualpresentation.ScheduleController {
+		return manualpresentation.NewScheduleController(nil, log)
+	}))
 	require.NoError(t, container.Provide(func() *billingpresentation.Controller { return billingController }))
 	require.NoError(t, container.Provide(func() *dashboardpresentation.Controller { return dashboardController }))
 	_, err = v1.Router(router, container, log, scenarioAllowedOrigin)
@@ -165,6 +165,8 @@ func newManualMailWorkflowScenarioEnv(
 		&model.BillingLineItem{},
 		&model.ManualMailWorkflowHistory{},
 		&model.ManualMailWorkflowStageFailure{},
+		&model.ManualMailWorkflowStageEvent{},
+		&model.ManualMailWorkflowJob{},
 	))
 
 	clock := &manualMailWorkflowScenarioClock{
@@ -193,6 +195,9 @@ func newManualMailWorkflowScenarioEnv(
 			fetch:                     fetch,
 		},
 		mfinfra.NewGormEmailRepositoryAdapter(env.db, clock, log),
+		nil,
+		nil,
+		clock,
 		log,
 	)
 	analysisUseCase := maapp.NewUseCase(
@@ -224,6 +229,9 @@ func newManualMailWorkflowScenarioEnv(
 		manualinfra.NewDirectBillingEligibilityAdapter(billingEligibilityUseCase),
 		manualinfra.NewDirectBillingAdapter(billingUseCase),
 		env.workflowRepo,
+		env.workflowRepo,
+		env.workflowRepo,
+		env.workflowRepo,
 		clock,
 		log,
 	)
@@ -444,7 +452,7 @@ func (e *manualMailWorkflowScenarioEnv) listWorkflowHistories(rawQuery string) s
 
 	gin.SetMode(gin.TestMode)
 
-	controller := manualpresentation.NewController(nil, e.listUseCase, e.log)
+	controller := manualpresentation.NewController(nil, e.listUseCase, nil, nil, nil, nil, nil, nil, e.log)
 	router := gin.New()
 	router.GET("/manual-mail-workflows", func(c *gin.Context) {
 		c.Set("userID", e.userID)
@@ -0,0 +1,24 @@
+CREATE TABLE `manual_mail_workflow_jobs` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `workflow_history_id` bigint unsigned NOT NULL,
+  `workflow_id` char(26) NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `connection_id` bigint unsigned NOT NULL,
+  `label_name` varchar(255) NOT NULL,
+  `since_at` datetime(3) NOT NULL,
+  `until_at` datetime(3) NOT NULL,
+  `request_id` varchar(64) NULL,
+  `status` varchar(32) NOT NULL,
+  `attempt_count` int NOT NULL DEFAULT 0,
+  `max_attempts` int NOT NULL,
+  `available_at` datetime(3) NOT NULL,
+  `lease_owner` varchar(128) NULL,
+  `lease_expires_at` datetime(3) NULL,
+  `last_error` text NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uni_manual_mail_workflow_jobs_workflow_history_id` (`workflow_history_id`),
+  INDEX `idx_manual_mail_workflow_jobs_status_available_at` (`status`, `available_at`),
+  INDEX `idx_manual_mail_workflow_jobs_status_lease_expires_at` (`status`, `lease_expires_at`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE `manual_mail_workflow_histories`
+  ADD COLUMN `cancel_requested_at` datetime(3) NULL AFTER `finished_at`;
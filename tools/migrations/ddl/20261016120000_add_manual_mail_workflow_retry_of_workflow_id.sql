@@ -0,0 +1,5 @@
+ALTER TABLE `manual_mail_workflow_histories`
+  ADD COLUMN `retry_of_workflow_id` char(26) NULL AFTER `workflow_id`;
+
+ALTER TABLE `manual_mail_workflow_jobs`
+  ADD COLUMN `retry_of_workflow_id` char(26) NULL AFTER `workflow_id`;
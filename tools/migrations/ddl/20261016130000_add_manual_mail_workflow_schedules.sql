@@ -0,0 +1,21 @@
+CREATE TABLE `manual_mail_workflow_schedules` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `connection_id` bigint unsigned NOT NULL,
+  `label_name` varchar(255) NOT NULL,
+  `frequency` varchar(16) NOT NULL,
+  `weekday` tinyint NULL,
+  `hour_of_day` tinyint NOT NULL,
+  `minute_of_hour` tinyint NOT NULL,
+  `timezone` varchar(64) NOT NULL,
+  `lookback_hours` int NOT NULL,
+  `next_run_at` datetime(3) NOT NULL,
+  `last_run_at` datetime(3) NULL,
+  `last_workflow_id` char(26) NULL,
+  `last_error` text NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_manual_mail_workflow_schedules_next_run_at` (`next_run_at`),
+  INDEX `idx_manual_mail_workflow_schedules_user_id` (`user_id`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
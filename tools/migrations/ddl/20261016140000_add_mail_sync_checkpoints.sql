@@ -0,0 +1,15 @@
+-- Create "mail_sync_checkpoints" table for incremental Gmail sync per connection and label
+CREATE TABLE `mail_sync_checkpoints` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `connection_id` bigint unsigned NOT NULL,
+  `account_identifier` varchar(255) NOT NULL,
+  `label_name` varchar(255) NOT NULL,
+  `history_id` bigint unsigned NOT NULL,
+  `covered_since` datetime(3) NOT NULL,
+  `covered_until` datetime(3) NOT NULL,
+  `synced_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uni_mail_sync_checkpoints_connection_label` (`connection_id`, `label_name`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
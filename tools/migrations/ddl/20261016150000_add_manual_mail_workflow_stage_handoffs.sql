@@ -0,0 +1,11 @@
+-- Create "manual_mail_workflow_stage_handoffs" table for resuming failed workflows from the last finished stage
+CREATE TABLE `manual_mail_workflow_stage_handoffs` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `workflow_history_id` bigint unsigned NOT NULL,
+  `stage` varchar(32) NOT NULL,
+  `payload_json` json NOT NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uni_manual_mail_workflow_stage_handoffs_history_stage` (`workflow_history_id`, `stage`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
@@ -0,0 +1,16 @@
+ALTER TABLE `manual_mail_workflow_histories`
+  ADD COLUMN `dry_run` bool NOT NULL DEFAULT 0 AFTER `retry_of_workflow_id`;
+
+ALTER TABLE `manual_mail_workflow_jobs`
+  ADD COLUMN `dry_run` bool NOT NULL DEFAULT 0 AFTER `retry_of_workflow_id`;
+
+-- Create "manual_mail_workflow_previews" table for the results of dry-run workflows
+CREATE TABLE `manual_mail_workflow_previews` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `workflow_history_id` bigint unsigned NOT NULL,
+  `payload_json` json NOT NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uni_manual_mail_workflow_previews_workflow_history_id` (`workflow_history_id`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
@@ -0,0 +1,5 @@
+-- Link per-connection child workflows to the parent history of a sync-all request
+ALTER TABLE `manual_mail_workflow_histories`
+  ADD COLUMN `parent_workflow_id` char(26) NULL AFTER `retry_of_workflow_id`,
+  ADD COLUMN `fan_out` bool NOT NULL DEFAULT 0 AFTER `dry_run`,
+  ADD INDEX `idx_manual_mail_workflow_histories_parent_workflow_id` (`parent_workflow_id`);
@@ -0,0 +1,13 @@
+-- Create "manual_mail_workflow_stage_counts" table for the counts of stages added to the workflow pipeline
+CREATE TABLE `manual_mail_workflow_stage_counts` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `workflow_history_id` bigint unsigned NOT NULL,
+  `stage` varchar(32) NOT NULL,
+  `success_count` int NOT NULL DEFAULT 0,
+  `business_failure_count` int NOT NULL DEFAULT 0,
+  `technical_failure_count` int NOT NULL DEFAULT 0,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uni_manual_mail_workflow_stage_counts_history_stage` (`workflow_history_id`, `stage`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
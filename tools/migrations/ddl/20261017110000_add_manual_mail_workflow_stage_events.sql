@@ -0,0 +1,12 @@
+-- Create "manual_mail_workflow_stage_events" table for the start and end time of each workflow stage run
+CREATE TABLE `manual_mail_workflow_stage_events` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `workflow_history_id` bigint unsigned NOT NULL,
+  `stage` varchar(32) NOT NULL,
+  `started_at` datetime(3) NOT NULL,
+  `finished_at` datetime(3) NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_manual_mail_workflow_stage_events_history_started_at` (`workflow_history_id`, `started_at`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
@@ -0,0 +1,14 @@
+-- Create "imap_connection_settings" table for the server and encrypted login of IMAP mail account connections
+CREATE TABLE `imap_connection_settings` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `email_credential_id` bigint unsigned NOT NULL,
+  `host` varchar(255) NOT NULL,
+  `port` bigint NOT NULL,
+  `security` varchar(16) NOT NULL,
+  `username` varchar(255) NOT NULL,
+  `password` text NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uni_imap_connection_settings_email_credential_id` (`email_credential_id`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
@@ -0,0 +1,12 @@
+-- Create "mail_import_messages" table for the raw messages of uploaded .eml and mbox files until a workflow fetches them
+CREATE TABLE `mail_import_messages` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `email_credential_id` bigint unsigned NOT NULL,
+  `label_name` varchar(255) NOT NULL,
+  `external_message_id` varchar(255) NOT NULL,
+  `received_at` datetime(3) NULL,
+  `raw_message` longblob NOT NULL,
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uni_mail_import_messages_credential_label_message` (`email_credential_id`, `label_name`, `external_message_id`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
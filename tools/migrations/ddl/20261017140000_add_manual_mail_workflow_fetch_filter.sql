@@ -0,0 +1,4 @@
+-- Modify "manual_mail_workflow_histories" table to keep the Gmail search query and label filter of a workflow
+ALTER TABLE `manual_mail_workflow_histories` ADD COLUMN `fetch_filter` json NULL AFTER `until_at`;
+-- Modify "manual_mail_workflow_jobs" table to hand the fetch filter to the worker
+ALTER TABLE `manual_mail_workflow_jobs` ADD COLUMN `fetch_filter` json NULL AFTER `until_at`;
@@ -0,0 +1,14 @@
+-- Create "email_bodies" table for the owner and retention of email bodies kept encrypted in the body store
+CREATE TABLE `email_bodies` (
+  `email_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `object_key` varchar(255) NOT NULL,
+  `body_digest` varchar(64) NOT NULL,
+  `stored_at` datetime(3) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`email_id`),
+  INDEX `idx_email_bodies_user_id` (`user_id`),
+  INDEX `idx_email_bodies_expires_at` (`expires_at`)
+) CHARSET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
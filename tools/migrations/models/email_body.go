@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// EmailBody represents the email_bodies table for email bodies kept encrypted in the body store.
+type EmailBody struct {
+	EmailID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	UserID     uint      `gorm:"not null;index:idx_email_bodies_user_id"`
+	ObjectKey  string    `gorm:"size:255;not null"`
+	BodyDigest string    `gorm:"size:64;not null"`
+	StoredAt   time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index:idx_email_bodies_expires_at"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName specifies the table name for the EmailBody model.
+func (EmailBody) TableName() string {
+	return "email_bodies"
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// IMAPConnectionSetting represents the imap_connection_settings table for the server and login of an IMAP connection.
+// Password is encrypted with crypto.Vault.
+type IMAPConnectionSetting struct {
+	ID                uint   `gorm:"primaryKey;autoIncrement"`
+	EmailCredentialID uint   `gorm:"not null;uniqueIndex:uni_imap_connection_settings_email_credential_id"`
+	Host              string `gorm:"size:255;not null"`
+	Port              int    `gorm:"not null"`
+	Security          string `gorm:"size:16;not null"`
+	Username          string `gorm:"size:255;not null"`
+	Password          string `gorm:"type:text;not null"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName specifies the table name for the IMAPConnectionSetting model.
+func (IMAPConnectionSetting) TableName() string {
+	return "imap_connection_settings"
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// MailSyncCheckpoint represents the mail_sync_checkpoints table for incremental provider sync.
+type MailSyncCheckpoint struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	ConnectionID      uint      `gorm:"not null;uniqueIndex:uni_mail_sync_checkpoints_connection_label"`
+	AccountIdentifier string    `gorm:"size:255;not null"`
+	LabelName         string    `gorm:"size:255;not null;uniqueIndex:uni_mail_sync_checkpoints_connection_label"`
+	HistoryID         uint64    `gorm:"not null"`
+	CoveredSince      time.Time `gorm:"not null"`
+	CoveredUntil      time.Time `gorm:"not null"`
+	SyncedAt          time.Time `gorm:"not null"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName specifies the table name for the MailSyncCheckpoint model.
+func (MailSyncCheckpoint) TableName() string {
+	return "mail_sync_checkpoints"
+}
@@ -6,6 +6,10 @@ import "time"
 type ManualMailWorkflowHistory struct {
 	ID                                      uint64    `gorm:"primaryKey;autoIncrement"`
 	WorkflowID                              string    `gorm:"type:char(26);not null;uniqueIndex:uni_manual_mail_workflow_histories_workflow_id"`
+	RetryOfWorkflowID                       *string   `gorm:"type:char(26)"`
+	ParentWorkflowID                        *string   `gorm:"type:char(26);index:idx_manual_mail_workflow_histories_parent_workflow_id"`
+	DryRun                                  bool      `gorm:"not null;default:false"`
+	FanOut                                  bool      `gorm:"not null;default:false"`
 	UserID                                  uint      `gorm:"not null;index:idx_manual_mail_workflow_histories_user_queued_at,priority:1;index:idx_manual_mail_workflow_histories_user_status_queued_at,priority:1"`
 	Provider                                string    `gorm:"size:50;not null"`
 	AccountIdentifier                       string    `gorm:"size:255;not null"`
@@ -16,6 +20,7 @@ type ManualMailWorkflowHistory struct {
 	CurrentStage                            *string   `gorm:"size:32"`
 	QueuedAt                                time.Time `gorm:"not null;index:idx_manual_mail_workflow_histories_user_queued_at,priority:2;index:idx_manual_mail_workflow_histories_user_status_queued_at,priority:3"`
 	FinishedAt                              *time.Time
+	CancelRequestedAt                       *time.Time
 	ErrorMessage                            *string `gorm:"type:text"`
 	FetchSuccessCount                       int     `gorm:"not null;default:0"`
 	FetchBusinessFailureCount               int     `gorm:"not null;default:0"`
@@ -55,3 +60,119 @@ type ManualMailWorkflowStageFailure struct {
 func (ManualMailWorkflowStageFailure) TableName() string {
 	return "manual_mail_workflow_stage_failures"
 }
+
+// ManualMailWorkflowJob represents the manual_mail_workflow_jobs table.
+type ManualMailWorkflowJob struct {
+	ID                uint64     `gorm:"primaryKey;autoIncrement"`
+	WorkflowHistoryID uint64     `gorm:"not null;uniqueIndex:uni_manual_mail_workflow_jobs_workflow_history_id"`
+	WorkflowID        string     `gorm:"type:char(26);not null"`
+	RetryOfWorkflowID *string    `gorm:"type:char(26)"`
+	DryRun            bool       `gorm:"not null;default:false"`
+	UserID            uint       `gorm:"not null"`
+	ConnectionID      uint       `gorm:"not null"`
+	LabelName         string     `gorm:"size:255;not null"`
+	SinceAt           time.Time  `gorm:"not null"`
+	UntilAt           time.Time  `gorm:"not null"`
+	RequestID         *string    `gorm:"size:64"`
+	Status            string     `gorm:"size:32;not null;index:idx_manual_mail_workflow_jobs_status_available_at,priority:1;index:idx_manual_mail_workflow_jobs_status_lease_expires_at,priority:1"`
+	AttemptCount      int        `gorm:"not null;default:0"`
+	MaxAttempts       int        `gorm:"not null"`
+	AvailableAt       time.Time  `gorm:"not null;index:idx_manual_mail_workflow_jobs_status_available_at,priority:2"`
+	LeaseOwner        *string    `gorm:"size:128"`
+	LeaseExpiresAt    *time.Time `gorm:"index:idx_manual_mail_workflow_jobs_status_lease_expires_at,priority:2"`
+	LastError         *string    `gorm:"type:text"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName specifies the table name for the ManualMailWorkflowJob model.
+func (ManualMailWorkflowJob) TableName() string {
+	return "manual_mail_workflow_jobs"
+}
+
+// ManualMailWorkflowSchedule represents the manual_mail_workflow_schedules table.
+type ManualMailWorkflowSchedule struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID         uint      `gorm:"not null;index:idx_manual_mail_workflow_schedules_user_id"`
+	ConnectionID   uint      `gorm:"not null"`
+	LabelName      string    `gorm:"size:255;not null"`
+	Frequency      string    `gorm:"size:16;not null"`
+	Weekday        *int      `gorm:"type:tinyint"`
+	HourOfDay      int       `gorm:"type:tinyint;not null"`
+	MinuteOfHour   int       `gorm:"type:tinyint;not null"`
+	Timezone       string    `gorm:"size:64;not null"`
+	LookbackHours  int       `gorm:"not null"`
+	NextRunAt      time.Time `gorm:"not null;index:idx_manual_mail_workflow_schedules_next_run_at"`
+	LastRunAt      *time.Time
+	LastWorkflowID *string `gorm:"type:char(26)"`
+	LastError      *string `gorm:"type:text"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName specifies the table name for the ManualMailWorkflowSchedule model.
+func (ManualMailWorkflowSchedule) TableName() string {
+	return "manual_mail_workflow_schedules"
+}
+
+// ManualMailWorkflowStageHandoff represents the manual_mail_workflow_stage_handoffs table.
+type ManualMailWorkflowStageHandoff struct {
+	ID                uint64 `gorm:"primaryKey;autoIncrement"`
+	WorkflowHistoryID uint64 `gorm:"not null;uniqueIndex:uni_manual_mail_workflow_stage_handoffs_history_stage,priority:1"`
+	Stage             string `gorm:"size:32;not null;uniqueIndex:uni_manual_mail_workflow_stage_handoffs_history_stage,priority:2"`
+	PayloadJSON       string `gorm:"column:payload_json;type:json;not null"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName specifies the table name for the ManualMailWorkflowStageHandoff model.
+func (ManualMailWorkflowStageHandoff) TableName() string {
+	return "manual_mail_workflow_stage_handoffs"
+}
+
+// ManualMailWorkflowStageCount represents the manual_mail_workflow_stage_counts table.
+type ManualMailWorkflowStageCount struct {
+	ID                    uint64 `gorm:"primaryKey;autoIncrement"`
+	WorkflowHistoryID     uint64 `gorm:"not null;uniqueIndex:uni_manual_mail_workflow_stage_counts_history_stage,priority:1"`
+	Stage                 string `gorm:"size:32;not null;uniqueIndex:uni_manual_mail_workflow_stage_counts_history_stage,priority:2"`
+	SuccessCount          int    `gorm:"not null;default:0"`
+	BusinessFailureCount  int    `gorm:"not null;default:0"`
+	TechnicalFailureCount int    `gorm:"not null;default:0"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// TableName specifies the table name for the ManualMailWorkflowStageCount model.
+func (ManualMailWorkflowStageCount) TableName() string {
+	return "manual_mail_workflow_stage_counts"
+}
+
+// ManualMailWorkflowStageEvent represents the manual_mail_workflow_stage_events table.
+type ManualMailWorkflowStageEvent struct {
+	ID                uint64    `gorm:"primaryKey;autoIncrement"`
+	WorkflowHistoryID uint64    `gorm:"not null;index:idx_manual_mail_workflow_stage_events_history_started_at,priority:1"`
+	Stage             string    `gorm:"size:32;not null"`
+	StartedAt         time.Time `gorm:"not null;index:idx_manual_mail_workflow_stage_events_history_started_at,priority:2"`
+	FinishedAt        *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName specifies the table name for the ManualMailWorkflowStageEvent model.
+func (ManualMailWorkflowStageEvent) TableName() string {
+	return "manual_mail_workflow_stage_events"
+}
+
+// ManualMailWorkflowPreview represents the manual_mail_workflow_previews table.
+type ManualMailWorkflowPreview struct {
+	ID                uint64 `gorm:"primaryKey;autoIncrement"`
+	WorkflowHistoryID uint64 `gorm:"not null;uniqueIndex:uni_manual_mail_workflow_previews_workflow_history_id"`
+	PayloadJSON       string `gorm:"column:payload_json;type:json;not null"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName specifies the table name for the ManualMailWorkflowPreview model.
+func (ManualMailWorkflowPreview) TableName() string {
+	return "manual_mail_workflow_previews"
+}